@@ -0,0 +1,3577 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        v5.29.3
+// source: segmentation/v1/segmentation.proto
+
+// Typed binary API of the user segmentation service for service-to-service calls.
+// Every operation mirrors the corresponding method of ports.SegmentService and the http API.
+
+package segmentationv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CreateSegmentRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// A short name containing only letters, numbers, underscores, or hyphens. Format: ^[\w-]+$
+	Slug string `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	// Rule over the user attributes, e.g. 'city == "Moscow" and listings > 5'.
+	Rule string `protobuf:"bytes,2,opt,name=rule,proto3" json:"rule,omitempty"`
+	// Expression over other segments, e.g. '(MOSCOW union KAZAN) except BANNED'.
+	Expression string `protobuf:"bytes,3,opt,name=expression,proto3" json:"expression,omitempty"`
+	// Percentage of users in the rollout, from 0 to 100.
+	Rollout *float64 `protobuf:"fixed64,4,opt,name=rollout,proto3,oneof" json:"rollout,omitempty"`
+	// Salt of the rollout hash, the slug by default.
+	Salt string `protobuf:"bytes,5,opt,name=salt,proto3" json:"salt,omitempty"`
+	// Start and end of the activation window in RFC 3339 format, empty for an open side.
+	ActiveFrom  string `protobuf:"bytes,6,opt,name=active_from,json=activeFrom,proto3" json:"active_from,omitempty"`
+	ActiveUntil string `protobuf:"bytes,7,opt,name=active_until,json=activeUntil,proto3" json:"active_until,omitempty"`
+	// Maximum number of members, no cap if missing.
+	MaxMembers    *int32 `protobuf:"varint,8,opt,name=max_members,json=maxMembers,proto3,oneof" json:"max_members,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateSegmentRequest) Reset() {
+	*x = CreateSegmentRequest{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateSegmentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateSegmentRequest) ProtoMessage() {}
+
+func (x *CreateSegmentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateSegmentRequest.ProtoReflect.Descriptor instead.
+func (*CreateSegmentRequest) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CreateSegmentRequest) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *CreateSegmentRequest) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+func (x *CreateSegmentRequest) GetExpression() string {
+	if x != nil {
+		return x.Expression
+	}
+	return ""
+}
+
+func (x *CreateSegmentRequest) GetRollout() float64 {
+	if x != nil && x.Rollout != nil {
+		return *x.Rollout
+	}
+	return 0
+}
+
+func (x *CreateSegmentRequest) GetSalt() string {
+	if x != nil {
+		return x.Salt
+	}
+	return ""
+}
+
+func (x *CreateSegmentRequest) GetActiveFrom() string {
+	if x != nil {
+		return x.ActiveFrom
+	}
+	return ""
+}
+
+func (x *CreateSegmentRequest) GetActiveUntil() string {
+	if x != nil {
+		return x.ActiveUntil
+	}
+	return ""
+}
+
+func (x *CreateSegmentRequest) GetMaxMembers() int32 {
+	if x != nil && x.MaxMembers != nil {
+		return *x.MaxMembers
+	}
+	return 0
+}
+
+type CreateSegmentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateSegmentResponse) Reset() {
+	*x = CreateSegmentResponse{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateSegmentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateSegmentResponse) ProtoMessage() {}
+
+func (x *CreateSegmentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateSegmentResponse.ProtoReflect.Descriptor instead.
+func (*CreateSegmentResponse) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{1}
+}
+
+type DeleteSegmentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Slug          string                 `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteSegmentRequest) Reset() {
+	*x = DeleteSegmentRequest{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteSegmentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteSegmentRequest) ProtoMessage() {}
+
+func (x *DeleteSegmentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteSegmentRequest.ProtoReflect.Descriptor instead.
+func (*DeleteSegmentRequest) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *DeleteSegmentRequest) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+type DeleteSegmentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteSegmentResponse) Reset() {
+	*x = DeleteSegmentResponse{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteSegmentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteSegmentResponse) ProtoMessage() {}
+
+func (x *DeleteSegmentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteSegmentResponse.ProtoReflect.Descriptor instead.
+func (*DeleteSegmentResponse) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{3}
+}
+
+type RenameSegmentRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Slug    string                 `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	NewSlug string                 `protobuf:"bytes,2,opt,name=new_slug,json=newSlug,proto3" json:"new_slug,omitempty"`
+	// Lifetime of the alias in days, 0-365, 30 if not set.
+	AliasDays     *int32 `protobuf:"varint,3,opt,name=alias_days,json=aliasDays,proto3,oneof" json:"alias_days,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenameSegmentRequest) Reset() {
+	*x = RenameSegmentRequest{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenameSegmentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenameSegmentRequest) ProtoMessage() {}
+
+func (x *RenameSegmentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenameSegmentRequest.ProtoReflect.Descriptor instead.
+func (*RenameSegmentRequest) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *RenameSegmentRequest) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *RenameSegmentRequest) GetNewSlug() string {
+	if x != nil {
+		return x.NewSlug
+	}
+	return ""
+}
+
+func (x *RenameSegmentRequest) GetAliasDays() int32 {
+	if x != nil && x.AliasDays != nil {
+		return *x.AliasDays
+	}
+	return 0
+}
+
+type RenameSegmentResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Alias string                 `protobuf:"bytes,1,opt,name=alias,proto3" json:"alias,omitempty"`
+	Slug  string                 `protobuf:"bytes,2,opt,name=slug,proto3" json:"slug,omitempty"`
+	// Expiration time of the alias in RFC 3339 format.
+	ExpiresAt     string `protobuf:"bytes,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenameSegmentResponse) Reset() {
+	*x = RenameSegmentResponse{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenameSegmentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenameSegmentResponse) ProtoMessage() {}
+
+func (x *RenameSegmentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenameSegmentResponse.ProtoReflect.Descriptor instead.
+func (*RenameSegmentResponse) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *RenameSegmentResponse) GetAlias() string {
+	if x != nil {
+		return x.Alias
+	}
+	return ""
+}
+
+func (x *RenameSegmentResponse) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *RenameSegmentResponse) GetExpiresAt() string {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return ""
+}
+
+type SetSegmentRuleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Slug          string                 `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	Rule          string                 `protobuf:"bytes,2,opt,name=rule,proto3" json:"rule,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetSegmentRuleRequest) Reset() {
+	*x = SetSegmentRuleRequest{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetSegmentRuleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetSegmentRuleRequest) ProtoMessage() {}
+
+func (x *SetSegmentRuleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetSegmentRuleRequest.ProtoReflect.Descriptor instead.
+func (*SetSegmentRuleRequest) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SetSegmentRuleRequest) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *SetSegmentRuleRequest) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+type SetSegmentRuleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Added         int32                  `protobuf:"varint,1,opt,name=added,proto3" json:"added,omitempty"`
+	Removed       int32                  `protobuf:"varint,2,opt,name=removed,proto3" json:"removed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetSegmentRuleResponse) Reset() {
+	*x = SetSegmentRuleResponse{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetSegmentRuleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetSegmentRuleResponse) ProtoMessage() {}
+
+func (x *SetSegmentRuleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetSegmentRuleResponse.ProtoReflect.Descriptor instead.
+func (*SetSegmentRuleResponse) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SetSegmentRuleResponse) GetAdded() int32 {
+	if x != nil {
+		return x.Added
+	}
+	return 0
+}
+
+func (x *SetSegmentRuleResponse) GetRemoved() int32 {
+	if x != nil {
+		return x.Removed
+	}
+	return 0
+}
+
+type SetSegmentExpressionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Slug          string                 `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	Expression    string                 `protobuf:"bytes,2,opt,name=expression,proto3" json:"expression,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetSegmentExpressionRequest) Reset() {
+	*x = SetSegmentExpressionRequest{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetSegmentExpressionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetSegmentExpressionRequest) ProtoMessage() {}
+
+func (x *SetSegmentExpressionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetSegmentExpressionRequest.ProtoReflect.Descriptor instead.
+func (*SetSegmentExpressionRequest) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SetSegmentExpressionRequest) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *SetSegmentExpressionRequest) GetExpression() string {
+	if x != nil {
+		return x.Expression
+	}
+	return ""
+}
+
+type SetSegmentExpressionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Added         int32                  `protobuf:"varint,1,opt,name=added,proto3" json:"added,omitempty"`
+	Removed       int32                  `protobuf:"varint,2,opt,name=removed,proto3" json:"removed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetSegmentExpressionResponse) Reset() {
+	*x = SetSegmentExpressionResponse{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetSegmentExpressionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetSegmentExpressionResponse) ProtoMessage() {}
+
+func (x *SetSegmentExpressionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetSegmentExpressionResponse.ProtoReflect.Descriptor instead.
+func (*SetSegmentExpressionResponse) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SetSegmentExpressionResponse) GetAdded() int32 {
+	if x != nil {
+		return x.Added
+	}
+	return 0
+}
+
+func (x *SetSegmentExpressionResponse) GetRemoved() int32 {
+	if x != nil {
+		return x.Removed
+	}
+	return 0
+}
+
+type SetSegmentRolloutRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Slug    string                 `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	Rollout *float64               `protobuf:"fixed64,2,opt,name=rollout,proto3,oneof" json:"rollout,omitempty"`
+	// Salt of the rollout hash, the slug by default. It can't be changed once set.
+	Salt          string `protobuf:"bytes,3,opt,name=salt,proto3" json:"salt,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetSegmentRolloutRequest) Reset() {
+	*x = SetSegmentRolloutRequest{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetSegmentRolloutRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetSegmentRolloutRequest) ProtoMessage() {}
+
+func (x *SetSegmentRolloutRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetSegmentRolloutRequest.ProtoReflect.Descriptor instead.
+func (*SetSegmentRolloutRequest) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SetSegmentRolloutRequest) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *SetSegmentRolloutRequest) GetRollout() float64 {
+	if x != nil && x.Rollout != nil {
+		return *x.Rollout
+	}
+	return 0
+}
+
+func (x *SetSegmentRolloutRequest) GetSalt() string {
+	if x != nil {
+		return x.Salt
+	}
+	return ""
+}
+
+type SetSegmentRolloutResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Slug          string                 `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	Rollout       *float64               `protobuf:"fixed64,2,opt,name=rollout,proto3,oneof" json:"rollout,omitempty"`
+	Salt          string                 `protobuf:"bytes,3,opt,name=salt,proto3" json:"salt,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetSegmentRolloutResponse) Reset() {
+	*x = SetSegmentRolloutResponse{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetSegmentRolloutResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetSegmentRolloutResponse) ProtoMessage() {}
+
+func (x *SetSegmentRolloutResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetSegmentRolloutResponse.ProtoReflect.Descriptor instead.
+func (*SetSegmentRolloutResponse) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *SetSegmentRolloutResponse) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *SetSegmentRolloutResponse) GetRollout() float64 {
+	if x != nil && x.Rollout != nil {
+		return *x.Rollout
+	}
+	return 0
+}
+
+func (x *SetSegmentRolloutResponse) GetSalt() string {
+	if x != nil {
+		return x.Salt
+	}
+	return ""
+}
+
+type SetSegmentWindowRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Slug  string                 `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	// Start and end of the activation window in RFC 3339 format, empty for an open side.
+	ActiveFrom    string `protobuf:"bytes,2,opt,name=active_from,json=activeFrom,proto3" json:"active_from,omitempty"`
+	ActiveUntil   string `protobuf:"bytes,3,opt,name=active_until,json=activeUntil,proto3" json:"active_until,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetSegmentWindowRequest) Reset() {
+	*x = SetSegmentWindowRequest{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetSegmentWindowRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetSegmentWindowRequest) ProtoMessage() {}
+
+func (x *SetSegmentWindowRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetSegmentWindowRequest.ProtoReflect.Descriptor instead.
+func (*SetSegmentWindowRequest) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *SetSegmentWindowRequest) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *SetSegmentWindowRequest) GetActiveFrom() string {
+	if x != nil {
+		return x.ActiveFrom
+	}
+	return ""
+}
+
+func (x *SetSegmentWindowRequest) GetActiveUntil() string {
+	if x != nil {
+		return x.ActiveUntil
+	}
+	return ""
+}
+
+type SetSegmentCapRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Slug          string                 `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	MaxMembers    *int32                 `protobuf:"varint,2,opt,name=max_members,json=maxMembers,proto3,oneof" json:"max_members,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetSegmentCapRequest) Reset() {
+	*x = SetSegmentCapRequest{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetSegmentCapRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetSegmentCapRequest) ProtoMessage() {}
+
+func (x *SetSegmentCapRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetSegmentCapRequest.ProtoReflect.Descriptor instead.
+func (*SetSegmentCapRequest) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *SetSegmentCapRequest) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *SetSegmentCapRequest) GetMaxMembers() int32 {
+	if x != nil && x.MaxMembers != nil {
+		return *x.MaxMembers
+	}
+	return 0
+}
+
+type SegmentState struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Slug  string                 `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	// scheduled, active or ended
+	State       string `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`
+	ActiveFrom  string `protobuf:"bytes,3,opt,name=active_from,json=activeFrom,proto3" json:"active_from,omitempty"`
+	ActiveUntil string `protobuf:"bytes,4,opt,name=active_until,json=activeUntil,proto3" json:"active_until,omitempty"`
+	// The cap and the number of members, only for a segment with a cap.
+	MaxMembers    *int32 `protobuf:"varint,5,opt,name=max_members,json=maxMembers,proto3,oneof" json:"max_members,omitempty"`
+	Members       *int32 `protobuf:"varint,6,opt,name=members,proto3,oneof" json:"members,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SegmentState) Reset() {
+	*x = SegmentState{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SegmentState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SegmentState) ProtoMessage() {}
+
+func (x *SegmentState) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SegmentState.ProtoReflect.Descriptor instead.
+func (*SegmentState) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *SegmentState) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *SegmentState) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *SegmentState) GetActiveFrom() string {
+	if x != nil {
+		return x.ActiveFrom
+	}
+	return ""
+}
+
+func (x *SegmentState) GetActiveUntil() string {
+	if x != nil {
+		return x.ActiveUntil
+	}
+	return ""
+}
+
+func (x *SegmentState) GetMaxMembers() int32 {
+	if x != nil && x.MaxMembers != nil {
+		return *x.MaxMembers
+	}
+	return 0
+}
+
+func (x *SegmentState) GetMembers() int32 {
+	if x != nil && x.Members != nil {
+		return *x.Members
+	}
+	return 0
+}
+
+type UpdateUserSegmentsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// User ID in uuid format.
+	UserId           string   `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	SegmentsToAdd    []string `protobuf:"bytes,2,rep,name=segments_to_add,json=segmentsToAdd,proto3" json:"segments_to_add,omitempty"`
+	SegmentsToRemove []string `protobuf:"bytes,3,rep,name=segments_to_remove,json=segmentsToRemove,proto3" json:"segments_to_remove,omitempty"`
+	// Go through all the checks and return the diff without writing anything, the unknown segments are listed
+	// in the diff instead of failing.
+	DryRun bool `protobuf:"varint,4,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	// Apply the existing segments and report the unknown ones with the not_found status instead of failing.
+	NonStrict     bool `protobuf:"varint,5,opt,name=non_strict,json=nonStrict,proto3" json:"non_strict,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateUserSegmentsRequest) Reset() {
+	*x = UpdateUserSegmentsRequest{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateUserSegmentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateUserSegmentsRequest) ProtoMessage() {}
+
+func (x *UpdateUserSegmentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateUserSegmentsRequest.ProtoReflect.Descriptor instead.
+func (*UpdateUserSegmentsRequest) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *UpdateUserSegmentsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *UpdateUserSegmentsRequest) GetSegmentsToAdd() []string {
+	if x != nil {
+		return x.SegmentsToAdd
+	}
+	return nil
+}
+
+func (x *UpdateUserSegmentsRequest) GetSegmentsToRemove() []string {
+	if x != nil {
+		return x.SegmentsToRemove
+	}
+	return nil
+}
+
+func (x *UpdateUserSegmentsRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *UpdateUserSegmentsRequest) GetNonStrict() bool {
+	if x != nil {
+		return x.NonStrict
+	}
+	return false
+}
+
+// The outcome of adding or removing the user to or from one of the requested segments.
+type SegmentResult struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Segment string                 `protobuf:"bytes,1,opt,name=segment,proto3" json:"segment,omitempty"`
+	// "add" or "remove".
+	Action string `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	// "applied", "already_member", "not_member", "not_found" or "segment_full".
+	Status        string `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SegmentResult) Reset() {
+	*x = SegmentResult{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SegmentResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SegmentResult) ProtoMessage() {}
+
+func (x *SegmentResult) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SegmentResult.ProtoReflect.Descriptor instead.
+func (*SegmentResult) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *SegmentResult) GetSegment() string {
+	if x != nil {
+		return x.Segment
+	}
+	return ""
+}
+
+func (x *SegmentResult) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *SegmentResult) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+// The change of the memberships of the user made by the update or, for a dry run, that it would make. Added and
+// removed include the composite segments and the experiment variants changed as a side effect.
+type UpdateUserSegmentsResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	DryRun  bool                   `protobuf:"varint,1,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	Added   []string               `protobuf:"bytes,2,rep,name=added,proto3" json:"added,omitempty"`
+	Removed []string               `protobuf:"bytes,3,rep,name=removed,proto3" json:"removed,omitempty"`
+	NoOp    []string               `protobuf:"bytes,4,rep,name=no_op,json=noOp,proto3" json:"no_op,omitempty"`
+	Unknown []string               `protobuf:"bytes,5,rep,name=unknown,proto3" json:"unknown,omitempty"`
+	// The status of every requested segment in the order of the request, the removals first.
+	Results       []*SegmentResult `protobuf:"bytes,6,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateUserSegmentsResponse) Reset() {
+	*x = UpdateUserSegmentsResponse{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateUserSegmentsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateUserSegmentsResponse) ProtoMessage() {}
+
+func (x *UpdateUserSegmentsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateUserSegmentsResponse.ProtoReflect.Descriptor instead.
+func (*UpdateUserSegmentsResponse) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *UpdateUserSegmentsResponse) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *UpdateUserSegmentsResponse) GetAdded() []string {
+	if x != nil {
+		return x.Added
+	}
+	return nil
+}
+
+func (x *UpdateUserSegmentsResponse) GetRemoved() []string {
+	if x != nil {
+		return x.Removed
+	}
+	return nil
+}
+
+func (x *UpdateUserSegmentsResponse) GetNoOp() []string {
+	if x != nil {
+		return x.NoOp
+	}
+	return nil
+}
+
+func (x *UpdateUserSegmentsResponse) GetUnknown() []string {
+	if x != nil {
+		return x.Unknown
+	}
+	return nil
+}
+
+func (x *UpdateUserSegmentsResponse) GetResults() []*SegmentResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type GetUserSegmentsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserSegmentsRequest) Reset() {
+	*x = GetUserSegmentsRequest{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserSegmentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserSegmentsRequest) ProtoMessage() {}
+
+func (x *GetUserSegmentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserSegmentsRequest.ProtoReflect.Descriptor instead.
+func (*GetUserSegmentsRequest) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *GetUserSegmentsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type GetUserSegmentsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Segments      []string               `protobuf:"bytes,1,rep,name=segments,proto3" json:"segments,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserSegmentsResponse) Reset() {
+	*x = GetUserSegmentsResponse{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserSegmentsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserSegmentsResponse) ProtoMessage() {}
+
+func (x *GetUserSegmentsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserSegmentsResponse.ProtoReflect.Descriptor instead.
+func (*GetUserSegmentsResponse) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *GetUserSegmentsResponse) GetSegments() []string {
+	if x != nil {
+		return x.Segments
+	}
+	return nil
+}
+
+type ListSegmentsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSegmentsRequest) Reset() {
+	*x = ListSegmentsRequest{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSegmentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSegmentsRequest) ProtoMessage() {}
+
+func (x *ListSegmentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSegmentsRequest.ProtoReflect.Descriptor instead.
+func (*ListSegmentsRequest) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{20}
+}
+
+type ListSegmentsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Segments      []string               `protobuf:"bytes,1,rep,name=segments,proto3" json:"segments,omitempty"`
+	States        []*SegmentState        `protobuf:"bytes,2,rep,name=states,proto3" json:"states,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSegmentsResponse) Reset() {
+	*x = ListSegmentsResponse{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSegmentsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSegmentsResponse) ProtoMessage() {}
+
+func (x *ListSegmentsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSegmentsResponse.ProtoReflect.Descriptor instead.
+func (*ListSegmentsResponse) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ListSegmentsResponse) GetSegments() []string {
+	if x != nil {
+		return x.Segments
+	}
+	return nil
+}
+
+func (x *ListSegmentsResponse) GetStates() []*SegmentState {
+	if x != nil {
+		return x.States
+	}
+	return nil
+}
+
+type GetSegmentMembersRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Slug  string                 `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	// Maximum number of members, 1-10000, 1000 if not set.
+	Limit int32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	// Return the members with user IDs greater than this one.
+	After         string `protobuf:"bytes,3,opt,name=after,proto3" json:"after,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSegmentMembersRequest) Reset() {
+	*x = GetSegmentMembersRequest{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSegmentMembersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSegmentMembersRequest) ProtoMessage() {}
+
+func (x *GetSegmentMembersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSegmentMembersRequest.ProtoReflect.Descriptor instead.
+func (*GetSegmentMembersRequest) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *GetSegmentMembersRequest) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *GetSegmentMembersRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GetSegmentMembersRequest) GetAfter() string {
+	if x != nil {
+		return x.After
+	}
+	return ""
+}
+
+type GetSegmentMembersResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Members []string               `protobuf:"bytes,1,rep,name=members,proto3" json:"members,omitempty"`
+	// The 'after' value of the next page, empty on the last page.
+	Next          string `protobuf:"bytes,2,opt,name=next,proto3" json:"next,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSegmentMembersResponse) Reset() {
+	*x = GetSegmentMembersResponse{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSegmentMembersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSegmentMembersResponse) ProtoMessage() {}
+
+func (x *GetSegmentMembersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSegmentMembersResponse.ProtoReflect.Descriptor instead.
+func (*GetSegmentMembersResponse) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *GetSegmentMembersResponse) GetMembers() []string {
+	if x != nil {
+		return x.Members
+	}
+	return nil
+}
+
+func (x *GetSegmentMembersResponse) GetNext() string {
+	if x != nil {
+		return x.Next
+	}
+	return ""
+}
+
+type GetSegmentHistoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Slug          string                 `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSegmentHistoryRequest) Reset() {
+	*x = GetSegmentHistoryRequest{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSegmentHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSegmentHistoryRequest) ProtoMessage() {}
+
+func (x *GetSegmentHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSegmentHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetSegmentHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *GetSegmentHistoryRequest) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+type GetSegmentHistoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Slug          string                 `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	Events        []*SegmentEvent        `protobuf:"bytes,2,rep,name=events,proto3" json:"events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSegmentHistoryResponse) Reset() {
+	*x = GetSegmentHistoryResponse{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSegmentHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSegmentHistoryResponse) ProtoMessage() {}
+
+func (x *GetSegmentHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSegmentHistoryResponse.ProtoReflect.Descriptor instead.
+func (*GetSegmentHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *GetSegmentHistoryResponse) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *GetSegmentHistoryResponse) GetEvents() []*SegmentEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+// An entry of the segment audit log, empty values mean the segment had no such setting.
+type SegmentEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Slug of the segment at the time of the event.
+	Segment string `protobuf:"bytes,1,opt,name=segment,proto3" json:"segment,omitempty"`
+	// One of created, deleted, renamed, rule_changed, expression_changed, rollout_changed, window_changed, activated, deactivated.
+	Event    string `protobuf:"bytes,2,opt,name=event,proto3" json:"event,omitempty"`
+	OldValue string `protobuf:"bytes,3,opt,name=old_value,json=oldValue,proto3" json:"old_value,omitempty"`
+	NewValue string `protobuf:"bytes,4,opt,name=new_value,json=newValue,proto3" json:"new_value,omitempty"`
+	// Time of the event in RFC 3339 format in the history, in the format 'yyyy-mm-dd hh:mm:ss' in the report.
+	Time          string `protobuf:"bytes,5,opt,name=time,proto3" json:"time,omitempty"`
+	Actor         string `protobuf:"bytes,6,opt,name=actor,proto3" json:"actor,omitempty"`
+	Source        string `protobuf:"bytes,7,opt,name=source,proto3" json:"source,omitempty"`
+	Reason        string `protobuf:"bytes,8,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SegmentEvent) Reset() {
+	*x = SegmentEvent{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SegmentEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SegmentEvent) ProtoMessage() {}
+
+func (x *SegmentEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SegmentEvent.ProtoReflect.Descriptor instead.
+func (*SegmentEvent) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *SegmentEvent) GetSegment() string {
+	if x != nil {
+		return x.Segment
+	}
+	return ""
+}
+
+func (x *SegmentEvent) GetEvent() string {
+	if x != nil {
+		return x.Event
+	}
+	return ""
+}
+
+func (x *SegmentEvent) GetOldValue() string {
+	if x != nil {
+		return x.OldValue
+	}
+	return ""
+}
+
+func (x *SegmentEvent) GetNewValue() string {
+	if x != nil {
+		return x.NewValue
+	}
+	return ""
+}
+
+func (x *SegmentEvent) GetTime() string {
+	if x != nil {
+		return x.Time
+	}
+	return ""
+}
+
+func (x *SegmentEvent) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *SegmentEvent) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *SegmentEvent) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type GetSegmentStatsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Slug  string                 `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	// First day of the range in the format 'yyyy-mm-dd', 30 days before the last one if empty.
+	From string `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	// Last day of the range in the format 'yyyy-mm-dd', today if empty.
+	To            string `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSegmentStatsRequest) Reset() {
+	*x = GetSegmentStatsRequest{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSegmentStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSegmentStatsRequest) ProtoMessage() {}
+
+func (x *GetSegmentStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSegmentStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetSegmentStatsRequest) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *GetSegmentStatsRequest) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *GetSegmentStatsRequest) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *GetSegmentStatsRequest) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+type GetSegmentStatsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Slug  string                 `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	// Stored members, for a percentage rollout the explicitly added users.
+	Members    int64  `protobuf:"varint,2,opt,name=members,proto3" json:"members,omitempty"`
+	MaxMembers *int32 `protobuf:"varint,3,opt,name=max_members,json=maxMembers,proto3,oneof" json:"max_members,omitempty"`
+	From       string `protobuf:"bytes,4,opt,name=from,proto3" json:"from,omitempty"`
+	To         string `protobuf:"bytes,5,opt,name=to,proto3" json:"to,omitempty"`
+	// Every day of the range, the days without changes included.
+	Days          []*DailyStats `protobuf:"bytes,6,rep,name=days,proto3" json:"days,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSegmentStatsResponse) Reset() {
+	*x = GetSegmentStatsResponse{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSegmentStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSegmentStatsResponse) ProtoMessage() {}
+
+func (x *GetSegmentStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSegmentStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetSegmentStatsResponse) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *GetSegmentStatsResponse) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *GetSegmentStatsResponse) GetMembers() int64 {
+	if x != nil {
+		return x.Members
+	}
+	return 0
+}
+
+func (x *GetSegmentStatsResponse) GetMaxMembers() int32 {
+	if x != nil && x.MaxMembers != nil {
+		return *x.MaxMembers
+	}
+	return 0
+}
+
+func (x *GetSegmentStatsResponse) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *GetSegmentStatsResponse) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *GetSegmentStatsResponse) GetDays() []*DailyStats {
+	if x != nil {
+		return x.Days
+	}
+	return nil
+}
+
+type DailyStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Date          string                 `protobuf:"bytes,1,opt,name=date,proto3" json:"date,omitempty"`
+	Added         int64                  `protobuf:"varint,2,opt,name=added,proto3" json:"added,omitempty"`
+	Removed       int64                  `protobuf:"varint,3,opt,name=removed,proto3" json:"removed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DailyStats) Reset() {
+	*x = DailyStats{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DailyStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DailyStats) ProtoMessage() {}
+
+func (x *DailyStats) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DailyStats.ProtoReflect.Descriptor instead.
+func (*DailyStats) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *DailyStats) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *DailyStats) GetAdded() int64 {
+	if x != nil {
+		return x.Added
+	}
+	return 0
+}
+
+func (x *DailyStats) GetRemoved() int64 {
+	if x != nil {
+		return x.Removed
+	}
+	return 0
+}
+
+type GetServiceStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetServiceStatsRequest) Reset() {
+	*x = GetServiceStatsRequest{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetServiceStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetServiceStatsRequest) ProtoMessage() {}
+
+func (x *GetServiceStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetServiceStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetServiceStatsRequest) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{30}
+}
+
+type GetServiceStatsResponse struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Segments    int32                  `protobuf:"varint,1,opt,name=segments,proto3" json:"segments,omitempty"`
+	Memberships int64                  `protobuf:"varint,2,opt,name=memberships,proto3" json:"memberships,omitempty"`
+	Sizes       []*SegmentSize         `protobuf:"bytes,3,rep,name=sizes,proto3" json:"sizes,omitempty"`
+	// unset if the user segments cache is disabled
+	Cache         *CacheStats `protobuf:"bytes,4,opt,name=cache,proto3" json:"cache,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetServiceStatsResponse) Reset() {
+	*x = GetServiceStatsResponse{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetServiceStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetServiceStatsResponse) ProtoMessage() {}
+
+func (x *GetServiceStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetServiceStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetServiceStatsResponse) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *GetServiceStatsResponse) GetSegments() int32 {
+	if x != nil {
+		return x.Segments
+	}
+	return 0
+}
+
+func (x *GetServiceStatsResponse) GetMemberships() int64 {
+	if x != nil {
+		return x.Memberships
+	}
+	return 0
+}
+
+func (x *GetServiceStatsResponse) GetSizes() []*SegmentSize {
+	if x != nil {
+		return x.Sizes
+	}
+	return nil
+}
+
+func (x *GetServiceStatsResponse) GetCache() *CacheStats {
+	if x != nil {
+		return x.Cache
+	}
+	return nil
+}
+
+type CacheStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hits          uint64                 `protobuf:"varint,1,opt,name=hits,proto3" json:"hits,omitempty"`
+	Misses        uint64                 `protobuf:"varint,2,opt,name=misses,proto3" json:"misses,omitempty"`
+	Size          int64                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CacheStats) Reset() {
+	*x = CacheStats{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CacheStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CacheStats) ProtoMessage() {}
+
+func (x *CacheStats) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CacheStats.ProtoReflect.Descriptor instead.
+func (*CacheStats) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *CacheStats) GetHits() uint64 {
+	if x != nil {
+		return x.Hits
+	}
+	return 0
+}
+
+func (x *CacheStats) GetMisses() uint64 {
+	if x != nil {
+		return x.Misses
+	}
+	return 0
+}
+
+func (x *CacheStats) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+type SegmentSize struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Slug          string                 `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	Members       int64                  `protobuf:"varint,2,opt,name=members,proto3" json:"members,omitempty"`
+	MaxMembers    *int32                 `protobuf:"varint,3,opt,name=max_members,json=maxMembers,proto3,oneof" json:"max_members,omitempty"`
+	Rollout       *float64               `protobuf:"fixed64,4,opt,name=rollout,proto3,oneof" json:"rollout,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SegmentSize) Reset() {
+	*x = SegmentSize{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SegmentSize) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SegmentSize) ProtoMessage() {}
+
+func (x *SegmentSize) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SegmentSize.ProtoReflect.Descriptor instead.
+func (*SegmentSize) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *SegmentSize) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *SegmentSize) GetMembers() int64 {
+	if x != nil {
+		return x.Members
+	}
+	return 0
+}
+
+func (x *SegmentSize) GetMaxMembers() int32 {
+	if x != nil && x.MaxMembers != nil {
+		return *x.MaxMembers
+	}
+	return 0
+}
+
+func (x *SegmentSize) GetRollout() float64 {
+	if x != nil && x.Rollout != nil {
+		return *x.Rollout
+	}
+	return 0
+}
+
+type UpdateUserAttributesRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	UserId string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Strings, numbers, booleans or nulls.
+	Attributes    *structpb.Struct `protobuf:"bytes,2,opt,name=attributes,proto3" json:"attributes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateUserAttributesRequest) Reset() {
+	*x = UpdateUserAttributesRequest{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateUserAttributesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateUserAttributesRequest) ProtoMessage() {}
+
+func (x *UpdateUserAttributesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateUserAttributesRequest.ProtoReflect.Descriptor instead.
+func (*UpdateUserAttributesRequest) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *UpdateUserAttributesRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *UpdateUserAttributesRequest) GetAttributes() *structpb.Struct {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+type UpdateUserAttributesResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Attributes      *structpb.Struct       `protobuf:"bytes,1,opt,name=attributes,proto3" json:"attributes,omitempty"`
+	SegmentsAdded   []string               `protobuf:"bytes,2,rep,name=segments_added,json=segmentsAdded,proto3" json:"segments_added,omitempty"`
+	SegmentsRemoved []string               `protobuf:"bytes,3,rep,name=segments_removed,json=segmentsRemoved,proto3" json:"segments_removed,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *UpdateUserAttributesResponse) Reset() {
+	*x = UpdateUserAttributesResponse{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateUserAttributesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateUserAttributesResponse) ProtoMessage() {}
+
+func (x *UpdateUserAttributesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateUserAttributesResponse.ProtoReflect.Descriptor instead.
+func (*UpdateUserAttributesResponse) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *UpdateUserAttributesResponse) GetAttributes() *structpb.Struct {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+func (x *UpdateUserAttributesResponse) GetSegmentsAdded() []string {
+	if x != nil {
+		return x.SegmentsAdded
+	}
+	return nil
+}
+
+func (x *UpdateUserAttributesResponse) GetSegmentsRemoved() []string {
+	if x != nil {
+		return x.SegmentsRemoved
+	}
+	return nil
+}
+
+type GetUserAttributesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserAttributesRequest) Reset() {
+	*x = GetUserAttributesRequest{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserAttributesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserAttributesRequest) ProtoMessage() {}
+
+func (x *GetUserAttributesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserAttributesRequest.ProtoReflect.Descriptor instead.
+func (*GetUserAttributesRequest) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *GetUserAttributesRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type GetUserAttributesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Attributes    *structpb.Struct       `protobuf:"bytes,1,opt,name=attributes,proto3" json:"attributes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserAttributesResponse) Reset() {
+	*x = GetUserAttributesResponse{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserAttributesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserAttributesResponse) ProtoMessage() {}
+
+func (x *GetUserAttributesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserAttributesResponse.ProtoReflect.Descriptor instead.
+func (*GetUserAttributesResponse) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *GetUserAttributesResponse) GetAttributes() *structpb.Struct {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+type ExperimentGroup struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// The name of the group if not set.
+	Salt string `protobuf:"bytes,2,opt,name=salt,proto3" json:"salt,omitempty"`
+	// Either "reject" (default) or "move".
+	Conflict      string     `protobuf:"bytes,3,opt,name=conflict,proto3" json:"conflict,omitempty"`
+	Variants      []*Variant `protobuf:"bytes,4,rep,name=variants,proto3" json:"variants,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExperimentGroup) Reset() {
+	*x = ExperimentGroup{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExperimentGroup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExperimentGroup) ProtoMessage() {}
+
+func (x *ExperimentGroup) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExperimentGroup.ProtoReflect.Descriptor instead.
+func (*ExperimentGroup) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *ExperimentGroup) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ExperimentGroup) GetSalt() string {
+	if x != nil {
+		return x.Salt
+	}
+	return ""
+}
+
+func (x *ExperimentGroup) GetConflict() string {
+	if x != nil {
+		return x.Conflict
+	}
+	return ""
+}
+
+func (x *ExperimentGroup) GetVariants() []*Variant {
+	if x != nil {
+		return x.Variants
+	}
+	return nil
+}
+
+type Variant struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Slug          string                 `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	Weight        int32                  `protobuf:"varint,2,opt,name=weight,proto3" json:"weight,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Variant) Reset() {
+	*x = Variant{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Variant) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Variant) ProtoMessage() {}
+
+func (x *Variant) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Variant.ProtoReflect.Descriptor instead.
+func (*Variant) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *Variant) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *Variant) GetWeight() int32 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+type CreateExperimentGroupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Group         *ExperimentGroup       `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateExperimentGroupRequest) Reset() {
+	*x = CreateExperimentGroupRequest{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateExperimentGroupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateExperimentGroupRequest) ProtoMessage() {}
+
+func (x *CreateExperimentGroupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateExperimentGroupRequest.ProtoReflect.Descriptor instead.
+func (*CreateExperimentGroupRequest) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *CreateExperimentGroupRequest) GetGroup() *ExperimentGroup {
+	if x != nil {
+		return x.Group
+	}
+	return nil
+}
+
+type CreateExperimentGroupResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Group         *ExperimentGroup       `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateExperimentGroupResponse) Reset() {
+	*x = CreateExperimentGroupResponse{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateExperimentGroupResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateExperimentGroupResponse) ProtoMessage() {}
+
+func (x *CreateExperimentGroupResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateExperimentGroupResponse.ProtoReflect.Descriptor instead.
+func (*CreateExperimentGroupResponse) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *CreateExperimentGroupResponse) GetGroup() *ExperimentGroup {
+	if x != nil {
+		return x.Group
+	}
+	return nil
+}
+
+type DeleteExperimentGroupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteExperimentGroupRequest) Reset() {
+	*x = DeleteExperimentGroupRequest{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteExperimentGroupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteExperimentGroupRequest) ProtoMessage() {}
+
+func (x *DeleteExperimentGroupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteExperimentGroupRequest.ProtoReflect.Descriptor instead.
+func (*DeleteExperimentGroupRequest) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *DeleteExperimentGroupRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type DeleteExperimentGroupResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteExperimentGroupResponse) Reset() {
+	*x = DeleteExperimentGroupResponse{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteExperimentGroupResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteExperimentGroupResponse) ProtoMessage() {}
+
+func (x *DeleteExperimentGroupResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteExperimentGroupResponse.ProtoReflect.Descriptor instead.
+func (*DeleteExperimentGroupResponse) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{43}
+}
+
+type ListExperimentGroupsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListExperimentGroupsRequest) Reset() {
+	*x = ListExperimentGroupsRequest{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListExperimentGroupsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListExperimentGroupsRequest) ProtoMessage() {}
+
+func (x *ListExperimentGroupsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListExperimentGroupsRequest.ProtoReflect.Descriptor instead.
+func (*ListExperimentGroupsRequest) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{44}
+}
+
+type ListExperimentGroupsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Groups        []*ExperimentGroup     `protobuf:"bytes,1,rep,name=groups,proto3" json:"groups,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListExperimentGroupsResponse) Reset() {
+	*x = ListExperimentGroupsResponse{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListExperimentGroupsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListExperimentGroupsResponse) ProtoMessage() {}
+
+func (x *ListExperimentGroupsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListExperimentGroupsResponse.ProtoReflect.Descriptor instead.
+func (*ListExperimentGroupsResponse) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *ListExperimentGroupsResponse) GetGroups() []*ExperimentGroup {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+type AssignExperimentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Group         string                 `protobuf:"bytes,2,opt,name=group,proto3" json:"group,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AssignExperimentRequest) Reset() {
+	*x = AssignExperimentRequest{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AssignExperimentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AssignExperimentRequest) ProtoMessage() {}
+
+func (x *AssignExperimentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AssignExperimentRequest.ProtoReflect.Descriptor instead.
+func (*AssignExperimentRequest) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *AssignExperimentRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *AssignExperimentRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+type AssignExperimentResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Segment string                 `protobuf:"bytes,1,opt,name=segment,proto3" json:"segment,omitempty"`
+	// False if the user had already been in the variant.
+	Assigned      bool `protobuf:"varint,2,opt,name=assigned,proto3" json:"assigned,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AssignExperimentResponse) Reset() {
+	*x = AssignExperimentResponse{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AssignExperimentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AssignExperimentResponse) ProtoMessage() {}
+
+func (x *AssignExperimentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AssignExperimentResponse.ProtoReflect.Descriptor instead.
+func (*AssignExperimentResponse) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *AssignExperimentResponse) GetSegment() string {
+	if x != nil {
+		return x.Segment
+	}
+	return ""
+}
+
+func (x *AssignExperimentResponse) GetAssigned() bool {
+	if x != nil {
+		return x.Assigned
+	}
+	return false
+}
+
+type GetReportRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Month in the format 'yyyy-mm'.
+	Period string `protobuf:"bytes,1,opt,name=period,proto3" json:"period,omitempty"`
+	// Only the changes made by this actor.
+	Actor string `protobuf:"bytes,2,opt,name=actor,proto3" json:"actor,omitempty"`
+	// Only the changes from this source: api, import, ttl-reaper, auto-percent or rule.
+	Source string `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"`
+	// Only the changes whose reason contains this text, case-insensitively.
+	Reason        string `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetReportRequest) Reset() {
+	*x = GetReportRequest{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetReportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetReportRequest) ProtoMessage() {}
+
+func (x *GetReportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetReportRequest.ProtoReflect.Descriptor instead.
+func (*GetReportRequest) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *GetReportRequest) GetPeriod() string {
+	if x != nil {
+		return x.Period
+	}
+	return ""
+}
+
+func (x *GetReportRequest) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *GetReportRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *GetReportRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type GetUserReportRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Period        string                 `protobuf:"bytes,1,opt,name=period,proto3" json:"period,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Actor         string                 `protobuf:"bytes,3,opt,name=actor,proto3" json:"actor,omitempty"`
+	Source        string                 `protobuf:"bytes,4,opt,name=source,proto3" json:"source,omitempty"`
+	Reason        string                 `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserReportRequest) Reset() {
+	*x = GetUserReportRequest{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserReportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserReportRequest) ProtoMessage() {}
+
+func (x *GetUserReportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserReportRequest.ProtoReflect.Descriptor instead.
+func (*GetUserReportRequest) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *GetUserReportRequest) GetPeriod() string {
+	if x != nil {
+		return x.Period
+	}
+	return ""
+}
+
+func (x *GetUserReportRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *GetUserReportRequest) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *GetUserReportRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *GetUserReportRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type GetSegmentReportRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Month in the format 'yyyy-mm'.
+	Period        string `protobuf:"bytes,1,opt,name=period,proto3" json:"period,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSegmentReportRequest) Reset() {
+	*x = GetSegmentReportRequest{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSegmentReportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSegmentReportRequest) ProtoMessage() {}
+
+func (x *GetSegmentReportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSegmentReportRequest.ProtoReflect.Descriptor instead.
+func (*GetSegmentReportRequest) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *GetSegmentReportRequest) GetPeriod() string {
+	if x != nil {
+		return x.Period
+	}
+	return ""
+}
+
+type GetAggregateReportRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Month in the format 'yyyy-mm'.
+	Period        string `protobuf:"bytes,1,opt,name=period,proto3" json:"period,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAggregateReportRequest) Reset() {
+	*x = GetAggregateReportRequest{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAggregateReportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAggregateReportRequest) ProtoMessage() {}
+
+func (x *GetAggregateReportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAggregateReportRequest.ProtoReflect.Descriptor instead.
+func (*GetAggregateReportRequest) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *GetAggregateReportRequest) GetPeriod() string {
+	if x != nil {
+		return x.Period
+	}
+	return ""
+}
+
+type AggregateReportRow struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Segment string                 `protobuf:"bytes,1,opt,name=segment,proto3" json:"segment,omitempty"`
+	// Day in the format 'yyyy-mm-dd'.
+	Date          string `protobuf:"bytes,2,opt,name=date,proto3" json:"date,omitempty"`
+	Added         int64  `protobuf:"varint,3,opt,name=added,proto3" json:"added,omitempty"`
+	Removed       int64  `protobuf:"varint,4,opt,name=removed,proto3" json:"removed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AggregateReportRow) Reset() {
+	*x = AggregateReportRow{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AggregateReportRow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AggregateReportRow) ProtoMessage() {}
+
+func (x *AggregateReportRow) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AggregateReportRow.ProtoReflect.Descriptor instead.
+func (*AggregateReportRow) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *AggregateReportRow) GetSegment() string {
+	if x != nil {
+		return x.Segment
+	}
+	return ""
+}
+
+func (x *AggregateReportRow) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *AggregateReportRow) GetAdded() int64 {
+	if x != nil {
+		return x.Added
+	}
+	return 0
+}
+
+func (x *AggregateReportRow) GetRemoved() int64 {
+	if x != nil {
+		return x.Removed
+	}
+	return 0
+}
+
+type ReportRow struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	UserId  string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Segment string                 `protobuf:"bytes,2,opt,name=segment,proto3" json:"segment,omitempty"`
+	// Either "add" or "remove".
+	Action string `protobuf:"bytes,3,opt,name=action,proto3" json:"action,omitempty"`
+	// Time of the event in the format 'yyyy-mm-dd hh:mm:ss'.
+	Time string `protobuf:"bytes,4,opt,name=time,proto3" json:"time,omitempty"`
+	// Who made the change, through what and why, see the x-actor, x-source and x-reason metadata.
+	Actor         string `protobuf:"bytes,5,opt,name=actor,proto3" json:"actor,omitempty"`
+	Source        string `protobuf:"bytes,6,opt,name=source,proto3" json:"source,omitempty"`
+	Reason        string `protobuf:"bytes,7,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReportRow) Reset() {
+	*x = ReportRow{}
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReportRow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportRow) ProtoMessage() {}
+
+func (x *ReportRow) ProtoReflect() protoreflect.Message {
+	mi := &file_segmentation_v1_segmentation_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportRow.ProtoReflect.Descriptor instead.
+func (*ReportRow) Descriptor() ([]byte, []int) {
+	return file_segmentation_v1_segmentation_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *ReportRow) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ReportRow) GetSegment() string {
+	if x != nil {
+		return x.Segment
+	}
+	return ""
+}
+
+func (x *ReportRow) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *ReportRow) GetTime() string {
+	if x != nil {
+		return x.Time
+	}
+	return ""
+}
+
+func (x *ReportRow) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *ReportRow) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *ReportRow) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+var File_segmentation_v1_segmentation_proto protoreflect.FileDescriptor
+
+const file_segmentation_v1_segmentation_proto_rawDesc = "" +
+	"\n" +
+	"\"segmentation/v1/segmentation.proto\x12\x0fsegmentation.v1\x1a\x1cgoogle/protobuf/struct.proto\"\x97\x02\n" +
+	"\x14CreateSegmentRequest\x12\x12\n" +
+	"\x04slug\x18\x01 \x01(\tR\x04slug\x12\x12\n" +
+	"\x04rule\x18\x02 \x01(\tR\x04rule\x12\x1e\n" +
+	"\n" +
+	"expression\x18\x03 \x01(\tR\n" +
+	"expression\x12\x1d\n" +
+	"\arollout\x18\x04 \x01(\x01H\x00R\arollout\x88\x01\x01\x12\x12\n" +
+	"\x04salt\x18\x05 \x01(\tR\x04salt\x12\x1f\n" +
+	"\vactive_from\x18\x06 \x01(\tR\n" +
+	"activeFrom\x12!\n" +
+	"\factive_until\x18\a \x01(\tR\vactiveUntil\x12$\n" +
+	"\vmax_members\x18\b \x01(\x05H\x01R\n" +
+	"maxMembers\x88\x01\x01B\n" +
+	"\n" +
+	"\b_rolloutB\x0e\n" +
+	"\f_max_members\"\x17\n" +
+	"\x15CreateSegmentResponse\"*\n" +
+	"\x14DeleteSegmentRequest\x12\x12\n" +
+	"\x04slug\x18\x01 \x01(\tR\x04slug\"\x17\n" +
+	"\x15DeleteSegmentResponse\"x\n" +
+	"\x14RenameSegmentRequest\x12\x12\n" +
+	"\x04slug\x18\x01 \x01(\tR\x04slug\x12\x19\n" +
+	"\bnew_slug\x18\x02 \x01(\tR\anewSlug\x12\"\n" +
+	"\n" +
+	"alias_days\x18\x03 \x01(\x05H\x00R\taliasDays\x88\x01\x01B\r\n" +
+	"\v_alias_days\"`\n" +
+	"\x15RenameSegmentResponse\x12\x14\n" +
+	"\x05alias\x18\x01 \x01(\tR\x05alias\x12\x12\n" +
+	"\x04slug\x18\x02 \x01(\tR\x04slug\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\tR\texpiresAt\"?\n" +
+	"\x15SetSegmentRuleRequest\x12\x12\n" +
+	"\x04slug\x18\x01 \x01(\tR\x04slug\x12\x12\n" +
+	"\x04rule\x18\x02 \x01(\tR\x04rule\"H\n" +
+	"\x16SetSegmentRuleResponse\x12\x14\n" +
+	"\x05added\x18\x01 \x01(\x05R\x05added\x12\x18\n" +
+	"\aremoved\x18\x02 \x01(\x05R\aremoved\"Q\n" +
+	"\x1bSetSegmentExpressionRequest\x12\x12\n" +
+	"\x04slug\x18\x01 \x01(\tR\x04slug\x12\x1e\n" +
+	"\n" +
+	"expression\x18\x02 \x01(\tR\n" +
+	"expression\"N\n" +
+	"\x1cSetSegmentExpressionResponse\x12\x14\n" +
+	"\x05added\x18\x01 \x01(\x05R\x05added\x12\x18\n" +
+	"\aremoved\x18\x02 \x01(\x05R\aremoved\"m\n" +
+	"\x18SetSegmentRolloutRequest\x12\x12\n" +
+	"\x04slug\x18\x01 \x01(\tR\x04slug\x12\x1d\n" +
+	"\arollout\x18\x02 \x01(\x01H\x00R\arollout\x88\x01\x01\x12\x12\n" +
+	"\x04salt\x18\x03 \x01(\tR\x04saltB\n" +
+	"\n" +
+	"\b_rollout\"n\n" +
+	"\x19SetSegmentRolloutResponse\x12\x12\n" +
+	"\x04slug\x18\x01 \x01(\tR\x04slug\x12\x1d\n" +
+	"\arollout\x18\x02 \x01(\x01H\x00R\arollout\x88\x01\x01\x12\x12\n" +
+	"\x04salt\x18\x03 \x01(\tR\x04saltB\n" +
+	"\n" +
+	"\b_rollout\"q\n" +
+	"\x17SetSegmentWindowRequest\x12\x12\n" +
+	"\x04slug\x18\x01 \x01(\tR\x04slug\x12\x1f\n" +
+	"\vactive_from\x18\x02 \x01(\tR\n" +
+	"activeFrom\x12!\n" +
+	"\factive_until\x18\x03 \x01(\tR\vactiveUntil\"`\n" +
+	"\x14SetSegmentCapRequest\x12\x12\n" +
+	"\x04slug\x18\x01 \x01(\tR\x04slug\x12$\n" +
+	"\vmax_members\x18\x02 \x01(\x05H\x00R\n" +
+	"maxMembers\x88\x01\x01B\x0e\n" +
+	"\f_max_members\"\xdd\x01\n" +
+	"\fSegmentState\x12\x12\n" +
+	"\x04slug\x18\x01 \x01(\tR\x04slug\x12\x14\n" +
+	"\x05state\x18\x02 \x01(\tR\x05state\x12\x1f\n" +
+	"\vactive_from\x18\x03 \x01(\tR\n" +
+	"activeFrom\x12!\n" +
+	"\factive_until\x18\x04 \x01(\tR\vactiveUntil\x12$\n" +
+	"\vmax_members\x18\x05 \x01(\x05H\x00R\n" +
+	"maxMembers\x88\x01\x01\x12\x1d\n" +
+	"\amembers\x18\x06 \x01(\x05H\x01R\amembers\x88\x01\x01B\x0e\n" +
+	"\f_max_membersB\n" +
+	"\n" +
+	"\b_members\"\xc2\x01\n" +
+	"\x19UpdateUserSegmentsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12&\n" +
+	"\x0fsegments_to_add\x18\x02 \x03(\tR\rsegmentsToAdd\x12,\n" +
+	"\x12segments_to_remove\x18\x03 \x03(\tR\x10segmentsToRemove\x12\x17\n" +
+	"\adry_run\x18\x04 \x01(\bR\x06dryRun\x12\x1d\n" +
+	"\n" +
+	"non_strict\x18\x05 \x01(\bR\tnonStrict\"Y\n" +
+	"\rSegmentResult\x12\x18\n" +
+	"\asegment\x18\x01 \x01(\tR\asegment\x12\x16\n" +
+	"\x06action\x18\x02 \x01(\tR\x06action\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\"\xce\x01\n" +
+	"\x1aUpdateUserSegmentsResponse\x12\x17\n" +
+	"\adry_run\x18\x01 \x01(\bR\x06dryRun\x12\x14\n" +
+	"\x05added\x18\x02 \x03(\tR\x05added\x12\x18\n" +
+	"\aremoved\x18\x03 \x03(\tR\aremoved\x12\x13\n" +
+	"\x05no_op\x18\x04 \x03(\tR\x04noOp\x12\x18\n" +
+	"\aunknown\x18\x05 \x03(\tR\aunknown\x128\n" +
+	"\aresults\x18\x06 \x03(\v2\x1e.segmentation.v1.SegmentResultR\aresults\"1\n" +
+	"\x16GetUserSegmentsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"5\n" +
+	"\x17GetUserSegmentsResponse\x12\x1a\n" +
+	"\bsegments\x18\x01 \x03(\tR\bsegments\"\x15\n" +
+	"\x13ListSegmentsRequest\"i\n" +
+	"\x14ListSegmentsResponse\x12\x1a\n" +
+	"\bsegments\x18\x01 \x03(\tR\bsegments\x125\n" +
+	"\x06states\x18\x02 \x03(\v2\x1d.segmentation.v1.SegmentStateR\x06states\"Z\n" +
+	"\x18GetSegmentMembersRequest\x12\x12\n" +
+	"\x04slug\x18\x01 \x01(\tR\x04slug\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x14\n" +
+	"\x05after\x18\x03 \x01(\tR\x05after\"I\n" +
+	"\x19GetSegmentMembersResponse\x12\x18\n" +
+	"\amembers\x18\x01 \x03(\tR\amembers\x12\x12\n" +
+	"\x04next\x18\x02 \x01(\tR\x04next\".\n" +
+	"\x18GetSegmentHistoryRequest\x12\x12\n" +
+	"\x04slug\x18\x01 \x01(\tR\x04slug\"f\n" +
+	"\x19GetSegmentHistoryResponse\x12\x12\n" +
+	"\x04slug\x18\x01 \x01(\tR\x04slug\x125\n" +
+	"\x06events\x18\x02 \x03(\v2\x1d.segmentation.v1.SegmentEventR\x06events\"\xd2\x01\n" +
+	"\fSegmentEvent\x12\x18\n" +
+	"\asegment\x18\x01 \x01(\tR\asegment\x12\x14\n" +
+	"\x05event\x18\x02 \x01(\tR\x05event\x12\x1b\n" +
+	"\told_value\x18\x03 \x01(\tR\boldValue\x12\x1b\n" +
+	"\tnew_value\x18\x04 \x01(\tR\bnewValue\x12\x12\n" +
+	"\x04time\x18\x05 \x01(\tR\x04time\x12\x14\n" +
+	"\x05actor\x18\x06 \x01(\tR\x05actor\x12\x16\n" +
+	"\x06source\x18\a \x01(\tR\x06source\x12\x16\n" +
+	"\x06reason\x18\b \x01(\tR\x06reason\"P\n" +
+	"\x16GetSegmentStatsRequest\x12\x12\n" +
+	"\x04slug\x18\x01 \x01(\tR\x04slug\x12\x12\n" +
+	"\x04from\x18\x02 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x03 \x01(\tR\x02to\"\xd2\x01\n" +
+	"\x17GetSegmentStatsResponse\x12\x12\n" +
+	"\x04slug\x18\x01 \x01(\tR\x04slug\x12\x18\n" +
+	"\amembers\x18\x02 \x01(\x03R\amembers\x12$\n" +
+	"\vmax_members\x18\x03 \x01(\x05H\x00R\n" +
+	"maxMembers\x88\x01\x01\x12\x12\n" +
+	"\x04from\x18\x04 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x05 \x01(\tR\x02to\x12/\n" +
+	"\x04days\x18\x06 \x03(\v2\x1b.segmentation.v1.DailyStatsR\x04daysB\x0e\n" +
+	"\f_max_members\"P\n" +
+	"\n" +
+	"DailyStats\x12\x12\n" +
+	"\x04date\x18\x01 \x01(\tR\x04date\x12\x14\n" +
+	"\x05added\x18\x02 \x01(\x03R\x05added\x12\x18\n" +
+	"\aremoved\x18\x03 \x01(\x03R\aremoved\"\x18\n" +
+	"\x16GetServiceStatsRequest\"\xbe\x01\n" +
+	"\x17GetServiceStatsResponse\x12\x1a\n" +
+	"\bsegments\x18\x01 \x01(\x05R\bsegments\x12 \n" +
+	"\vmemberships\x18\x02 \x01(\x03R\vmemberships\x122\n" +
+	"\x05sizes\x18\x03 \x03(\v2\x1c.segmentation.v1.SegmentSizeR\x05sizes\x121\n" +
+	"\x05cache\x18\x04 \x01(\v2\x1b.segmentation.v1.CacheStatsR\x05cache\"L\n" +
+	"\n" +
+	"CacheStats\x12\x12\n" +
+	"\x04hits\x18\x01 \x01(\x04R\x04hits\x12\x16\n" +
+	"\x06misses\x18\x02 \x01(\x04R\x06misses\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x03R\x04size\"\x9c\x01\n" +
+	"\vSegmentSize\x12\x12\n" +
+	"\x04slug\x18\x01 \x01(\tR\x04slug\x12\x18\n" +
+	"\amembers\x18\x02 \x01(\x03R\amembers\x12$\n" +
+	"\vmax_members\x18\x03 \x01(\x05H\x00R\n" +
+	"maxMembers\x88\x01\x01\x12\x1d\n" +
+	"\arollout\x18\x04 \x01(\x01H\x01R\arollout\x88\x01\x01B\x0e\n" +
+	"\f_max_membersB\n" +
+	"\n" +
+	"\b_rollout\"o\n" +
+	"\x1bUpdateUserAttributesRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x127\n" +
+	"\n" +
+	"attributes\x18\x02 \x01(\v2\x17.google.protobuf.StructR\n" +
+	"attributes\"\xa9\x01\n" +
+	"\x1cUpdateUserAttributesResponse\x127\n" +
+	"\n" +
+	"attributes\x18\x01 \x01(\v2\x17.google.protobuf.StructR\n" +
+	"attributes\x12%\n" +
+	"\x0esegments_added\x18\x02 \x03(\tR\rsegmentsAdded\x12)\n" +
+	"\x10segments_removed\x18\x03 \x03(\tR\x0fsegmentsRemoved\"3\n" +
+	"\x18GetUserAttributesRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"T\n" +
+	"\x19GetUserAttributesResponse\x127\n" +
+	"\n" +
+	"attributes\x18\x01 \x01(\v2\x17.google.protobuf.StructR\n" +
+	"attributes\"\x8b\x01\n" +
+	"\x0fExperimentGroup\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04salt\x18\x02 \x01(\tR\x04salt\x12\x1a\n" +
+	"\bconflict\x18\x03 \x01(\tR\bconflict\x124\n" +
+	"\bvariants\x18\x04 \x03(\v2\x18.segmentation.v1.VariantR\bvariants\"5\n" +
+	"\aVariant\x12\x12\n" +
+	"\x04slug\x18\x01 \x01(\tR\x04slug\x12\x16\n" +
+	"\x06weight\x18\x02 \x01(\x05R\x06weight\"V\n" +
+	"\x1cCreateExperimentGroupRequest\x126\n" +
+	"\x05group\x18\x01 \x01(\v2 .segmentation.v1.ExperimentGroupR\x05group\"W\n" +
+	"\x1dCreateExperimentGroupResponse\x126\n" +
+	"\x05group\x18\x01 \x01(\v2 .segmentation.v1.ExperimentGroupR\x05group\"2\n" +
+	"\x1cDeleteExperimentGroupRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"\x1f\n" +
+	"\x1dDeleteExperimentGroupResponse\"\x1d\n" +
+	"\x1bListExperimentGroupsRequest\"X\n" +
+	"\x1cListExperimentGroupsResponse\x128\n" +
+	"\x06groups\x18\x01 \x03(\v2 .segmentation.v1.ExperimentGroupR\x06groups\"H\n" +
+	"\x17AssignExperimentRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05group\x18\x02 \x01(\tR\x05group\"P\n" +
+	"\x18AssignExperimentResponse\x12\x18\n" +
+	"\asegment\x18\x01 \x01(\tR\asegment\x12\x1a\n" +
+	"\bassigned\x18\x02 \x01(\bR\bassigned\"p\n" +
+	"\x10GetReportRequest\x12\x16\n" +
+	"\x06period\x18\x01 \x01(\tR\x06period\x12\x14\n" +
+	"\x05actor\x18\x02 \x01(\tR\x05actor\x12\x16\n" +
+	"\x06source\x18\x03 \x01(\tR\x06source\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\"\x8d\x01\n" +
+	"\x14GetUserReportRequest\x12\x16\n" +
+	"\x06period\x18\x01 \x01(\tR\x06period\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05actor\x18\x03 \x01(\tR\x05actor\x12\x16\n" +
+	"\x06source\x18\x04 \x01(\tR\x06source\x12\x16\n" +
+	"\x06reason\x18\x05 \x01(\tR\x06reason\"1\n" +
+	"\x17GetSegmentReportRequest\x12\x16\n" +
+	"\x06period\x18\x01 \x01(\tR\x06period\"3\n" +
+	"\x19GetAggregateReportRequest\x12\x16\n" +
+	"\x06period\x18\x01 \x01(\tR\x06period\"r\n" +
+	"\x12AggregateReportRow\x12\x18\n" +
+	"\asegment\x18\x01 \x01(\tR\asegment\x12\x12\n" +
+	"\x04date\x18\x02 \x01(\tR\x04date\x12\x14\n" +
+	"\x05added\x18\x03 \x01(\x03R\x05added\x12\x18\n" +
+	"\aremoved\x18\x04 \x01(\x03R\aremoved\"\xb0\x01\n" +
+	"\tReportRow\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x18\n" +
+	"\asegment\x18\x02 \x01(\tR\asegment\x12\x16\n" +
+	"\x06action\x18\x03 \x01(\tR\x06action\x12\x12\n" +
+	"\x04time\x18\x04 \x01(\tR\x04time\x12\x14\n" +
+	"\x05actor\x18\x05 \x01(\tR\x05actor\x12\x16\n" +
+	"\x06source\x18\x06 \x01(\tR\x06source\x12\x16\n" +
+	"\x06reason\x18\a \x01(\tR\x06reason2\x9e\x14\n" +
+	"\x13SegmentationService\x12^\n" +
+	"\rCreateSegment\x12%.segmentation.v1.CreateSegmentRequest\x1a&.segmentation.v1.CreateSegmentResponse\x12^\n" +
+	"\rDeleteSegment\x12%.segmentation.v1.DeleteSegmentRequest\x1a&.segmentation.v1.DeleteSegmentResponse\x12^\n" +
+	"\rRenameSegment\x12%.segmentation.v1.RenameSegmentRequest\x1a&.segmentation.v1.RenameSegmentResponse\x12a\n" +
+	"\x0eSetSegmentRule\x12&.segmentation.v1.SetSegmentRuleRequest\x1a'.segmentation.v1.SetSegmentRuleResponse\x12s\n" +
+	"\x14SetSegmentExpression\x12,.segmentation.v1.SetSegmentExpressionRequest\x1a-.segmentation.v1.SetSegmentExpressionResponse\x12j\n" +
+	"\x11SetSegmentRollout\x12).segmentation.v1.SetSegmentRolloutRequest\x1a*.segmentation.v1.SetSegmentRolloutResponse\x12[\n" +
+	"\x10SetSegmentWindow\x12(.segmentation.v1.SetSegmentWindowRequest\x1a\x1d.segmentation.v1.SegmentState\x12U\n" +
+	"\rSetSegmentCap\x12%.segmentation.v1.SetSegmentCapRequest\x1a\x1d.segmentation.v1.SegmentState\x12m\n" +
+	"\x12UpdateUserSegments\x12*.segmentation.v1.UpdateUserSegmentsRequest\x1a+.segmentation.v1.UpdateUserSegmentsResponse\x12d\n" +
+	"\x0fGetUserSegments\x12'.segmentation.v1.GetUserSegmentsRequest\x1a(.segmentation.v1.GetUserSegmentsResponse\x12[\n" +
+	"\fListSegments\x12$.segmentation.v1.ListSegmentsRequest\x1a%.segmentation.v1.ListSegmentsResponse\x12j\n" +
+	"\x11GetSegmentMembers\x12).segmentation.v1.GetSegmentMembersRequest\x1a*.segmentation.v1.GetSegmentMembersResponse\x12j\n" +
+	"\x11GetSegmentHistory\x12).segmentation.v1.GetSegmentHistoryRequest\x1a*.segmentation.v1.GetSegmentHistoryResponse\x12d\n" +
+	"\x0fGetSegmentStats\x12'.segmentation.v1.GetSegmentStatsRequest\x1a(.segmentation.v1.GetSegmentStatsResponse\x12d\n" +
+	"\x0fGetServiceStats\x12'.segmentation.v1.GetServiceStatsRequest\x1a(.segmentation.v1.GetServiceStatsResponse\x12s\n" +
+	"\x14UpdateUserAttributes\x12,.segmentation.v1.UpdateUserAttributesRequest\x1a-.segmentation.v1.UpdateUserAttributesResponse\x12j\n" +
+	"\x11GetUserAttributes\x12).segmentation.v1.GetUserAttributesRequest\x1a*.segmentation.v1.GetUserAttributesResponse\x12v\n" +
+	"\x15CreateExperimentGroup\x12-.segmentation.v1.CreateExperimentGroupRequest\x1a..segmentation.v1.CreateExperimentGroupResponse\x12v\n" +
+	"\x15DeleteExperimentGroup\x12-.segmentation.v1.DeleteExperimentGroupRequest\x1a..segmentation.v1.DeleteExperimentGroupResponse\x12s\n" +
+	"\x14ListExperimentGroups\x12,.segmentation.v1.ListExperimentGroupsRequest\x1a-.segmentation.v1.ListExperimentGroupsResponse\x12g\n" +
+	"\x10AssignExperiment\x12(.segmentation.v1.AssignExperimentRequest\x1a).segmentation.v1.AssignExperimentResponse\x12L\n" +
+	"\tGetReport\x12!.segmentation.v1.GetReportRequest\x1a\x1a.segmentation.v1.ReportRow0\x01\x12T\n" +
+	"\rGetUserReport\x12%.segmentation.v1.GetUserReportRequest\x1a\x1a.segmentation.v1.ReportRow0\x01\x12]\n" +
+	"\x10GetSegmentReport\x12(.segmentation.v1.GetSegmentReportRequest\x1a\x1d.segmentation.v1.SegmentEvent0\x01\x12g\n" +
+	"\x12GetAggregateReport\x12*.segmentation.v1.GetAggregateReportRequest\x1a#.segmentation.v1.AggregateReportRow0\x01B?Z=segmentation-service/api/proto/segmentation/v1;segmentationv1b\x06proto3"
+
+var (
+	file_segmentation_v1_segmentation_proto_rawDescOnce sync.Once
+	file_segmentation_v1_segmentation_proto_rawDescData []byte
+)
+
+func file_segmentation_v1_segmentation_proto_rawDescGZIP() []byte {
+	file_segmentation_v1_segmentation_proto_rawDescOnce.Do(func() {
+		file_segmentation_v1_segmentation_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_segmentation_v1_segmentation_proto_rawDesc), len(file_segmentation_v1_segmentation_proto_rawDesc)))
+	})
+	return file_segmentation_v1_segmentation_proto_rawDescData
+}
+
+var file_segmentation_v1_segmentation_proto_msgTypes = make([]protoimpl.MessageInfo, 54)
+var file_segmentation_v1_segmentation_proto_goTypes = []any{
+	(*CreateSegmentRequest)(nil),          // 0: segmentation.v1.CreateSegmentRequest
+	(*CreateSegmentResponse)(nil),         // 1: segmentation.v1.CreateSegmentResponse
+	(*DeleteSegmentRequest)(nil),          // 2: segmentation.v1.DeleteSegmentRequest
+	(*DeleteSegmentResponse)(nil),         // 3: segmentation.v1.DeleteSegmentResponse
+	(*RenameSegmentRequest)(nil),          // 4: segmentation.v1.RenameSegmentRequest
+	(*RenameSegmentResponse)(nil),         // 5: segmentation.v1.RenameSegmentResponse
+	(*SetSegmentRuleRequest)(nil),         // 6: segmentation.v1.SetSegmentRuleRequest
+	(*SetSegmentRuleResponse)(nil),        // 7: segmentation.v1.SetSegmentRuleResponse
+	(*SetSegmentExpressionRequest)(nil),   // 8: segmentation.v1.SetSegmentExpressionRequest
+	(*SetSegmentExpressionResponse)(nil),  // 9: segmentation.v1.SetSegmentExpressionResponse
+	(*SetSegmentRolloutRequest)(nil),      // 10: segmentation.v1.SetSegmentRolloutRequest
+	(*SetSegmentRolloutResponse)(nil),     // 11: segmentation.v1.SetSegmentRolloutResponse
+	(*SetSegmentWindowRequest)(nil),       // 12: segmentation.v1.SetSegmentWindowRequest
+	(*SetSegmentCapRequest)(nil),          // 13: segmentation.v1.SetSegmentCapRequest
+	(*SegmentState)(nil),                  // 14: segmentation.v1.SegmentState
+	(*UpdateUserSegmentsRequest)(nil),     // 15: segmentation.v1.UpdateUserSegmentsRequest
+	(*SegmentResult)(nil),                 // 16: segmentation.v1.SegmentResult
+	(*UpdateUserSegmentsResponse)(nil),    // 17: segmentation.v1.UpdateUserSegmentsResponse
+	(*GetUserSegmentsRequest)(nil),        // 18: segmentation.v1.GetUserSegmentsRequest
+	(*GetUserSegmentsResponse)(nil),       // 19: segmentation.v1.GetUserSegmentsResponse
+	(*ListSegmentsRequest)(nil),           // 20: segmentation.v1.ListSegmentsRequest
+	(*ListSegmentsResponse)(nil),          // 21: segmentation.v1.ListSegmentsResponse
+	(*GetSegmentMembersRequest)(nil),      // 22: segmentation.v1.GetSegmentMembersRequest
+	(*GetSegmentMembersResponse)(nil),     // 23: segmentation.v1.GetSegmentMembersResponse
+	(*GetSegmentHistoryRequest)(nil),      // 24: segmentation.v1.GetSegmentHistoryRequest
+	(*GetSegmentHistoryResponse)(nil),     // 25: segmentation.v1.GetSegmentHistoryResponse
+	(*SegmentEvent)(nil),                  // 26: segmentation.v1.SegmentEvent
+	(*GetSegmentStatsRequest)(nil),        // 27: segmentation.v1.GetSegmentStatsRequest
+	(*GetSegmentStatsResponse)(nil),       // 28: segmentation.v1.GetSegmentStatsResponse
+	(*DailyStats)(nil),                    // 29: segmentation.v1.DailyStats
+	(*GetServiceStatsRequest)(nil),        // 30: segmentation.v1.GetServiceStatsRequest
+	(*GetServiceStatsResponse)(nil),       // 31: segmentation.v1.GetServiceStatsResponse
+	(*CacheStats)(nil),                    // 32: segmentation.v1.CacheStats
+	(*SegmentSize)(nil),                   // 33: segmentation.v1.SegmentSize
+	(*UpdateUserAttributesRequest)(nil),   // 34: segmentation.v1.UpdateUserAttributesRequest
+	(*UpdateUserAttributesResponse)(nil),  // 35: segmentation.v1.UpdateUserAttributesResponse
+	(*GetUserAttributesRequest)(nil),      // 36: segmentation.v1.GetUserAttributesRequest
+	(*GetUserAttributesResponse)(nil),     // 37: segmentation.v1.GetUserAttributesResponse
+	(*ExperimentGroup)(nil),               // 38: segmentation.v1.ExperimentGroup
+	(*Variant)(nil),                       // 39: segmentation.v1.Variant
+	(*CreateExperimentGroupRequest)(nil),  // 40: segmentation.v1.CreateExperimentGroupRequest
+	(*CreateExperimentGroupResponse)(nil), // 41: segmentation.v1.CreateExperimentGroupResponse
+	(*DeleteExperimentGroupRequest)(nil),  // 42: segmentation.v1.DeleteExperimentGroupRequest
+	(*DeleteExperimentGroupResponse)(nil), // 43: segmentation.v1.DeleteExperimentGroupResponse
+	(*ListExperimentGroupsRequest)(nil),   // 44: segmentation.v1.ListExperimentGroupsRequest
+	(*ListExperimentGroupsResponse)(nil),  // 45: segmentation.v1.ListExperimentGroupsResponse
+	(*AssignExperimentRequest)(nil),       // 46: segmentation.v1.AssignExperimentRequest
+	(*AssignExperimentResponse)(nil),      // 47: segmentation.v1.AssignExperimentResponse
+	(*GetReportRequest)(nil),              // 48: segmentation.v1.GetReportRequest
+	(*GetUserReportRequest)(nil),          // 49: segmentation.v1.GetUserReportRequest
+	(*GetSegmentReportRequest)(nil),       // 50: segmentation.v1.GetSegmentReportRequest
+	(*GetAggregateReportRequest)(nil),     // 51: segmentation.v1.GetAggregateReportRequest
+	(*AggregateReportRow)(nil),            // 52: segmentation.v1.AggregateReportRow
+	(*ReportRow)(nil),                     // 53: segmentation.v1.ReportRow
+	(*structpb.Struct)(nil),               // 54: google.protobuf.Struct
+}
+var file_segmentation_v1_segmentation_proto_depIdxs = []int32{
+	16, // 0: segmentation.v1.UpdateUserSegmentsResponse.results:type_name -> segmentation.v1.SegmentResult
+	14, // 1: segmentation.v1.ListSegmentsResponse.states:type_name -> segmentation.v1.SegmentState
+	26, // 2: segmentation.v1.GetSegmentHistoryResponse.events:type_name -> segmentation.v1.SegmentEvent
+	29, // 3: segmentation.v1.GetSegmentStatsResponse.days:type_name -> segmentation.v1.DailyStats
+	33, // 4: segmentation.v1.GetServiceStatsResponse.sizes:type_name -> segmentation.v1.SegmentSize
+	32, // 5: segmentation.v1.GetServiceStatsResponse.cache:type_name -> segmentation.v1.CacheStats
+	54, // 6: segmentation.v1.UpdateUserAttributesRequest.attributes:type_name -> google.protobuf.Struct
+	54, // 7: segmentation.v1.UpdateUserAttributesResponse.attributes:type_name -> google.protobuf.Struct
+	54, // 8: segmentation.v1.GetUserAttributesResponse.attributes:type_name -> google.protobuf.Struct
+	39, // 9: segmentation.v1.ExperimentGroup.variants:type_name -> segmentation.v1.Variant
+	38, // 10: segmentation.v1.CreateExperimentGroupRequest.group:type_name -> segmentation.v1.ExperimentGroup
+	38, // 11: segmentation.v1.CreateExperimentGroupResponse.group:type_name -> segmentation.v1.ExperimentGroup
+	38, // 12: segmentation.v1.ListExperimentGroupsResponse.groups:type_name -> segmentation.v1.ExperimentGroup
+	0,  // 13: segmentation.v1.SegmentationService.CreateSegment:input_type -> segmentation.v1.CreateSegmentRequest
+	2,  // 14: segmentation.v1.SegmentationService.DeleteSegment:input_type -> segmentation.v1.DeleteSegmentRequest
+	4,  // 15: segmentation.v1.SegmentationService.RenameSegment:input_type -> segmentation.v1.RenameSegmentRequest
+	6,  // 16: segmentation.v1.SegmentationService.SetSegmentRule:input_type -> segmentation.v1.SetSegmentRuleRequest
+	8,  // 17: segmentation.v1.SegmentationService.SetSegmentExpression:input_type -> segmentation.v1.SetSegmentExpressionRequest
+	10, // 18: segmentation.v1.SegmentationService.SetSegmentRollout:input_type -> segmentation.v1.SetSegmentRolloutRequest
+	12, // 19: segmentation.v1.SegmentationService.SetSegmentWindow:input_type -> segmentation.v1.SetSegmentWindowRequest
+	13, // 20: segmentation.v1.SegmentationService.SetSegmentCap:input_type -> segmentation.v1.SetSegmentCapRequest
+	15, // 21: segmentation.v1.SegmentationService.UpdateUserSegments:input_type -> segmentation.v1.UpdateUserSegmentsRequest
+	18, // 22: segmentation.v1.SegmentationService.GetUserSegments:input_type -> segmentation.v1.GetUserSegmentsRequest
+	20, // 23: segmentation.v1.SegmentationService.ListSegments:input_type -> segmentation.v1.ListSegmentsRequest
+	22, // 24: segmentation.v1.SegmentationService.GetSegmentMembers:input_type -> segmentation.v1.GetSegmentMembersRequest
+	24, // 25: segmentation.v1.SegmentationService.GetSegmentHistory:input_type -> segmentation.v1.GetSegmentHistoryRequest
+	27, // 26: segmentation.v1.SegmentationService.GetSegmentStats:input_type -> segmentation.v1.GetSegmentStatsRequest
+	30, // 27: segmentation.v1.SegmentationService.GetServiceStats:input_type -> segmentation.v1.GetServiceStatsRequest
+	34, // 28: segmentation.v1.SegmentationService.UpdateUserAttributes:input_type -> segmentation.v1.UpdateUserAttributesRequest
+	36, // 29: segmentation.v1.SegmentationService.GetUserAttributes:input_type -> segmentation.v1.GetUserAttributesRequest
+	40, // 30: segmentation.v1.SegmentationService.CreateExperimentGroup:input_type -> segmentation.v1.CreateExperimentGroupRequest
+	42, // 31: segmentation.v1.SegmentationService.DeleteExperimentGroup:input_type -> segmentation.v1.DeleteExperimentGroupRequest
+	44, // 32: segmentation.v1.SegmentationService.ListExperimentGroups:input_type -> segmentation.v1.ListExperimentGroupsRequest
+	46, // 33: segmentation.v1.SegmentationService.AssignExperiment:input_type -> segmentation.v1.AssignExperimentRequest
+	48, // 34: segmentation.v1.SegmentationService.GetReport:input_type -> segmentation.v1.GetReportRequest
+	49, // 35: segmentation.v1.SegmentationService.GetUserReport:input_type -> segmentation.v1.GetUserReportRequest
+	50, // 36: segmentation.v1.SegmentationService.GetSegmentReport:input_type -> segmentation.v1.GetSegmentReportRequest
+	51, // 37: segmentation.v1.SegmentationService.GetAggregateReport:input_type -> segmentation.v1.GetAggregateReportRequest
+	1,  // 38: segmentation.v1.SegmentationService.CreateSegment:output_type -> segmentation.v1.CreateSegmentResponse
+	3,  // 39: segmentation.v1.SegmentationService.DeleteSegment:output_type -> segmentation.v1.DeleteSegmentResponse
+	5,  // 40: segmentation.v1.SegmentationService.RenameSegment:output_type -> segmentation.v1.RenameSegmentResponse
+	7,  // 41: segmentation.v1.SegmentationService.SetSegmentRule:output_type -> segmentation.v1.SetSegmentRuleResponse
+	9,  // 42: segmentation.v1.SegmentationService.SetSegmentExpression:output_type -> segmentation.v1.SetSegmentExpressionResponse
+	11, // 43: segmentation.v1.SegmentationService.SetSegmentRollout:output_type -> segmentation.v1.SetSegmentRolloutResponse
+	14, // 44: segmentation.v1.SegmentationService.SetSegmentWindow:output_type -> segmentation.v1.SegmentState
+	14, // 45: segmentation.v1.SegmentationService.SetSegmentCap:output_type -> segmentation.v1.SegmentState
+	17, // 46: segmentation.v1.SegmentationService.UpdateUserSegments:output_type -> segmentation.v1.UpdateUserSegmentsResponse
+	19, // 47: segmentation.v1.SegmentationService.GetUserSegments:output_type -> segmentation.v1.GetUserSegmentsResponse
+	21, // 48: segmentation.v1.SegmentationService.ListSegments:output_type -> segmentation.v1.ListSegmentsResponse
+	23, // 49: segmentation.v1.SegmentationService.GetSegmentMembers:output_type -> segmentation.v1.GetSegmentMembersResponse
+	25, // 50: segmentation.v1.SegmentationService.GetSegmentHistory:output_type -> segmentation.v1.GetSegmentHistoryResponse
+	28, // 51: segmentation.v1.SegmentationService.GetSegmentStats:output_type -> segmentation.v1.GetSegmentStatsResponse
+	31, // 52: segmentation.v1.SegmentationService.GetServiceStats:output_type -> segmentation.v1.GetServiceStatsResponse
+	35, // 53: segmentation.v1.SegmentationService.UpdateUserAttributes:output_type -> segmentation.v1.UpdateUserAttributesResponse
+	37, // 54: segmentation.v1.SegmentationService.GetUserAttributes:output_type -> segmentation.v1.GetUserAttributesResponse
+	41, // 55: segmentation.v1.SegmentationService.CreateExperimentGroup:output_type -> segmentation.v1.CreateExperimentGroupResponse
+	43, // 56: segmentation.v1.SegmentationService.DeleteExperimentGroup:output_type -> segmentation.v1.DeleteExperimentGroupResponse
+	45, // 57: segmentation.v1.SegmentationService.ListExperimentGroups:output_type -> segmentation.v1.ListExperimentGroupsResponse
+	47, // 58: segmentation.v1.SegmentationService.AssignExperiment:output_type -> segmentation.v1.AssignExperimentResponse
+	53, // 59: segmentation.v1.SegmentationService.GetReport:output_type -> segmentation.v1.ReportRow
+	53, // 60: segmentation.v1.SegmentationService.GetUserReport:output_type -> segmentation.v1.ReportRow
+	26, // 61: segmentation.v1.SegmentationService.GetSegmentReport:output_type -> segmentation.v1.SegmentEvent
+	52, // 62: segmentation.v1.SegmentationService.GetAggregateReport:output_type -> segmentation.v1.AggregateReportRow
+	38, // [38:63] is the sub-list for method output_type
+	13, // [13:38] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
+}
+
+func init() { file_segmentation_v1_segmentation_proto_init() }
+func file_segmentation_v1_segmentation_proto_init() {
+	if File_segmentation_v1_segmentation_proto != nil {
+		return
+	}
+	file_segmentation_v1_segmentation_proto_msgTypes[0].OneofWrappers = []any{}
+	file_segmentation_v1_segmentation_proto_msgTypes[4].OneofWrappers = []any{}
+	file_segmentation_v1_segmentation_proto_msgTypes[10].OneofWrappers = []any{}
+	file_segmentation_v1_segmentation_proto_msgTypes[11].OneofWrappers = []any{}
+	file_segmentation_v1_segmentation_proto_msgTypes[13].OneofWrappers = []any{}
+	file_segmentation_v1_segmentation_proto_msgTypes[14].OneofWrappers = []any{}
+	file_segmentation_v1_segmentation_proto_msgTypes[28].OneofWrappers = []any{}
+	file_segmentation_v1_segmentation_proto_msgTypes[33].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_segmentation_v1_segmentation_proto_rawDesc), len(file_segmentation_v1_segmentation_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   54,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_segmentation_v1_segmentation_proto_goTypes,
+		DependencyIndexes: file_segmentation_v1_segmentation_proto_depIdxs,
+		MessageInfos:      file_segmentation_v1_segmentation_proto_msgTypes,
+	}.Build()
+	File_segmentation_v1_segmentation_proto = out.File
+	file_segmentation_v1_segmentation_proto_goTypes = nil
+	file_segmentation_v1_segmentation_proto_depIdxs = nil
+}
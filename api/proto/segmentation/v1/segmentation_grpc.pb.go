@@ -0,0 +1,1105 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: segmentation/v1/segmentation.proto
+
+// Typed binary API of the user segmentation service for service-to-service calls.
+// Every operation mirrors the corresponding method of ports.SegmentService and the http API.
+
+package segmentationv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	SegmentationService_CreateSegment_FullMethodName         = "/segmentation.v1.SegmentationService/CreateSegment"
+	SegmentationService_DeleteSegment_FullMethodName         = "/segmentation.v1.SegmentationService/DeleteSegment"
+	SegmentationService_RenameSegment_FullMethodName         = "/segmentation.v1.SegmentationService/RenameSegment"
+	SegmentationService_SetSegmentRule_FullMethodName        = "/segmentation.v1.SegmentationService/SetSegmentRule"
+	SegmentationService_SetSegmentExpression_FullMethodName  = "/segmentation.v1.SegmentationService/SetSegmentExpression"
+	SegmentationService_SetSegmentRollout_FullMethodName     = "/segmentation.v1.SegmentationService/SetSegmentRollout"
+	SegmentationService_SetSegmentWindow_FullMethodName      = "/segmentation.v1.SegmentationService/SetSegmentWindow"
+	SegmentationService_SetSegmentCap_FullMethodName         = "/segmentation.v1.SegmentationService/SetSegmentCap"
+	SegmentationService_UpdateUserSegments_FullMethodName    = "/segmentation.v1.SegmentationService/UpdateUserSegments"
+	SegmentationService_GetUserSegments_FullMethodName       = "/segmentation.v1.SegmentationService/GetUserSegments"
+	SegmentationService_ListSegments_FullMethodName          = "/segmentation.v1.SegmentationService/ListSegments"
+	SegmentationService_GetSegmentMembers_FullMethodName     = "/segmentation.v1.SegmentationService/GetSegmentMembers"
+	SegmentationService_GetSegmentHistory_FullMethodName     = "/segmentation.v1.SegmentationService/GetSegmentHistory"
+	SegmentationService_GetSegmentStats_FullMethodName       = "/segmentation.v1.SegmentationService/GetSegmentStats"
+	SegmentationService_GetServiceStats_FullMethodName       = "/segmentation.v1.SegmentationService/GetServiceStats"
+	SegmentationService_UpdateUserAttributes_FullMethodName  = "/segmentation.v1.SegmentationService/UpdateUserAttributes"
+	SegmentationService_GetUserAttributes_FullMethodName     = "/segmentation.v1.SegmentationService/GetUserAttributes"
+	SegmentationService_CreateExperimentGroup_FullMethodName = "/segmentation.v1.SegmentationService/CreateExperimentGroup"
+	SegmentationService_DeleteExperimentGroup_FullMethodName = "/segmentation.v1.SegmentationService/DeleteExperimentGroup"
+	SegmentationService_ListExperimentGroups_FullMethodName  = "/segmentation.v1.SegmentationService/ListExperimentGroups"
+	SegmentationService_AssignExperiment_FullMethodName      = "/segmentation.v1.SegmentationService/AssignExperiment"
+	SegmentationService_GetReport_FullMethodName             = "/segmentation.v1.SegmentationService/GetReport"
+	SegmentationService_GetUserReport_FullMethodName         = "/segmentation.v1.SegmentationService/GetUserReport"
+	SegmentationService_GetSegmentReport_FullMethodName      = "/segmentation.v1.SegmentationService/GetSegmentReport"
+	SegmentationService_GetAggregateReport_FullMethodName    = "/segmentation.v1.SegmentationService/GetAggregateReport"
+)
+
+// SegmentationServiceClient is the client API for SegmentationService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SegmentationServiceClient interface {
+	// Creates a new segment with the given slug and, optionally, the rule, the expression or the rollout percentage computing its members.
+	CreateSegment(ctx context.Context, in *CreateSegmentRequest, opts ...grpc.CallOption) (*CreateSegmentResponse, error)
+	// Deletes the segment with the given slug and all users from it.
+	DeleteSegment(ctx context.Context, in *DeleteSegmentRequest, opts ...grpc.CallOption) (*DeleteSegmentResponse, error)
+	// Changes the slug of the segment, keeping its members and history. The old slug works as an alias for alias_days
+	// days, the calls using it get the deprecation, sunset and warning headers, and stays reserved afterwards.
+	RenameSegment(ctx context.Context, in *RenameSegmentRequest, opts ...grpc.CallOption) (*RenameSegmentResponse, error)
+	// Sets or, if the rule is empty, removes the rule of the segment and brings its members in line with it.
+	SetSegmentRule(ctx context.Context, in *SetSegmentRuleRequest, opts ...grpc.CallOption) (*SetSegmentRuleResponse, error)
+	// Sets or, if the expression is empty, removes the expression of the composite segment and brings its members in line with it.
+	SetSegmentExpression(ctx context.Context, in *SetSegmentExpressionRequest, opts ...grpc.CallOption) (*SetSegmentExpressionResponse, error)
+	// Sets or, if the rollout is missing, removes the percentage of the rollout segment, whose members are computed on read.
+	SetSegmentRollout(ctx context.Context, in *SetSegmentRolloutRequest, opts ...grpc.CallOption) (*SetSegmentRolloutResponse, error)
+	// Sets the activation window of the segment, outside of which it isn't returned to its members.
+	SetSegmentWindow(ctx context.Context, in *SetSegmentWindowRequest, opts ...grpc.CallOption) (*SegmentState, error)
+	// Sets or, if max_members is missing, removes the cap of the segment. Additions to a full segment fail with
+	// RESOURCE_EXHAUSTED, while the rules, the expressions and the imports stop at the cap.
+	SetSegmentCap(ctx context.Context, in *SetSegmentCapRequest, opts ...grpc.CallOption) (*SegmentState, error)
+	// Adds and removes the user from segments in accordance with the lists for adding and deleting.
+	UpdateUserSegments(ctx context.Context, in *UpdateUserSegmentsRequest, opts ...grpc.CallOption) (*UpdateUserSegmentsResponse, error)
+	// Returns the list of segments the user is a member of.
+	GetUserSegments(ctx context.Context, in *GetUserSegmentsRequest, opts ...grpc.CallOption) (*GetUserSegmentsResponse, error)
+	// Returns the slugs of all segments in alphabetical order with their activation windows and states.
+	ListSegments(ctx context.Context, in *ListSegmentsRequest, opts ...grpc.CallOption) (*ListSegmentsResponse, error)
+	// Returns a page of the members of the segment, ordered by user ID.
+	GetSegmentMembers(ctx context.Context, in *GetSegmentMembersRequest, opts ...grpc.CallOption) (*GetSegmentMembersResponse, error)
+	// Returns the audit log of the segment, including the events recorded under its previous slugs.
+	GetSegmentHistory(ctx context.Context, in *GetSegmentHistoryRequest, opts ...grpc.CallOption) (*GetSegmentHistoryResponse, error)
+	// Returns the number of the members of the segment and the numbers of the members added and removed on every day of the range.
+	// The daily numbers are read from the daily rollups of the report.
+	GetSegmentStats(ctx context.Context, in *GetSegmentStatsRequest, opts ...grpc.CallOption) (*GetSegmentStatsResponse, error)
+	// Returns all segments in alphabetical order with the numbers of their members, read from counters.
+	GetServiceStats(ctx context.Context, in *GetServiceStatsRequest, opts ...grpc.CallOption) (*GetServiceStatsResponse, error)
+	// Merges the attributes of the user, null values remove the attribute, and re-evaluates the rules of the dynamic segments.
+	UpdateUserAttributes(ctx context.Context, in *UpdateUserAttributesRequest, opts ...grpc.CallOption) (*UpdateUserAttributesResponse, error)
+	// Returns the attributes of the user.
+	GetUserAttributes(ctx context.Context, in *GetUserAttributesRequest, opts ...grpc.CallOption) (*GetUserAttributesResponse, error)
+	// Creates a group of mutually exclusive segments with weights.
+	CreateExperimentGroup(ctx context.Context, in *CreateExperimentGroupRequest, opts ...grpc.CallOption) (*CreateExperimentGroupResponse, error)
+	// Deletes the experiment group, keeping its segments and their members.
+	DeleteExperimentGroup(ctx context.Context, in *DeleteExperimentGroupRequest, opts ...grpc.CallOption) (*DeleteExperimentGroupResponse, error)
+	// Returns all experiment groups in alphabetical order.
+	ListExperimentGroups(ctx context.Context, in *ListExperimentGroupsRequest, opts ...grpc.CallOption) (*ListExperimentGroupsResponse, error)
+	// Adds the user to a variant of the experiment group, chosen by the weights and the hash of the user ID and the salt.
+	AssignExperiment(ctx context.Context, in *AssignExperimentRequest, opts ...grpc.CallOption) (*AssignExperimentResponse, error)
+	// Streams the history of events for the given month.
+	GetReport(ctx context.Context, in *GetReportRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ReportRow], error)
+	// Streams a specific user's history of events for the given month.
+	GetUserReport(ctx context.Context, in *GetUserReportRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ReportRow], error)
+	// Streams the audit log of all segments, including the deleted ones, for the given month.
+	GetSegmentReport(ctx context.Context, in *GetSegmentReportRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SegmentEvent], error)
+	// Streams the daily numbers of the members added to and removed from every segment for the given month, read from the rollups.
+	GetAggregateReport(ctx context.Context, in *GetAggregateReportRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AggregateReportRow], error)
+}
+
+type segmentationServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSegmentationServiceClient(cc grpc.ClientConnInterface) SegmentationServiceClient {
+	return &segmentationServiceClient{cc}
+}
+
+func (c *segmentationServiceClient) CreateSegment(ctx context.Context, in *CreateSegmentRequest, opts ...grpc.CallOption) (*CreateSegmentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateSegmentResponse)
+	err := c.cc.Invoke(ctx, SegmentationService_CreateSegment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *segmentationServiceClient) DeleteSegment(ctx context.Context, in *DeleteSegmentRequest, opts ...grpc.CallOption) (*DeleteSegmentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteSegmentResponse)
+	err := c.cc.Invoke(ctx, SegmentationService_DeleteSegment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *segmentationServiceClient) RenameSegment(ctx context.Context, in *RenameSegmentRequest, opts ...grpc.CallOption) (*RenameSegmentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RenameSegmentResponse)
+	err := c.cc.Invoke(ctx, SegmentationService_RenameSegment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *segmentationServiceClient) SetSegmentRule(ctx context.Context, in *SetSegmentRuleRequest, opts ...grpc.CallOption) (*SetSegmentRuleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetSegmentRuleResponse)
+	err := c.cc.Invoke(ctx, SegmentationService_SetSegmentRule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *segmentationServiceClient) SetSegmentExpression(ctx context.Context, in *SetSegmentExpressionRequest, opts ...grpc.CallOption) (*SetSegmentExpressionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetSegmentExpressionResponse)
+	err := c.cc.Invoke(ctx, SegmentationService_SetSegmentExpression_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *segmentationServiceClient) SetSegmentRollout(ctx context.Context, in *SetSegmentRolloutRequest, opts ...grpc.CallOption) (*SetSegmentRolloutResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetSegmentRolloutResponse)
+	err := c.cc.Invoke(ctx, SegmentationService_SetSegmentRollout_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *segmentationServiceClient) SetSegmentWindow(ctx context.Context, in *SetSegmentWindowRequest, opts ...grpc.CallOption) (*SegmentState, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SegmentState)
+	err := c.cc.Invoke(ctx, SegmentationService_SetSegmentWindow_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *segmentationServiceClient) SetSegmentCap(ctx context.Context, in *SetSegmentCapRequest, opts ...grpc.CallOption) (*SegmentState, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SegmentState)
+	err := c.cc.Invoke(ctx, SegmentationService_SetSegmentCap_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *segmentationServiceClient) UpdateUserSegments(ctx context.Context, in *UpdateUserSegmentsRequest, opts ...grpc.CallOption) (*UpdateUserSegmentsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateUserSegmentsResponse)
+	err := c.cc.Invoke(ctx, SegmentationService_UpdateUserSegments_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *segmentationServiceClient) GetUserSegments(ctx context.Context, in *GetUserSegmentsRequest, opts ...grpc.CallOption) (*GetUserSegmentsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetUserSegmentsResponse)
+	err := c.cc.Invoke(ctx, SegmentationService_GetUserSegments_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *segmentationServiceClient) ListSegments(ctx context.Context, in *ListSegmentsRequest, opts ...grpc.CallOption) (*ListSegmentsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSegmentsResponse)
+	err := c.cc.Invoke(ctx, SegmentationService_ListSegments_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *segmentationServiceClient) GetSegmentMembers(ctx context.Context, in *GetSegmentMembersRequest, opts ...grpc.CallOption) (*GetSegmentMembersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetSegmentMembersResponse)
+	err := c.cc.Invoke(ctx, SegmentationService_GetSegmentMembers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *segmentationServiceClient) GetSegmentHistory(ctx context.Context, in *GetSegmentHistoryRequest, opts ...grpc.CallOption) (*GetSegmentHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetSegmentHistoryResponse)
+	err := c.cc.Invoke(ctx, SegmentationService_GetSegmentHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *segmentationServiceClient) GetSegmentStats(ctx context.Context, in *GetSegmentStatsRequest, opts ...grpc.CallOption) (*GetSegmentStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetSegmentStatsResponse)
+	err := c.cc.Invoke(ctx, SegmentationService_GetSegmentStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *segmentationServiceClient) GetServiceStats(ctx context.Context, in *GetServiceStatsRequest, opts ...grpc.CallOption) (*GetServiceStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetServiceStatsResponse)
+	err := c.cc.Invoke(ctx, SegmentationService_GetServiceStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *segmentationServiceClient) UpdateUserAttributes(ctx context.Context, in *UpdateUserAttributesRequest, opts ...grpc.CallOption) (*UpdateUserAttributesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateUserAttributesResponse)
+	err := c.cc.Invoke(ctx, SegmentationService_UpdateUserAttributes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *segmentationServiceClient) GetUserAttributes(ctx context.Context, in *GetUserAttributesRequest, opts ...grpc.CallOption) (*GetUserAttributesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetUserAttributesResponse)
+	err := c.cc.Invoke(ctx, SegmentationService_GetUserAttributes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *segmentationServiceClient) CreateExperimentGroup(ctx context.Context, in *CreateExperimentGroupRequest, opts ...grpc.CallOption) (*CreateExperimentGroupResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateExperimentGroupResponse)
+	err := c.cc.Invoke(ctx, SegmentationService_CreateExperimentGroup_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *segmentationServiceClient) DeleteExperimentGroup(ctx context.Context, in *DeleteExperimentGroupRequest, opts ...grpc.CallOption) (*DeleteExperimentGroupResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteExperimentGroupResponse)
+	err := c.cc.Invoke(ctx, SegmentationService_DeleteExperimentGroup_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *segmentationServiceClient) ListExperimentGroups(ctx context.Context, in *ListExperimentGroupsRequest, opts ...grpc.CallOption) (*ListExperimentGroupsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListExperimentGroupsResponse)
+	err := c.cc.Invoke(ctx, SegmentationService_ListExperimentGroups_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *segmentationServiceClient) AssignExperiment(ctx context.Context, in *AssignExperimentRequest, opts ...grpc.CallOption) (*AssignExperimentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AssignExperimentResponse)
+	err := c.cc.Invoke(ctx, SegmentationService_AssignExperiment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *segmentationServiceClient) GetReport(ctx context.Context, in *GetReportRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ReportRow], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SegmentationService_ServiceDesc.Streams[0], SegmentationService_GetReport_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[GetReportRequest, ReportRow]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SegmentationService_GetReportClient = grpc.ServerStreamingClient[ReportRow]
+
+func (c *segmentationServiceClient) GetUserReport(ctx context.Context, in *GetUserReportRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ReportRow], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SegmentationService_ServiceDesc.Streams[1], SegmentationService_GetUserReport_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[GetUserReportRequest, ReportRow]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SegmentationService_GetUserReportClient = grpc.ServerStreamingClient[ReportRow]
+
+func (c *segmentationServiceClient) GetSegmentReport(ctx context.Context, in *GetSegmentReportRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SegmentEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SegmentationService_ServiceDesc.Streams[2], SegmentationService_GetSegmentReport_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[GetSegmentReportRequest, SegmentEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SegmentationService_GetSegmentReportClient = grpc.ServerStreamingClient[SegmentEvent]
+
+func (c *segmentationServiceClient) GetAggregateReport(ctx context.Context, in *GetAggregateReportRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AggregateReportRow], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SegmentationService_ServiceDesc.Streams[3], SegmentationService_GetAggregateReport_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[GetAggregateReportRequest, AggregateReportRow]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SegmentationService_GetAggregateReportClient = grpc.ServerStreamingClient[AggregateReportRow]
+
+// SegmentationServiceServer is the server API for SegmentationService service.
+// All implementations must embed UnimplementedSegmentationServiceServer
+// for forward compatibility.
+type SegmentationServiceServer interface {
+	// Creates a new segment with the given slug and, optionally, the rule, the expression or the rollout percentage computing its members.
+	CreateSegment(context.Context, *CreateSegmentRequest) (*CreateSegmentResponse, error)
+	// Deletes the segment with the given slug and all users from it.
+	DeleteSegment(context.Context, *DeleteSegmentRequest) (*DeleteSegmentResponse, error)
+	// Changes the slug of the segment, keeping its members and history. The old slug works as an alias for alias_days
+	// days, the calls using it get the deprecation, sunset and warning headers, and stays reserved afterwards.
+	RenameSegment(context.Context, *RenameSegmentRequest) (*RenameSegmentResponse, error)
+	// Sets or, if the rule is empty, removes the rule of the segment and brings its members in line with it.
+	SetSegmentRule(context.Context, *SetSegmentRuleRequest) (*SetSegmentRuleResponse, error)
+	// Sets or, if the expression is empty, removes the expression of the composite segment and brings its members in line with it.
+	SetSegmentExpression(context.Context, *SetSegmentExpressionRequest) (*SetSegmentExpressionResponse, error)
+	// Sets or, if the rollout is missing, removes the percentage of the rollout segment, whose members are computed on read.
+	SetSegmentRollout(context.Context, *SetSegmentRolloutRequest) (*SetSegmentRolloutResponse, error)
+	// Sets the activation window of the segment, outside of which it isn't returned to its members.
+	SetSegmentWindow(context.Context, *SetSegmentWindowRequest) (*SegmentState, error)
+	// Sets or, if max_members is missing, removes the cap of the segment. Additions to a full segment fail with
+	// RESOURCE_EXHAUSTED, while the rules, the expressions and the imports stop at the cap.
+	SetSegmentCap(context.Context, *SetSegmentCapRequest) (*SegmentState, error)
+	// Adds and removes the user from segments in accordance with the lists for adding and deleting.
+	UpdateUserSegments(context.Context, *UpdateUserSegmentsRequest) (*UpdateUserSegmentsResponse, error)
+	// Returns the list of segments the user is a member of.
+	GetUserSegments(context.Context, *GetUserSegmentsRequest) (*GetUserSegmentsResponse, error)
+	// Returns the slugs of all segments in alphabetical order with their activation windows and states.
+	ListSegments(context.Context, *ListSegmentsRequest) (*ListSegmentsResponse, error)
+	// Returns a page of the members of the segment, ordered by user ID.
+	GetSegmentMembers(context.Context, *GetSegmentMembersRequest) (*GetSegmentMembersResponse, error)
+	// Returns the audit log of the segment, including the events recorded under its previous slugs.
+	GetSegmentHistory(context.Context, *GetSegmentHistoryRequest) (*GetSegmentHistoryResponse, error)
+	// Returns the number of the members of the segment and the numbers of the members added and removed on every day of the range.
+	// The daily numbers are read from the daily rollups of the report.
+	GetSegmentStats(context.Context, *GetSegmentStatsRequest) (*GetSegmentStatsResponse, error)
+	// Returns all segments in alphabetical order with the numbers of their members, read from counters.
+	GetServiceStats(context.Context, *GetServiceStatsRequest) (*GetServiceStatsResponse, error)
+	// Merges the attributes of the user, null values remove the attribute, and re-evaluates the rules of the dynamic segments.
+	UpdateUserAttributes(context.Context, *UpdateUserAttributesRequest) (*UpdateUserAttributesResponse, error)
+	// Returns the attributes of the user.
+	GetUserAttributes(context.Context, *GetUserAttributesRequest) (*GetUserAttributesResponse, error)
+	// Creates a group of mutually exclusive segments with weights.
+	CreateExperimentGroup(context.Context, *CreateExperimentGroupRequest) (*CreateExperimentGroupResponse, error)
+	// Deletes the experiment group, keeping its segments and their members.
+	DeleteExperimentGroup(context.Context, *DeleteExperimentGroupRequest) (*DeleteExperimentGroupResponse, error)
+	// Returns all experiment groups in alphabetical order.
+	ListExperimentGroups(context.Context, *ListExperimentGroupsRequest) (*ListExperimentGroupsResponse, error)
+	// Adds the user to a variant of the experiment group, chosen by the weights and the hash of the user ID and the salt.
+	AssignExperiment(context.Context, *AssignExperimentRequest) (*AssignExperimentResponse, error)
+	// Streams the history of events for the given month.
+	GetReport(*GetReportRequest, grpc.ServerStreamingServer[ReportRow]) error
+	// Streams a specific user's history of events for the given month.
+	GetUserReport(*GetUserReportRequest, grpc.ServerStreamingServer[ReportRow]) error
+	// Streams the audit log of all segments, including the deleted ones, for the given month.
+	GetSegmentReport(*GetSegmentReportRequest, grpc.ServerStreamingServer[SegmentEvent]) error
+	// Streams the daily numbers of the members added to and removed from every segment for the given month, read from the rollups.
+	GetAggregateReport(*GetAggregateReportRequest, grpc.ServerStreamingServer[AggregateReportRow]) error
+	mustEmbedUnimplementedSegmentationServiceServer()
+}
+
+// UnimplementedSegmentationServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSegmentationServiceServer struct{}
+
+func (UnimplementedSegmentationServiceServer) CreateSegment(context.Context, *CreateSegmentRequest) (*CreateSegmentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateSegment not implemented")
+}
+func (UnimplementedSegmentationServiceServer) DeleteSegment(context.Context, *DeleteSegmentRequest) (*DeleteSegmentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteSegment not implemented")
+}
+func (UnimplementedSegmentationServiceServer) RenameSegment(context.Context, *RenameSegmentRequest) (*RenameSegmentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RenameSegment not implemented")
+}
+func (UnimplementedSegmentationServiceServer) SetSegmentRule(context.Context, *SetSegmentRuleRequest) (*SetSegmentRuleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetSegmentRule not implemented")
+}
+func (UnimplementedSegmentationServiceServer) SetSegmentExpression(context.Context, *SetSegmentExpressionRequest) (*SetSegmentExpressionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetSegmentExpression not implemented")
+}
+func (UnimplementedSegmentationServiceServer) SetSegmentRollout(context.Context, *SetSegmentRolloutRequest) (*SetSegmentRolloutResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetSegmentRollout not implemented")
+}
+func (UnimplementedSegmentationServiceServer) SetSegmentWindow(context.Context, *SetSegmentWindowRequest) (*SegmentState, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetSegmentWindow not implemented")
+}
+func (UnimplementedSegmentationServiceServer) SetSegmentCap(context.Context, *SetSegmentCapRequest) (*SegmentState, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetSegmentCap not implemented")
+}
+func (UnimplementedSegmentationServiceServer) UpdateUserSegments(context.Context, *UpdateUserSegmentsRequest) (*UpdateUserSegmentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateUserSegments not implemented")
+}
+func (UnimplementedSegmentationServiceServer) GetUserSegments(context.Context, *GetUserSegmentsRequest) (*GetUserSegmentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUserSegments not implemented")
+}
+func (UnimplementedSegmentationServiceServer) ListSegments(context.Context, *ListSegmentsRequest) (*ListSegmentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSegments not implemented")
+}
+func (UnimplementedSegmentationServiceServer) GetSegmentMembers(context.Context, *GetSegmentMembersRequest) (*GetSegmentMembersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSegmentMembers not implemented")
+}
+func (UnimplementedSegmentationServiceServer) GetSegmentHistory(context.Context, *GetSegmentHistoryRequest) (*GetSegmentHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSegmentHistory not implemented")
+}
+func (UnimplementedSegmentationServiceServer) GetSegmentStats(context.Context, *GetSegmentStatsRequest) (*GetSegmentStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSegmentStats not implemented")
+}
+func (UnimplementedSegmentationServiceServer) GetServiceStats(context.Context, *GetServiceStatsRequest) (*GetServiceStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetServiceStats not implemented")
+}
+func (UnimplementedSegmentationServiceServer) UpdateUserAttributes(context.Context, *UpdateUserAttributesRequest) (*UpdateUserAttributesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateUserAttributes not implemented")
+}
+func (UnimplementedSegmentationServiceServer) GetUserAttributes(context.Context, *GetUserAttributesRequest) (*GetUserAttributesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUserAttributes not implemented")
+}
+func (UnimplementedSegmentationServiceServer) CreateExperimentGroup(context.Context, *CreateExperimentGroupRequest) (*CreateExperimentGroupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateExperimentGroup not implemented")
+}
+func (UnimplementedSegmentationServiceServer) DeleteExperimentGroup(context.Context, *DeleteExperimentGroupRequest) (*DeleteExperimentGroupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteExperimentGroup not implemented")
+}
+func (UnimplementedSegmentationServiceServer) ListExperimentGroups(context.Context, *ListExperimentGroupsRequest) (*ListExperimentGroupsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListExperimentGroups not implemented")
+}
+func (UnimplementedSegmentationServiceServer) AssignExperiment(context.Context, *AssignExperimentRequest) (*AssignExperimentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AssignExperiment not implemented")
+}
+func (UnimplementedSegmentationServiceServer) GetReport(*GetReportRequest, grpc.ServerStreamingServer[ReportRow]) error {
+	return status.Errorf(codes.Unimplemented, "method GetReport not implemented")
+}
+func (UnimplementedSegmentationServiceServer) GetUserReport(*GetUserReportRequest, grpc.ServerStreamingServer[ReportRow]) error {
+	return status.Errorf(codes.Unimplemented, "method GetUserReport not implemented")
+}
+func (UnimplementedSegmentationServiceServer) GetSegmentReport(*GetSegmentReportRequest, grpc.ServerStreamingServer[SegmentEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method GetSegmentReport not implemented")
+}
+func (UnimplementedSegmentationServiceServer) GetAggregateReport(*GetAggregateReportRequest, grpc.ServerStreamingServer[AggregateReportRow]) error {
+	return status.Errorf(codes.Unimplemented, "method GetAggregateReport not implemented")
+}
+func (UnimplementedSegmentationServiceServer) mustEmbedUnimplementedSegmentationServiceServer() {}
+func (UnimplementedSegmentationServiceServer) testEmbeddedByValue()                             {}
+
+// UnsafeSegmentationServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SegmentationServiceServer will
+// result in compilation errors.
+type UnsafeSegmentationServiceServer interface {
+	mustEmbedUnimplementedSegmentationServiceServer()
+}
+
+func RegisterSegmentationServiceServer(s grpc.ServiceRegistrar, srv SegmentationServiceServer) {
+	// If the following call pancis, it indicates UnimplementedSegmentationServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&SegmentationService_ServiceDesc, srv)
+}
+
+func _SegmentationService_CreateSegment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateSegmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SegmentationServiceServer).CreateSegment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SegmentationService_CreateSegment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SegmentationServiceServer).CreateSegment(ctx, req.(*CreateSegmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SegmentationService_DeleteSegment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteSegmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SegmentationServiceServer).DeleteSegment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SegmentationService_DeleteSegment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SegmentationServiceServer).DeleteSegment(ctx, req.(*DeleteSegmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SegmentationService_RenameSegment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenameSegmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SegmentationServiceServer).RenameSegment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SegmentationService_RenameSegment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SegmentationServiceServer).RenameSegment(ctx, req.(*RenameSegmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SegmentationService_SetSegmentRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetSegmentRuleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SegmentationServiceServer).SetSegmentRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SegmentationService_SetSegmentRule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SegmentationServiceServer).SetSegmentRule(ctx, req.(*SetSegmentRuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SegmentationService_SetSegmentExpression_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetSegmentExpressionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SegmentationServiceServer).SetSegmentExpression(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SegmentationService_SetSegmentExpression_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SegmentationServiceServer).SetSegmentExpression(ctx, req.(*SetSegmentExpressionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SegmentationService_SetSegmentRollout_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetSegmentRolloutRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SegmentationServiceServer).SetSegmentRollout(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SegmentationService_SetSegmentRollout_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SegmentationServiceServer).SetSegmentRollout(ctx, req.(*SetSegmentRolloutRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SegmentationService_SetSegmentWindow_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetSegmentWindowRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SegmentationServiceServer).SetSegmentWindow(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SegmentationService_SetSegmentWindow_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SegmentationServiceServer).SetSegmentWindow(ctx, req.(*SetSegmentWindowRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SegmentationService_SetSegmentCap_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetSegmentCapRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SegmentationServiceServer).SetSegmentCap(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SegmentationService_SetSegmentCap_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SegmentationServiceServer).SetSegmentCap(ctx, req.(*SetSegmentCapRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SegmentationService_UpdateUserSegments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateUserSegmentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SegmentationServiceServer).UpdateUserSegments(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SegmentationService_UpdateUserSegments_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SegmentationServiceServer).UpdateUserSegments(ctx, req.(*UpdateUserSegmentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SegmentationService_GetUserSegments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserSegmentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SegmentationServiceServer).GetUserSegments(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SegmentationService_GetUserSegments_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SegmentationServiceServer).GetUserSegments(ctx, req.(*GetUserSegmentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SegmentationService_ListSegments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSegmentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SegmentationServiceServer).ListSegments(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SegmentationService_ListSegments_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SegmentationServiceServer).ListSegments(ctx, req.(*ListSegmentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SegmentationService_GetSegmentMembers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSegmentMembersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SegmentationServiceServer).GetSegmentMembers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SegmentationService_GetSegmentMembers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SegmentationServiceServer).GetSegmentMembers(ctx, req.(*GetSegmentMembersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SegmentationService_GetSegmentHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSegmentHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SegmentationServiceServer).GetSegmentHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SegmentationService_GetSegmentHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SegmentationServiceServer).GetSegmentHistory(ctx, req.(*GetSegmentHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SegmentationService_GetSegmentStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSegmentStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SegmentationServiceServer).GetSegmentStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SegmentationService_GetSegmentStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SegmentationServiceServer).GetSegmentStats(ctx, req.(*GetSegmentStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SegmentationService_GetServiceStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetServiceStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SegmentationServiceServer).GetServiceStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SegmentationService_GetServiceStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SegmentationServiceServer).GetServiceStats(ctx, req.(*GetServiceStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SegmentationService_UpdateUserAttributes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateUserAttributesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SegmentationServiceServer).UpdateUserAttributes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SegmentationService_UpdateUserAttributes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SegmentationServiceServer).UpdateUserAttributes(ctx, req.(*UpdateUserAttributesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SegmentationService_GetUserAttributes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserAttributesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SegmentationServiceServer).GetUserAttributes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SegmentationService_GetUserAttributes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SegmentationServiceServer).GetUserAttributes(ctx, req.(*GetUserAttributesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SegmentationService_CreateExperimentGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateExperimentGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SegmentationServiceServer).CreateExperimentGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SegmentationService_CreateExperimentGroup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SegmentationServiceServer).CreateExperimentGroup(ctx, req.(*CreateExperimentGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SegmentationService_DeleteExperimentGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteExperimentGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SegmentationServiceServer).DeleteExperimentGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SegmentationService_DeleteExperimentGroup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SegmentationServiceServer).DeleteExperimentGroup(ctx, req.(*DeleteExperimentGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SegmentationService_ListExperimentGroups_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListExperimentGroupsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SegmentationServiceServer).ListExperimentGroups(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SegmentationService_ListExperimentGroups_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SegmentationServiceServer).ListExperimentGroups(ctx, req.(*ListExperimentGroupsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SegmentationService_AssignExperiment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AssignExperimentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SegmentationServiceServer).AssignExperiment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SegmentationService_AssignExperiment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SegmentationServiceServer).AssignExperiment(ctx, req.(*AssignExperimentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SegmentationService_GetReport_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetReportRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SegmentationServiceServer).GetReport(m, &grpc.GenericServerStream[GetReportRequest, ReportRow]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SegmentationService_GetReportServer = grpc.ServerStreamingServer[ReportRow]
+
+func _SegmentationService_GetUserReport_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetUserReportRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SegmentationServiceServer).GetUserReport(m, &grpc.GenericServerStream[GetUserReportRequest, ReportRow]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SegmentationService_GetUserReportServer = grpc.ServerStreamingServer[ReportRow]
+
+func _SegmentationService_GetSegmentReport_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetSegmentReportRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SegmentationServiceServer).GetSegmentReport(m, &grpc.GenericServerStream[GetSegmentReportRequest, SegmentEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SegmentationService_GetSegmentReportServer = grpc.ServerStreamingServer[SegmentEvent]
+
+func _SegmentationService_GetAggregateReport_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetAggregateReportRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SegmentationServiceServer).GetAggregateReport(m, &grpc.GenericServerStream[GetAggregateReportRequest, AggregateReportRow]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SegmentationService_GetAggregateReportServer = grpc.ServerStreamingServer[AggregateReportRow]
+
+// SegmentationService_ServiceDesc is the grpc.ServiceDesc for SegmentationService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SegmentationService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "segmentation.v1.SegmentationService",
+	HandlerType: (*SegmentationServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateSegment",
+			Handler:    _SegmentationService_CreateSegment_Handler,
+		},
+		{
+			MethodName: "DeleteSegment",
+			Handler:    _SegmentationService_DeleteSegment_Handler,
+		},
+		{
+			MethodName: "RenameSegment",
+			Handler:    _SegmentationService_RenameSegment_Handler,
+		},
+		{
+			MethodName: "SetSegmentRule",
+			Handler:    _SegmentationService_SetSegmentRule_Handler,
+		},
+		{
+			MethodName: "SetSegmentExpression",
+			Handler:    _SegmentationService_SetSegmentExpression_Handler,
+		},
+		{
+			MethodName: "SetSegmentRollout",
+			Handler:    _SegmentationService_SetSegmentRollout_Handler,
+		},
+		{
+			MethodName: "SetSegmentWindow",
+			Handler:    _SegmentationService_SetSegmentWindow_Handler,
+		},
+		{
+			MethodName: "SetSegmentCap",
+			Handler:    _SegmentationService_SetSegmentCap_Handler,
+		},
+		{
+			MethodName: "UpdateUserSegments",
+			Handler:    _SegmentationService_UpdateUserSegments_Handler,
+		},
+		{
+			MethodName: "GetUserSegments",
+			Handler:    _SegmentationService_GetUserSegments_Handler,
+		},
+		{
+			MethodName: "ListSegments",
+			Handler:    _SegmentationService_ListSegments_Handler,
+		},
+		{
+			MethodName: "GetSegmentMembers",
+			Handler:    _SegmentationService_GetSegmentMembers_Handler,
+		},
+		{
+			MethodName: "GetSegmentHistory",
+			Handler:    _SegmentationService_GetSegmentHistory_Handler,
+		},
+		{
+			MethodName: "GetSegmentStats",
+			Handler:    _SegmentationService_GetSegmentStats_Handler,
+		},
+		{
+			MethodName: "GetServiceStats",
+			Handler:    _SegmentationService_GetServiceStats_Handler,
+		},
+		{
+			MethodName: "UpdateUserAttributes",
+			Handler:    _SegmentationService_UpdateUserAttributes_Handler,
+		},
+		{
+			MethodName: "GetUserAttributes",
+			Handler:    _SegmentationService_GetUserAttributes_Handler,
+		},
+		{
+			MethodName: "CreateExperimentGroup",
+			Handler:    _SegmentationService_CreateExperimentGroup_Handler,
+		},
+		{
+			MethodName: "DeleteExperimentGroup",
+			Handler:    _SegmentationService_DeleteExperimentGroup_Handler,
+		},
+		{
+			MethodName: "ListExperimentGroups",
+			Handler:    _SegmentationService_ListExperimentGroups_Handler,
+		},
+		{
+			MethodName: "AssignExperiment",
+			Handler:    _SegmentationService_AssignExperiment_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GetReport",
+			Handler:       _SegmentationService_GetReport_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GetUserReport",
+			Handler:       _SegmentationService_GetUserReport_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GetSegmentReport",
+			Handler:       _SegmentationService_GetSegmentReport_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GetAggregateReport",
+			Handler:       _SegmentationService_GetAggregateReport_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "segmentation/v1/segmentation.proto",
+}
@@ -19,9 +19,156 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/assignExperiment/{userID}": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Adds the user to a variant of the experiment group. The variant is chosen by the weights and the hash of the user ID and the salt of the group, so the same user always gets the same variant. A user that is already in a variant of the group stays there. If the chosen variant reached its cap, the user isn't assigned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "experiment"
+                ],
+                "summary": "Assign a user to an experiment group",
+                "operationId": "assignExperiment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "User ID in uuid format",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Name of the group",
+                        "name": "group",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AssignRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The variant of the user, 'assigned' is false if the user had already been in it.",
+                        "schema": {
+                            "$ref": "#/definitions/models.Assignment"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid format for parameter 'userID' / missing required 'group' parameter.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid API key.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Experiment group not found.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The variant of the user is full.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error / Internal Server Error.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/createExperimentGroup": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates a group of mutually exclusive segments, its variants, with weights. A user can be in one variant of the group only: adding them to another one through updateUserSegments or the import fails ('reject', the default) or removes them from the previous variant ('move'). The variants must be existing ordinary segments, and no user may be in two of them. The salt defaults to the name of the group.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "experiment"
+                ],
+                "summary": "Create an experiment group",
+                "operationId": "createExperimentGroup",
+                "parameters": [
+                    {
+                        "description": "Name, salt, conflict policy and variants of the group",
+                        "name": "group",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ExperimentGroup"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Experiment group created.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ExperimentGroup"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid experiment group / group already exists / a variant is dynamic, composite or in another group / a user is in several variants.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid API key.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "A segment of the group not found.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error / Internal Server Error.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/createSegment": {
             "post": {
-                "description": "Creates a new segment with the given slug. If this segment was already in the database, return the BadRequest status.",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates a new segment with the given slug. If this segment was already in the database, return the BadRequest status. If the rule is set, the segment is dynamic: its members are the users whose attributes match the rule. If the expression is set, the segment is composite: its members are computed from other segments. If the rollout is set, the segment is a percentage rollout: its members are computed from the hash of the user ID. If active_from or active_until is set, the segment is returned to its members only within that window. If max_members is set, the segment takes no more members than that.",
                 "consumes": [
                     "application/json"
                 ],
@@ -32,7 +179,7 @@ const docTemplate = `{
                 "operationId": "createSegment",
                 "parameters": [
                     {
-                        "description": "A short name containing only letters, numbers, underscores, or hyphens. Format: ^[\\w-]+$",
+                        "description": "A short name containing only letters, numbers, underscores, or hyphens. Format: ^[\\w-]+$. An optional rule over the user attributes, an expression over other segments or a rollout percentage, an optional activation window and an optional cap.",
                         "name": "slug",
                         "in": "body",
                         "required": true,
@@ -49,7 +196,80 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Segment already exists / missing required 'slug' parameter / invalid format of 'slug' parameter.",
+                        "description": "Segment already exists / missing required 'slug' parameter / invalid format of 'slug' parameter / invalid rule / invalid expression / invalid rollout / invalid activation window / invalid cap / the slug is reserved by a renamed segment.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid API key.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "A segment of the expression not found.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error / Internal Server Error.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/deleteExperimentGroup": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Deletes the experiment group. Its segments and their members are kept.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "experiment"
+                ],
+                "summary": "Delete an experiment group",
+                "operationId": "deleteExperimentGroup",
+                "parameters": [
+                    {
+                        "description": "Name of the group, the other fields are ignored",
+                        "name": "group",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ExperimentGroup"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Experiment group deleted.",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing required 'name' parameter.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid API key.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Experiment group not found.",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -65,6 +285,11 @@ const docTemplate = `{
         },
         "/deleteSegment": {
             "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
                 "description": "Delete the segment with the given slug and all users from it.",
                 "consumes": [
                     "application/json"
@@ -93,7 +318,13 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Missing required 'slug' parameter / invalid format of 'slug' parameter.",
+                        "description": "Missing required 'slug' parameter / invalid format of 'slug' parameter / the segment is used by composite segments or an experiment group.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid API key.",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -113,35 +344,42 @@ const docTemplate = `{
                 }
             }
         },
-        "/getReport/{period}": {
+        "/exportState": {
             "get": {
-                "description": "Returns the history of events for the given month as a csv file.",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Returns a gzip compressed versioned archive with all segments, current memberships and, optionally, the report log, read from one consistent snapshot.",
                 "produces": [
-                    "text/csv"
+                    "application/gzip"
                 ],
                 "tags": [
-                    "report"
+                    "backup"
                 ],
-                "summary": "Get report file",
-                "operationId": "getReport",
+                "summary": "Export the service state",
+                "operationId": "exportState",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Month for which you want to display information, in the format 'yyyy-mm'",
-                        "name": "period",
-                        "in": "path",
-                        "required": true
+                        "type": "boolean",
+                        "description": "Include the report log",
+                        "name": "report",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Report file received successfully."
+                        "description": "Archive file received successfully."
                     },
                     "400": {
-                        "description": "Invalid format for parameter 'period'.",
+                        "description": "Invalid format of the 'report' parameter.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid API key.",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -155,9 +393,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/getUserReport/{period}/{userID}": {
+        "/getAggregateReport/{period}": {
             "get": {
-                "description": "Returns a specific user's history of events for the specified month as a csv file.",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the numbers of the members added to and removed from every segment on every day of the given month with changes as a csv file with the columns: segment, date, added, removed. The numbers are read from the daily rollups of the report rather than from its rows.",
                 "consumes": [
                     "application/json"
                 ],
@@ -167,8 +410,8 @@ const docTemplate = `{
                 "tags": [
                     "report"
                 ],
-                "summary": "Get a report file for a specific user",
-                "operationId": "getUserReport",
+                "summary": "Get the aggregate report file",
+                "operationId": "getAggregateReport",
                 "parameters": [
                     {
                         "type": "string",
@@ -176,14 +419,6 @@ const docTemplate = `{
                         "name": "period",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "User ID in uuid format",
-                        "name": "userID",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
@@ -196,6 +431,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Missing or invalid API key.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Database error / Internal Server Error.",
                         "schema": {
@@ -205,33 +446,71 @@ const docTemplate = `{
                 }
             }
         },
-        "/getUserSegments/{userID}": {
+        "/getReport/{period}": {
             "get": {
-                "description": "Return the list of segments the user is a member of.",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the history of events for the given month as a csv file with the columns: user, segment, action, time, actor, source, reason.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/csv"
+                ],
                 "tags": [
-                    "segment"
+                    "report"
                 ],
-                "summary": "Get user segments",
-                "operationId": "getSegments",
+                "summary": "Get report file",
+                "operationId": "getReport",
                 "parameters": [
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "User ID in uuid format",
-                        "name": "userID",
+                        "description": "Month for which you want to display information, in the format 'yyyy-mm'",
+                        "name": "period",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only the changes made by this actor",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "api",
+                            "import",
+                            "ttl-reaper",
+                            "auto-percent",
+                            "rule"
+                        ],
+                        "type": "string",
+                        "description": "Only the changes from this source",
+                        "name": "source",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only the changes whose reason contains this text, case-insensitively",
+                        "name": "reason",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "User segments received successfully.",
+                        "description": "Report file received successfully."
+                    },
+                    "400": {
+                        "description": "Invalid format for parameter 'period' / Unknown source.",
                         "schema": {
-                            "$ref": "#/definitions/models.SegmentsList"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "400": {
-                        "description": "Invalid format for parameter 'userID'.",
+                    "401": {
+                        "description": "Missing or invalid API key.",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -245,11 +524,1169 @@ const docTemplate = `{
                 }
             }
         },
-        "/updateUserSegments/{userID}": {
-            "post": {
-                "description": "Add/remove a user from segments in accordance with the transferred lists for adding and deleting.",
-                "consumes": [
-                    "application/json"
+        "/getSegmentReport/{period}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the audit log of all segments, including the deleted ones, for the given month as a csv file with the columns: segment, event, old value, new value, time, actor, source, reason.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/csv"
+                ],
+                "tags": [
+                    "report"
+                ],
+                "summary": "Get the segment report file",
+                "operationId": "getSegmentReport",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Month for which you want to display information, in the format 'yyyy-mm'",
+                        "name": "period",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Report file received successfully."
+                    },
+                    "400": {
+                        "description": "Invalid format for parameter 'period'.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid API key.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error / Internal Server Error.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/getUserAttributes/{userID}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Return the attributes of the user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attributes"
+                ],
+                "summary": "Get user attributes",
+                "operationId": "getUserAttributes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "User ID in uuid format",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User attributes received successfully.",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserAttributes"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid format for parameter 'userID'.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid API key.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error / Internal Server Error.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/getUserReport/{period}/{userID}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns a specific user's history of events for the specified month as a csv file with the same columns as the full report.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/csv"
+                ],
+                "tags": [
+                    "report"
+                ],
+                "summary": "Get a report file for a specific user",
+                "operationId": "getUserReport",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Month for which you want to display information, in the format 'yyyy-mm'",
+                        "name": "period",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "User ID in uuid format",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only the changes made by this actor",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "api",
+                            "import",
+                            "ttl-reaper",
+                            "auto-percent",
+                            "rule"
+                        ],
+                        "type": "string",
+                        "description": "Only the changes from this source",
+                        "name": "source",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only the changes whose reason contains this text, case-insensitively",
+                        "name": "reason",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Report file received successfully."
+                    },
+                    "400": {
+                        "description": "Invalid format for parameter 'period' / Unknown source.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid API key.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error / Internal Server Error.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/getUserSegments/{userID}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Return the list of segments the user is a member of.",
+                "tags": [
+                    "segment"
+                ],
+                "summary": "Get user segments",
+                "operationId": "getSegments",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "User ID in uuid format",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User segments received successfully.",
+                        "schema": {
+                            "$ref": "#/definitions/models.SegmentsList"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid format for parameter 'userID'.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid API key.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error / Internal Server Error.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/importMemberships": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Adds/removes users to/from segments in accordance with the lines of a csv file. Every line is either \"user_id,segment,action\" (action is 'add' or 'remove'), or, if the 'segment' parameter is set, a single user ID. A header line starting with \"user_id\" is skipped. Valid lines are applied in batches, invalid ones are returned in the per-line error report. The additions to a segment with a cap are applied in the order of the file until it is full, the rest are counted as capped.",
+                "consumes": [
+                    "text/csv",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "segment"
+                ],
+                "summary": "Import memberships from a csv file",
+                "operationId": "importMemberships",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "csv file, can also be sent as the request body",
+                        "name": "file",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Segment to which all the user IDs of the file are applied",
+                        "name": "segment",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Action for the 'segment' parameter: 'add' (default) or 'remove'",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Validate the file and compute the diff of the memberships without applying it",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "File processed, see the per-line errors and, for a dry run, the diff.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing file / invalid format of 'segment' or 'action' parameters.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid API key.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Segment from the 'segment' parameter not found.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error / Internal Server Error.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/importState": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Restores the service state from an archive created by the export after checking its version and checksum. The 'merge' mode adds the missing segments and memberships, the 'replace' mode makes segments and memberships exactly as in the archive and replaces the report log if the archive contains it.",
+                "consumes": [
+                    "application/gzip",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "backup"
+                ],
+                "summary": "Import the service state",
+                "operationId": "importState",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Archive file, can also be sent as the request body",
+                        "name": "file",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Restore mode: 'merge' (default) or 'replace'",
+                        "name": "mode",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "State restored successfully.",
+                        "schema": {
+                            "$ref": "#/definitions/models.RestoreResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing file / invalid archive / unsupported version / checksum mismatch / invalid mode.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid API key.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error / Internal Server Error.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/listExperimentGroups": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Return all experiment groups in alphabetical order with their variants.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "experiment"
+                ],
+                "summary": "List experiment groups",
+                "operationId": "listExperimentGroups",
+                "responses": {
+                    "200": {
+                        "description": "Experiment groups received successfully.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ExperimentGroupsList"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid API key.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error / Internal Server Error.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/listSegments": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Return the slugs of all segments in alphabetical order, and the activation window and state (scheduled, active or ended) of each segment, and the cap and number of members of the capped ones.",
+                "tags": [
+                    "segment"
+                ],
+                "summary": "List segments",
+                "operationId": "listSegments",
+                "responses": {
+                    "200": {
+                        "description": "Segments received successfully.",
+                        "schema": {
+                            "$ref": "#/definitions/models.SegmentsList"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid API key.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error / Internal Server Error.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/renameSegment": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Changes the slug of the segment, keeping its members, experiment variants and history. The expressions of composite segments are rewritten to the new slug. The old slug keeps working as an alias for 'alias_days' days (30 by default, 0 - no alias): the requests using it get the Deprecation, Sunset and Warning headers. Afterwards the old slug stays reserved, so no other segment can take it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "segment"
+                ],
+                "summary": "Rename a segment",
+                "operationId": "renameSegment",
+                "parameters": [
+                    {
+                        "description": "Current and new slug of the segment and the alias lifetime in days, 0-365",
+                        "name": "segment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RenameRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Segment renamed, the old slug and its expiration time.",
+                        "schema": {
+                            "$ref": "#/definitions/models.SegmentAlias"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing required parameters / invalid format of 'slug' or 'alias_days' parameter / segment with the new slug already exists / the new slug is reserved by another renamed segment.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid API key.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Segment with the given slug not found.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error / Internal Server Error.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/segments/{slug}/history": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Return the audit log of the segment: its creation, renames, changes of the rule, expression, rollout and activation window and its activations, with the old and new values and who made the changes. The events recorded under the previous slugs of the segment are included.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "segment"
+                ],
+                "summary": "Get segment history",
+                "operationId": "getSegmentHistory",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Slug of the segment",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Segment history received successfully.",
+                        "schema": {
+                            "$ref": "#/definitions/models.SegmentHistory"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid format for parameter 'slug'.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid API key.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Segment with the given slug not found.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error / Internal Server Error.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/segments/{slug}/members": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Return a page of the members of the segment, ordered by user ID. Pass the 'next' value of the response as 'after' to get the next page.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "segment"
+                ],
+                "summary": "List segment members",
+                "operationId": "getSegmentMembers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Slug of the segment",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1000,
+                        "description": "Maximum number of members, 1-10000",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Return the members with user IDs greater than this one",
+                        "name": "after",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Segment members received successfully.",
+                        "schema": {
+                            "$ref": "#/definitions/models.MembersList"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid format for parameter 'slug', 'limit' or 'after' / the segment is a percentage rollout, whose members aren't stored.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid API key.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Segment with the given slug not found.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error / Internal Server Error.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/segments/{slug}/stats": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Return the number of the members of the segment and the numbers of the members added and removed on every day of the range, the days without changes included. The daily numbers are read from the daily rollups of the report. The range defaults to the last 30 days up to today and can't be longer than 366 days. For a percentage rollout only the explicitly added users are counted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "segment"
+                ],
+                "summary": "Get segment statistics",
+                "operationId": "getSegmentStats",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Slug of the segment",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First day of the range, in the format 'yyyy-mm-dd'",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day of the range, in the format 'yyyy-mm-dd'",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Segment statistics received successfully.",
+                        "schema": {
+                            "$ref": "#/definitions/models.SegmentStats"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid format for parameter 'slug', 'from' or 'to' / the range is reversed or too long.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid API key.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Segment with the given slug not found.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error / Internal Server Error.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/setSegmentCap": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Sets the maximum number of members of the segment, a request without max_members removes the cap. Adding a user to a full segment is rejected with the Conflict status, and the rules, the expressions and the imports stop at the cap. The members above a lowered cap are kept. Reaching the cap is recorded in the audit log as the 'full' event.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "segment"
+                ],
+                "summary": "Set the cap of a segment",
+                "operationId": "setSegmentCap",
+                "parameters": [
+                    {
+                        "description": "Slug of the segment and its max_members",
+                        "name": "segment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Segment"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Cap set, the segment with its state, cap and number of members.",
+                        "schema": {
+                            "$ref": "#/definitions/models.SegmentState"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing required 'slug' parameter / invalid format of 'slug' parameter / invalid cap / the segment is a percentage rollout.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid API key.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Segment with the given slug not found.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error / Internal Server Error.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/setSegmentExpression": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Sets the expression over other segments (union, intersect, except) that computes the members of the segment, and immediately adds the users of the set and removes the others. Later changes of the other segments flow into the segment. An empty expression turns the segment back into an ordinary one, keeping its members.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "segment"
+                ],
+                "summary": "Set the expression of a segment",
+                "operationId": "setSegmentExpression",
+                "parameters": [
+                    {
+                        "description": "Slug of the segment and its expression",
+                        "name": "segment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Segment"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Expression set, the number of added and removed members.",
+                        "schema": {
+                            "$ref": "#/definitions/models.SyncResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing required 'slug' parameter / invalid format of 'slug' parameter / invalid expression / the segments form a cycle.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid API key.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "The segment or a segment of the expression not found.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error / Internal Server Error.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/setSegmentRollout": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Makes the segment a percentage rollout: its members are the given percentage of all users, computed on read from the hash of the salt and the user ID, so nothing is stored per user. The salt defaults to the slug and can't be changed later, so raising the percentage only adds users. Users added or removed through updateUserSegments or the import are explicit overrides and are written to the report. A missing rollout turns the segment back into an ordinary one, keeping only the explicitly added members.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "segment"
+                ],
+                "summary": "Set the rollout percentage of a segment",
+                "operationId": "setSegmentRollout",
+                "parameters": [
+                    {
+                        "description": "Slug of the segment, its rollout percentage from 0 to 100 and an optional salt",
+                        "name": "segment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Segment"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Rollout set, the segment with its percentage and salt.",
+                        "schema": {
+                            "$ref": "#/definitions/models.Segment"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing required 'slug' parameter / invalid format of 'slug' parameter / invalid rollout / the segment is used by composite segments or an experiment group.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid API key.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Segment with the given slug not found.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error / Internal Server Error.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/setSegmentRule": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Sets the rule over the user attributes that computes the members of the segment, and immediately adds the matching users and removes the others. An empty rule turns the segment back into an ordinary one, keeping its members.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "segment"
+                ],
+                "summary": "Set the rule of a segment",
+                "operationId": "setSegmentRule",
+                "parameters": [
+                    {
+                        "description": "Slug of the segment and its rule",
+                        "name": "segment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Segment"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Rule set, the number of added and removed members.",
+                        "schema": {
+                            "$ref": "#/definitions/models.SyncResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing required 'slug' parameter / invalid format of 'slug' parameter / invalid rule.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid API key.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Segment with the given slug not found.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error / Internal Server Error.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/setSegmentWindow": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Sets the time from which and until which the segment is active. Outside the window the segment isn't returned by getUserSegments, but its memberships are kept and can still be changed. A missing bound leaves the window open on that side, so a request without both bounds removes the window. The activations and deactivations are recorded in the audit log by the scheduler.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "segment"
+                ],
+                "summary": "Set the activation window of a segment",
+                "operationId": "setSegmentWindow",
+                "parameters": [
+                    {
+                        "description": "Slug of the segment and its active_from and active_until in RFC 3339 format",
+                        "name": "segment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Segment"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Window set, the segment with its window and current state.",
+                        "schema": {
+                            "$ref": "#/definitions/models.SegmentState"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing required 'slug' parameter / invalid format of 'slug' parameter / invalid activation window.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid API key.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Segment with the given slug not found.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error / Internal Server Error.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stats": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Return all segments in alphabetical order with the numbers of their members, the number of segments and the total number of memberships. The numbers are read from counters maintained on every change, without counting the members. For a percentage rollout only the explicitly added users are counted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "segment"
+                ],
+                "summary": "Get service statistics",
+                "operationId": "getServiceStats",
+                "responses": {
+                    "200": {
+                        "description": "Service statistics received successfully.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ServiceStats"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid API key.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error / Internal Server Error.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/updateUserAttributes/{userID}": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Merges the given attributes into the stored ones, a null value removes the attribute. Then the rules of all dynamic segments are evaluated for the user and the user is added to or removed from them.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attributes"
+                ],
+                "summary": "Update user attributes",
+                "operationId": "updateUserAttributes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "User ID in uuid format",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Attributes: strings, numbers, booleans or nulls",
+                        "name": "attributes",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserAttributes"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Attributes updated, the resulting attributes and the changes of the dynamic segments.",
+                        "schema": {
+                            "$ref": "#/definitions/models.AttributesResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid format for parameter 'userID' / invalid attributes.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid API key.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error / Internal Server Error.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/updateUserSegments/{userID}": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Add/remove a user from segments in accordance with the transferred lists for adding and deleting. In the strict mode (default) nothing is changed if some of the segments don't exist, and the error names them. The non-strict mode applies the existing segments and returns the diff of the user's memberships with the status of every requested segment (models.MembershipDiff). A dry run goes through all the checks and returns the diff without writing anything, the unknown segments are listed in the diff instead of failing. Adding the user to a segment that reached its cap fails in the strict mode and gets the segment_full status otherwise.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
                 ],
                 "tags": [
                     "segment"
@@ -273,46 +1710,529 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.UpdateRequest"
                         }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only compute the diff without applying it",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Fail if some of the segments don't exist, true by default",
+                        "name": "strict",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User information updated successfully / models.MembershipDiff for a dry run or in the non-strict mode.",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid format for parameter 'userID' / one of the segments is dynamic / the user is already in another variant of an experiment group.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid API key.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Some of the segments not found, the error names them.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "One of the segments to add to is full, the error names it and its fill level.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Database error / Internal Server Error.",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.AssignRequest": {
+            "type": "object",
+            "properties": {
+                "group": {
+                    "type": "string",
+                    "example": "EXP_X"
+                }
+            }
+        },
+        "models.Assignment": {
+            "type": "object",
+            "properties": {
+                "assigned": {
+                    "type": "boolean"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "segment": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Attributes": {
+            "type": "object",
+            "additionalProperties": {}
+        },
+        "models.AttributesResult": {
+            "type": "object",
+            "properties": {
+                "attributes": {
+                    "$ref": "#/definitions/models.Attributes"
+                },
+                "segments_added": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "segments_removed": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.CacheStats": {
+            "type": "object",
+            "properties": {
+                "hits": {
+                    "type": "integer",
+                    "example": 98000
+                },
+                "misses": {
+                    "type": "integer",
+                    "example": 2000
+                },
+                "size": {
+                    "type": "integer",
+                    "example": 10000
+                }
+            }
+        },
+        "models.DailyStats": {
+            "type": "object",
+            "properties": {
+                "added": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2023-08-30"
+                },
+                "removed": {
+                    "type": "integer",
+                    "example": 15
+                }
+            }
+        },
+        "models.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ExperimentGroup": {
+            "type": "object",
+            "properties": {
+                "conflict": {
+                    "description": "ConflictReject (default) or ConflictMove",
+                    "type": "string",
+                    "example": "reject"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "EXP_X"
+                },
+                "salt": {
+                    "description": "the name of the group by default",
+                    "type": "string",
+                    "example": "EXP_X"
+                },
+                "variants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Variant"
                     }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "User information updated successfully.",
-                        "schema": {
-                            "$ref": "#/definitions/models.SuccessResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Invalid format for parameter 'userID'.",
-                        "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Database error / Internal Server Error.",
-                        "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
-                        }
+                }
+            }
+        },
+        "models.ExperimentGroupsList": {
+            "type": "object",
+            "properties": {
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ExperimentGroup"
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.ErrorResponse": {
+        },
+        "models.ImportDiff": {
+            "type": "object",
+            "properties": {
+                "added": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Membership"
+                    }
+                },
+                "no_op": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Membership"
+                    }
+                },
+                "removed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Membership"
+                    }
+                },
+                "unknown": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.ImportLineError": {
             "type": "object",
             "properties": {
                 "error": {
                     "type": "string"
+                },
+                "line": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ImportResult": {
+            "type": "object",
+            "properties": {
+                "applied": {
+                    "description": "number of memberships that actually changed",
+                    "type": "integer"
+                },
+                "capped": {
+                    "description": "number of additions left out because their segments reached the cap",
+                    "type": "integer"
+                },
+                "diff": {
+                    "description": "only for a dry run",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ImportDiff"
+                        }
+                    ]
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ImportLineError"
+                    }
+                },
+                "total": {
+                    "description": "number of processed lines, without the header",
+                    "type": "integer"
+                },
+                "valid": {
+                    "description": "number of lines that passed validation",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.MembersList": {
+            "type": "object",
+            "properties": {
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "next": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Membership": {
+            "type": "object",
+            "properties": {
+                "segment": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RenameRequest": {
+            "type": "object",
+            "properties": {
+                "alias_days": {
+                    "type": "integer",
+                    "example": 30
+                },
+                "new_slug": {
+                    "type": "string",
+                    "example": "AVITO_VOICE_NOTES"
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "AVITO_VOICE_MESSAGES"
+                }
+            }
+        },
+        "models.RestoreResult": {
+            "type": "object",
+            "properties": {
+                "aliases_restored": {
+                    "type": "integer"
+                },
+                "attributes_restored": {
+                    "type": "integer"
+                },
+                "experiments_created": {
+                    "type": "integer"
+                },
+                "memberships_added": {
+                    "type": "integer"
+                },
+                "memberships_removed": {
+                    "type": "integer"
+                },
+                "mode": {
+                    "type": "string"
+                },
+                "report_restored": {
+                    "type": "integer"
+                },
+                "segments_created": {
+                    "type": "integer"
+                },
+                "segments_deleted": {
+                    "type": "integer"
                 }
             }
         },
         "models.Segment": {
             "type": "object",
             "properties": {
+                "active_from": {
+                    "description": "outside the activation window the segment isn't returned to its members, but the memberships are kept",
+                    "type": "string",
+                    "example": "2023-09-01T00:00:00+03:00"
+                },
+                "active_until": {
+                    "type": "string",
+                    "example": "2023-10-01T00:00:00+03:00"
+                },
+                "expression": {
+                    "description": "members of a composite segment are computed from other segments",
+                    "type": "string",
+                    "example": "AVITO_VOICE_MESSAGES except AVITO_DISCOUNT_30"
+                },
+                "max_members": {
+                    "description": "additions beyond the cap are rejected, enrollments stop at it",
+                    "type": "integer",
+                    "example": 10000
+                },
+                "rollout": {
+                    "description": "members of a percentage rollout are computed from the hash of the user ID",
+                    "type": "number",
+                    "example": 25
+                },
+                "rule": {
+                    "description": "members of a segment with a rule are computed from the user attributes",
+                    "type": "string",
+                    "example": "city == \"Moscow\" and listings \u003e 5"
+                },
+                "salt": {
+                    "description": "salt of the hash of a percentage rollout, fixed once set",
+                    "type": "string",
+                    "example": "AVITO_VOICE_MESSAGES"
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "AVITO_VOICE_MESSAGES"
+                }
+            }
+        },
+        "models.SegmentAlias": {
+            "type": "object",
+            "properties": {
+                "alias": {
+                    "type": "string",
+                    "example": "AVITO_VOICE_MESSAGES"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2023-10-01T00:00:00+03:00"
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "AVITO_VOICE_NOTES"
+                }
+            }
+        },
+        "models.SegmentEvent": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string",
+                    "example": "ops"
+                },
+                "event": {
+                    "type": "string",
+                    "example": "rule_changed"
+                },
+                "new_value": {
+                    "type": "string",
+                    "example": "city == \"Moscow\""
+                },
+                "old_value": {
+                    "type": "string",
+                    "example": ""
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "promo in Moscow"
+                },
+                "segment": {
+                    "type": "string",
+                    "example": "AVITO_VOICE_MESSAGES"
+                },
+                "source": {
+                    "type": "string",
+                    "example": "api"
+                },
+                "time": {
+                    "type": "string",
+                    "example": "2023-08-30T14:38:42+03:00"
+                }
+            }
+        },
+        "models.SegmentHistory": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SegmentEvent"
+                    }
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "AVITO_VOICE_MESSAGES"
+                }
+            }
+        },
+        "models.SegmentSize": {
+            "type": "object",
+            "properties": {
+                "max_members": {
+                    "type": "integer",
+                    "example": 10000
+                },
+                "members": {
+                    "type": "integer",
+                    "example": 9850
+                },
+                "rollout": {
+                    "type": "number",
+                    "example": 10
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "AVITO_VOICE_MESSAGES"
+                }
+            }
+        },
+        "models.SegmentState": {
+            "type": "object",
+            "properties": {
+                "active_from": {
+                    "type": "string",
+                    "example": "2023-09-01T00:00:00+03:00"
+                },
+                "active_until": {
+                    "type": "string",
+                    "example": "2023-10-01T00:00:00+03:00"
+                },
+                "max_members": {
+                    "type": "integer",
+                    "example": 10000
+                },
+                "members": {
+                    "type": "integer",
+                    "example": 9850
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "AVITO_VOICE_MESSAGES"
+                },
+                "state": {
+                    "type": "string",
+                    "example": "scheduled"
+                }
+            }
+        },
+        "models.SegmentStats": {
+            "type": "object",
+            "properties": {
+                "days": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DailyStats"
+                    }
+                },
+                "from": {
+                    "type": "string",
+                    "example": "2023-08-01"
+                },
+                "max_members": {
+                    "type": "integer",
+                    "example": 10000
+                },
+                "members": {
+                    "type": "integer",
+                    "example": 9850
+                },
                 "slug": {
                     "type": "string",
                     "example": "AVITO_VOICE_MESSAGES"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2023-08-30"
                 }
             }
         },
@@ -324,6 +2244,39 @@ const docTemplate = `{
                     "items": {
                         "type": "string"
                     }
+                },
+                "states": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SegmentState"
+                    }
+                }
+            }
+        },
+        "models.ServiceStats": {
+            "type": "object",
+            "properties": {
+                "cache": {
+                    "description": "nil if the user segments cache is disabled",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.CacheStats"
+                        }
+                    ]
+                },
+                "memberships": {
+                    "type": "integer",
+                    "example": 120500
+                },
+                "segments": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "sizes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SegmentSize"
+                    }
                 }
             }
         },
@@ -335,6 +2288,17 @@ const docTemplate = `{
                 }
             }
         },
+        "models.SyncResult": {
+            "type": "object",
+            "properties": {
+                "added": {
+                    "type": "integer"
+                },
+                "removed": {
+                    "type": "integer"
+                }
+            }
+        },
         "models.UpdateRequest": {
             "type": "object",
             "properties": {
@@ -351,6 +2315,35 @@ const docTemplate = `{
                     }
                 }
             }
+        },
+        "models.UserAttributes": {
+            "type": "object",
+            "properties": {
+                "attributes": {
+                    "$ref": "#/definitions/models.Attributes"
+                }
+            }
+        },
+        "models.Variant": {
+            "type": "object",
+            "properties": {
+                "slug": {
+                    "type": "string",
+                    "example": "EXP_X_CONTROL"
+                },
+                "weight": {
+                    "type": "integer",
+                    "example": 50
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "description": "Required only if the API keys are configured (API_KEYS).",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
         }
     }
 }`
@@ -10,8 +10,8 @@ import (
 	"segmentation-service/internal/application"
 	"segmentation-service/internal/config"
 	"segmentation-service/pkg/infra/logger"
+	"segmentation-service/pkg/infra/tracing"
 	"syscall"
-	"time"
 )
 
 func main() {
@@ -27,28 +27,66 @@ func main() {
 
 	log.Debug("Get config: ", "", cfg)
 
+	// initialize tracing
+	optsTracing := tracing.TracingOptions{
+		Exporter:     cfg.TracingExporter,
+		OTLPEndpoint: cfg.OTLPEndpoint,
+		ServiceName:  cfg.ServiceName,
+	}
+	shutdownTracing, err := tracing.New(optsTracing)
+	if err != nil {
+		log.Error("tracing not initialized", "desc", err.Error())
+		os.Exit(1)
+	}
+
 	optsApp := application.AppOptions{
 		DB_url:      cfg.DB_URL,
 		HTTP_port:   cfg.HTTP_port,
+		GRPC_port:   cfg.GRPC_port,
 		Timeout:     cfg.Timeout,
 		IdleTimeout: cfg.IdleTimeout,
+		ServiceName: cfg.ServiceName,
+		GracePeriod: cfg.GracePeriod,
+		CacheSize:   cfg.CacheSize,
+		CacheTTL:    cfg.CacheTTL,
+		APIKeys:     cfg.APIKeys,
+
+		SchedulerInterval: cfg.SchedulerInterval,
+
+		ReportRetentionMonths: cfg.ReportRetentionMonths,
+		ReportArchiveDir:      cfg.ReportArchiveDir,
+		ReportArchiveInterval: cfg.ReportArchiveInterval,
+		ReportLocation:        cfg.ReportLocation(),
+
+		DBInitialBackoff: cfg.DBInitialBackoff,
+		DBMaxBackoff:     cfg.DBMaxBackoff,
+		DBMaxWait:        cfg.DBMaxWait,
 	}
 	app := application.New(optsApp)
 
-	err := app.Start()
-	if err != nil {
-		log.Error("app not started", "desc", err.Error())
+	startErr := app.Start(ctx)
+	if startErr != nil {
+		log.Error("app not started", "desc", startErr.Error())
+	} else {
+		<-ctx.Done()
 	}
-	<-ctx.Done()
 
 	// graceful shutdown
 	log.Info("shutting down...")
-	stopCtx, stopCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), cfg.StopTimeout)
 	defer stopCancel()
 
 	err = app.Stop(stopCtx)
 	if err != nil {
 		log.Error("app stop error", "desc", err.Error())
 	}
+	if err = shutdownTracing(stopCtx); err != nil {
+		log.Error("tracing shutdown error", "desc", err.Error())
+	}
 	log.Info("app stopped")
+
+	if startErr != nil {
+		stopCancel()
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,132 @@
+// The archive command exports the service state to an archive file or restores it, working directly with the database
+// from DB_URL. Usage:
+//
+//	archive export [-report] [-out file]
+//	archive import [-mode merge|replace] file
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"segmentation-service/internal/adapters/archive"
+	"segmentation-service/internal/adapters/db"
+	"segmentation-service/internal/config"
+	"segmentation-service/internal/domain/models"
+	"segmentation-service/internal/domain/usecases"
+	"segmentation-service/pkg/infra/logger"
+	"syscall"
+	"time"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer cancel()
+
+	var err error
+	switch os.Args[1] {
+	case "export":
+		err = export(ctx, os.Args[2:])
+	case "import":
+		err = restore(ctx, os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "archive:", err)
+		cancel()
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: archive export [-report] [-out file]")
+	fmt.Fprintln(os.Stderr, "       archive import [-mode merge|replace] file")
+	os.Exit(2)
+}
+
+func export(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	withReport := fs.Bool("report", false, "include the report log")
+	out := fs.String("out", time.Now().Format("segmentation-2006-01-02T15-04-05.json.gz"), "archive file")
+	fs.Parse(args)
+
+	svc, closeStorage, err := newService(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeStorage()
+
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err = svc.ExportState(ctx, f, *withReport); err != nil {
+		f.Close()
+		os.Remove(*out)
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	fmt.Println(*out)
+	return nil
+}
+
+func restore(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	mode := fs.String("mode", models.RestoreMerge, "restore mode: merge or replace")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		usage()
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	svc, closeStorage, err := newService(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeStorage()
+
+	result, err := svc.ImportState(ctx, f, *mode)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(result)
+}
+
+// newService connects to the database and returns the service working with it.
+func newService(ctx context.Context) (*usecases.SegmentSvc, func(), error) {
+	cfg := config.Get()
+	logger.New(logger.LoggerOptions{IsProd: cfg.IsProd})
+
+	// the export of the report includes the archived months
+	reportArchive, err := archive.New(cfg.ReportArchiveDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	optsConnect := db.ConnectOptions{
+		InitialBackoff: cfg.DBInitialBackoff,
+		MaxBackoff:     cfg.DBMaxBackoff,
+		MaxWait:        cfg.DBMaxWait,
+	}
+	storage, err := db.New(ctx, cfg.DB_URL, optsConnect, cfg.ReportLocation())
+	if err != nil {
+		return nil, nil, fmt.Errorf("storage creation failed: %w", err)
+	}
+	svc := usecases.New(storage, usecases.WithReportArchive(reportArchive, 0))
+	return svc, func() { storage.Close(context.Background()) }, nil
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+)
+
+type generateOptions struct {
+	Concurrency int
+	Requests    int           // total number of requests, unlimited if 0
+	Duration    time.Duration // how long the load runs, unlimited if 0
+	Seed        int64
+}
+
+// sample is the outcome of one request.
+type sample struct {
+	op      string
+	latency time.Duration
+	err     error
+}
+
+// generate sends the operations of the mix from the concurrent workers until the number of requests is sent,
+// the duration passes or ctx is cancelled. The requests in flight when the time is up are cancelled and not counted.
+func generate(ctx context.Context, w *workload, mix []weight, opts generateOptions) summary {
+	if opts.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Duration)
+		defer cancel()
+	}
+
+	var sent atomic.Int64
+	samples := make([][]sample, opts.Concurrency)
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := range opts.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// every worker has its own source, the sources aren't safe for concurrent use
+			rnd := rand.New(rand.NewSource(opts.Seed + int64(i)))
+			for ctx.Err() == nil {
+				if opts.Requests > 0 && sent.Add(1) > int64(opts.Requests) {
+					return
+				}
+				op := pick(mix, rnd)
+				begin := time.Now()
+				err := w.do(ctx, op, rnd)
+				if ctx.Err() != nil {
+					return
+				}
+				samples[i] = append(samples[i], sample{op: op, latency: time.Since(begin), err: err})
+			}
+		}()
+	}
+	wg.Wait()
+	return summarize(slices.Concat(samples...), time.Since(start))
+}
+
+// opStats are the results of an operation, the latencies are in milliseconds.
+type opStats struct {
+	Op         string  `json:"op"`
+	Requests   int     `json:"requests"`
+	Errors     int     `json:"errors"`
+	Throughput float64 `json:"throughput_rps"`
+	P50        float64 `json:"p50_ms"`
+	P90        float64 `json:"p90_ms"`
+	P99        float64 `json:"p99_ms"`
+	Max        float64 `json:"max_ms"`
+	FirstError string  `json:"first_error,omitempty"`
+}
+
+// summary are the results of the run: the totals and the results of every operation sent.
+type summary struct {
+	Duration float64   `json:"duration_s"`
+	Total    opStats   `json:"total"`
+	Ops      []opStats `json:"ops"`
+}
+
+func summarize(samples []sample, elapsed time.Duration) summary {
+	s := summary{Duration: elapsed.Seconds(), Total: stats("total", samples, elapsed), Ops: []opStats{}}
+	for _, op := range operations {
+		var of []sample
+		for _, smp := range samples {
+			if smp.op == op {
+				of = append(of, smp)
+			}
+		}
+		if len(of) > 0 {
+			s.Ops = append(s.Ops, stats(op, of, elapsed))
+		}
+	}
+	return s
+}
+
+// stats computes the results of the samples, the failed requests are included in the latencies.
+func stats(op string, samples []sample, elapsed time.Duration) opStats {
+	st := opStats{Op: op, Requests: len(samples)}
+	if len(samples) == 0 {
+		return st
+	}
+	latencies := make([]time.Duration, 0, len(samples))
+	for _, smp := range samples {
+		latencies = append(latencies, smp.latency)
+		if smp.err != nil {
+			if st.Errors == 0 {
+				st.FirstError = smp.err.Error()
+			}
+			st.Errors++
+		}
+	}
+	slices.Sort(latencies)
+	if elapsed > 0 {
+		st.Throughput = float64(len(samples)) / elapsed.Seconds()
+	}
+	st.P50, st.P90, st.P99 = percentile(latencies, 50), percentile(latencies, 90), percentile(latencies, 99)
+	st.Max = milliseconds(latencies[len(latencies)-1])
+	return st
+}
+
+// percentile returns the nearest-rank percentile of the sorted latencies in milliseconds.
+func percentile(sorted []time.Duration, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return milliseconds(sorted[max(rank-1, 0)])
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func printSummary(w io.Writer, format string, s summary) error {
+	if format == outputJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(s)
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "OP\tREQUESTS\tERRORS\tRPS\tP50_MS\tP90_MS\tP99_MS\tMAX_MS\t")
+	for _, st := range append(s.Ops, s.Total) {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\t%s\t%s\t%s\t\n", st.Op, st.Requests, st.Errors,
+			number(st.Throughput), number(st.P50), number(st.P90), number(st.P99), number(st.Max))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "%d requests in %.1fs\n", s.Total.Requests, s.Duration)
+	return err
+}
+
+func number(f float64) string {
+	return strconv.FormatFloat(f, 'f', 2, 64)
+}
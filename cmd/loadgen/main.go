@@ -0,0 +1,129 @@
+// The loadgen command drives the http API of the service with a mix of operations and reports the throughput
+// and the latency percentiles of every operation. Usage:
+//
+//	loadgen [-url URL] [-api-key KEY] [-mix create=1,update=20,read=75,report=4] [-users N] [-segments N]
+//	        [-concurrency N] [-duration D | -requests N] [-period yyyy-mm] [-o table|json]
+//
+// Before the run the segments PREFIX_1..PREFIX_N are created, unless they exist. The operations are:
+// create - create a new segment PREFIX_<run>_<n>, update - add a user to one to three segments and remove them from
+// as many others, read - get the segments of a user, report - download the report for the month. The users are
+// the same in every run, so that repeated runs work on the same data. The created segments stay in the database.
+// The exit code is 0 if all requests succeeded, 1 if the segments couldn't be created, 2 for an invalid command line
+// and 3 if some requests failed.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+const (
+	exitOK     = 0
+	exitError  = 1
+	exitUsage  = 2
+	exitFailed = 3
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+const defaultURL = "http://localhost:3000"
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	cancel()
+	os.Exit(code)
+}
+
+// run executes the load and returns the exit code. An interrupt stops the load early, the summary is still printed.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("loadgen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	baseURL := fs.String("url", defaultURL, "base URL of the service")
+	apiKey := fs.String("api-key", os.Getenv("LOADGEN_API_KEY"), "API key (default $LOADGEN_API_KEY)")
+	mixFlag := fs.String("mix", "create=1,update=20,read=75,report=4", "relative weights of the operations")
+	users := fs.Int("users", 1000, "number of users")
+	segments := fs.Int("segments", 20, "number of segments the users are added to and removed from")
+	prefix := fs.String("prefix", "LOADGEN", "prefix of the slugs of the segments")
+	concurrency := fs.Int("concurrency", 8, "number of concurrent requests")
+	duration := fs.Duration("duration", 30*time.Second, "how long the load runs")
+	requests := fs.Int("requests", 0, "number of requests to send instead of running for -duration")
+	period := fs.String("period", time.Now().Format("2006-01"), "month of the downloaded report")
+	timeout := fs.Duration("timeout", 30*time.Second, "request timeout")
+	seed := fs.Int64("seed", 1, "seed of the random choices, the same seed repeats the sequence of every worker")
+	format := fs.String("o", outputTable, "output format: table or json")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	mix, err := parseMix(*mixFlag)
+	if err == nil && fs.NArg() != 0 {
+		err = fmt.Errorf("unexpected arguments %q", fs.Args())
+	}
+	if err == nil {
+		err = validateOptions(*users, *segments, *concurrency, *requests, *duration, *format)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "loadgen:", err)
+		fs.Usage()
+		return exitUsage
+	}
+
+	c := newClient(*baseURL, *apiKey, &http.Client{
+		Timeout:   *timeout,
+		Transport: &http.Transport{MaxIdleConnsPerHost: *concurrency},
+	})
+	w := newWorkload(c, *prefix, *users, *segments, *period)
+	if err = w.setup(ctx); err != nil {
+		fmt.Fprintln(stderr, "loadgen: creating the segments failed:", err)
+		return exitError
+	}
+
+	opts := generateOptions{Concurrency: *concurrency, Requests: *requests, Duration: *duration, Seed: *seed}
+	if *requests > 0 {
+		opts.Duration = 0
+	}
+	s := generate(ctx, w, mix, opts)
+	if err = printSummary(stdout, *format, s); err != nil {
+		fmt.Fprintln(stderr, "loadgen:", err)
+		return exitError
+	}
+	for _, op := range s.Ops {
+		if op.FirstError != "" {
+			fmt.Fprintf(stderr, "loadgen: %d %s requests failed, the first one with: %s\n", op.Errors, op.Op, op.FirstError)
+		}
+	}
+	if s.Total.Errors > 0 {
+		return exitFailed
+	}
+	return exitOK
+}
+
+func validateOptions(users, segments, concurrency, requests int, duration time.Duration, format string) error {
+	switch {
+	case users <= 0:
+		return errors.New("-users must be positive")
+	case segments < 2:
+		return errors.New("-segments must be at least 2")
+	case concurrency <= 0:
+		return errors.New("-concurrency must be positive")
+	case requests < 0:
+		return errors.New("-requests must not be negative")
+	case requests == 0 && duration <= 0:
+		return errors.New("-duration must be positive")
+	case format != outputTable && format != outputJSON:
+		return fmt.Errorf("invalid output format '%s'", format)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"segmentation-service/internal/domain/models"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+)
+
+// testServer imitates the http API and counts the requests by path; LOADGEN_1 exists before the run.
+type testServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests map[string]int
+	updates  []models.UpdateRequest
+	failing  string // the path prefix answered with an internal error
+}
+
+func newTestServer(t *testing.T) *testServer {
+	s := &testServer{requests: make(map[string]int)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		path := strings.TrimPrefix(r.URL.Path, "/api/v1")
+		s.mu.Lock()
+		s.requests[path]++
+		failing := s.failing != "" && strings.HasPrefix(path, s.failing)
+		if strings.HasPrefix(path, "/updateUserSegments/") {
+			var data models.UpdateRequest
+			json.Unmarshal(body, &data)
+			s.updates = append(s.updates, data)
+		}
+		s.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Header.Get("X-API-Key") != "secret" || r.Header.Get("X-Actor") != "loadgen":
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(models.ErrorResponse{ErrorMsg: models.ErrUnauthorized.Error()})
+		case failing:
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(models.ErrorResponse{ErrorMsg: "some error"})
+		case path == "/createSegment" && strings.Contains(string(body), `"LOADGEN_1"`):
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(models.ErrorResponse{ErrorMsg: models.ErrSegmentAlreadyExists.Error()})
+		case strings.HasPrefix(path, "/getReport/"):
+			w.Header().Set("Content-Type", "text/csv")
+			io.WriteString(w, "550e8400-e29b-41d4-a716-446655440000,LOADGEN_1,add,2023-09-01 10:00:00,loadgen,api,\n")
+		default:
+			json.NewEncoder(w).Encode(models.SuccessResponse{SuccessMsg: "ok"})
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// count returns the number of requests of the path.
+func (s *testServer) count(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[path]
+}
+
+func TestRun(t *testing.T) {
+	srv := newTestServer(t)
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), []string{
+		"-url", srv.URL, "-api-key", "secret", "-users", "10", "-segments", "4", "-concurrency", "4",
+		"-requests", "200", "-mix", "create=1,update=2,read=3,report=1", "-period", "2023-09", "-o", "json",
+	}, &stdout, &stderr)
+	require.Equal(t, exitOK, code, stderr.String())
+
+	var s summary
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &s))
+	assert.Equal(t, 200, s.Total.Requests)
+	assert.Equal(t, 0, s.Total.Errors)
+	require.Len(t, s.Ops, 4)
+	sum := 0
+	for _, op := range s.Ops {
+		sum += op.Requests
+		assert.Assert(t, op.P50 <= op.P90 && op.P90 <= op.P99 && op.P99 <= op.Max)
+	}
+	assert.Equal(t, 200, sum)
+
+	// the segments of the workload are created before the load, the existing one is kept
+	assert.Equal(t, 4+s.Ops[0].Requests, srv.count("/createSegment"))
+	assert.Equal(t, s.Ops[3].Requests, srv.count("/getReport/2023-09"))
+	for _, data := range srv.updates {
+		require.NotEmpty(t, data.SegmentsToAdd)
+		require.Len(t, data.SegmentsToRemove, len(data.SegmentsToAdd))
+		for _, slug := range data.SegmentsToAdd {
+			assert.Assert(t, !slices.Contains(data.SegmentsToRemove, slug))
+		}
+	}
+	assert.Equal(t, s.Ops[1].Requests, len(srv.updates))
+}
+
+func TestRunFailedRequests(t *testing.T) {
+	srv := newTestServer(t)
+	srv.failing = "/getReport/"
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), []string{
+		"-url", srv.URL, "-api-key", "secret", "-segments", "2", "-requests", "20", "-mix", "read=1,report=1",
+	}, &stdout, &stderr)
+	assert.Equal(t, exitFailed, code)
+	assert.Assert(t, strings.Contains(stdout.String(), "20 requests in"))
+	assert.Assert(t, strings.Contains(stderr.String(), "report requests failed, the first one with: some error (500)"), stderr.String())
+}
+
+func TestRunDuration(t *testing.T) {
+	srv := newTestServer(t)
+	var stdout, stderr bytes.Buffer
+	start := time.Now()
+	code := run(context.Background(), []string{
+		"-url", srv.URL, "-api-key", "secret", "-segments", "2", "-duration", "200ms", "-mix", "read=1",
+	}, &stdout, &stderr)
+	require.Equal(t, exitOK, code, stderr.String())
+	assert.Assert(t, time.Since(start) < 5*time.Second)
+	assert.Assert(t, srv.count("/createSegment") == 2)
+}
+
+func TestRunSetupFailed(t *testing.T) {
+	srv := newTestServer(t)
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), []string{"-url", srv.URL, "-api-key", "wrong", "-requests", "1"}, &stdout, &stderr)
+	assert.Equal(t, exitError, code)
+	assert.Assert(t, strings.Contains(stderr.String(), "creating the segments failed: missing or invalid API key (401)"), stderr.String())
+}
+
+func TestRunUsage(t *testing.T) {
+	for _, args := range [][]string{
+		{"-mix", "read=1,write=1"},
+		{"-mix", "read=0"},
+		{"-mix", "read=1,read=2"},
+		{"-mix", "read"},
+		{"-segments", "1"},
+		{"-users", "0"},
+		{"-requests", "0", "-duration", "0"},
+		{"-o", "xml"},
+		{"extra"},
+	} {
+		var stdout, stderr bytes.Buffer
+		assert.Equal(t, exitUsage, run(context.Background(), args, &stdout, &stderr), args)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	latencies := make([]time.Duration, 100)
+	for i := range latencies {
+		latencies[i] = time.Duration(i+1) * time.Millisecond
+	}
+	assert.Equal(t, 50.0, percentile(latencies, 50))
+	assert.Equal(t, 99.0, percentile(latencies, 99))
+	assert.Equal(t, 100.0, percentile(latencies, 100))
+	assert.Equal(t, 7.0, percentile([]time.Duration{7 * time.Millisecond}, 99))
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"segmentation-service/internal/domain/models"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Operations of the load.
+const (
+	opCreate = "create"
+	opUpdate = "update"
+	opRead   = "read"
+	opReport = "report"
+)
+
+// operations are the known operations in the order of the summary.
+var operations = []string{opCreate, opUpdate, opRead, opReport}
+
+// weight is the relative frequency of an operation in the mix.
+type weight struct {
+	op     string
+	weight int
+}
+
+// parseMix parses the comma-separated op=weight pairs. The operations left out aren't sent.
+func parseMix(s string) ([]weight, error) {
+	var mix []weight
+	total := 0
+	for _, pair := range strings.Split(s, ",") {
+		op, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid mix entry '%s', expected op=weight", pair)
+		}
+		if !slices.Contains(operations, op) {
+			return nil, fmt.Errorf("unknown operation '%s', expected one of %s", op, strings.Join(operations, ", "))
+		}
+		if slices.ContainsFunc(mix, func(w weight) bool { return w.op == op }) {
+			return nil, fmt.Errorf("operation '%s' is repeated in the mix", op)
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid weight '%s' of operation '%s'", value, op)
+		}
+		mix = append(mix, weight{op: op, weight: n})
+		total += n
+	}
+	if total == 0 {
+		return nil, errors.New("the weights of the mix add up to zero")
+	}
+	return mix, nil
+}
+
+// pick returns a random operation of the mix with the probability of its weight.
+func pick(mix []weight, rnd *rand.Rand) string {
+	total := 0
+	for _, w := range mix {
+		total += w.weight
+	}
+	n := rnd.Intn(total)
+	for _, w := range mix {
+		if n < w.weight {
+			return w.op
+		}
+		n -= w.weight
+	}
+	return mix[len(mix)-1].op
+}
+
+// client sends the requests of the load to the http API.
+type client struct {
+	baseURL string
+	apiKey  string
+	http    *http.Client
+}
+
+// apiError is a response of the service with an error status.
+type apiError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s (%d)", e.Message, e.StatusCode)
+}
+
+func newClient(baseURL, apiKey string, httpClient *http.Client) *client {
+	return &client{baseURL: strings.TrimSuffix(baseURL, "/") + "/api/v1", apiKey: apiKey, http: httpClient}
+}
+
+// send executes the request with the JSON body, if it isn't nil, and reads the response to the end, so that
+// the time of a download includes the transfer and the connection is reused.
+func (c *client) send(ctx context.Context, method, path string, body any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+	// the changes are attributed to the load in the report
+	req.Header.Set("X-Actor", "loadgen")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		var errResp models.ErrorResponse
+		if err = json.NewDecoder(resp.Body).Decode(&errResp); err != nil || errResp.ErrorMsg == "" {
+			errResp.ErrorMsg = http.StatusText(resp.StatusCode)
+		}
+		return &apiError{StatusCode: resp.StatusCode, Message: errResp.ErrorMsg}
+	}
+	_, err = io.Copy(io.Discard, resp.Body)
+	return err
+}
+
+// workload holds the users and segments the operations work on.
+type workload struct {
+	client   *client
+	users    []uuid.UUID
+	segments []string
+	prefix   string
+	run      string // tells the segments created by this run from the ones of the previous runs
+	period   string
+	created  atomic.Int64
+}
+
+// userNamespace is the namespace of the UUIDs of the users, derived from their numbers.
+var userNamespace = uuid.MustParse("6f1c3d52-7c0e-4b8a-9a51-3f0b2d0c9e47")
+
+func newWorkload(c *client, prefix string, users, segments int, period string) *workload {
+	w := &workload{
+		client: c,
+		prefix: prefix,
+		run:    strconv.FormatInt(time.Now().UnixNano(), 36),
+		period: period,
+	}
+	for i := range users {
+		w.users = append(w.users, uuid.NewSHA1(userNamespace, []byte(strconv.Itoa(i))))
+	}
+	for i := range segments {
+		w.segments = append(w.segments, fmt.Sprintf("%s_%d", prefix, i+1))
+	}
+	return w
+}
+
+// setup creates the segments of the workload, the existing ones are kept.
+func (w *workload) setup(ctx context.Context) error {
+	for _, slug := range w.segments {
+		err := w.client.send(ctx, http.MethodPost, "/createSegment", models.Segment{Slug: slug})
+		var apiErr *apiError
+		if errors.As(err, &apiErr) && apiErr.Message == models.ErrSegmentAlreadyExists.Error() {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// do sends one request of the operation.
+func (w *workload) do(ctx context.Context, op string, rnd *rand.Rand) error {
+	switch op {
+	case opCreate:
+		slug := fmt.Sprintf("%s_%s_%d", w.prefix, w.run, w.created.Add(1))
+		return w.client.send(ctx, http.MethodPost, "/createSegment", models.Segment{Slug: slug})
+	case opUpdate:
+		// up to three segments to add and as many others to remove
+		n := 1 + rnd.Intn(min(3, len(w.segments)/2))
+		order := rnd.Perm(len(w.segments))
+		data := models.UpdateRequest{}
+		for _, i := range order[:n] {
+			data.SegmentsToAdd = append(data.SegmentsToAdd, w.segments[i])
+		}
+		for _, i := range order[n : 2*n] {
+			data.SegmentsToRemove = append(data.SegmentsToRemove, w.segments[i])
+		}
+		return w.client.send(ctx, http.MethodPost, "/updateUserSegments/"+w.user(rnd).String(), data)
+	case opRead:
+		return w.client.send(ctx, http.MethodGet, "/getUserSegments/"+w.user(rnd).String(), nil)
+	case opReport:
+		return w.client.send(ctx, http.MethodGet, "/getReport/"+w.period, nil)
+	}
+	return fmt.Errorf("unknown operation '%s'", op)
+}
+
+func (w *workload) user(rnd *rand.Rand) uuid.UUID {
+	return w.users[rnd.Intn(len(w.users))]
+}
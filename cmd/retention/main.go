@@ -0,0 +1,128 @@
+// The retention command archives the old report rows, working directly with the database from DB_URL and the archive
+// directory from REPORT_ARCHIVE_DIR. Usage:
+//
+//	retention run [-months n]
+//	retention status
+//
+// run moves the report rows older than n months (REPORT_RETENTION_MONTHS by default) to the archive, resuming
+// the months whose archival failed, and prints the archived months. status prints the log of the archival.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"segmentation-service/internal/adapters/archive"
+	"segmentation-service/internal/adapters/db"
+	"segmentation-service/internal/config"
+	"segmentation-service/internal/domain/models"
+	"segmentation-service/internal/domain/usecases"
+	"segmentation-service/pkg/infra/logger"
+	"syscall"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer cancel()
+
+	var err error
+	switch os.Args[1] {
+	case "run":
+		err = run(ctx, os.Args[2:])
+	case "status":
+		err = status(ctx, os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "retention:", err)
+		cancel()
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: retention run [-months n]")
+	fmt.Fprintln(os.Stderr, "       retention status")
+	os.Exit(2)
+}
+
+func run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	months := fs.Int("months", config.Get().ReportRetentionMonths, "number of months the report rows are kept in the database")
+	fs.Parse(args)
+	if fs.NArg() != 0 || *months <= 0 {
+		usage()
+	}
+
+	svc, closeStorage, err := newService(ctx, *months)
+	if err != nil {
+		return err
+	}
+	defer closeStorage()
+
+	runs, err := svc.ArchiveReports(ctx)
+	if err != nil {
+		return err
+	}
+	if runs == nil {
+		runs = []models.ReportArchiveRun{}
+	}
+	return printJSON(runs)
+}
+
+func status(ctx context.Context, args []string) error {
+	if len(args) != 0 {
+		usage()
+	}
+
+	svc, closeStorage, err := newService(ctx, 0)
+	if err != nil {
+		return err
+	}
+	defer closeStorage()
+
+	runs, err := svc.GetReportArchiveRuns(ctx)
+	if err != nil {
+		return err
+	}
+	if runs == nil {
+		runs = []models.ReportArchiveRun{}
+	}
+	return printJSON(runs)
+}
+
+func printJSON(value any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(value)
+}
+
+// newService connects to the database and returns the service working with it and with the archive, keeping
+// the report rows for the given number of months.
+func newService(ctx context.Context, months int) (*usecases.SegmentSvc, func(), error) {
+	cfg := config.Get()
+	logger.New(logger.LoggerOptions{IsProd: cfg.IsProd})
+
+	reportArchive, err := archive.New(cfg.ReportArchiveDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	optsConnect := db.ConnectOptions{
+		InitialBackoff: cfg.DBInitialBackoff,
+		MaxBackoff:     cfg.DBMaxBackoff,
+		MaxWait:        cfg.DBMaxWait,
+	}
+	storage, err := db.New(ctx, cfg.DB_URL, optsConnect, cfg.ReportLocation())
+	if err != nil {
+		return nil, nil, fmt.Errorf("storage creation failed: %w", err)
+	}
+	svc := usecases.New(storage, usecases.WithReportArchive(reportArchive, months))
+	return svc, func() { storage.Close(context.Background()) }, nil
+}
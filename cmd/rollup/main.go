@@ -0,0 +1,145 @@
+// The rollup command maintains the daily rollups of the report, working directly with the database from DB_URL.
+// Usage:
+//
+//	rollup backfill [-from yyyy-mm-dd] [-to yyyy-mm-dd]
+//	rollup check [-from yyyy-mm-dd] [-to yyyy-mm-dd]
+//
+// backfill rebuilds the rollups of the days from the report rows, e.g. for the history written before the rollups
+// existed, and check compares them and prints the differences. Without the bounds the whole history is processed.
+// check exits with the code 3 if some rollups don't match the report.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"segmentation-service/internal/adapters/db"
+	"segmentation-service/internal/config"
+	"segmentation-service/internal/domain/models"
+	"segmentation-service/internal/domain/usecases"
+	"segmentation-service/pkg/infra/logger"
+	"syscall"
+	"time"
+)
+
+// exitMismatch is the exit code of a check that found differences.
+const exitMismatch = 3
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer cancel()
+
+	var err error
+	switch os.Args[1] {
+	case "backfill":
+		err = backfill(ctx, os.Args[2:])
+	case "check":
+		var consistent bool
+		consistent, err = check(ctx, os.Args[2:])
+		if err == nil && !consistent {
+			cancel()
+			os.Exit(exitMismatch)
+		}
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "rollup:", err)
+		cancel()
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: rollup backfill [-from yyyy-mm-dd] [-to yyyy-mm-dd]")
+	fmt.Fprintln(os.Stderr, "       rollup check [-from yyyy-mm-dd] [-to yyyy-mm-dd]")
+	os.Exit(2)
+}
+
+func backfill(ctx context.Context, args []string) error {
+	from, to := parseRange("backfill", args)
+
+	svc, closeStorage, err := newService(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeStorage()
+
+	result, err := svc.BackfillRollups(ctx, from, to)
+	if err != nil {
+		return err
+	}
+	return printJSON(result)
+}
+
+// check prints the rollups that don't match the report and reports whether there are none.
+func check(ctx context.Context, args []string) (bool, error) {
+	from, to := parseRange("check", args)
+
+	svc, closeStorage, err := newService(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer closeStorage()
+
+	mismatches, err := svc.CheckRollups(ctx, from, to)
+	if err != nil {
+		return false, err
+	}
+	if mismatches == nil {
+		mismatches = []models.RollupMismatch{}
+	}
+	return len(mismatches) == 0, printJSON(mismatches)
+}
+
+// parseRange parses the -from and -to flags of the command, a missing bound is nil.
+func parseRange(name string, args []string) (from, to *time.Time) {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fromFlag := fs.String("from", "", "first day in the format yyyy-mm-dd, the beginning of the history if empty")
+	toFlag := fs.String("to", "", "last day in the format yyyy-mm-dd, the end of the history if empty")
+	fs.Parse(args)
+	if fs.NArg() != 0 {
+		usage()
+	}
+	parse := func(value string) *time.Time {
+		if value == "" {
+			return nil
+		}
+		day, err := time.Parse(models.DateLayout, value)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "rollup: invalid date '%s'\n", value)
+			usage()
+		}
+		return &day
+	}
+	return parse(*fromFlag), parse(*toFlag)
+}
+
+func printJSON(value any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(value)
+}
+
+// newService connects to the database and returns the service working with it.
+func newService(ctx context.Context) (*usecases.SegmentSvc, func(), error) {
+	cfg := config.Get()
+	logger.New(logger.LoggerOptions{IsProd: cfg.IsProd})
+
+	optsConnect := db.ConnectOptions{
+		InitialBackoff: cfg.DBInitialBackoff,
+		MaxBackoff:     cfg.DBMaxBackoff,
+		MaxWait:        cfg.DBMaxWait,
+	}
+	storage, err := db.New(ctx, cfg.DB_URL, optsConnect, cfg.ReportLocation())
+	if err != nil {
+		return nil, nil, fmt.Errorf("storage creation failed: %w", err)
+	}
+	return usecases.New(storage), func() { storage.Close(context.Background()) }, nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"segmentation-service/internal/domain/models"
+	"strings"
+)
+
+// client calls the http API of the service.
+type client struct {
+	baseURL string
+	apiKey  string
+	actor   string
+	reason  string
+	http    *http.Client
+}
+
+// apiError is a response of the service with an error status.
+type apiError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s (%d)", e.Message, e.StatusCode)
+}
+
+// newClient returns a client of the service, the reason is sent with every request like the actor.
+func newClient(s settings, reason string, httpClient *http.Client) *client {
+	return &client{
+		baseURL: strings.TrimSuffix(s.URL, "/") + "/api/v1",
+		apiKey:  s.APIKey,
+		actor:   s.Actor,
+		reason:  reason,
+		http:    httpClient,
+	}
+}
+
+// call sends the request with the JSON body, if it isn't nil, and decodes the JSON response into out.
+func (c *client) call(ctx context.Context, method, path string, body, out any) error {
+	resp, err := c.send(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// download sends a GET request and copies the response body to w.
+func (c *client) download(ctx context.Context, path string, w io.Writer) error {
+	resp, err := c.send(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// send executes the request and converts the error statuses into apiError.
+func (c *client) send(ctx context.Context, method, path string, body any) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+	// the header values are percent-encoded, so that they may contain any text
+	if c.actor != "" {
+		req.Header.Set("X-Actor", url.PathEscape(c.actor))
+	}
+	if c.reason != "" {
+		req.Header.Set("X-Reason", url.PathEscape(c.reason))
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer resp.Body.Close()
+		var errResp models.ErrorResponse
+		if err = json.NewDecoder(resp.Body).Decode(&errResp); err != nil || errResp.ErrorMsg == "" {
+			errResp.ErrorMsg = http.StatusText(resp.StatusCode)
+		}
+		return nil, &apiError{StatusCode: resp.StatusCode, Message: errResp.ErrorMsg}
+	}
+	return resp, nil
+}
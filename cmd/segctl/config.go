@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const defaultURL = "http://localhost:3000"
+
+// settings are the connection parameters of the client. The actor is sent with the changes, so that the report
+// tells who made them; the service ignores it if the API key identifies the caller.
+type settings struct {
+	URL    string `json:"url"`
+	APIKey string `json:"api_key"`
+	Actor  string `json:"actor"`
+}
+
+// resolveSettings merges the connection parameters with the precedence: flags, environment, config file, defaults.
+// The config file is read from the -config flag, SEGCTL_CONFIG or the user config directory; only an explicitly
+// given file must exist.
+func resolveSettings(flags settings, configPath string) (settings, error) {
+	explicit := configPath != ""
+	if !explicit {
+		configPath = os.Getenv("SEGCTL_CONFIG")
+		explicit = configPath != ""
+	}
+	if !explicit {
+		if dir, err := os.UserConfigDir(); err == nil {
+			configPath = filepath.Join(dir, "segctl", "config.json")
+		}
+	}
+
+	var file settings
+	if configPath != "" {
+		data, err := os.ReadFile(configPath)
+		switch {
+		case err == nil:
+			if err = json.Unmarshal(data, &file); err != nil {
+				return settings{}, fmt.Errorf("invalid config file %s: %w", configPath, err)
+			}
+		case explicit || !errors.Is(err, fs.ErrNotExist):
+			return settings{}, err
+		}
+	}
+
+	return settings{
+		URL:    firstNonEmpty(flags.URL, os.Getenv("SEGCTL_URL"), file.URL, defaultURL),
+		APIKey: firstNonEmpty(flags.APIKey, os.Getenv("SEGCTL_API_KEY"), file.APIKey),
+		Actor:  firstNonEmpty(flags.Actor, os.Getenv("SEGCTL_ACTOR"), file.Actor),
+	}, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
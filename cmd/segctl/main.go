@@ -0,0 +1,652 @@
+// The segctl command is an admin client of the http API. Usage:
+//
+//	segctl [flags] segments list
+//	segctl [flags] segments create SLUG
+//	segctl [flags] segments delete SLUG
+//	segctl [flags] segments set-rule SLUG RULE
+//	segctl [flags] segments set-expression SLUG EXPRESSION
+//	segctl [flags] segments set-rollout [-salt SALT] SLUG PERCENT|off
+//	segctl [flags] segments set-window [-from TIME] [-until TIME] SLUG
+//	segctl [flags] segments set-cap SLUG MAX|off
+//	segctl [flags] segments rename [-alias-days N] SLUG NEW_SLUG
+//	segctl [flags] segments members [-limit N] [-after USER_ID] SLUG
+//	segctl [flags] segments history SLUG
+//	segctl [flags] segments stats [-from DATE] [-to DATE] SLUG
+//	segctl [flags] segments sizes
+//	segctl [flags] experiments list
+//	segctl [flags] experiments create [-salt SALT] [-conflict reject|move] NAME SLUG=WEIGHT...
+//	segctl [flags] experiments delete NAME
+//	segctl [flags] experiments assign NAME USER_ID
+//	segctl [flags] users add [-dry-run] [-non-strict] USER_ID SLUG...
+//	segctl [flags] users remove [-dry-run] [-non-strict] USER_ID SLUG...
+//	segctl [flags] users segments USER_ID
+//	segctl [flags] report [-user USER_ID | -segments | -aggregate] [-actor ACTOR] [-source SOURCE] [-reason TEXT] [-out FILE] PERIOD
+//
+// The base URL, the API key and the actor are taken from the -url, -api-key and -actor flags, the SEGCTL_URL,
+// SEGCTL_API_KEY and SEGCTL_ACTOR environment variables or the config file, in this order. The exit code tells
+// scripts what happened: 0 - success, 1 - server or connection error, 2 - invalid command line, 3 - the request
+// was rejected by the service, 4 - the segment was not found.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"segmentation-service/internal/domain/models"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	exitOK       = 0
+	exitError    = 1
+	exitUsage    = 2
+	exitRejected = 3
+	exitNotFound = 4
+)
+
+const usage = `usage: segctl [flags] COMMAND
+
+commands:
+  segments list                          list all segments with their states and fill levels
+  segments create SLUG                   create a segment
+  segments delete SLUG                   delete a segment and all its members
+  segments set-rule SLUG RULE            make the segment dynamic, an empty rule makes it ordinary
+  segments set-expression SLUG EXPRESSION
+                                         make the segment composite, an empty expression makes it ordinary
+  segments set-rollout [-salt SALT] SLUG PERCENT|off
+                                         make the segment a percentage rollout, off makes it ordinary
+  segments set-window [-from TIME] [-until TIME] SLUG
+                                         set the activation window (RFC 3339), no bounds remove it
+  segments set-cap SLUG MAX|off          limit the number of members, off removes the cap
+  segments rename [-alias-days N] SLUG NEW_SLUG
+                                         rename the segment, the old slug stays an alias for N days
+  segments members [-limit N] [-after USER_ID] SLUG
+                                         list a page of the members, the last one is the next -after
+  segments history SLUG                  show the changes of the segment with who made them and why
+  segments stats [-from DATE] [-to DATE] SLUG
+                                         show the members added and removed on every day (yyyy-mm-dd),
+                                         the last 30 days by default
+  segments sizes                         show the number of members of every segment
+  experiments list                       list all experiment groups
+  experiments create [-salt SALT] [-conflict reject|move] NAME SLUG=WEIGHT...
+                                         create a group of mutually exclusive segments
+  experiments delete NAME                delete the group, keeping its segments
+  experiments assign NAME USER_ID        put the user into a variant of the group
+  users add [-dry-run] [-non-strict] USER_ID SLUG...
+                                         add the user to the segments
+  users remove [-dry-run] [-non-strict] USER_ID SLUG...
+                                         remove the user from the segments, -dry-run only shows what would change,
+                                         -non-strict skips the unknown segments and shows the status of each one
+  users segments USER_ID                 show the segments of the user
+  report [-user USER_ID | -segments | -aggregate] [-actor ACTOR] [-source SOURCE] [-reason TEXT] [-out FILE] PERIOD
+                                         download the report for the month (yyyy-mm), optionally
+                                         only the changes by the actor, from the source or with the reason,
+                                         -segments downloads the changes of the segments themselves,
+                                         -aggregate the daily numbers of the changes of every segment
+
+flags:
+`
+
+// errUsage is returned for an invalid command line.
+var errUsage = errors.New("invalid command line")
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	cancel()
+	os.Exit(code)
+}
+
+// run executes the command and returns the exit code.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("segctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		fs.PrintDefaults()
+	}
+	var flags settings
+	fs.StringVar(&flags.URL, "url", "", "base URL of the service (default "+defaultURL+")")
+	fs.StringVar(&flags.APIKey, "api-key", "", "API key")
+	fs.StringVar(&flags.Actor, "actor", "", "who makes the changes, written to the report")
+	reason := fs.String("reason", "", "why the changes are made, written to the report")
+	configPath := fs.String("config", "", "config file (default $SEGCTL_CONFIG or segctl/config.json in the user config directory)")
+	format := fs.String("o", outputTable, "output format: table or json")
+	timeout := fs.Duration("timeout", 30*time.Second, "request timeout")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *format != outputTable && *format != outputJSON {
+		fmt.Fprintf(stderr, "segctl: invalid output format '%s'\n", *format)
+		return exitUsage
+	}
+
+	s, err := resolveSettings(flags, *configPath)
+	if err != nil {
+		fmt.Fprintln(stderr, "segctl:", err)
+		return exitUsage
+	}
+	cmd := command{
+		client: newClient(s, *reason, &http.Client{Timeout: *timeout}),
+		out:    printer{w: stdout, format: *format},
+	}
+
+	err = cmd.run(ctx, fs.Args())
+	if err != nil && err != errUsage {
+		fmt.Fprintln(stderr, "segctl:", err)
+	}
+	if errors.Is(err, errUsage) {
+		fs.Usage()
+	}
+	return exitCode(err)
+}
+
+// exitCode maps the error of the command to the exit code.
+func exitCode(err error) int {
+	var apiErr *apiError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, errUsage):
+		return exitUsage
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound:
+		return exitNotFound
+	case errors.As(err, &apiErr) && apiErr.StatusCode < http.StatusInternalServerError:
+		return exitRejected
+	default:
+		return exitError
+	}
+}
+
+type command struct {
+	client *client
+	out    printer
+}
+
+func (c command) run(ctx context.Context, args []string) error {
+	switch {
+	case len(args) > 1 && args[0] == "segments":
+		return c.segments(ctx, args[1], args[2:])
+	case len(args) > 1 && args[0] == "experiments":
+		return c.experiments(ctx, args[1], args[2:])
+	case len(args) > 1 && args[0] == "users":
+		return c.users(ctx, args[1], args[2:])
+	case len(args) > 0 && args[0] == "report":
+		return c.report(ctx, args[1:])
+	default:
+		return errUsage
+	}
+}
+
+func (c command) segments(ctx context.Context, action string, args []string) error {
+	switch {
+	case action == "list" && len(args) == 0:
+		var list models.SegmentsList
+		if err := c.client.call(ctx, http.MethodGet, "/listSegments", nil, &list); err != nil {
+			return err
+		}
+		if len(list.States) == 0 {
+			return c.out.table(list, []string{"SEGMENT"}, column(list.S))
+		}
+		rows := make([][]string, 0, len(list.States))
+		for _, state := range list.States {
+			rows = append(rows, stateRow(state))
+		}
+		return c.out.table(list, []string{"SEGMENT", "STATE", "ACTIVE_FROM", "ACTIVE_UNTIL", "FILL"}, rows)
+	case (action == "create" || action == "delete") && len(args) == 1:
+		method, path := http.MethodPost, "/createSegment"
+		if action == "delete" {
+			method, path = http.MethodDelete, "/deleteSegment"
+		}
+		var resp models.SuccessResponse
+		if err := c.client.call(ctx, method, path, models.Segment{Slug: args[0]}, &resp); err != nil {
+			return err
+		}
+		return c.out.message(resp.SuccessMsg)
+	case action == "set-rule" && len(args) == 2:
+		var result models.SyncResult
+		if err := c.client.call(ctx, http.MethodPost, "/setSegmentRule", models.Segment{Slug: args[0], Rule: args[1]}, &result); err != nil {
+			return err
+		}
+		return c.out.table(result, []string{"ADDED", "REMOVED"}, [][]string{{strconv.Itoa(result.Added), strconv.Itoa(result.Removed)}})
+	case action == "set-expression" && len(args) == 2:
+		var result models.SyncResult
+		if err := c.client.call(ctx, http.MethodPost, "/setSegmentExpression", models.Segment{Slug: args[0], Expression: args[1]}, &result); err != nil {
+			return err
+		}
+		return c.out.table(result, []string{"ADDED", "REMOVED"}, [][]string{{strconv.Itoa(result.Added), strconv.Itoa(result.Removed)}})
+	case action == "set-rollout":
+		return c.setRollout(ctx, args)
+	case action == "set-window":
+		return c.setWindow(ctx, args)
+	case action == "set-cap" && len(args) == 2:
+		segment := models.Segment{Slug: args[0]}
+		if args[1] != "off" {
+			limit, err := strconv.Atoi(args[1])
+			if err != nil {
+				return fmt.Errorf("%w: invalid cap '%s'", errUsage, args[1])
+			}
+			segment.MaxMembers = &limit
+		}
+		var result models.SegmentState
+		if err := c.client.call(ctx, http.MethodPost, "/setSegmentCap", segment, &result); err != nil {
+			return err
+		}
+		return c.out.table(result, []string{"SEGMENT", "STATE", "ACTIVE_FROM", "ACTIVE_UNTIL", "FILL"}, [][]string{stateRow(result)})
+	case action == "rename":
+		return c.rename(ctx, args)
+	case action == "members":
+		return c.members(ctx, args)
+	case action == "history" && len(args) == 1:
+		var history models.SegmentHistory
+		if err := c.client.call(ctx, http.MethodGet, "/segments/"+url.PathEscape(args[0])+"/history", nil, &history); err != nil {
+			return err
+		}
+		rows := make([][]string, 0, len(history.Events))
+		for _, e := range history.Events {
+			rows = append(rows, []string{e.Time.Format(time.RFC3339), e.Segment, e.Event, e.OldValue, e.NewValue, e.Actor, e.Source, e.Reason})
+		}
+		return c.out.table(history, []string{"TIME", "SEGMENT", "EVENT", "OLD", "NEW", "ACTOR", "SOURCE", "REASON"}, rows)
+	case action == "stats":
+		return c.stats(ctx, args)
+	case action == "sizes" && len(args) == 0:
+		var stats models.ServiceStats
+		if err := c.client.call(ctx, http.MethodGet, "/stats", nil, &stats); err != nil {
+			return err
+		}
+		rows := make([][]string, 0, len(stats.Sizes))
+		for _, size := range stats.Sizes {
+			limit := "-"
+			if size.MaxMembers != nil {
+				limit = strconv.Itoa(*size.MaxMembers)
+			}
+			rows = append(rows, []string{size.Slug, strconv.Itoa(size.Members), limit})
+		}
+		return c.out.table(stats, []string{"SEGMENT", "MEMBERS", "CAP"}, rows)
+	default:
+		return errUsage
+	}
+}
+
+func (c command) setRollout(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("set-rollout", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	salt := fs.String("salt", "", "salt of the rollout hash, the slug by default")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 2 {
+		return errUsage
+	}
+	segment := models.Segment{Slug: fs.Arg(0), Salt: *salt}
+	if fs.Arg(1) != "off" {
+		percent, err := strconv.ParseFloat(strings.TrimSuffix(fs.Arg(1), "%"), 64)
+		if err != nil {
+			return fmt.Errorf("%w: invalid percentage '%s'", errUsage, fs.Arg(1))
+		}
+		segment.Rollout = &percent
+	}
+
+	var result models.Segment
+	if err := c.client.call(ctx, http.MethodPost, "/setSegmentRollout", segment, &result); err != nil {
+		return err
+	}
+	rollout := "off"
+	if result.Rollout != nil {
+		rollout = strconv.FormatFloat(*result.Rollout, 'f', -1, 64) + "%"
+	}
+	return c.out.table(result, []string{"SEGMENT", "ROLLOUT", "SALT"}, [][]string{{result.Slug, rollout, result.Salt}})
+}
+
+func (c command) setWindow(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("set-window", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	from := fs.String("from", "", "start of the window in RFC 3339 format, open if empty")
+	until := fs.String("until", "", "end of the window in RFC 3339 format, open if empty")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 1 {
+		return errUsage
+	}
+	segment := models.Segment{Slug: fs.Arg(0)}
+	for _, bound := range []struct {
+		value string
+		dst   **time.Time
+	}{{*from, &segment.ActiveFrom}, {*until, &segment.ActiveUntil}} {
+		if bound.value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, bound.value)
+		if err != nil {
+			return fmt.Errorf("%w: invalid time '%s'", errUsage, bound.value)
+		}
+		*bound.dst = &t
+	}
+
+	var result models.SegmentState
+	if err := c.client.call(ctx, http.MethodPost, "/setSegmentWindow", segment, &result); err != nil {
+		return err
+	}
+	return c.out.table(result, []string{"SEGMENT", "STATE", "ACTIVE_FROM", "ACTIVE_UNTIL", "FILL"}, [][]string{stateRow(result)})
+}
+
+func (c command) rename(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("rename", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	days := fs.Int("alias-days", models.DefaultAliasDays, "number of days the old slug keeps working, 0 disables the alias")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 2 {
+		return errUsage
+	}
+
+	var result models.SegmentAlias
+	req := models.RenameRequest{Slug: fs.Arg(0), NewSlug: fs.Arg(1), AliasDays: days}
+	if err := c.client.call(ctx, http.MethodPost, "/renameSegment", req, &result); err != nil {
+		return err
+	}
+	return c.out.table(result, []string{"ALIAS", "SEGMENT", "EXPIRES_AT"},
+		[][]string{{result.Alias, result.Slug, result.ExpiresAt.Format(time.RFC3339)}})
+}
+
+// stateRow formats the state of the segment for a table, the open sides of the window and the fill level of
+// a segment without a cap are shown as "-".
+func stateRow(state models.SegmentState) []string {
+	bound := func(t *time.Time) string {
+		if t == nil {
+			return "-"
+		}
+		return t.Format(time.RFC3339)
+	}
+	fill := "-"
+	if state.MaxMembers != nil && state.Members != nil {
+		fill = models.FillValue(*state.Members, *state.MaxMembers)
+	}
+	return []string{state.Slug, state.State, bound(state.ActiveFrom), bound(state.ActiveUntil), fill}
+}
+
+func (c command) members(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("members", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	limit := fs.Int("limit", models.DefaultMembersLimit, "maximum number of members")
+	after := fs.String("after", "", "list the members with user IDs greater than this one")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 1 {
+		return errUsage
+	}
+	if *limit < 1 || *limit > models.MaxMembersLimit {
+		return fmt.Errorf("%w: %s", errUsage, models.ErrInvalidLimit)
+	}
+	query := url.Values{"limit": {strconv.Itoa(*limit)}}
+	if *after != "" {
+		userID, err := uuid.Parse(*after)
+		if err != nil {
+			return fmt.Errorf("%w: %s", errUsage, models.ErrInvalidCursor)
+		}
+		query.Set("after", userID.String())
+	}
+
+	var list models.MembersList
+	if err := c.client.call(ctx, http.MethodGet, "/segments/"+url.PathEscape(fs.Arg(0))+"/members?"+query.Encode(), nil, &list); err != nil {
+		return err
+	}
+	rows := make([][]string, 0, len(list.Members))
+	for _, userID := range list.Members {
+		rows = append(rows, []string{userID.String()})
+	}
+	return c.out.table(list, []string{"USER_ID"}, rows)
+}
+
+func (c command) stats(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	from := fs.String("from", "", "first day in the format yyyy-mm-dd, 30 days before the last one by default")
+	to := fs.String("to", "", "last day in the format yyyy-mm-dd, today by default")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 1 {
+		return errUsage
+	}
+	query := url.Values{}
+	for name, value := range map[string]string{"from": *from, "to": *to} {
+		if value == "" {
+			continue
+		}
+		if _, err := time.Parse(models.DateLayout, value); err != nil {
+			return fmt.Errorf("%w: invalid date '%s'", errUsage, value)
+		}
+		query.Set(name, value)
+	}
+
+	var stats models.SegmentStats
+	if err := c.client.call(ctx, http.MethodGet, "/segments/"+url.PathEscape(fs.Arg(0))+"/stats?"+query.Encode(), nil, &stats); err != nil {
+		return err
+	}
+	rows := make([][]string, 0, len(stats.Days))
+	for _, day := range stats.Days {
+		rows = append(rows, []string{day.Date, strconv.Itoa(day.Added), strconv.Itoa(day.Removed)})
+	}
+	return c.out.table(stats, []string{"DATE", "ADDED", "REMOVED"}, rows)
+}
+
+func (c command) experiments(ctx context.Context, action string, args []string) error {
+	switch {
+	case action == "list" && len(args) == 0:
+		var list models.ExperimentGroupsList
+		if err := c.client.call(ctx, http.MethodGet, "/listExperimentGroups", nil, &list); err != nil {
+			return err
+		}
+		rows := make([][]string, 0, len(list.Groups))
+		for _, g := range list.Groups {
+			variants := make([]string, 0, len(g.Variants))
+			for _, v := range g.Variants {
+				variants = append(variants, v.Slug+"="+strconv.Itoa(v.Weight))
+			}
+			rows = append(rows, []string{g.Name, strings.Join(variants, " "), g.Conflict})
+		}
+		return c.out.table(list, []string{"GROUP", "VARIANTS", "CONFLICT"}, rows)
+	case action == "create":
+		return c.createExperiment(ctx, args)
+	case action == "delete" && len(args) == 1:
+		var resp models.SuccessResponse
+		if err := c.client.call(ctx, http.MethodDelete, "/deleteExperimentGroup", models.ExperimentGroup{Name: args[0]}, &resp); err != nil {
+			return err
+		}
+		return c.out.message(resp.SuccessMsg)
+	case action == "assign" && len(args) == 2:
+		userID, err := uuid.Parse(args[1])
+		if err != nil {
+			return fmt.Errorf("%w: %s", errUsage, models.ErrInvalidUuidFormat)
+		}
+		var result models.Assignment
+		if err = c.client.call(ctx, http.MethodPost, "/assignExperiment/"+userID.String(), models.AssignRequest{Group: args[0]}, &result); err != nil {
+			return err
+		}
+		return c.out.table(result, []string{"SEGMENT", "ASSIGNED"}, [][]string{{result.Segment, strconv.FormatBool(result.Assigned)}})
+	default:
+		return errUsage
+	}
+}
+
+func (c command) createExperiment(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("create", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var group models.ExperimentGroup
+	fs.StringVar(&group.Salt, "salt", "", "salt of the assignment hash (default the name of the group)")
+	fs.StringVar(&group.Conflict, "conflict", "", "adding a user to a second variant fails (reject) or moves them (move)")
+	if err := fs.Parse(args); err != nil || fs.NArg() < 2 {
+		return errUsage
+	}
+	group.Name = fs.Arg(0)
+	for _, arg := range fs.Args()[1:] {
+		slug, weight, ok := strings.Cut(arg, "=")
+		w, err := strconv.Atoi(weight)
+		if !ok || err != nil {
+			return fmt.Errorf("%w: invalid variant '%s', expected SLUG=WEIGHT", errUsage, arg)
+		}
+		group.Variants = append(group.Variants, models.Variant{Slug: slug, Weight: w})
+	}
+
+	var saved models.ExperimentGroup
+	if err := c.client.call(ctx, http.MethodPost, "/createExperimentGroup", group, &saved); err != nil {
+		return err
+	}
+	return c.out.message(fmt.Sprintf("experiment group '%s' created", saved.Name))
+}
+
+func (c command) users(ctx context.Context, action string, args []string) error {
+	var dryRun, nonStrict bool
+	if action == "add" || action == "remove" {
+		fs := flag.NewFlagSet(action, flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		fs.BoolVar(&dryRun, "dry-run", false, "only show what would change")
+		fs.BoolVar(&nonStrict, "non-strict", false, "apply the existing segments and show the status of every segment")
+		if err := fs.Parse(args); err != nil {
+			return errUsage
+		}
+		args = fs.Args()
+	}
+	if len(args) == 0 {
+		return errUsage
+	}
+	userID, err := uuid.Parse(args[0])
+	if err != nil {
+		return fmt.Errorf("%w: %s", errUsage, models.ErrInvalidUuidFormat)
+	}
+
+	switch {
+	case action == "segments" && len(args) == 1:
+		var list models.SegmentsList
+		if err := c.client.call(ctx, http.MethodGet, "/getUserSegments/"+userID.String(), nil, &list); err != nil {
+			return err
+		}
+		return c.out.table(list, []string{"SEGMENT"}, column(list.S))
+	case (action == "add" || action == "remove") && len(args) > 1:
+		data := models.UpdateRequest{SegmentsToAdd: args[1:], SegmentsToRemove: []string{}}
+		if action == "remove" {
+			data = models.UpdateRequest{SegmentsToAdd: []string{}, SegmentsToRemove: args[1:]}
+		}
+		path, query := "/updateUserSegments/"+userID.String(), url.Values{}
+		if dryRun {
+			query.Set("dry_run", "true")
+		}
+		if nonStrict {
+			query.Set("strict", "false")
+		}
+		if len(query) > 0 {
+			path += "?" + query.Encode()
+		}
+		if dryRun {
+			var diff models.MembershipDiff
+			if err := c.client.call(ctx, http.MethodPost, path, data, &diff); err != nil {
+				return err
+			}
+			var rows [][]string
+			for _, change := range []struct {
+				name  string
+				slugs []string
+			}{{"added", diff.Added}, {"removed", diff.Removed}, {"no-op", diff.NoOp}, {"unknown", diff.Unknown}} {
+				for _, slug := range change.slugs {
+					rows = append(rows, []string{change.name, slug})
+				}
+			}
+			return c.out.table(diff, []string{"CHANGE", "SEGMENT"}, rows)
+		}
+		if nonStrict {
+			var diff models.MembershipDiff
+			if err := c.client.call(ctx, http.MethodPost, path, data, &diff); err != nil {
+				return err
+			}
+			rows := make([][]string, 0, len(diff.Results))
+			for _, r := range diff.Results {
+				rows = append(rows, []string{r.Segment, r.Action, r.Status})
+			}
+			return c.out.table(diff, []string{"SEGMENT", "ACTION", "STATUS"}, rows)
+		}
+		var resp models.SuccessResponse
+		if err := c.client.call(ctx, http.MethodPost, path, data, &resp); err != nil {
+			return err
+		}
+		return c.out.message(resp.SuccessMsg)
+	default:
+		return errUsage
+	}
+}
+
+func (c command) report(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("report", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	user := fs.String("user", "", "download the report of this user only")
+	actor := fs.String("actor", "", "only the changes made by this actor")
+	source := fs.String("source", "", "only the changes from this source: "+strings.Join(models.Sources, ", "))
+	reason := fs.String("reason", "", "only the changes whose reason contains this text")
+	segments := fs.Bool("segments", false, "download the changes of the segments instead of the memberships")
+	aggregate := fs.Bool("aggregate", false, "download the daily numbers of the added and removed members of every segment")
+	out := fs.String("out", "", "file to save the report to, '-' for the standard output (default report-PERIOD.csv)")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 1 {
+		return errUsage
+	}
+	period := fs.Arg(0)
+	if !models.PeriodRegexp.MatchString(period) {
+		return fmt.Errorf("%w: %s", errUsage, models.ErrInvalidPeriodFormat)
+	}
+
+	path, filename := "/getReport/"+period, "report-"+period+".csv"
+	if *segments {
+		if *user != "" || *actor != "" || *source != "" || *reason != "" {
+			return fmt.Errorf("%w: -segments can not be filtered", errUsage)
+		}
+		path, filename = "/getSegmentReport/"+period, "report-"+period+"-segments.csv"
+	}
+	if *aggregate {
+		if *segments || *user != "" || *actor != "" || *source != "" || *reason != "" {
+			return fmt.Errorf("%w: -aggregate can not be filtered", errUsage)
+		}
+		path, filename = "/getAggregateReport/"+period, "report-"+period+"-aggregate.csv"
+	}
+	if *user != "" {
+		userID, err := uuid.Parse(*user)
+		if err != nil {
+			return fmt.Errorf("%w: %s", errUsage, models.ErrInvalidUuidFormat)
+		}
+		path, filename = "/getUserReport/"+period+"/"+userID.String(), "report-"+period+"-"+userID.String()+".csv"
+	}
+	query := url.Values{}
+	for name, value := range map[string]string{"actor": *actor, "source": *source, "reason": *reason} {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	if *out == "-" {
+		return c.client.download(ctx, path, c.out.w)
+	}
+	if *out != "" {
+		filename = *out
+	}
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err = c.client.download(ctx, path, f); err != nil {
+		f.Close()
+		os.Remove(filename)
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return c.out.message(fmt.Sprintf("report for %s saved to %s", period, filename))
+}
+
+func column(values []string) [][]string {
+	rows := make([][]string, 0, len(values))
+	for _, v := range values {
+		rows = append(rows, []string{v})
+	}
+	return rows
+}
@@ -0,0 +1,509 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"segmentation-service/internal/domain/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+)
+
+const userID = "550e8400-e29b-41d4-a716-446655440000"
+
+// newTestServer imitates the http API, the requests are recorded as "METHOD PATH BODY".
+func newTestServer(t *testing.T, requests *[]string) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		*requests = append(*requests, r.Method+" "+r.URL.RequestURI()+" "+string(body))
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Header.Get("X-API-Key") != "secret":
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(models.ErrorResponse{ErrorMsg: models.ErrUnauthorized.Error()})
+		case r.URL.Path == "/api/v1/listSegments":
+			from := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+			maxMembers, members := 100, 42
+			json.NewEncoder(w).Encode(models.SegmentsList{S: []string{"TEST1", "TEST2"}, States: []models.SegmentState{
+				{Slug: "TEST1", State: models.StateActive, MaxMembers: &maxMembers, Members: &members},
+				{Slug: "TEST2", State: models.StateScheduled, ActiveFrom: &from},
+			}})
+		case r.URL.Path == "/api/v1/updateUserSegments/"+userID && r.URL.Query().Get("dry_run") == "true":
+			json.NewEncoder(w).Encode(models.MembershipDiff{
+				DryRun: true, Added: []string{"TEST1"}, Removed: []string{}, NoOp: []string{"TEST2"}, Unknown: []string{"TEST9"},
+			})
+		case r.URL.Path == "/api/v1/updateUserSegments/"+userID && r.URL.Query().Get("strict") == "false":
+			json.NewEncoder(w).Encode(models.MembershipDiff{Results: []models.SegmentResult{
+				{Segment: "TEST1", Action: models.ActRemove, Status: models.StatusApplied},
+				{Segment: "TEST9", Action: models.ActRemove, Status: models.StatusNotFound},
+			}})
+		case r.URL.Path == "/api/v1/getUserSegments/"+userID:
+			json.NewEncoder(w).Encode(models.SegmentsList{S: []string{"TEST1", "TEST2"}})
+		case r.URL.Path == "/api/v1/setSegmentRule", r.URL.Path == "/api/v1/setSegmentExpression":
+			json.NewEncoder(w).Encode(models.SyncResult{Added: 2, Removed: 1})
+		case r.URL.Path == "/api/v1/setSegmentRollout":
+			var segment models.Segment
+			json.Unmarshal(body, &segment)
+			segment.Salt = segment.Slug
+			json.NewEncoder(w).Encode(segment)
+		case r.URL.Path == "/api/v1/setSegmentWindow":
+			var segment models.Segment
+			json.Unmarshal(body, &segment)
+			json.NewEncoder(w).Encode(models.SegmentState{
+				Slug: segment.Slug, State: models.StateScheduled, ActiveFrom: segment.ActiveFrom, ActiveUntil: segment.ActiveUntil,
+			})
+		case r.URL.Path == "/api/v1/setSegmentCap":
+			var segment models.Segment
+			json.Unmarshal(body, &segment)
+			members := 42
+			state := models.SegmentState{Slug: segment.Slug, State: models.StateActive, MaxMembers: segment.MaxMembers}
+			if segment.MaxMembers != nil {
+				state.Members = &members
+			}
+			json.NewEncoder(w).Encode(state)
+		case r.URL.Path == "/api/v1/renameSegment":
+			var req models.RenameRequest
+			json.Unmarshal(body, &req)
+			json.NewEncoder(w).Encode(models.SegmentAlias{
+				Alias: req.Slug, Slug: req.NewSlug, ExpiresAt: time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC),
+			})
+		case r.URL.Path == "/api/v1/segments/TEST1/history":
+			json.NewEncoder(w).Encode(models.SegmentHistory{Slug: "TEST1", Events: []models.SegmentEvent{{
+				Segment: "TEST1", Event: models.EventRuleChanged, NewValue: "age > 18",
+				Time: time.Date(2023, 8, 30, 14, 38, 42, 0, time.UTC), Actor: "alice", Source: models.SourceAPI, Reason: "adults",
+			}}})
+		case r.URL.Path == "/api/v1/segments/TEST1/stats":
+			json.NewEncoder(w).Encode(models.SegmentStats{Slug: "TEST1", Members: 10, From: "2023-08-01", To: "2023-08-02", Days: []models.DailyStats{
+				{Date: "2023-08-01", Added: 12, Removed: 2},
+				{Date: "2023-08-02"},
+			}})
+		case r.URL.Path == "/api/v1/stats":
+			maxMembers := 100
+			json.NewEncoder(w).Encode(models.ServiceStats{Segments: 2, Memberships: 110, Sizes: []models.SegmentSize{
+				{Slug: "TEST1", Members: 10},
+				{Slug: "TEST2", Members: 100, MaxMembers: &maxMembers},
+			}})
+		case r.URL.Path == "/api/v1/getAggregateReport/2023-08":
+			w.Header().Set("Content-Type", "text/csv")
+			io.WriteString(w, "TEST1,2023-08-30,12,2\n")
+		case r.URL.Path == "/api/v1/getSegmentReport/2023-08":
+			w.Header().Set("Content-Type", "text/csv")
+			io.WriteString(w, "TEST1,created,,TEST1,2023-08-30 14:38:42,alice,api,\n")
+		case r.URL.Path == "/api/v1/segments/TEST1/members":
+			json.NewEncoder(w).Encode(models.MembersList{Members: []uuid.UUID{uuid.MustParse(userID)}, Next: userID})
+		case r.URL.Path == "/api/v1/createExperimentGroup":
+			json.NewEncoder(w).Encode(models.ExperimentGroup{Name: "EXP_X"})
+		case r.URL.Path == "/api/v1/listExperimentGroups":
+			json.NewEncoder(w).Encode(models.ExperimentGroupsList{Groups: []models.ExperimentGroup{{
+				Name: "EXP_X", Conflict: models.ConflictReject,
+				Variants: []models.Variant{{Slug: "EXP_X_CONTROL", Weight: 50}, {Slug: "EXP_X_VARIANT_A", Weight: 50}},
+			}}})
+		case r.URL.Path == "/api/v1/assignExperiment/"+userID:
+			json.NewEncoder(w).Encode(models.Assignment{Group: "EXP_X", Segment: "EXP_X_VARIANT_A", Assigned: true})
+		case r.URL.Path == "/api/v1/getReport/2023-08":
+			w.Header().Set("Content-Type", "text/csv")
+			io.WriteString(w, userID+",TEST1,add,2023-08-30 14:38:42\n")
+		case r.URL.Path == "/api/v1/updateUserSegments/"+userID && bytes.Contains(body, []byte(`"FULL"`)):
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(models.ErrorResponse{ErrorMsg: models.ErrSegmentFull.Error() + ": 'FULL' has 10 of 10 members"})
+		case r.URL.Path == "/api/v1/deleteSegment":
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(models.ErrorResponse{ErrorMsg: models.ErrSegmentNotFound.Error()})
+		case r.URL.Path == "/api/v1/getReport/2023-09":
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(models.ErrorResponse{ErrorMsg: "database error"})
+		default:
+			json.NewEncoder(w).Encode(models.SuccessResponse{SuccessMsg: "done"})
+		}
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRun(t *testing.T) {
+	var requests []string
+	srv := newTestServer(t, &requests)
+	t.Setenv("SEGCTL_URL", srv.URL)
+	t.Setenv("SEGCTL_API_KEY", "secret")
+	t.Setenv("SEGCTL_CONFIG", "")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	// prepare test data
+	testCases := []struct {
+		name        string
+		args        []string
+		expCode     int
+		expStdout   string
+		expRequests []string
+	}{
+		{
+			name:        "List segments - table",
+			args:        []string{"segments", "list"},
+			expCode:     exitOK,
+			expStdout:   "SEGMENT  STATE      ACTIVE_FROM           ACTIVE_UNTIL  FILL\nTEST1    active     -                     -             42/100\nTEST2    scheduled  2023-09-01T00:00:00Z  -             -\n",
+			expRequests: []string{"GET /api/v1/listSegments "},
+		},
+		{
+			name:        "User segments - json",
+			args:        []string{"-o", "json", "users", "segments", userID},
+			expCode:     exitOK,
+			expStdout:   "{\n  \"segments\": [\n    \"TEST1\",\n    \"TEST2\"\n  ]\n}\n",
+			expRequests: []string{"GET /api/v1/getUserSegments/" + userID + " "},
+		},
+		{
+			name:      "Create segment",
+			args:      []string{"segments", "create", "TEST1"},
+			expCode:   exitOK,
+			expStdout: "done\n",
+			expRequests: []string{
+				`POST /api/v1/createSegment {"slug":"TEST1"}`,
+			},
+		},
+		{
+			name:      "Set rule",
+			args:      []string{"segments", "set-rule", "TEST1", `city == "Moscow"`},
+			expCode:   exitOK,
+			expStdout: "ADDED  REMOVED\n2      1\n",
+			expRequests: []string{
+				`POST /api/v1/setSegmentRule {"slug":"TEST1","rule":"city == \"Moscow\""}`,
+			},
+		},
+		{
+			name:      "Set expression",
+			args:      []string{"segments", "set-expression", "TEST1", "TEST2 except TEST3"},
+			expCode:   exitOK,
+			expStdout: "ADDED  REMOVED\n2      1\n",
+			expRequests: []string{
+				`POST /api/v1/setSegmentExpression {"slug":"TEST1","expression":"TEST2 except TEST3"}`,
+			},
+		},
+		{
+			name:      "Set rollout",
+			args:      []string{"segments", "set-rollout", "TEST1", "12.5%"},
+			expCode:   exitOK,
+			expStdout: "SEGMENT  ROLLOUT  SALT\nTEST1    12.5%    TEST1\n",
+			expRequests: []string{
+				`POST /api/v1/setSegmentRollout {"slug":"TEST1","rollout":12.5}`,
+			},
+		},
+		{
+			name:      "Turn rollout off",
+			args:      []string{"segments", "set-rollout", "TEST1", "off"},
+			expCode:   exitOK,
+			expStdout: "SEGMENT  ROLLOUT  SALT\nTEST1    off      TEST1\n",
+			expRequests: []string{
+				`POST /api/v1/setSegmentRollout {"slug":"TEST1"}`,
+			},
+		},
+		{
+			name:    "Invalid percentage",
+			args:    []string{"segments", "set-rollout", "TEST1", "half"},
+			expCode: exitUsage,
+		},
+		{
+			name:      "Set window",
+			args:      []string{"segments", "set-window", "-from", "2023-09-01T00:00:00Z", "TEST1"},
+			expCode:   exitOK,
+			expStdout: "SEGMENT  STATE      ACTIVE_FROM           ACTIVE_UNTIL  FILL\nTEST1    scheduled  2023-09-01T00:00:00Z  -             -\n",
+			expRequests: []string{
+				`POST /api/v1/setSegmentWindow {"slug":"TEST1","active_from":"2023-09-01T00:00:00Z"}`,
+			},
+		},
+		{
+			name:    "Invalid window time",
+			args:    []string{"segments", "set-window", "-until", "tomorrow", "TEST1"},
+			expCode: exitUsage,
+		},
+		{
+			name:        "Set cap",
+			args:        []string{"segments", "set-cap", "TEST1", "100"},
+			expCode:     exitOK,
+			expStdout:   "SEGMENT  STATE   ACTIVE_FROM  ACTIVE_UNTIL  FILL\nTEST1    active  -            -             42/100\n",
+			expRequests: []string{`POST /api/v1/setSegmentCap {"slug":"TEST1","max_members":100}`},
+		},
+		{
+			name:        "Remove cap",
+			args:        []string{"segments", "set-cap", "TEST1", "off"},
+			expCode:     exitOK,
+			expStdout:   "SEGMENT  STATE   ACTIVE_FROM  ACTIVE_UNTIL  FILL\nTEST1    active  -            -             -\n",
+			expRequests: []string{`POST /api/v1/setSegmentCap {"slug":"TEST1"}`},
+		},
+		{
+			name:    "Invalid cap",
+			args:    []string{"segments", "set-cap", "TEST1", "many"},
+			expCode: exitUsage,
+		},
+		{
+			name:      "Rename segment",
+			args:      []string{"segments", "rename", "-alias-days", "7", "TEST1", "TEST9"},
+			expCode:   exitOK,
+			expStdout: "ALIAS  SEGMENT  EXPIRES_AT\nTEST1  TEST9    2023-10-01T00:00:00Z\n",
+			expRequests: []string{
+				`POST /api/v1/renameSegment {"slug":"TEST1","new_slug":"TEST9","alias_days":7}`,
+			},
+		},
+		{
+			name:    "Rename without new slug",
+			args:    []string{"segments", "rename", "TEST1"},
+			expCode: exitUsage,
+		},
+		{
+			name:        "Segment members",
+			args:        []string{"segments", "members", "-limit", "1", "-after", "00000000-0000-0000-0000-000000000001", "TEST1"},
+			expCode:     exitOK,
+			expStdout:   "USER_ID\n" + userID + "\n",
+			expRequests: []string{"GET /api/v1/segments/TEST1/members?after=00000000-0000-0000-0000-000000000001&limit=1 "},
+		},
+		{
+			name:        "Segment history",
+			args:        []string{"segments", "history", "TEST1"},
+			expCode:     exitOK,
+			expStdout:   "TIME                  SEGMENT  EVENT         OLD  NEW       ACTOR  SOURCE  REASON\n2023-08-30T14:38:42Z  TEST1    rule_changed       age > 18  alice  api     adults\n",
+			expRequests: []string{"GET /api/v1/segments/TEST1/history "},
+		},
+		{
+			name:        "Segment stats",
+			args:        []string{"segments", "stats", "-from", "2023-08-01", "-to", "2023-08-02", "TEST1"},
+			expCode:     exitOK,
+			expStdout:   "DATE        ADDED  REMOVED\n2023-08-01  12     2\n2023-08-02  0      0\n",
+			expRequests: []string{"GET /api/v1/segments/TEST1/stats?from=2023-08-01&to=2023-08-02 "},
+		},
+		{
+			name:    "Segment stats with invalid date",
+			args:    []string{"segments", "stats", "-from", "01.08.2023", "TEST1"},
+			expCode: exitUsage,
+		},
+		{
+			name:        "Segment sizes",
+			args:        []string{"segments", "sizes"},
+			expCode:     exitOK,
+			expStdout:   "SEGMENT  MEMBERS  CAP\nTEST1    10       -\nTEST2    100      100\n",
+			expRequests: []string{"GET /api/v1/stats "},
+		},
+		{
+			name:    "Invalid limit",
+			args:    []string{"segments", "members", "-limit", "0", "TEST1"},
+			expCode: exitUsage,
+		},
+		{
+			name:      "Create experiment group",
+			args:      []string{"experiments", "create", "-conflict", "move", "EXP_X", "EXP_X_CONTROL=50", "EXP_X_VARIANT_A=50"},
+			expCode:   exitOK,
+			expStdout: "experiment group 'EXP_X' created\n",
+			expRequests: []string{
+				`POST /api/v1/createExperimentGroup {"name":"EXP_X","conflict":"move","variants":[{"slug":"EXP_X_CONTROL","weight":50},{"slug":"EXP_X_VARIANT_A","weight":50}]}`,
+			},
+		},
+		{
+			name:        "List experiment groups",
+			args:        []string{"experiments", "list"},
+			expCode:     exitOK,
+			expStdout:   "GROUP  VARIANTS                             CONFLICT\nEXP_X  EXP_X_CONTROL=50 EXP_X_VARIANT_A=50  reject\n",
+			expRequests: []string{"GET /api/v1/listExperimentGroups "},
+		},
+		{
+			name:      "Assign experiment",
+			args:      []string{"experiments", "assign", "EXP_X", userID},
+			expCode:   exitOK,
+			expStdout: "SEGMENT          ASSIGNED\nEXP_X_VARIANT_A  true\n",
+			expRequests: []string{
+				"POST /api/v1/assignExperiment/" + userID + ` {"group":"EXP_X"}`,
+			},
+		},
+		{
+			name:    "Invalid variant",
+			args:    []string{"experiments", "create", "EXP_X", "EXP_X_CONTROL"},
+			expCode: exitUsage,
+		},
+		{
+			name:      "Add user",
+			args:      []string{"users", "add", userID, "TEST1", "TEST2"},
+			expCode:   exitOK,
+			expStdout: "done\n",
+			expRequests: []string{
+				"POST /api/v1/updateUserSegments/" + userID + ` {"segments-to-add":["TEST1","TEST2"],"segments-to-remove":[]}`,
+			},
+		},
+		{
+			name:    "Add user to a full segment",
+			args:    []string{"users", "add", userID, "FULL"},
+			expCode: exitRejected,
+			expRequests: []string{
+				"POST /api/v1/updateUserSegments/" + userID + ` {"segments-to-add":["FULL"],"segments-to-remove":[]}`,
+			},
+		},
+		{
+			name:      "Add user dry run",
+			args:      []string{"users", "add", "-dry-run", userID, "TEST1", "TEST2", "TEST9"},
+			expCode:   exitOK,
+			expStdout: "CHANGE   SEGMENT\nadded    TEST1\nno-op    TEST2\nunknown  TEST9\n",
+			expRequests: []string{
+				"POST /api/v1/updateUserSegments/" + userID + `?dry_run=true {"segments-to-add":["TEST1","TEST2","TEST9"],"segments-to-remove":[]}`,
+			},
+		},
+		{
+			name:      "Remove user non-strict",
+			args:      []string{"users", "remove", "-non-strict", userID, "TEST1", "TEST9"},
+			expCode:   exitOK,
+			expStdout: "SEGMENT  ACTION  STATUS\nTEST1    remove  applied\nTEST9    remove  not_found\n",
+			expRequests: []string{
+				"POST /api/v1/updateUserSegments/" + userID + `?strict=false {"segments-to-add":[],"segments-to-remove":["TEST1","TEST9"]}`,
+			},
+		},
+		{
+			name:        "Report to stdout",
+			args:        []string{"report", "-out", "-", "2023-08"},
+			expCode:     exitOK,
+			expStdout:   userID + ",TEST1,add,2023-08-30 14:38:42\n",
+			expRequests: []string{"GET /api/v1/getReport/2023-08 "},
+		},
+		{
+			name:        "Report filtered by attribution",
+			args:        []string{"report", "-out", "-", "-actor", "alice", "-source", "import", "-reason", "wave 2", "2023-08"},
+			expCode:     exitOK,
+			expStdout:   userID + ",TEST1,add,2023-08-30 14:38:42\n",
+			expRequests: []string{"GET /api/v1/getReport/2023-08?actor=alice&reason=wave+2&source=import "},
+		},
+		{
+			name:        "Segment report",
+			args:        []string{"report", "-out", "-", "-segments", "2023-08"},
+			expCode:     exitOK,
+			expStdout:   "TEST1,created,,TEST1,2023-08-30 14:38:42,alice,api,\n",
+			expRequests: []string{"GET /api/v1/getSegmentReport/2023-08 "},
+		},
+		{
+			name:    "Filtered segment report",
+			args:    []string{"report", "-segments", "-actor", "alice", "2023-08"},
+			expCode: exitUsage,
+		},
+		{
+			name:        "Aggregate report",
+			args:        []string{"report", "-out", "-", "-aggregate", "2023-08"},
+			expCode:     exitOK,
+			expStdout:   "TEST1,2023-08-30,12,2\n",
+			expRequests: []string{"GET /api/v1/getAggregateReport/2023-08 "},
+		},
+		{
+			name:    "Filtered aggregate report",
+			args:    []string{"report", "-aggregate", "-user", userID, "2023-08"},
+			expCode: exitUsage,
+		},
+		{
+			name:        "Segment not found",
+			args:        []string{"segments", "delete", "TEST3"},
+			expCode:     exitNotFound,
+			expRequests: []string{`DELETE /api/v1/deleteSegment {"slug":"TEST3"}`},
+		},
+		{
+			name:        "Rejected key",
+			args:        []string{"-api-key", "wrong", "segments", "list"},
+			expCode:     exitRejected,
+			expRequests: []string{"GET /api/v1/listSegments "},
+		},
+		{
+			name:        "Server error",
+			args:        []string{"report", "-out", "-", "2023-09"},
+			expCode:     exitError,
+			expRequests: []string{"GET /api/v1/getReport/2023-09 "},
+		},
+		{
+			name:    "Unknown command",
+			args:    []string{"segments", "rename", "TEST1"},
+			expCode: exitUsage,
+		},
+		{
+			name:    "Invalid user ID",
+			args:    []string{"users", "add", "123", "TEST1"},
+			expCode: exitUsage,
+		},
+		{
+			name:    "Invalid period",
+			args:    []string{"report", "2023-8"},
+			expCode: exitUsage,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			requests = nil
+			var stdout, stderr bytes.Buffer
+			code := run(context.Background(), tc.args, &stdout, &stderr)
+
+			assert.Equal(t, tc.expCode, code, stderr.String())
+			assert.Equal(t, tc.expStdout, stdout.String())
+			assert.DeepEqual(t, tc.expRequests, requests)
+		})
+	}
+}
+
+func TestReportToFile(t *testing.T) {
+	var requests []string
+	srv := newTestServer(t, &requests)
+	filename := filepath.Join(t.TempDir(), "report.csv")
+
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), []string{"-url", srv.URL, "-api-key", "secret", "report", "-out", filename, "2023-08"}, &stdout, &stderr)
+	assert.Equal(t, exitOK, code, stderr.String())
+
+	data, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, userID+",TEST1,add,2023-08-30 14:38:42\n", string(data))
+}
+
+func TestAttributionHeaders(t *testing.T) {
+	var actor, reason string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor, reason = r.Header.Get("X-Actor"), r.Header.Get("X-Reason")
+		json.NewEncoder(w).Encode(models.SuccessResponse{SuccessMsg: "done"})
+	}))
+	t.Cleanup(srv.Close)
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"-url", srv.URL, "-actor", "alice", "-reason", "акция 30%", "users", "add", userID, "TEST1"}
+	code := run(context.Background(), args, &stdout, &stderr)
+	assert.Equal(t, exitOK, code, stderr.String())
+	assert.Equal(t, "alice", actor)
+	assert.Equal(t, "%D0%B0%D0%BA%D1%86%D0%B8%D1%8F%2030%25", reason)
+}
+
+func TestResolveSettings(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(configPath, []byte(`{"url":"http://file:3000","api_key":"file-key"}`), 0o600))
+	t.Setenv("SEGCTL_URL", "")
+	t.Setenv("SEGCTL_API_KEY", "")
+	t.Setenv("SEGCTL_ACTOR", "")
+	t.Setenv("SEGCTL_CONFIG", configPath)
+
+	// the config file
+	s, err := resolveSettings(settings{}, "")
+	require.NoError(t, err)
+	assert.Equal(t, settings{URL: "http://file:3000", APIKey: "file-key"}, s)
+
+	// the environment overrides the file
+	t.Setenv("SEGCTL_API_KEY", "env-key")
+	s, err = resolveSettings(settings{}, "")
+	require.NoError(t, err)
+	assert.Equal(t, settings{URL: "http://file:3000", APIKey: "env-key"}, s)
+
+	// the flags override everything
+	s, err = resolveSettings(settings{URL: "http://flag:3000"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, settings{URL: "http://flag:3000", APIKey: "env-key"}, s)
+
+	// an explicitly given config file must exist
+	_, err = resolveSettings(settings{}, filepath.Join(t.TempDir(), "missing.json"))
+	require.Error(t, err)
+}
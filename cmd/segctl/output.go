@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// printer writes the results in the chosen format.
+type printer struct {
+	w      io.Writer
+	format string
+}
+
+// message prints the result of a command that only reports its success.
+func (p printer) message(msg string) error {
+	if p.format == outputJSON {
+		return p.json(map[string]string{"success": msg})
+	}
+	_, err := fmt.Fprintln(p.w, msg)
+	return err
+}
+
+// table prints the rows under the header, or the value as JSON.
+func (p printer) table(value any, header []string, rows [][]string) error {
+	if p.format == outputJSON {
+		return p.json(value)
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	for _, row := range append([][]string{header}, rows...) {
+		for i, cell := range row {
+			if i > 0 {
+				fmt.Fprint(tw, "\t")
+			}
+			fmt.Fprint(tw, cell)
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
+
+func (p printer) json(value any) error {
+	enc := json.NewEncoder(p.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(value)
+}
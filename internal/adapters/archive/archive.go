@@ -0,0 +1,208 @@
+// The archive package keeps the report rows of the archived months in gzip compressed csv files, one file per month,
+// in a local directory.
+package archive
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"segmentation-service/internal/domain/models"
+	"segmentation-service/internal/ports"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// header is the first line of the files, the columns of the rows.
+var header = []string{"segment_id", "segment", "user_id", "action", "created_at", "actor", "source", "reason"}
+
+type Archive struct {
+	dir string
+}
+
+var _ ports.ReportArchive = (*Archive)(nil)
+
+// New returns an archive keeping the files in dir, the directory is created if it doesn't exist.
+func New(dir string) (*Archive, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating archive directory failed: %w", err)
+	}
+	return &Archive{dir: dir}, nil
+}
+
+// FileName returns the name of the file of the month (in the format: yyyy-mm).
+func FileName(month string) string {
+	return "report-" + month + ".csv.gz"
+}
+
+// Create starts the file of the month in a temporary file of the directory, it is renamed to the file of the month
+// on commit.
+func (a *Archive) Create(month string) (ports.ReportArchiveWriter, error) {
+	if _, err := time.Parse(models.MonthLayout, month); err != nil {
+		return nil, fmt.Errorf("invalid month '%s'", month)
+	}
+	f, err := os.CreateTemp(a.dir, FileName(month)+".*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	w := &writer{
+		file: f,
+		path: filepath.Join(a.dir, FileName(month)),
+		hash: sha256.New(),
+	}
+	w.gz = gzip.NewWriter(io.MultiWriter(f, w.hash))
+	w.csv = csv.NewWriter(w.gz)
+	if err = w.csv.Write(header); err != nil {
+		w.Abort()
+		return nil, err
+	}
+	return w, nil
+}
+
+// Read calls visit with every row of the file of the archived month. The file must have the checksum and the number
+// of rows of the run, otherwise an error is returned after the rows that have been read.
+func (a *Archive) Read(ctx context.Context, run models.ReportArchiveRun, visit func(models.ArchivedReportRow) error) error {
+	f, err := os.Open(filepath.Join(a.dir, filepath.Base(run.File)))
+	if err != nil {
+		return fmt.Errorf("opening archive of the month '%s' failed: %w", run.Month, err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	gz, err := gzip.NewReader(io.TeeReader(f, h))
+	if err != nil {
+		return fmt.Errorf("reading archive of the month '%s' failed: %w", run.Month, err)
+	}
+	r := csv.NewReader(gz)
+	r.FieldsPerRecord = len(header)
+	r.ReuseRecord = true
+	if _, err = r.Read(); err != nil {
+		return fmt.Errorf("reading archive of the month '%s' failed: %w", run.Month, err)
+	}
+
+	count := 0
+	for {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("reading archive of the month '%s' failed: %w", run.Month, err)
+		}
+		row, err := parseRow(record)
+		if err != nil {
+			return fmt.Errorf("reading archive of the month '%s' failed: line %d: %w", run.Month, count+2, err)
+		}
+		if err = visit(row); err != nil {
+			return err
+		}
+		count++
+	}
+
+	// the rest of the file after the compressed stream counts into the checksum too
+	if _, err = io.Copy(io.Discard, io.TeeReader(f, h)); err != nil {
+		return fmt.Errorf("reading archive of the month '%s' failed: %w", run.Month, err)
+	}
+	if count != run.Rows {
+		return fmt.Errorf("archive of the month '%s' has %d rows instead of %d", run.Month, count, run.Rows)
+	}
+	if checksum := hex.EncodeToString(h.Sum(nil)); checksum != run.Checksum {
+		return fmt.Errorf("archive of the month '%s': %w", run.Month, models.ErrArchiveChecksum)
+	}
+	return nil
+}
+
+// writer writes the rows to the temporary file, computing the checksum of the compressed data.
+type writer struct {
+	file *os.File
+	path string
+	hash hash.Hash
+	gz   *gzip.Writer
+	csv  *csv.Writer
+	done bool
+}
+
+func (w *writer) Write(row models.ArchivedReportRow) error {
+	return w.csv.Write([]string{
+		strconv.Itoa(int(row.SegmentID)), row.Segment, row.UserID.String(), row.Action,
+		row.Time.UTC().Format(time.RFC3339Nano), row.Actor, row.Source, row.Reason,
+	})
+}
+
+// Commit flushes the rows, syncs the temporary file and renames it to the file of the month.
+func (w *writer) Commit() (file, checksum string, err error) {
+	if w.done {
+		return "", "", errors.New("archive file is already closed")
+	}
+	defer func() {
+		if err != nil {
+			w.Abort()
+		}
+	}()
+	w.csv.Flush()
+	if err = w.csv.Error(); err != nil {
+		return "", "", err
+	}
+	if err = w.gz.Close(); err != nil {
+		return "", "", err
+	}
+	if err = w.file.Sync(); err != nil {
+		return "", "", err
+	}
+	if err = w.file.Close(); err != nil {
+		return "", "", err
+	}
+	if err = os.Rename(w.file.Name(), w.path); err != nil {
+		return "", "", err
+	}
+	w.done = true
+	return filepath.Base(w.path), hex.EncodeToString(w.hash.Sum(nil)), nil
+}
+
+// Abort removes the temporary file.
+func (w *writer) Abort() error {
+	if w.done {
+		return nil
+	}
+	w.done = true
+	w.file.Close()
+	return os.Remove(w.file.Name())
+}
+
+// parseRow reads a row written by writer.Write.
+func parseRow(record []string) (row models.ArchivedReportRow, err error) {
+	segmentID, err := strconv.ParseInt(record[0], 10, 32)
+	if err != nil {
+		return row, fmt.Errorf("invalid segment id '%s'", record[0])
+	}
+	userID, err := uuid.Parse(record[2])
+	if err != nil {
+		return row, fmt.Errorf("invalid user id '%s'", record[2])
+	}
+	created, err := time.Parse(time.RFC3339Nano, record[4])
+	if err != nil {
+		return row, fmt.Errorf("invalid time '%s'", record[4])
+	}
+	return models.ArchivedReportRow{
+		SegmentID: int32(segmentID),
+		Segment:   record[1],
+		UserID:    userID,
+		Action:    record[3],
+		Time:      created,
+		Actor:     record[5],
+		Source:    record[6],
+		Reason:    record[7],
+	}, nil
+}
@@ -0,0 +1,77 @@
+package archive
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"segmentation-service/internal/domain/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+)
+
+func TestArchiveRoundTrip(t *testing.T) {
+	a, err := New(filepath.Join(t.TempDir(), "archive"))
+	require.NoError(t, err)
+	rows := []models.ArchivedReportRow{
+		{SegmentID: 1, Segment: "BETA", UserID: uuid.New(), Action: models.ActAdd,
+			Time: time.Date(2023, 8, 1, 10, 0, 0, 123456000, time.UTC), Actor: "ci", Source: models.SourceAPI, Reason: "rollout, \"phase\" 1"},
+		{SegmentID: 2, Segment: "", UserID: uuid.New(), Action: models.ActRemove,
+			Time: time.Date(2023, 8, 31, 23, 59, 59, 0, time.UTC), Source: models.SourceTTLReaper},
+	}
+
+	w, err := a.Create("2023-08")
+	require.NoError(t, err)
+	for _, row := range rows {
+		require.NoError(t, w.Write(row))
+	}
+	file, checksum, err := w.Commit()
+	require.NoError(t, err)
+	assert.Equal(t, "report-2023-08.csv.gz", file)
+	require.NoError(t, w.Abort()) // does nothing after the commit
+
+	// only the file of the month is left in the directory
+	entries, err := os.ReadDir(a.dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	run := models.ReportArchiveRun{Month: "2023-08", State: models.ArchiveArchived, File: file, Rows: len(rows), Checksum: checksum}
+	var read []models.ArchivedReportRow
+	require.NoError(t, a.Read(context.Background(), run, func(row models.ArchivedReportRow) error {
+		read = append(read, row)
+		return nil
+	}))
+	assert.DeepEqual(t, rows, read)
+
+	// the file doesn't match the run
+	run.Rows = 3
+	require.ErrorContains(t, a.Read(context.Background(), run, func(models.ArchivedReportRow) error { return nil }), "has 2 rows instead of 3")
+	run.Rows, run.Checksum = 2, "0000"
+	require.ErrorIs(t, a.Read(context.Background(), run, func(models.ArchivedReportRow) error { return nil }), models.ErrArchiveChecksum)
+}
+
+func TestArchiveAbort(t *testing.T) {
+	a, err := New(t.TempDir())
+	require.NoError(t, err)
+
+	// the aborted file doesn't replace the committed one
+	w, err := a.Create("2023-08")
+	require.NoError(t, err)
+	_, _, err = w.Commit()
+	require.NoError(t, err)
+	w, err = a.Create("2023-08")
+	require.NoError(t, err)
+	require.NoError(t, w.Write(models.ArchivedReportRow{SegmentID: 1, UserID: uuid.New(), Action: models.ActAdd, Time: time.Now()}))
+	require.NoError(t, w.Abort())
+
+	entries, err := os.ReadDir(a.dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "report-2023-08.csv.gz", entries[0].Name())
+
+	_, err = a.Create("2023-8")
+	require.Error(t, err)
+}
@@ -0,0 +1,64 @@
+// The auth package is responsible for the API key authentication shared by the http and grpc adapters.
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"strings"
+)
+
+// Keys maps the configured API keys to the names of their owners. Empty Keys disable the authentication.
+type Keys []key
+
+type key struct {
+	name  string
+	value string
+}
+
+type identityKey struct{}
+
+// ParseKeys parses the keys in the "name:key" format.
+func ParseKeys(entries []string) (Keys, error) {
+	var keys Keys
+	for i, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(entry, ":")
+		if !ok || name == "" || value == "" {
+			// the entry itself isn't printed, since it may be the key
+			return nil, fmt.Errorf("invalid API key entry #%d, expected 'name:key'", i+1)
+		}
+		keys = append(keys, key{name: name, value: value})
+	}
+	return keys, nil
+}
+
+// Enabled reports whether the requests must be authenticated.
+func (k Keys) Enabled() bool {
+	return len(k) > 0
+}
+
+// Identify returns the name of the owner of the given key. All keys are compared in constant time.
+func (k Keys) Identify(value string) (string, bool) {
+	var name string
+	for _, key := range k {
+		if subtle.ConstantTimeCompare([]byte(key.value), []byte(value)) == 1 {
+			name = key.name
+		}
+	}
+	return name, name != ""
+}
+
+// WithIdentity returns a copy of ctx carrying the name of the authenticated caller.
+func WithIdentity(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, identityKey{}, name)
+}
+
+// Identity returns the name of the authenticated caller, or an empty string if the request wasn't authenticated.
+func Identity(ctx context.Context) string {
+	name, _ := ctx.Value(identityKey{}).(string)
+	return name
+}
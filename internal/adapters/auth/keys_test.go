@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+)
+
+func TestParseKeys(t *testing.T) {
+	keys, err := ParseKeys([]string{"ops:secret1", " ci:secret2 ", ""})
+	require.NoError(t, err)
+	assert.Equal(t, true, keys.Enabled())
+
+	name, ok := keys.Identify("secret2")
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "ci", name)
+
+	_, ok = keys.Identify("secret")
+	assert.Equal(t, false, ok)
+	_, ok = keys.Identify("")
+	assert.Equal(t, false, ok)
+
+	_, err = ParseKeys([]string{"secret"})
+	require.Error(t, err)
+
+	keys, err = ParseKeys(nil)
+	require.NoError(t, err)
+	assert.Equal(t, false, keys.Enabled())
+}
+
+func TestIdentity(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, "", Identity(ctx))
+	assert.Equal(t, "ops", Identity(WithIdentity(ctx, "ops")))
+}
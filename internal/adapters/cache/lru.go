@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"container/list"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type entry struct {
+	userID    uuid.UUID
+	segments  []string
+	expiresAt time.Time
+}
+
+// lru is a fixed-size least recently used cache of user segments. It is not safe for concurrent use.
+type lru struct {
+	size  int
+	ttl   time.Duration
+	order *list.List // front is the most recently used entry
+	items map[uuid.UUID]*list.Element
+}
+
+func newLRU(size int, ttl time.Duration) *lru {
+	return &lru{
+		size:  size,
+		ttl:   ttl,
+		order: list.New(),
+		items: make(map[uuid.UUID]*list.Element, size),
+	}
+}
+
+// get returns a copy of the cached segments of the user if they are present and not expired.
+// The entries never share their slices with the callers, so a caller may modify the result.
+func (c *lru) get(userID uuid.UUID, now time.Time) ([]string, bool) {
+	el, ok := c.items[userID]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*entry)
+	if now.After(e.expiresAt) {
+		c.remove(userID)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return slices.Clone(e.segments), true
+}
+
+// put stores a copy of the segments of the user evicting the least recently used entry if the cache is full.
+func (c *lru) put(userID uuid.UUID, segments []string, now time.Time) {
+	segments = slices.Clone(segments)
+	if el, ok := c.items[userID]; ok {
+		e := el.Value.(*entry)
+		e.segments = segments
+		e.expiresAt = now.Add(c.ttl)
+		c.order.MoveToFront(el)
+		return
+	}
+	if c.order.Len() >= c.size {
+		c.remove(c.order.Back().Value.(*entry).userID)
+	}
+	c.items[userID] = c.order.PushFront(&entry{userID: userID, segments: segments, expiresAt: now.Add(c.ttl)})
+}
+
+func (c *lru) remove(userID uuid.UUID) {
+	if el, ok := c.items[userID]; ok {
+		c.order.Remove(el)
+		delete(c.items, userID)
+	}
+}
+
+// removeSegment drops the entries of all users that are members of the segment.
+func (c *lru) removeSegment(slug string) {
+	for userID, el := range c.items {
+		for _, s := range el.Value.(*entry).segments {
+			if s == slug {
+				c.order.Remove(el)
+				delete(c.items, userID)
+				break
+			}
+		}
+	}
+}
+
+func (c *lru) len() int {
+	return c.order.Len()
+}
@@ -0,0 +1,226 @@
+// The cache package provides an in-process read cache of user segments on top of the segment storage.
+package cache
+
+import (
+	"context"
+	"encoding/binary"
+	"segmentation-service/internal/domain/models"
+	"segmentation-service/internal/ports"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Storage struct {
+	ports.SegmentStorage
+
+	mu    sync.Mutex
+	lru   *lru
+	gens  [genStripes]uint64 // bumped on every write of a user of the stripe
+	epoch uint64             // bumped on every segment deletion
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+var _ ports.SegmentStorage = (*Storage)(nil)
+
+// genStripes is the number of write generation counters. Users are spread over a fixed number of counters
+// instead of having one each, so that the memory doesn't grow with the number of users ever written.
+const genStripes = 1024
+
+type StorageOptions struct {
+	Size int           // maximum number of cached users
+	TTL  time.Duration // how long an entry is served without going to the storage
+}
+
+type Stats = models.CacheStats
+
+// New wraps the storage with an LRU cache of GetUserSegments results.
+func New(storage ports.SegmentStorage, opts StorageOptions) *Storage {
+	return &Storage{
+		SegmentStorage: storage,
+		lru:            newLRU(opts.Size, opts.TTL),
+	}
+}
+
+// GetUserSegments returns the cached segments of the user or reads them from the storage.
+// A result read concurrently with a write of the same user is returned but not cached,
+// so the cache never serves data older than the last completed write.
+func (s *Storage) GetUserSegments(ctx context.Context, userID uuid.UUID, inRollout models.RolloutChecker) (models.SegmentsList, error) {
+	s.mu.Lock()
+	if segments, ok := s.lru.get(userID, time.Now()); ok {
+		s.mu.Unlock()
+		s.hits.Add(1)
+		return models.SegmentsList{S: segments}, nil
+	}
+	gen, epoch := s.gens[stripe(userID)], s.epoch
+	s.mu.Unlock()
+	s.misses.Add(1)
+
+	list, err := s.SegmentStorage.GetUserSegments(ctx, userID, inRollout)
+	if err != nil {
+		return list, err
+	}
+
+	s.mu.Lock()
+	if s.gens[stripe(userID)] == gen && s.epoch == epoch {
+		s.lru.put(userID, list.S, time.Now())
+	}
+	s.mu.Unlock()
+	return list, nil
+}
+
+// UpdateUserSegments invalidates the entry of the user. It is invalidated both before and after the write:
+// before, so that reads started during the write don't get cached, and after, to drop anything cached meanwhile.
+// A dry run writes nothing and keeps the entry.
+func (s *Storage) UpdateUserSegments(ctx context.Context, data models.UpdateRequest, userID uuid.UUID, inRollout models.RolloutChecker) (models.MembershipDiff, error) {
+	if data.DryRun {
+		return s.SegmentStorage.UpdateUserSegments(ctx, data, userID, inRollout)
+	}
+	s.invalidateUser(userID)
+	defer s.invalidateUser(userID)
+	return s.SegmentStorage.UpdateUserSegments(ctx, data, userID, inRollout)
+}
+
+// ApplyMemberships invalidates the entries of all users of the batch.
+func (s *Storage) ApplyMemberships(ctx context.Context, changes []models.MembershipChange, inRollout models.RolloutChecker) (int, int, error) {
+	invalidate := func() {
+		for _, c := range changes {
+			s.invalidateUser(c.UserID)
+		}
+	}
+	invalidate()
+	defer invalidate()
+	return s.SegmentStorage.ApplyMemberships(ctx, changes, inRollout)
+}
+
+// SaveSegment drops the whole cache if the new segment has a rule, an expression or a rollout, since it may add
+// any user to the segment.
+func (s *Storage) SaveSegment(ctx context.Context, segment models.Segment, evaluate models.RuleEvaluator) error {
+	if segment.Rule == "" && segment.Expression == "" && segment.Rollout == nil {
+		return s.SegmentStorage.SaveSegment(ctx, segment, evaluate)
+	}
+	s.invalidateAll()
+	defer s.invalidateAll()
+	return s.SegmentStorage.SaveSegment(ctx, segment, evaluate)
+}
+
+// SetSegmentRule drops the whole cache, since the new rule may add any user to the segment.
+func (s *Storage) SetSegmentRule(ctx context.Context, slug, rule string, evaluate models.RuleEvaluator) (models.SyncResult, error) {
+	s.invalidateAll()
+	defer s.invalidateAll()
+	return s.SegmentStorage.SetSegmentRule(ctx, slug, rule, evaluate)
+}
+
+// SetSegmentExpression drops the whole cache, since the new expression may add any user to the segment.
+func (s *Storage) SetSegmentExpression(ctx context.Context, slug, expression string) (models.SyncResult, error) {
+	s.invalidateAll()
+	defer s.invalidateAll()
+	return s.SegmentStorage.SetSegmentExpression(ctx, slug, expression)
+}
+
+// SetSegmentRollout drops the whole cache, since the new percentage may add or remove any user.
+func (s *Storage) SetSegmentRollout(ctx context.Context, slug string, rollout *float64, salt string) (models.Segment, error) {
+	s.invalidateAll()
+	defer s.invalidateAll()
+	return s.SegmentStorage.SetSegmentRollout(ctx, slug, rollout, salt)
+}
+
+// SetSegmentWindow drops the whole cache, since the segment may appear or disappear for all its members.
+func (s *Storage) SetSegmentWindow(ctx context.Context, slug string, from, until *time.Time) (models.SegmentState, error) {
+	s.invalidateAll()
+	defer s.invalidateAll()
+	return s.SegmentStorage.SetSegmentWindow(ctx, slug, from, until)
+}
+
+// RecordSegmentStates drops the whole cache when any segment was activated or deactivated, so that the cached
+// entries don't outlive a window by more than the scheduler interval.
+func (s *Storage) RecordSegmentStates(ctx context.Context) (int, error) {
+	count, err := s.SegmentStorage.RecordSegmentStates(ctx)
+	if count > 0 {
+		s.invalidateAll()
+	}
+	return count, err
+}
+
+// RenameSegment drops the whole cache, since the cached lists of all its members contain the old slug.
+func (s *Storage) RenameSegment(ctx context.Context, slug, newSlug string, aliasTTL time.Duration) (models.SegmentAlias, error) {
+	s.invalidateAll()
+	defer s.invalidateAll()
+	return s.SegmentStorage.RenameSegment(ctx, slug, newSlug, aliasTTL)
+}
+
+// AssignExperiment invalidates the entry of the user, who may be added to a variant of the group.
+func (s *Storage) AssignExperiment(ctx context.Context, name string, userID uuid.UUID, pick models.VariantPicker) (models.Assignment, error) {
+	s.invalidateUser(userID)
+	defer s.invalidateUser(userID)
+	return s.SegmentStorage.AssignExperiment(ctx, name, userID, pick)
+}
+
+// UpdateUserAttributes invalidates the entry of the user, whose dynamic segments may change.
+func (s *Storage) UpdateUserAttributes(ctx context.Context, userID uuid.UUID, attrs models.Attributes, evaluate models.RuleEvaluator) (models.AttributesResult, error) {
+	s.invalidateUser(userID)
+	defer s.invalidateUser(userID)
+	return s.SegmentStorage.UpdateUserAttributes(ctx, userID, attrs, evaluate)
+}
+
+// DeleteSegment invalidates the entries of all users that were members of the segment.
+func (s *Storage) DeleteSegment(ctx context.Context, slug string) error {
+	s.invalidateSegment(slug)
+	defer s.invalidateSegment(slug)
+	return s.SegmentStorage.DeleteSegment(ctx, slug)
+}
+
+// RestoreState drops the whole cache, since the restore may change the segments of any user.
+func (s *Storage) RestoreState(ctx context.Context, state models.State, mode string) (models.RestoreResult, error) {
+	s.invalidateAll()
+	defer s.invalidateAll()
+	return s.SegmentStorage.RestoreState(ctx, state, mode)
+}
+
+// Stats returns the hit and miss counters and the current number of cached users.
+func (s *Storage) Stats() Stats {
+	s.mu.Lock()
+	size := s.lru.len()
+	s.mu.Unlock()
+	return Stats{Hits: s.hits.Load(), Misses: s.misses.Load(), Size: size}
+}
+
+// GetServiceStats returns the stats of the storage with the stats of the cache.
+func (s *Storage) GetServiceStats(ctx context.Context) (models.ServiceStats, error) {
+	stats, err := s.SegmentStorage.GetServiceStats(ctx)
+	if err != nil {
+		return models.ServiceStats{}, err
+	}
+	cache := s.Stats()
+	stats.Cache = &cache
+	return stats, nil
+}
+
+func (s *Storage) invalidateUser(userID uuid.UUID) {
+	s.mu.Lock()
+	s.gens[stripe(userID)]++
+	s.lru.remove(userID)
+	s.mu.Unlock()
+}
+
+func (s *Storage) invalidateAll() {
+	s.mu.Lock()
+	s.epoch++
+	s.lru = newLRU(s.lru.size, s.lru.ttl)
+	s.mu.Unlock()
+}
+
+func (s *Storage) invalidateSegment(slug string) {
+	s.mu.Lock()
+	s.epoch++
+	s.lru.removeSegment(slug)
+	s.mu.Unlock()
+}
+
+func stripe(userID uuid.UUID) int {
+	return int(binary.BigEndian.Uint16(userID[14:]) % genStripes)
+}
@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"context"
+	"segmentation-service/internal/domain/bucket"
+	"segmentation-service/internal/domain/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BenchmarkStorage measures the cache on top of the in-memory storage, so that only the cost of the cache is counted:
+// the hits of the cached users, the misses of more users than fit, the hits of concurrent readers, which share
+// the lock of the cache, and the writes, which invalidate the entries.
+func BenchmarkStorage(b *testing.B) {
+	ctx := context.Background()
+	inner := newMemStorage("TEST1", "TEST2", "TEST3")
+	users := make([]uuid.UUID, 10000)
+	for i := range users {
+		users[i] = uuid.New()
+		if _, err := inner.UpdateUserSegments(ctx, models.UpdateRequest{SegmentsToAdd: []string{"TEST1", "TEST2"}}, users[i], bucket.InRollout); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.Run("Hit", func(b *testing.B) {
+		c := New(inner, StorageOptions{Size: len(users), TTL: time.Hour})
+		for i := 0; i < b.N; i++ {
+			if _, err := c.GetUserSegments(ctx, users[i%100], bucket.InRollout); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Miss", func(b *testing.B) {
+		c := New(inner, StorageOptions{Size: 100, TTL: time.Hour})
+		for i := 0; i < b.N; i++ {
+			if _, err := c.GetUserSegments(ctx, users[i%len(users)], bucket.InRollout); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("HitParallel", func(b *testing.B) {
+		c := New(inner, StorageOptions{Size: len(users), TTL: time.Hour})
+		b.RunParallel(func(pb *testing.PB) {
+			for i := 0; pb.Next(); i++ {
+				if _, err := c.GetUserSegments(ctx, users[i%100], bucket.InRollout); err != nil {
+					b.Error(err)
+					return
+				}
+			}
+		})
+	})
+	b.Run("UpdateUserSegments", func(b *testing.B) {
+		c := New(inner, StorageOptions{Size: len(users), TTL: time.Hour})
+		data := models.UpdateRequest{SegmentsToAdd: []string{"TEST3"}}
+		for i := 0; i < b.N; i++ {
+			if _, err := c.UpdateUserSegments(ctx, data, users[i%len(users)], bucket.InRollout); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
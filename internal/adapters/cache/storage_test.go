@@ -0,0 +1,531 @@
+package cache
+
+import (
+	"context"
+	"segmentation-service/internal/domain/bucket"
+	"segmentation-service/internal/domain/models"
+	"segmentation-service/internal/domain/sets"
+	"segmentation-service/internal/ports"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+)
+
+// memStorage is an in-memory segment storage that counts the reads of user segments.
+type memStorage struct {
+	ports.SegmentStorage
+
+	mu       sync.Mutex
+	members  map[string]map[uuid.UUID]bool
+	rules    map[string]string
+	rollouts map[string]float64
+	inactive map[string]bool
+	attrs    map[uuid.UUID]models.Attributes
+	reads    int
+}
+
+func newMemStorage(slugs ...string) *memStorage {
+	m := &memStorage{
+		members:  make(map[string]map[uuid.UUID]bool),
+		rules:    make(map[string]string),
+		rollouts: make(map[string]float64),
+		inactive: make(map[string]bool),
+		attrs:    make(map[uuid.UUID]models.Attributes),
+	}
+	for _, slug := range slugs {
+		m.members[slug] = make(map[uuid.UUID]bool)
+	}
+	return m
+}
+
+func (m *memStorage) GetServiceStats(ctx context.Context) (models.ServiceStats, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats := models.ServiceStats{Segments: len(m.members)}
+	for _, members := range m.members {
+		stats.Memberships += len(members)
+	}
+	return stats, nil
+}
+
+func (m *memStorage) UpdateUserSegments(ctx context.Context, data models.UpdateRequest, userID uuid.UUID, inRollout models.RolloutChecker) (models.MembershipDiff, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, slug := range append(data.SegmentsToAdd, data.SegmentsToRemove...) {
+		if _, ok := m.members[slug]; !ok {
+			return models.MembershipDiff{}, models.ErrSegmentNotFound
+		}
+	}
+	if data.DryRun {
+		return models.MembershipDiff{DryRun: true}, nil
+	}
+	for _, slug := range data.SegmentsToRemove {
+		delete(m.members[slug], userID)
+	}
+	for _, slug := range data.SegmentsToAdd {
+		m.members[slug][userID] = true
+	}
+	return models.MembershipDiff{}, nil
+}
+
+func (m *memStorage) ApplyMemberships(ctx context.Context, changes []models.MembershipChange, inRollout models.RolloutChecker) (int, int, error) {
+	for _, c := range changes {
+		data := models.UpdateRequest{SegmentsToAdd: []string{c.Segment}}
+		if c.Action == models.ActRemove {
+			data = models.UpdateRequest{SegmentsToRemove: []string{c.Segment}}
+		}
+		if _, err := m.UpdateUserSegments(ctx, data, c.UserID, inRollout); err != nil {
+			return 0, 0, err
+		}
+	}
+	return len(changes), 0, nil
+}
+
+func (m *memStorage) SetSegmentRule(ctx context.Context, slug, rule string, evaluate models.RuleEvaluator) (models.SyncResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rules[slug] = rule
+	for userID, attrs := range m.attrs {
+		m.members[slug][userID] = evaluate(rule, attrs)
+	}
+	return models.SyncResult{}, nil
+}
+
+func (m *memStorage) UpdateUserAttributes(ctx context.Context, userID uuid.UUID, attrs models.Attributes, evaluate models.RuleEvaluator) (models.AttributesResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.attrs[userID] = attrs
+	for slug, rule := range m.rules {
+		m.members[slug][userID] = evaluate(rule, attrs)
+	}
+	return models.AttributesResult{Attributes: attrs}, nil
+}
+
+func (m *memStorage) SetSegmentExpression(ctx context.Context, slug, expression string) (models.SyncResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	expr, err := sets.Parse(expression)
+	if err != nil {
+		return models.SyncResult{}, err
+	}
+	users := make(map[uuid.UUID]bool)
+	for _, s := range expr.Segments() {
+		for userID := range m.members[s] {
+			users[userID] = true
+		}
+	}
+	m.members[slug] = make(map[uuid.UUID]bool)
+	for userID := range users {
+		m.members[slug][userID] = expr.Contains(func(s string) bool { return m.members[s][userID] })
+	}
+	return models.SyncResult{}, nil
+}
+
+func (m *memStorage) AssignExperiment(ctx context.Context, name string, userID uuid.UUID, pick models.VariantPicker) (models.Assignment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	slug := pick(models.ExperimentGroup{Name: name}, userID)
+	if m.members[slug] == nil {
+		m.members[slug] = make(map[uuid.UUID]bool)
+	}
+	m.members[slug][userID] = true
+	return models.Assignment{Group: name, Segment: slug, Assigned: true}, nil
+}
+
+func (m *memStorage) DeleteSegment(ctx context.Context, slug string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.members, slug)
+	return nil
+}
+
+func (m *memStorage) RestoreState(ctx context.Context, state models.State, mode string) (models.RestoreResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.members = make(map[string]map[uuid.UUID]bool)
+	for _, s := range state.Segments {
+		m.members[s.Slug] = make(map[uuid.UUID]bool)
+	}
+	for _, ms := range state.Memberships {
+		m.members[ms.Segment][ms.UserID] = true
+	}
+	return models.RestoreResult{Mode: mode}, nil
+}
+
+func (m *memStorage) SetSegmentRollout(ctx context.Context, slug string, rollout *float64, salt string) (models.Segment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.members[slug] == nil {
+		m.members[slug] = make(map[uuid.UUID]bool)
+	}
+	if rollout == nil {
+		delete(m.rollouts, slug)
+	} else {
+		m.rollouts[slug] = *rollout
+	}
+	return models.Segment{Slug: slug, Rollout: rollout, Salt: slug}, nil
+}
+
+func (m *memStorage) SaveSegment(ctx context.Context, segment models.Segment, evaluate models.RuleEvaluator) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.members[segment.Slug] = make(map[uuid.UUID]bool)
+	if segment.Rollout != nil {
+		m.rollouts[segment.Slug] = *segment.Rollout
+	}
+	return nil
+}
+
+func (m *memStorage) SetSegmentWindow(ctx context.Context, slug string, from, until *time.Time) (models.SegmentState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	m.inactive[slug] = (from != nil && from.After(now)) || (until != nil && !until.After(now))
+	return models.SegmentState{Slug: slug, ActiveFrom: from, ActiveUntil: until}, nil
+}
+
+func (m *memStorage) RenameSegment(ctx context.Context, slug, newSlug string, aliasTTL time.Duration) (models.SegmentAlias, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.members[newSlug] = m.members[slug]
+	delete(m.members, slug)
+	return models.SegmentAlias{Alias: slug, Slug: newSlug, ExpiresAt: time.Now().Add(aliasTTL)}, nil
+}
+
+func (m *memStorage) GetUserSegments(ctx context.Context, userID uuid.UUID, inRollout models.RolloutChecker) (models.SegmentsList, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reads++
+	var list models.SegmentsList
+	for slug, users := range m.members {
+		rollout, ok := m.rollouts[slug]
+		if !m.inactive[slug] && (users[userID] || (ok && inRollout(slug, userID, rollout))) {
+			list.S = append(list.S, slug)
+		}
+	}
+	sort.Strings(list.S)
+	return list, nil
+}
+
+func TestCacheHitsAndMisses(t *testing.T) {
+	storage := newMemStorage("TEST1")
+	c := New(storage, StorageOptions{Size: 10, TTL: time.Minute})
+	userID := uuid.New()
+	ctx := context.Background()
+
+	_, err := c.UpdateUserSegments(ctx, models.UpdateRequest{SegmentsToAdd: []string{"TEST1"}}, userID, bucket.InRollout)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		list, err := c.GetUserSegments(ctx, userID, bucket.InRollout)
+		require.NoError(t, err)
+		assert.DeepEqual(t, []string{"TEST1"}, list.S)
+	}
+
+	assert.Equal(t, 1, storage.reads)
+	assert.Equal(t, Stats{Hits: 2, Misses: 1, Size: 1}, c.Stats())
+
+	stats, err := c.GetServiceStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.Memberships)
+	assert.DeepEqual(t, &Stats{Hits: 2, Misses: 1, Size: 1}, stats.Cache)
+}
+
+func TestCacheNoStaleReadsAfterWrites(t *testing.T) {
+	storage := newMemStorage("TEST1", "TEST2", "TEST3")
+	c := New(storage, StorageOptions{Size: 10, TTL: time.Hour})
+	userID := uuid.New()
+	ctx := context.Background()
+
+	steps := []struct {
+		name        string
+		write       func() error
+		expSegments []string
+	}{
+		{
+			name: "Add segments",
+			write: func() error {
+				_, err := c.UpdateUserSegments(ctx, models.UpdateRequest{SegmentsToAdd: []string{"TEST1", "TEST2"}}, userID, bucket.InRollout)
+				return err
+			},
+			expSegments: []string{"TEST1", "TEST2"},
+		},
+		{
+			name: "Remove segment",
+			write: func() error {
+				_, err := c.UpdateUserSegments(ctx, models.UpdateRequest{SegmentsToRemove: []string{"TEST1"}}, userID, bucket.InRollout)
+				return err
+			},
+			expSegments: []string{"TEST2"},
+		},
+		{
+			name: "Failed write",
+			write: func() error {
+				c.UpdateUserSegments(ctx, models.UpdateRequest{SegmentsToAdd: []string{"UNKNOWN"}}, userID, bucket.InRollout)
+				return nil
+			},
+			expSegments: []string{"TEST2"},
+		},
+		{
+			name: "Dry run",
+			write: func() error {
+				_, err := c.UpdateUserSegments(ctx, models.UpdateRequest{SegmentsToAdd: []string{"TEST1"}, DryRun: true}, userID, bucket.InRollout)
+				return err
+			},
+			expSegments: []string{"TEST2"},
+		},
+		{
+			name: "Add another segment",
+			write: func() error {
+				_, err := c.UpdateUserSegments(ctx, models.UpdateRequest{SegmentsToAdd: []string{"TEST3"}}, userID, bucket.InRollout)
+				return err
+			},
+			expSegments: []string{"TEST2", "TEST3"},
+		},
+		{
+			name: "Import",
+			write: func() error {
+				_, _, err := c.ApplyMemberships(ctx, []models.MembershipChange{{UserID: userID, Segment: "TEST1", Action: models.ActAdd}}, bucket.InRollout)
+				return err
+			},
+			expSegments: []string{"TEST1", "TEST2", "TEST3"},
+		},
+		{
+			name:        "Delete segment of the user",
+			write:       func() error { return c.DeleteSegment(ctx, "TEST2") },
+			expSegments: []string{"TEST1", "TEST3"},
+		},
+		{
+			name: "Restore",
+			write: func() error {
+				state := models.State{
+					Segments:    []models.ArchiveSegment{{Slug: "TEST1"}, {Slug: "TEST2"}},
+					Memberships: []models.ArchiveMembership{{UserID: userID, Segment: "TEST2"}},
+				}
+				_, err := c.RestoreState(ctx, state, models.RestoreReplace)
+				return err
+			},
+			expSegments: []string{"TEST2"},
+		},
+		{
+			name: "Set rule",
+			write: func() error {
+				_, err := c.UpdateUserAttributes(ctx, userID, models.Attributes{"premium": true}, nil)
+				if err != nil {
+					return err
+				}
+				_, err = c.SetSegmentRule(ctx, "TEST1", "premium", evaluatePremium)
+				return err
+			},
+			expSegments: []string{"TEST1", "TEST2"},
+		},
+		{
+			name: "Update attributes",
+			write: func() error {
+				_, err := c.UpdateUserAttributes(ctx, userID, models.Attributes{"premium": false}, evaluatePremium)
+				return err
+			},
+			expSegments: []string{"TEST2"},
+		},
+		{
+			name: "Set expression",
+			write: func() error {
+				_, err := c.SetSegmentExpression(ctx, "TEST1", "TEST2 except TEST3")
+				return err
+			},
+			expSegments: []string{"TEST1", "TEST2"},
+		},
+		{
+			name: "Assign experiment",
+			write: func() error {
+				_, err := c.AssignExperiment(ctx, "EXP", userID, func(models.ExperimentGroup, uuid.UUID) string { return "TEST3" })
+				return err
+			},
+			expSegments: []string{"TEST1", "TEST2", "TEST3"},
+		},
+		{
+			name: "Set rollout",
+			write: func() error {
+				all := 100.0
+				_, err := c.SetSegmentRollout(ctx, "TEST4", &all, "")
+				return err
+			},
+			expSegments: []string{"TEST1", "TEST2", "TEST3", "TEST4"},
+		},
+		{
+			name: "Lower rollout",
+			write: func() error {
+				none := 0.0
+				_, err := c.SetSegmentRollout(ctx, "TEST4", &none, "")
+				return err
+			},
+			expSegments: []string{"TEST1", "TEST2", "TEST3"},
+		},
+		{
+			name: "Set window in the future",
+			write: func() error {
+				from := time.Now().Add(time.Hour)
+				_, err := c.SetSegmentWindow(ctx, "TEST3", &from, nil)
+				return err
+			},
+			expSegments: []string{"TEST1", "TEST2"},
+		},
+		{
+			name: "Remove window",
+			write: func() error {
+				_, err := c.SetSegmentWindow(ctx, "TEST3", nil, nil)
+				return err
+			},
+			expSegments: []string{"TEST1", "TEST2", "TEST3"},
+		},
+		{
+			name: "Rename segment",
+			write: func() error {
+				_, err := c.RenameSegment(ctx, "TEST3", "TEST5", time.Hour)
+				return err
+			},
+			expSegments: []string{"TEST1", "TEST2", "TEST5"},
+		},
+		{
+			name: "Create rollout",
+			write: func() error {
+				all := 100.0
+				return c.SaveSegment(ctx, models.Segment{Slug: "TEST6", Rollout: &all}, nil)
+			},
+			expSegments: []string{"TEST1", "TEST2", "TEST5", "TEST6"},
+		},
+	}
+
+	for _, step := range steps {
+		step := step
+		t.Run(step.name, func(t *testing.T) {
+			// warm up the cache with the state before the write
+			_, err := c.GetUserSegments(ctx, userID, bucket.InRollout)
+			require.NoError(t, err)
+
+			require.NoError(t, step.write())
+
+			list, err := c.GetUserSegments(ctx, userID, bucket.InRollout)
+			require.NoError(t, err)
+			assert.DeepEqual(t, step.expSegments, list.S)
+		})
+	}
+}
+
+// evaluatePremium matches the users whose attribute named by the rule is true.
+func evaluatePremium(rule string, attrs models.Attributes) bool {
+	return attrs[rule] == true
+}
+
+func TestCacheOtherUsersStayCached(t *testing.T) {
+	storage := newMemStorage("TEST1", "TEST2")
+	c := New(storage, StorageOptions{Size: 10, TTL: time.Hour})
+	first, second := uuid.New(), uuid.New()
+	ctx := context.Background()
+
+	_, err := c.UpdateUserSegments(ctx, models.UpdateRequest{SegmentsToAdd: []string{"TEST1"}}, first, bucket.InRollout)
+	require.NoError(t, err)
+	_, err = c.UpdateUserSegments(ctx, models.UpdateRequest{SegmentsToAdd: []string{"TEST2"}}, second, bucket.InRollout)
+	require.NoError(t, err)
+	c.GetUserSegments(ctx, first, bucket.InRollout)
+	c.GetUserSegments(ctx, second, bucket.InRollout)
+
+	// deleting a segment drops only its members
+	require.NoError(t, c.DeleteSegment(ctx, "TEST1"))
+	c.GetUserSegments(ctx, second, bucket.InRollout)
+	assert.Equal(t, uint64(1), c.Stats().Hits)
+
+	list, err := c.GetUserSegments(ctx, first, bucket.InRollout)
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(list.S))
+}
+
+func TestCacheResultsAreCopies(t *testing.T) {
+	storage := newMemStorage("TEST1", "TEST2")
+	c := New(storage, StorageOptions{Size: 10, TTL: time.Hour})
+	userID := uuid.New()
+	ctx := context.Background()
+
+	_, err := c.UpdateUserSegments(ctx, models.UpdateRequest{SegmentsToAdd: []string{"TEST1", "TEST2"}}, userID, bucket.InRollout)
+	require.NoError(t, err)
+
+	// modifying the result of a miss doesn't change the cached entry
+	list, err := c.GetUserSegments(ctx, userID, bucket.InRollout)
+	require.NoError(t, err)
+	list.S[0] = "CHANGED"
+
+	// nor does modifying the result of a hit
+	list, err = c.GetUserSegments(ctx, userID, bucket.InRollout)
+	require.NoError(t, err)
+	assert.DeepEqual(t, []string{"TEST1", "TEST2"}, list.S)
+	list.S[1] = "CHANGED"
+
+	list, err = c.GetUserSegments(ctx, userID, bucket.InRollout)
+	require.NoError(t, err)
+	assert.DeepEqual(t, []string{"TEST1", "TEST2"}, list.S)
+	assert.Equal(t, uint64(2), c.Stats().Hits)
+}
+
+func TestCacheEvictionAndTTL(t *testing.T) {
+	storage := newMemStorage()
+	c := New(storage, StorageOptions{Size: 2, TTL: 50 * time.Millisecond})
+	users := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	ctx := context.Background()
+
+	for _, userID := range users {
+		c.GetUserSegments(ctx, userID, bucket.InRollout)
+	}
+	assert.Equal(t, 2, c.Stats().Size)
+
+	// the least recently used user was evicted
+	c.GetUserSegments(ctx, users[0], bucket.InRollout)
+	assert.Equal(t, uint64(4), c.Stats().Misses)
+
+	// expired entries are read again
+	time.Sleep(60 * time.Millisecond)
+	c.GetUserSegments(ctx, users[0], bucket.InRollout)
+	assert.Equal(t, uint64(5), c.Stats().Misses)
+}
+
+// slowStorage blocks reads until released, to interleave a read with a write.
+type slowStorage struct {
+	*memStorage
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *slowStorage) GetUserSegments(ctx context.Context, userID uuid.UUID, inRollout models.RolloutChecker) (models.SegmentsList, error) {
+	list, err := s.memStorage.GetUserSegments(ctx, userID, inRollout)
+	s.started <- struct{}{}
+	<-s.release
+	return list, err
+}
+
+func TestCacheConcurrentReadIsNotCached(t *testing.T) {
+	storage := &slowStorage{memStorage: newMemStorage("TEST1"), started: make(chan struct{}), release: make(chan struct{})}
+	c := New(storage, StorageOptions{Size: 10, TTL: time.Hour})
+	userID := uuid.New()
+	ctx := context.Background()
+
+	// the read gets the state before the write, but finishes after it
+	done := make(chan models.SegmentsList)
+	go func() {
+		list, _ := c.GetUserSegments(ctx, userID, bucket.InRollout)
+		done <- list
+	}()
+	<-storage.started
+	_, err := c.UpdateUserSegments(ctx, models.UpdateRequest{SegmentsToAdd: []string{"TEST1"}}, userID, bucket.InRollout)
+	require.NoError(t, err)
+	close(storage.release)
+	assert.Equal(t, 0, len((<-done).S))
+
+	// the outdated result must not be served from the cache
+	go func() { <-storage.started }()
+	list, err := c.GetUserSegments(ctx, userID, bucket.InRollout)
+	require.NoError(t, err)
+	assert.DeepEqual(t, []string{"TEST1"}, list.S)
+}
@@ -0,0 +1,512 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"segmentation-service/internal/domain/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// ExportState reads all segments with their aliases, memberships, attributes, experiment groups and, if requested,
+// the report log from one consistent snapshot. With the report, the archived months of the snapshot are returned too:
+// their rows are no longer in the report log, and a month archived after the snapshot is still in it.
+func (db *DBStorage) ExportState(ctx context.Context, withReport bool) (state models.State, archived models.ArchivedMonths, err error) {
+	// a read only repeatable read transaction sees every table as of the same moment
+	tx, err := db.Pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return state, archived, err
+	}
+	defer tx.Rollback(ctx)
+	q := withSpans(tx, "ExportState")
+
+	const querySegments = `
+	SELECT name, COALESCE(rule, ''), COALESCE(expression, ''), rollout, CASE WHEN rollout IS NOT NULL THEN salt ELSE '' END,
+		active_from, active_until, max_members
+	FROM segments ORDER BY id;
+	`
+	rows, err := q.Query(ctx, querySegments)
+	if err != nil {
+		return state, archived, err
+	}
+	index := make(map[string]int)
+	for rows.Next() {
+		var segment models.ArchiveSegment
+		if err = rows.Scan(&segment.Slug, &segment.Rule, &segment.Expression, &segment.Rollout, &segment.Salt,
+			&segment.ActiveFrom, &segment.ActiveUntil, &segment.MaxMembers); err != nil {
+			return state, archived, err
+		}
+		index[segment.Slug] = len(state.Segments)
+		state.Segments = append(state.Segments, segment)
+	}
+	if err = rows.Err(); err != nil {
+		return state, archived, err
+	}
+
+	const queryExclusions = `
+	SELECT rollout_exclusions.user_id, segments.name FROM rollout_exclusions
+	INNER JOIN segments ON segments.id = rollout_exclusions.segments_id
+	ORDER BY segments.id, rollout_exclusions.user_id;
+	`
+	rows, err = q.Query(ctx, queryExclusions)
+	if err != nil {
+		return state, archived, err
+	}
+	for rows.Next() {
+		var userID uuid.UUID
+		var slug string
+		if err = rows.Scan(&userID, &slug); err != nil {
+			return state, archived, err
+		}
+		segment := &state.Segments[index[slug]]
+		segment.Excluded = append(segment.Excluded, userID)
+	}
+	if err = rows.Err(); err != nil {
+		return state, archived, err
+	}
+
+	const queryAliases = `
+	SELECT segment_aliases.name, segment_aliases.expires_at, segments.name FROM segment_aliases
+	INNER JOIN segments ON segments.id = segment_aliases.segments_id
+	ORDER BY segments.id, segment_aliases.name;
+	`
+	rows, err = q.Query(ctx, queryAliases)
+	if err != nil {
+		return state, archived, err
+	}
+	for rows.Next() {
+		var alias models.ArchiveAlias
+		var slug string
+		if err = rows.Scan(&alias.Alias, &alias.ExpiresAt, &slug); err != nil {
+			return state, archived, err
+		}
+		segment := &state.Segments[index[slug]]
+		segment.Aliases = append(segment.Aliases, alias)
+	}
+	if err = rows.Err(); err != nil {
+		return state, archived, err
+	}
+
+	const queryMemberships = `
+	SELECT segments_users.user_id, segments.name FROM segments_users
+	INNER JOIN segments ON segments.id = segments_users.segments_id
+	ORDER BY segments.id, segments_users.user_id;
+	`
+	rows, err = q.Query(ctx, queryMemberships)
+	if err != nil {
+		return state, archived, err
+	}
+	for rows.Next() {
+		var membership models.ArchiveMembership
+		if err = rows.Scan(&membership.UserID, &membership.Segment); err != nil {
+			return state, archived, err
+		}
+		state.Memberships = append(state.Memberships, membership)
+	}
+	if err = rows.Err(); err != nil {
+		return state, archived, err
+	}
+
+	const queryAttributes = `
+	SELECT user_id, attributes FROM user_attributes ORDER BY user_id;
+	`
+	rows, err = q.Query(ctx, queryAttributes)
+	if err != nil {
+		return state, archived, err
+	}
+	for rows.Next() {
+		var attrs models.ArchiveAttributes
+		if err = rows.Scan(&attrs.UserID, &attrs.Attributes); err != nil {
+			return state, archived, err
+		}
+		state.Attributes = append(state.Attributes, attrs)
+	}
+	if err = rows.Err(); err != nil {
+		return state, archived, err
+	}
+
+	state.Experiments, err = listExperimentGroups(ctx, q, "")
+	if err != nil {
+		return state, archived, err
+	}
+
+	if !withReport {
+		return state, archived, nil
+	}
+	runs, err := reportArchiveRuns(ctx, q)
+	if err != nil {
+		return state, archived, err
+	}
+	for _, run := range runs {
+		if run.State == models.ArchiveArchived {
+			archived.Runs = append(archived.Runs, run)
+		}
+	}
+	if archived.Names, err = segmentNames(ctx, q); err != nil {
+		return state, archived, err
+	}
+
+	const queryReport = `
+	SELECT report.user_id, segments.name, report.action, report.created_at, report.actor, report.source, report.reason
+	FROM report
+	INNER JOIN segments ON segments.id = report.segments_id
+	ORDER BY report.id;
+	`
+	rows, err = q.Query(ctx, queryReport)
+	if err != nil {
+		return state, archived, err
+	}
+	for rows.Next() {
+		var row models.ArchiveReportRow
+		if err = rows.Scan(&row.UserID, &row.Segment, &row.Action, &row.Time, &row.Actor, &row.Source, &row.Reason); err != nil {
+			return state, archived, err
+		}
+		state.Report = append(state.Report, row)
+	}
+	return state, archived, rows.Err()
+}
+
+// RestoreState applies an exported state in one transaction.
+//
+// In the merge mode the missing segments, memberships and attributes of unknown users are added, the added memberships
+// are written to the report like ordinary changes, and the archived report log is ignored. The rules, expressions,
+// rollouts and activation windows of the existing segments are kept, the archived exclusions from rollouts and
+// the archived aliases that aren't taken are added. The missing experiment groups are created unless one of their
+// segments is already a variant of another group.
+//
+// In the replace mode the segments, their rules, expressions, rollouts, windows and aliases, memberships, user
+// attributes and experiment groups become exactly as in the archive: the segments that exist in both keep their IDs,
+// the others are deleted or created. If the archive contains the report log, it replaces the current one, otherwise
+// the current log is kept. No report rows are written for the restored memberships.
+//
+// In both modes the composite segments are recomputed at the end, and their changes are written to the report.
+// The created and deleted segments are recorded in the audit log, the audit log itself isn't restored.
+func (db *DBStorage) RestoreState(ctx context.Context, state models.State, mode string) (result models.RestoreResult, err error) {
+	result.Mode = mode
+	slugs := make([]string, 0, len(state.Segments))
+	rules := make([]string, 0, len(state.Segments))
+	expressions := make([]string, 0, len(state.Segments))
+	rollouts := make([]*float64, 0, len(state.Segments))
+	salts := make([]string, 0, len(state.Segments))
+	froms := make([]*time.Time, 0, len(state.Segments))
+	untils := make([]*time.Time, 0, len(state.Segments))
+	caps := make([]*int, 0, len(state.Segments))
+	var excludedUsers []uuid.UUID
+	var excludedSegments []string
+	var aliases, aliasSegments []string
+	var aliasExpirations []time.Time
+	for _, s := range state.Segments {
+		slugs = append(slugs, s.Slug)
+		rules = append(rules, s.Rule)
+		expressions = append(expressions, s.Expression)
+		rollouts = append(rollouts, s.Rollout)
+		salts = append(salts, s.Salt)
+		froms = append(froms, s.ActiveFrom)
+		untils = append(untils, s.ActiveUntil)
+		caps = append(caps, s.MaxMembers)
+		for _, userID := range s.Excluded {
+			excludedUsers = append(excludedUsers, userID)
+			excludedSegments = append(excludedSegments, s.Slug)
+		}
+		for _, alias := range s.Aliases {
+			aliases = append(aliases, alias.Alias)
+			aliasSegments = append(aliasSegments, s.Slug)
+			aliasExpirations = append(aliasExpirations, alias.ExpiresAt)
+		}
+	}
+	users := make([]uuid.UUID, 0, len(state.Memberships))
+	segments := make([]string, 0, len(state.Memberships))
+	for _, m := range state.Memberships {
+		users = append(users, m.UserID)
+		segments = append(segments, m.Segment)
+	}
+
+	// start a transaction
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+	q := withSpans(tx, "RestoreState")
+	by := models.AttributionFrom(ctx)
+
+	var tag pgconn.CommandTag
+	if mode == models.RestoreReplace {
+		const queryDeleteExperiments = `
+		DELETE FROM experiment_groups;
+		`
+		if _, err = q.Exec(ctx, queryDeleteExperiments); err != nil {
+			return result, err
+		}
+
+		const queryDeleteMemberships = `
+		WITH input AS (
+			SELECT DISTINCT segments.id AS segments_id, input.user_id FROM unnest($1::uuid[], $2::text[]) AS input(user_id, name)
+			INNER JOIN segments ON segments.name = input.name
+		)
+		DELETE FROM segments_users WHERE NOT EXISTS (
+			SELECT 1 FROM input WHERE input.segments_id = segments_users.segments_id AND input.user_id = segments_users.user_id
+		);
+		`
+		tag, err = q.Exec(ctx, queryDeleteMemberships, users, segments)
+		if err != nil {
+			return result, err
+		}
+		result.MembershipsRemoved = int(tag.RowsAffected())
+
+		const queryDeleteSegments = `
+		WITH deleted AS (
+			DELETE FROM segments WHERE name <> ALL($1::text[])
+			RETURNING id, name
+		), recorded AS (
+			INSERT INTO segment_events (segments_id, slug, event, old_value, actor, source, reason)
+			SELECT id, name, $2::text, name, $3::text, $4::text, $5::text FROM deleted
+		)
+		SELECT COUNT(*) FROM deleted;
+		`
+		err = q.QueryRow(ctx, queryDeleteSegments, slugs, models.EventDeleted, by.Actor, by.Source, by.Reason).Scan(&result.SegmentsDeleted)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	// the archived slugs win over the aliases of renamed segments; in the replace mode the aliases become exactly
+	// as in the archive
+	const queryFreeAliases = `
+	DELETE FROM segment_aliases WHERE name = ANY($1::text[]) OR $2;
+	`
+	if _, err = q.Exec(ctx, queryFreeAliases, slugs, mode == models.RestoreReplace); err != nil {
+		return result, err
+	}
+
+	const queryCreateSegments = `
+	WITH created AS (
+		INSERT INTO segments (name, rule, expression, rollout, salt, active_from, active_until, max_members)
+		SELECT DISTINCT ON (input.name) input.name, NULLIF(input.rule, ''), NULLIF(input.expression, ''), input.rollout, NULLIF(input.salt, ''),
+			input.active_from, input.active_until, input.max_members
+		FROM unnest($1::text[], $2::text[], $3::text[], $4::float8[], $5::text[], $6::timestamptz[], $7::timestamptz[], $12::int[])
+			AS input(name, rule, expression, rollout, salt, active_from, active_until, max_members)
+		WHERE NOT EXISTS (SELECT 1 FROM segments WHERE segments.name = input.name)
+		RETURNING id, name
+	), recorded AS (
+		INSERT INTO segment_events (segments_id, slug, event, new_value, actor, source, reason)
+		SELECT id, name, $8::text, name, $9::text, $10::text, $11::text FROM created
+	)
+	SELECT COUNT(*) FROM created;
+	`
+	err = q.QueryRow(ctx, queryCreateSegments, slugs, rules, expressions, rollouts, salts, froms, untils,
+		models.EventCreated, by.Actor, by.Source, by.Reason, caps).Scan(&result.SegmentsCreated)
+	if err != nil {
+		return result, err
+	}
+
+	if mode == models.RestoreReplace {
+		const queryUpdateRules = `
+		UPDATE segments SET rule = NULLIF(input.rule, ''), expression = NULLIF(input.expression, ''),
+			rollout = input.rollout, salt = COALESCE(NULLIF(input.salt, ''), segments.salt),
+			active_from = input.active_from, active_until = input.active_until, max_members = input.max_members
+		FROM unnest($1::text[], $2::text[], $3::text[], $4::float8[], $5::text[], $6::timestamptz[], $7::timestamptz[], $8::int[])
+			AS input(name, rule, expression, rollout, salt, active_from, active_until, max_members)
+		WHERE segments.name = input.name AND (segments.rule IS DISTINCT FROM NULLIF(input.rule, '')
+			OR segments.expression IS DISTINCT FROM NULLIF(input.expression, '')
+			OR segments.rollout IS DISTINCT FROM input.rollout OR segments.salt IS DISTINCT FROM COALESCE(NULLIF(input.salt, ''), segments.salt)
+			OR segments.active_from IS DISTINCT FROM input.active_from OR segments.active_until IS DISTINCT FROM input.active_until
+			OR segments.max_members IS DISTINCT FROM input.max_members);
+		`
+		if _, err = q.Exec(ctx, queryUpdateRules, slugs, rules, expressions, rollouts, salts, froms, untils, caps); err != nil {
+			return result, err
+		}
+
+		const queryDeleteExclusions = `
+		DELETE FROM rollout_exclusions;
+		`
+		if _, err = q.Exec(ctx, queryDeleteExclusions); err != nil {
+			return result, err
+		}
+
+		const queryDeleteAttributes = `
+		DELETE FROM user_attributes;
+		`
+		if _, err = q.Exec(ctx, queryDeleteAttributes); err != nil {
+			return result, err
+		}
+	}
+
+	attrUsers := make([]uuid.UUID, 0, len(state.Attributes))
+	attrValues := make([]string, 0, len(state.Attributes))
+	for _, a := range state.Attributes {
+		data, err := json.Marshal(a.Attributes)
+		if err != nil {
+			return result, err
+		}
+		attrUsers = append(attrUsers, a.UserID)
+		attrValues = append(attrValues, string(data))
+	}
+	const queryRestoreAttributes = `
+	INSERT INTO user_attributes (user_id, attributes)
+	SELECT input.user_id, jsonb_strip_nulls(input.attributes::jsonb) FROM unnest($1::uuid[], $2::text[]) AS input(user_id, attributes)
+	ON CONFLICT DO NOTHING;
+	`
+	tag, err = q.Exec(ctx, queryRestoreAttributes, attrUsers, attrValues)
+	if err != nil {
+		return result, err
+	}
+	result.AttributesRestored = int(tag.RowsAffected())
+
+	// the exclusions are only kept for the segments that are rollouts after the restore
+	const queryRestoreExclusions = `
+	INSERT INTO rollout_exclusions (segments_id, user_id)
+	SELECT segments.id, input.user_id FROM unnest($1::uuid[], $2::text[]) AS input(user_id, name)
+	INNER JOIN segments ON segments.name = input.name
+	WHERE segments.rollout IS NOT NULL
+	ON CONFLICT DO NOTHING;
+	`
+	if _, err = q.Exec(ctx, queryRestoreExclusions, excludedUsers, excludedSegments); err != nil {
+		return result, err
+	}
+
+	// the aliases keep resolving to their segments and stay reserved; in the merge mode the existing aliases win,
+	// and an alias isn't restored over the slug of an existing segment
+	const queryRestoreAliases = `
+	INSERT INTO segment_aliases (name, segments_id, expires_at)
+	SELECT input.alias, segments.id, input.expires_at FROM unnest($1::text[], $2::text[], $3::timestamptz[])
+		AS input(alias, name, expires_at)
+	INNER JOIN segments ON segments.name = input.name
+	WHERE NOT EXISTS (SELECT 1 FROM segments WHERE segments.name = input.alias)
+	ON CONFLICT DO NOTHING;
+	`
+	tag, err = q.Exec(ctx, queryRestoreAliases, aliases, aliasSegments, aliasExpirations)
+	if err != nil {
+		return result, err
+	}
+	result.AliasesRestored = int(tag.RowsAffected())
+
+	// the groups are created after the segments, so that their variants exist
+	const queryCreateExperiment = `
+	WITH created AS (
+		INSERT INTO experiment_groups (name, salt, conflict)
+		SELECT $1, $2, $3 WHERE NOT EXISTS (
+			SELECT 1 FROM experiment_variants
+			INNER JOIN segments ON segments.id = experiment_variants.segments_id
+			WHERE segments.name = ANY($4::text[])
+		)
+		ON CONFLICT (name) DO NOTHING
+		RETURNING id
+	), variants AS (
+		INSERT INTO experiment_variants (group_id, segments_id, weight, position)
+		SELECT created.id, segments.id, input.weight, input.position
+		FROM created, unnest($4::text[], $5::int[]) WITH ORDINALITY AS input(name, weight, position)
+		INNER JOIN segments ON segments.name = input.name
+	)
+	SELECT COUNT(*) FROM created;
+	`
+	for _, group := range state.Experiments {
+		variants := make([]string, 0, len(group.Variants))
+		weights := make([]int32, 0, len(group.Variants))
+		for _, v := range group.Variants {
+			variants = append(variants, v.Slug)
+			weights = append(weights, int32(v.Weight))
+		}
+		var created int
+		err = q.QueryRow(ctx, queryCreateExperiment, group.Name, group.Salt, group.Conflict, variants, weights).Scan(&created)
+		if err != nil {
+			return result, err
+		}
+		result.ExperimentsCreated += created
+	}
+
+	const queryAddMemberships = `
+	WITH input AS (
+		SELECT DISTINCT segments.id AS segments_id, input.user_id FROM unnest($1::uuid[], $2::text[]) AS input(user_id, name)
+		INNER JOIN segments ON segments.name = input.name
+	), inserted AS (
+		INSERT INTO segments_users (segments_id, user_id) SELECT segments_id, user_id FROM input
+		ON CONFLICT DO NOTHING
+		RETURNING segments_id, user_id
+	), reported AS (
+		INSERT INTO report (user_id, segments_id, action, actor, source, reason)
+		SELECT user_id, segments_id, $3, $5::text, $6::text, $7::text FROM inserted WHERE $4
+	)
+	SELECT COUNT(*) FROM inserted;
+	`
+	writeReport := mode == models.RestoreMerge
+	err = q.QueryRow(ctx, queryAddMemberships, users, segments, models.ActAdd, writeReport, by.Actor, by.Source, by.Reason).
+		Scan(&result.MembershipsAdded)
+	if err != nil {
+		return result, err
+	}
+
+	if mode == models.RestoreReplace && state.Report != nil {
+		const queryDeleteReport = `
+		DELETE FROM report;
+		`
+		if _, err = q.Exec(ctx, queryDeleteReport); err != nil {
+			return result, err
+		}
+		// the rollups count the inserted rows only, the restored rows below roll up again
+		const queryDeleteRollups = `
+		DELETE FROM report_rollups;
+		`
+		if _, err = q.Exec(ctx, queryDeleteRollups); err != nil {
+			return result, err
+		}
+		// the restored rows of the archived months are in the database again and are archived anew
+		const queryDeleteArchiveRuns = `
+		DELETE FROM report_archive_runs;
+		`
+		if _, err = q.Exec(ctx, queryDeleteArchiveRuns); err != nil {
+			return result, err
+		}
+
+		reportUsers := make([]uuid.UUID, 0, len(state.Report))
+		reportSegments := make([]string, 0, len(state.Report))
+		reportActions := make([]string, 0, len(state.Report))
+		reportTimes := make([]time.Time, 0, len(state.Report))
+		reportActors := make([]string, 0, len(state.Report))
+		reportSources := make([]string, 0, len(state.Report))
+		reportReasons := make([]string, 0, len(state.Report))
+		for _, r := range state.Report {
+			reportUsers = append(reportUsers, r.UserID)
+			reportSegments = append(reportSegments, r.Segment)
+			reportActions = append(reportActions, r.Action)
+			reportTimes = append(reportTimes, r.Time)
+			reportActors = append(reportActors, r.Actor)
+			reportSources = append(reportSources, r.Source)
+			reportReasons = append(reportReasons, r.Reason)
+		}
+		// the restored rows go to the partitions of their months rather than to the default one
+		const queryCreatePartitions = `
+		SELECT create_report_partitions(MIN(created_at)::date, MAX(created_at)::date) FROM unnest($1::timestamptz[]) AS input(created_at);
+		`
+		if _, err = q.Exec(ctx, queryCreatePartitions, reportTimes); err != nil {
+			return result, err
+		}
+		// the rows of the archives written before the attribution was recorded are attributed to the API
+		const queryRestoreReport = `
+		INSERT INTO report (user_id, segments_id, action, created_at, actor, source, reason)
+		SELECT input.user_id, segments.id, input.action, input.created_at, input.actor, COALESCE(NULLIF(input.source, ''), 'api'), input.reason
+		FROM unnest($1::uuid[], $2::text[], $3::text[], $4::timestamptz[], $5::text[], $6::text[], $7::text[])
+			WITH ORDINALITY AS input(user_id, name, action, created_at, actor, source, reason, n)
+		INNER JOIN segments ON segments.name = input.name
+		ORDER BY input.n;
+		`
+		tag, err = q.Exec(ctx, queryRestoreReport, reportUsers, reportSegments, reportActions, reportTimes,
+			reportActors, reportSources, reportReasons)
+		if err != nil {
+			return result, err
+		}
+		result.ReportRestored = int(tag.RowsAffected())
+	}
+
+	if _, err = syncComposites(ctx, q, nil, nil); err != nil {
+		return result, err
+	}
+	return result, tx.Commit(ctx)
+}
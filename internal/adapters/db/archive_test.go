@@ -0,0 +1,41 @@
+package db
+
+import (
+	"context"
+	"segmentation-service/internal/domain/models"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+)
+
+func TestRestoreStateAliases(t *testing.T) {
+	ctx := context.Background()
+
+	// the segment was renamed twice: the first alias has expired but stays reserved
+	storage := benchStorage(t, "test_archive", benchSchema(t)+`
+	INSERT INTO segments (name) VALUES ('TEST');
+	INSERT INTO segment_aliases (name, segments_id, expires_at) VALUES
+		('FIRST', 1, NOW() - interval '1 day'), ('SECOND', 1, NOW() + interval '1 day');
+	`)
+
+	state, _, err := storage.ExportState(ctx, false)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(state.Segments))
+	require.Equal(t, 2, len(state.Segments[0].Aliases))
+
+	for _, mode := range []string{models.RestoreReplace, models.RestoreMerge} {
+		_, err = storage.Pool.Exec(ctx, `DELETE FROM segments;`)
+		require.NoError(t, err)
+		result, err := storage.RestoreState(ctx, state, mode)
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.AliasesRestored)
+
+		aliases, err := storage.ResolveSegmentAliases(ctx, []string{"FIRST", "SECOND"})
+		require.NoError(t, err)
+		assert.Equal(t, 1, len(aliases))
+		assert.Equal(t, "TEST", aliases["SECOND"].Slug)
+		err = storage.SaveSegment(ctx, models.Segment{Slug: "FIRST"}, nil)
+		require.ErrorIs(t, err, models.ErrSlugReserved)
+	}
+}
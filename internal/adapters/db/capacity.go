@@ -0,0 +1,191 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"segmentation-service/internal/domain/models"
+	"segmentation-service/pkg/infra/logger"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// capacityLockSpace is the first key of the per-segment advisory locks of the capped segments. Every addition
+// to a capped segment takes its lock before counting the members, so that concurrent additions see each other's
+// members and the cap holds. The per-user locks are always taken before these ones.
+const capacityLockSpace int32 = 0x4341
+
+// SetSegmentCap saves the maximum number of members of the segment, nil removes the cap. The current members are
+// kept even if there are more of them than the new cap, only further additions are rejected. The state of
+// the segment with its fill level is returned; reaching the cap right away is recorded in the audit log.
+func (db *DBStorage) SetSegmentCap(ctx context.Context, slug string, maxMembers *int) (result models.SegmentState, err error) {
+	result = models.SegmentState{Slug: slug}
+
+	// start a transaction
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+	q := withSpans(tx, "SetSegmentCap")
+
+	if result, err = setSegmentCap(ctx, q, slug, maxMembers); err != nil {
+		return result, err
+	}
+	return result, tx.Commit(ctx)
+}
+
+// setSegmentCap saves the cap in the transaction of q, see SetSegmentCap.
+func setSegmentCap(ctx context.Context, q querier, slug string, maxMembers *int) (result models.SegmentState, err error) {
+	result = models.SegmentState{Slug: slug}
+
+	const querySegment = `
+	SELECT id, rollout IS NOT NULL, max_members FROM segments WHERE name = $1 FOR UPDATE;
+	`
+	var segmentID int32
+	var rollout bool
+	var previous *int
+	if err = q.QueryRow(ctx, querySegment, slug).Scan(&segmentID, &rollout, &previous); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = models.ErrSegmentNotFound
+		}
+		return result, err
+	}
+	if rollout && maxMembers != nil {
+		return result, fmt.Errorf("%w: its members aren't stored", models.ErrRolloutSegment)
+	}
+
+	// wait for the additions in flight, so that the count below includes them
+	if _, err = q.Exec(ctx, `SELECT pg_advisory_xact_lock($1, $2);`, capacityLockSpace, segmentID); err != nil {
+		return result, err
+	}
+	query := `
+	UPDATE segments SET max_members = $2 WHERE id = $1
+	RETURNING ` + stateColumn + `, active_from, active_until, max_members,
+		CASE WHEN max_members IS NOT NULL THEN (SELECT COUNT(*) FROM segments_users WHERE segments_id = $1) END;
+	`
+	err = q.QueryRow(ctx, query, segmentID, maxMembers).
+		Scan(&result.State, &result.ActiveFrom, &result.ActiveUntil, &result.MaxMembers, &result.Members)
+	if err != nil {
+		return result, err
+	}
+	if oldValue, newValue := models.CapValue(previous), models.CapValue(maxMembers); oldValue != newValue {
+		if err = recordEvent(ctx, q, slug, models.EventCapChanged, oldValue, newValue); err != nil {
+			return result, err
+		}
+	}
+	if result.Full() && (previous == nil || *result.Members < *previous) {
+		if err = recordEvent(ctx, q, slug, models.EventFull, "", models.FillValue(*result.Members, *maxMembers)); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// capacity is the cap of a segment and the number of its members when its lock was taken.
+type capacity struct {
+	id      int32
+	max     int
+	members int
+}
+
+// room returns how many more members fit into the segment.
+func (c capacity) room() int {
+	return max(c.max-c.members, 0)
+}
+
+// fullError returns models.ErrSegmentFull naming the segment and its fill level.
+func (c capacity) fullError(slug string) error {
+	return fmt.Errorf("%w: '%s' has %d of %d members", models.ErrSegmentFull, slug, c.members, c.max)
+}
+
+// lockCapacity takes the advisory locks of the capped segments among the slugs until the end of the transaction
+// and returns their caps and numbers of members, keyed by slug. The segments without a cap are neither locked nor
+// returned. The locks are taken in a fixed order, so that concurrent transactions don't deadlock.
+func lockCapacity(ctx context.Context, q querier, slugs []string) (map[string]capacity, error) {
+	caps := make(map[string]capacity)
+	if len(slugs) == 0 {
+		return caps, nil
+	}
+	const queryLock = `
+	SELECT pg_advisory_xact_lock($1, id) FROM (
+		SELECT id FROM segments WHERE name = ANY($2::text[]) AND max_members IS NOT NULL ORDER BY id
+	) AS capped;
+	`
+	if _, err := q.Exec(ctx, queryLock, capacityLockSpace, slugs); err != nil {
+		return nil, err
+	}
+
+	// counted in a statement of its own, so that the count includes the members added by the previous holders
+	const queryCount = `
+	SELECT id, name, max_members, (SELECT COUNT(*) FROM segments_users WHERE segments_id = segments.id)
+	FROM segments WHERE name = ANY($1::text[]) AND max_members IS NOT NULL;
+	`
+	rows, err := q.Query(ctx, queryCount, slugs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var slug string
+		var c capacity
+		if err = rows.Scan(&c.id, &slug, &c.max, &c.members); err != nil {
+			return nil, err
+		}
+		caps[slug] = c
+	}
+	return caps, rows.Err()
+}
+
+// recordFull records in the audit log the segments locked by lockCapacity that reached their caps since the locks
+// were taken. It must be called in the same transaction, after the additions.
+func recordFull(ctx context.Context, q querier, caps map[string]capacity) error {
+	var ids []int32
+	for _, c := range caps {
+		if c.room() > 0 {
+			ids = append(ids, c.id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	const query = `
+	SELECT name, max_members, members FROM (
+		SELECT name, max_members, (SELECT COUNT(*) FROM segments_users WHERE segments_id = segments.id) AS members
+		FROM segments WHERE id = ANY($1::int[])
+	) AS fill
+	WHERE members >= max_members
+	ORDER BY name;
+	`
+	rows, err := q.Query(ctx, query, ids)
+	if err != nil {
+		return err
+	}
+	type fill struct {
+		slug         string
+		max, members int
+	}
+	var full []fill
+	for rows.Next() {
+		var f fill
+		if err = rows.Scan(&f.slug, &f.max, &f.members); err != nil {
+			rows.Close()
+			return err
+		}
+		full = append(full, f)
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	for _, f := range full {
+		logger.Get().InfoContext(ctx, "segment is full", "segment", f.slug, "members", f.members, "max_members", f.max)
+		if err = recordEvent(ctx, q, f.slug, models.EventFull, "", models.FillValue(f.members, f.max)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,392 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"segmentation-service/internal/domain/models"
+	"segmentation-service/internal/domain/sets"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+// compositesLock is the key of the advisory lock that guards the expressions of composite segments. Changes of the
+// expressions and recomputations for all users take it exclusively, the updates of single users take it shared.
+const compositesLock int64 = 0x5345474d
+
+// usersLockSpace is the first key of the per-user advisory locks, so that concurrent updates of the same user
+// recompute its composite segments and check its experiment variants one after another, each seeing the changes
+// of the previous one.
+const usersLockSpace int32 = 0x5345
+
+// SetSegmentExpression saves the expression of the segment and, in the same transaction, makes its members exactly
+// the users of the set the expression describes. The changes are written to the report. An empty expression turns
+// the segment back into an ordinary one and keeps its current members.
+func (db *DBStorage) SetSegmentExpression(ctx context.Context, slug, expression string) (result models.SyncResult, err error) {
+	// start a transaction
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+	q := withSpans(tx, "SetSegmentExpression")
+
+	if result, err = setSegmentExpression(ctx, q, slug, expression); err != nil {
+		return result, err
+	}
+	return result, tx.Commit(ctx)
+}
+
+// setSegmentExpression saves the expression and computes the members in the transaction of q, see SetSegmentExpression.
+func setSegmentExpression(ctx context.Context, q querier, slug, expression string) (result models.SyncResult, err error) {
+	// take the lock before reading the other expressions, so that concurrent changes can't form a cycle
+	if _, err = q.Exec(ctx, `SELECT pg_advisory_xact_lock($1);`, compositesLock); err != nil {
+		return result, err
+	}
+
+	const querySegment = `
+	SELECT id, rule IS NOT NULL, rollout IS NOT NULL, COALESCE(expression, '') FROM segments WHERE name = $1 FOR UPDATE;
+	`
+	var segmentID int32
+	var dynamic, rollout bool
+	var previous string
+	if err = q.QueryRow(ctx, querySegment, slug).Scan(&segmentID, &dynamic, &rollout, &previous); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = models.ErrSegmentNotFound
+		}
+		return result, err
+	}
+	if dynamic && expression != "" {
+		return result, fmt.Errorf("%w: the segment has a rule", models.ErrInvalidExpression)
+	}
+	if rollout && expression != "" {
+		return result, fmt.Errorf("%w: the segment is a percentage rollout", models.ErrInvalidExpression)
+	}
+	if expression != "" {
+		if err = checkNoRollouts(ctx, q, expression); err != nil {
+			return result, err
+		}
+		var group string
+		if group, err = experimentOf(ctx, q, slug); err != nil {
+			return result, err
+		}
+		if group != "" {
+			return result, fmt.Errorf("%w '%s'", models.ErrSegmentInExperiment, group)
+		}
+	}
+
+	const queryUpdate = `
+	UPDATE segments SET expression = NULLIF($2, '') WHERE id = $1;
+	`
+	if _, err = q.Exec(ctx, queryUpdate, segmentID, expression); err != nil {
+		return result, err
+	}
+	if previous != expression {
+		if err = recordEvent(ctx, q, slug, models.EventExpressionChanged, previous, expression); err != nil {
+			return result, err
+		}
+	}
+	if expression == "" {
+		return result, nil
+	}
+
+	results, err := syncComposites(ctx, q, nil, nil)
+	if err != nil {
+		return result, err
+	}
+	return results[segmentID], nil
+}
+
+// GetSegmentMembers returns up to limit members of the segment with user IDs greater than after, ordered by user ID.
+// The members of a percentage rollout can't be listed.
+func (db *DBStorage) GetSegmentMembers(ctx context.Context, slug string, after uuid.UUID, limit int) ([]uuid.UUID, error) {
+	q := withSpans(db.Pool, "GetSegmentMembers")
+	const querySegment = `
+	SELECT id, rollout IS NOT NULL FROM segments WHERE name = $1;
+	`
+	var segmentID int32
+	var rollout bool
+	if err := q.QueryRow(ctx, querySegment, slug).Scan(&segmentID, &rollout); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrSegmentNotFound
+		}
+		return nil, err
+	}
+	if rollout {
+		return nil, fmt.Errorf("%w: its members aren't stored", models.ErrRolloutSegment)
+	}
+
+	const queryMembers = `
+	SELECT user_id FROM segments_users WHERE segments_id = $1 AND user_id > $2 ORDER BY user_id LIMIT $3;
+	`
+	rows, err := q.Query(ctx, queryMembers, segmentID, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	members := []uuid.UUID{}
+	for rows.Next() {
+		var userID uuid.UUID
+		if err = rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		members = append(members, userID)
+	}
+	return members, rows.Err()
+}
+
+type composite struct {
+	id   int32
+	slug string
+	expr *sets.Expr
+}
+
+// loadComposites returns the composite segments in the order their members have to be computed in.
+func loadComposites(ctx context.Context, q querier) ([]composite, error) {
+	const query = `
+	SELECT id, name, expression FROM segments WHERE expression IS NOT NULL;
+	`
+	rows, err := q.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	bySlug := make(map[string]composite)
+	exprs := make(map[string]*sets.Expr)
+	for rows.Next() {
+		var c composite
+		var src string
+		if err = rows.Scan(&c.id, &c.slug, &src); err != nil {
+			return nil, err
+		}
+		if c.expr, err = sets.Parse(src); err != nil {
+			return nil, fmt.Errorf("stored expression of '%s' can't be parsed: %w", c.slug, err)
+		}
+		bySlug[c.slug] = c
+		exprs[c.slug] = c.expr
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	order, err := sets.Order(exprs)
+	if err != nil {
+		return nil, err
+	}
+	composites := make([]composite, 0, len(order))
+	for _, slug := range order {
+		composites = append(composites, bySlug[slug])
+	}
+	return composites, nil
+}
+
+// syncComposites brings the members of the composite segments in line with their expressions for the given users,
+// or for all users if users is nil, stopping at their caps, and writes the changes to the report. Only the composites
+// whose expressions refer, directly or through other composites, to the segments with the changed IDs are recomputed,
+// all of them if changed is nil, and none if it is empty. It must be called in the transaction that changed
+// the memberships, after the changes. The returned results are keyed by segment ID.
+func syncComposites(ctx context.Context, q querier, users []uuid.UUID, changed []int32) (map[int32]models.SyncResult, error) {
+	if changed != nil && len(changed) == 0 {
+		return nil, nil
+	}
+	lock := `SELECT pg_advisory_xact_lock_shared($1);`
+	if users == nil {
+		lock = `SELECT pg_advisory_xact_lock($1);`
+	}
+	if _, err := q.Exec(ctx, lock, compositesLock); err != nil {
+		return nil, err
+	}
+	composites, err := loadComposites(ctx, q)
+	if err != nil || len(composites) == 0 {
+		return nil, err
+	}
+
+	// resolve the slugs the expressions refer to
+	var slugs []string
+	for _, c := range composites {
+		slugs = append(slugs, c.expr.Segments()...)
+	}
+	ids := make(map[string]int32)
+	rows, err := q.Query(ctx, `SELECT id, name FROM segments WHERE name = ANY($1::text[]);`, slugs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int32
+		var slug string
+		if err = rows.Scan(&id, &slug); err != nil {
+			return nil, err
+		}
+		ids[slug] = id
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	for _, slug := range slugs {
+		if _, ok := ids[slug]; !ok {
+			return nil, fmt.Errorf("%w: '%s'", models.ErrSegmentNotFound, slug)
+		}
+	}
+	if changed != nil {
+		if composites = dependentComposites(composites, ids, changed); len(composites) == 0 {
+			return nil, nil
+		}
+	}
+	if users != nil {
+		if err = lockUsers(ctx, q, users); err != nil {
+			return nil, err
+		}
+	}
+
+	// the composites with a cap stop at it, the users freed by the same recomputation make room only the next time
+	composed := make([]string, 0, len(composites))
+	for _, c := range composites {
+		composed = append(composed, c.slug)
+	}
+	caps, err := lockCapacity(ctx, q, composed)
+	if err != nil {
+		return nil, err
+	}
+
+	// every statement sees the changes of the previous ones, so composites of composites get the fresh members
+	const querySync = `
+	WITH target AS (
+		%s
+	), deleted AS (
+		DELETE FROM segments_users
+		WHERE segments_id = $1 AND ($2::uuid[] IS NULL OR user_id = ANY($2::uuid[]))
+			AND NOT EXISTS (SELECT 1 FROM target WHERE target.user_id = segments_users.user_id)
+		RETURNING user_id
+	), inserted AS (
+		INSERT INTO segments_users (segments_id, user_id)
+		SELECT $1, user_id FROM target
+		WHERE NOT EXISTS (SELECT 1 FROM segments_users WHERE segments_id = $1 AND segments_users.user_id = target.user_id)
+		ORDER BY user_id LIMIT $8
+		ON CONFLICT DO NOTHING
+		RETURNING user_id
+	), reported AS (
+		INSERT INTO report (user_id, segments_id, action, actor, source, reason)
+		SELECT user_id, $1, $3, $5::text, $6::text, $7::text FROM deleted
+		UNION ALL SELECT user_id, $1, $4, $5::text, $6::text, $7::text FROM inserted
+	)
+	SELECT (SELECT COUNT(*) FROM inserted), (SELECT COUNT(*) FROM deleted);
+	`
+	// the memberships are computed from the expressions, whatever change triggered the recomputation
+	by := models.AttributionFrom(models.WithSource(ctx, models.SourceRule))
+	results := make(map[int32]models.SyncResult, len(composites))
+	for _, c := range composites {
+		var result models.SyncResult
+		var room *int
+		if capped, ok := caps[c.slug]; ok {
+			n := capped.room()
+			room = &n
+		}
+		query := fmt.Sprintf(querySync, setQuery(c.expr, ids))
+		err = q.QueryRow(ctx, query, c.id, users, models.ActRemove, models.ActAdd, by.Actor, by.Source, by.Reason, room).
+			Scan(&result.Added, &result.Removed)
+		if err != nil {
+			return nil, err
+		}
+		results[c.id] = result
+	}
+	if err = recordFull(ctx, q, caps); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// dependentComposites returns the composites, given in the order of loadComposites, whose expressions refer to
+// the segments with the changed IDs or to other composites returned. The order is kept.
+func dependentComposites(composites []composite, ids map[string]int32, changed []int32) []composite {
+	dirty := make(map[int32]bool, len(changed))
+	for _, id := range changed {
+		dirty[id] = true
+	}
+	var dependent []composite
+	for _, c := range composites {
+		// the composites a composite refers to come before it, so they are already marked
+		for _, slug := range c.expr.Segments() {
+			if dirty[ids[slug]] {
+				dirty[c.id] = true
+				dependent = append(dependent, c)
+				break
+			}
+		}
+	}
+	return dependent
+}
+
+// lockUsers takes the per-user advisory locks of the users until the end of the transaction. The locks are taken
+// in a fixed order, so that concurrent batches don't deadlock.
+func lockUsers(ctx context.Context, q querier, users []uuid.UUID) error {
+	const query = `
+	SELECT pg_advisory_xact_lock($1, h) FROM (
+		SELECT DISTINCT hashtext(user_id::text) AS h FROM unnest($2::uuid[]) AS user_id ORDER BY h
+	) AS input;
+	`
+	_, err := q.Exec(ctx, query, usersLockSpace, users)
+	return err
+}
+
+// setQuery compiles the expression to a query of the user IDs of the set, limited to the users in $2 unless it is null.
+// Only segment IDs are inlined, so the query is safe to build from the expression.
+func setQuery(e *sets.Expr, ids map[string]int32) string {
+	const member = `SELECT user_id FROM segments_users WHERE segments_id = %d AND ($2::uuid[] IS NULL OR user_id = ANY($2::uuid[]))`
+	if e.Op == sets.OpSegment {
+		return fmt.Sprintf(member, ids[e.Slug])
+	}
+	op := map[sets.Op]string{sets.OpUnion: "UNION", sets.OpIntersect: "INTERSECT", sets.OpExcept: "EXCEPT"}[e.Op]
+	return "(" + setQuery(e.Left, ids) + ") " + op + " (" + setQuery(e.Right, ids) + ")"
+}
+
+// checkNotReferenced returns models.ErrSegmentInUse if the expression of a composite segment refers to the segment.
+// It takes the composites lock, so that no expression starts referring to the segment until the transaction ends.
+func checkNotReferenced(ctx context.Context, q querier, slug string) error {
+	if _, err := q.Exec(ctx, `SELECT pg_advisory_xact_lock($1);`, compositesLock); err != nil {
+		return err
+	}
+	composites, err := loadComposites(ctx, q)
+	if err != nil {
+		return err
+	}
+	var users []string
+	for _, c := range composites {
+		for _, s := range c.expr.Segments() {
+			if s == slug {
+				users = append(users, c.slug)
+			}
+		}
+	}
+	if len(users) != 0 {
+		return fmt.Errorf("%w by composite segments: %s", models.ErrSegmentInUse, strings.Join(users, ", "))
+	}
+	return nil
+}
+
+// checkNoRollouts returns models.ErrRolloutSegment if the expression refers to a percentage rollout, whose members
+// aren't stored. It must be called under the composites lock, which SetSegmentRollout takes too.
+func checkNoRollouts(ctx context.Context, q querier, expression string) error {
+	expr, err := sets.Parse(expression)
+	if err != nil {
+		return fmt.Errorf("%w: %v", models.ErrInvalidExpression, err)
+	}
+	const query = `
+	SELECT name FROM segments WHERE name = ANY($1::text[]) AND rollout IS NOT NULL ORDER BY name LIMIT 1;
+	`
+	var slug string
+	err = q.QueryRow(ctx, query, expr.Segments()).Scan(&slug)
+	if err == nil {
+		return fmt.Errorf("%w: '%s' can't be used in an expression", models.ErrRolloutSegment, slug)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,44 @@
+package db
+
+import (
+	"fmt"
+	"segmentation-service/internal/domain/sets"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+)
+
+func TestSetQuery(t *testing.T) {
+	expr, err := sets.Parse(`A union B intersect C except A`)
+	require.NoError(t, err)
+
+	member := func(id int) string {
+		return fmt.Sprintf(`SELECT user_id FROM segments_users WHERE segments_id = %d AND ($2::uuid[] IS NULL OR user_id = ANY($2::uuid[]))`, id)
+	}
+	exp := "((" + member(1) + ") UNION ((" + member(2) + ") INTERSECT (" + member(3) + "))) EXCEPT (" + member(1) + ")"
+	assert.Equal(t, exp, setQuery(expr, map[string]int32{"A": 1, "B": 2, "C": 3}))
+}
+
+func TestDependentComposites(t *testing.T) {
+	// C1 = A union B, C2 = C1 except D, C3 = D
+	ids := map[string]int32{"A": 1, "B": 2, "D": 3, "C1": 10, "C2": 11, "C3": 12}
+	var composites []composite
+	for _, c := range []struct{ slug, expr string }{{"C1", "A union B"}, {"C2", "C1 except D"}, {"C3", "D"}} {
+		expr, err := sets.Parse(c.expr)
+		require.NoError(t, err)
+		composites = append(composites, composite{id: ids[c.slug], slug: c.slug, expr: expr})
+	}
+	slugsOf := func(composites []composite) []string {
+		slugs := []string{}
+		for _, c := range composites {
+			slugs = append(slugs, c.slug)
+		}
+		return slugs
+	}
+
+	// the composites over a composite depend on its segments too
+	assert.DeepEqual(t, []string{"C1", "C2"}, slugsOf(dependentComposites(composites, ids, []int32{1})))
+	assert.DeepEqual(t, []string{"C2", "C3"}, slugsOf(dependentComposites(composites, ids, []int32{3})))
+	assert.DeepEqual(t, []string{}, slugsOf(dependentComposites(composites, ids, []int32{42})))
+}
@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"segmentation-service/pkg/infra/logger"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type ConnectOptions struct {
+	InitialBackoff time.Duration // delay after the first failed attempt, doubled after each next one
+	MaxBackoff     time.Duration // upper bound of a single delay
+	MaxWait        time.Duration // total time after which the connection attempts are abandoned
+}
+
+// connect tries to create a connection pool until it succeeds, ctx is cancelled or the maximum wait is exceeded.
+// Between the attempts it waits with exponential backoff and full jitter.
+func connect(ctx context.Context, conn string, opts ConnectOptions) (*pgxpool.Pool, error) {
+	log := logger.Get()
+	ctx, cancel := context.WithTimeout(ctx, opts.MaxWait)
+	defer cancel()
+
+	for attempt := 1; ; attempt++ {
+		pool, err := pgxpool.Connect(ctx, conn)
+		if err == nil {
+			if err = pool.Ping(ctx); err == nil {
+				return pool, nil
+			}
+			pool.Close()
+		}
+
+		delay := backoff(attempt, opts.InitialBackoff, opts.MaxBackoff, rand.Float64())
+		log.Warn("database connection failed", "attempt", attempt, "retry_in", delay, "desc", err.Error())
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, fmt.Errorf("database unavailable after %d attempts: %w", attempt, err)
+		}
+	}
+}
+
+// backoff returns the delay before the next attempt: a random value in [0, min(maxDelay, initial*2^(attempt-1))].
+// The jitter is passed in as a number in [0, 1) to keep the function deterministic.
+func backoff(attempt int, initial, maxDelay time.Duration, jitter float64) time.Duration {
+	d := initial
+	for i := 1; i < attempt && d < maxDelay; i++ {
+		d *= 2
+	}
+	if d > maxDelay {
+		d = maxDelay
+	}
+	return time.Duration(jitter * float64(d))
+}
@@ -0,0 +1,32 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestBackoff(t *testing.T) {
+	// prepare test data
+	testCases := []struct {
+		name     string
+		attempt  int
+		jitter   float64
+		expDelay time.Duration
+	}{
+		{name: "First attempt", attempt: 1, jitter: 1, expDelay: 100 * time.Millisecond},
+		{name: "Doubles", attempt: 3, jitter: 1, expDelay: 400 * time.Millisecond},
+		{name: "Capped", attempt: 10, jitter: 1, expDelay: time.Second},
+		{name: "Large attempt does not overflow", attempt: 1000, jitter: 1, expDelay: time.Second},
+		{name: "Jitter", attempt: 2, jitter: 0.5, expDelay: 100 * time.Millisecond},
+		{name: "Zero jitter", attempt: 5, jitter: 0, expDelay: 0},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expDelay, backoff(tc.attempt, 100*time.Millisecond, time.Second, tc.jitter))
+		})
+	}
+}
@@ -4,65 +4,180 @@ package db
 import (
 	"context"
 	"fmt"
-	"log"
 	"segmentation-service/internal/domain/models"
 	"segmentation-service/internal/ports"
 	"segmentation-service/pkg/infra/logger"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 	_ "github.com/lib/pq"
 )
 
 type DBStorage struct {
 	Pool *pgxpool.Pool
+
+	reportLoc *time.Location // time zone of the times in the csv reports, UTC if nil
 }
 
 var _ ports.SegmentStorage = (*DBStorage)(nil)
-var ctx = context.Background()
+var _ ports.HealthChecker = (*DBStorage)(nil)
 
-// New establishes one connection and returns a new instance of DBStorage.
-func New(ctx context.Context, conn string) (*DBStorage, error) {
-	time.Sleep(time.Second)
-	pool, err := pgxpool.Connect(ctx, conn)
+// New connects to the database, retrying while it is not ready yet, and returns a new instance of DBStorage
+// writing the times of the csv reports in reportLoc.
+func New(ctx context.Context, conn string, opts ConnectOptions, reportLoc *time.Location) (*DBStorage, error) {
+	pool, err := connect(ctx, conn, opts)
 	if err != nil {
 		return nil, err
 	}
 	return &DBStorage{
-		Pool: pool,
+		Pool:      pool,
+		reportLoc: reportLoc,
 	}, nil
 }
 
-func (db *DBStorage) FindSegment(slug string) (count int, err error) {
+// Ping checks that the database answers and that all the tables of the schema have been created.
+func (db *DBStorage) Ping(ctx context.Context) error {
+	if err := db.Pool.Ping(ctx); err != nil {
+		return fmt.Errorf("database ping failed: %w", err)
+	}
+	q := withSpans(db.Pool, "Ping")
+	const query = `
+	SELECT to_regclass('segments') IS NOT NULL
+		AND to_regclass('segments_users') IS NOT NULL
+		AND to_regclass('rollout_exclusions') IS NOT NULL
+		AND to_regclass('segment_aliases') IS NOT NULL
+		AND to_regclass('report') IS NOT NULL
+		AND to_regclass('segment_events') IS NOT NULL
+		AND to_regclass('user_attributes') IS NOT NULL
+		AND to_regclass('experiment_groups') IS NOT NULL
+		AND to_regclass('experiment_variants') IS NOT NULL;
+	`
+	var migrated bool
+	if err := q.QueryRow(ctx, query).Scan(&migrated); err != nil {
+		return fmt.Errorf("schema check failed: %w", err)
+	}
+	if !migrated {
+		return fmt.Errorf("database schema is not migrated")
+	}
+	return nil
+}
+
+// Close closes all connections of the pool.
+func (db *DBStorage) Close(ctx context.Context) error {
+	db.Pool.Close()
+	return nil
+}
+
+func (db *DBStorage) FindSegment(ctx context.Context, slug string) (count int, err error) {
+	q := withSpans(db.Pool, "FindSegment")
 	const query = `
 	SELECT COUNT(*) FROM segments WHERE name = $1;
 	`
-	err = db.Pool.QueryRow(ctx, query, slug).Scan(&count)
+	err = q.QueryRow(ctx, query, slug).Scan(&count)
 	return count, err
 }
 
-func (db *DBStorage) SaveSegment(slug string) (err error) {
+// SaveSegment creates the segment with its settings in one transaction, so that a segment whose settings are
+// rejected isn't created, and records its creation in the audit log. The cap is set before the members are computed
+// by the rule or the expression, so that they stop at it. The slugs of renamed segments are reserved.
+func (db *DBStorage) SaveSegment(ctx context.Context, segment models.Segment, evaluate models.RuleEvaluator) (err error) {
+	// start a transaction
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+	q := withSpans(tx, "SaveSegment")
+
+	by := models.AttributionFrom(ctx)
 	const query = `
-	INSERT INTO segments (name) VALUES ($1);
+	WITH created AS (
+		INSERT INTO segments (name) SELECT $1::text WHERE NOT EXISTS (SELECT 1 FROM segment_aliases WHERE name = $1)
+		RETURNING id, name
+	)
+	INSERT INTO segment_events (segments_id, slug, event, new_value, actor, source, reason)
+	SELECT id, name, $2::text, name, $3::text, $4::text, $5::text FROM created;
 	`
-	_, err = db.Pool.Exec(ctx, query, slug)
-	return err
+	slug := segment.Slug
+	tag, err := q.Exec(ctx, query, slug, models.EventCreated, by.Actor, by.Source, by.Reason)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrSlugReserved
+	}
+
+	if segment.ActiveFrom != nil || segment.ActiveUntil != nil {
+		if _, err = setSegmentWindow(ctx, q, slug, segment.ActiveFrom, segment.ActiveUntil); err != nil {
+			return err
+		}
+	}
+	if segment.MaxMembers != nil {
+		if _, err = setSegmentCap(ctx, q, slug, segment.MaxMembers); err != nil {
+			return err
+		}
+	}
+	if segment.Rule != "" {
+		if _, err = setSegmentRule(ctx, q, slug, segment.Rule, evaluate); err != nil {
+			return err
+		}
+	}
+	if segment.Expression != "" {
+		if _, err = setSegmentExpression(ctx, q, slug, segment.Expression); err != nil {
+			return err
+		}
+	}
+	if segment.Rollout != nil {
+		if _, err = setSegmentRollout(ctx, q, slug, segment.Rollout, segment.Salt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
 }
 
-// DeleteSegment removes a segment and all users from it.
-func (db *DBStorage) DeleteSegment(slug string) (err error) {
+// DeleteSegment removes a segment and all users from it. A segment used by composite segments or experiment groups
+// can't be deleted.
+func (db *DBStorage) DeleteSegment(ctx context.Context, slug string) (err error) {
 	// start a transaction
 	tx, err := db.Pool.Begin(ctx)
 	if err != nil {
 		return err
 	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+	q := withSpans(tx, "DeleteSegment")
+
+	if err = checkNotReferenced(ctx, q, slug); err != nil {
+		return err
+	}
+	group, err := experimentOf(ctx, q, slug)
+	if err != nil {
+		return err
+	}
+	if group != "" {
+		return fmt.Errorf("%w by experiment group '%s'", models.ErrSegmentInUse, group)
+	}
+	// the audit log has no foreign key, so the event stays after the segment is deleted
+	if err = recordEvent(ctx, q, slug, models.EventDeleted, slug, ""); err != nil {
+		return err
+	}
 
 	// remove all users from a segment
 	const queryDeleteUsers = `	
 	DELETE FROM segments_users WHERE segments_id = (SELECT id FROM segments WHERE segments.name = $1);
 	`
-	_, err = tx.Exec(ctx, queryDeleteUsers, slug)
+	_, err = q.Exec(ctx, queryDeleteUsers, slug)
 	if err != nil {
 		return err
 	}
@@ -71,193 +186,457 @@ func (db *DBStorage) DeleteSegment(slug string) (err error) {
 	const queryDeleteSegment = `
 	DELETE FROM segments WHERE name = $1;
 	`
-	_, err = tx.Exec(ctx, queryDeleteSegment, slug)
+	_, err = q.Exec(ctx, queryDeleteSegment, slug)
 	if err != nil {
 		return err
 	}
-
-	if tx.Commit(ctx) != nil {
-		if rb := tx.Rollback(ctx); rb != nil {
-			log.Fatalf("query failed: %v, unable to abort: %v", err, rb)
-		}
-	}
-	return nil
+	return tx.Commit(ctx)
 }
 
-// UpdateUserSegments adds and removes segments from a user. If one of the segments is not in the database, an error will be returned.
-func (db *DBStorage) UpdateUserSegments(data models.UpdateRequest, userID uuid.UUID) error {
+// UpdateUserSegments adds and removes segments from a user and returns the diff of the user's memberships with
+// the status of every requested segment. Removing the user from a percentage rollout excludes them from it, which
+// is a change only if inRollout puts them into the rollout. In the strict mode nothing is changed if some of the
+// segments are not in the database, and the error names them. In the non-strict mode such segments get the not found
+// status and the rest are applied. A dry run lists the unknown segments instead of failing in both modes and rolls
+// back the transaction, so nothing is written. The number of statements doesn't depend on the number of requested
+// segments, except for the composite segments over the changed ones, which are recomputed with one statement each.
+func (db *DBStorage) UpdateUserSegments(ctx context.Context, data models.UpdateRequest, userID uuid.UUID, inRollout models.RolloutChecker) (diff models.MembershipDiff, err error) {
 	// start a transaction
 	tx, err := db.Pool.Begin(ctx)
 	if err != nil {
-		return err
+		return diff, err
 	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+	q := withSpans(tx, "UpdateUserSegments")
 	logger := logger.Get()
+	by := models.AttributionFrom(ctx)
+	diff = models.MembershipDiff{DryRun: data.DryRun, Unknown: []string{}, Results: []models.SegmentResult{}}
 
-	logger.Debug("start processing the list of segments for deletion")
-	for _, slug := range data.SegmentsToRemove {
-		// check that the segment with the given slug exists and get segment_id
-		const querySegmId = `
-		SELECT id FROM segments WHERE name = $1;
-		`
-		var segment_id int
-		if err = tx.QueryRow(ctx, querySegmId, slug).Scan(&segment_id); err != nil {
-			return models.ErrSegmentNotFound
+	// look all the segments up at once, so that the error names every missing one
+	const querySegments = `
+	SELECT name, id, rollout, COALESCE(salt, '') FROM segments WHERE name = ANY($1::text[]);
+	`
+	rows, err := q.Query(ctx, querySegments, slices.Concat(data.SegmentsToAdd, data.SegmentsToRemove))
+	if err != nil {
+		return diff, err
+	}
+	ids := make(map[string]int)
+	var rolledIn []int // the percentage rollouts the user falls into
+	for rows.Next() {
+		var slug, salt string
+		var id int
+		var rollout *float64
+		if err = rows.Scan(&slug, &id, &rollout, &salt); err != nil {
+			rows.Close()
+			return diff, err
+		}
+		ids[slug] = id
+		if rollout != nil && inRollout(salt, userID, *rollout) {
+			rolledIn = append(rolledIn, id)
 		}
+	}
+	if err = rows.Err(); err != nil {
+		return diff, err
+	}
+	for _, slug := range slices.Concat(data.SegmentsToRemove, data.SegmentsToAdd) {
+		if _, ok := ids[slug]; !ok && !slices.Contains(diff.Unknown, slug) {
+			diff.Unknown = append(diff.Unknown, slug)
+		}
+	}
+	if len(diff.Unknown) > 0 && !data.NonStrict && !data.DryRun {
+		return diff, fmt.Errorf("%w: '%s'", models.ErrSegmentNotFound, strings.Join(diff.Unknown, "', '"))
+	}
 
-		// remove row from segments_users table
-		const queryDelete = `
-		DELETE FROM segments_users WHERE segments_id = $1 AND user_id = $2;
-		`
-		_, err = tx.Exec(ctx, queryDelete, segment_id, userID)
-		if err != nil {
-			logger.Debug("failed to delete entry from segments_users table")
-			return err
+	// the memberships before the update, to compute the diff
+	before, err := memberships(ctx, q, []uuid.UUID{userID})
+	if err != nil {
+		return diff, err
+	}
+	var requested []models.Membership
+	result := func(slug, action, status string) {
+		diff.Results = append(diff.Results, models.SegmentResult{Segment: slug, Action: action, Status: status})
+	}
+
+	// the known segments in the order of the request; the statements below skip the repeated ones
+	known := func(slugs []string) []int {
+		list := make([]int, 0, len(slugs))
+		for _, slug := range slugs {
+			if id, ok := ids[slug]; ok {
+				list = append(list, id)
+			}
 		}
+		return list
+	}
 
-		// add delete entry to report table
-		const queryReport = `
-			INSERT INTO report (user_id, segments_id, action)
-			VALUES ($1, $2, $3)
-			`
-		_, err = tx.Exec(ctx, queryReport, userID, segment_id, models.ActRemove)
+	logger.DebugContext(ctx, "start processing the list of segments for deletion")
+	changed := []int32{} // the segments whose memberships changed, for the composites over them
+	dropped := make(map[int]bool)
+	if toRemove := known(data.SegmentsToRemove); len(toRemove) > 0 {
+		// one statement removes the memberships, excludes the user from the percentage rollouts, so that the hash
+		// doesn't add them back, and writes the report rows of the segments either of them changed, in the order
+		// of the request; removing a non-member is a no-op and isn't written to the report, and so is excluding
+		// the user from a rollout they don't fall into, $7 being the rollouts they do
+		const queryRemove = `
+		WITH input AS (
+			SELECT segments_id, MIN(n) AS n FROM unnest($1::int[]) WITH ORDINALITY AS input(segments_id, n) GROUP BY segments_id
+		), deleted AS (
+			DELETE FROM segments_users USING input
+			WHERE segments_users.segments_id = input.segments_id AND segments_users.user_id = $2
+			RETURNING segments_users.segments_id
+		), excluded AS (
+			INSERT INTO rollout_exclusions (segments_id, user_id)
+			SELECT segments.id, $2::uuid FROM segments INNER JOIN input ON input.segments_id = segments.id
+			WHERE segments.rollout IS NOT NULL
+			ON CONFLICT DO NOTHING
+			RETURNING segments_id
+		), changed AS (
+			SELECT segments_id FROM deleted UNION SELECT segments_id FROM excluded WHERE segments_id = ANY($7::int[])
+		), reported AS (
+			INSERT INTO report (user_id, segments_id, action, actor, source, reason)
+			SELECT $2::uuid, changed.segments_id, $3::text, $4::text, $5::text, $6::text
+			FROM changed INNER JOIN input ON input.segments_id = changed.segments_id ORDER BY input.n
+		)
+		SELECT segments_id FROM changed;
+		`
+		rows, err = q.Query(ctx, queryRemove, toRemove, userID, models.ActRemove, by.Actor, by.Source, by.Reason, rolledIn)
 		if err != nil {
-			logger.Debug("failed to add delete record to report table")
-			return err
+			logger.DebugContext(ctx, "failed to remove entries from segments_users table")
+			return diff, err
+		}
+		for rows.Next() {
+			var id int
+			if err = rows.Scan(&id); err != nil {
+				rows.Close()
+				return diff, err
+			}
+			dropped[id] = true
+			changed = append(changed, int32(id))
+		}
+		if err = rows.Err(); err != nil {
+			return diff, err
+		}
+	}
+	for _, slug := range data.SegmentsToRemove {
+		segment_id, ok := ids[slug]
+		if !ok {
+			result(slug, models.ActRemove, models.StatusNotFound)
+			continue
+		}
+		requested = append(requested, models.Membership{UserID: userID, Segment: slug})
+		// a repeated segment is no longer a member
+		if dropped[segment_id] {
+			result(slug, models.ActRemove, models.StatusApplied)
+			delete(dropped, segment_id)
+		} else {
+			result(slug, models.ActRemove, models.StatusNotMember)
 		}
 	}
 
-	logger.Debug("start processing the list of segments to be added")
-	for _, slug := range data.SegmentsToAdd {
-		// check that the segment with the given slug exists
-		const querySegmId = `
-		SELECT id FROM segments WHERE name = $1;
-		`
-		var segment_id int
-		if err = tx.QueryRow(ctx, querySegmId, slug).Scan(&segment_id); err != nil {
-			return models.ErrSegmentNotFound
+	// the capped segments are counted after the removals, under their locks, see lockCapacity
+	var caps map[string]capacity
+	if len(data.SegmentsToAdd) > 0 {
+		if err = lockUsers(ctx, q, []uuid.UUID{userID}); err != nil {
+			return diff, err
+		}
+		if caps, err = lockCapacity(ctx, q, data.SegmentsToAdd); err != nil {
+			return diff, err
 		}
+	}
 
-		// check if the user is added to the segment
+	logger.DebugContext(ctx, "start processing the list of segments to be added")
+	members := make(map[int]bool)
+	toAdd := known(data.SegmentsToAdd)
+	if len(toAdd) > 0 {
+		// an explicitly added user is no longer excluded from a percentage rollout, even if the segment is full
 		const queryCheck = `
-		SELECT COUNT(*) FROM segments_users WHERE segments_id = $1 AND user_id = $2;
+		WITH included AS (
+			DELETE FROM rollout_exclusions WHERE segments_id = ANY($1::int[]) AND user_id = $2
+		)
+		SELECT segments_id FROM segments_users WHERE segments_id = ANY($1::int[]) AND user_id = $2;
 		`
-		var cnt int
-		if err = tx.QueryRow(ctx, queryCheck, segment_id, userID).Scan(&cnt); err != nil {
-			return err
+		rows, err = q.Query(ctx, queryCheck, toAdd, userID)
+		if err != nil {
+			logger.DebugContext(ctx, "failed to check entries of segments_users table")
+			return diff, err
+		}
+		for rows.Next() {
+			var id int
+			if err = rows.Scan(&id); err != nil {
+				rows.Close()
+				return diff, err
+			}
+			members[id] = true
 		}
+		if err = rows.Err(); err != nil {
+			return diff, err
+		}
+	}
 
-		// if there is no user in the segment, add it
-		if cnt == 0 {
-			const queryInsert = `
-			INSERT INTO segments_users (segments_id, user_id)
-			VALUES ($1, $2)
-			`
-			_, err = tx.Exec(ctx, queryInsert, segment_id, userID)
-			if err != nil {
-				logger.Debug("failed to add record to segments_users tablee")
-				return err
+	// the statuses are decided before the insert; the first occurrence of a repeated segment is applied, the rest
+	// are already members, and a full segment stays full
+	insert := make([]int, 0, len(toAdd))
+	applied := make(map[int]int) // the index of the result of every segment to insert
+	statuses := make(map[int]string)
+	for _, slug := range data.SegmentsToAdd {
+		segment_id, ok := ids[slug]
+		if !ok {
+			result(slug, models.ActAdd, models.StatusNotFound)
+			continue
+		}
+		status, seen := statuses[segment_id]
+		switch {
+		case seen && status == models.StatusApplied:
+			status = models.StatusAlreadyMember
+		case seen:
+			// the status of the first occurrence
+		case members[segment_id]:
+			status = models.StatusAlreadyMember
+		default:
+			// a full segment fails the update in the strict mode and is skipped otherwise
+			if c, ok := caps[slug]; ok && c.room() == 0 {
+				if !data.NonStrict && !data.DryRun {
+					return diff, c.fullError(slug)
+				}
+				status = models.StatusFull
+				break
 			}
+			status = models.StatusApplied
+			insert = append(insert, segment_id)
+			applied[segment_id] = len(diff.Results)
+		}
+		if !seen {
+			statuses[segment_id] = status
+		}
+		if status != models.StatusFull {
+			requested = append(requested, models.Membership{UserID: userID, Segment: slug})
+		}
+		result(slug, models.ActAdd, status)
+	}
 
-			// write add record to report table
-			const queryReport = `
-			INSERT INTO report (user_id, segments_id, action)
-			VALUES ($1, $2, $3)
-			`
-			_, err = tx.Exec(ctx, queryReport, userID, segment_id, models.ActAdd)
-			if err != nil {
-				logger.Debug("failed to write add entry to report table")
-				return err
+	if len(insert) > 0 {
+		// the report rows are written for the inserted memberships only, in the order of the request
+		const queryInsert = `
+		WITH input AS (
+			SELECT segments_id, n FROM unnest($1::int[]) WITH ORDINALITY AS input(segments_id, n)
+		), inserted AS (
+			INSERT INTO segments_users (segments_id, user_id)
+			SELECT segments_id, $2::uuid FROM input ORDER BY n
+			ON CONFLICT DO NOTHING
+			RETURNING segments_id
+		), reported AS (
+			INSERT INTO report (user_id, segments_id, action, actor, source, reason)
+			SELECT $2::uuid, inserted.segments_id, $3::text, $4::text, $5::text, $6::text
+			FROM inserted INNER JOIN input ON input.segments_id = inserted.segments_id ORDER BY input.n
+		)
+		SELECT segments_id FROM inserted;
+		`
+		rows, err = q.Query(ctx, queryInsert, insert, userID, models.ActAdd, by.Actor, by.Source, by.Reason)
+		if err != nil {
+			logger.DebugContext(ctx, "failed to add records to segments_users table")
+			return diff, err
+		}
+		for rows.Next() {
+			var id int
+			if err = rows.Scan(&id); err != nil {
+				rows.Close()
+				return diff, err
 			}
+			delete(applied, id)
+			changed = append(changed, int32(id))
+		}
+		if err = rows.Err(); err != nil {
+			return diff, err
+		}
+		// the memberships that appeared since the check weren't inserted
+		for _, i := range applied {
+			diff.Results[i].Status = models.StatusAlreadyMember
 		}
 	}
-	if tx.Commit(ctx) != nil {
-		if rb := tx.Rollback(ctx); rb != nil {
-			log.Fatalf("query failed: %v, unable to abort: %v", err, rb)
+
+	// keep the variants of the experiment groups exclusive, for the segments the user is now a member of
+	var users []uuid.UUID
+	var slugs []string
+	for _, r := range diff.Results {
+		if r.Action == models.ActAdd && (r.Status == models.StatusApplied || r.Status == models.StatusAlreadyMember) {
+			users, slugs = append(users, userID), append(slugs, r.Segment)
 		}
 	}
-	return nil
+	moved, err := enforceExperiments(ctx, q, users, slugs)
+	if err != nil {
+		logger.DebugContext(ctx, "failed to check experiment variants")
+		return diff, err
+	}
+
+	// recompute the composite segments of the user over the changed ones
+	if _, err = syncComposites(ctx, q, []uuid.UUID{userID}, append(changed, moved...)); err != nil {
+		logger.DebugContext(ctx, "failed to update composite segments")
+		return diff, err
+	}
+	if err = recordFull(ctx, q, caps); err != nil {
+		return diff, err
+	}
+
+	after, err := memberships(ctx, q, []uuid.UUID{userID})
+	if err != nil {
+		return diff, err
+	}
+	added, removed, noOp := diffMemberships(before, after, requested)
+	diff.Added, diff.Removed, diff.NoOp = segmentsOf(added), segmentsOf(removed), segmentsOf(noOp)
+	if data.DryRun {
+		return diff, tx.Rollback(ctx)
+	}
+	return diff, tx.Commit(ctx)
 }
 
-// GetUserSegments returns all segments the user is a member of.
-func (db *DBStorage) GetUserSegments(userID uuid.UUID) (models.SegmentsList, error) {
+// GetUserSegments returns all active segments the user is a member of: the stored memberships and the percentage
+// rollouts the user falls into by inRollout, unless they were explicitly removed from them. The segments outside
+// their activation windows are left out.
+func (db *DBStorage) GetUserSegments(ctx context.Context, userID uuid.UUID, inRollout models.RolloutChecker) (models.SegmentsList, error) {
+	q := withSpans(db.Pool, "GetUserSegments")
 	segments := models.SegmentsList{}
 	const query = `
-	SELECT segments.name FROM segments
-	INNER JOIN segments_users ON segments.id=segments_users.segments_id WHERE user_id = $1;
+	SELECT segments.name, segments.rollout, COALESCE(segments.salt, ''), segments_users.user_id IS NOT NULL, EXISTS (
+		SELECT 1 FROM rollout_exclusions WHERE rollout_exclusions.segments_id = segments.id AND rollout_exclusions.user_id = $1
+	)
+	FROM segments
+	LEFT JOIN segments_users ON segments.id=segments_users.segments_id AND segments_users.user_id = $1
+	WHERE (segments_users.user_id IS NOT NULL OR segments.rollout IS NOT NULL)
+		AND (segments.active_from IS NULL OR segments.active_from <= NOW())
+		AND (segments.active_until IS NULL OR segments.active_until > NOW());
 	`
-	rows, err := db.Pool.Query(ctx, query, userID)
+	rows, err := q.Query(ctx, query, userID)
 	if err != nil {
 		return segments, fmt.Errorf("can't get segments by user: %v", err)
 	}
+	defer rows.Close()
 
 	for rows.Next() {
-		var slug string
-		if err = rows.Scan(&slug); err != nil {
+		var slug, salt string
+		var rollout *float64
+		var member, excluded bool
+		if err = rows.Scan(&slug, &rollout, &salt, &member, &excluded); err != nil {
 			return segments, err
 		}
-		segments.S = append(segments.S, slug)
+		if member || (rollout != nil && !excluded && inRollout(salt, userID, *rollout)) {
+			segments.S = append(segments.S, slug)
+		}
 	}
-	return segments, err
+	return segments, rows.Err()
 }
 
-// GetMonthlyReport returns all entries about adding / removing users from segments for the specified month (in the format: yyyy-mm).
-func (db *DBStorage) GetReport(period string) ([][]string, error) {
-	var result [][]string
+// GetSegmentDefinitions returns the dynamic, composite and percentage rollout segments with their rules, expressions
+// and percentages by their slugs.
+func (db *DBStorage) GetSegmentDefinitions(ctx context.Context) (map[string]models.Segment, error) {
+	q := withSpans(db.Pool, "GetSegmentDefinitions")
+	const query = `
+	SELECT name, COALESCE(rule, ''), COALESCE(expression, ''), rollout, CASE WHEN rollout IS NOT NULL THEN salt ELSE '' END FROM segments
+	WHERE rule IS NOT NULL OR expression IS NOT NULL OR rollout IS NOT NULL;
+	`
+	rows, err := q.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	segments := make(map[string]models.Segment)
+	for rows.Next() {
+		var segment models.Segment
+		if err = rows.Scan(&segment.Slug, &segment.Rule, &segment.Expression, &segment.Rollout, &segment.Salt); err != nil {
+			return nil, err
+		}
+		segments[segment.Slug] = segment
+	}
+	return segments, rows.Err()
+}
 
-	const queryCheck = `
-	SELECT user_id, segments.name, action, created_at FROM segments
-	INNER JOIN report ON segments.id=report.segments_id
-	WHERE created_at between date($1) and date($1) + interval '1 month';
+// ListSegments returns the slugs of all segments in alphabetical order with their activation windows and states,
+// and the fill levels of the capped ones.
+func (db *DBStorage) ListSegments(ctx context.Context) (models.SegmentsList, error) {
+	q := withSpans(db.Pool, "ListSegments")
+	segments := models.SegmentsList{S: []string{}, States: []models.SegmentState{}}
+	query := `
+	SELECT name, ` + stateColumn + `, active_from, active_until, max_members,
+		CASE WHEN max_members IS NOT NULL THEN (SELECT COUNT(*) FROM segments_users WHERE segments_id = segments.id) END
+	FROM segments ORDER BY name;
 	`
-	rows, err := db.Pool.Query(ctx, queryCheck, period)
+	rows, err := q.Query(ctx, query)
 	if err != nil {
-		return result, fmt.Errorf("getting report for the month since '%s' failed: %v", period, err)
+		return segments, fmt.Errorf("can't get segments: %v", err)
 	}
+	defer rows.Close()
 
 	for rows.Next() {
-		var line models.ReportRow
-		err = rows.Scan(
-			&line.UserID,
-			&line.SegmentName,
-			&line.Action,
-			&line.Time,
-		)
+		var state models.SegmentState
+		err = rows.Scan(&state.Slug, &state.State, &state.ActiveFrom, &state.ActiveUntil, &state.MaxMembers, &state.Members)
 		if err != nil {
-			return result, err
+			return segments, err
 		}
-		arr := []string{fmt.Sprintf("%v", line.UserID), string(line.SegmentName), string(line.Action), line.Time.Add(3 * time.Hour).Format("2006-01-02 15:04:05")}
-		result = append(result, arr)
+		segments.S = append(segments.S, state.Slug)
+		segments.States = append(segments.States, state)
 	}
-	return result, nil
+	return segments, rows.Err()
 }
 
+// reportFilter is the condition of the report queries selecting the rows by their attribution, the parameters
+// $2, $3 and $4 are the actor, the source and the text the reason contains.
+const reportFilter = `($2::text = '' OR report.actor = $2) AND ($3::text = '' OR report.source = $3)
+	AND ($4::text = '' OR strpos(lower(report.reason), lower($4)) > 0)`
+
 // GetMonthlyReport returns all entries about adding / removing users from segments for the specified month (in the format: yyyy-mm).
-func (db *DBStorage) GetUserReport(period string, userID uuid.UUID) ([][]string, error) {
-	var result [][]string
+func (db *DBStorage) GetReport(ctx context.Context, period string, filter models.ReportFilter) ([][]string, error) {
+	q := withSpans(db.Pool, "GetReport")
 
-	const queryCheck = `
-	SELECT user_id, segments.name, action, created_at FROM segments
+	queryCheck := `
+	SELECT user_id, segments.name, action, created_at, actor, source, reason FROM segments
 	INNER JOIN report ON segments.id=report.segments_id
-	WHERE (created_at between date($1) and date($1) + interval '1 month') AND user_id = $2;
+	WHERE (created_at between date($1) and date($1) + interval '1 month') AND ` + reportFilter + `;
 	`
-	rows, err := db.Pool.Query(ctx, queryCheck, period, userID)
+	rows, err := q.Query(ctx, queryCheck, period, filter.Actor, filter.Source, filter.Reason)
 	if err != nil {
-		return result, fmt.Errorf("getting report for the month since '%s' failed: %v", period, err)
+		return nil, fmt.Errorf("getting report for the month since '%s' failed: %v", period, err)
 	}
+	return scanReport(rows, db.reportLoc)
+}
 
+// GetMonthlyReport returns all entries about adding / removing users from segments for the specified month (in the format: yyyy-mm).
+func (db *DBStorage) GetUserReport(ctx context.Context, period string, userID uuid.UUID, filter models.ReportFilter) ([][]string, error) {
+	q := withSpans(db.Pool, "GetUserReport")
+
+	queryCheck := `
+	SELECT user_id, segments.name, action, created_at, actor, source, reason FROM segments
+	INNER JOIN report ON segments.id=report.segments_id
+	WHERE (created_at between date($1) and date($1) + interval '1 month') AND ` + reportFilter + ` AND user_id = $5;
+	`
+	rows, err := q.Query(ctx, queryCheck, period, filter.Actor, filter.Source, filter.Reason, userID)
+	if err != nil {
+		return nil, fmt.Errorf("getting report for the month since '%s' failed: %v", period, err)
+	}
+	return scanReport(rows, db.reportLoc)
+}
+
+// scanReport reads the report rows in the order of the csv columns: user, segment, action, time, actor, source, reason.
+func scanReport(rows pgx.Rows, loc *time.Location) ([][]string, error) {
+	defer rows.Close()
+
+	var result [][]string
 	for rows.Next() {
 		var line models.ReportRow
-		err = rows.Scan(
+		err := rows.Scan(
 			&line.UserID,
 			&line.SegmentName,
 			&line.Action,
 			&line.Time,
+			&line.Actor,
+			&line.Source,
+			&line.Reason,
 		)
 		if err != nil {
 			return result, err
 		}
-		arr := []string{fmt.Sprintf("%v", line.UserID), string(line.SegmentName), string(line.Action), line.Time.Add(3 * time.Hour).Format("2006-01-02 15:04:05")}
-		result = append(result, arr)
+		result = append(result, line.Record(loc))
 	}
-	return result, nil
+	return result, rows.Err()
 }
@@ -0,0 +1,71 @@
+package db
+
+import (
+	"cmp"
+	"context"
+	"segmentation-service/internal/domain/models"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// memberships returns the stored memberships of the users.
+func memberships(ctx context.Context, q querier, users []uuid.UUID) (map[models.Membership]bool, error) {
+	const query = `
+	SELECT segments_users.user_id, segments.name FROM segments_users
+	INNER JOIN segments ON segments.id = segments_users.segments_id
+	WHERE segments_users.user_id = ANY($1::uuid[]);
+	`
+	rows, err := q.Query(ctx, query, users)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result := make(map[models.Membership]bool)
+	for rows.Next() {
+		var m models.Membership
+		if err = rows.Scan(&m.UserID, &m.Segment); err != nil {
+			return nil, err
+		}
+		result[m] = true
+	}
+	return result, rows.Err()
+}
+
+// diffMemberships compares the memberships before and after a change. The requested memberships that were neither
+// added nor removed are no-ops. Every list is sorted by the user ID and the slug and has no duplicates.
+func diffMemberships(before, after map[models.Membership]bool, requested []models.Membership) (added, removed, noOp []models.Membership) {
+	added, removed, noOp = []models.Membership{}, []models.Membership{}, []models.Membership{}
+	for m := range after {
+		if !before[m] {
+			added = append(added, m)
+		}
+	}
+	for m := range before {
+		if !after[m] {
+			removed = append(removed, m)
+		}
+	}
+	seen := make(map[models.Membership]bool, len(requested))
+	for _, m := range requested {
+		if before[m] == after[m] && !seen[m] {
+			noOp = append(noOp, m)
+		}
+		seen[m] = true
+	}
+	for _, list := range [][]models.Membership{added, removed, noOp} {
+		slices.SortFunc(list, func(a, b models.Membership) int {
+			return cmp.Or(slices.Compare(a.UserID[:], b.UserID[:]), cmp.Compare(a.Segment, b.Segment))
+		})
+	}
+	return added, removed, noOp
+}
+
+// segmentsOf returns the slugs of the memberships.
+func segmentsOf(list []models.Membership) []string {
+	slugs := make([]string, 0, len(list))
+	for _, m := range list {
+		slugs = append(slugs, m.Segment)
+	}
+	return slugs
+}
@@ -0,0 +1,28 @@
+package db
+
+import (
+	"segmentation-service/internal/domain/models"
+	"testing"
+
+	"github.com/google/uuid"
+	"gotest.tools/assert"
+)
+
+func TestDiffMemberships(t *testing.T) {
+	first, second := uuid.MustParse("00000000-0000-0000-0000-000000000001"), uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	m := func(userID uuid.UUID, slug string) models.Membership {
+		return models.Membership{UserID: userID, Segment: slug}
+	}
+
+	before := map[models.Membership]bool{m(first, "A"): true, m(first, "B"): true, m(second, "A"): true}
+	// A of the first user is removed and the composite C is added as a side effect, B is already there,
+	// D of the second user is added
+	after := map[models.Membership]bool{m(first, "B"): true, m(first, "C"): true, m(second, "A"): true, m(second, "D"): true}
+	requested := []models.Membership{m(second, "D"), m(first, "A"), m(first, "B"), m(first, "B"), m(second, "E")}
+
+	added, removed, noOp := diffMemberships(before, after, requested)
+	assert.DeepEqual(t, []models.Membership{m(first, "C"), m(second, "D")}, added)
+	assert.DeepEqual(t, []models.Membership{m(first, "A")}, removed)
+	assert.DeepEqual(t, []models.Membership{m(first, "B"), m(second, "E")}, noOp)
+	assert.DeepEqual(t, []string{"B", "E"}, segmentsOf(noOp))
+}
@@ -0,0 +1,329 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"segmentation-service/internal/domain/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+// CreateExperimentGroup saves the group with its variants. The variants must be existing ordinary segments that
+// aren't variants of other groups, and no user may be in two of them already.
+func (db *DBStorage) CreateExperimentGroup(ctx context.Context, group models.ExperimentGroup) (err error) {
+	slugs := make([]string, 0, len(group.Variants))
+	weights := make([]int32, 0, len(group.Variants))
+	for _, v := range group.Variants {
+		slugs = append(slugs, v.Slug)
+		weights = append(weights, int32(v.Weight))
+	}
+
+	// start a transaction
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+	q := withSpans(tx, "CreateExperimentGroup")
+
+	// the segments are locked, so that they don't get a rule or an expression until the group is saved
+	const querySegments = `
+	SELECT segments.id, segments.name, segments.rule IS NOT NULL OR segments.expression IS NOT NULL, segments.rollout IS NOT NULL,
+		COALESCE(experiment_groups.name, '')
+	FROM segments
+	LEFT JOIN experiment_variants variants ON variants.segments_id = segments.id
+	LEFT JOIN experiment_groups ON experiment_groups.id = variants.group_id
+	WHERE segments.name = ANY($1::text[])
+	FOR SHARE OF segments;
+	`
+	rows, err := q.Query(ctx, querySegments, slugs)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	ids := make(map[string]int32, len(slugs))
+	for rows.Next() {
+		var id int32
+		var slug, other string
+		var computed, rollout bool
+		if err = rows.Scan(&id, &slug, &computed, &rollout, &other); err != nil {
+			return err
+		}
+		if computed {
+			return fmt.Errorf("%w: '%s'", models.ErrDynamicSegment, slug)
+		}
+		if rollout {
+			return fmt.Errorf("%w: '%s'", models.ErrRolloutSegment, slug)
+		}
+		if other != "" {
+			return fmt.Errorf("%w: '%s' is a variant of '%s'", models.ErrSegmentInExperiment, slug, other)
+		}
+		ids[slug] = id
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	segmentIDs := make([]int32, 0, len(slugs))
+	for _, slug := range slugs {
+		id, ok := ids[slug]
+		if !ok {
+			return fmt.Errorf("%w: '%s'", models.ErrSegmentNotFound, slug)
+		}
+		segmentIDs = append(segmentIDs, id)
+	}
+
+	const queryOverlap = `
+	SELECT user_id FROM segments_users WHERE segments_id = ANY($1::int[]) GROUP BY user_id HAVING COUNT(*) > 1 LIMIT 1;
+	`
+	var userID uuid.UUID
+	err = q.QueryRow(ctx, queryOverlap, segmentIDs).Scan(&userID)
+	if err == nil {
+		return fmt.Errorf("%w: user %s is in several variants", models.ErrExperimentConflict, userID)
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+
+	const queryGroup = `
+	INSERT INTO experiment_groups (name, salt, conflict) VALUES ($1, $2, $3)
+	ON CONFLICT (name) DO NOTHING
+	RETURNING id;
+	`
+	var groupID int32
+	if err = q.QueryRow(ctx, queryGroup, group.Name, group.Salt, group.Conflict).Scan(&groupID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = models.ErrExperimentExists
+		}
+		return err
+	}
+
+	const queryVariants = `
+	INSERT INTO experiment_variants (group_id, segments_id, weight, position)
+	SELECT $1, input.segments_id, input.weight, input.position
+	FROM unnest($2::int[], $3::int[]) WITH ORDINALITY AS input(segments_id, weight, position);
+	`
+	if _, err = q.Exec(ctx, queryVariants, groupID, segmentIDs, weights); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// DeleteExperimentGroup deletes the group. Its segments and their members are kept.
+func (db *DBStorage) DeleteExperimentGroup(ctx context.Context, name string) error {
+	q := withSpans(db.Pool, "DeleteExperimentGroup")
+	const query = `
+	DELETE FROM experiment_groups WHERE name = $1;
+	`
+	tag, err := q.Exec(ctx, query, name)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrExperimentNotFound
+	}
+	return nil
+}
+
+// ListExperimentGroups returns all experiment groups in alphabetical order with their variants in their order.
+func (db *DBStorage) ListExperimentGroups(ctx context.Context) ([]models.ExperimentGroup, error) {
+	return listExperimentGroups(ctx, withSpans(db.Pool, "ListExperimentGroups"), "")
+}
+
+// listExperimentGroups returns the experiment groups, or only the one with the given name if it isn't empty.
+func listExperimentGroups(ctx context.Context, q querier, name string) ([]models.ExperimentGroup, error) {
+	const query = `
+	SELECT experiment_groups.name, experiment_groups.salt, experiment_groups.conflict, segments.name, variants.weight FROM experiment_groups
+	INNER JOIN experiment_variants variants ON variants.group_id = experiment_groups.id
+	INNER JOIN segments ON segments.id = variants.segments_id
+	WHERE $1::text = '' OR experiment_groups.name = $1
+	ORDER BY experiment_groups.name, variants.position;
+	`
+	rows, err := q.Query(ctx, query, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	groups := []models.ExperimentGroup{}
+	for rows.Next() {
+		var group models.ExperimentGroup
+		var variant models.Variant
+		if err = rows.Scan(&group.Name, &group.Salt, &group.Conflict, &variant.Slug, &variant.Weight); err != nil {
+			return nil, err
+		}
+		if n := len(groups); n == 0 || groups[n-1].Name != group.Name {
+			groups = append(groups, group)
+		}
+		last := &groups[len(groups)-1]
+		last.Variants = append(last.Variants, variant)
+	}
+	return groups, rows.Err()
+}
+
+// AssignExperiment adds the user to the variant of the group chosen by pick and writes it to the report. If the user
+// is already in a variant of the group, they stay there. If the chosen variant is full, models.ErrSegmentFull
+// is returned.
+func (db *DBStorage) AssignExperiment(ctx context.Context, name string, userID uuid.UUID, pick models.VariantPicker) (result models.Assignment, err error) {
+	result.Group = name
+
+	// start a transaction
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+	q := withSpans(tx, "AssignExperiment")
+
+	// the group is locked, so that it can't be deleted before the assignment is saved
+	const queryLock = `
+	SELECT id FROM experiment_groups WHERE name = $1 FOR SHARE;
+	`
+	var groupID int32
+	if err = q.QueryRow(ctx, queryLock, name).Scan(&groupID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = models.ErrExperimentNotFound
+		}
+		return result, err
+	}
+	groups, err := listExperimentGroups(ctx, q, name)
+	if err != nil {
+		return result, err
+	}
+
+	// concurrent updates of the user add variants one after another, so the check sees the committed ones
+	if err = lockUsers(ctx, q, []uuid.UUID{userID}); err != nil {
+		return result, err
+	}
+	const queryCurrent = `
+	SELECT segments.name FROM segments_users
+	INNER JOIN experiment_variants variants ON variants.segments_id = segments_users.segments_id
+	INNER JOIN segments ON segments.id = segments_users.segments_id
+	WHERE variants.group_id = $1 AND segments_users.user_id = $2
+	ORDER BY variants.position LIMIT 1;
+	`
+	err = q.QueryRow(ctx, queryCurrent, groupID, userID).Scan(&result.Segment)
+	if err == nil {
+		return result, tx.Commit(ctx)
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return result, err
+	}
+
+	result.Segment, result.Assigned = pick(groups[0], userID), true
+	caps, err := lockCapacity(ctx, q, []string{result.Segment})
+	if err != nil {
+		return result, err
+	}
+	if c, ok := caps[result.Segment]; ok && c.room() == 0 {
+		return result, c.fullError(result.Segment)
+	}
+	const queryAdd = `
+	WITH inserted AS (
+		INSERT INTO segments_users (segments_id, user_id) SELECT id, $2::uuid FROM segments WHERE name = $1
+		RETURNING segments_id, user_id
+	), reported AS (
+		INSERT INTO report (user_id, segments_id, action, actor, source, reason)
+		SELECT user_id, segments_id, $3, $4::text, $5::text, $6::text FROM inserted
+	)
+	SELECT segments_id FROM inserted;
+	`
+	by := models.AttributionFrom(ctx)
+	var segmentID int32
+	if err = q.QueryRow(ctx, queryAdd, result.Segment, userID, models.ActAdd, by.Actor, by.Source, by.Reason).Scan(&segmentID); err != nil {
+		return result, err
+	}
+
+	if _, err = syncComposites(ctx, q, []uuid.UUID{userID}, []int32{segmentID}); err != nil {
+		return result, err
+	}
+	if err = recordFull(ctx, q, caps); err != nil {
+		return result, err
+	}
+	return result, tx.Commit(ctx)
+}
+
+// enforceExperiments keeps the variants of the experiment groups mutually exclusive after the users were added
+// to the segments, given as pairs of users[i] and slugs[i]. If a user is also in a sibling variant, the change
+// fails in the groups with the reject policy, and the user is removed from the sibling in the groups with the move
+// policy, which is written to the report. Adding a user to two variants of the same group at once always fails.
+// It must be called in the transaction that added the users, after the changes. The IDs of the siblings the users
+// were removed from are returned.
+func enforceExperiments(ctx context.Context, q querier, users []uuid.UUID, slugs []string) ([]int32, error) {
+	if len(users) == 0 {
+		return []int32{}, nil
+	}
+	if err := lockUsers(ctx, q, users); err != nil {
+		return nil, err
+	}
+
+	const queryConflicts = `
+	WITH added AS (
+		SELECT DISTINCT input.user_id, segments.id AS segments_id FROM unnest($1::uuid[], $2::text[]) AS input(user_id, name)
+		INNER JOIN segments ON segments.name = input.name
+	)
+	SELECT added.user_id, experiment_groups.name FROM added
+	INNER JOIN experiment_variants variant ON variant.segments_id = added.segments_id
+	INNER JOIN experiment_variants sibling ON sibling.group_id = variant.group_id AND sibling.segments_id <> variant.segments_id
+	INNER JOIN experiment_groups ON experiment_groups.id = variant.group_id
+	INNER JOIN segments_users ON segments_users.user_id = added.user_id AND segments_users.segments_id = sibling.segments_id
+	WHERE experiment_groups.conflict = $3 OR EXISTS (
+		SELECT 1 FROM added other WHERE other.user_id = added.user_id AND other.segments_id = sibling.segments_id
+	)
+	LIMIT 1;
+	`
+	var userID uuid.UUID
+	var group string
+	err := q.QueryRow(ctx, queryConflicts, users, slugs, models.ConflictReject).Scan(&userID, &group)
+	if err == nil {
+		return nil, fmt.Errorf("%w: user %s, group '%s'", models.ErrExperimentConflict, userID, group)
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	const queryMove = `
+	WITH added AS (
+		SELECT DISTINCT input.user_id, segments.id AS segments_id FROM unnest($1::uuid[], $2::text[]) AS input(user_id, name)
+		INNER JOIN segments ON segments.name = input.name
+	), deleted AS (
+		DELETE FROM segments_users USING added, experiment_variants variant, experiment_variants sibling
+		WHERE variant.segments_id = added.segments_id
+			AND sibling.group_id = variant.group_id AND sibling.segments_id <> variant.segments_id
+			AND segments_users.user_id = added.user_id AND segments_users.segments_id = sibling.segments_id
+		RETURNING segments_users.segments_id, segments_users.user_id
+	), reported AS (
+		INSERT INTO report (user_id, segments_id, action, actor, source, reason)
+		SELECT user_id, segments_id, $3, $4::text, $5::text, $6::text FROM deleted
+	)
+	SELECT ARRAY(SELECT DISTINCT segments_id FROM deleted);
+	`
+	by := models.AttributionFrom(ctx)
+	var moved []int32
+	err = q.QueryRow(ctx, queryMove, users, slugs, models.ActRemove, by.Actor, by.Source, by.Reason).Scan(&moved)
+	return moved, err
+}
+
+// experimentOf returns the name of the experiment group the segment is a variant of, or an empty string.
+func experimentOf(ctx context.Context, q querier, slug string) (string, error) {
+	const query = `
+	SELECT experiment_groups.name FROM experiment_groups
+	INNER JOIN experiment_variants variants ON variants.group_id = experiment_groups.id
+	INNER JOIN segments ON segments.id = variants.segments_id
+	WHERE segments.name = $1;
+	`
+	var name string
+	err := q.QueryRow(ctx, query, slug).Scan(&name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	return name, err
+}
@@ -0,0 +1,89 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"segmentation-service/internal/domain/models"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// recordEvent writes the lifecycle event of the segment to the audit log with the attribution stored in ctx.
+// It must be called in the transaction of the change while the segment still has the given slug, so that
+// the event is written only if the change is committed and keeps the slug after a rename or deletion.
+func recordEvent(ctx context.Context, q querier, slug, event, oldValue, newValue string) error {
+	by := models.AttributionFrom(ctx)
+	const query = `
+	INSERT INTO segment_events (segments_id, slug, event, old_value, new_value, actor, source, reason)
+	SELECT id, name, $2::text, $3::text, $4::text, $5::text, $6::text, $7::text FROM segments WHERE name = $1;
+	`
+	_, err := q.Exec(ctx, query, slug, event, oldValue, newValue, by.Actor, by.Source, by.Reason)
+	return err
+}
+
+// GetSegmentHistory returns the audit log of the segment in the order of the events, including the events
+// recorded under its previous slugs.
+func (db *DBStorage) GetSegmentHistory(ctx context.Context, slug string) (history models.SegmentHistory, err error) {
+	q := withSpans(db.Pool, "GetSegmentHistory")
+	history = models.SegmentHistory{Slug: slug, Events: []models.SegmentEvent{}}
+
+	const querySegment = `
+	SELECT id FROM segments WHERE name = $1;
+	`
+	var segmentID int32
+	if err = q.QueryRow(ctx, querySegment, slug).Scan(&segmentID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = models.ErrSegmentNotFound
+		}
+		return history, err
+	}
+
+	const query = `
+	SELECT slug, event, old_value, new_value, created_at, actor, source, reason FROM segment_events
+	WHERE segments_id = $1
+	ORDER BY id;
+	`
+	rows, err := q.Query(ctx, query, segmentID)
+	if err != nil {
+		return history, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var e models.SegmentEvent
+		if err = rows.Scan(&e.Segment, &e.Event, &e.OldValue, &e.NewValue, &e.Time, &e.Actor, &e.Source, &e.Reason); err != nil {
+			return history, err
+		}
+		history.Events = append(history.Events, e)
+	}
+	return history, rows.Err()
+}
+
+// GetSegmentReport returns the audit log of all segments, the deleted ones included, for the month since period
+// (in the format: yyyy-mm-dd) in the order of the csv columns: segment, event, old value, new value, time, actor,
+// source, reason.
+func (db *DBStorage) GetSegmentReport(ctx context.Context, period string) ([][]string, error) {
+	q := withSpans(db.Pool, "GetSegmentReport")
+	const query = `
+	SELECT slug, event, old_value, new_value, created_at, actor, source, reason FROM segment_events
+	WHERE created_at between date($1) and date($1) + interval '1 month'
+	ORDER BY id;
+	`
+	rows, err := q.Query(ctx, query, period)
+	if err != nil {
+		return nil, fmt.Errorf("getting segment report for the month since '%s' failed: %v", period, err)
+	}
+	defer rows.Close()
+
+	var result [][]string
+	for rows.Next() {
+		var e models.SegmentEvent
+		if err = rows.Scan(&e.Segment, &e.Event, &e.OldValue, &e.NewValue, &e.Time, &e.Actor, &e.Source, &e.Reason); err != nil {
+			return result, err
+		}
+		result = append(result, []string{
+			e.Segment, e.Event, e.OldValue, e.NewValue, models.FormatReportTime(e.Time, db.reportLoc), e.Actor, e.Source, e.Reason,
+		})
+	}
+	return result, rows.Err()
+}
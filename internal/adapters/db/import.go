@@ -0,0 +1,250 @@
+package db
+
+import (
+	"context"
+	"segmentation-service/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+// ApplyMemberships applies a batch of membership changes in one transaction and returns the number of memberships
+// that actually changed and the number of additions left out because their segments reached the cap. Adding
+// an existing member or removing a non-member is a no-op and isn't written to the report. Removing a user from
+// a percentage rollout excludes them from it, which is a change only if inRollout puts them into the rollout,
+// adding them includes them explicitly. The additions to a capped
+// segment are applied in the order of the batch until it is full. All the segments are expected to exist:
+// changes of unknown segments are skipped.
+func (db *DBStorage) ApplyMemberships(ctx context.Context, changes []models.MembershipChange, inRollout models.RolloutChecker) (applied, capped int, err error) {
+	// start a transaction
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+	q := withSpans(tx, "ApplyMemberships")
+
+	if applied, capped, err = applyMemberships(ctx, q, changes, inRollout); err != nil {
+		return 0, 0, err
+	}
+	return applied, capped, tx.Commit(ctx)
+}
+
+// DiffMemberships applies the changes like ApplyMemberships in a transaction that is always rolled back and returns
+// the diff of the memberships of their users. The unknown segments are left to the caller.
+func (db *DBStorage) DiffMemberships(ctx context.Context, changes []models.MembershipChange, inRollout models.RolloutChecker) (diff models.ImportDiff, err error) {
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return diff, err
+	}
+	defer tx.Rollback(ctx)
+	q := withSpans(tx, "DiffMemberships")
+
+	users := make([]uuid.UUID, 0, len(changes))
+	requested := make([]models.Membership, 0, len(changes))
+	for _, c := range changes {
+		users = append(users, c.UserID)
+		requested = append(requested, models.Membership{UserID: c.UserID, Segment: c.Segment})
+	}
+	before, err := memberships(ctx, q, users)
+	if err != nil {
+		return diff, err
+	}
+	if _, _, err = applyMemberships(ctx, q, changes, inRollout); err != nil {
+		return diff, err
+	}
+	after, err := memberships(ctx, q, users)
+	if err != nil {
+		return diff, err
+	}
+	diff.Added, diff.Removed, diff.NoOp = diffMemberships(before, after, requested)
+	diff.Unknown = []string{}
+	return diff, nil
+}
+
+// applyMemberships writes the changes in the transaction of q, see ApplyMemberships.
+func applyMemberships(ctx context.Context, q querier, changes []models.MembershipChange, inRollout models.RolloutChecker) (applied, capped int, err error) {
+	var addUsers, removeUsers []uuid.UUID
+	var addSegments, removeSegments []string
+	users := make([]uuid.UUID, 0, len(changes))
+	for _, c := range changes {
+		users = append(users, c.UserID)
+		switch c.Action {
+		case models.ActAdd:
+			addUsers = append(addUsers, c.UserID)
+			addSegments = append(addSegments, c.Segment)
+		case models.ActRemove:
+			removeUsers = append(removeUsers, c.UserID)
+			removeSegments = append(removeSegments, c.Segment)
+		}
+	}
+
+	rolledIn, err := usersInRollouts(ctx, q, removeUsers, removeSegments, inRollout)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// remove memberships and exclude the users from percentage rollouts, and write the report rows only for
+	// the actual changes; excluding a user from a rollout they don't fall into ($7) isn't one
+	const queryRemove = `
+	WITH input AS (
+		SELECT DISTINCT segments.id AS segments_id, segments.rollout IS NOT NULL AS rollout, input.user_id, input.rolled_in
+		FROM unnest($1::uuid[], $2::text[], $7::bool[]) AS input(user_id, name, rolled_in)
+		INNER JOIN segments ON segments.name = input.name
+	), deleted AS (
+		DELETE FROM segments_users USING input
+		WHERE segments_users.segments_id = input.segments_id AND segments_users.user_id = input.user_id
+		RETURNING segments_users.segments_id, segments_users.user_id
+	), excluded AS (
+		INSERT INTO rollout_exclusions (segments_id, user_id) SELECT segments_id, user_id FROM input WHERE rollout
+		ON CONFLICT DO NOTHING
+		RETURNING segments_id, user_id
+	), changed AS (
+		SELECT segments_id, user_id FROM deleted
+		UNION SELECT segments_id, user_id FROM excluded INNER JOIN input USING (segments_id, user_id) WHERE input.rolled_in
+	), reported AS (
+		INSERT INTO report (user_id, segments_id, action, actor, source, reason)
+		SELECT user_id, segments_id, $3, $4::text, $5::text, $6::text FROM changed
+		RETURNING 1
+	)
+	SELECT (SELECT COUNT(*) FROM reported), ARRAY(SELECT DISTINCT segments_id FROM deleted);
+	`
+	by := models.AttributionFrom(ctx)
+	var removed int
+	var deleted []int32
+	err = q.QueryRow(ctx, queryRemove, removeUsers, removeSegments, models.ActRemove, by.Actor, by.Source, by.Reason, rolledIn).
+		Scan(&removed, &deleted)
+	if err != nil {
+		return 0, 0, err
+	}
+	applied += removed
+
+	// the capped segments are counted after the removals, under their locks, see lockCapacity
+	var caps map[string]capacity
+	if len(addUsers) > 0 {
+		if err = lockUsers(ctx, q, addUsers); err != nil {
+			return 0, 0, err
+		}
+		if caps, err = lockCapacity(ctx, q, addSegments); err != nil {
+			return 0, 0, err
+		}
+	}
+	var capIDs, rooms []int32
+	for _, c := range caps {
+		capIDs, rooms = append(capIDs, c.id), append(rooms, int32(c.room()))
+	}
+
+	// add memberships up to the room left in the capped segments, in the order of the batch, and write the report
+	// rows only for the inserted ones
+	const queryAdd = `
+	WITH input AS (
+		SELECT DISTINCT ON (segments.id, input.user_id) segments.id AS segments_id, input.user_id, input.n
+		FROM unnest($1::uuid[], $2::text[]) WITH ORDINALITY AS input(user_id, name, n)
+		INNER JOIN segments ON segments.name = input.name
+		ORDER BY segments.id, input.user_id, input.n
+	), included AS (
+		DELETE FROM rollout_exclusions USING input
+		WHERE rollout_exclusions.segments_id = input.segments_id AND rollout_exclusions.user_id = input.user_id
+	), fresh AS (
+		SELECT segments_id, user_id, ROW_NUMBER() OVER (PARTITION BY segments_id ORDER BY n) AS n FROM input
+		WHERE NOT EXISTS (
+			SELECT 1 FROM segments_users
+			WHERE segments_users.segments_id = input.segments_id AND segments_users.user_id = input.user_id
+		)
+	), rooms AS (
+		SELECT * FROM unnest($7::int[], $8::int[]) AS rooms(segments_id, room)
+	), inserted AS (
+		INSERT INTO segments_users (segments_id, user_id)
+		SELECT fresh.segments_id, fresh.user_id FROM fresh LEFT JOIN rooms ON rooms.segments_id = fresh.segments_id
+		WHERE rooms.room IS NULL OR fresh.n <= rooms.room
+		ON CONFLICT DO NOTHING
+		RETURNING segments_id, user_id
+	), reported AS (
+		INSERT INTO report (user_id, segments_id, action, actor, source, reason)
+		SELECT user_id, segments_id, $3, $4::text, $5::text, $6::text FROM inserted
+		RETURNING 1
+	), capped AS (
+		SELECT fresh.segments_id, fresh.user_id FROM fresh INNER JOIN rooms ON rooms.segments_id = fresh.segments_id
+		WHERE fresh.n > rooms.room
+	), kept AS (
+		SELECT input.user_id::text AS user_id, segments.name FROM input
+		INNER JOIN segments ON segments.id = input.segments_id
+		WHERE NOT EXISTS (
+			SELECT 1 FROM capped WHERE capped.segments_id = input.segments_id AND capped.user_id = input.user_id
+		)
+	)
+	SELECT (SELECT COUNT(*) FROM reported), (SELECT COUNT(*) FROM capped),
+		ARRAY(SELECT user_id FROM kept ORDER BY user_id, name), ARRAY(SELECT name FROM kept ORDER BY user_id, name),
+		ARRAY(SELECT DISTINCT segments_id FROM inserted);
+	`
+	var added int
+	var keptUsers, keptSegments []string
+	var inserted []int32
+	err = q.QueryRow(ctx, queryAdd, addUsers, addSegments, models.ActAdd, by.Actor, by.Source, by.Reason, capIDs, rooms).
+		Scan(&added, &capped, &keptUsers, &keptSegments, &inserted)
+	if err != nil {
+		return 0, 0, err
+	}
+	applied += added
+
+	// keep the variants of the experiment groups exclusive, for the additions that weren't capped
+	members := make([]uuid.UUID, len(keptUsers))
+	for i, id := range keptUsers {
+		if members[i], err = uuid.Parse(id); err != nil {
+			return 0, 0, err
+		}
+	}
+	moved, err := enforceExperiments(ctx, q, members, keptSegments)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// recompute the composite segments of the users of the batch over the changed ones; the list is never nil,
+	// which would recompute all of them
+	changed := make([]int32, 0, len(deleted)+len(inserted)+len(moved))
+	changed = append(append(append(changed, deleted...), inserted...), moved...)
+	if _, err = syncComposites(ctx, q, users, changed); err != nil {
+		return 0, 0, err
+	}
+	if err = recordFull(ctx, q, caps); err != nil {
+		return 0, 0, err
+	}
+	return applied, capped, nil
+}
+
+// usersInRollouts reports for every user and segment of the lists whether inRollout puts the user into the segment's
+// percentage rollout. It is false for the segments that aren't rollouts.
+func usersInRollouts(ctx context.Context, q querier, users []uuid.UUID, slugs []string, inRollout models.RolloutChecker) ([]bool, error) {
+	result := make([]bool, len(users))
+	if len(users) == 0 {
+		return result, nil
+	}
+	const query = `
+	SELECT name, rollout, salt FROM segments WHERE name = ANY($1::text[]) AND rollout IS NOT NULL;
+	`
+	rows, err := q.Query(ctx, query, slugs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	rollouts := make(map[string]models.Segment)
+	for rows.Next() {
+		var segment models.Segment
+		if err = rows.Scan(&segment.Slug, &segment.Rollout, &segment.Salt); err != nil {
+			return nil, err
+		}
+		rollouts[segment.Slug] = segment
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	for i, slug := range slugs {
+		if segment, ok := rollouts[slug]; ok {
+			result[i] = inRollout(segment.Salt, users[i], *segment.Rollout)
+		}
+	}
+	return result, nil
+}
@@ -5,7 +5,15 @@ ALTER DATABASE segmentation SET timezone TO 'Europe/Moscow';
 
 CREATE TABLE segments (
     id SERIAL NOT NULL PRIMARY KEY,
-    name TEXT NOT NULL
+    name TEXT NOT NULL,
+    rule TEXT, -- members of the segments with a rule are computed from user_attributes
+    expression TEXT, -- members of the composite segments are computed from other segments
+    rollout DOUBLE PRECISION CHECK (rollout BETWEEN 0 AND 100), -- percentage of users in a rollout, computed on read from the hash of salt and user ID
+    salt TEXT, -- salt of the rollout hash, kept when the percentage changes so that the users stay in the rollout
+    active_from TIMESTAMP WITH TIME ZONE, -- outside the activation window the segment isn't returned to its members
+    active_until TIMESTAMP WITH TIME ZONE CHECK (active_until > active_from),
+    window_state TEXT NOT NULL DEFAULT 'active', -- the state last written to segment_events by the scheduler
+    max_members INTEGER CHECK (max_members > 0) -- additions beyond the cap are rejected, no cap if null
 );
 
 CREATE TABLE segments_users (
@@ -14,10 +22,169 @@ CREATE TABLE segments_users (
     PRIMARY KEY (segments_id, user_id)
 );
 
+-- numbers of the members of the segments, maintained by the triggers of segments_users: every statement appends
+-- the change of the number of members per segment instead of updating a shared row, so that concurrent writers
+-- don't wait for each other, and the scheduler folds the rows of every segment into one;
+-- there is no foreign key, the rows of the deleted segments are dropped by the folding
+CREATE TABLE segment_sizes (
+    segments_id INTEGER NOT NULL,
+    members BIGINT NOT NULL
+);
+
+CREATE INDEX segment_sizes_segments_id_idx ON segment_sizes (segments_id);
+
+CREATE FUNCTION count_segment_members() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO segment_sizes (segments_id, members) SELECT segments_id, COUNT(*) FROM added GROUP BY segments_id;
+    ELSE
+        INSERT INTO segment_sizes (segments_id, members) SELECT segments_id, -COUNT(*) FROM removed GROUP BY segments_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER segments_users_added AFTER INSERT ON segments_users
+    REFERENCING NEW TABLE AS added FOR EACH STATEMENT EXECUTE FUNCTION count_segment_members();
+CREATE TRIGGER segments_users_removed AFTER DELETE ON segments_users
+    REFERENCING OLD TABLE AS removed FOR EACH STATEMENT EXECUTE FUNCTION count_segment_members();
+
+-- users explicitly removed from a percentage rollout; the explicitly added ones are stored in segments_users
+CREATE TABLE rollout_exclusions (
+    segments_id INTEGER NOT NULL REFERENCES segments (id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    PRIMARY KEY (segments_id, user_id)
+);
+
+-- old slugs of renamed segments: resolved to the segment until expires_at and reserved while the segment exists
+CREATE TABLE segment_aliases (
+    name TEXT NOT NULL PRIMARY KEY,
+    segments_id INTEGER NOT NULL REFERENCES segments (id) ON DELETE CASCADE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- the report is partitioned by month, so that the report queries read the partitions of their months only; the partitions
+-- are created ahead of time by create_report_partitions, which the scheduler calls, and the rows of the months without
+-- a partition go to report_default until their partition is created
 CREATE TABLE report (
-    id SERIAL NOT NULL PRIMARY KEY,
+    id SERIAL NOT NULL,
     user_id UUID NOT NULL,
-    segments_id SERIAL NOT NULL,
+    segments_id INTEGER NOT NULL,
     action VARCHAR(6),
-    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    actor TEXT NOT NULL DEFAULT '', -- the authenticated caller or the X-Actor header of the request that made the change
+    source TEXT NOT NULL DEFAULT 'api', -- api, import, ttl-reaper, auto-percent or rule
+    reason TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (id, created_at)
+) PARTITION BY RANGE (created_at);
+
+CREATE TABLE report_default PARTITION OF report DEFAULT;
+
+-- getReport reads a month, getUserReport the rows of a user in a month
+CREATE INDEX report_created_at_idx ON report (created_at);
+CREATE INDEX report_user_id_created_at_idx ON report (user_id, created_at);
+
+-- creates the missing partitions of report for the months from the first to the last one and returns their number;
+-- the rows of these months in report_default are moved to their partitions
+CREATE FUNCTION create_report_partitions(first_month DATE, last_month DATE) RETURNS INTEGER AS $$
+DECLARE
+    first_day DATE := date_trunc('month', first_month)::date;
+    next_day DATE;
+    partition_name TEXT;
+    created INTEGER := 0;
+BEGIN
+    -- the instances of the service create the partitions one at a time
+    PERFORM pg_advisory_xact_lock(1380995668);
+    WHILE first_day <= last_month LOOP
+        next_day := (first_day + interval '1 month')::date;
+        partition_name := 'report_' || to_char(first_day, 'YYYY_MM');
+        IF to_regclass(partition_name) IS NULL THEN
+            EXECUTE format('CREATE TABLE %I (LIKE report INCLUDING DEFAULTS)', partition_name);
+            EXECUTE format('WITH moved AS (DELETE FROM report_default WHERE created_at >= %L AND created_at < %L RETURNING *) '
+                'INSERT INTO %I SELECT * FROM moved', first_day, next_day, partition_name);
+            EXECUTE format('ALTER TABLE report ATTACH PARTITION %I FOR VALUES FROM (%L) TO (%L)', partition_name, first_day, next_day);
+            created := created + 1;
+        END IF;
+        first_day := next_day;
+    END LOOP;
+    RETURN created;
+END;
+$$ LANGUAGE plpgsql;
+
+SELECT create_report_partitions(date_trunc('month', NOW())::date, (date_trunc('month', NOW()) + interval '3 months')::date);
+
+-- daily numbers of the report rows per segment and action, maintained by the trigger of report: every statement
+-- appends the numbers of its rows instead of updating shared rows, and the scheduler folds the rows of every day
+-- into one; the days are dates in the time zone of the database, as the report shows them
+CREATE TABLE report_rollups (
+    segments_id INTEGER NOT NULL,
+    day DATE NOT NULL,
+    action VARCHAR(6) NOT NULL,
+    count BIGINT NOT NULL
+);
+
+CREATE INDEX report_rollups_segments_id_day_idx ON report_rollups (segments_id, day);
+CREATE INDEX report_rollups_day_idx ON report_rollups (day);
+
+CREATE FUNCTION roll_up_report() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO report_rollups (segments_id, day, action, count)
+    SELECT segments_id, created_at::date, action, COUNT(*) FROM added GROUP BY segments_id, created_at::date, action;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER report_added AFTER INSERT ON report
+    REFERENCING NEW TABLE AS added FOR EACH STATEMENT EXECUTE FUNCTION roll_up_report();
+
+-- log of the archival of the old months of the report: the rows of a month are written to its file and deleted
+-- in one transaction, so a month is either archived or still in report; the failed months are retried;
+-- the rollups of the archived months are kept
+CREATE TABLE report_archive_runs (
+    month DATE NOT NULL PRIMARY KEY, -- the first day of the month
+    state TEXT NOT NULL, -- running, failed or archived
+    file TEXT NOT NULL DEFAULT '',
+    rows BIGINT NOT NULL DEFAULT 0,
+    checksum TEXT NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP WITH TIME ZONE
+);
+
+-- audit log of the segments: the lifecycle operations and the activations and deactivations by their windows;
+-- there is no foreign key, so the log outlives the deleted segments
+CREATE TABLE segment_events (
+    id SERIAL NOT NULL PRIMARY KEY,
+    segments_id INTEGER NOT NULL,
+    slug TEXT NOT NULL, -- the slug of the segment at the time of the event
+    event TEXT NOT NULL,
+    old_value TEXT NOT NULL DEFAULT '',
+    new_value TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    actor TEXT NOT NULL DEFAULT '',
+    source TEXT NOT NULL DEFAULT 'api',
+    reason TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX segment_events_segments_id_idx ON segment_events (segments_id);
+
+CREATE TABLE user_attributes (
+    user_id UUID NOT NULL PRIMARY KEY,
+    attributes JSONB NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE experiment_groups (
+    id SERIAL NOT NULL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    salt TEXT NOT NULL,
+    conflict TEXT NOT NULL DEFAULT 'reject' -- adding a user to a variant fails ('reject') or removes them from the siblings ('move')
+);
+
+CREATE TABLE experiment_variants (
+    group_id INTEGER NOT NULL REFERENCES experiment_groups (id) ON DELETE CASCADE,
+    segments_id INTEGER NOT NULL UNIQUE REFERENCES segments (id) ON DELETE CASCADE, -- a segment is a variant of one group at most
+    weight INTEGER NOT NULL CHECK (weight > 0),
+    position INTEGER NOT NULL, -- the order of the variants, which the assignment depends on
+    PRIMARY KEY (group_id, segments_id)
 );
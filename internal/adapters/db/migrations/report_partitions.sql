@@ -0,0 +1,69 @@
+-- Moves the report of a database created before the report was partitioned to the monthly partitions of init.sql.
+-- Run it once with psql while the service is stopped: the rows are copied in one transaction, which keeps the table
+-- locked until it ends. The IDs of the rows and the rollups are kept.
+BEGIN;
+
+ALTER TABLE report RENAME TO report_unpartitioned;
+ALTER INDEX report_pkey RENAME TO report_unpartitioned_pkey;
+DROP TRIGGER report_added ON report_unpartitioned;
+
+-- the IDs continue the sequence of the old table
+CREATE TABLE report (
+    id INTEGER NOT NULL DEFAULT nextval('report_id_seq'),
+    user_id UUID NOT NULL,
+    segments_id INTEGER NOT NULL,
+    action VARCHAR(6),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    actor TEXT NOT NULL DEFAULT '',
+    source TEXT NOT NULL DEFAULT 'api',
+    reason TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (id, created_at)
+) PARTITION BY RANGE (created_at);
+ALTER SEQUENCE report_id_seq OWNED BY report.id;
+
+CREATE TABLE report_default PARTITION OF report DEFAULT;
+
+CREATE INDEX report_created_at_idx ON report (created_at);
+CREATE INDEX report_user_id_created_at_idx ON report (user_id, created_at);
+
+CREATE FUNCTION create_report_partitions(first_month DATE, last_month DATE) RETURNS INTEGER AS $$
+DECLARE
+    first_day DATE := date_trunc('month', first_month)::date;
+    next_day DATE;
+    partition_name TEXT;
+    created INTEGER := 0;
+BEGIN
+    -- the instances of the service create the partitions one at a time
+    PERFORM pg_advisory_xact_lock(1380995668);
+    WHILE first_day <= last_month LOOP
+        next_day := (first_day + interval '1 month')::date;
+        partition_name := 'report_' || to_char(first_day, 'YYYY_MM');
+        IF to_regclass(partition_name) IS NULL THEN
+            EXECUTE format('CREATE TABLE %I (LIKE report INCLUDING DEFAULTS)', partition_name);
+            EXECUTE format('WITH moved AS (DELETE FROM report_default WHERE created_at >= %L AND created_at < %L RETURNING *) '
+                'INSERT INTO %I SELECT * FROM moved', first_day, next_day, partition_name);
+            EXECUTE format('ALTER TABLE report ATTACH PARTITION %I FOR VALUES FROM (%L) TO (%L)', partition_name, first_day, next_day);
+            created := created + 1;
+        END IF;
+        first_day := next_day;
+    END LOOP;
+    RETURN created;
+END;
+$$ LANGUAGE plpgsql;
+
+-- a partition for every month of the history and the next ones, then the rows are copied into them
+SELECT create_report_partitions(COALESCE(MIN(created_at), NOW())::date, (date_trunc('month', NOW()) + interval '3 months')::date)
+FROM report_unpartitioned;
+
+INSERT INTO report (id, user_id, segments_id, action, created_at, actor, source, reason)
+SELECT id, user_id, segments_id, action, COALESCE(created_at, NOW()), actor, source, reason FROM report_unpartitioned;
+
+-- the trigger is created after the copy, so that the copied rows don't roll up again
+CREATE TRIGGER report_added AFTER INSERT ON report
+    REFERENCING NEW TABLE AS added FOR EACH STATEMENT EXECUTE FUNCTION roll_up_report();
+
+DROP TABLE report_unpartitioned;
+
+ANALYZE report;
+
+COMMIT;
@@ -0,0 +1,16 @@
+package db
+
+import "context"
+
+// CreateReportPartitions creates the missing monthly partitions of the report for the current month and the given
+// number of the next ones, so that the rows are never written to the default partition, and returns the number
+// of the created partitions.
+func (db *DBStorage) CreateReportPartitions(ctx context.Context, months int) (int, error) {
+	q := withSpans(db.Pool, "CreateReportPartitions")
+	const query = `
+	SELECT create_report_partitions(date_trunc('month', NOW())::date, (date_trunc('month', NOW()) + make_interval(months => $1))::date);
+	`
+	var created int
+	err := q.QueryRow(ctx, query, months).Scan(&created)
+	return created, err
+}
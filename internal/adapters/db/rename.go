@@ -0,0 +1,132 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"segmentation-service/internal/domain/models"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// RenameSegment changes the slug of the segment, keeping its ID, so the memberships, exclusions, experiment variants
+// and report rows stay with it. The expressions of composite segments are rewritten to the new slug. The old slug
+// becomes an alias that resolves to the segment for aliasTTL and stays reserved while the segment exists. Renaming
+// a segment back to one of its own aliases frees that alias.
+func (db *DBStorage) RenameSegment(ctx context.Context, slug, newSlug string, aliasTTL time.Duration) (alias models.SegmentAlias, err error) {
+	alias = models.SegmentAlias{Alias: slug, Slug: newSlug}
+
+	// start a transaction
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return alias, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+	q := withSpans(tx, "RenameSegment")
+
+	// the expressions are rewritten below, so no expression may change until the transaction ends
+	if _, err = q.Exec(ctx, `SELECT pg_advisory_xact_lock($1);`, compositesLock); err != nil {
+		return alias, err
+	}
+
+	const querySegment = `
+	SELECT id FROM segments WHERE name = $1 FOR UPDATE;
+	`
+	var segmentID int32
+	if err = q.QueryRow(ctx, querySegment, slug).Scan(&segmentID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = models.ErrSegmentNotFound
+		}
+		return alias, err
+	}
+
+	// the new slug must be free: neither a segment nor an alias of another segment
+	const queryTaken = `
+	SELECT EXISTS (SELECT 1 FROM segments WHERE name = $1),
+		EXISTS (SELECT 1 FROM segment_aliases WHERE name = $1 AND segments_id <> $2);
+	`
+	var exists, reserved bool
+	if err = q.QueryRow(ctx, queryTaken, newSlug, segmentID).Scan(&exists, &reserved); err != nil {
+		return alias, err
+	}
+	if exists {
+		return alias, models.ErrSegmentAlreadyExists
+	}
+	if reserved {
+		return alias, models.ErrSlugReserved
+	}
+
+	const queryReclaim = `
+	DELETE FROM segment_aliases WHERE name = $1;
+	`
+	if _, err = q.Exec(ctx, queryReclaim, newSlug); err != nil {
+		return alias, err
+	}
+	const queryUpdate = `
+	UPDATE segments SET name = $2 WHERE id = $1;
+	`
+	if _, err = q.Exec(ctx, queryUpdate, segmentID, newSlug); err != nil {
+		return alias, err
+	}
+	const queryAlias = `
+	INSERT INTO segment_aliases (name, segments_id, expires_at) VALUES ($1, $2, NOW() + make_interval(secs => $3::float8))
+	RETURNING expires_at;
+	`
+	if err = q.QueryRow(ctx, queryAlias, slug, segmentID, aliasTTL.Seconds()).Scan(&alias.ExpiresAt); err != nil {
+		return alias, err
+	}
+	if err = recordEvent(ctx, q, newSlug, models.EventRenamed, slug, newSlug); err != nil {
+		return alias, err
+	}
+
+	// the composite segments refer to other segments by slug
+	composites, err := loadComposites(ctx, q)
+	if err != nil {
+		return alias, err
+	}
+	const queryExpression = `
+	UPDATE segments SET expression = $2 WHERE id = $1;
+	`
+	for _, c := range composites {
+		previous := c.expr.String()
+		if !c.expr.Rename(map[string]string{slug: newSlug}) {
+			continue
+		}
+		if _, err = q.Exec(ctx, queryExpression, c.id, c.expr.String()); err != nil {
+			return alias, err
+		}
+		if err = recordEvent(ctx, q, c.slug, models.EventExpressionChanged, previous, c.expr.String()); err != nil {
+			return alias, err
+		}
+	}
+	return alias, tx.Commit(ctx)
+}
+
+// ResolveSegmentAliases returns the aliases among the given slugs that haven't expired yet, keyed by the old slug.
+func (db *DBStorage) ResolveSegmentAliases(ctx context.Context, slugs []string) (map[string]models.SegmentAlias, error) {
+	q := withSpans(db.Pool, "ResolveSegmentAliases")
+	const query = `
+	SELECT segment_aliases.name, segments.name, segment_aliases.expires_at FROM segment_aliases
+	INNER JOIN segments ON segments.id = segment_aliases.segments_id
+	WHERE segment_aliases.name = ANY($1::text[]) AND segment_aliases.expires_at > NOW();
+	`
+	rows, err := q.Query(ctx, query, slugs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	aliases := make(map[string]models.SegmentAlias)
+	for rows.Next() {
+		var alias models.SegmentAlias
+		if err = rows.Scan(&alias.Alias, &alias.Slug, &alias.ExpiresAt); err != nil {
+			return nil, err
+		}
+		aliases[alias.Alias] = alias
+	}
+	return aliases, rows.Err()
+}
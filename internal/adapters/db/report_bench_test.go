@@ -0,0 +1,103 @@
+package db
+
+import (
+	"context"
+	"crypto/md5"
+	"fmt"
+	"segmentation-service/internal/domain/models"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// benchReportRows rows of the report are spread evenly over the 12 months of 2023, among 50 segments
+// and 10000 users whose IDs are the md5 sums of their numbers.
+const benchReportRows = 1_200_000
+
+// benchReportSeed fills the segments and the report of the schema.
+var benchReportSeed = fmt.Sprintf(`
+CREATE TABLE segments (id SERIAL NOT NULL PRIMARY KEY, name TEXT NOT NULL UNIQUE);
+INSERT INTO segments (name) SELECT 'BENCH_' || i FROM generate_series(1, 50) AS i;
+INSERT INTO report (user_id, segments_id, action, created_at, actor, source, reason)
+SELECT md5((n %% 10000)::text)::uuid, 1 + n %% 50, CASE WHEN n %% 3 = 0 THEN 'remove' ELSE 'add' END,
+	timestamptz '2023-01-01' + n * (interval '365 days' / %[1]d), '', 'api', ''
+FROM generate_series(0, %[1]d - 1) AS n;
+ANALYZE segments;
+ANALYZE report;
+`, benchReportRows)
+
+// benchReportColumns are the columns of the report table.
+const benchReportColumns = `
+	user_id UUID NOT NULL,
+	segments_id INTEGER NOT NULL,
+	action VARCHAR(6),
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+	actor TEXT NOT NULL DEFAULT '',
+	source TEXT NOT NULL DEFAULT 'api',
+	reason TEXT NOT NULL DEFAULT ''`
+
+// benchHeapReport is the report before the partitioning: a single table without an index on created_at.
+var benchHeapReport = `
+CREATE TABLE report (id SERIAL NOT NULL PRIMARY KEY,` + benchReportColumns + `);
+` + benchReportSeed
+
+// benchPartitionedReport is the report of init.sql with the partitions of the seeded months.
+var benchPartitionedReport = func() string {
+	var ddl strings.Builder
+	ddl.WriteString(`
+	CREATE TABLE report (id SERIAL NOT NULL,` + benchReportColumns + `, PRIMARY KEY (id, created_at)) PARTITION BY RANGE (created_at);
+	CREATE TABLE report_default PARTITION OF report DEFAULT;
+	CREATE INDEX report_created_at_idx ON report (created_at);
+	CREATE INDEX report_user_id_created_at_idx ON report (user_id, created_at);
+	`)
+	for month := 1; month <= 12; month++ {
+		next := fmt.Sprintf("2023-%02d-01", month+1)
+		if month == 12 {
+			next = "2024-01-01"
+		}
+		fmt.Fprintf(&ddl, "CREATE TABLE report_2023_%02[1]d PARTITION OF report FOR VALUES FROM ('2023-%02[1]d-01') TO ('%[2]s');\n", month, next)
+	}
+	ddl.WriteString(benchReportSeed)
+	return ddl.String()
+}()
+
+// BenchmarkReport compares the report queries of a month on the single table the report was and on the partitioned one.
+func BenchmarkReport(b *testing.B) {
+	ctx := context.Background()
+	userID := uuid.MustParse(fmt.Sprintf("%x", md5.Sum([]byte("42"))))
+
+	for _, layout := range []struct {
+		schema string
+		ddl    string
+	}{
+		{schema: "bench_report_heap", ddl: benchHeapReport},
+		{schema: "bench_report_partitioned", ddl: benchPartitionedReport},
+	} {
+		b.Run(layout.schema, func(b *testing.B) {
+			storage := benchStorage(b, layout.schema, layout.ddl)
+
+			b.Run("GetReport", func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if _, err := storage.GetReport(ctx, "2023-06-01", models.ReportFilter{}); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+			b.Run("GetReportFiltered", func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if _, err := storage.GetReport(ctx, "2023-06-01", models.ReportFilter{Actor: "nobody"}); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+			b.Run("GetUserReport", func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if _, err := storage.GetUserReport(ctx, "2023-06-01", userID, models.ReportFilter{}); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		})
+	}
+}
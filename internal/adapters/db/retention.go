@@ -0,0 +1,219 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"segmentation-service/internal/domain/models"
+	"segmentation-service/internal/ports"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// reportArchiveLock is the key of the advisory lock taken by the archival of a month, so that the instances
+// of the service archive the months one at a time.
+const reportArchiveLock int64 = 0x52455054
+
+// reportMonth is the condition selecting the report rows of the month since $1 (in the format: yyyy-mm-dd).
+const reportMonth = `report.created_at >= date($1) AND report.created_at < date($1) + interval '1 month'`
+
+// archiveRunColumns are the columns of report_archive_runs in the order of scanArchiveRun.
+const archiveRunColumns = `to_char(month, 'YYYY-MM'), state, file, rows, checksum, error, started_at, finished_at`
+
+// GetSegmentNames returns the names of all segments by their IDs.
+func (db *DBStorage) GetSegmentNames(ctx context.Context) (map[int32]string, error) {
+	return segmentNames(ctx, withSpans(db.Pool, "GetSegmentNames"))
+}
+
+// segmentNames reads the names of the segments in the transaction of q, see GetSegmentNames.
+func segmentNames(ctx context.Context, q querier) (map[int32]string, error) {
+	const query = `
+	SELECT id, name FROM segments;
+	`
+	rows, err := q.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	names := make(map[int32]string)
+	for rows.Next() {
+		var id int32
+		var name string
+		if err = rows.Scan(&id, &name); err != nil {
+			return nil, err
+		}
+		names[id] = name
+	}
+	return names, rows.Err()
+}
+
+// GetArchivableMonths returns the months (in the format: yyyy-mm) having report rows created before the day
+// (in the format: yyyy-mm-dd), the oldest first.
+func (db *DBStorage) GetArchivableMonths(ctx context.Context, before string) ([]string, error) {
+	q := withSpans(db.Pool, "GetArchivableMonths")
+	const query = `
+	SELECT DISTINCT to_char(created_at, 'YYYY-MM') FROM report WHERE created_at < date($1) ORDER BY 1;
+	`
+	rows, err := q.Query(ctx, query, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var months []string
+	for rows.Next() {
+		var month string
+		if err = rows.Scan(&month); err != nil {
+			return nil, err
+		}
+		months = append(months, month)
+	}
+	return months, rows.Err()
+}
+
+// GetReportArchiveRuns returns the log of the archival of the months of the report, the oldest month first.
+func (db *DBStorage) GetReportArchiveRuns(ctx context.Context) ([]models.ReportArchiveRun, error) {
+	return reportArchiveRuns(ctx, withSpans(db.Pool, "GetReportArchiveRuns"))
+}
+
+// reportArchiveRuns reads the log in the transaction of q, see GetReportArchiveRuns.
+func reportArchiveRuns(ctx context.Context, q querier) ([]models.ReportArchiveRun, error) {
+	query := `
+	SELECT ` + archiveRunColumns + ` FROM report_archive_runs ORDER BY month;
+	`
+	rows, err := q.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var runs []models.ReportArchiveRun
+	for rows.Next() {
+		run, err := scanArchiveRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+// SaveReportArchiveRun writes the state and the error of the archival of the month to the log. A running archival
+// starts anew, the other states finish it. The log entry of an archived month is left as it is.
+func (db *DBStorage) SaveReportArchiveRun(ctx context.Context, run models.ReportArchiveRun) error {
+	q := withSpans(db.Pool, "SaveReportArchiveRun")
+	const query = `
+	INSERT INTO report_archive_runs (month, state, error, finished_at)
+	VALUES (date($1), $2::text, $3::text, CASE WHEN $2::text = $4::text THEN NULL ELSE NOW() END)
+	ON CONFLICT (month) DO UPDATE SET state = EXCLUDED.state, error = EXCLUDED.error,
+		started_at = CASE WHEN EXCLUDED.state = $4::text THEN NOW() ELSE report_archive_runs.started_at END,
+		finished_at = EXCLUDED.finished_at
+	WHERE report_archive_runs.state <> $5::text;
+	`
+	_, err := q.Exec(ctx, query, run.Month+"-01", run.State, run.Error, models.ArchiveRunning, models.ArchiveArchived)
+	return err
+}
+
+// ArchiveReportMonth writes the report rows of the month (in the format: yyyy-mm) to w in the order of their creation,
+// commits the file, deletes the rows and marks the month archived, all in one transaction: if any step fails,
+// the rows stay in the database and the month isn't archived. The rows of the segments deleted since are kept
+// in the file too. A month archived meanwhile by another instance is returned as it is, and models.ErrArchiveBusy
+// is returned while another instance is archiving.
+func (db *DBStorage) ArchiveReportMonth(ctx context.Context, month string, w ports.ReportArchiveWriter) (run models.ReportArchiveRun, err error) {
+	// start a transaction, the rows are read and deleted from the same snapshot, so the rows written to the month
+	// concurrently are neither exported nor deleted
+	tx, err := db.Pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead})
+	if err != nil {
+		return run, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+	q := withSpans(tx, "ArchiveReportMonth")
+	monthBeginning := month + "-01"
+
+	var locked bool
+	if err = q.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock($1);`, reportArchiveLock).Scan(&locked); err != nil {
+		return run, err
+	}
+	if !locked {
+		return run, models.ErrArchiveBusy
+	}
+
+	queryRun := `
+	SELECT ` + archiveRunColumns + ` FROM report_archive_runs WHERE month = date($1) AND state = $2::text;
+	`
+	run, err = scanArchiveRun(q.QueryRow(ctx, queryRun, monthBeginning, models.ArchiveArchived))
+	if err == nil {
+		return run, tx.Commit(ctx)
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return run, err
+	}
+
+	queryRows := `
+	SELECT report.segments_id, COALESCE(segments.name, ''), report.user_id, report.action, report.created_at,
+		report.actor, report.source, report.reason
+	FROM report
+	LEFT JOIN segments ON segments.id = report.segments_id
+	WHERE ` + reportMonth + `
+	ORDER BY report.id;
+	`
+	rows, err := q.Query(ctx, queryRows, monthBeginning)
+	if err != nil {
+		return run, err
+	}
+	count := 0
+	for rows.Next() {
+		var row models.ArchivedReportRow
+		err = rows.Scan(&row.SegmentID, &row.Segment, &row.UserID, &row.Action, &row.Time, &row.Actor, &row.Source, &row.Reason)
+		if err == nil {
+			err = w.Write(row)
+		}
+		if err != nil {
+			rows.Close()
+			return run, err
+		}
+		count++
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return run, err
+	}
+
+	file, checksum, err := w.Commit()
+	if err != nil {
+		return run, err
+	}
+
+	queryDelete := `
+	DELETE FROM report WHERE ` + reportMonth + `;
+	`
+	tag, err := q.Exec(ctx, queryDelete, monthBeginning)
+	if err != nil {
+		return run, err
+	}
+	if deleted := int(tag.RowsAffected()); deleted != count {
+		err = fmt.Errorf("%d rows of the month deleted instead of %d exported", deleted, count)
+		return run, err
+	}
+
+	queryArchived := `
+	INSERT INTO report_archive_runs (month, state, file, rows, checksum, finished_at)
+	VALUES (date($1), $2::text, $3::text, $4, $5::text, NOW())
+	ON CONFLICT (month) DO UPDATE SET state = EXCLUDED.state, file = EXCLUDED.file, rows = EXCLUDED.rows,
+		checksum = EXCLUDED.checksum, error = '', finished_at = EXCLUDED.finished_at
+	RETURNING ` + archiveRunColumns + `;
+	`
+	run, err = scanArchiveRun(q.QueryRow(ctx, queryArchived, monthBeginning, models.ArchiveArchived, file, count, checksum))
+	if err != nil {
+		return run, err
+	}
+	return run, tx.Commit(ctx)
+}
+
+// scanArchiveRun reads a log entry selected with archiveRunColumns.
+func scanArchiveRun(row pgx.Row) (run models.ReportArchiveRun, err error) {
+	err = row.Scan(&run.Month, &run.State, &run.File, &run.Rows, &run.Checksum, &run.Error, &run.StartedAt, &run.FinishedAt)
+	return run, err
+}
@@ -0,0 +1,109 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"segmentation-service/internal/domain/models"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// SetSegmentRollout saves the percentage of the rollout segment. Its members aren't stored but computed on read,
+// so nothing is written to the report. The salt is set when the segment first becomes a rollout, by default to its
+// slug, and can't be changed afterwards, so that raising the percentage keeps the users in the rollout. A nil
+// percentage turns the segment back into an ordinary one, keeping its explicitly added members and dropping
+// the exclusions. The saved segment is returned.
+func (db *DBStorage) SetSegmentRollout(ctx context.Context, slug string, rollout *float64, salt string) (result models.Segment, err error) {
+	result.Slug = slug
+
+	// start a transaction
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+	q := withSpans(tx, "SetSegmentRollout")
+
+	if result, err = setSegmentRollout(ctx, q, slug, rollout, salt); err != nil {
+		return result, err
+	}
+	return result, tx.Commit(ctx)
+}
+
+// setSegmentRollout saves the percentage in the transaction of q, see SetSegmentRollout.
+func setSegmentRollout(ctx context.Context, q querier, slug string, rollout *float64, salt string) (result models.Segment, err error) {
+	result.Slug = slug
+
+	// the members of a rollout aren't stored, so composite segments can't refer to it
+	if rollout != nil {
+		if err = checkNotReferenced(ctx, q, slug); err != nil {
+			return result, err
+		}
+	}
+
+	const querySegment = `
+	SELECT id, rule IS NOT NULL OR expression IS NOT NULL, max_members IS NOT NULL, COALESCE(salt, ''), rollout
+	FROM segments WHERE name = $1 FOR UPDATE;
+	`
+	var segmentID int32
+	var computed, capped bool
+	var current string
+	var previous *float64
+	if err = q.QueryRow(ctx, querySegment, slug).Scan(&segmentID, &computed, &capped, &current, &previous); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = models.ErrSegmentNotFound
+		}
+		return result, err
+	}
+	if rollout != nil {
+		if computed {
+			return result, fmt.Errorf("%w: the segment has a rule or an expression", models.ErrInvalidRollout)
+		}
+		if capped {
+			return result, fmt.Errorf("%w: the segment has a cap", models.ErrInvalidRollout)
+		}
+		var group string
+		if group, err = experimentOf(ctx, q, slug); err != nil {
+			return result, err
+		}
+		if group != "" {
+			return result, fmt.Errorf("%w '%s'", models.ErrSegmentInExperiment, group)
+		}
+	}
+	switch {
+	case current != "" && salt != "" && salt != current:
+		return result, fmt.Errorf("%w: the salt can't be changed", models.ErrInvalidRollout)
+	case current != "":
+		salt = current
+	case salt == "":
+		salt = slug
+	}
+
+	const queryUpdate = `
+	UPDATE segments SET rollout = $2, salt = $3 WHERE id = $1;
+	`
+	if _, err = q.Exec(ctx, queryUpdate, segmentID, rollout, salt); err != nil {
+		return result, err
+	}
+	if oldValue, newValue := models.RolloutValue(previous), models.RolloutValue(rollout); oldValue != newValue {
+		if err = recordEvent(ctx, q, slug, models.EventRolloutChanged, oldValue, newValue); err != nil {
+			return result, err
+		}
+	}
+	if rollout == nil {
+		const queryExclusions = `
+		DELETE FROM rollout_exclusions WHERE segments_id = $1;
+		`
+		if _, err = q.Exec(ctx, queryExclusions, segmentID); err != nil {
+			return result, err
+		}
+		return result, nil
+	}
+	result.Rollout, result.Salt = rollout, salt
+	return result, nil
+}
@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+	"segmentation-service/internal/domain/models"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+)
+
+func TestRemoveFromRolloutReport(t *testing.T) {
+	ctx := context.Background()
+	storage := benchStorage(t, "test_rollout", benchSchema(t)+`
+	INSERT INTO segments (name, rollout, salt) VALUES ('ROLLOUT', 50, 'salt');
+	`)
+	inside, outside := uuid.New(), uuid.New()
+	importInside, importOutside := uuid.New(), uuid.New()
+	inRollout := func(salt string, userID uuid.UUID, percent float64) bool {
+		return userID == inside || userID == importInside
+	}
+
+	// only the user the rollout put into it is removed from it, both are excluded
+	diff, err := storage.UpdateUserSegments(ctx, models.UpdateRequest{SegmentsToRemove: []string{"ROLLOUT"}}, inside, inRollout)
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusApplied, diff.Results[0].Status)
+	diff, err = storage.UpdateUserSegments(ctx, models.UpdateRequest{SegmentsToRemove: []string{"ROLLOUT"}}, outside, inRollout)
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusNotMember, diff.Results[0].Status)
+
+	applied, _, err := storage.ApplyMemberships(ctx, []models.MembershipChange{
+		{UserID: importInside, Segment: "ROLLOUT", Action: models.ActRemove},
+		{UserID: importOutside, Segment: "ROLLOUT", Action: models.ActRemove},
+	}, inRollout)
+	require.NoError(t, err)
+	assert.Equal(t, 1, applied)
+
+	var reported []uuid.UUID
+	rows, err := storage.Pool.Query(ctx, `SELECT user_id FROM report ORDER BY id;`)
+	require.NoError(t, err)
+	for rows.Next() {
+		var userID uuid.UUID
+		require.NoError(t, rows.Scan(&userID))
+		reported = append(reported, userID)
+	}
+	require.NoError(t, rows.Err())
+	assert.DeepEqual(t, []uuid.UUID{inside, importInside}, reported)
+
+	var excluded int
+	require.NoError(t, storage.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM rollout_exclusions;`).Scan(&excluded))
+	assert.Equal(t, 4, excluded)
+}
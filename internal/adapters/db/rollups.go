@@ -0,0 +1,152 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"segmentation-service/internal/domain/models"
+	"strconv"
+	"time"
+)
+
+// rollupRange and reportRange are the conditions selecting the rollups and the report rows of the days from $1 to $2,
+// a NULL bound leaves the range open on its side.
+const (
+	rollupRange = `($1::date IS NULL OR report_rollups.day >= $1::date) AND ($2::date IS NULL OR report_rollups.day <= $2::date)`
+	reportRange = `($1::date IS NULL OR report.created_at >= $1::date) AND ($2::date IS NULL OR report.created_at < $2::date + 1)`
+)
+
+// rollupNotArchived and reportNotArchived are the conditions excluding the rollups and the report rows of the archived
+// months: their report rows have been deleted, so the rollups can neither be rebuilt nor checked.
+const (
+	rollupNotArchived = `NOT EXISTS (
+	SELECT 1 FROM report_archive_runs WHERE report_archive_runs.month = date_trunc('month', report_rollups.day)::date
+		AND report_archive_runs.state = 'archived'
+)`
+	reportNotArchived = `NOT EXISTS (
+	SELECT 1 FROM report_archive_runs WHERE report_archive_runs.month = date_trunc('month', report.created_at)::date
+		AND report_archive_runs.state = 'archived'
+)`
+)
+
+// FoldReportRollups replaces the rows of report_rollups of every segment, day and action written since the last run
+// with one row of their sum, so that the rollups are read from one row per day. It returns the number of the folded
+// rows. The rows appended concurrently are left for the next run.
+func (db *DBStorage) FoldReportRollups(ctx context.Context) (int, error) {
+	q := withSpans(db.Pool, "FoldReportRollups")
+	const query = `
+	WITH scattered AS (
+		SELECT segments_id, day, action FROM report_rollups
+		GROUP BY segments_id, day, action
+		HAVING COUNT(*) > 1
+	), folded AS (
+		DELETE FROM report_rollups USING scattered
+		WHERE report_rollups.segments_id = scattered.segments_id AND report_rollups.day = scattered.day
+			AND report_rollups.action = scattered.action
+		RETURNING report_rollups.segments_id, report_rollups.day, report_rollups.action, report_rollups.count
+	), inserted AS (
+		INSERT INTO report_rollups (segments_id, day, action, count)
+		SELECT segments_id, day, action, SUM(count) FROM folded
+		GROUP BY segments_id, day, action
+	)
+	SELECT COUNT(*) FROM folded;
+	`
+	var count int
+	err := q.QueryRow(ctx, query).Scan(&count)
+	return count, err
+}
+
+// BackfillRollups rebuilds the rollups of the days from the first to the last one, nil meaning the beginning or
+// the end of the history, from the report rows. The old rollups are replaced in the same statement, so the rows
+// written concurrently are counted exactly once: either by the rebuild or by the trigger of their transaction.
+// The rollups of the archived months are kept.
+func (db *DBStorage) BackfillRollups(ctx context.Context, from, to *time.Time) (result models.RollupResult, err error) {
+	q := withSpans(db.Pool, "BackfillRollups")
+	query := `
+	WITH cleared AS (
+		DELETE FROM report_rollups WHERE ` + rollupRange + ` AND ` + rollupNotArchived + `
+	), rolled AS (
+		INSERT INTO report_rollups (segments_id, day, action, count)
+		SELECT segments_id, created_at::date, action, COUNT(*) FROM report
+		WHERE ` + reportRange + `
+		GROUP BY segments_id, created_at::date, action
+		RETURNING count
+	)
+	SELECT COUNT(*), COALESCE(SUM(count), 0)::bigint FROM rolled;
+	`
+	err = q.QueryRow(ctx, query, from, to).Scan(&result.Rollups, &result.Rows)
+	return result, err
+}
+
+// CheckRollups compares the rollups of the days from the first to the last one, nil meaning the beginning or the end
+// of the history, with the numbers of the report rows and returns the differences ordered by day and segment.
+// Both are read in one statement, so the changes in flight don't show up as differences. The archived months are
+// skipped.
+func (db *DBStorage) CheckRollups(ctx context.Context, from, to *time.Time) ([]models.RollupMismatch, error) {
+	q := withSpans(db.Pool, "CheckRollups")
+	query := `
+	WITH rolled AS (
+		SELECT segments_id, day, action, SUM(count) AS count FROM report_rollups
+		WHERE ` + rollupRange + ` AND ` + rollupNotArchived + `
+		GROUP BY segments_id, day, action
+	), raw AS (
+		SELECT segments_id, created_at::date AS day, action, COUNT(*) AS count FROM report
+		WHERE ` + reportRange + ` AND ` + reportNotArchived + `
+		GROUP BY segments_id, created_at::date, action
+	)
+	SELECT COALESCE(rolled.segments_id, raw.segments_id), COALESCE(segments.name, ''),
+		to_char(COALESCE(rolled.day, raw.day), 'YYYY-MM-DD'), COALESCE(rolled.action, raw.action),
+		COALESCE(rolled.count, 0)::bigint, COALESCE(raw.count, 0)::bigint
+	FROM rolled
+	FULL JOIN raw ON raw.segments_id = rolled.segments_id AND raw.day = rolled.day AND raw.action = rolled.action
+	LEFT JOIN segments ON segments.id = COALESCE(rolled.segments_id, raw.segments_id)
+	WHERE COALESCE(rolled.count, 0) <> COALESCE(raw.count, 0)
+	ORDER BY 3, 2, 1, 4;
+	`
+	rows, err := q.Query(ctx, query, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var mismatches []models.RollupMismatch
+	for rows.Next() {
+		var m models.RollupMismatch
+		if err = rows.Scan(&m.SegmentID, &m.Segment, &m.Date, &m.Action, &m.Rollup, &m.Report); err != nil {
+			return nil, err
+		}
+		mismatches = append(mismatches, m)
+	}
+	return mismatches, rows.Err()
+}
+
+// GetAggregateReport returns the numbers of the members added to and removed from every segment on every day
+// of the month since period (in the format: yyyy-mm-dd) with changes, read from the rollups, in the order of
+// the csv columns: segment, date, added, removed.
+func (db *DBStorage) GetAggregateReport(ctx context.Context, period string) ([][]string, error) {
+	q := withSpans(db.Pool, "GetAggregateReport")
+	const query = `
+	SELECT segments.name, to_char(report_rollups.day, 'YYYY-MM-DD'),
+		COALESCE(SUM(report_rollups.count) FILTER (WHERE report_rollups.action = $2::text), 0)::bigint,
+		COALESCE(SUM(report_rollups.count) FILTER (WHERE report_rollups.action = $3::text), 0)::bigint
+	FROM report_rollups
+	INNER JOIN segments ON segments.id = report_rollups.segments_id
+	WHERE report_rollups.day >= date($1) AND report_rollups.day < date($1) + interval '1 month'
+	GROUP BY report_rollups.day, segments.name
+	ORDER BY report_rollups.day, segments.name;
+	`
+	rows, err := q.Query(ctx, query, period, models.ActAdd, models.ActRemove)
+	if err != nil {
+		return nil, fmt.Errorf("getting aggregate report for the month since '%s' failed: %v", period, err)
+	}
+	defer rows.Close()
+
+	var result [][]string
+	for rows.Next() {
+		var segment, day string
+		var added, removed int
+		if err = rows.Scan(&segment, &day, &added, &removed); err != nil {
+			return result, err
+		}
+		result = append(result, []string{segment, day, strconv.Itoa(added), strconv.Itoa(removed)})
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,37 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+)
+
+func TestCheckRollupsArchived(t *testing.T) {
+	ctx := context.Background()
+
+	// the rows of January are archived: deleted from the report, while their rollups are kept
+	storage := benchStorage(t, "test_rollups", benchSchema(t)+`
+	INSERT INTO segments (name) VALUES ('TEST');
+	INSERT INTO report (user_id, segments_id, action, actor, source, reason, created_at)
+	SELECT gen_random_uuid(), 1, 'add', '', 'api', '', day
+	FROM unnest(ARRAY['2023-01-10 12:00:00+00', '2023-01-11 12:00:00+00', '2023-02-10 12:00:00+00']::timestamptz[]) AS day;
+	DELETE FROM report WHERE created_at < '2023-02-01';
+	INSERT INTO report_archive_runs (month, state, finished_at) VALUES ('2023-01-01', 'archived', NOW());
+	`)
+
+	mismatches, err := storage.CheckRollups(ctx, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(mismatches))
+
+	// the months that aren't archived are still checked
+	_, err = storage.Pool.Exec(ctx, `UPDATE report_rollups SET count = count + 1 WHERE day = '2023-02-10';`)
+	require.NoError(t, err)
+	mismatches, err = storage.CheckRollups(ctx, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(mismatches))
+	assert.Equal(t, "2023-02-10", mismatches[0].Date)
+	assert.Equal(t, 2, mismatches[0].Rollup)
+	assert.Equal(t, 1, mismatches[0].Report)
+}
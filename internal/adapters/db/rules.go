@@ -0,0 +1,307 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"segmentation-service/internal/domain/models"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+// SetSegmentRule saves the rule of the segment and, in the same transaction, makes its members exactly the users whose
+// attributes match the rule, stopping at the cap of the segment. The changes are written to the report. An empty
+// rule turns the segment back into an ordinary one and keeps its current members.
+func (db *DBStorage) SetSegmentRule(ctx context.Context, slug, rule string, evaluate models.RuleEvaluator) (result models.SyncResult, err error) {
+	// start a transaction
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+	q := withSpans(tx, "SetSegmentRule")
+
+	if result, err = setSegmentRule(ctx, q, slug, rule, evaluate); err != nil {
+		return result, err
+	}
+	return result, tx.Commit(ctx)
+}
+
+// setSegmentRule saves the rule and computes the members in the transaction of q, see SetSegmentRule.
+func setSegmentRule(ctx context.Context, q querier, slug, rule string, evaluate models.RuleEvaluator) (result models.SyncResult, err error) {
+	// the segment row stays locked until the commit, so attribute updates wait for the new rule
+	const querySegment = `
+	SELECT id, expression IS NOT NULL, rollout IS NOT NULL, COALESCE(rule, '') FROM segments WHERE name = $1 FOR UPDATE;
+	`
+	var segmentID int32
+	var composite, rollout bool
+	var previous string
+	if err = q.QueryRow(ctx, querySegment, slug).Scan(&segmentID, &composite, &rollout, &previous); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = models.ErrSegmentNotFound
+		}
+		return result, err
+	}
+	if composite && rule != "" {
+		return result, fmt.Errorf("%w: the segment is composite", models.ErrInvalidRule)
+	}
+	if rollout && rule != "" {
+		return result, fmt.Errorf("%w: the segment is a percentage rollout", models.ErrInvalidRule)
+	}
+	if rule != "" {
+		var group string
+		if group, err = experimentOf(ctx, q, slug); err != nil {
+			return result, err
+		}
+		if group != "" {
+			return result, fmt.Errorf("%w '%s'", models.ErrSegmentInExperiment, group)
+		}
+	}
+
+	const queryUpdate = `
+	UPDATE segments SET rule = NULLIF($2, '') WHERE id = $1;
+	`
+	if _, err = q.Exec(ctx, queryUpdate, segmentID, rule); err != nil {
+		return result, err
+	}
+	if previous != rule {
+		if err = recordEvent(ctx, q, slug, models.EventRuleChanged, previous, rule); err != nil {
+			return result, err
+		}
+	}
+	if rule == "" {
+		return result, nil
+	}
+
+	// evaluate the rule for every user with attributes
+	const queryAttributes = `
+	SELECT user_id, attributes FROM user_attributes;
+	`
+	rows, err := q.Query(ctx, queryAttributes)
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+	matched := []uuid.UUID{}
+	for rows.Next() {
+		var userID uuid.UUID
+		var attrs models.Attributes
+		if err = rows.Scan(&userID, &attrs); err != nil {
+			return result, err
+		}
+		if evaluate(rule, attrs) {
+			matched = append(matched, userID)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return result, err
+	}
+
+	const queryRemove = `
+	WITH deleted AS (
+		DELETE FROM segments_users WHERE segments_id = $1 AND user_id <> ALL($2::uuid[])
+		RETURNING user_id
+	)
+	INSERT INTO report (user_id, segments_id, action, actor, source, reason)
+	SELECT user_id, $1, $3, $4::text, $5::text, $6::text FROM deleted;
+	`
+	by := models.AttributionFrom(models.WithSource(ctx, models.SourceRule))
+	tag, err := q.Exec(ctx, queryRemove, segmentID, matched, models.ActRemove, by.Actor, by.Source, by.Reason)
+	if err != nil {
+		return result, err
+	}
+	result.Removed = int(tag.RowsAffected())
+
+	// the matched users beyond the cap are left out, a null limit doesn't limit
+	caps, err := lockCapacity(ctx, q, []string{slug})
+	if err != nil {
+		return result, err
+	}
+	var room *int
+	if c, ok := caps[slug]; ok {
+		n := c.room()
+		room = &n
+	}
+	const queryAdd = `
+	WITH inserted AS (
+		INSERT INTO segments_users (segments_id, user_id)
+		SELECT $1, user_id FROM unnest($2::uuid[]) WITH ORDINALITY AS input(user_id, n)
+		WHERE NOT EXISTS (SELECT 1 FROM segments_users WHERE segments_id = $1 AND segments_users.user_id = input.user_id)
+		ORDER BY n LIMIT $7
+		ON CONFLICT DO NOTHING
+		RETURNING user_id
+	)
+	INSERT INTO report (user_id, segments_id, action, actor, source, reason)
+	SELECT user_id, $1, $3, $4::text, $5::text, $6::text FROM inserted;
+	`
+	tag, err = q.Exec(ctx, queryAdd, segmentID, matched, models.ActAdd, by.Actor, by.Source, by.Reason, room)
+	if err != nil {
+		return result, err
+	}
+	result.Added = int(tag.RowsAffected())
+	if err = recordFull(ctx, q, caps); err != nil {
+		return result, err
+	}
+
+	// any user may have been moved, so the composite segments over this one are recomputed for everybody
+	if _, err = syncComposites(ctx, q, nil, []int32{segmentID}); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// UpdateUserAttributes merges the attributes into the stored ones, null values remove the attribute, and in the same
+// transaction brings the user's memberships of the dynamic segments in line with their rules. A full segment doesn't
+// take the user in. The changes are written to the report.
+func (db *DBStorage) UpdateUserAttributes(ctx context.Context, userID uuid.UUID, attrs models.Attributes, evaluate models.RuleEvaluator) (result models.AttributesResult, err error) {
+	// start a transaction
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+	q := withSpans(tx, "UpdateUserAttributes")
+
+	// the upsert locks the row of the user, so concurrent updates of the same user are applied one after another
+	const queryUpsert = `
+	INSERT INTO user_attributes (user_id, attributes) VALUES ($1, jsonb_strip_nulls($2::jsonb))
+	ON CONFLICT (user_id) DO UPDATE SET attributes = jsonb_strip_nulls(user_attributes.attributes || $2::jsonb), updated_at = NOW()
+	RETURNING attributes;
+	`
+	if err = q.QueryRow(ctx, queryUpsert, userID, attrs).Scan(&result.Attributes); err != nil {
+		return result, err
+	}
+
+	// the rules are read with a shared lock, so a rule can't change until the memberships are updated
+	const queryRules = `
+	SELECT id, name, rule FROM segments WHERE rule IS NOT NULL FOR SHARE;
+	`
+	rows, err := q.Query(ctx, queryRules)
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+	names := make(map[int32]string)
+	matched, unmatched := []int32{}, []int32{}
+	for rows.Next() {
+		var id int32
+		var name, rule string
+		if err = rows.Scan(&id, &name, &rule); err != nil {
+			return result, err
+		}
+		names[id] = name
+		if evaluate(rule, result.Attributes) {
+			matched = append(matched, id)
+		} else {
+			unmatched = append(unmatched, id)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return result, err
+	}
+
+	const queryRemove = `
+	WITH deleted AS (
+		DELETE FROM segments_users WHERE user_id = $1 AND segments_id = ANY($2::int[])
+		RETURNING segments_id
+	), reported AS (
+		INSERT INTO report (user_id, segments_id, action, actor, source, reason)
+		SELECT $1, segments_id, $3, $4::text, $5::text, $6::text FROM deleted
+	)
+	SELECT segments_id FROM deleted;
+	`
+	by := models.AttributionFrom(models.WithSource(ctx, models.SourceRule))
+	result.SegmentsRemoved, err = queryNames(ctx, q, names, queryRemove, userID, unmatched, models.ActRemove, by.Actor, by.Source, by.Reason)
+	if err != nil {
+		return result, err
+	}
+
+	// the user isn't added to the full segments, see lockCapacity
+	if err = lockUsers(ctx, q, []uuid.UUID{userID}); err != nil {
+		return result, err
+	}
+	slugs := make([]string, 0, len(matched))
+	for _, id := range matched {
+		slugs = append(slugs, names[id])
+	}
+	caps, err := lockCapacity(ctx, q, slugs)
+	if err != nil {
+		return result, err
+	}
+	matched = slices.DeleteFunc(matched, func(id int32) bool {
+		c, ok := caps[names[id]]
+		return ok && c.room() == 0
+	})
+
+	const queryAdd = `
+	WITH inserted AS (
+		INSERT INTO segments_users (segments_id, user_id) SELECT unnest($2::int[]), $1
+		ON CONFLICT DO NOTHING
+		RETURNING segments_id
+	), reported AS (
+		INSERT INTO report (user_id, segments_id, action, actor, source, reason)
+		SELECT $1, segments_id, $3, $4::text, $5::text, $6::text FROM inserted
+	)
+	SELECT segments_id FROM inserted;
+	`
+	result.SegmentsAdded, err = queryNames(ctx, q, names, queryAdd, userID, matched, models.ActAdd, by.Actor, by.Source, by.Reason)
+	if err != nil {
+		return result, err
+	}
+	if err = recordFull(ctx, q, caps); err != nil {
+		return result, err
+	}
+
+	changed := []int32{}
+	for id, name := range names {
+		if slices.Contains(result.SegmentsRemoved, name) || slices.Contains(result.SegmentsAdded, name) {
+			changed = append(changed, id)
+		}
+	}
+	if _, err = syncComposites(ctx, q, []uuid.UUID{userID}, changed); err != nil {
+		return result, err
+	}
+	return result, tx.Commit(ctx)
+}
+
+// GetUserAttributes returns the attributes of the user, empty if none were set.
+func (db *DBStorage) GetUserAttributes(ctx context.Context, userID uuid.UUID) (models.Attributes, error) {
+	q := withSpans(db.Pool, "GetUserAttributes")
+	const query = `
+	SELECT attributes FROM user_attributes WHERE user_id = $1;
+	`
+	attrs := models.Attributes{}
+	err := q.QueryRow(ctx, query, userID).Scan(&attrs)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return attrs, nil
+	}
+	return attrs, err
+}
+
+// queryNames runs the query returning segment IDs and converts them to the slugs.
+func queryNames(ctx context.Context, q querier, names map[int32]string, query string, args ...any) ([]string, error) {
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	slugs := []string{}
+	for rows.Next() {
+		var id int32
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		slugs = append(slugs, names[id])
+	}
+	return slugs, rows.Err()
+}
@@ -0,0 +1,109 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"segmentation-service/internal/domain/models"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// GetSegmentStats returns the number of the members of the segment, read from the counters of segment_sizes,
+// and the numbers of the additions and removals on every day from the first to the last day of the range,
+// read from the daily rollups of the report.
+func (db *DBStorage) GetSegmentStats(ctx context.Context, slug string, from, to time.Time) (stats models.SegmentStats, err error) {
+	q := withSpans(db.Pool, "GetSegmentStats")
+	stats = models.SegmentStats{Slug: slug, From: from.Format(models.DateLayout), To: to.Format(models.DateLayout), Days: []models.DailyStats{}}
+
+	const querySegment = `
+	SELECT id, max_members, (SELECT COALESCE(SUM(members), 0)::bigint FROM segment_sizes WHERE segments_id = segments.id)
+	FROM segments WHERE name = $1;
+	`
+	var segmentID int32
+	if err = q.QueryRow(ctx, querySegment, slug).Scan(&segmentID, &stats.MaxMembers, &stats.Members); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = models.ErrSegmentNotFound
+		}
+		return stats, err
+	}
+
+	// the days are the dates in the time zone of the database, as the report shows them
+	const queryDays = `
+	SELECT to_char(day, 'YYYY-MM-DD'),
+		COALESCE(SUM(report_rollups.count) FILTER (WHERE report_rollups.action = $4::text), 0)::bigint,
+		COALESCE(SUM(report_rollups.count) FILTER (WHERE report_rollups.action = $5::text), 0)::bigint
+	FROM generate_series($2::date, $3::date, interval '1 day') AS day
+	LEFT JOIN report_rollups ON report_rollups.segments_id = $1 AND report_rollups.day = day::date
+	GROUP BY day
+	ORDER BY day;
+	`
+	rows, err := q.Query(ctx, queryDays, segmentID, from, to, models.ActAdd, models.ActRemove)
+	if err != nil {
+		return stats, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var day models.DailyStats
+		if err = rows.Scan(&day.Date, &day.Added, &day.Removed); err != nil {
+			return stats, err
+		}
+		stats.Days = append(stats.Days, day)
+	}
+	return stats, rows.Err()
+}
+
+// GetServiceStats returns the numbers of the members of all segments in alphabetical order, read from the counters
+// of segment_sizes rather than by counting the memberships.
+func (db *DBStorage) GetServiceStats(ctx context.Context) (stats models.ServiceStats, err error) {
+	q := withSpans(db.Pool, "GetServiceStats")
+	stats = models.ServiceStats{Sizes: []models.SegmentSize{}}
+
+	const query = `
+	SELECT segments.name, COALESCE(sizes.members, 0)::bigint, segments.max_members, segments.rollout FROM segments
+	LEFT JOIN (SELECT segments_id, SUM(members) AS members FROM segment_sizes GROUP BY segments_id) AS sizes
+		ON sizes.segments_id = segments.id
+	ORDER BY segments.name;
+	`
+	rows, err := q.Query(ctx, query)
+	if err != nil {
+		return stats, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var size models.SegmentSize
+		if err = rows.Scan(&size.Slug, &size.Members, &size.MaxMembers, &size.Rollout); err != nil {
+			return stats, err
+		}
+		stats.Segments++
+		stats.Memberships += size.Members
+		stats.Sizes = append(stats.Sizes, size)
+	}
+	return stats, rows.Err()
+}
+
+// FoldSegmentSizes replaces the rows of segment_sizes of every segment changed since the last run with one row
+// of their sum and drops the rows of the deleted segments, so that the counters are read from one row per segment.
+// It returns the number of the folded rows. The rows appended concurrently are left for the next run.
+func (db *DBStorage) FoldSegmentSizes(ctx context.Context) (int, error) {
+	q := withSpans(db.Pool, "FoldSegmentSizes")
+	const query = `
+	WITH scattered AS (
+		SELECT segments_id FROM segment_sizes
+		GROUP BY segments_id
+		HAVING COUNT(*) > 1 OR NOT EXISTS (SELECT 1 FROM segments WHERE segments.id = segment_sizes.segments_id)
+	), folded AS (
+		DELETE FROM segment_sizes USING scattered WHERE segment_sizes.segments_id = scattered.segments_id
+		RETURNING segment_sizes.segments_id, segment_sizes.members
+	), inserted AS (
+		INSERT INTO segment_sizes (segments_id, members)
+		SELECT segments_id, SUM(members) FROM folded
+		WHERE EXISTS (SELECT 1 FROM segments WHERE segments.id = folded.segments_id)
+		GROUP BY segments_id
+	)
+	SELECT COUNT(*) FROM folded;
+	`
+	var count int
+	err := q.QueryRow(ctx, query).Scan(&count)
+	return count, err
+}
@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"crypto/md5"
+	"fmt"
+	"segmentation-service/internal/domain/bucket"
+	"segmentation-service/internal/domain/models"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// benchStorageUsers users whose IDs are the md5 sums of their numbers are members of every other one
+// of the 20 segments, and the additions are written to the report of the current month.
+const benchStorageUsers = 1000
+
+// benchStorageSeed fills the schema of init.sql.
+var benchStorageSeed = fmt.Sprintf(`
+INSERT INTO segments (name) SELECT 'BENCH_' || i FROM generate_series(1, 20) AS i;
+INSERT INTO segments_users (segments_id, user_id)
+SELECT segments.id, md5(u::text)::uuid FROM segments, generate_series(0, %[1]d - 1) AS u WHERE (u + segments.id) %% 2 = 0;
+INSERT INTO report (user_id, segments_id, action, actor, source, reason)
+SELECT user_id, segments_id, 'add', 'bench', 'api', '' FROM segments_users;
+ANALYZE;
+`, benchStorageUsers)
+
+// BenchmarkStorage measures the storage operations behind the requests of cmd/loadgen on the schema of init.sql.
+func BenchmarkStorage(b *testing.B) {
+	ctx := context.Background()
+	storage := benchStorage(b, "bench_storage", benchSchema(b)+benchStorageSeed)
+	users := make([]uuid.UUID, benchStorageUsers)
+	for i := range users {
+		users[i] = uuid.UUID(md5.Sum([]byte(strconv.Itoa(i))))
+	}
+	month := time.Now().Format("2006-01") + "-01"
+
+	b.Run("SaveSegment", func(b *testing.B) {
+		from := benchStatements.Load()
+		run := strconv.FormatInt(time.Now().UnixNano(), 36)
+		for i := 0; i < b.N; i++ {
+			if err := storage.SaveSegment(ctx, models.Segment{Slug: fmt.Sprintf("BENCH_%s_%d", run, i)}, nil); err != nil {
+				b.Fatal(err)
+			}
+		}
+		reportStatements(b, from)
+	})
+	b.Run("UpdateUserSegments", func(b *testing.B) {
+		from := benchStatements.Load()
+		for i := 0; i < b.N; i++ {
+			// three segments are added and three others removed, every other call the other way round
+			data := models.UpdateRequest{SegmentsToAdd: []string{"BENCH_1", "BENCH_3", "BENCH_5"}, SegmentsToRemove: []string{"BENCH_2", "BENCH_4", "BENCH_6"}}
+			if i%2 == 1 {
+				data.SegmentsToAdd, data.SegmentsToRemove = data.SegmentsToRemove, data.SegmentsToAdd
+			}
+			if _, err := storage.UpdateUserSegments(ctx, data, users[i%len(users)], bucket.InRollout); err != nil {
+				b.Fatal(err)
+			}
+		}
+		reportStatements(b, from)
+	})
+	b.Run("GetUserSegments", func(b *testing.B) {
+		from := benchStatements.Load()
+		for i := 0; i < b.N; i++ {
+			if _, err := storage.GetUserSegments(ctx, users[i%len(users)], bucket.InRollout); err != nil {
+				b.Fatal(err)
+			}
+		}
+		reportStatements(b, from)
+	})
+	b.Run("GetReport", func(b *testing.B) {
+		from := benchStatements.Load()
+		for i := 0; i < b.N; i++ {
+			if _, err := storage.GetReport(ctx, month, models.ReportFilter{}); err != nil {
+				b.Fatal(err)
+			}
+		}
+		reportStatements(b, from)
+	})
+}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"sync"
+
+	"segmentation-service/pkg/infra/tracing"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// querier is the subset of methods shared by pgxpool.Pool and pgx.Tx that the storage uses.
+type querier interface {
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// traced wraps a querier so that every SQL statement gets its own child span named after the operation.
+type traced struct {
+	q  querier
+	op string
+}
+
+func withSpans(q querier, op string) traced {
+	return traced{q: q, op: op}
+}
+
+func (t traced) start(ctx context.Context, sql string) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, "db."+t.op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.statement", sql),
+		),
+	)
+}
+
+func (t traced) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	ctx, span := t.start(ctx, sql)
+	tag, err := t.q.Exec(ctx, sql, args...)
+	endSpan(span, err)
+	return tag, err
+}
+
+func (t traced) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	ctx, span := t.start(ctx, sql)
+	rows, err := t.q.Query(ctx, sql, args...)
+	if err != nil {
+		endSpan(span, err)
+		return rows, err
+	}
+	return &tracedRows{Rows: rows, span: span}, nil
+}
+
+func (t traced) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	ctx, span := t.start(ctx, sql)
+	return tracedRow{row: t.q.QueryRow(ctx, sql, args...), span: span}
+}
+
+// tracedRow ends the span once the row has been scanned.
+type tracedRow struct {
+	row  pgx.Row
+	span trace.Span
+}
+
+func (r tracedRow) Scan(dest ...interface{}) error {
+	err := r.row.Scan(dest...)
+	if err == pgx.ErrNoRows {
+		endSpan(r.span, nil)
+	} else {
+		endSpan(r.span, err)
+	}
+	return err
+}
+
+// tracedRows ends the span once the result set has been read to the end or closed.
+type tracedRows struct {
+	pgx.Rows
+	span trace.Span
+	once sync.Once
+}
+
+func (r *tracedRows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+	r.end()
+	return false
+}
+
+func (r *tracedRows) Close() {
+	r.Rows.Close()
+	r.end()
+}
+
+func (r *tracedRows) end() {
+	r.once.Do(func() { endSpan(r.span, r.Rows.Err()) })
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,241 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"segmentation-service/internal/domain/bucket"
+	"segmentation-service/internal/domain/models"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// benchSchema returns the statements of init.sql that create the tables, without the creation of the database.
+func benchSchema(tb testing.TB) string {
+	tb.Helper()
+	script, err := os.ReadFile("init.sql")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	start := strings.Index(string(script), "CREATE TABLE segments (")
+	if start < 0 {
+		tb.Fatal("no segments table in init.sql")
+	}
+	return string(script[start:])
+}
+
+// BenchmarkUpdateUserSegments adds a fresh user to a number of segments and removes them back, with the statements
+// per segment the update used to send and with the set-based ones.
+func BenchmarkUpdateUserSegments(b *testing.B) {
+	ctx := context.Background()
+	storage := benchStorage(b, "bench_update", benchSchema(b)+`
+	INSERT INTO segments (name) SELECT 'BENCH_' || i FROM generate_series(1, 50) AS i;
+	INSERT INTO segments (name, rollout, salt) SELECT 'BENCH_ROLLOUT_' || i, 50, 'salt' FROM generate_series(1, 50) AS i;
+	ANALYZE segments;
+	`)
+
+	for _, count := range []int{1, 10, 50} {
+		slugs := make([]string, count)
+		for i := range slugs {
+			// every other segment is a rollout, whose removals are written to rollout_exclusions as well
+			if i%2 == 0 {
+				slugs[i] = fmt.Sprintf("BENCH_%d", i/2+1)
+			} else {
+				slugs[i] = fmt.Sprintf("BENCH_ROLLOUT_%d", i/2+1)
+			}
+		}
+		add := models.UpdateRequest{SegmentsToAdd: slugs}
+		remove := models.UpdateRequest{SegmentsToRemove: slugs}
+
+		for _, impl := range []struct {
+			name   string
+			update func(ctx context.Context, data models.UpdateRequest, userID uuid.UUID) error
+		}{
+			{name: "PerSlug", update: func(ctx context.Context, data models.UpdateRequest, userID uuid.UUID) error {
+				return updatePerSlug(ctx, storage, data, userID)
+			}},
+			{name: "SetBased", update: func(ctx context.Context, data models.UpdateRequest, userID uuid.UUID) error {
+				_, err := storage.UpdateUserSegments(ctx, data, userID, bucket.InRollout)
+				return err
+			}},
+		} {
+			b.Run(fmt.Sprintf("%s/%d", impl.name, count), func(b *testing.B) {
+				from := benchStatements.Load()
+				for i := 0; i < b.N; i++ {
+					userID := uuid.New()
+					if err := impl.update(ctx, add, userID); err != nil {
+						b.Fatal(err)
+					}
+					if err := impl.update(ctx, remove, userID); err != nil {
+						b.Fatal(err)
+					}
+				}
+				reportStatements(b, from)
+			})
+		}
+	}
+}
+
+// BenchmarkUpdateUserSegmentsComposites adds a fresh user to a segment and removes them back with 50 composite
+// segments in the database, when no composite refers to the segment and when one does. The statements per segment
+// the update used to send recompute every composite, the set-based ones only the composites over the changed segments.
+func BenchmarkUpdateUserSegmentsComposites(b *testing.B) {
+	ctx := context.Background()
+	storage := benchStorage(b, "bench_composites", benchSchema(b)+`
+	INSERT INTO segments (name) VALUES ('BENCH_FREE'), ('BENCH_USED');
+	INSERT INTO segments (name) SELECT 'BENCH_' || i FROM generate_series(1, 50) AS i;
+	INSERT INTO segments (name, expression)
+	SELECT 'BENCH_COMPOSITE_' || i, 'BENCH_' || i || ' union BENCH_' || (i % 50 + 1) FROM generate_series(1, 49) AS i;
+	INSERT INTO segments (name, expression) VALUES ('BENCH_COMPOSITE_50', 'BENCH_USED union BENCH_50');
+	ANALYZE segments;
+	`)
+
+	for _, slug := range []string{"BENCH_FREE", "BENCH_USED"} {
+		add := models.UpdateRequest{SegmentsToAdd: []string{slug}}
+		remove := models.UpdateRequest{SegmentsToRemove: []string{slug}}
+
+		for _, impl := range []struct {
+			name   string
+			update func(ctx context.Context, data models.UpdateRequest, userID uuid.UUID) error
+		}{
+			{name: "PerSlug", update: func(ctx context.Context, data models.UpdateRequest, userID uuid.UUID) error {
+				return updatePerSlug(ctx, storage, data, userID)
+			}},
+			{name: "SetBased", update: func(ctx context.Context, data models.UpdateRequest, userID uuid.UUID) error {
+				_, err := storage.UpdateUserSegments(ctx, data, userID, bucket.InRollout)
+				return err
+			}},
+		} {
+			b.Run(fmt.Sprintf("%s/%s", impl.name, slug), func(b *testing.B) {
+				from := benchStatements.Load()
+				for i := 0; i < b.N; i++ {
+					userID := uuid.New()
+					if err := impl.update(ctx, add, userID); err != nil {
+						b.Fatal(err)
+					}
+					if err := impl.update(ctx, remove, userID); err != nil {
+						b.Fatal(err)
+					}
+				}
+				reportStatements(b, from)
+			})
+		}
+	}
+}
+
+// updatePerSlug is the baseline of BenchmarkUpdateUserSegments: the update as it was before the statements became
+// set-based, with three to four statements per segment. The results aren't collected.
+func updatePerSlug(ctx context.Context, db *DBStorage, data models.UpdateRequest, userID uuid.UUID) (err error) {
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+	q := withSpans(tx, "UpdateUserSegments")
+
+	ids := make(map[string]int)
+	rows, err := q.Query(ctx, `SELECT name, id FROM segments WHERE name = ANY($1::text[]);`, slices.Concat(data.SegmentsToAdd, data.SegmentsToRemove))
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var slug string
+		var id int
+		if err = rows.Scan(&slug, &id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids[slug] = id
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	if _, err = memberships(ctx, q, []uuid.UUID{userID}); err != nil {
+		return err
+	}
+
+	const queryReport = `
+	INSERT INTO report (user_id, segments_id, action, actor, source, reason) VALUES ($1, $2, $3, '', 'api', '')
+	`
+	for _, slug := range data.SegmentsToRemove {
+		deleted, err := q.Exec(ctx, `DELETE FROM segments_users WHERE segments_id = $1 AND user_id = $2;`, ids[slug], userID)
+		if err != nil {
+			return err
+		}
+		excluded, err := q.Exec(ctx, `
+		INSERT INTO rollout_exclusions (segments_id, user_id)
+		SELECT id, $2::uuid FROM segments WHERE id = $1 AND rollout IS NOT NULL
+		ON CONFLICT DO NOTHING
+		`, ids[slug], userID)
+		if err != nil {
+			return err
+		}
+		if deleted.RowsAffected() == 0 && excluded.RowsAffected() == 0 {
+			continue
+		}
+		if _, err = q.Exec(ctx, queryReport, userID, ids[slug], models.ActRemove); err != nil {
+			return err
+		}
+	}
+
+	var caps map[string]capacity
+	if len(data.SegmentsToAdd) > 0 {
+		if err = lockUsers(ctx, q, []uuid.UUID{userID}); err != nil {
+			return err
+		}
+		if caps, err = lockCapacity(ctx, q, data.SegmentsToAdd); err != nil {
+			return err
+		}
+	}
+	var added []string // the segments the user is a member of after the additions
+	for _, slug := range data.SegmentsToAdd {
+		if _, err = q.Exec(ctx, `DELETE FROM rollout_exclusions WHERE segments_id = $1 AND user_id = $2;`, ids[slug], userID); err != nil {
+			return err
+		}
+		var cnt int
+		err = q.QueryRow(ctx, `SELECT COUNT(*) FROM segments_users WHERE segments_id = $1 AND user_id = $2;`, ids[slug], userID).Scan(&cnt)
+		if err != nil {
+			return err
+		}
+		if cnt != 0 {
+			added = append(added, slug)
+			continue
+		}
+		// a full segment is skipped, as in the non-strict mode
+		if c, ok := caps[slug]; ok && c.room() == 0 {
+			continue
+		}
+		if _, err = q.Exec(ctx, `INSERT INTO segments_users (segments_id, user_id) VALUES ($1, $2)`, ids[slug], userID); err != nil {
+			return err
+		}
+		if _, err = q.Exec(ctx, queryReport, userID, ids[slug], models.ActAdd); err != nil {
+			return err
+		}
+		added = append(added, slug)
+	}
+
+	users := make([]uuid.UUID, len(added))
+	for i := range users {
+		users[i] = userID
+	}
+	if _, err = enforceExperiments(ctx, q, users, added); err != nil {
+		return err
+	}
+	if _, err = syncComposites(ctx, q, []uuid.UUID{userID}, nil); err != nil {
+		return err
+	}
+	if err = recordFull(ctx, q, caps); err != nil {
+		return err
+	}
+	if _, err = memberships(ctx, q, []uuid.UUID{userID}); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"segmentation-service/internal/domain/models"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// stateColumn computes the state of a segment by its activation window at the time of the query.
+const stateColumn = `CASE WHEN active_from > NOW() THEN 'scheduled' WHEN active_until <= NOW() THEN 'ended' ELSE 'active' END`
+
+// SetSegmentWindow saves the activation window of the segment, nil bounds leave the window open on that side.
+// The memberships aren't touched, so nothing is written to the report. The state of the segment with the new
+// window is returned; the change of the window is recorded in the audit log right away, and the change of the state
+// by RecordSegmentStates.
+func (db *DBStorage) SetSegmentWindow(ctx context.Context, slug string, from, until *time.Time) (result models.SegmentState, err error) {
+	result = models.SegmentState{Slug: slug}
+
+	// start a transaction
+	tx, err := db.Pool.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+	q := withSpans(tx, "SetSegmentWindow")
+
+	if result, err = setSegmentWindow(ctx, q, slug, from, until); err != nil {
+		return result, err
+	}
+	return result, tx.Commit(ctx)
+}
+
+// setSegmentWindow saves the window in the transaction of q, see SetSegmentWindow.
+func setSegmentWindow(ctx context.Context, q querier, slug string, from, until *time.Time) (result models.SegmentState, err error) {
+	result = models.SegmentState{Slug: slug}
+
+	const queryCurrent = `
+	SELECT active_from, active_until FROM segments WHERE name = $1 FOR UPDATE;
+	`
+	var oldFrom, oldUntil *time.Time
+	if err = q.QueryRow(ctx, queryCurrent, slug).Scan(&oldFrom, &oldUntil); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = models.ErrSegmentNotFound
+		}
+		return result, err
+	}
+
+	query := `
+	UPDATE segments SET active_from = $2, active_until = $3 WHERE name = $1
+	RETURNING ` + stateColumn + `, active_from, active_until;
+	`
+	if err = q.QueryRow(ctx, query, slug, from, until).Scan(&result.State, &result.ActiveFrom, &result.ActiveUntil); err != nil {
+		return result, err
+	}
+	oldValue, newValue := models.WindowValue(oldFrom, oldUntil), models.WindowValue(result.ActiveFrom, result.ActiveUntil)
+	if oldValue != newValue {
+		if err = recordEvent(ctx, q, slug, models.EventWindowChanged, oldValue, newValue); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// RecordSegmentStates writes the activations and deactivations of the segments whose state changed since the last
+// call to the audit log and returns the number of written events. Becoming active is recorded as an activation,
+// leaving the active state as a deactivation; a scheduled segment that ended before it was noticed isn't recorded.
+func (db *DBStorage) RecordSegmentStates(ctx context.Context) (int, error) {
+	q := withSpans(db.Pool, "RecordSegmentStates")
+
+	// the old state is taken from the row before the update, so the changes are written in a single statement
+	query := `
+	WITH changed AS (
+		UPDATE segments SET window_state = current.state
+		FROM (SELECT id, window_state AS old_state, ` + stateColumn + ` AS state FROM segments FOR UPDATE) AS current
+		WHERE segments.id = current.id AND current.old_state <> current.state
+		RETURNING segments.id, segments.name, current.old_state, current.state
+	)
+	INSERT INTO segment_events (segments_id, slug, event, old_value, new_value, source)
+	SELECT id, name, CASE WHEN state = 'active' THEN $1::text ELSE $2::text END, old_state, state, $3::text
+	FROM changed
+	WHERE state = 'active' OR old_state = 'active';
+	`
+	tag, err := q.Exec(ctx, query, models.EventActivated, models.EventDeactivated, models.SourceScheduler)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
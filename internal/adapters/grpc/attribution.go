@@ -0,0 +1,41 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"segmentation-service/internal/adapters/auth"
+	"segmentation-service/internal/domain/models"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// attribute stores the actor, the source and the reason of the call in its context, taken from the x-actor,
+// x-source and x-reason metadata like the http headers: percent-encoded values are decoded, and the authenticated
+// caller overrides the actor.
+func attribute(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var by models.Attribution
+	for _, m := range []struct {
+		key string
+		dst *string
+	}{{"x-actor", &by.Actor}, {"x-source", &by.Source}, {"x-reason", &by.Reason}} {
+		values := md.Get(m.key)
+		if len(values) == 0 {
+			continue
+		}
+		value, err := url.PathUnescape(values[0])
+		if err != nil {
+			return nil, toStatus(ctx, fmt.Errorf("%w: invalid %s metadata", models.ErrInvalidAttribution, m.key))
+		}
+		*m.dst = value
+	}
+	if identity := auth.Identity(ctx); identity != "" {
+		by.Actor = identity
+	}
+	if err := by.Validate(); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return handler(models.WithAttribution(ctx, by), req)
+}
@@ -0,0 +1,65 @@
+package grpc
+
+import (
+	"context"
+	segmentationv1 "segmentation-service/api/proto/segmentation/v1"
+	"segmentation-service/internal/adapters/auth"
+	"segmentation-service/internal/domain/models"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// apiKeyMetadata is the metadata key carrying the API key, the key can also be sent as a bearer token.
+const apiKeyMetadata = "x-api-key"
+
+// authenticator checks the API key of the calls to the segmentation service. The health and reflection
+// services stay open, like the probes of the http server.
+type authenticator struct {
+	keys auth.Keys
+}
+
+func (a authenticator) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := a.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a authenticator) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+}
+
+func (a authenticator) authenticate(ctx context.Context, method string) (context.Context, error) {
+	if !a.keys.Enabled() || !strings.HasPrefix(method, "/"+segmentationv1.SegmentationService_ServiceDesc.ServiceName+"/") {
+		return ctx, nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	var key string
+	if values := md.Get(apiKeyMetadata); len(values) > 0 {
+		key = values[0]
+	} else if values := md.Get("authorization"); len(values) > 0 {
+		key, _ = strings.CutPrefix(values[0], "Bearer ")
+	}
+	name, ok := a.keys.Identify(key)
+	if !ok {
+		return ctx, toStatus(ctx, models.ErrUnauthorized)
+	}
+	return auth.WithIdentity(ctx, name), nil
+}
+
+// authenticatedStream replaces the context of the stream with the one carrying the caller identity.
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,72 @@
+package grpc
+
+import (
+	"context"
+	segmentationv1 "segmentation-service/api/proto/segmentation/v1"
+	"segmentation-service/internal/adapters/auth"
+	"segmentation-service/internal/domain/models"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"gotest.tools/assert"
+)
+
+func TestAuthentication(t *testing.T) {
+	keys, err := auth.ParseKeys([]string{"ops:secret"})
+	require.NoError(t, err)
+	client, svc, conn := newTestClientWithOptions(t, AdapterOptions{GRPC_port: 0, APIKeys: keys})
+
+	// prepare test data
+	testCases := []struct {
+		name    string
+		md      metadata.MD
+		useMock bool
+		expCode codes.Code
+	}{
+		{
+			name:    "OK - api key",
+			md:      metadata.Pairs(apiKeyMetadata, "secret"),
+			useMock: true,
+			expCode: codes.OK,
+		},
+		{
+			name:    "OK - bearer token",
+			md:      metadata.Pairs("authorization", "Bearer secret"),
+			useMock: true,
+			expCode: codes.OK,
+		},
+		{
+			name:    "Missing key",
+			expCode: codes.Unauthenticated,
+		},
+		{
+			name:    "Invalid key",
+			md:      metadata.Pairs(apiKeyMetadata, "wrong"),
+			expCode: codes.Unauthenticated,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.useMock {
+				svc.EXPECT().ListSegments(gomock.Any()).DoAndReturn(func(ctx context.Context) (models.SegmentsList, error) {
+					assert.Equal(t, "ops", auth.Identity(ctx))
+					return models.SegmentsList{}, nil
+				})
+			}
+			ctx := metadata.NewOutgoingContext(context.Background(), tc.md)
+			_, err := client.ListSegments(ctx, &segmentationv1.ListSegmentsRequest{})
+			assert.Equal(t, tc.expCode, status.Code(err))
+		})
+	}
+
+	// the health service doesn't require a key
+	_, err = healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+}
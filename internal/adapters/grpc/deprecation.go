@@ -0,0 +1,35 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"segmentation-service/internal/domain/models"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// deprecation collects the old slugs of renamed segments used by the call and, if there are any, sends them back
+// in the deprecation, sunset and warning headers, like the http adapter does.
+func deprecation(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, deprecated := models.WithDeprecatedSlugs(ctx)
+	resp, err := handler(ctx, req)
+
+	aliases := deprecated.List()
+	if len(aliases) == 0 {
+		return resp, err
+	}
+	md := metadata.Pairs("deprecation", "true")
+	sunset := aliases[0].ExpiresAt
+	for _, alias := range aliases {
+		if alias.ExpiresAt.Before(sunset) {
+			sunset = alias.ExpiresAt
+		}
+		md.Append("warning", fmt.Sprintf(`299 - "segment slug '%s' is deprecated, use '%s'"`, alias.Alias, alias.Slug))
+	}
+	md.Set("sunset", sunset.UTC().Format(http.TimeFormat))
+	// the headers can't be sent if the call has already failed to send them, which isn't worth failing the call
+	_ = grpc.SetHeader(ctx, md)
+	return resp, err
+}
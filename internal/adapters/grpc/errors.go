@@ -0,0 +1,47 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"segmentation-service/internal/domain/models"
+	"segmentation-service/pkg/infra/logger"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// toStatus converts the error returned by the service into a grpc status with the matching code.
+func toStatus(ctx context.Context, err error) error {
+	logger.Get().WarnContext(ctx, "request failed: ", "desc", err.Error())
+
+	switch {
+	case errors.Is(err, models.ErrInvalidSlugFormat), errors.Is(err, models.ErrInvalidUuidFormat),
+		errors.Is(err, models.ErrInvalidPeriodFormat), errors.Is(err, models.ErrBadRequest),
+		errors.Is(err, models.ErrInvalidAction), errors.Is(err, models.ErrInvalidColumns),
+		errors.Is(err, models.ErrInvalidImportFile), errors.Is(err, models.ErrInvalidArchive),
+		errors.Is(err, models.ErrArchiveVersion), errors.Is(err, models.ErrArchiveChecksum),
+		errors.Is(err, models.ErrInvalidRestoreMode), errors.Is(err, models.ErrInvalidRule),
+		errors.Is(err, models.ErrInvalidAttributes), errors.Is(err, models.ErrInvalidExpression),
+		errors.Is(err, models.ErrCompositeCycle), errors.Is(err, models.ErrInvalidLimit),
+		errors.Is(err, models.ErrInvalidCursor), errors.Is(err, models.ErrInvalidExperiment),
+		errors.Is(err, models.ErrInvalidRollout), errors.Is(err, models.ErrInvalidWindow),
+		errors.Is(err, models.ErrInvalidAliasDays), errors.Is(err, models.ErrInvalidAttribution),
+		errors.Is(err, models.ErrInvalidCap), errors.Is(err, models.ErrInvalidDateRange):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, models.ErrUnauthorized):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, models.ErrDynamicSegment), errors.Is(err, models.ErrSegmentInUse),
+		errors.Is(err, models.ErrExperimentConflict), errors.Is(err, models.ErrSegmentInExperiment),
+		errors.Is(err, models.ErrRolloutSegment):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, models.ErrSegmentAlreadyExists), errors.Is(err, models.ErrExperimentExists),
+		errors.Is(err, models.ErrSlugReserved):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, models.ErrSegmentNotFound), errors.Is(err, models.ErrExperimentNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, models.ErrSegmentFull):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
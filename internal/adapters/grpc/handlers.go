@@ -0,0 +1,596 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	segmentationv1 "segmentation-service/api/proto/segmentation/v1"
+	"segmentation-service/internal/domain/models"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func (a *Adapter) CreateSegment(ctx context.Context, req *segmentationv1.CreateSegmentRequest) (*segmentationv1.CreateSegmentResponse, error) {
+	if err := validateSlug(req.GetSlug()); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	from, until, err := parseWindow(req.GetActiveFrom(), req.GetActiveUntil())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	segment := models.Segment{
+		Slug: req.GetSlug(), Rule: req.GetRule(), Expression: req.GetExpression(), Rollout: req.Rollout, Salt: req.GetSalt(),
+		ActiveFrom: from, ActiveUntil: until, MaxMembers: optionalInt(req.MaxMembers),
+	}
+	if err = a.segmentSvc.CreateSegment(ctx, segment); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &segmentationv1.CreateSegmentResponse{}, nil
+}
+
+func (a *Adapter) DeleteSegment(ctx context.Context, req *segmentationv1.DeleteSegmentRequest) (*segmentationv1.DeleteSegmentResponse, error) {
+	if err := validateSlug(req.GetSlug()); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	if err := a.segmentSvc.DeleteSegment(ctx, req.GetSlug()); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &segmentationv1.DeleteSegmentResponse{}, nil
+}
+
+func (a *Adapter) RenameSegment(ctx context.Context, req *segmentationv1.RenameSegmentRequest) (*segmentationv1.RenameSegmentResponse, error) {
+	if err := validateSlug(req.GetSlug()); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	if err := validateSlug(req.GetNewSlug()); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	rename := models.RenameRequest{Slug: req.GetSlug(), NewSlug: req.GetNewSlug()}
+	if req.AliasDays != nil {
+		days := int(req.GetAliasDays())
+		rename.AliasDays = &days
+	}
+	result, err := a.segmentSvc.RenameSegment(ctx, rename)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &segmentationv1.RenameSegmentResponse{
+		Alias: result.Alias, Slug: result.Slug, ExpiresAt: result.ExpiresAt.Format(time.RFC3339),
+	}, nil
+}
+
+func (a *Adapter) SetSegmentRule(ctx context.Context, req *segmentationv1.SetSegmentRuleRequest) (*segmentationv1.SetSegmentRuleResponse, error) {
+	if err := validateSlug(req.GetSlug()); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	result, err := a.segmentSvc.SetSegmentRule(ctx, req.GetSlug(), req.GetRule())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &segmentationv1.SetSegmentRuleResponse{Added: int32(result.Added), Removed: int32(result.Removed)}, nil
+}
+
+func (a *Adapter) SetSegmentExpression(ctx context.Context, req *segmentationv1.SetSegmentExpressionRequest) (*segmentationv1.SetSegmentExpressionResponse, error) {
+	if err := validateSlug(req.GetSlug()); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	result, err := a.segmentSvc.SetSegmentExpression(ctx, req.GetSlug(), req.GetExpression())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &segmentationv1.SetSegmentExpressionResponse{Added: int32(result.Added), Removed: int32(result.Removed)}, nil
+}
+
+func (a *Adapter) SetSegmentRollout(ctx context.Context, req *segmentationv1.SetSegmentRolloutRequest) (*segmentationv1.SetSegmentRolloutResponse, error) {
+	if err := validateSlug(req.GetSlug()); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	result, err := a.segmentSvc.SetSegmentRollout(ctx, req.GetSlug(), req.Rollout, req.GetSalt())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &segmentationv1.SetSegmentRolloutResponse{Slug: result.Slug, Rollout: result.Rollout, Salt: result.Salt}, nil
+}
+
+func (a *Adapter) SetSegmentWindow(ctx context.Context, req *segmentationv1.SetSegmentWindowRequest) (*segmentationv1.SegmentState, error) {
+	if err := validateSlug(req.GetSlug()); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	from, until, err := parseWindow(req.GetActiveFrom(), req.GetActiveUntil())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	result, err := a.segmentSvc.SetSegmentWindow(ctx, req.GetSlug(), from, until)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return segmentState(result), nil
+}
+
+func (a *Adapter) SetSegmentCap(ctx context.Context, req *segmentationv1.SetSegmentCapRequest) (*segmentationv1.SegmentState, error) {
+	if err := validateSlug(req.GetSlug()); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	result, err := a.segmentSvc.SetSegmentCap(ctx, req.GetSlug(), optionalInt(req.MaxMembers))
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return segmentState(result), nil
+}
+
+func (a *Adapter) UpdateUserSegments(ctx context.Context, req *segmentationv1.UpdateUserSegmentsRequest) (*segmentationv1.UpdateUserSegmentsResponse, error) {
+	userID, err := parseUserID(req.GetUserId())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	data := models.UpdateRequest{
+		SegmentsToAdd:    req.GetSegmentsToAdd(),
+		SegmentsToRemove: req.GetSegmentsToRemove(),
+		DryRun:           req.GetDryRun(),
+		NonStrict:        req.GetNonStrict(),
+	}
+	diff, err := a.segmentSvc.UpdateUserSegments(ctx, data, userID)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	results := make([]*segmentationv1.SegmentResult, 0, len(diff.Results))
+	for _, r := range diff.Results {
+		results = append(results, &segmentationv1.SegmentResult{Segment: r.Segment, Action: r.Action, Status: r.Status})
+	}
+	return &segmentationv1.UpdateUserSegmentsResponse{
+		DryRun:  diff.DryRun,
+		Added:   diff.Added,
+		Removed: diff.Removed,
+		NoOp:    diff.NoOp,
+		Unknown: diff.Unknown,
+		Results: results,
+	}, nil
+}
+
+func (a *Adapter) GetUserSegments(ctx context.Context, req *segmentationv1.GetUserSegmentsRequest) (*segmentationv1.GetUserSegmentsResponse, error) {
+	userID, err := parseUserID(req.GetUserId())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	segments, err := a.segmentSvc.GetUserSegments(ctx, userID)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &segmentationv1.GetUserSegmentsResponse{Segments: segments.S}, nil
+}
+
+func (a *Adapter) ListSegments(ctx context.Context, req *segmentationv1.ListSegmentsRequest) (*segmentationv1.ListSegmentsResponse, error) {
+	segments, err := a.segmentSvc.ListSegments(ctx)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	resp := &segmentationv1.ListSegmentsResponse{Segments: segments.S}
+	for _, state := range segments.States {
+		resp.States = append(resp.States, segmentState(state))
+	}
+	return resp, nil
+}
+
+func (a *Adapter) GetSegmentMembers(ctx context.Context, req *segmentationv1.GetSegmentMembersRequest) (*segmentationv1.GetSegmentMembersResponse, error) {
+	if err := validateSlug(req.GetSlug()); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	limit := int(req.GetLimit())
+	if limit == 0 {
+		limit = models.DefaultMembersLimit
+	}
+	if limit < 1 || limit > models.MaxMembersLimit {
+		return nil, toStatus(ctx, models.ErrInvalidLimit)
+	}
+	var after uuid.UUID
+	if req.GetAfter() != "" {
+		var err error
+		if after, err = uuid.Parse(req.GetAfter()); err != nil {
+			return nil, toStatus(ctx, models.ErrInvalidCursor)
+		}
+	}
+
+	result, err := a.segmentSvc.GetSegmentMembers(ctx, req.GetSlug(), after, limit)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	members := make([]string, 0, len(result.Members))
+	for _, userID := range result.Members {
+		members = append(members, userID.String())
+	}
+	return &segmentationv1.GetSegmentMembersResponse{Members: members, Next: result.Next}, nil
+}
+
+func (a *Adapter) UpdateUserAttributes(ctx context.Context, req *segmentationv1.UpdateUserAttributesRequest) (*segmentationv1.UpdateUserAttributesResponse, error) {
+	userID, err := parseUserID(req.GetUserId())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	result, err := a.segmentSvc.UpdateUserAttributes(ctx, userID, req.GetAttributes().AsMap())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	attrs, err := structpb.NewStruct(result.Attributes)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &segmentationv1.UpdateUserAttributesResponse{
+		Attributes:      attrs,
+		SegmentsAdded:   result.SegmentsAdded,
+		SegmentsRemoved: result.SegmentsRemoved,
+	}, nil
+}
+
+func (a *Adapter) GetUserAttributes(ctx context.Context, req *segmentationv1.GetUserAttributesRequest) (*segmentationv1.GetUserAttributesResponse, error) {
+	userID, err := parseUserID(req.GetUserId())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	result, err := a.segmentSvc.GetUserAttributes(ctx, userID)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	attrs, err := structpb.NewStruct(result)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &segmentationv1.GetUserAttributesResponse{Attributes: attrs}, nil
+}
+
+func (a *Adapter) CreateExperimentGroup(ctx context.Context, req *segmentationv1.CreateExperimentGroupRequest) (*segmentationv1.CreateExperimentGroupResponse, error) {
+	if req.GetGroup() == nil {
+		return nil, toStatus(ctx, models.ErrBadRequest)
+	}
+	group, err := a.segmentSvc.CreateExperimentGroup(ctx, fromProtoGroup(req.GetGroup()))
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &segmentationv1.CreateExperimentGroupResponse{Group: toProtoGroup(group)}, nil
+}
+
+func (a *Adapter) DeleteExperimentGroup(ctx context.Context, req *segmentationv1.DeleteExperimentGroupRequest) (*segmentationv1.DeleteExperimentGroupResponse, error) {
+	if req.GetName() == "" {
+		return nil, toStatus(ctx, models.ErrBadRequest)
+	}
+	if err := a.segmentSvc.DeleteExperimentGroup(ctx, req.GetName()); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &segmentationv1.DeleteExperimentGroupResponse{}, nil
+}
+
+func (a *Adapter) ListExperimentGroups(ctx context.Context, req *segmentationv1.ListExperimentGroupsRequest) (*segmentationv1.ListExperimentGroupsResponse, error) {
+	result, err := a.segmentSvc.ListExperimentGroups(ctx)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	groups := make([]*segmentationv1.ExperimentGroup, 0, len(result.Groups))
+	for _, group := range result.Groups {
+		groups = append(groups, toProtoGroup(group))
+	}
+	return &segmentationv1.ListExperimentGroupsResponse{Groups: groups}, nil
+}
+
+func (a *Adapter) AssignExperiment(ctx context.Context, req *segmentationv1.AssignExperimentRequest) (*segmentationv1.AssignExperimentResponse, error) {
+	userID, err := parseUserID(req.GetUserId())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	if req.GetGroup() == "" {
+		return nil, toStatus(ctx, models.ErrBadRequest)
+	}
+	result, err := a.segmentSvc.AssignExperiment(ctx, req.GetGroup(), userID)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &segmentationv1.AssignExperimentResponse{Segment: result.Segment, Assigned: result.Assigned}, nil
+}
+
+func (a *Adapter) GetSegmentHistory(ctx context.Context, req *segmentationv1.GetSegmentHistoryRequest) (*segmentationv1.GetSegmentHistoryResponse, error) {
+	if err := validateSlug(req.GetSlug()); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	history, err := a.segmentSvc.GetSegmentHistory(ctx, req.GetSlug())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	events := make([]*segmentationv1.SegmentEvent, 0, len(history.Events))
+	for _, e := range history.Events {
+		events = append(events, &segmentationv1.SegmentEvent{
+			Segment:  e.Segment,
+			Event:    e.Event,
+			OldValue: e.OldValue,
+			NewValue: e.NewValue,
+			Time:     e.Time.Format(time.RFC3339),
+			Actor:    e.Actor,
+			Source:   e.Source,
+			Reason:   e.Reason,
+		})
+	}
+	return &segmentationv1.GetSegmentHistoryResponse{Slug: history.Slug, Events: events}, nil
+}
+
+func (a *Adapter) GetSegmentStats(ctx context.Context, req *segmentationv1.GetSegmentStatsRequest) (*segmentationv1.GetSegmentStatsResponse, error) {
+	if err := validateSlug(req.GetSlug()); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	from, err := parseDate(req.GetFrom())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	to, err := parseDate(req.GetTo())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	stats, err := a.segmentSvc.GetSegmentStats(ctx, req.GetSlug(), from, to)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	days := make([]*segmentationv1.DailyStats, 0, len(stats.Days))
+	for _, day := range stats.Days {
+		days = append(days, &segmentationv1.DailyStats{Date: day.Date, Added: int64(day.Added), Removed: int64(day.Removed)})
+	}
+	return &segmentationv1.GetSegmentStatsResponse{
+		Slug:       stats.Slug,
+		Members:    int64(stats.Members),
+		MaxMembers: optionalInt32(stats.MaxMembers),
+		From:       stats.From,
+		To:         stats.To,
+		Days:       days,
+	}, nil
+}
+
+func (a *Adapter) GetServiceStats(ctx context.Context, req *segmentationv1.GetServiceStatsRequest) (*segmentationv1.GetServiceStatsResponse, error) {
+	stats, err := a.segmentSvc.GetServiceStats(ctx)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	sizes := make([]*segmentationv1.SegmentSize, 0, len(stats.Sizes))
+	for _, size := range stats.Sizes {
+		sizes = append(sizes, &segmentationv1.SegmentSize{
+			Slug:       size.Slug,
+			Members:    int64(size.Members),
+			MaxMembers: optionalInt32(size.MaxMembers),
+			Rollout:    size.Rollout,
+		})
+	}
+	resp := &segmentationv1.GetServiceStatsResponse{
+		Segments:    int32(stats.Segments),
+		Memberships: int64(stats.Memberships),
+		Sizes:       sizes,
+	}
+	if stats.Cache != nil {
+		resp.Cache = &segmentationv1.CacheStats{
+			Hits:   stats.Cache.Hits,
+			Misses: stats.Cache.Misses,
+			Size:   int64(stats.Cache.Size),
+		}
+	}
+	return resp, nil
+}
+
+func (a *Adapter) GetReport(req *segmentationv1.GetReportRequest, stream segmentationv1.SegmentationService_GetReportServer) error {
+	ctx := stream.Context()
+	if err := validatePeriod(req.GetPeriod()); err != nil {
+		return toStatus(ctx, err)
+	}
+	filter := models.ReportFilter{Actor: req.GetActor(), Source: req.GetSource(), Reason: req.GetReason()}
+	records, err := a.segmentSvc.GetReport(ctx, req.GetPeriod(), filter)
+	if err != nil {
+		return toStatus(ctx, err)
+	}
+	return sendReport(records, stream.Send)
+}
+
+func (a *Adapter) GetUserReport(req *segmentationv1.GetUserReportRequest, stream segmentationv1.SegmentationService_GetUserReportServer) error {
+	ctx := stream.Context()
+	if err := validatePeriod(req.GetPeriod()); err != nil {
+		return toStatus(ctx, err)
+	}
+	userID, err := parseUserID(req.GetUserId())
+	if err != nil {
+		return toStatus(ctx, err)
+	}
+	filter := models.ReportFilter{Actor: req.GetActor(), Source: req.GetSource(), Reason: req.GetReason()}
+	records, err := a.segmentSvc.GetUserReport(ctx, req.GetPeriod(), userID, filter)
+	if err != nil {
+		return toStatus(ctx, err)
+	}
+	return sendReport(records, stream.Send)
+}
+
+func (a *Adapter) GetSegmentReport(req *segmentationv1.GetSegmentReportRequest, stream segmentationv1.SegmentationService_GetSegmentReportServer) error {
+	ctx := stream.Context()
+	if err := validatePeriod(req.GetPeriod()); err != nil {
+		return toStatus(ctx, err)
+	}
+	records, err := a.segmentSvc.GetSegmentReport(ctx, req.GetPeriod())
+	if err != nil {
+		return toStatus(ctx, err)
+	}
+	// the rows are in the order of the csv columns: segment, event, old value, new value, time, actor, source, reason
+	for _, record := range records {
+		if len(record) < 8 {
+			continue
+		}
+		event := &segmentationv1.SegmentEvent{
+			Segment:  record[0],
+			Event:    record[1],
+			OldValue: record[2],
+			NewValue: record[3],
+			Time:     record[4],
+			Actor:    record[5],
+			Source:   record[6],
+			Reason:   record[7],
+		}
+		if err := stream.Send(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sendReport streams the report rows in the order of the csv columns: user, segment, action, time, actor, source,
+// reason.
+func sendReport(records [][]string, send func(*segmentationv1.ReportRow) error) error {
+	for _, record := range records {
+		if len(record) < 7 {
+			continue
+		}
+		row := &segmentationv1.ReportRow{
+			UserId:  record[0],
+			Segment: record[1],
+			Action:  record[2],
+			Time:    record[3],
+			Actor:   record[4],
+			Source:  record[5],
+			Reason:  record[6],
+		}
+		if err := send(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseWindow parses the bounds of the activation window in RFC 3339 format, an empty bound is left open.
+func parseWindow(from, until string) (*time.Time, *time.Time, error) {
+	parse := func(name, value string) (*time.Time, error) {
+		if value == "" {
+			return nil, nil
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s must be in RFC 3339 format", models.ErrInvalidWindow, name)
+		}
+		return &t, nil
+	}
+	start, err := parse("active_from", from)
+	if err != nil {
+		return nil, nil, err
+	}
+	end, err := parse("active_until", until)
+	if err != nil {
+		return nil, nil, err
+	}
+	return start, end, nil
+}
+
+// segmentState converts the state of the segment, the bounds of its window are formatted in RFC 3339.
+func segmentState(state models.SegmentState) *segmentationv1.SegmentState {
+	result := &segmentationv1.SegmentState{Slug: state.Slug, State: state.State}
+	if state.ActiveFrom != nil {
+		result.ActiveFrom = state.ActiveFrom.Format(time.RFC3339)
+	}
+	if state.ActiveUntil != nil {
+		result.ActiveUntil = state.ActiveUntil.Format(time.RFC3339)
+	}
+	if state.MaxMembers != nil && state.Members != nil {
+		maxMembers, members := int32(*state.MaxMembers), int32(*state.Members)
+		result.MaxMembers, result.Members = &maxMembers, &members
+	}
+	return result
+}
+
+// optionalInt converts an optional field of a request, nil stays nil.
+func optionalInt(v *int32) *int {
+	if v == nil {
+		return nil
+	}
+	n := int(*v)
+	return &n
+}
+
+func (a *Adapter) GetAggregateReport(req *segmentationv1.GetAggregateReportRequest, stream segmentationv1.SegmentationService_GetAggregateReportServer) error {
+	ctx := stream.Context()
+	if err := validatePeriod(req.GetPeriod()); err != nil {
+		return toStatus(ctx, err)
+	}
+	records, err := a.segmentSvc.GetAggregateReport(ctx, req.GetPeriod())
+	if err != nil {
+		return toStatus(ctx, err)
+	}
+	// the rows are in the order of the csv columns: segment, date, added, removed
+	for _, record := range records {
+		if len(record) < 4 {
+			continue
+		}
+		added, _ := strconv.ParseInt(record[2], 10, 64)
+		removed, _ := strconv.ParseInt(record[3], 10, 64)
+		row := &segmentationv1.AggregateReportRow{Segment: record[0], Date: record[1], Added: added, Removed: removed}
+		if err := stream.Send(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// optionalInt32 converts an optional field of a response, nil stays nil.
+func optionalInt32(v *int) *int32 {
+	if v == nil {
+		return nil
+	}
+	n := int32(*v)
+	return &n
+}
+
+// parseDate parses a day in the format 'yyyy-mm-dd', an empty string is the zero time.
+func parseDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	date, err := time.Parse(models.DateLayout, value)
+	if err != nil {
+		return time.Time{}, models.ErrInvalidDateRange
+	}
+	return date, nil
+}
+
+func validateSlug(slug string) error {
+	if slug == "" {
+		return models.ErrBadRequest
+	}
+	if !models.SlugRegexp.MatchString(slug) {
+		return models.ErrInvalidSlugFormat
+	}
+	return nil
+}
+
+func validatePeriod(period string) error {
+	if period == "" {
+		return models.ErrBadRequest
+	}
+	if !models.PeriodRegexp.MatchString(period) {
+		return models.ErrInvalidPeriodFormat
+	}
+	return nil
+}
+
+func parseUserID(id string) (uuid.UUID, error) {
+	if id == "" {
+		return uuid.Nil, models.ErrBadRequest
+	}
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, models.ErrInvalidUuidFormat
+	}
+	return userID, nil
+}
+
+func fromProtoGroup(g *segmentationv1.ExperimentGroup) models.ExperimentGroup {
+	group := models.ExperimentGroup{Name: g.GetName(), Salt: g.GetSalt(), Conflict: g.GetConflict()}
+	for _, v := range g.GetVariants() {
+		group.Variants = append(group.Variants, models.Variant{Slug: v.GetSlug(), Weight: int(v.GetWeight())})
+	}
+	return group
+}
+
+func toProtoGroup(group models.ExperimentGroup) *segmentationv1.ExperimentGroup {
+	g := &segmentationv1.ExperimentGroup{Name: group.Name, Salt: group.Salt, Conflict: group.Conflict}
+	for _, v := range group.Variants {
+		g.Variants = append(g.Variants, &segmentationv1.Variant{Slug: v.Slug, Weight: int32(v.Weight)})
+	}
+	return g
+}
@@ -0,0 +1,555 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	segmentationv1 "segmentation-service/api/proto/segmentation/v1"
+	"segmentation-service/internal/domain/models"
+	"segmentation-service/internal/ports/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"gotest.tools/assert"
+)
+
+// newTestClient starts the adapter on a random port and returns a client connected to it.
+func newTestClient(t *testing.T) (segmentationv1.SegmentationServiceClient, *mocks.MockSegmentService, *grpc.ClientConn) {
+	return newTestClientWithOptions(t, AdapterOptions{GRPC_port: 0})
+}
+
+func newTestClientWithOptions(t *testing.T, opts AdapterOptions) (segmentationv1.SegmentationServiceClient, *mocks.MockSegmentService, *grpc.ClientConn) {
+	ctrl := gomock.NewController(t)
+	svc := mocks.NewMockSegmentService(ctrl)
+	health := mocks.NewMockHealthChecker(ctrl)
+	health.EXPECT().Ping(gomock.Any()).Return(nil).AnyTimes()
+
+	a, err := New(svc, health, opts)
+	require.NoError(t, err)
+	require.NoError(t, a.Start())
+	t.Cleanup(func() { a.Stop(context.Background()) })
+
+	conn, err := grpc.NewClient(a.l.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return segmentationv1.NewSegmentationServiceClient(conn), svc, conn
+}
+
+func TestCreateSegment(t *testing.T) {
+	client, svc, _ := newTestClient(t)
+
+	// prepare test data
+	testCases := []struct {
+		name          string
+		slug          string
+		useMock       bool
+		mockBehaviour func(m *mocks.MockSegmentService)
+		expCode       codes.Code
+	}{
+		{
+			name:    "OK",
+			slug:    "TEST",
+			useMock: true,
+			mockBehaviour: func(m *mocks.MockSegmentService) {
+				m.EXPECT().CreateSegment(gomock.Any(), models.Segment{Slug: "TEST"}).Return(nil)
+			},
+			expCode: codes.OK,
+		},
+		{
+			name:    "Incorrect name",
+			slug:    "# %TEST",
+			expCode: codes.InvalidArgument,
+		},
+		{
+			name:    "No slug param",
+			expCode: codes.InvalidArgument,
+		},
+		{
+			name:    "Slug already exists",
+			slug:    "TEST",
+			useMock: true,
+			mockBehaviour: func(m *mocks.MockSegmentService) {
+				m.EXPECT().CreateSegment(gomock.Any(), models.Segment{Slug: "TEST"}).Return(models.ErrSegmentAlreadyExists)
+			},
+			expCode: codes.AlreadyExists,
+		},
+		{
+			name:    "Internal server error",
+			slug:    "TEST",
+			useMock: true,
+			mockBehaviour: func(m *mocks.MockSegmentService) {
+				m.EXPECT().CreateSegment(gomock.Any(), models.Segment{Slug: "TEST"}).Return(errors.New("some error"))
+			},
+			expCode: codes.Internal,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.useMock {
+				tc.mockBehaviour(svc)
+			}
+			_, err := client.CreateSegment(context.Background(), &segmentationv1.CreateSegmentRequest{Slug: tc.slug})
+			assert.Equal(t, tc.expCode, status.Code(err))
+		})
+	}
+}
+
+func TestUpdateUserSegments(t *testing.T) {
+	client, svc, _ := newTestClient(t)
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+
+	// OK
+	data := models.UpdateRequest{SegmentsToAdd: []string{"TEST1", "TEST2"}, SegmentsToRemove: []string{"TEST3"}}
+	svc.EXPECT().UpdateUserSegments(gomock.Any(), data, uuid.MustParse(userID)).Return(models.MembershipDiff{}, nil)
+	_, err := client.UpdateUserSegments(context.Background(), &segmentationv1.UpdateUserSegmentsRequest{
+		UserId:           userID,
+		SegmentsToAdd:    data.SegmentsToAdd,
+		SegmentsToRemove: data.SegmentsToRemove,
+	})
+	require.NoError(t, err)
+
+	// Dry run
+	data = models.UpdateRequest{SegmentsToAdd: []string{"TEST1", "UNKNOWN"}, DryRun: true}
+	svc.EXPECT().UpdateUserSegments(gomock.Any(), data, uuid.MustParse(userID)).Return(models.MembershipDiff{
+		DryRun: true, Added: []string{"TEST1"}, Unknown: []string{"UNKNOWN"},
+	}, nil)
+	resp, err := client.UpdateUserSegments(context.Background(), &segmentationv1.UpdateUserSegmentsRequest{
+		UserId:        userID,
+		SegmentsToAdd: data.SegmentsToAdd,
+		DryRun:        true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, true, resp.GetDryRun())
+	assert.DeepEqual(t, []string{"TEST1"}, resp.GetAdded())
+	assert.DeepEqual(t, []string{"UNKNOWN"}, resp.GetUnknown())
+
+	// Non-strict mode
+	data = models.UpdateRequest{SegmentsToRemove: []string{"TEST1", "UNKNOWN"}, NonStrict: true}
+	svc.EXPECT().UpdateUserSegments(gomock.Any(), data, uuid.MustParse(userID)).Return(models.MembershipDiff{
+		Unknown: []string{"UNKNOWN"},
+		Results: []models.SegmentResult{
+			{Segment: "TEST1", Action: models.ActRemove, Status: models.StatusNotMember},
+			{Segment: "UNKNOWN", Action: models.ActRemove, Status: models.StatusNotFound},
+		},
+	}, nil)
+	resp, err = client.UpdateUserSegments(context.Background(), &segmentationv1.UpdateUserSegmentsRequest{
+		UserId:           userID,
+		SegmentsToRemove: data.SegmentsToRemove,
+		NonStrict:        true,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.GetResults(), 2)
+	assert.Equal(t, "not_member", resp.GetResults()[0].GetStatus())
+	assert.Equal(t, "not_found", resp.GetResults()[1].GetStatus())
+
+	// Error - invalid uuid format
+	_, err = client.UpdateUserSegments(context.Background(), &segmentationv1.UpdateUserSegmentsRequest{UserId: "123"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// Error - unknown segment
+	svc.EXPECT().UpdateUserSegments(gomock.Any(), gomock.Any(), uuid.MustParse(userID)).Return(models.MembershipDiff{}, models.ErrSegmentNotFound)
+	_, err = client.UpdateUserSegments(context.Background(), &segmentationv1.UpdateUserSegmentsRequest{UserId: userID})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestAttribution(t *testing.T) {
+	client, svc, _ := newTestClient(t)
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+
+	// the attribution metadata is passed to the service in the context
+	svc.EXPECT().UpdateUserSegments(gomock.Any(), gomock.Any(), uuid.MustParse(userID)).DoAndReturn(
+		func(ctx context.Context, _ models.UpdateRequest, _ uuid.UUID) (models.MembershipDiff, error) {
+			assert.Equal(t, models.Attribution{Actor: "alice", Source: models.SourceAPI, Reason: "support ticket 42"}, models.AttributionFrom(ctx))
+			return models.MembershipDiff{}, nil
+		})
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-actor", "alice", "x-reason", "support%20ticket%2042")
+	_, err := client.UpdateUserSegments(ctx, &segmentationv1.UpdateUserSegmentsRequest{UserId: userID})
+	require.NoError(t, err)
+
+	// Error - unknown source
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "x-source", "cron")
+	_, err = client.UpdateUserSegments(ctx, &segmentationv1.UpdateUserSegmentsRequest{UserId: userID})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGetUserSegments(t *testing.T) {
+	client, svc, _ := newTestClient(t)
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+
+	svc.EXPECT().GetUserSegments(gomock.Any(), uuid.MustParse(userID)).Return(models.SegmentsList{S: []string{"TEST1", "TEST2"}}, nil)
+	resp, err := client.GetUserSegments(context.Background(), &segmentationv1.GetUserSegmentsRequest{UserId: userID})
+	require.NoError(t, err)
+	assert.DeepEqual(t, []string{"TEST1", "TEST2"}, resp.GetSegments())
+}
+
+func TestListSegments(t *testing.T) {
+	client, svc, _ := newTestClient(t)
+
+	svc.EXPECT().ListSegments(gomock.Any()).Return(models.SegmentsList{S: []string{"TEST1", "TEST2"}}, nil)
+	resp, err := client.ListSegments(context.Background(), &segmentationv1.ListSegmentsRequest{})
+	require.NoError(t, err)
+	assert.DeepEqual(t, []string{"TEST1", "TEST2"}, resp.GetSegments())
+}
+
+func TestSetSegmentRule(t *testing.T) {
+	client, svc, _ := newTestClient(t)
+
+	svc.EXPECT().SetSegmentRule(gomock.Any(), "TEST", "age >= 18").Return(models.SyncResult{Added: 2}, nil)
+	resp, err := client.SetSegmentRule(context.Background(), &segmentationv1.SetSegmentRuleRequest{Slug: "TEST", Rule: "age >= 18"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), resp.GetAdded())
+
+	svc.EXPECT().SetSegmentRule(gomock.Any(), "TEST", "age >=").Return(models.SyncResult{}, models.ErrInvalidRule)
+	_, err = client.SetSegmentRule(context.Background(), &segmentationv1.SetSegmentRuleRequest{Slug: "TEST", Rule: "age >="})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestSetSegmentExpression(t *testing.T) {
+	client, svc, _ := newTestClient(t)
+
+	svc.EXPECT().SetSegmentExpression(gomock.Any(), "TEST", "A union B").Return(models.SyncResult{Added: 2}, nil)
+	resp, err := client.SetSegmentExpression(context.Background(), &segmentationv1.SetSegmentExpressionRequest{Slug: "TEST", Expression: "A union B"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), resp.GetAdded())
+
+	svc.EXPECT().SetSegmentExpression(gomock.Any(), "TEST", "A").Return(models.SyncResult{}, models.ErrCompositeCycle)
+	_, err = client.SetSegmentExpression(context.Background(), &segmentationv1.SetSegmentExpressionRequest{Slug: "TEST", Expression: "A"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestSetSegmentRollout(t *testing.T) {
+	client, svc, _ := newTestClient(t)
+	percent := 25.0
+
+	svc.EXPECT().SetSegmentRollout(gomock.Any(), "TEST", &percent, "").Return(models.Segment{Slug: "TEST", Rollout: &percent, Salt: "TEST"}, nil)
+	resp, err := client.SetSegmentRollout(context.Background(), &segmentationv1.SetSegmentRolloutRequest{Slug: "TEST", Rollout: &percent})
+	require.NoError(t, err)
+	assert.Equal(t, 25.0, resp.GetRollout())
+	assert.Equal(t, "TEST", resp.GetSalt())
+
+	// turning the rollout off passes no percentage
+	svc.EXPECT().SetSegmentRollout(gomock.Any(), "TEST", nil, "").Return(models.Segment{Slug: "TEST"}, nil)
+	resp, err = client.SetSegmentRollout(context.Background(), &segmentationv1.SetSegmentRolloutRequest{Slug: "TEST"})
+	require.NoError(t, err)
+	assert.Assert(t, resp.Rollout == nil)
+
+	svc.EXPECT().SetSegmentRollout(gomock.Any(), "TEST", &percent, "").Return(models.Segment{}, models.ErrSegmentInUse)
+	_, err = client.SetSegmentRollout(context.Background(), &segmentationv1.SetSegmentRolloutRequest{Slug: "TEST", Rollout: &percent})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestRenameSegment(t *testing.T) {
+	client, svc, _ := newTestClient(t)
+	expires := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	days := 7
+
+	svc.EXPECT().RenameSegment(gomock.Any(), models.RenameRequest{Slug: "OLD", NewSlug: "NEW", AliasDays: &days}).
+		Return(models.SegmentAlias{Alias: "OLD", Slug: "NEW", ExpiresAt: expires}, nil)
+	aliasDays := int32(days)
+	resp, err := client.RenameSegment(context.Background(), &segmentationv1.RenameSegmentRequest{Slug: "OLD", NewSlug: "NEW", AliasDays: &aliasDays})
+	require.NoError(t, err)
+	assert.Equal(t, "2023-10-01T00:00:00Z", resp.GetExpiresAt())
+
+	svc.EXPECT().RenameSegment(gomock.Any(), gomock.Any()).Return(models.SegmentAlias{}, models.ErrSlugReserved)
+	_, err = client.RenameSegment(context.Background(), &segmentationv1.RenameSegmentRequest{Slug: "OLD", NewSlug: "NEW"})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	// Error - invalid new slug
+	_, err = client.RenameSegment(context.Background(), &segmentationv1.RenameSegmentRequest{Slug: "OLD", NewSlug: "# %NEW"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// the calls using an alias get the deprecation headers
+	svc.EXPECT().DeleteSegment(gomock.Any(), "OLD").DoAndReturn(func(ctx context.Context, slug string) error {
+		models.RecordDeprecatedSlug(ctx, models.SegmentAlias{Alias: "OLD", Slug: "NEW", ExpiresAt: expires})
+		return nil
+	})
+	var header metadata.MD
+	_, err = client.DeleteSegment(context.Background(), &segmentationv1.DeleteSegmentRequest{Slug: "OLD"}, grpc.Header(&header))
+	require.NoError(t, err)
+	assert.DeepEqual(t, []string{"true"}, header.Get("deprecation"))
+	assert.DeepEqual(t, []string{"Sun, 01 Oct 2023 00:00:00 GMT"}, header.Get("sunset"))
+}
+
+func TestSetSegmentWindow(t *testing.T) {
+	client, svc, _ := newTestClient(t)
+	from := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	svc.EXPECT().SetSegmentWindow(gomock.Any(), "TEST", &from, nil).
+		Return(models.SegmentState{Slug: "TEST", State: models.StateActive, ActiveFrom: &from}, nil)
+	resp, err := client.SetSegmentWindow(context.Background(), &segmentationv1.SetSegmentWindowRequest{Slug: "TEST", ActiveFrom: "2023-09-01T00:00:00Z"})
+	require.NoError(t, err)
+	assert.Equal(t, models.StateActive, resp.GetState())
+	assert.Equal(t, "2023-09-01T00:00:00Z", resp.GetActiveFrom())
+	assert.Equal(t, "", resp.GetActiveUntil())
+
+	// Error - invalid time
+	_, err = client.SetSegmentWindow(context.Background(), &segmentationv1.SetSegmentWindowRequest{Slug: "TEST", ActiveUntil: "tomorrow"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// the states are listed with the segments
+	svc.EXPECT().ListSegments(gomock.Any()).Return(models.SegmentsList{
+		S:      []string{"TEST"},
+		States: []models.SegmentState{{Slug: "TEST", State: models.StateScheduled, ActiveFrom: &from}},
+	}, nil)
+	list, err := client.ListSegments(context.Background(), &segmentationv1.ListSegmentsRequest{})
+	require.NoError(t, err)
+	require.Len(t, list.GetStates(), 1)
+	assert.Equal(t, models.StateScheduled, list.GetStates()[0].GetState())
+}
+
+func TestSetSegmentCap(t *testing.T) {
+	client, svc, _ := newTestClient(t)
+	maxMembers, members := 100, 100
+
+	svc.EXPECT().SetSegmentCap(gomock.Any(), "TEST", &maxMembers).
+		Return(models.SegmentState{Slug: "TEST", State: models.StateActive, MaxMembers: &maxMembers, Members: &members}, nil)
+	limit := int32(100)
+	resp, err := client.SetSegmentCap(context.Background(), &segmentationv1.SetSegmentCapRequest{Slug: "TEST", MaxMembers: &limit})
+	require.NoError(t, err)
+	assert.Equal(t, int32(100), resp.GetMaxMembers())
+	assert.Equal(t, int32(100), resp.GetMembers())
+
+	// a missing cap removes it
+	svc.EXPECT().SetSegmentCap(gomock.Any(), "TEST", nil).Return(models.SegmentState{Slug: "TEST", State: models.StateActive}, nil)
+	resp, err = client.SetSegmentCap(context.Background(), &segmentationv1.SetSegmentCapRequest{Slug: "TEST"})
+	require.NoError(t, err)
+	assert.Equal(t, false, resp.MaxMembers != nil)
+
+	// Error - the segment is full
+	svc.EXPECT().UpdateUserSegments(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(models.MembershipDiff{}, fmt.Errorf("%w: 'TEST' has 100 of 100 members", models.ErrSegmentFull))
+	_, err = client.UpdateUserSegments(context.Background(), &segmentationv1.UpdateUserSegmentsRequest{
+		UserId: uuid.NewString(), SegmentsToAdd: []string{"TEST"},
+	})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func TestGetSegmentMembers(t *testing.T) {
+	client, svc, _ := newTestClient(t)
+	userID := uuid.New()
+
+	svc.EXPECT().GetSegmentMembers(gomock.Any(), "TEST", uuid.Nil, models.DefaultMembersLimit).
+		Return(models.MembersList{Members: []uuid.UUID{userID}, Next: userID.String()}, nil)
+	resp, err := client.GetSegmentMembers(context.Background(), &segmentationv1.GetSegmentMembersRequest{Slug: "TEST"})
+	require.NoError(t, err)
+	assert.DeepEqual(t, []string{userID.String()}, resp.GetMembers())
+	assert.Equal(t, userID.String(), resp.GetNext())
+
+	// Error - invalid limit and cursor
+	_, err = client.GetSegmentMembers(context.Background(), &segmentationv1.GetSegmentMembersRequest{Slug: "TEST", Limit: -1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.GetSegmentMembers(context.Background(), &segmentationv1.GetSegmentMembersRequest{Slug: "TEST", After: "123"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestUpdateUserAttributes(t *testing.T) {
+	client, svc, _ := newTestClient(t)
+	userID := uuid.New()
+	attrs := models.Attributes{"city": "Moscow", "age": float64(30)}
+
+	svc.EXPECT().UpdateUserAttributes(gomock.Any(), userID, attrs).
+		Return(models.AttributesResult{Attributes: attrs, SegmentsAdded: []string{"MOSCOW"}}, nil)
+	fields, err := structpb.NewStruct(attrs)
+	require.NoError(t, err)
+	resp, err := client.UpdateUserAttributes(context.Background(), &segmentationv1.UpdateUserAttributesRequest{UserId: userID.String(), Attributes: fields})
+	require.NoError(t, err)
+	assert.DeepEqual(t, map[string]any(attrs), resp.GetAttributes().AsMap())
+	assert.DeepEqual(t, []string{"MOSCOW"}, resp.GetSegmentsAdded())
+}
+
+func TestCreateExperimentGroup(t *testing.T) {
+	client, svc, _ := newTestClient(t)
+	group := models.ExperimentGroup{
+		Name:     "EXP_X",
+		Variants: []models.Variant{{Slug: "EXP_X_CONTROL", Weight: 50}, {Slug: "EXP_X_VARIANT_A", Weight: 50}},
+	}
+	saved := group
+	saved.Salt, saved.Conflict = "EXP_X", models.ConflictReject
+
+	svc.EXPECT().CreateExperimentGroup(gomock.Any(), group).Return(saved, nil)
+	resp, err := client.CreateExperimentGroup(context.Background(), &segmentationv1.CreateExperimentGroupRequest{Group: &segmentationv1.ExperimentGroup{
+		Name:     "EXP_X",
+		Variants: []*segmentationv1.Variant{{Slug: "EXP_X_CONTROL", Weight: 50}, {Slug: "EXP_X_VARIANT_A", Weight: 50}},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, "EXP_X", resp.GetGroup().GetSalt())
+	assert.Equal(t, models.ConflictReject, resp.GetGroup().GetConflict())
+
+	// Error - missing group
+	_, err = client.CreateExperimentGroup(context.Background(), &segmentationv1.CreateExperimentGroupRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestAssignExperiment(t *testing.T) {
+	client, svc, _ := newTestClient(t)
+	userID := uuid.New()
+
+	svc.EXPECT().AssignExperiment(gomock.Any(), "EXP_X", userID).Return(models.Assignment{Group: "EXP_X", Segment: "EXP_X_CONTROL"}, nil)
+	resp, err := client.AssignExperiment(context.Background(), &segmentationv1.AssignExperimentRequest{UserId: userID.String(), Group: "EXP_X"})
+	require.NoError(t, err)
+	assert.Equal(t, "EXP_X_CONTROL", resp.GetSegment())
+	assert.Equal(t, false, resp.GetAssigned())
+
+	svc.EXPECT().AssignExperiment(gomock.Any(), "EXP_Y", userID).Return(models.Assignment{}, models.ErrExperimentNotFound)
+	_, err = client.AssignExperiment(context.Background(), &segmentationv1.AssignExperimentRequest{UserId: userID.String(), Group: "EXP_Y"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestGetReport(t *testing.T) {
+	client, svc, _ := newTestClient(t)
+
+	records := [][]string{
+		{"550e8400-e29b-41d4-a716-446655440000", "TEST1", "add", "2023-08-30 14:38:42", "alice", "api", "promo"},
+		{"550e8400-e29b-41d4-a716-446655440000", "TEST1", "remove", "2023-08-30 14:44:57", "alice", "api", "promo ended"},
+	}
+	svc.EXPECT().GetReport(gomock.Any(), "2023-08", models.ReportFilter{Actor: "alice", Reason: "promo"}).Return(records, nil)
+
+	stream, err := client.GetReport(context.Background(), &segmentationv1.GetReportRequest{Period: "2023-08", Actor: "alice", Reason: "promo"})
+	require.NoError(t, err)
+	var got [][]string
+	for {
+		row, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		got = append(got, []string{
+			row.GetUserId(), row.GetSegment(), row.GetAction(), row.GetTime(), row.GetActor(), row.GetSource(), row.GetReason(),
+		})
+	}
+	assert.DeepEqual(t, records, got)
+
+	// Error - invalid period format
+	stream, err = client.GetReport(context.Background(), &segmentationv1.GetReportRequest{Period: "123-123"})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGetSegmentHistory(t *testing.T) {
+	client, svc, _ := newTestClient(t)
+
+	created := time.Date(2023, 8, 1, 12, 0, 0, 0, time.UTC)
+	svc.EXPECT().GetSegmentHistory(gomock.Any(), "TEST1").Return(models.SegmentHistory{Slug: "TEST1", Events: []models.SegmentEvent{
+		{Segment: "TEST1", Event: models.EventCreated, NewValue: "TEST1", Time: created, Actor: "ops", Source: models.SourceAPI},
+	}}, nil)
+	resp, err := client.GetSegmentHistory(context.Background(), &segmentationv1.GetSegmentHistoryRequest{Slug: "TEST1"})
+	require.NoError(t, err)
+	require.Len(t, resp.GetEvents(), 1)
+	event := resp.GetEvents()[0]
+	assert.Equal(t, "created", event.GetEvent())
+	assert.Equal(t, "2023-08-01T12:00:00Z", event.GetTime())
+	assert.Equal(t, "ops", event.GetActor())
+
+	svc.EXPECT().GetSegmentHistory(gomock.Any(), "TEST2").Return(models.SegmentHistory{}, models.ErrSegmentNotFound)
+	_, err = client.GetSegmentHistory(context.Background(), &segmentationv1.GetSegmentHistoryRequest{Slug: "TEST2"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestGetSegmentStats(t *testing.T) {
+	client, svc, _ := newTestClient(t)
+
+	from, to := time.Date(2023, 8, 1, 0, 0, 0, 0, time.UTC), time.Date(2023, 8, 2, 0, 0, 0, 0, time.UTC)
+	limit := 100
+	svc.EXPECT().GetSegmentStats(gomock.Any(), "TEST1", from, to).Return(models.SegmentStats{
+		Slug: "TEST1", Members: 10, MaxMembers: &limit, From: "2023-08-01", To: "2023-08-02",
+		Days: []models.DailyStats{{Date: "2023-08-01", Added: 12, Removed: 2}, {Date: "2023-08-02"}},
+	}, nil)
+	resp, err := client.GetSegmentStats(context.Background(), &segmentationv1.GetSegmentStatsRequest{Slug: "TEST1", From: "2023-08-01", To: "2023-08-02"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), resp.GetMembers())
+	assert.Equal(t, int32(100), resp.GetMaxMembers())
+	require.Len(t, resp.GetDays(), 2)
+	assert.Equal(t, "2023-08-01", resp.GetDays()[0].GetDate())
+	assert.Equal(t, int64(12), resp.GetDays()[0].GetAdded())
+	assert.Equal(t, int64(2), resp.GetDays()[0].GetRemoved())
+
+	_, err = client.GetSegmentStats(context.Background(), &segmentationv1.GetSegmentStatsRequest{Slug: "TEST1", From: "yesterday"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	svc.EXPECT().GetSegmentStats(gomock.Any(), "TEST2", time.Time{}, time.Time{}).Return(models.SegmentStats{}, models.ErrSegmentNotFound)
+	_, err = client.GetSegmentStats(context.Background(), &segmentationv1.GetSegmentStatsRequest{Slug: "TEST2"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestGetServiceStats(t *testing.T) {
+	client, svc, _ := newTestClient(t)
+
+	rollout := 10.0
+	svc.EXPECT().GetServiceStats(gomock.Any()).Return(models.ServiceStats{Segments: 2, Memberships: 15, Sizes: []models.SegmentSize{
+		{Slug: "TEST1", Members: 10},
+		{Slug: "TEST2", Members: 5, Rollout: &rollout},
+	}, Cache: &models.CacheStats{Hits: 7, Misses: 3, Size: 2}}, nil)
+	resp, err := client.GetServiceStats(context.Background(), &segmentationv1.GetServiceStatsRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), resp.GetSegments())
+	assert.Equal(t, int64(15), resp.GetMemberships())
+	require.Len(t, resp.GetSizes(), 2)
+	assert.Assert(t, resp.GetSizes()[0].MaxMembers == nil)
+	assert.Equal(t, 10.0, resp.GetSizes()[1].GetRollout())
+	assert.Equal(t, uint64(7), resp.GetCache().GetHits())
+	assert.Equal(t, uint64(3), resp.GetCache().GetMisses())
+	assert.Equal(t, int64(2), resp.GetCache().GetSize())
+}
+
+func TestGetSegmentReport(t *testing.T) {
+	client, svc, _ := newTestClient(t)
+
+	records := [][]string{{"TEST1", "renamed", "OLD", "TEST1", "2023-08-30 14:38:42", "ops", "api", "typo"}}
+	svc.EXPECT().GetSegmentReport(gomock.Any(), "2023-08").Return(records, nil)
+
+	stream, err := client.GetSegmentReport(context.Background(), &segmentationv1.GetSegmentReportRequest{Period: "2023-08"})
+	require.NoError(t, err)
+	var got [][]string
+	for {
+		e, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		got = append(got, []string{
+			e.GetSegment(), e.GetEvent(), e.GetOldValue(), e.GetNewValue(), e.GetTime(), e.GetActor(), e.GetSource(), e.GetReason(),
+		})
+	}
+	assert.DeepEqual(t, records, got)
+}
+
+func TestGetAggregateReport(t *testing.T) {
+	client, svc, _ := newTestClient(t)
+
+	svc.EXPECT().GetAggregateReport(gomock.Any(), "2023-08").Return([][]string{{"TEST1", "2023-08-01", "12", "2"}}, nil)
+
+	stream, err := client.GetAggregateReport(context.Background(), &segmentationv1.GetAggregateReportRequest{Period: "2023-08"})
+	require.NoError(t, err)
+	row, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "TEST1", row.GetSegment())
+	assert.Equal(t, "2023-08-01", row.GetDate())
+	assert.Equal(t, int64(12), row.GetAdded())
+	assert.Equal(t, int64(2), row.GetRemoved())
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestHealth(t *testing.T) {
+	_, _, conn := newTestClient(t)
+
+	resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{
+		Service: segmentationv1.SegmentationService_ServiceDesc.ServiceName,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+}
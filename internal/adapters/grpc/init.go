@@ -0,0 +1,143 @@
+// The grpc package is responsible for initializing the gRPC server for service-to-service calls
+// and for processing its requests.
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	segmentationv1 "segmentation-service/api/proto/segmentation/v1"
+	"segmentation-service/internal/adapters/auth"
+	"segmentation-service/internal/ports"
+	"segmentation-service/pkg/infra/logger"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// healthCheckInterval is how often the database is pinged to update the serving status of the health service.
+const healthCheckInterval = 5 * time.Second
+
+type Adapter struct {
+	segmentationv1.UnimplementedSegmentationServiceServer
+
+	s             *grpc.Server
+	l             net.Listener
+	eg            errgroup.Group
+	stopOnce      sync.Once
+	stopHealth    context.CancelFunc
+	health        *health.Server
+	segmentSvc    ports.SegmentService
+	healthChecker ports.HealthChecker
+}
+
+type AdapterOptions struct {
+	GRPC_port int
+	APIKeys   auth.Keys // keys accepted by the segmentation service, the authentication is disabled if empty
+}
+
+// New instantiates the adapter and registers the segmentation, health and reflection services.
+func New(segmentService ports.SegmentService, healthChecker ports.HealthChecker, opts AdapterOptions) (*Adapter, error) {
+	l, err := net.Listen("tcp", fmt.Sprintf(":%d", opts.GRPC_port))
+	if err != nil {
+		return nil, fmt.Errorf("server start failed: %w", err)
+	}
+
+	authenticator := authenticator{keys: opts.APIKeys}
+	server := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(unaryLogger, authenticator.unary, attribute, deprecation),
+		grpc.ChainStreamInterceptor(streamLogger, authenticator.stream),
+	)
+	a := &Adapter{
+		s:             server,
+		l:             l,
+		health:        health.NewServer(),
+		segmentSvc:    segmentService,
+		healthChecker: healthChecker,
+	}
+	a.health.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	segmentationv1.RegisterSegmentationServiceServer(server, a)
+	healthpb.RegisterHealthServer(server, a.health)
+	reflection.Register(server)
+	return a, nil
+}
+
+// Start starts a grpc server that accepts incoming connections on the Listener in the background.
+func (a *Adapter) Start() error {
+	logger.Get().Info("starting grpc server...")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a.stopHealth = cancel
+	a.checkHealth(ctx)
+	a.eg.Go(func() error {
+		a.watchHealth(ctx)
+		return nil
+	})
+	a.eg.Go(func() error {
+		return a.s.Serve(a.l)
+	})
+	return nil
+}
+
+// Stop marks the server as not serving and gracefully stops it. If ctx expires before all the active
+// calls finish, the remaining ones are cancelled.
+func (a *Adapter) Stop(ctx context.Context) error {
+	var err error
+	a.stopOnce.Do(func() {
+		if a.stopHealth != nil {
+			a.stopHealth()
+		}
+		a.health.Shutdown()
+
+		done := make(chan struct{})
+		go func() {
+			a.s.GracefulStop()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			a.s.Stop()
+		}
+		err = a.eg.Wait()
+	})
+	return err
+}
+
+// watchHealth periodically pings the storage until ctx is cancelled.
+func (a *Adapter) watchHealth(ctx context.Context) {
+	ticker := time.NewTicker(healthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			a.checkHealth(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// checkHealth pings the storage and reports the result through the health service.
+func (a *Adapter) checkHealth(ctx context.Context) {
+	status := healthpb.HealthCheckResponse_SERVING
+	pingCtx, cancel := context.WithTimeout(ctx, healthCheckInterval)
+	defer cancel()
+	if err := a.healthChecker.Ping(pingCtx); err != nil {
+		logger.Get().WarnContext(ctx, "health check failed", "desc", err.Error())
+		status = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	if ctx.Err() != nil {
+		return
+	}
+	a.health.SetServingStatus("", status)
+	a.health.SetServingStatus(segmentationv1.SegmentationService_ServiceDesc.ServiceName, status)
+}
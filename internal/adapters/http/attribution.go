@@ -0,0 +1,45 @@
+package http
+
+import (
+	"fmt"
+	"net/url"
+	"segmentation-service/internal/adapters/auth"
+	"segmentation-service/internal/domain/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// The headers attributing the changes made by the request. The values may be percent-encoded, so that a reason
+// can contain any text.
+const (
+	actorHeader  = "X-Actor"
+	sourceHeader = "X-Source"
+	reasonHeader = "X-Reason"
+)
+
+// attribute stores the actor, the source and the reason of the request in its context, to be written to the report
+// rows of its changes. The authenticated caller is the actor, the X-Actor header is used only without authentication.
+func (a *Adapter) attribute(ctx *gin.Context) {
+	var by models.Attribution
+	for _, h := range []struct {
+		name string
+		dst  *string
+	}{{actorHeader, &by.Actor}, {sourceHeader, &by.Source}, {reasonHeader, &by.Reason}} {
+		value, err := url.PathUnescape(ctx.GetHeader(h.name))
+		if err != nil {
+			a.ErrorHandler(ctx, fmt.Errorf("%w: invalid %s header", models.ErrInvalidAttribution, h.name))
+			ctx.Abort()
+			return
+		}
+		*h.dst = value
+	}
+	if identity := auth.Identity(ctx.Request.Context()); identity != "" {
+		by.Actor = identity
+	}
+	if err := by.Validate(); err != nil {
+		a.ErrorHandler(ctx, err)
+		ctx.Abort()
+		return
+	}
+	ctx.Request = ctx.Request.WithContext(models.WithAttribution(ctx.Request.Context(), by))
+}
@@ -0,0 +1,31 @@
+package http
+
+import (
+	"segmentation-service/internal/adapters/auth"
+	"segmentation-service/internal/domain/models"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiKeyHeader is the header carrying the API key, the key can also be sent as a bearer token.
+const apiKeyHeader = "X-API-Key"
+
+// authenticate rejects the requests without a known API key and stores the name of the caller in the request context.
+// Nothing is checked if no keys are configured.
+func (a *Adapter) authenticate(ctx *gin.Context) {
+	if !a.apiKeys.Enabled() {
+		return
+	}
+	key := ctx.GetHeader(apiKeyHeader)
+	if key == "" {
+		key, _ = strings.CutPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+	}
+	name, ok := a.apiKeys.Identify(key)
+	if !ok {
+		a.ErrorHandler(ctx, models.ErrUnauthorized)
+		ctx.Abort()
+		return
+	}
+	ctx.Request = ctx.Request.WithContext(auth.WithIdentity(ctx.Request.Context(), name))
+}
@@ -0,0 +1,97 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"segmentation-service/internal/adapters/auth"
+	"segmentation-service/internal/domain/models"
+	"segmentation-service/internal/ports/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+)
+
+func TestAuthentication(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	keys, err := auth.ParseKeys([]string{"ops:secret"})
+	require.NoError(t, err)
+	svc := mocks.NewMockSegmentService(ctrl)
+	a, err := New(svc, mocks.NewMockHealthChecker(ctrl), AdapterOptions{HTTP_port: 0, Timeout: 10 * time.Second, APIKeys: keys})
+	require.NoError(t, err)
+	defer a.l.Close()
+	r := GetRouter()
+
+	// prepare test data
+	testCases := []struct {
+		name            string
+		header          string
+		value           string
+		useMock         bool
+		expStatusCode   int
+		expResponseBody string
+	}{
+		{
+			name:            "OK - api key",
+			header:          apiKeyHeader,
+			value:           "secret",
+			useMock:         true,
+			expStatusCode:   200,
+			expResponseBody: `{"segments":["TEST1"]}`,
+		},
+		{
+			name:            "OK - bearer token",
+			header:          "Authorization",
+			value:           "Bearer secret",
+			useMock:         true,
+			expStatusCode:   200,
+			expResponseBody: `{"segments":["TEST1"]}`,
+		},
+		{
+			name:            "Missing key",
+			expStatusCode:   401,
+			expResponseBody: `{"error":"missing or invalid API key"}`,
+		},
+		{
+			name:            "Invalid key",
+			header:          apiKeyHeader,
+			value:           "wrong",
+			expStatusCode:   401,
+			expResponseBody: `{"error":"missing or invalid API key"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.useMock {
+				svc.EXPECT().ListSegments(gomock.Any()).DoAndReturn(func(ctx context.Context) (models.SegmentsList, error) {
+					assert.Equal(t, "ops", auth.Identity(ctx))
+					return models.SegmentsList{S: []string{"TEST1"}}, nil
+				})
+			}
+
+			// create and execute request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/listSegments", nil)
+			if tc.header != "" {
+				req.Header.Set(tc.header, tc.value)
+			}
+			r.ServeHTTP(w, req)
+
+			// check response
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponseBody, w.Body.String())
+		})
+	}
+
+	// the probes don't require a key
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, 200, w.Code)
+}
@@ -0,0 +1,63 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"segmentation-service/internal/domain/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// deprecation collects the old slugs of renamed segments used by the request and, if there are any, marks
+// the response with the Deprecation, Sunset and Warning headers, so that the clients can switch to the new slugs
+// before the aliases expire.
+func (a *Adapter) deprecation(ctx *gin.Context) {
+	reqCtx, deprecated := models.WithDeprecatedSlugs(ctx.Request.Context())
+	ctx.Request = ctx.Request.WithContext(reqCtx)
+	ctx.Writer = &deprecationWriter{ResponseWriter: ctx.Writer, deprecated: deprecated}
+}
+
+// deprecationWriter adds the deprecation headers right before the status is written, when the handler has already
+// resolved the slugs.
+type deprecationWriter struct {
+	gin.ResponseWriter
+	deprecated *models.DeprecatedSlugs
+	done       bool
+}
+
+func (w *deprecationWriter) WriteHeader(code int) {
+	w.setHeaders()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *deprecationWriter) Write(data []byte) (int, error) {
+	w.setHeaders()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *deprecationWriter) WriteString(s string) (int, error) {
+	w.setHeaders()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *deprecationWriter) setHeaders() {
+	if w.done || w.ResponseWriter.Written() {
+		return
+	}
+	aliases := w.deprecated.List()
+	if len(aliases) == 0 {
+		return
+	}
+	w.done = true
+
+	header := w.Header()
+	header.Set("Deprecation", "true")
+	sunset := aliases[0].ExpiresAt
+	for _, alias := range aliases {
+		if alias.ExpiresAt.Before(sunset) {
+			sunset = alias.ExpiresAt
+		}
+		header.Add("Warning", fmt.Sprintf(`299 - "segment slug '%s' is deprecated, use '%s'"`, alias.Alias, alias.Slug))
+	}
+	header.Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+}
@@ -10,21 +10,44 @@ import (
 )
 
 func (a *Adapter) ErrorHandler(ctx *gin.Context, err error) {
-	logger.Get().Warn("request failed: ", "desc", err.Error())
+	logger.Get().WarnContext(ctx.Request.Context(), "request failed: ", "desc", err.Error())
 
 	switch {
 	case errors.Is(err, models.ErrInvalidSlugFormat), errors.Is(err, models.ErrInvalidUuidFormat),
 		errors.Is(err, models.ErrInvalidPeriodFormat), errors.Is(err, models.ErrBadRequest),
-		errors.Is(err, models.ErrSegmentAlreadyExists):
+		errors.Is(err, models.ErrSegmentAlreadyExists), errors.Is(err, models.ErrInvalidAction),
+		errors.Is(err, models.ErrInvalidColumns), errors.Is(err, models.ErrInvalidImportFile),
+		errors.Is(err, models.ErrInvalidArchive), errors.Is(err, models.ErrArchiveVersion),
+		errors.Is(err, models.ErrArchiveChecksum), errors.Is(err, models.ErrInvalidRestoreMode),
+		errors.Is(err, models.ErrInvalidRule), errors.Is(err, models.ErrInvalidAttributes),
+		errors.Is(err, models.ErrDynamicSegment), errors.Is(err, models.ErrInvalidExpression),
+		errors.Is(err, models.ErrCompositeCycle), errors.Is(err, models.ErrSegmentInUse),
+		errors.Is(err, models.ErrInvalidLimit), errors.Is(err, models.ErrInvalidCursor),
+		errors.Is(err, models.ErrInvalidExperiment), errors.Is(err, models.ErrExperimentExists),
+		errors.Is(err, models.ErrExperimentConflict), errors.Is(err, models.ErrSegmentInExperiment),
+		errors.Is(err, models.ErrInvalidRollout), errors.Is(err, models.ErrRolloutSegment),
+		errors.Is(err, models.ErrInvalidWindow), errors.Is(err, models.ErrSlugReserved),
+		errors.Is(err, models.ErrInvalidAliasDays), errors.Is(err, models.ErrInvalidAttribution),
+		errors.Is(err, models.ErrInvalidCap), errors.Is(err, models.ErrInvalidDateRange):
 		ctx.JSON(
 			http.StatusBadRequest,
 			models.ErrorResponse{ErrorMsg: err.Error()},
 		)
-	case errors.Is(err, models.ErrSegmentNotFound):
+	case errors.Is(err, models.ErrUnauthorized):
+		ctx.JSON(
+			http.StatusUnauthorized,
+			models.ErrorResponse{ErrorMsg: err.Error()},
+		)
+	case errors.Is(err, models.ErrSegmentNotFound), errors.Is(err, models.ErrExperimentNotFound):
 		ctx.JSON(
 			http.StatusNotFound,
 			models.ErrorResponse{ErrorMsg: err.Error()},
 		)
+	case errors.Is(err, models.ErrSegmentFull):
+		ctx.JSON(
+			http.StatusConflict,
+			models.ErrorResponse{ErrorMsg: err.Error()},
+		)
 	default:
 		ctx.JSON(
 			http.StatusInternalServerError,
@@ -1,26 +1,38 @@
 package http
 
 import (
+	"bytes"
 	"encoding/csv"
 	"fmt"
+	"io"
 	"net/http"
-	"regexp"
 	"segmentation-service/internal/domain/models"
 	"segmentation-service/pkg/infra/logger"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
+const (
+	maxImportSize  = 32 << 20  // limits the size of an uploaded csv file
+	maxArchiveSize = 512 << 20 // limits the size of an uploaded state archive
+)
+
 // @ID createSegment
 // @tags segment
 // @Summary Create a new segment
-// @Description Creates a new segment with the given slug. If this segment was already in the database, return the BadRequest status.
+// @Description Creates a new segment with the given slug. If this segment was already in the database, return the BadRequest status. If the rule is set, the segment is dynamic: its members are the users whose attributes match the rule. If the expression is set, the segment is composite: its members are computed from other segments. If the rollout is set, the segment is a percentage rollout: its members are computed from the hash of the user ID. If active_from or active_until is set, the segment is returned to its members only within that window. If max_members is set, the segment takes no more members than that.
 // @Accept json
-// @Param slug body models.Segment true "A short name containing only letters, numbers, underscores, or hyphens. Format: ^[\w-]+$"
+// @Param slug body models.Segment true "A short name containing only letters, numbers, underscores, or hyphens. Format: ^[\w-]+$. An optional rule over the user attributes, an expression over other segments or a rollout percentage, an optional activation window and an optional cap."
 // @Success 201 {object} models.SuccessResponse "Segment created successfully."
-// @Failure 400 {object} models.ErrorResponse "Segment already exists / missing required 'slug' parameter / invalid format of 'slug' parameter."
+// @Failure 400 {object} models.ErrorResponse "Segment already exists / missing required 'slug' parameter / invalid format of 'slug' parameter / invalid rule / invalid expression / invalid rollout / invalid activation window / invalid cap / the slug is reserved by a renamed segment."
+// @Failure 404 {object} models.ErrorResponse "A segment of the expression not found."
+// @Failure 401 {object} models.ErrorResponse "Missing or invalid API key."
 // @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Security ApiKeyAuth
 // @Router /createSegment [post]
 func (a *Adapter) createSegment(ctx *gin.Context) {
 	var segment models.Segment
@@ -29,13 +41,12 @@ func (a *Adapter) createSegment(ctx *gin.Context) {
 		a.ErrorHandler(ctx, models.ErrBadRequest)
 		return
 	}
-	matched, err := regexp.MatchString(`^[\w-]+$`, string(segment.Slug))
-	if err != nil || !matched {
+	if !models.SlugRegexp.MatchString(segment.Slug) {
 		a.ErrorHandler(ctx, models.ErrInvalidSlugFormat)
 		return
 	}
 
-	err = a.segmentSvc.CreateSegment(segment.Slug)
+	err = a.segmentSvc.CreateSegment(ctx.Request.Context(), segment)
 	if err != nil {
 		a.ErrorHandler(ctx, err)
 		return
@@ -54,9 +65,11 @@ func (a *Adapter) createSegment(ctx *gin.Context) {
 // @Accept json
 // @Param slug body models.Segment true "A short name containing only letters, numbers, underscores, or hyphens. Format: ^[\w-]+$"
 // @Success 200 {object} models.SuccessResponse "Segment deleted successfully."
-// @Failure 400 {object} models.ErrorResponse "Missing required 'slug' parameter / invalid format of 'slug' parameter."
+// @Failure 400 {object} models.ErrorResponse "Missing required 'slug' parameter / invalid format of 'slug' parameter / the segment is used by composite segments or an experiment group."
 // @Failure 404 {object} models.ErrorResponse "Segment with the given slug not found."
+// @Failure 401 {object} models.ErrorResponse "Missing or invalid API key."
 // @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Security ApiKeyAuth
 // @Router /deleteSegment [delete]
 func (a *Adapter) deleteSegment(ctx *gin.Context) {
 	var segment models.Segment
@@ -65,13 +78,12 @@ func (a *Adapter) deleteSegment(ctx *gin.Context) {
 		a.ErrorHandler(ctx, models.ErrBadRequest)
 		return
 	}
-	matched, err := regexp.MatchString(`^[\w-]+$`, string(segment.Slug))
-	if err != nil || !matched {
+	if !models.SlugRegexp.MatchString(segment.Slug) {
 		a.ErrorHandler(ctx, models.ErrInvalidSlugFormat)
 		return
 	}
 
-	err = a.segmentSvc.DeleteSegment(segment.Slug)
+	err = a.segmentSvc.DeleteSegment(ctx.Request.Context(), segment.Slug)
 	if err != nil {
 		a.ErrorHandler(ctx, err)
 		return
@@ -83,16 +95,223 @@ func (a *Adapter) deleteSegment(ctx *gin.Context) {
 	)
 }
 
+// @ID renameSegment
+// @tags segment
+// @Summary Rename a segment
+// @Description Changes the slug of the segment, keeping its members, experiment variants and history. The expressions of composite segments are rewritten to the new slug. The old slug keeps working as an alias for 'alias_days' days (30 by default, 0 - no alias): the requests using it get the Deprecation, Sunset and Warning headers. Afterwards the old slug stays reserved, so no other segment can take it.
+// @Accept json
+// @Produce json
+// @Param segment body models.RenameRequest true "Current and new slug of the segment and the alias lifetime in days, 0-365"
+// @Success 200 {object} models.SegmentAlias "Segment renamed, the old slug and its expiration time."
+// @Failure 400 {object} models.ErrorResponse "Missing required parameters / invalid format of 'slug' or 'alias_days' parameter / segment with the new slug already exists / the new slug is reserved by another renamed segment."
+// @Failure 404 {object} models.ErrorResponse "Segment with the given slug not found."
+// @Failure 401 {object} models.ErrorResponse "Missing or invalid API key."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Security ApiKeyAuth
+// @Router /renameSegment [post]
+func (a *Adapter) renameSegment(ctx *gin.Context) {
+	var req models.RenameRequest
+	err := ctx.BindJSON(&req)
+	if err != nil || req.Slug == "" || req.NewSlug == "" {
+		a.ErrorHandler(ctx, models.ErrBadRequest)
+		return
+	}
+
+	result, err := a.segmentSvc.RenameSegment(ctx.Request.Context(), req)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// @ID setSegmentRule
+// @tags segment
+// @Summary Set the rule of a segment
+// @Description Sets the rule over the user attributes that computes the members of the segment, and immediately adds the matching users and removes the others. An empty rule turns the segment back into an ordinary one, keeping its members.
+// @Accept json
+// @Produce json
+// @Param segment body models.Segment true "Slug of the segment and its rule"
+// @Success 200 {object} models.SyncResult "Rule set, the number of added and removed members."
+// @Failure 400 {object} models.ErrorResponse "Missing required 'slug' parameter / invalid format of 'slug' parameter / invalid rule."
+// @Failure 404 {object} models.ErrorResponse "Segment with the given slug not found."
+// @Failure 401 {object} models.ErrorResponse "Missing or invalid API key."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Security ApiKeyAuth
+// @Router /setSegmentRule [post]
+func (a *Adapter) setSegmentRule(ctx *gin.Context) {
+	var segment models.Segment
+	err := ctx.BindJSON(&segment)
+	if err != nil {
+		a.ErrorHandler(ctx, models.ErrBadRequest)
+		return
+	}
+	if !models.SlugRegexp.MatchString(segment.Slug) {
+		a.ErrorHandler(ctx, models.ErrInvalidSlugFormat)
+		return
+	}
+
+	result, err := a.segmentSvc.SetSegmentRule(ctx.Request.Context(), segment.Slug, segment.Rule)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// @ID setSegmentExpression
+// @tags segment
+// @Summary Set the expression of a segment
+// @Description Sets the expression over other segments (union, intersect, except) that computes the members of the segment, and immediately adds the users of the set and removes the others. Later changes of the other segments flow into the segment. An empty expression turns the segment back into an ordinary one, keeping its members.
+// @Accept json
+// @Produce json
+// @Param segment body models.Segment true "Slug of the segment and its expression"
+// @Success 200 {object} models.SyncResult "Expression set, the number of added and removed members."
+// @Failure 400 {object} models.ErrorResponse "Missing required 'slug' parameter / invalid format of 'slug' parameter / invalid expression / the segments form a cycle."
+// @Failure 404 {object} models.ErrorResponse "The segment or a segment of the expression not found."
+// @Failure 401 {object} models.ErrorResponse "Missing or invalid API key."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Security ApiKeyAuth
+// @Router /setSegmentExpression [post]
+func (a *Adapter) setSegmentExpression(ctx *gin.Context) {
+	var segment models.Segment
+	err := ctx.BindJSON(&segment)
+	if err != nil {
+		a.ErrorHandler(ctx, models.ErrBadRequest)
+		return
+	}
+	if !models.SlugRegexp.MatchString(segment.Slug) {
+		a.ErrorHandler(ctx, models.ErrInvalidSlugFormat)
+		return
+	}
+
+	result, err := a.segmentSvc.SetSegmentExpression(ctx.Request.Context(), segment.Slug, segment.Expression)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// @ID setSegmentRollout
+// @tags segment
+// @Summary Set the rollout percentage of a segment
+// @Description Makes the segment a percentage rollout: its members are the given percentage of all users, computed on read from the hash of the salt and the user ID, so nothing is stored per user. The salt defaults to the slug and can't be changed later, so raising the percentage only adds users. Users added or removed through updateUserSegments or the import are explicit overrides and are written to the report. A missing rollout turns the segment back into an ordinary one, keeping only the explicitly added members.
+// @Accept json
+// @Produce json
+// @Param segment body models.Segment true "Slug of the segment, its rollout percentage from 0 to 100 and an optional salt"
+// @Success 200 {object} models.Segment "Rollout set, the segment with its percentage and salt."
+// @Failure 400 {object} models.ErrorResponse "Missing required 'slug' parameter / invalid format of 'slug' parameter / invalid rollout / the segment is used by composite segments or an experiment group."
+// @Failure 404 {object} models.ErrorResponse "Segment with the given slug not found."
+// @Failure 401 {object} models.ErrorResponse "Missing or invalid API key."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Security ApiKeyAuth
+// @Router /setSegmentRollout [post]
+func (a *Adapter) setSegmentRollout(ctx *gin.Context) {
+	var segment models.Segment
+	err := ctx.BindJSON(&segment)
+	if err != nil {
+		a.ErrorHandler(ctx, models.ErrBadRequest)
+		return
+	}
+	if !models.SlugRegexp.MatchString(segment.Slug) {
+		a.ErrorHandler(ctx, models.ErrInvalidSlugFormat)
+		return
+	}
+
+	result, err := a.segmentSvc.SetSegmentRollout(ctx.Request.Context(), segment.Slug, segment.Rollout, segment.Salt)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// @ID setSegmentWindow
+// @tags segment
+// @Summary Set the activation window of a segment
+// @Description Sets the time from which and until which the segment is active. Outside the window the segment isn't returned by getUserSegments, but its memberships are kept and can still be changed. A missing bound leaves the window open on that side, so a request without both bounds removes the window. The activations and deactivations are recorded in the audit log by the scheduler.
+// @Accept json
+// @Produce json
+// @Param segment body models.Segment true "Slug of the segment and its active_from and active_until in RFC 3339 format"
+// @Success 200 {object} models.SegmentState "Window set, the segment with its window and current state."
+// @Failure 400 {object} models.ErrorResponse "Missing required 'slug' parameter / invalid format of 'slug' parameter / invalid activation window."
+// @Failure 404 {object} models.ErrorResponse "Segment with the given slug not found."
+// @Failure 401 {object} models.ErrorResponse "Missing or invalid API key."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Security ApiKeyAuth
+// @Router /setSegmentWindow [post]
+func (a *Adapter) setSegmentWindow(ctx *gin.Context) {
+	var segment models.Segment
+	err := ctx.BindJSON(&segment)
+	if err != nil {
+		a.ErrorHandler(ctx, models.ErrBadRequest)
+		return
+	}
+	if !models.SlugRegexp.MatchString(segment.Slug) {
+		a.ErrorHandler(ctx, models.ErrInvalidSlugFormat)
+		return
+	}
+
+	result, err := a.segmentSvc.SetSegmentWindow(ctx.Request.Context(), segment.Slug, segment.ActiveFrom, segment.ActiveUntil)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// @ID setSegmentCap
+// @tags segment
+// @Summary Set the cap of a segment
+// @Description Sets the maximum number of members of the segment, a request without max_members removes the cap. Adding a user to a full segment is rejected with the Conflict status, and the rules, the expressions and the imports stop at the cap. The members above a lowered cap are kept. Reaching the cap is recorded in the audit log as the 'full' event.
+// @Accept json
+// @Produce json
+// @Param segment body models.Segment true "Slug of the segment and its max_members"
+// @Success 200 {object} models.SegmentState "Cap set, the segment with its state, cap and number of members."
+// @Failure 400 {object} models.ErrorResponse "Missing required 'slug' parameter / invalid format of 'slug' parameter / invalid cap / the segment is a percentage rollout."
+// @Failure 404 {object} models.ErrorResponse "Segment with the given slug not found."
+// @Failure 401 {object} models.ErrorResponse "Missing or invalid API key."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Security ApiKeyAuth
+// @Router /setSegmentCap [post]
+func (a *Adapter) setSegmentCap(ctx *gin.Context) {
+	var segment models.Segment
+	err := ctx.BindJSON(&segment)
+	if err != nil {
+		a.ErrorHandler(ctx, models.ErrBadRequest)
+		return
+	}
+	if !models.SlugRegexp.MatchString(segment.Slug) {
+		a.ErrorHandler(ctx, models.ErrInvalidSlugFormat)
+		return
+	}
+
+	result, err := a.segmentSvc.SetSegmentCap(ctx.Request.Context(), segment.Slug, segment.MaxMembers)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
 // @ID updateSegments
 // @tags segment
 // @Summary Update user segments
-// @Description Add/remove a user from segments in accordance with the transferred lists for adding and deleting.
+// @Description Add/remove a user from segments in accordance with the transferred lists for adding and deleting. In the strict mode (default) nothing is changed if some of the segments don't exist, and the error names them. The non-strict mode applies the existing segments and returns the diff of the user's memberships with the status of every requested segment (models.MembershipDiff). A dry run goes through all the checks and returns the diff without writing anything, the unknown segments are listed in the diff instead of failing. Adding the user to a segment that reached its cap fails in the strict mode and gets the segment_full status otherwise.
 // @Accept json
+// @Produce json
 // @Param userID path string true "User ID in uuid format" Format(uuid)
 // @Param segments body models.UpdateRequest true "segments"
-// @Success 200 {object} models.SuccessResponse "User information updated successfully."
-// @Failure 400 {object} models.ErrorResponse "Invalid format for parameter 'userID'."
+// @Param dry_run query bool false "Only compute the diff without applying it"
+// @Param strict query bool false "Fail if some of the segments don't exist, true by default"
+// @Success 200 {object} models.SuccessResponse "User information updated successfully / models.MembershipDiff for a dry run or in the non-strict mode."
+// @Failure 400 {object} models.ErrorResponse "Invalid format for parameter 'userID' / one of the segments is dynamic / the user is already in another variant of an experiment group."
+// @Failure 401 {object} models.ErrorResponse "Missing or invalid API key."
+// @Failure 404 {object} models.ErrorResponse "Some of the segments not found, the error names them."
+// @Failure 409 {object} models.ErrorResponse "One of the segments to add to is full, the error names it and its fill level."
 // @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Security ApiKeyAuth
 // @Router /updateUserSegments/{userID} [post]
 func (a *Adapter) updateSegments(ctx *gin.Context) {
 	user_id, err := a.getIdFromPath(ctx)
@@ -100,24 +319,149 @@ func (a *Adapter) updateSegments(ctx *gin.Context) {
 		a.ErrorHandler(ctx, err)
 		return
 	}
+	dryRun, err := a.getBoolFromQuery(ctx, "dry_run")
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	strict := true
+	if ctx.Query("strict") != "" {
+		if strict, err = a.getBoolFromQuery(ctx, "strict"); err != nil {
+			a.ErrorHandler(ctx, err)
+			return
+		}
+	}
 	var data models.UpdateRequest
 	err = ctx.BindJSON(&data)
 	if err != nil {
 		a.ErrorHandler(ctx, models.ErrBadRequest)
 		return
 	}
+	data.DryRun, data.NonStrict = dryRun, !strict
 
-	err = a.segmentSvc.UpdateUserSegments(data, user_id)
+	diff, err := a.segmentSvc.UpdateUserSegments(ctx.Request.Context(), data, user_id)
 	if err != nil {
 		a.ErrorHandler(ctx, err)
 		return
 	}
+	if data.DryRun || data.NonStrict {
+		ctx.JSON(http.StatusOK, diff)
+		return
+	}
 	ctx.JSON(
 		http.StatusOK,
 		models.SuccessResponse{SuccessMsg: fmt.Sprintf("segment information for user with userID = %v updated", user_id)},
 	)
 }
 
+// @ID importMemberships
+// @tags segment
+// @Summary Import memberships from a csv file
+// @Description Adds/removes users to/from segments in accordance with the lines of a csv file. Every line is either "user_id,segment,action" (action is 'add' or 'remove'), or, if the 'segment' parameter is set, a single user ID. A header line starting with "user_id" is skipped. Valid lines are applied in batches, invalid ones are returned in the per-line error report. The additions to a segment with a cap are applied in the order of the file until it is full, the rest are counted as capped.
+// @Accept text/csv
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file false "csv file, can also be sent as the request body"
+// @Param segment query string false "Segment to which all the user IDs of the file are applied"
+// @Param action query string false "Action for the 'segment' parameter: 'add' (default) or 'remove'"
+// @Param dry_run query bool false "Validate the file and compute the diff of the memberships without applying it"
+// @Success 200 {object} models.ImportResult "File processed, see the per-line errors and, for a dry run, the diff."
+// @Failure 400 {object} models.ErrorResponse "Missing file / invalid format of 'segment' or 'action' parameters."
+// @Failure 404 {object} models.ErrorResponse "Segment from the 'segment' parameter not found."
+// @Failure 401 {object} models.ErrorResponse "Missing or invalid API key."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Security ApiKeyAuth
+// @Router /importMemberships [post]
+func (a *Adapter) importMemberships(ctx *gin.Context) {
+	dryRun, err := a.getBoolFromQuery(ctx, "dry_run")
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	opts := models.ImportOptions{
+		Segment: ctx.Query("segment"),
+		Action:  ctx.Query("action"),
+		DryRun:  dryRun,
+	}
+
+	file, closeFile, err := a.getUploadedFile(ctx, maxImportSize)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	defer closeFile()
+
+	result, err := a.segmentSvc.ImportMemberships(ctx.Request.Context(), file, opts)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// @ID exportState
+// @tags backup
+// @Summary Export the service state
+// @Description Returns a gzip compressed versioned archive with all segments, current memberships and, optionally, the report log, read from one consistent snapshot.
+// @Produce application/gzip
+// @Param report query bool false "Include the report log"
+// @Success 200 "Archive file received successfully."
+// @Failure 400 {object} models.ErrorResponse "Invalid format of the 'report' parameter."
+// @Failure 401 {object} models.ErrorResponse "Missing or invalid API key."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Security ApiKeyAuth
+// @Router /exportState [get]
+func (a *Adapter) exportState(ctx *gin.Context) {
+	withReport, err := a.getBoolFromQuery(ctx, "report")
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+
+	// the archive is built in memory, so that a database error can still be returned as an error response
+	var buf bytes.Buffer
+	err = a.segmentSvc.ExportState(ctx.Request.Context(), &buf, withReport)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+
+	filename := fmt.Sprintf("segmentation-%s.json.gz", time.Now().Format("2006-01-02T15-04-05"))
+	ctx.Writer.Header().Set("Content-Disposition", "attachment;filename="+filename)
+	ctx.Data(http.StatusOK, "application/gzip", buf.Bytes())
+}
+
+// @ID importState
+// @tags backup
+// @Summary Import the service state
+// @Description Restores the service state from an archive created by the export after checking its version and checksum. The 'merge' mode adds the missing segments and memberships, the 'replace' mode makes segments and memberships exactly as in the archive and replaces the report log if the archive contains it.
+// @Accept application/gzip
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file false "Archive file, can also be sent as the request body"
+// @Param mode query string false "Restore mode: 'merge' (default) or 'replace'"
+// @Success 200 {object} models.RestoreResult "State restored successfully."
+// @Failure 400 {object} models.ErrorResponse "Missing file / invalid archive / unsupported version / checksum mismatch / invalid mode."
+// @Failure 401 {object} models.ErrorResponse "Missing or invalid API key."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Security ApiKeyAuth
+// @Router /importState [post]
+func (a *Adapter) importState(ctx *gin.Context) {
+	file, closeFile, err := a.getUploadedFile(ctx, maxArchiveSize)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	defer closeFile()
+
+	result, err := a.segmentSvc.ImportState(ctx.Request.Context(), file, ctx.Query("mode"))
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
 // @ID getSegments
 // @tags segment
 // @Summary Get user segments
@@ -125,7 +469,9 @@ func (a *Adapter) updateSegments(ctx *gin.Context) {
 // @Param userID path string true "User ID in uuid format" Format(uuid)
 // @Success 200 {object} models.SegmentsList "User segments received successfully."
 // @Failure 400 {object} models.ErrorResponse "Invalid format for parameter 'userID'."
+// @Failure 401 {object} models.ErrorResponse "Missing or invalid API key."
 // @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Security ApiKeyAuth
 // @Router /getUserSegments/{userID} [get]
 func (a *Adapter) getSegments(ctx *gin.Context) {
 	user_id, err := a.getIdFromPath(ctx)
@@ -133,7 +479,7 @@ func (a *Adapter) getSegments(ctx *gin.Context) {
 		a.ErrorHandler(ctx, err)
 		return
 	}
-	segments, err := a.segmentSvc.GetUserSegments(user_id)
+	segments, err := a.segmentSvc.GetUserSegments(ctx.Request.Context(), user_id)
 	if err != nil {
 		a.ErrorHandler(ctx, fmt.Errorf("database error: %w", err))
 		return
@@ -141,16 +487,349 @@ func (a *Adapter) getSegments(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, segments)
 }
 
+// @ID updateUserAttributes
+// @tags attributes
+// @Summary Update user attributes
+// @Description Merges the given attributes into the stored ones, a null value removes the attribute. Then the rules of all dynamic segments are evaluated for the user and the user is added to or removed from them.
+// @Accept json
+// @Produce json
+// @Param userID path string true "User ID in uuid format" Format(uuid)
+// @Param attributes body models.UserAttributes true "Attributes: strings, numbers, booleans or nulls"
+// @Success 200 {object} models.AttributesResult "Attributes updated, the resulting attributes and the changes of the dynamic segments."
+// @Failure 400 {object} models.ErrorResponse "Invalid format for parameter 'userID' / invalid attributes."
+// @Failure 401 {object} models.ErrorResponse "Missing or invalid API key."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Security ApiKeyAuth
+// @Router /updateUserAttributes/{userID} [post]
+func (a *Adapter) updateUserAttributes(ctx *gin.Context) {
+	userID, err := a.getIdFromPath(ctx)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	var data models.UserAttributes
+	err = ctx.BindJSON(&data)
+	if err != nil {
+		a.ErrorHandler(ctx, models.ErrBadRequest)
+		return
+	}
+
+	result, err := a.segmentSvc.UpdateUserAttributes(ctx.Request.Context(), userID, data.Attributes)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// @ID getUserAttributes
+// @tags attributes
+// @Summary Get user attributes
+// @Description Return the attributes of the user.
+// @Produce json
+// @Param userID path string true "User ID in uuid format" Format(uuid)
+// @Success 200 {object} models.UserAttributes "User attributes received successfully."
+// @Failure 400 {object} models.ErrorResponse "Invalid format for parameter 'userID'."
+// @Failure 401 {object} models.ErrorResponse "Missing or invalid API key."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Security ApiKeyAuth
+// @Router /getUserAttributes/{userID} [get]
+func (a *Adapter) getUserAttributes(ctx *gin.Context) {
+	userID, err := a.getIdFromPath(ctx)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	attrs, err := a.segmentSvc.GetUserAttributes(ctx.Request.Context(), userID)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, models.UserAttributes{Attributes: attrs})
+}
+
+// @ID listSegments
+// @tags segment
+// @Summary List segments
+// @Description Return the slugs of all segments in alphabetical order, and the activation window and state (scheduled, active or ended) of each segment, and the cap and number of members of the capped ones.
+// @Success 200 {object} models.SegmentsList "Segments received successfully."
+// @Failure 401 {object} models.ErrorResponse "Missing or invalid API key."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Security ApiKeyAuth
+// @Router /listSegments [get]
+func (a *Adapter) listSegments(ctx *gin.Context) {
+	segments, err := a.segmentSvc.ListSegments(ctx.Request.Context())
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, segments)
+}
+
+// @ID getSegmentMembers
+// @tags segment
+// @Summary List segment members
+// @Description Return a page of the members of the segment, ordered by user ID. Pass the 'next' value of the response as 'after' to get the next page.
+// @Produce json
+// @Param slug path string true "Slug of the segment"
+// @Param limit query int false "Maximum number of members, 1-10000" default(1000)
+// @Param after query string false "Return the members with user IDs greater than this one" Format(uuid)
+// @Success 200 {object} models.MembersList "Segment members received successfully."
+// @Failure 400 {object} models.ErrorResponse "Invalid format for parameter 'slug', 'limit' or 'after' / the segment is a percentage rollout, whose members aren't stored."
+// @Failure 404 {object} models.ErrorResponse "Segment with the given slug not found."
+// @Failure 401 {object} models.ErrorResponse "Missing or invalid API key."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Security ApiKeyAuth
+// @Router /segments/{slug}/members [get]
+func (a *Adapter) getSegmentMembers(ctx *gin.Context) {
+	slug := ctx.Param("slug")
+	if !models.SlugRegexp.MatchString(slug) {
+		a.ErrorHandler(ctx, models.ErrInvalidSlugFormat)
+		return
+	}
+	limit := models.DefaultMembersLimit
+	if value := ctx.Query("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > models.MaxMembersLimit {
+			a.ErrorHandler(ctx, models.ErrInvalidLimit)
+			return
+		}
+	}
+	var after uuid.UUID
+	if value := ctx.Query("after"); value != "" {
+		var err error
+		after, err = uuid.Parse(value)
+		if err != nil {
+			a.ErrorHandler(ctx, models.ErrInvalidCursor)
+			return
+		}
+	}
+
+	members, err := a.segmentSvc.GetSegmentMembers(ctx.Request.Context(), slug, after, limit)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, members)
+}
+
+// @ID getSegmentHistory
+// @tags segment
+// @Summary Get segment history
+// @Description Return the audit log of the segment: its creation, renames, changes of the rule, expression, rollout and activation window and its activations, with the old and new values and who made the changes. The events recorded under the previous slugs of the segment are included.
+// @Produce json
+// @Param slug path string true "Slug of the segment"
+// @Success 200 {object} models.SegmentHistory "Segment history received successfully."
+// @Failure 400 {object} models.ErrorResponse "Invalid format for parameter 'slug'."
+// @Failure 404 {object} models.ErrorResponse "Segment with the given slug not found."
+// @Failure 401 {object} models.ErrorResponse "Missing or invalid API key."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Security ApiKeyAuth
+// @Router /segments/{slug}/history [get]
+func (a *Adapter) getSegmentHistory(ctx *gin.Context) {
+	slug := ctx.Param("slug")
+	if !models.SlugRegexp.MatchString(slug) {
+		a.ErrorHandler(ctx, models.ErrInvalidSlugFormat)
+		return
+	}
+
+	history, err := a.segmentSvc.GetSegmentHistory(ctx.Request.Context(), slug)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, history)
+}
+
+// @ID getSegmentStats
+// @tags segment
+// @Summary Get segment statistics
+// @Description Return the number of the members of the segment and the numbers of the members added and removed on every day of the range, the days without changes included. The daily numbers are read from the daily rollups of the report. The range defaults to the last 30 days up to today and can't be longer than 366 days. For a percentage rollout only the explicitly added users are counted.
+// @Produce json
+// @Param slug path string true "Slug of the segment"
+// @Param from query string false "First day of the range, in the format 'yyyy-mm-dd'"
+// @Param to query string false "Last day of the range, in the format 'yyyy-mm-dd'"
+// @Success 200 {object} models.SegmentStats "Segment statistics received successfully."
+// @Failure 400 {object} models.ErrorResponse "Invalid format for parameter 'slug', 'from' or 'to' / the range is reversed or too long."
+// @Failure 404 {object} models.ErrorResponse "Segment with the given slug not found."
+// @Failure 401 {object} models.ErrorResponse "Missing or invalid API key."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Security ApiKeyAuth
+// @Router /segments/{slug}/stats [get]
+func (a *Adapter) getSegmentStats(ctx *gin.Context) {
+	slug := ctx.Param("slug")
+	if !models.SlugRegexp.MatchString(slug) {
+		a.ErrorHandler(ctx, models.ErrInvalidSlugFormat)
+		return
+	}
+	from, err := a.getDateFromQuery(ctx, "from")
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	to, err := a.getDateFromQuery(ctx, "to")
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+
+	stats, err := a.segmentSvc.GetSegmentStats(ctx.Request.Context(), slug, from, to)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, stats)
+}
+
+// @ID getServiceStats
+// @tags segment
+// @Summary Get service statistics
+// @Description Return all segments in alphabetical order with the numbers of their members, the number of segments and the total number of memberships. The numbers are read from counters maintained on every change, without counting the members. For a percentage rollout only the explicitly added users are counted.
+// @Produce json
+// @Success 200 {object} models.ServiceStats "Service statistics received successfully."
+// @Failure 401 {object} models.ErrorResponse "Missing or invalid API key."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Security ApiKeyAuth
+// @Router /stats [get]
+func (a *Adapter) getServiceStats(ctx *gin.Context) {
+	stats, err := a.segmentSvc.GetServiceStats(ctx.Request.Context())
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, stats)
+}
+
+// @ID createExperimentGroup
+// @tags experiment
+// @Summary Create an experiment group
+// @Description Creates a group of mutually exclusive segments, its variants, with weights. A user can be in one variant of the group only: adding them to another one through updateUserSegments or the import fails ('reject', the default) or removes them from the previous variant ('move'). The variants must be existing ordinary segments, and no user may be in two of them. The salt defaults to the name of the group.
+// @Accept json
+// @Produce json
+// @Param group body models.ExperimentGroup true "Name, salt, conflict policy and variants of the group"
+// @Success 201 {object} models.ExperimentGroup "Experiment group created."
+// @Failure 400 {object} models.ErrorResponse "Invalid experiment group / group already exists / a variant is dynamic, composite or in another group / a user is in several variants."
+// @Failure 404 {object} models.ErrorResponse "A segment of the group not found."
+// @Failure 401 {object} models.ErrorResponse "Missing or invalid API key."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Security ApiKeyAuth
+// @Router /createExperimentGroup [post]
+func (a *Adapter) createExperimentGroup(ctx *gin.Context) {
+	var group models.ExperimentGroup
+	if err := ctx.BindJSON(&group); err != nil {
+		a.ErrorHandler(ctx, models.ErrBadRequest)
+		return
+	}
+
+	group, err := a.segmentSvc.CreateExperimentGroup(ctx.Request.Context(), group)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, group)
+}
+
+// @ID deleteExperimentGroup
+// @tags experiment
+// @Summary Delete an experiment group
+// @Description Deletes the experiment group. Its segments and their members are kept.
+// @Accept json
+// @Param group body models.ExperimentGroup true "Name of the group, the other fields are ignored"
+// @Success 200 {object} models.SuccessResponse "Experiment group deleted."
+// @Failure 400 {object} models.ErrorResponse "Missing required 'name' parameter."
+// @Failure 404 {object} models.ErrorResponse "Experiment group not found."
+// @Failure 401 {object} models.ErrorResponse "Missing or invalid API key."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Security ApiKeyAuth
+// @Router /deleteExperimentGroup [delete]
+func (a *Adapter) deleteExperimentGroup(ctx *gin.Context) {
+	var group models.ExperimentGroup
+	if err := ctx.BindJSON(&group); err != nil || group.Name == "" {
+		a.ErrorHandler(ctx, models.ErrBadRequest)
+		return
+	}
+
+	if err := a.segmentSvc.DeleteExperimentGroup(ctx.Request.Context(), group.Name); err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(
+		http.StatusOK,
+		models.SuccessResponse{SuccessMsg: fmt.Sprintf("experiment group '%s' deleted", group.Name)},
+	)
+}
+
+// @ID listExperimentGroups
+// @tags experiment
+// @Summary List experiment groups
+// @Description Return all experiment groups in alphabetical order with their variants.
+// @Produce json
+// @Success 200 {object} models.ExperimentGroupsList "Experiment groups received successfully."
+// @Failure 401 {object} models.ErrorResponse "Missing or invalid API key."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Security ApiKeyAuth
+// @Router /listExperimentGroups [get]
+func (a *Adapter) listExperimentGroups(ctx *gin.Context) {
+	groups, err := a.segmentSvc.ListExperimentGroups(ctx.Request.Context())
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, groups)
+}
+
+// @ID assignExperiment
+// @tags experiment
+// @Summary Assign a user to an experiment group
+// @Description Adds the user to a variant of the experiment group. The variant is chosen by the weights and the hash of the user ID and the salt of the group, so the same user always gets the same variant. A user that is already in a variant of the group stays there. If the chosen variant reached its cap, the user isn't assigned.
+// @Accept json
+// @Produce json
+// @Param userID path string true "User ID in uuid format" Format(uuid)
+// @Param group body models.AssignRequest true "Name of the group"
+// @Success 200 {object} models.Assignment "The variant of the user, 'assigned' is false if the user had already been in it."
+// @Failure 400 {object} models.ErrorResponse "Invalid format for parameter 'userID' / missing required 'group' parameter."
+// @Failure 404 {object} models.ErrorResponse "Experiment group not found."
+// @Failure 409 {object} models.ErrorResponse "The variant of the user is full."
+// @Failure 401 {object} models.ErrorResponse "Missing or invalid API key."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Security ApiKeyAuth
+// @Router /assignExperiment/{userID} [post]
+func (a *Adapter) assignExperiment(ctx *gin.Context) {
+	userID, err := a.getIdFromPath(ctx)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	var req models.AssignRequest
+	if err = ctx.BindJSON(&req); err != nil || req.Group == "" {
+		a.ErrorHandler(ctx, models.ErrBadRequest)
+		return
+	}
+
+	result, err := a.segmentSvc.AssignExperiment(ctx.Request.Context(), req.Group, userID)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
 // @ID getReport
 // @tags report
 // @Summary Get report file
-// @Description Returns the history of events for the given month as a csv file.
+// @Description Returns the history of events for the given month as a csv file with the columns: user, segment, action, time, actor, source, reason.
 // @Accept json
 // @Produce text/csv
 // @Param period path string true "Month for which you want to display information, in the format 'yyyy-mm'"
+// @Param actor query string false "Only the changes made by this actor"
+// @Param source query string false "Only the changes from this source" Enums(api, import, ttl-reaper, auto-percent, rule)
+// @Param reason query string false "Only the changes whose reason contains this text, case-insensitively"
 // @Success 200 "Report file received successfully."
-// @Failure 400 {object} models.ErrorResponse "Invalid format for parameter 'period'."
+// @Failure 400 {object} models.ErrorResponse "Invalid format for parameter 'period' / Unknown source."
+// @Failure 401 {object} models.ErrorResponse "Missing or invalid API key."
 // @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Security ApiKeyAuth
 // @Router /getReport/{period} [get]
 func (a *Adapter) getReport(ctx *gin.Context) {
 	period, err := a.getPeriodFromPath(ctx)
@@ -164,7 +843,7 @@ func (a *Adapter) getReport(ctx *gin.Context) {
 	ctx.Writer.Header().Set("Content-Disposition", "attachment;filename=data.csv")
 	wr := csv.NewWriter(ctx.Writer)
 
-	records, err := a.segmentSvc.GetReport(period)
+	records, err := a.segmentSvc.GetReport(ctx.Request.Context(), period, reportFilter(ctx))
 	if err != nil {
 		a.ErrorHandler(ctx, err)
 		return
@@ -175,14 +854,19 @@ func (a *Adapter) getReport(ctx *gin.Context) {
 // @ID getUserReport
 // @tags report
 // @Summary Get a report file for a specific user
-// @Description Returns a specific user's history of events for the specified month as a csv file.
+// @Description Returns a specific user's history of events for the specified month as a csv file with the same columns as the full report.
 // @Accept json
 // @Produce text/csv
 // @Param period path string true "Month for which you want to display information, in the format 'yyyy-mm'"
 // @Param userID path string true "User ID in uuid format" Format(uuid)
+// @Param actor query string false "Only the changes made by this actor"
+// @Param source query string false "Only the changes from this source" Enums(api, import, ttl-reaper, auto-percent, rule)
+// @Param reason query string false "Only the changes whose reason contains this text, case-insensitively"
 // @Success 200 "Report file received successfully."
-// @Failure 400 {object} models.ErrorResponse "Invalid format for parameter 'period'."
+// @Failure 400 {object} models.ErrorResponse "Invalid format for parameter 'period' / Unknown source."
+// @Failure 401 {object} models.ErrorResponse "Missing or invalid API key."
 // @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Security ApiKeyAuth
 // @Router /getUserReport/{period}/{userID} [get]
 func (a *Adapter) getUserReport(ctx *gin.Context) {
 	period, err := a.getPeriodFromPath(ctx)
@@ -201,7 +885,73 @@ func (a *Adapter) getUserReport(ctx *gin.Context) {
 	ctx.Writer.Header().Set("Content-Disposition", "attachment;filename=userdata.csv")
 	wr := csv.NewWriter(ctx.Writer)
 
-	records, err := a.segmentSvc.GetUserReport(period, user_id)
+	records, err := a.segmentSvc.GetUserReport(ctx.Request.Context(), period, user_id, reportFilter(ctx))
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	wr.WriteAll(records)
+}
+
+// @ID getSegmentReport
+// @tags report
+// @Summary Get the segment report file
+// @Description Returns the audit log of all segments, including the deleted ones, for the given month as a csv file with the columns: segment, event, old value, new value, time, actor, source, reason.
+// @Accept json
+// @Produce text/csv
+// @Param period path string true "Month for which you want to display information, in the format 'yyyy-mm'"
+// @Success 200 "Report file received successfully."
+// @Failure 400 {object} models.ErrorResponse "Invalid format for parameter 'period'."
+// @Failure 401 {object} models.ErrorResponse "Missing or invalid API key."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Security ApiKeyAuth
+// @Router /getSegmentReport/{period} [get]
+func (a *Adapter) getSegmentReport(ctx *gin.Context) {
+	period, err := a.getPeriodFromPath(ctx)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+
+	// set multiple http headers so that the browser responds by downloading the CSV file
+	ctx.Writer.Header().Set("Content-Type", "text/csv")
+	ctx.Writer.Header().Set("Content-Disposition", "attachment;filename=segments.csv")
+	wr := csv.NewWriter(ctx.Writer)
+
+	records, err := a.segmentSvc.GetSegmentReport(ctx.Request.Context(), period)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+	wr.WriteAll(records)
+}
+
+// @ID getAggregateReport
+// @tags report
+// @Summary Get the aggregate report file
+// @Description Returns the numbers of the members added to and removed from every segment on every day of the given month with changes as a csv file with the columns: segment, date, added, removed. The numbers are read from the daily rollups of the report rather than from its rows.
+// @Accept json
+// @Produce text/csv
+// @Param period path string true "Month for which you want to display information, in the format 'yyyy-mm'"
+// @Success 200 "Report file received successfully."
+// @Failure 400 {object} models.ErrorResponse "Invalid format for parameter 'period'."
+// @Failure 401 {object} models.ErrorResponse "Missing or invalid API key."
+// @Failure 500 {object} models.ErrorResponse "Database error / Internal Server Error."
+// @Security ApiKeyAuth
+// @Router /getAggregateReport/{period} [get]
+func (a *Adapter) getAggregateReport(ctx *gin.Context) {
+	period, err := a.getPeriodFromPath(ctx)
+	if err != nil {
+		a.ErrorHandler(ctx, err)
+		return
+	}
+
+	// set multiple http headers so that the browser responds by downloading the CSV file
+	ctx.Writer.Header().Set("Content-Type", "text/csv")
+	ctx.Writer.Header().Set("Content-Disposition", "attachment;filename=aggregate.csv")
+	wr := csv.NewWriter(ctx.Writer)
+
+	records, err := a.segmentSvc.GetAggregateReport(ctx.Request.Context(), period)
 	if err != nil {
 		a.ErrorHandler(ctx, err)
 		return
@@ -209,8 +959,13 @@ func (a *Adapter) getUserReport(ctx *gin.Context) {
 	wr.WriteAll(records)
 }
 
+// reportFilter reads the attribution the report rows are selected by from the query.
+func reportFilter(ctx *gin.Context) models.ReportFilter {
+	return models.ReportFilter{Actor: ctx.Query("actor"), Source: ctx.Query("source"), Reason: ctx.Query("reason")}
+}
+
 func (a *Adapter) getIdFromPath(ctx *gin.Context) (uuid.UUID, error) {
-	logger.Get().Debug("got parameter from path", "userID", ctx.Param("userID"))
+	logger.Get().DebugContext(ctx.Request.Context(), "got parameter from path", "userID", ctx.Param("userID"))
 	if ctx.Param("userID") == ":userID" { // if path-parameter is not set (this is how it works in Postman)
 		return uuid.Nil, models.ErrBadRequest
 	}
@@ -223,13 +978,55 @@ func (a *Adapter) getIdFromPath(ctx *gin.Context) (uuid.UUID, error) {
 
 func (a *Adapter) getPeriodFromPath(ctx *gin.Context) (string, error) {
 	period := ctx.Param("period")
-	logger.Get().Debug("got parameter from path", "period", period)
+	logger.Get().DebugContext(ctx.Request.Context(), "got parameter from path", "period", period)
 	if period == ":period" {
 		return "", models.ErrBadRequest
 	}
-	matched, err := regexp.MatchString(`^\d{4}-\d{2}$`, period)
-	if err != nil || !matched {
+	if !models.PeriodRegexp.MatchString(period) {
 		return "", models.ErrInvalidPeriodFormat
 	}
 	return period, nil
 }
+
+// getUploadedFile returns the file from the 'file' field of a multipart form or, if the request isn't a form,
+// the request body. The returned function closes the file.
+func (a *Adapter) getUploadedFile(ctx *gin.Context, maxSize int64) (io.Reader, func(), error) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxSize)
+	if !strings.HasPrefix(ctx.ContentType(), "multipart/form-data") {
+		return ctx.Request.Body, func() {}, nil
+	}
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		return nil, nil, models.ErrBadRequest
+	}
+	f, err := header.Open()
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, func() { f.Close() }, nil
+}
+
+// getDateFromQuery returns the day in the format 'yyyy-mm-dd' from the query, the zero time if it isn't given.
+func (a *Adapter) getDateFromQuery(ctx *gin.Context, name string) (time.Time, error) {
+	value := ctx.Query(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	date, err := time.Parse(models.DateLayout, value)
+	if err != nil {
+		return time.Time{}, models.ErrInvalidDateRange
+	}
+	return date, nil
+}
+
+func (a *Adapter) getBoolFromQuery(ctx *gin.Context, name string) (bool, error) {
+	value := ctx.Query(name)
+	if value == "" {
+		return false, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, models.ErrBadRequest
+	}
+	return parsed, nil
+}
@@ -2,12 +2,16 @@ package http
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"segmentation-service/internal/domain/models"
 	"segmentation-service/internal/ports/mocks"
+	"segmentation-service/pkg/infra/tracing"
 	"testing"
 	"time"
 
@@ -16,12 +20,15 @@ import (
 	"github.com/google/uuid"
 	"github.com/labstack/echo"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
 	"gotest.tools/assert"
 )
 
 var (
-	r   *gin.Engine
-	svc *mocks.MockSegmentService
+	r       *gin.Engine
+	svc     *mocks.MockSegmentService
+	health  *mocks.MockHealthChecker
+	adapter *Adapter
 )
 
 func TestInit(t *testing.T) {
@@ -30,7 +37,9 @@ func TestInit(t *testing.T) {
 	defer ctrl.Finish()
 
 	svc = mocks.NewMockSegmentService(ctrl)
-	_, err := New(svc, AdapterOptions{HTTP_port: 3030, Timeout: 10 * time.Second, IdleTimeout: 60 * time.Second})
+	health = mocks.NewMockHealthChecker(ctrl)
+	var err error
+	adapter, err = New(svc, health, AdapterOptions{HTTP_port: 3030, Timeout: 10 * time.Second, IdleTimeout: 60 * time.Second})
 	require.NoError(t, err)
 	r = GetRouter()
 }
@@ -50,7 +59,7 @@ func TestCreateSegment(t *testing.T) {
 			inputBody: `{"slug":"TEST"}`,
 			useMock:   true,
 			mockBehaviour: func(m *mocks.MockSegmentService) {
-				m.EXPECT().CreateSegment("TEST").Return(nil)
+				m.EXPECT().CreateSegment(gomock.Any(), models.Segment{Slug: "TEST"}).Return(nil)
 			},
 			expStatusCode:   201,
 			expResponseBody: `{"success":"segment with slug 'TEST' created"}`,
@@ -73,7 +82,7 @@ func TestCreateSegment(t *testing.T) {
 			inputBody: `{"slug":"TEST"}`,
 			useMock:   true,
 			mockBehaviour: func(m *mocks.MockSegmentService) {
-				m.EXPECT().CreateSegment("TEST").Return(models.ErrSegmentAlreadyExists)
+				m.EXPECT().CreateSegment(gomock.Any(), models.Segment{Slug: "TEST"}).Return(models.ErrSegmentAlreadyExists)
 			},
 			expStatusCode:   400,
 			expResponseBody: `{"error":"segment with this slug already exists"}`,
@@ -83,7 +92,7 @@ func TestCreateSegment(t *testing.T) {
 			inputBody: `{"slug":"TEST"}`,
 			useMock:   true,
 			mockBehaviour: func(m *mocks.MockSegmentService) {
-				m.EXPECT().CreateSegment("TEST").Return(errors.New("some error"))
+				m.EXPECT().CreateSegment(gomock.Any(), models.Segment{Slug: "TEST"}).Return(errors.New("some error"))
 			},
 			expStatusCode:   500,
 			expResponseBody: `{"error":"some error"}`,
@@ -126,7 +135,7 @@ func TestDeleteSegment(t *testing.T) {
 			inputBody: `{"slug":"TEST"}`,
 			useMock:   true,
 			mockBehaviour: func(m *mocks.MockSegmentService) {
-				m.EXPECT().DeleteSegment("TEST").Return(nil)
+				m.EXPECT().DeleteSegment(gomock.Any(), "TEST").Return(nil)
 			},
 			expStatusCode:   200,
 			expResponseBody: `{"success":"segment with slug 'TEST' deleted"}`,
@@ -149,7 +158,7 @@ func TestDeleteSegment(t *testing.T) {
 			inputBody: `{"slug":"TEST"}`,
 			useMock:   true,
 			mockBehaviour: func(m *mocks.MockSegmentService) {
-				m.EXPECT().DeleteSegment("TEST").Return(models.ErrSegmentNotFound)
+				m.EXPECT().DeleteSegment(gomock.Any(), "TEST").Return(models.ErrSegmentNotFound)
 			},
 			expStatusCode:   404,
 			expResponseBody: `{"error":"segment not found"}`,
@@ -159,7 +168,7 @@ func TestDeleteSegment(t *testing.T) {
 			inputBody: `{"slug":"TEST"}`,
 			useMock:   true,
 			mockBehaviour: func(m *mocks.MockSegmentService) {
-				m.EXPECT().DeleteSegment("TEST").Return(errors.New("some error"))
+				m.EXPECT().DeleteSegment(gomock.Any(), "TEST").Return(errors.New("some error"))
 			},
 			expStatusCode:   500,
 			expResponseBody: `{"error":"some error"}`,
@@ -193,6 +202,7 @@ func TestUpdateUserSegments(t *testing.T) {
 		name            string
 		inputBody       string
 		userID          string
+		query           string
 		data            models.UpdateRequest
 		useMock         bool
 		mockBehaviour   func(m *mocks.MockSegmentService, data models.UpdateRequest, userID string)
@@ -206,11 +216,80 @@ func TestUpdateUserSegments(t *testing.T) {
 			data:      models.UpdateRequest{SegmentsToAdd: []string{"TEST1", "TEST2"}, SegmentsToRemove: []string{}},
 			useMock:   true,
 			mockBehaviour: func(m *mocks.MockSegmentService, data models.UpdateRequest, userID string) {
-				m.EXPECT().UpdateUserSegments(data, uuid.MustParse(userID)).Return(nil)
+				m.EXPECT().UpdateUserSegments(gomock.Any(), data, uuid.MustParse(userID)).Return(models.MembershipDiff{}, nil)
 			},
 			expStatusCode:   200,
 			expResponseBody: `{"success":"segment information for user with userID = 550e8400-e29b-41d4-a716-446655440000 updated"}`,
 		},
+		{
+			name:      "Dry run",
+			inputBody: `{"segments-to-add":["TEST1", "UNKNOWN"],"segments-to-remove":["TEST2"]}`,
+			userID:    "550e8400-e29b-41d4-a716-446655440000",
+			query:     "?dry_run=true",
+			data:      models.UpdateRequest{SegmentsToAdd: []string{"TEST1", "UNKNOWN"}, SegmentsToRemove: []string{"TEST2"}, DryRun: true},
+			useMock:   true,
+			mockBehaviour: func(m *mocks.MockSegmentService, data models.UpdateRequest, userID string) {
+				m.EXPECT().UpdateUserSegments(gomock.Any(), data, uuid.MustParse(userID)).Return(models.MembershipDiff{
+					DryRun: true, Added: []string{"TEST1"}, Removed: []string{}, NoOp: []string{"TEST2"}, Unknown: []string{"UNKNOWN"},
+				}, nil)
+			},
+			expStatusCode:   200,
+			expResponseBody: `{"dry_run":true,"added":["TEST1"],"removed":[],"no_op":["TEST2"],"unknown":["UNKNOWN"],"results":null}`,
+		},
+		{
+			name:      "Non-strict mode",
+			inputBody: `{"segments-to-add":["TEST1", "UNKNOWN"],"segments-to-remove":[]}`,
+			userID:    "550e8400-e29b-41d4-a716-446655440000",
+			query:     "?strict=false",
+			data:      models.UpdateRequest{SegmentsToAdd: []string{"TEST1", "UNKNOWN"}, SegmentsToRemove: []string{}, NonStrict: true},
+			useMock:   true,
+			mockBehaviour: func(m *mocks.MockSegmentService, data models.UpdateRequest, userID string) {
+				m.EXPECT().UpdateUserSegments(gomock.Any(), data, uuid.MustParse(userID)).Return(models.MembershipDiff{
+					Added: []string{"TEST1"}, Removed: []string{}, NoOp: []string{}, Unknown: []string{"UNKNOWN"},
+					Results: []models.SegmentResult{
+						{Segment: "TEST1", Action: models.ActAdd, Status: models.StatusApplied},
+						{Segment: "UNKNOWN", Action: models.ActAdd, Status: models.StatusNotFound},
+					},
+				}, nil)
+			},
+			expStatusCode:   200,
+			expResponseBody: `{"dry_run":false,"added":["TEST1"],"removed":[],"no_op":[],"unknown":["UNKNOWN"],"results":[{"segment":"TEST1","action":"add","status":"applied"},{"segment":"UNKNOWN","action":"add","status":"not_found"}]}`,
+		},
+		{
+			name:      "Unknown segments in the strict mode",
+			inputBody: `{"segments-to-add":["TEST1", "UNKNOWN"],"segments-to-remove":["MISSING"]}`,
+			userID:    "550e8400-e29b-41d4-a716-446655440000",
+			data:      models.UpdateRequest{SegmentsToAdd: []string{"TEST1", "UNKNOWN"}, SegmentsToRemove: []string{"MISSING"}},
+			useMock:   true,
+			mockBehaviour: func(m *mocks.MockSegmentService, data models.UpdateRequest, userID string) {
+				m.EXPECT().UpdateUserSegments(gomock.Any(), data, uuid.MustParse(userID)).
+					Return(models.MembershipDiff{}, fmt.Errorf("%w: 'MISSING', 'UNKNOWN'", models.ErrSegmentNotFound))
+			},
+			expStatusCode:   404,
+			expResponseBody: `{"error":"segment not found: 'MISSING', 'UNKNOWN'"}`,
+		},
+		{
+			name:      "Segment full",
+			inputBody: `{"segments-to-add":["BETA"],"segments-to-remove":[]}`,
+			userID:    "550e8400-e29b-41d4-a716-446655440000",
+			data:      models.UpdateRequest{SegmentsToAdd: []string{"BETA"}, SegmentsToRemove: []string{}},
+			useMock:   true,
+			mockBehaviour: func(m *mocks.MockSegmentService, data models.UpdateRequest, userID string) {
+				m.EXPECT().UpdateUserSegments(gomock.Any(), data, uuid.MustParse(userID)).
+					Return(models.MembershipDiff{}, fmt.Errorf("%w: 'BETA' has 100 of 100 members", models.ErrSegmentFull))
+			},
+			expStatusCode:   409,
+			expResponseBody: `{"error":"segment is full: 'BETA' has 100 of 100 members"}`,
+		},
+		{
+			name:            "Invalid dry run flag",
+			inputBody:       `{"segments-to-add":["TEST1"],"segments-to-remove":[]}`,
+			userID:          "550e8400-e29b-41d4-a716-446655440000",
+			query:           "?dry_run=maybe",
+			useMock:         false,
+			expStatusCode:   400,
+			expResponseBody: `{"error":"missing required parameters"}`,
+		},
 		{
 			name:            "Invalid uuid format",
 			inputBody:       `{"segments-to-add":["TEST1", "TEST2"],"segments-to-remove":[]}`,
@@ -233,7 +312,7 @@ func TestUpdateUserSegments(t *testing.T) {
 			data:      models.UpdateRequest{SegmentsToAdd: []string{"TEST1", "TEST2"}, SegmentsToRemove: []string{}},
 			useMock:   true,
 			mockBehaviour: func(m *mocks.MockSegmentService, data models.UpdateRequest, userID string) {
-				m.EXPECT().UpdateUserSegments(data, uuid.MustParse(userID)).Return(errors.New("some error"))
+				m.EXPECT().UpdateUserSegments(gomock.Any(), data, uuid.MustParse(userID)).Return(models.MembershipDiff{}, errors.New("some error"))
 			},
 			expStatusCode:   500,
 			expResponseBody: `{"error":"some error"}`,
@@ -250,7 +329,7 @@ func TestUpdateUserSegments(t *testing.T) {
 
 			// create and execute request
 			w := httptest.NewRecorder()
-			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/updateUserSegments/%s", tc.userID), bytes.NewBufferString(tc.inputBody))
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/updateUserSegments/%s%s", tc.userID, tc.query), bytes.NewBufferString(tc.inputBody))
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 			r.ServeHTTP(w, req)
 
@@ -278,7 +357,7 @@ func TestGetUserSegments(t *testing.T) {
 			segments: models.SegmentsList{S: []string{"TEST1", "TEST2"}},
 			useMock:  true,
 			mockBehaviour: func(m *mocks.MockSegmentService, userID string, segments models.SegmentsList) {
-				m.EXPECT().GetUserSegments(uuid.MustParse(userID)).Return(segments, nil)
+				m.EXPECT().GetUserSegments(gomock.Any(), uuid.MustParse(userID)).Return(segments, nil)
 			},
 			expStatusCode:   200,
 			expResponseBody: `{"segments":["TEST1","TEST2"]}`,
@@ -303,7 +382,7 @@ func TestGetUserSegments(t *testing.T) {
 			segments: models.SegmentsList{S: []string{"TEST1", "TEST2"}},
 			useMock:  true,
 			mockBehaviour: func(m *mocks.MockSegmentService, userID string, segments models.SegmentsList) {
-				m.EXPECT().GetUserSegments(uuid.MustParse(userID)).Return(segments, errors.New("some error"))
+				m.EXPECT().GetUserSegments(gomock.Any(), uuid.MustParse(userID)).Return(segments, errors.New("some error"))
 			},
 			expStatusCode:   500,
 			expResponseBody: `{"error":"database error: some error"}`,
@@ -343,3 +422,1142 @@ func TestGetPeriodFromPath_Bad(t *testing.T) {
 	assert.Equal(t, 400, w.Code)
 	assert.Equal(t, `{"error":"invalid format of parameter 'period'"}`, w.Body.String())
 }
+
+func TestGetReport(t *testing.T) {
+	records := [][]string{{"550e8400-e29b-41d4-a716-446655440000", "TEST1", "add", "2023-08-01 12:00:00", "alice", "api", "promo, wave 2"}}
+
+	// the attribution filter is taken from the query
+	filter := models.ReportFilter{Actor: "alice", Source: "api", Reason: "wave"}
+	svc.EXPECT().GetReport(gomock.Any(), "2023-08", filter).Return(records, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/getReport/2023-08?actor=alice&source=api&reason=wave", nil))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "550e8400-e29b-41d4-a716-446655440000,TEST1,add,2023-08-01 12:00:00,alice,api,\"promo, wave 2\"\n", w.Body.String())
+
+	// an unknown source is rejected by the service
+	svc.EXPECT().GetReport(gomock.Any(), "2023-08", models.ReportFilter{Source: "cron"}).
+		Return(nil, fmt.Errorf("%w: unknown source 'cron'", models.ErrInvalidAttribution))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/getReport/2023-08?source=cron", nil))
+	assert.Equal(t, 400, w.Code)
+	assert.Equal(t, `{"error":"invalid actor, source or reason: unknown source 'cron'"}`, w.Body.String())
+}
+
+func TestGetSegmentHistory(t *testing.T) {
+	created := time.Date(2023, 8, 1, 12, 0, 0, 0, time.UTC)
+	history := models.SegmentHistory{Slug: "TEST1", Events: []models.SegmentEvent{
+		{Segment: "TEST1", Event: models.EventCreated, NewValue: "TEST1", Time: created, Actor: "ops", Source: models.SourceAPI},
+	}}
+
+	svc.EXPECT().GetSegmentHistory(gomock.Any(), "TEST1").Return(history, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/segments/TEST1/history", nil))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `{"slug":"TEST1","events":[{"segment":"TEST1","event":"created","old_value":"","new_value":"TEST1",`+
+		`"time":"2023-08-01T12:00:00Z","actor":"ops","source":"api","reason":""}]}`, w.Body.String())
+
+	svc.EXPECT().GetSegmentHistory(gomock.Any(), "TEST2").Return(models.SegmentHistory{}, models.ErrSegmentNotFound)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/segments/TEST2/history", nil))
+	assert.Equal(t, 404, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/segments/%23%20%25/history", nil))
+	assert.Equal(t, 400, w.Code)
+}
+
+func TestGetSegmentStats(t *testing.T) {
+	from, to := time.Date(2023, 8, 1, 0, 0, 0, 0, time.UTC), time.Date(2023, 8, 2, 0, 0, 0, 0, time.UTC)
+	stats := models.SegmentStats{Slug: "TEST1", Members: 10, From: "2023-08-01", To: "2023-08-02", Days: []models.DailyStats{
+		{Date: "2023-08-01", Added: 12, Removed: 2},
+		{Date: "2023-08-02"},
+	}}
+
+	svc.EXPECT().GetSegmentStats(gomock.Any(), "TEST1", from, to).Return(stats, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/segments/TEST1/stats?from=2023-08-01&to=2023-08-02", nil))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `{"slug":"TEST1","members":10,"from":"2023-08-01","to":"2023-08-02","days":[`+
+		`{"date":"2023-08-01","added":12,"removed":2},{"date":"2023-08-02","added":0,"removed":0}]}`, w.Body.String())
+
+	// the missing range is filled in by the service
+	svc.EXPECT().GetSegmentStats(gomock.Any(), "TEST1", time.Time{}, time.Time{}).Return(stats, nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/segments/TEST1/stats", nil))
+	assert.Equal(t, 200, w.Code)
+
+	svc.EXPECT().GetSegmentStats(gomock.Any(), "TEST1", to, from).
+		Return(models.SegmentStats{}, fmt.Errorf("%w: 'from' is after 'to'", models.ErrInvalidDateRange))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/segments/TEST1/stats?from=2023-08-02&to=2023-08-01", nil))
+	assert.Equal(t, 400, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/segments/TEST1/stats?from=01.08.2023", nil))
+	assert.Equal(t, 400, w.Code)
+	assert.Equal(t, `{"error":"invalid format of parameters 'from' and 'to'"}`, w.Body.String())
+
+	svc.EXPECT().GetSegmentStats(gomock.Any(), "TEST2", time.Time{}, time.Time{}).Return(models.SegmentStats{}, models.ErrSegmentNotFound)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/segments/TEST2/stats", nil))
+	assert.Equal(t, 404, w.Code)
+}
+
+func TestGetServiceStats(t *testing.T) {
+	limit := 100
+	stats := models.ServiceStats{Segments: 2, Memberships: 110, Sizes: []models.SegmentSize{
+		{Slug: "TEST1", Members: 10},
+		{Slug: "TEST2", Members: 100, MaxMembers: &limit},
+	}}
+
+	svc.EXPECT().GetServiceStats(gomock.Any()).Return(stats, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/stats", nil))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `{"segments":2,"memberships":110,"sizes":[{"slug":"TEST1","members":10},`+
+		`{"slug":"TEST2","members":100,"max_members":100}]}`, w.Body.String())
+}
+
+func TestGetSegmentReport(t *testing.T) {
+	records := [][]string{{"TEST1", "rule_changed", "", `city == "Moscow"`, "2023-08-01 15:00:00", "ops", "api", ""}}
+	svc.EXPECT().GetSegmentReport(gomock.Any(), "2023-08").Return(records, nil)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/getSegmentReport/2023-08", nil))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+	assert.Equal(t, "TEST1,rule_changed,,\"city == \"\"Moscow\"\"\",2023-08-01 15:00:00,ops,api,\n", w.Body.String())
+}
+
+func TestGetAggregateReport(t *testing.T) {
+	records := [][]string{{"TEST1", "2023-08-01", "12", "2"}, {"TEST2", "2023-08-01", "0", "1"}}
+	svc.EXPECT().GetAggregateReport(gomock.Any(), "2023-08").Return(records, nil)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/getAggregateReport/2023-08", nil))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+	assert.Equal(t, "TEST1,2023-08-01,12,2\nTEST2,2023-08-01,0,1\n", w.Body.String())
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/getAggregateReport/08-2023", nil))
+	assert.Equal(t, 400, w.Code)
+}
+
+func TestAttribution(t *testing.T) {
+	userID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440000")
+	data := models.UpdateRequest{SegmentsToAdd: []string{"TEST1"}, SegmentsToRemove: []string{}}
+
+	// the attribution headers are passed to the service in the context, the reason may be percent-encoded
+	svc.EXPECT().UpdateUserSegments(gomock.Any(), data, userID).DoAndReturn(
+		func(ctx context.Context, _ models.UpdateRequest, _ uuid.UUID) (models.MembershipDiff, error) {
+			assert.Equal(t, models.Attribution{Actor: "alice", Source: "import", Reason: "акция"}, models.AttributionFrom(ctx))
+			return models.MembershipDiff{}, nil
+		})
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/updateUserSegments/"+userID.String(),
+		bytes.NewBufferString(`{"segments-to-add":["TEST1"],"segments-to-remove":[]}`))
+	req.Header.Set("X-Actor", "alice")
+	req.Header.Set("X-Source", "import")
+	req.Header.Set("X-Reason", "%D0%B0%D0%BA%D1%86%D0%B8%D1%8F")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+
+	// without headers the change is made through the API by nobody in particular
+	svc.EXPECT().UpdateUserSegments(gomock.Any(), data, userID).DoAndReturn(
+		func(ctx context.Context, _ models.UpdateRequest, _ uuid.UUID) (models.MembershipDiff, error) {
+			assert.Equal(t, models.Attribution{Source: models.SourceAPI}, models.AttributionFrom(ctx))
+			return models.MembershipDiff{}, nil
+		})
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/updateUserSegments/"+userID.String(),
+		bytes.NewBufferString(`{"segments-to-add":["TEST1"],"segments-to-remove":[]}`)))
+	assert.Equal(t, 200, w.Code)
+
+	// an unknown source is rejected before the service is called
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/updateUserSegments/"+userID.String(),
+		bytes.NewBufferString(`{"segments-to-add":["TEST1"],"segments-to-remove":[]}`))
+	req.Header.Set("X-Source", "cron")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, 400, w.Code)
+	assert.Equal(t, `{"error":"invalid actor, source or reason: unknown source 'cron'"}`, w.Body.String())
+}
+
+func TestTracePropagation(t *testing.T) {
+	shutdown, err := tracing.New(tracing.TracingOptions{Exporter: tracing.ExporterNone})
+	require.NoError(t, err)
+	defer shutdown(context.Background())
+
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	// the context passed to the service must contain a child of the span from the traceparent header
+	svc.EXPECT().GetUserSegments(gomock.Any(), uuid.MustParse(userID)).DoAndReturn(
+		func(ctx context.Context, _ uuid.UUID) (models.SegmentsList, error) {
+			sc := trace.SpanContextFromContext(ctx)
+			assert.Equal(t, traceID, sc.TraceID().String())
+			assert.Assert(t, sc.SpanID().String() != "00f067aa0ba902b7")
+			return models.SegmentsList{}, nil
+		})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/getUserSegments/%s", userID), nil)
+	req.Header.Set("traceparent", fmt.Sprintf("00-%s-00f067aa0ba902b7-01", traceID))
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+}
+
+func TestImportMemberships(t *testing.T) {
+	file := "550e8400-e29b-41d4-a716-446655440000,TEST1,add\n"
+	result := models.ImportResult{Total: 1, Valid: 1, Applied: 1, Errors: []models.ImportLineError{}}
+
+	// prepare test data
+	testCases := []struct {
+		name            string
+		query           string
+		multipart       bool
+		useMock         bool
+		mockBehaviour   func(m *mocks.MockSegmentService)
+		expStatusCode   int
+		expResponseBody string
+	}{
+		{
+			name:    "OK - request body",
+			useMock: true,
+			mockBehaviour: func(m *mocks.MockSegmentService) {
+				m.EXPECT().ImportMemberships(gomock.Any(), gomock.Any(), models.ImportOptions{}).Return(result, nil)
+			},
+			expStatusCode:   200,
+			expResponseBody: `{"dry_run":false,"total":1,"valid":1,"applied":1,"errors":[]}`,
+		},
+		{
+			name:      "OK - multipart form",
+			query:     "?segment=TEST1&action=remove&dry_run=true",
+			multipart: true,
+			useMock:   true,
+			mockBehaviour: func(m *mocks.MockSegmentService) {
+				opts := models.ImportOptions{Segment: "TEST1", Action: "remove", DryRun: true}
+				m.EXPECT().ImportMemberships(gomock.Any(), gomock.Any(), opts).Return(models.ImportResult{DryRun: true, Errors: []models.ImportLineError{}}, nil)
+			},
+			expStatusCode:   200,
+			expResponseBody: `{"dry_run":true,"total":0,"valid":0,"applied":0,"errors":[]}`,
+		},
+		{
+			name:            "Invalid dry_run",
+			query:           "?dry_run=maybe",
+			useMock:         false,
+			expStatusCode:   400,
+			expResponseBody: `{"error":"missing required parameters"}`,
+		},
+		{
+			name:    "Segment not found",
+			query:   "?segment=TEST1",
+			useMock: true,
+			mockBehaviour: func(m *mocks.MockSegmentService) {
+				m.EXPECT().ImportMemberships(gomock.Any(), gomock.Any(), models.ImportOptions{Segment: "TEST1"}).Return(models.ImportResult{}, models.ErrSegmentNotFound)
+			},
+			expStatusCode:   404,
+			expResponseBody: `{"error":"segment not found"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// change the behavior of the mock service if necessary
+			if tc.useMock {
+				tc.mockBehaviour(svc)
+			}
+
+			// create and execute request
+			body, contentType := bytes.NewBufferString(file), "text/csv"
+			if tc.multipart {
+				body = &bytes.Buffer{}
+				mw := multipart.NewWriter(body)
+				fw, err := mw.CreateFormFile("file", "import.csv")
+				require.NoError(t, err)
+				fw.Write([]byte(file))
+				mw.Close()
+				contentType = mw.FormDataContentType()
+			}
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/importMemberships"+tc.query, body)
+			req.Header.Set(echo.HeaderContentType, contentType)
+			r.ServeHTTP(w, req)
+
+			// check response
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestExportState(t *testing.T) {
+	// prepare test data
+	testCases := []struct {
+		name            string
+		query           string
+		useMock         bool
+		mockBehaviour   func(m *mocks.MockSegmentService)
+		expStatusCode   int
+		expContentType  string
+		expResponseBody string
+	}{
+		{
+			name:    "OK",
+			query:   "?report=true",
+			useMock: true,
+			mockBehaviour: func(m *mocks.MockSegmentService) {
+				m.EXPECT().ExportState(gomock.Any(), gomock.Any(), true).DoAndReturn(func(ctx context.Context, w io.Writer, withReport bool) error {
+					_, err := w.Write([]byte("archive"))
+					return err
+				})
+			},
+			expStatusCode:   200,
+			expContentType:  "application/gzip",
+			expResponseBody: "archive",
+		},
+		{
+			name:            "Invalid report",
+			query:           "?report=maybe",
+			useMock:         false,
+			expStatusCode:   400,
+			expContentType:  "application/json; charset=utf-8",
+			expResponseBody: `{"error":"missing required parameters"}`,
+		},
+		{
+			name:    "Database error",
+			useMock: true,
+			mockBehaviour: func(m *mocks.MockSegmentService) {
+				m.EXPECT().ExportState(gomock.Any(), gomock.Any(), false).Return(fmt.Errorf("database error: %w", errors.New("connection refused")))
+			},
+			expStatusCode:   500,
+			expContentType:  "application/json; charset=utf-8",
+			expResponseBody: `{"error":"database error: connection refused"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// change the behavior of the mock service if necessary
+			if tc.useMock {
+				tc.mockBehaviour(svc)
+			}
+
+			// create and execute request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/exportState"+tc.query, nil)
+			r.ServeHTTP(w, req)
+
+			// check response
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expContentType, w.Header().Get(echo.HeaderContentType))
+			assert.Equal(t, tc.expResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestImportState(t *testing.T) {
+	result := models.RestoreResult{Mode: models.RestoreReplace, SegmentsCreated: 1, MembershipsAdded: 2}
+
+	// prepare test data
+	testCases := []struct {
+		name            string
+		query           string
+		mockBehaviour   func(m *mocks.MockSegmentService)
+		expStatusCode   int
+		expResponseBody string
+	}{
+		{
+			name:  "OK",
+			query: "?mode=replace",
+			mockBehaviour: func(m *mocks.MockSegmentService) {
+				m.EXPECT().ImportState(gomock.Any(), gomock.Any(), models.RestoreReplace).Return(result, nil)
+			},
+			expStatusCode:   200,
+			expResponseBody: `{"mode":"replace","segments_created":1,"segments_deleted":0,"memberships_added":2,"memberships_removed":0,"attributes_restored":0,"experiments_created":0,"aliases_restored":0,"report_restored":0}`,
+		},
+		{
+			name: "Checksum mismatch",
+			mockBehaviour: func(m *mocks.MockSegmentService) {
+				m.EXPECT().ImportState(gomock.Any(), gomock.Any(), "").Return(models.RestoreResult{}, models.ErrArchiveChecksum)
+			},
+			expStatusCode:   400,
+			expResponseBody: fmt.Sprintf(`{"error":"%s"}`, models.ErrArchiveChecksum),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mockBehaviour(svc)
+
+			// create and execute request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/importState"+tc.query, bytes.NewBufferString("archive"))
+			req.Header.Set(echo.HeaderContentType, "application/gzip")
+			r.ServeHTTP(w, req)
+
+			// check response
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestSetSegmentRule(t *testing.T) {
+	// prepare test data
+	testCases := []struct {
+		name            string
+		inputBody       string
+		useMock         bool
+		mockBehaviour   func(m *mocks.MockSegmentService)
+		expStatusCode   int
+		expResponseBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"slug":"TEST","rule":"age >= 18"}`,
+			useMock:   true,
+			mockBehaviour: func(m *mocks.MockSegmentService) {
+				m.EXPECT().SetSegmentRule(gomock.Any(), "TEST", "age >= 18").Return(models.SyncResult{Added: 3, Removed: 1}, nil)
+			},
+			expStatusCode:   200,
+			expResponseBody: `{"added":3,"removed":1}`,
+		},
+		{
+			name:            "Incorrect name",
+			inputBody:       `{"slug":"# %TEST","rule":"age >= 18"}`,
+			useMock:         false,
+			expStatusCode:   400,
+			expResponseBody: `{"error":"invalid format of parameter 'slug'"}`,
+		},
+		{
+			name:      "Invalid rule",
+			inputBody: `{"slug":"TEST","rule":"age >="}`,
+			useMock:   true,
+			mockBehaviour: func(m *mocks.MockSegmentService) {
+				m.EXPECT().SetSegmentRule(gomock.Any(), "TEST", "age >=").Return(models.SyncResult{}, fmt.Errorf("%w: unexpected end of rule", models.ErrInvalidRule))
+			},
+			expStatusCode:   400,
+			expResponseBody: `{"error":"invalid rule: unexpected end of rule"}`,
+		},
+		{
+			name:      "Segment not found",
+			inputBody: `{"slug":"TEST","rule":"age >= 18"}`,
+			useMock:   true,
+			mockBehaviour: func(m *mocks.MockSegmentService) {
+				m.EXPECT().SetSegmentRule(gomock.Any(), "TEST", "age >= 18").Return(models.SyncResult{}, models.ErrSegmentNotFound)
+			},
+			expStatusCode:   404,
+			expResponseBody: `{"error":"segment not found"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// change the behavior of the mock service if necessary
+			if tc.useMock {
+				tc.mockBehaviour(svc)
+			}
+
+			// create and execute request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/setSegmentRule", bytes.NewBufferString(tc.inputBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			r.ServeHTTP(w, req)
+
+			// check response
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestSetSegmentExpression(t *testing.T) {
+	// prepare test data
+	testCases := []struct {
+		name            string
+		inputBody       string
+		useMock         bool
+		mockBehaviour   func(m *mocks.MockSegmentService)
+		expStatusCode   int
+		expResponseBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"slug":"TEST","expression":"A union B"}`,
+			useMock:   true,
+			mockBehaviour: func(m *mocks.MockSegmentService) {
+				m.EXPECT().SetSegmentExpression(gomock.Any(), "TEST", "A union B").Return(models.SyncResult{Added: 2}, nil)
+			},
+			expStatusCode:   200,
+			expResponseBody: `{"added":2,"removed":0}`,
+		},
+		{
+			name:            "Incorrect name",
+			inputBody:       `{"slug":"# %TEST","expression":"A union B"}`,
+			useMock:         false,
+			expStatusCode:   400,
+			expResponseBody: `{"error":"invalid format of parameter 'slug'"}`,
+		},
+		{
+			name:      "Cycle",
+			inputBody: `{"slug":"TEST","expression":"A"}`,
+			useMock:   true,
+			mockBehaviour: func(m *mocks.MockSegmentService) {
+				m.EXPECT().SetSegmentExpression(gomock.Any(), "TEST", "A").Return(models.SyncResult{}, fmt.Errorf("%w: A -> TEST -> A", models.ErrCompositeCycle))
+			},
+			expStatusCode:   400,
+			expResponseBody: `{"error":"composite segments form a cycle: A -\u003e TEST -\u003e A"}`,
+		},
+		{
+			name:      "Segment not found",
+			inputBody: `{"slug":"TEST","expression":"A"}`,
+			useMock:   true,
+			mockBehaviour: func(m *mocks.MockSegmentService) {
+				m.EXPECT().SetSegmentExpression(gomock.Any(), "TEST", "A").Return(models.SyncResult{}, fmt.Errorf("%w: 'A'", models.ErrSegmentNotFound))
+			},
+			expStatusCode:   404,
+			expResponseBody: `{"error":"segment not found: 'A'"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// change the behavior of the mock service if necessary
+			if tc.useMock {
+				tc.mockBehaviour(svc)
+			}
+
+			// create and execute request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/setSegmentExpression", bytes.NewBufferString(tc.inputBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			r.ServeHTTP(w, req)
+
+			// check response
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestSetSegmentRollout(t *testing.T) {
+	percent := 25.0
+
+	// prepare test data
+	testCases := []struct {
+		name            string
+		inputBody       string
+		useMock         bool
+		mockBehaviour   func(m *mocks.MockSegmentService)
+		expStatusCode   int
+		expResponseBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"slug":"TEST","rollout":25}`,
+			useMock:   true,
+			mockBehaviour: func(m *mocks.MockSegmentService) {
+				m.EXPECT().SetSegmentRollout(gomock.Any(), "TEST", &percent, "").Return(models.Segment{Slug: "TEST", Rollout: &percent, Salt: "TEST"}, nil)
+			},
+			expStatusCode:   200,
+			expResponseBody: `{"slug":"TEST","rollout":25,"salt":"TEST"}`,
+		},
+		{
+			name:            "Incorrect name",
+			inputBody:       `{"slug":"# %TEST","rollout":25}`,
+			useMock:         false,
+			expStatusCode:   400,
+			expResponseBody: `{"error":"invalid format of parameter 'slug'"}`,
+		},
+		{
+			name:      "Invalid percentage",
+			inputBody: `{"slug":"TEST","rollout":250}`,
+			useMock:   true,
+			mockBehaviour: func(m *mocks.MockSegmentService) {
+				m.EXPECT().SetSegmentRollout(gomock.Any(), "TEST", gomock.Any(), "").
+					Return(models.Segment{}, fmt.Errorf("%w: percentage must be between 0 and 100", models.ErrInvalidRollout))
+			},
+			expStatusCode:   400,
+			expResponseBody: `{"error":"invalid rollout: percentage must be between 0 and 100"}`,
+		},
+		{
+			name:      "Segment not found",
+			inputBody: `{"slug":"TEST","rollout":25}`,
+			useMock:   true,
+			mockBehaviour: func(m *mocks.MockSegmentService) {
+				m.EXPECT().SetSegmentRollout(gomock.Any(), "TEST", &percent, "").Return(models.Segment{}, models.ErrSegmentNotFound)
+			},
+			expStatusCode:   404,
+			expResponseBody: `{"error":"segment not found"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// change the behavior of the mock service if necessary
+			if tc.useMock {
+				tc.mockBehaviour(svc)
+			}
+
+			// create and execute request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/setSegmentRollout", bytes.NewBufferString(tc.inputBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			r.ServeHTTP(w, req)
+
+			// check response
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestRenameSegment(t *testing.T) {
+	expires := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	// prepare test data
+	testCases := []struct {
+		name            string
+		inputBody       string
+		useMock         bool
+		mockBehaviour   func(m *mocks.MockSegmentService)
+		expStatusCode   int
+		expResponseBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"slug":"OLD","new_slug":"NEW"}`,
+			useMock:   true,
+			mockBehaviour: func(m *mocks.MockSegmentService) {
+				m.EXPECT().RenameSegment(gomock.Any(), models.RenameRequest{Slug: "OLD", NewSlug: "NEW"}).
+					Return(models.SegmentAlias{Alias: "OLD", Slug: "NEW", ExpiresAt: expires}, nil)
+			},
+			expStatusCode:   200,
+			expResponseBody: `{"alias":"OLD","slug":"NEW","expires_at":"2023-10-01T00:00:00Z"}`,
+		},
+		{
+			name:            "Missing new slug",
+			inputBody:       `{"slug":"OLD"}`,
+			useMock:         false,
+			expStatusCode:   400,
+			expResponseBody: `{"error":"missing required parameters"}`,
+		},
+		{
+			name:      "Reserved slug",
+			inputBody: `{"slug":"OLD","new_slug":"NEW"}`,
+			useMock:   true,
+			mockBehaviour: func(m *mocks.MockSegmentService) {
+				m.EXPECT().RenameSegment(gomock.Any(), gomock.Any()).Return(models.SegmentAlias{}, models.ErrSlugReserved)
+			},
+			expStatusCode:   400,
+			expResponseBody: `{"error":"slug is reserved by a renamed segment"}`,
+		},
+		{
+			name:      "Segment not found",
+			inputBody: `{"slug":"OLD","new_slug":"NEW"}`,
+			useMock:   true,
+			mockBehaviour: func(m *mocks.MockSegmentService) {
+				m.EXPECT().RenameSegment(gomock.Any(), gomock.Any()).Return(models.SegmentAlias{}, models.ErrSegmentNotFound)
+			},
+			expStatusCode:   404,
+			expResponseBody: `{"error":"segment not found"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// change the behavior of the mock service if necessary
+			if tc.useMock {
+				tc.mockBehaviour(svc)
+			}
+
+			// create and execute request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/renameSegment", bytes.NewBufferString(tc.inputBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			r.ServeHTTP(w, req)
+
+			// check response
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestDeprecatedSlugHeaders(t *testing.T) {
+	expires := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	// the service resolves the old slug and records it
+	svc.EXPECT().GetSegmentMembers(gomock.Any(), "OLD", uuid.Nil, models.DefaultMembersLimit).
+		DoAndReturn(func(ctx context.Context, slug string, after uuid.UUID, limit int) (models.MembersList, error) {
+			models.RecordDeprecatedSlug(ctx, models.SegmentAlias{Alias: "OLD", Slug: "NEW", ExpiresAt: expires})
+			return models.MembersList{Members: []uuid.UUID{}}, nil
+		})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/segments/OLD/members", nil))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "true", w.Header().Get("Deprecation"))
+	assert.Equal(t, "Sun, 01 Oct 2023 00:00:00 GMT", w.Header().Get("Sunset"))
+	assert.Equal(t, `299 - "segment slug 'OLD' is deprecated, use 'NEW'"`, w.Header().Get("Warning"))
+
+	// the current slug gets no deprecation headers
+	svc.EXPECT().GetSegmentMembers(gomock.Any(), "NEW", uuid.Nil, models.DefaultMembersLimit).Return(models.MembersList{Members: []uuid.UUID{}}, nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/segments/NEW/members", nil))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "", w.Header().Get("Deprecation"))
+}
+
+func TestSetSegmentWindow(t *testing.T) {
+	from := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	// prepare test data
+	testCases := []struct {
+		name            string
+		inputBody       string
+		useMock         bool
+		mockBehaviour   func(m *mocks.MockSegmentService)
+		expStatusCode   int
+		expResponseBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"slug":"TEST","active_from":"2023-09-01T00:00:00Z"}`,
+			useMock:   true,
+			mockBehaviour: func(m *mocks.MockSegmentService) {
+				m.EXPECT().SetSegmentWindow(gomock.Any(), "TEST", &from, nil).
+					Return(models.SegmentState{Slug: "TEST", State: models.StateActive, ActiveFrom: &from}, nil)
+			},
+			expStatusCode:   200,
+			expResponseBody: `{"slug":"TEST","state":"active","active_from":"2023-09-01T00:00:00Z"}`,
+		},
+		{
+			name:            "Invalid time",
+			inputBody:       `{"slug":"TEST","active_from":"tomorrow"}`,
+			useMock:         false,
+			expStatusCode:   400,
+			expResponseBody: `{"error":"missing required parameters"}`,
+		},
+		{
+			name:      "Window ends before it starts",
+			inputBody: `{"slug":"TEST","active_from":"2023-09-01T00:00:00Z","active_until":"2023-08-01T00:00:00Z"}`,
+			useMock:   true,
+			mockBehaviour: func(m *mocks.MockSegmentService) {
+				m.EXPECT().SetSegmentWindow(gomock.Any(), "TEST", &from, gomock.Any()).
+					Return(models.SegmentState{}, fmt.Errorf("%w: active_until must be after active_from", models.ErrInvalidWindow))
+			},
+			expStatusCode:   400,
+			expResponseBody: `{"error":"invalid activation window: active_until must be after active_from"}`,
+		},
+		{
+			name:      "Segment not found",
+			inputBody: `{"slug":"TEST"}`,
+			useMock:   true,
+			mockBehaviour: func(m *mocks.MockSegmentService) {
+				m.EXPECT().SetSegmentWindow(gomock.Any(), "TEST", nil, nil).Return(models.SegmentState{}, models.ErrSegmentNotFound)
+			},
+			expStatusCode:   404,
+			expResponseBody: `{"error":"segment not found"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// change the behavior of the mock service if necessary
+			if tc.useMock {
+				tc.mockBehaviour(svc)
+			}
+
+			// create and execute request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/setSegmentWindow", bytes.NewBufferString(tc.inputBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			r.ServeHTTP(w, req)
+
+			// check response
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestSetSegmentCap(t *testing.T) {
+	maxMembers, members := 100, 100
+
+	// prepare test data
+	testCases := []struct {
+		name            string
+		inputBody       string
+		useMock         bool
+		mockBehaviour   func(m *mocks.MockSegmentService)
+		expStatusCode   int
+		expResponseBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"slug":"TEST","max_members":100}`,
+			useMock:   true,
+			mockBehaviour: func(m *mocks.MockSegmentService) {
+				m.EXPECT().SetSegmentCap(gomock.Any(), "TEST", &maxMembers).
+					Return(models.SegmentState{Slug: "TEST", State: models.StateActive, MaxMembers: &maxMembers, Members: &members}, nil)
+			},
+			expStatusCode:   200,
+			expResponseBody: `{"slug":"TEST","state":"active","max_members":100,"members":100}`,
+		},
+		{
+			name:      "Remove cap",
+			inputBody: `{"slug":"TEST"}`,
+			useMock:   true,
+			mockBehaviour: func(m *mocks.MockSegmentService) {
+				m.EXPECT().SetSegmentCap(gomock.Any(), "TEST", nil).Return(models.SegmentState{Slug: "TEST", State: models.StateActive}, nil)
+			},
+			expStatusCode:   200,
+			expResponseBody: `{"slug":"TEST","state":"active"}`,
+		},
+		{
+			name:      "Invalid cap",
+			inputBody: `{"slug":"TEST","max_members":0}`,
+			useMock:   true,
+			mockBehaviour: func(m *mocks.MockSegmentService) {
+				m.EXPECT().SetSegmentCap(gomock.Any(), "TEST", gomock.Any()).
+					Return(models.SegmentState{}, fmt.Errorf("%w: max_members must be positive, got 0", models.ErrInvalidCap))
+			},
+			expStatusCode:   400,
+			expResponseBody: `{"error":"invalid cap: max_members must be positive, got 0"}`,
+		},
+		{
+			name:            "Invalid slug",
+			inputBody:       `{"slug":"TE ST","max_members":100}`,
+			useMock:         false,
+			expStatusCode:   400,
+			expResponseBody: `{"error":"invalid format of parameter 'slug'"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// change the behavior of the mock service if necessary
+			if tc.useMock {
+				tc.mockBehaviour(svc)
+			}
+
+			// create and execute request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/setSegmentCap", bytes.NewBufferString(tc.inputBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			r.ServeHTTP(w, req)
+
+			// check response
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestGetSegmentMembers(t *testing.T) {
+	userID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440000")
+
+	// prepare test data
+	testCases := []struct {
+		name            string
+		query           string
+		useMock         bool
+		mockBehaviour   func(m *mocks.MockSegmentService)
+		expStatusCode   int
+		expResponseBody string
+	}{
+		{
+			name:    "OK",
+			query:   "/TEST/members",
+			useMock: true,
+			mockBehaviour: func(m *mocks.MockSegmentService) {
+				m.EXPECT().GetSegmentMembers(gomock.Any(), "TEST", uuid.Nil, models.DefaultMembersLimit).Return(models.MembersList{Members: []uuid.UUID{userID}}, nil)
+			},
+			expStatusCode:   200,
+			expResponseBody: `{"members":["550e8400-e29b-41d4-a716-446655440000"]}`,
+		},
+		{
+			name:    "Next page",
+			query:   "/TEST/members?limit=1&after=00000000-0000-0000-0000-000000000001",
+			useMock: true,
+			mockBehaviour: func(m *mocks.MockSegmentService) {
+				m.EXPECT().GetSegmentMembers(gomock.Any(), "TEST", uuid.MustParse("00000000-0000-0000-0000-000000000001"), 1).
+					Return(models.MembersList{Members: []uuid.UUID{userID}, Next: userID.String()}, nil)
+			},
+			expStatusCode:   200,
+			expResponseBody: `{"members":["550e8400-e29b-41d4-a716-446655440000"],"next":"550e8400-e29b-41d4-a716-446655440000"}`,
+		},
+		{
+			name:            "Invalid limit",
+			query:           "/TEST/members?limit=0",
+			useMock:         false,
+			expStatusCode:   400,
+			expResponseBody: `{"error":"invalid format of parameter 'limit'"}`,
+		},
+		{
+			name:            "Limit too large",
+			query:           "/TEST/members?limit=10001",
+			useMock:         false,
+			expStatusCode:   400,
+			expResponseBody: `{"error":"invalid format of parameter 'limit'"}`,
+		},
+		{
+			name:            "Invalid cursor",
+			query:           "/TEST/members?after=123",
+			useMock:         false,
+			expStatusCode:   400,
+			expResponseBody: `{"error":"invalid format of parameter 'after'"}`,
+		},
+		{
+			name:    "Segment not found",
+			query:   "/TEST/members",
+			useMock: true,
+			mockBehaviour: func(m *mocks.MockSegmentService) {
+				m.EXPECT().GetSegmentMembers(gomock.Any(), "TEST", uuid.Nil, models.DefaultMembersLimit).Return(models.MembersList{}, models.ErrSegmentNotFound)
+			},
+			expStatusCode:   404,
+			expResponseBody: `{"error":"segment not found"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// change the behavior of the mock service if necessary
+			if tc.useMock {
+				tc.mockBehaviour(svc)
+			}
+
+			// create and execute request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/segments"+tc.query, nil)
+			r.ServeHTTP(w, req)
+
+			// check response
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestCreateExperimentGroup(t *testing.T) {
+	group := models.ExperimentGroup{
+		Name:     "EXP_X",
+		Variants: []models.Variant{{Slug: "EXP_X_CONTROL", Weight: 50}, {Slug: "EXP_X_VARIANT_A", Weight: 50}},
+	}
+	saved := group
+	saved.Salt, saved.Conflict = "EXP_X", models.ConflictReject
+
+	// prepare test data
+	testCases := []struct {
+		name            string
+		inputBody       string
+		useMock         bool
+		mockBehaviour   func(m *mocks.MockSegmentService)
+		expStatusCode   int
+		expResponseBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"name":"EXP_X","variants":[{"slug":"EXP_X_CONTROL","weight":50},{"slug":"EXP_X_VARIANT_A","weight":50}]}`,
+			useMock:   true,
+			mockBehaviour: func(m *mocks.MockSegmentService) {
+				m.EXPECT().CreateExperimentGroup(gomock.Any(), group).Return(saved, nil)
+			},
+			expStatusCode:   201,
+			expResponseBody: `{"name":"EXP_X","salt":"EXP_X","conflict":"reject","variants":[{"slug":"EXP_X_CONTROL","weight":50},{"slug":"EXP_X_VARIANT_A","weight":50}]}`,
+		},
+		{
+			name:            "Invalid body",
+			inputBody:       `{"name":"EXP_X","variants":{}}`,
+			useMock:         false,
+			expStatusCode:   400,
+			expResponseBody: `{"error":"missing required parameters"}`,
+		},
+		{
+			name:      "Users in several variants",
+			inputBody: `{"name":"EXP_X","variants":[{"slug":"EXP_X_CONTROL","weight":50},{"slug":"EXP_X_VARIANT_A","weight":50}]}`,
+			useMock:   true,
+			mockBehaviour: func(m *mocks.MockSegmentService) {
+				m.EXPECT().CreateExperimentGroup(gomock.Any(), group).Return(saved, models.ErrExperimentConflict)
+			},
+			expStatusCode:   400,
+			expResponseBody: `{"error":"user is already in another variant of the experiment group"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// change the behavior of the mock service if necessary
+			if tc.useMock {
+				tc.mockBehaviour(svc)
+			}
+
+			// create and execute request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/createExperimentGroup", bytes.NewBufferString(tc.inputBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			r.ServeHTTP(w, req)
+
+			// check response
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestAssignExperiment(t *testing.T) {
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+
+	// prepare test data
+	testCases := []struct {
+		name            string
+		userID          string
+		inputBody       string
+		useMock         bool
+		mockBehaviour   func(m *mocks.MockSegmentService)
+		expStatusCode   int
+		expResponseBody string
+	}{
+		{
+			name:      "OK",
+			userID:    userID,
+			inputBody: `{"group":"EXP_X"}`,
+			useMock:   true,
+			mockBehaviour: func(m *mocks.MockSegmentService) {
+				m.EXPECT().AssignExperiment(gomock.Any(), "EXP_X", uuid.MustParse(userID)).
+					Return(models.Assignment{Group: "EXP_X", Segment: "EXP_X_VARIANT_A", Assigned: true}, nil)
+			},
+			expStatusCode:   200,
+			expResponseBody: `{"group":"EXP_X","segment":"EXP_X_VARIANT_A","assigned":true}`,
+		},
+		{
+			name:            "Missing group",
+			userID:          userID,
+			inputBody:       `{}`,
+			useMock:         false,
+			expStatusCode:   400,
+			expResponseBody: `{"error":"missing required parameters"}`,
+		},
+		{
+			name:            "Incorrect userID",
+			userID:          "123",
+			inputBody:       `{"group":"EXP_X"}`,
+			useMock:         false,
+			expStatusCode:   400,
+			expResponseBody: `{"error":"invalid format of parameter 'userID'"}`,
+		},
+		{
+			name:      "Group not found",
+			userID:    userID,
+			inputBody: `{"group":"EXP_Y"}`,
+			useMock:   true,
+			mockBehaviour: func(m *mocks.MockSegmentService) {
+				m.EXPECT().AssignExperiment(gomock.Any(), "EXP_Y", uuid.MustParse(userID)).Return(models.Assignment{}, models.ErrExperimentNotFound)
+			},
+			expStatusCode:   404,
+			expResponseBody: `{"error":"experiment group not found"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// change the behavior of the mock service if necessary
+			if tc.useMock {
+				tc.mockBehaviour(svc)
+			}
+
+			// create and execute request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/assignExperiment/"+tc.userID, bytes.NewBufferString(tc.inputBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			r.ServeHTTP(w, req)
+
+			// check response
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestUpdateUserAttributes(t *testing.T) {
+	userID := "550e8400-e29b-41d4-a716-446655440000"
+
+	// prepare test data
+	testCases := []struct {
+		name            string
+		userID          string
+		inputBody       string
+		useMock         bool
+		mockBehaviour   func(m *mocks.MockSegmentService)
+		expStatusCode   int
+		expResponseBody string
+	}{
+		{
+			name:      "OK",
+			userID:    userID,
+			inputBody: `{"attributes":{"city":"Moscow","age":30}}`,
+			useMock:   true,
+			mockBehaviour: func(m *mocks.MockSegmentService) {
+				m.EXPECT().UpdateUserAttributes(gomock.Any(), uuid.MustParse(userID), models.Attributes{"city": "Moscow", "age": float64(30)}).
+					Return(models.AttributesResult{Attributes: models.Attributes{"city": "Moscow", "age": float64(30)}, SegmentsAdded: []string{"MOSCOW"}, SegmentsRemoved: []string{}}, nil)
+			},
+			expStatusCode:   200,
+			expResponseBody: `{"attributes":{"age":30,"city":"Moscow"},"segments_added":["MOSCOW"],"segments_removed":[]}`,
+		},
+		{
+			name:            "Incorrect user id",
+			userID:          "123",
+			inputBody:       `{"attributes":{"age":30}}`,
+			useMock:         false,
+			expStatusCode:   400,
+			expResponseBody: `{"error":"invalid format of parameter 'userID'"}`,
+		},
+		{
+			name:      "Invalid attributes",
+			userID:    userID,
+			inputBody: `{"attributes":{"home city":"Moscow"}}`,
+			useMock:   true,
+			mockBehaviour: func(m *mocks.MockSegmentService) {
+				m.EXPECT().UpdateUserAttributes(gomock.Any(), uuid.MustParse(userID), gomock.Any()).
+					Return(models.AttributesResult{}, fmt.Errorf("%w: invalid name 'home city'", models.ErrInvalidAttributes))
+			},
+			expStatusCode:   400,
+			expResponseBody: `{"error":"invalid attributes: invalid name 'home city'"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// change the behavior of the mock service if necessary
+			if tc.useMock {
+				tc.mockBehaviour(svc)
+			}
+
+			// create and execute request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/updateUserAttributes/%s", tc.userID), bytes.NewBufferString(tc.inputBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			r.ServeHTTP(w, req)
+
+			// check response
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponseBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"segmentation-service/internal/domain/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// pingTimeout limits the database check of the readiness probe.
+const pingTimeout = 2 * time.Second
+
+// healthz is the liveness probe: it answers OK while the process is alive.
+func (a *Adapter) healthz(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, models.SuccessResponse{SuccessMsg: "alive"})
+}
+
+// readyz is the readiness probe: it answers OK if the service accepts traffic, that is the server is not
+// shutting down, the database answers a ping and its schema is migrated. The probes are served outside
+// of /api/v1 and are not part of the API documentation.
+func (a *Adapter) readyz(ctx *gin.Context) {
+	if !a.ready.Load() {
+		ctx.JSON(http.StatusServiceUnavailable, models.ErrorResponse{ErrorMsg: "not ready"})
+		return
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx.Request.Context(), pingTimeout)
+	defer cancel()
+	if err := a.healthChecker.Ping(pingCtx); err != nil {
+		ctx.JSON(http.StatusServiceUnavailable, models.ErrorResponse{ErrorMsg: err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, models.SuccessResponse{SuccessMsg: "ready"})
+}
@@ -0,0 +1,110 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"segmentation-service/internal/ports/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+)
+
+func TestHealthz(t *testing.T) {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `{"success":"alive"}`, w.Body.String())
+}
+
+func TestReadyz(t *testing.T) {
+	// prepare test data
+	testCases := []struct {
+		name            string
+		ready           bool
+		useMock         bool
+		mockBehaviour   func(m *mocks.MockHealthChecker)
+		expStatusCode   int
+		expResponseBody string
+	}{
+		{
+			name:            "Not started",
+			ready:           false,
+			useMock:         false,
+			expStatusCode:   503,
+			expResponseBody: `{"error":"not ready"}`,
+		},
+		{
+			name:    "OK",
+			ready:   true,
+			useMock: true,
+			mockBehaviour: func(m *mocks.MockHealthChecker) {
+				m.EXPECT().Ping(gomock.Any()).Return(nil)
+			},
+			expStatusCode:   200,
+			expResponseBody: `{"success":"ready"}`,
+		},
+		{
+			name:    "Database unavailable",
+			ready:   true,
+			useMock: true,
+			mockBehaviour: func(m *mocks.MockHealthChecker) {
+				m.EXPECT().Ping(gomock.Any()).Return(errors.New("database ping failed"))
+			},
+			expStatusCode:   503,
+			expResponseBody: `{"error":"database ping failed"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			adapter.ready.Store(tc.ready)
+			if tc.useMock {
+				tc.mockBehaviour(health)
+			}
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+			r.ServeHTTP(w, req)
+
+			// check response
+			assert.Equal(t, tc.expStatusCode, w.Code)
+			assert.Equal(t, tc.expResponseBody, w.Body.String())
+		})
+	}
+	adapter.ready.Store(false)
+}
+
+func TestGracefulStop(t *testing.T) {
+	require.NoError(t, adapter.Start())
+	assert.Equal(t, true, adapter.ready.Load())
+
+	adapter.gracePeriod = 300 * time.Millisecond
+	stopped := make(chan error)
+	go func() {
+		stopped <- adapter.Stop(context.Background())
+	}()
+
+	// during the grace period the readiness probe fails, but the server still accepts connections
+	time.Sleep(100 * time.Millisecond)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, 503, w.Code)
+
+	resp, err := http.Get("http://localhost:3030/healthz")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, 200, resp.StatusCode)
+
+	// after the grace period the server is drained and closed
+	require.NoError(t, <-stopped)
+	_, err = http.Get("http://localhost:3030/healthz")
+	require.Error(t, err)
+}
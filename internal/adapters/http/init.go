@@ -3,12 +3,15 @@ package http
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
+	"segmentation-service/internal/adapters/auth"
 	"segmentation-service/internal/ports"
 	"segmentation-service/pkg/infra/logger"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -16,15 +19,25 @@ import (
 )
 
 type Adapter struct {
-	s          *http.Server
-	l          net.Listener
-	segmentSvc ports.SegmentService
+	s             *http.Server
+	l             net.Listener
+	eg            errgroup.Group
+	stopOnce      sync.Once
+	ready         atomic.Bool
+	segmentSvc    ports.SegmentService
+	healthChecker ports.HealthChecker
+	serviceName   string
+	gracePeriod   time.Duration
+	apiKeys       auth.Keys
 }
 
 type AdapterOptions struct {
 	HTTP_port   int
 	Timeout     time.Duration
 	IdleTimeout time.Duration
+	ServiceName string        // reported as the server name of the http spans
+	GracePeriod time.Duration // how long /readyz reports failure before the server stops accepting connections
+	APIKeys     auth.Keys     // keys accepted by the API, the authentication is disabled if empty
 }
 
 var router *gin.Engine
@@ -34,7 +47,7 @@ func GetRouter() *gin.Engine {
 }
 
 // New instantiates the adapter.
-func New(segmentService ports.SegmentService, opts AdapterOptions) (*Adapter, error) {
+func New(segmentService ports.SegmentService, healthChecker ports.HealthChecker, opts AdapterOptions) (*Adapter, error) {
 	l, err := net.Listen("tcp", fmt.Sprintf(":%d", opts.HTTP_port))
 	if err != nil {
 		return nil, fmt.Errorf("server start failed: %w", err)
@@ -47,37 +60,52 @@ func New(segmentService ports.SegmentService, opts AdapterOptions) (*Adapter, er
 		WriteTimeout: opts.Timeout,
 		IdleTimeout:  opts.IdleTimeout, // client connection lifetime
 	}
-	a := Adapter{
-		s:          &server,
-		l:          l,
-		segmentSvc: segmentService,
+	a := &Adapter{
+		s:             &server,
+		l:             l,
+		segmentSvc:    segmentService,
+		healthChecker: healthChecker,
+		serviceName:   opts.ServiceName,
+		gracePeriod:   opts.GracePeriod,
+		apiKeys:       opts.APIKeys,
 	}
-	err = initRouter(&a, router)
-	return &a, err
+	err = initRouter(a, router)
+	return a, err
 }
 
-// Start starts an http server that accepts incoming connections on the Listener.
+// Start starts an http server that accepts incoming connections on the Listener in the background
+// and marks the adapter as ready to receive traffic.
 func (a *Adapter) Start() error {
 	logger.Get().Info("starting http server...")
 
-	eg := &errgroup.Group{}
-	eg.Go(func() error {
-		return a.s.Serve(a.l)
-	})
-	if err := eg.Wait(); err != nil {
+	a.eg.Go(func() error {
+		err := a.s.Serve(a.l)
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
 		return err
-	}
+	})
+	a.ready.Store(true)
 	return nil
 }
 
-// Stop stops the http server.
+// Stop gracefully stops the http server. First /readyz starts failing, so that the load balancer stops
+// routing new requests to the instance, then after the grace period the server drains active connections.
 func (a *Adapter) Stop(ctx context.Context) error {
-	var (
-		err  error
-		once sync.Once
-	)
-	once.Do(func() {
-		err = a.s.Shutdown(ctx)
+	var err error
+	a.stopOnce.Do(func() {
+		a.ready.Store(false)
+		logger.Get().Info("readiness disabled, draining http server...", "grace_period", a.gracePeriod)
+
+		select {
+		case <-time.After(a.gracePeriod):
+		case <-ctx.Done():
+		}
+
+		if err = a.s.Shutdown(ctx); err != nil {
+			return
+		}
+		err = a.eg.Wait()
 	})
 	return err
 }
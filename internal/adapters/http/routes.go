@@ -1,13 +1,16 @@
 package http
 
 import (
+	"net/http"
 	"segmentation-service/pkg/infra/logger"
+	"strings"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	sloggin "github.com/samber/slog-gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 func initRouter(a *Adapter, r *gin.Engine) error {
@@ -17,17 +20,51 @@ func initRouter(a *Adapter, r *gin.Engine) error {
 	config := cors.DefaultConfig()
 	config.AllowAllOrigins = true
 	r.Use(cors.New(config))
-	r.Use(sloggin.New(log))
+	// the server span is started from the incoming traceparent header before the request is logged,
+	// so the access log record carries the same trace ID as the spans of the request
+	r.Use(otelgin.Middleware(a.serviceName, otelgin.WithFilter(skipUntraced)))
+	r.Use(func(ctx *gin.Context) {
+		sloggin.New(logger.FromContext(ctx.Request.Context()))(ctx)
+	})
 
 	r.GET("swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
-	g := r.Group("/api/v1")
+	r.GET("/healthz", a.healthz)
+	r.GET("/readyz", a.readyz)
+	g := r.Group("/api/v1", a.authenticate, a.attribute, a.deprecation)
 	{
 		g.POST("/createSegment", a.createSegment)
 		g.DELETE("/deleteSegment", a.deleteSegment)
+		g.POST("/renameSegment", a.renameSegment)
+		g.POST("/setSegmentRule", a.setSegmentRule)
+		g.POST("/setSegmentExpression", a.setSegmentExpression)
+		g.POST("/setSegmentRollout", a.setSegmentRollout)
+		g.POST("/setSegmentWindow", a.setSegmentWindow)
+		g.POST("/setSegmentCap", a.setSegmentCap)
 		g.POST("/updateUserSegments/:userID", a.updateSegments)
+		g.POST("/importMemberships", a.importMemberships)
 		g.GET("/getUserSegments/:userID", a.getSegments)
+		g.GET("/listSegments", a.listSegments)
+		g.GET("/segments/:slug/members", a.getSegmentMembers)
+		g.GET("/segments/:slug/history", a.getSegmentHistory)
+		g.GET("/segments/:slug/stats", a.getSegmentStats)
+		g.GET("/stats", a.getServiceStats)
+		g.POST("/updateUserAttributes/:userID", a.updateUserAttributes)
+		g.GET("/getUserAttributes/:userID", a.getUserAttributes)
+		g.POST("/createExperimentGroup", a.createExperimentGroup)
+		g.DELETE("/deleteExperimentGroup", a.deleteExperimentGroup)
+		g.GET("/listExperimentGroups", a.listExperimentGroups)
+		g.POST("/assignExperiment/:userID", a.assignExperiment)
 		g.GET("/getReport/:period", a.getReport)
 		g.GET("/getUserReport/:period/:userID", a.getUserReport)
+		g.GET("/getSegmentReport/:period", a.getSegmentReport)
+		g.GET("/getAggregateReport/:period", a.getAggregateReport)
+		g.GET("/exportState", a.exportState)
+		g.POST("/importState", a.importState)
 	}
 	return nil
 }
+
+// skipUntraced excludes requests for the API documentation and the probes from tracing.
+func skipUntraced(r *http.Request) bool {
+	return !strings.HasPrefix(r.URL.Path, "/swagger/") && r.URL.Path != "/healthz" && r.URL.Path != "/readyz"
+}
@@ -8,3 +8,7 @@ package http
 // @description A service that stores a user and the segments they belong to.
 // @contact.name Olga Shishkina
 // @contact.email olenka.shishkina.02@mail.ru
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description Required only if the API keys are configured (API_KEYS).
@@ -0,0 +1,172 @@
+// The scheduler package is responsible for the periodic jobs of the service, such as recording the activations and
+// deactivations of the segments by their windows, folding the counters of the segment members and the daily
+// rollups of the report, creating the monthly partitions of the report and archiving its old rows.
+package scheduler
+
+import (
+	"context"
+	"segmentation-service/internal/ports"
+	"segmentation-service/pkg/infra/logger"
+	"sync"
+	"time"
+)
+
+type Scheduler struct {
+	segmentSvc      ports.SegmentService
+	interval        time.Duration
+	archiveInterval time.Duration
+
+	stop     context.CancelFunc
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+type SchedulerOptions struct {
+	Interval        time.Duration // time between the runs of the jobs
+	ArchiveInterval time.Duration // time between the archivals of the old report rows, 0 disables them
+}
+
+// New returns a scheduler running the jobs of the service with the given interval.
+func New(segmentService ports.SegmentService, opts SchedulerOptions) *Scheduler {
+	return &Scheduler{
+		segmentSvc:      segmentService,
+		interval:        opts.Interval,
+		archiveInterval: opts.ArchiveInterval,
+		done:            make(chan struct{}),
+	}
+}
+
+// Start runs the jobs once and then in the background every interval until Stop is called. The archival of the report
+// runs on its own, so that a long archival doesn't delay the other jobs.
+func (s *Scheduler) Start() error {
+	logger.Get().Info("starting scheduler...", "interval", s.interval.String(), "archive_interval", s.archiveInterval.String())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stop = cancel
+	var wg sync.WaitGroup
+	if s.archiveInterval > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.every(ctx, s.archiveInterval, s.archiveReports)
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.every(ctx, s.interval, func(ctx context.Context) {
+			s.recordSegmentStates(ctx)
+			s.foldSegmentSizes(ctx)
+			s.foldReportRollups(ctx)
+			s.createReportPartitions(ctx)
+		})
+	}()
+	go func() {
+		wg.Wait()
+		close(s.done)
+	}()
+	return nil
+}
+
+// every runs the job once and then every interval until ctx is cancelled.
+func (s *Scheduler) every(ctx context.Context, interval time.Duration, job func(ctx context.Context)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		job(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Stop cancels the running job and waits for the scheduler to finish or ctx to expire.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	var err error
+	s.stopOnce.Do(func() {
+		if s.stop == nil {
+			return
+		}
+		s.stop()
+		select {
+		case <-s.done:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	})
+	return err
+}
+
+// recordSegmentStates writes the changes of the segment states to the audit log. The errors are logged and the
+// changes are picked up by the next run.
+func (s *Scheduler) recordSegmentStates(ctx context.Context) {
+	count, err := s.segmentSvc.RecordSegmentStates(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Get().ErrorContext(ctx, "recording segment states failed", "desc", err.Error())
+		}
+		return
+	}
+	if count > 0 {
+		logger.Get().InfoContext(ctx, "segment states recorded", "events", count)
+	}
+}
+
+// foldSegmentSizes compacts the counters of the segment members. The errors are logged and the counters stay
+// correct, only slower to read, until the next run.
+func (s *Scheduler) foldSegmentSizes(ctx context.Context) {
+	count, err := s.segmentSvc.FoldSegmentSizes(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Get().ErrorContext(ctx, "folding segment sizes failed", "desc", err.Error())
+		}
+		return
+	}
+	if count > 0 {
+		logger.Get().DebugContext(ctx, "segment sizes folded", "rows", count)
+	}
+}
+
+// foldReportRollups compacts the daily rollups of the report. The errors are logged and the rollups stay correct,
+// only slower to read, until the next run.
+func (s *Scheduler) foldReportRollups(ctx context.Context) {
+	count, err := s.segmentSvc.FoldReportRollups(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Get().ErrorContext(ctx, "folding report rollups failed", "desc", err.Error())
+		}
+		return
+	}
+	if count > 0 {
+		logger.Get().DebugContext(ctx, "report rollups folded", "rows", count)
+	}
+}
+
+// createReportPartitions creates the partitions of the report for the next months. The errors are logged, the rows
+// of the months without a partition go to the default one until the next run creates it.
+func (s *Scheduler) createReportPartitions(ctx context.Context) {
+	count, err := s.segmentSvc.CreateReportPartitions(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Get().ErrorContext(ctx, "creating report partitions failed", "desc", err.Error())
+		}
+		return
+	}
+	if count > 0 {
+		logger.Get().InfoContext(ctx, "report partitions created", "partitions", count)
+	}
+}
+
+// archiveReports moves the old report rows to the archive. The errors are logged, the failed month is retried
+// by the next run.
+func (s *Scheduler) archiveReports(ctx context.Context) {
+	runs, err := s.segmentSvc.ArchiveReports(ctx)
+	for _, run := range runs {
+		logger.Get().InfoContext(ctx, "report month archived", "month", run.Month, "file", run.File, "rows", run.Rows)
+	}
+	if err != nil && ctx.Err() == nil {
+		logger.Get().ErrorContext(ctx, "archiving report failed", "desc", err.Error())
+	}
+}
@@ -0,0 +1,100 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"segmentation-service/internal/domain/models"
+	"segmentation-service/internal/ports/mocks"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedulerRecordsStates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	svc := mocks.NewMockSegmentService(ctrl)
+
+	// the first run fails, the scheduler keeps running and retries on the next tick
+	var runs atomic.Int32
+	svc.EXPECT().RecordSegmentStates(gomock.Any()).DoAndReturn(func(ctx context.Context) (int, error) {
+		if runs.Add(1) == 1 {
+			return 0, errors.New("some error")
+		}
+		return 1, nil
+	}).MinTimes(3)
+	svc.EXPECT().FoldSegmentSizes(gomock.Any()).Return(0, nil).AnyTimes()
+	svc.EXPECT().FoldReportRollups(gomock.Any()).Return(0, nil).AnyTimes()
+	svc.EXPECT().CreateReportPartitions(gomock.Any()).Return(0, nil).AnyTimes()
+
+	s := New(svc, SchedulerOptions{Interval: 10 * time.Millisecond})
+	require.NoError(t, s.Start())
+	require.Eventually(t, func() bool { return runs.Load() >= 3 }, time.Second, 5*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, s.Stop(ctx))
+
+	// no runs after the stop
+	stopped := runs.Load()
+	time.Sleep(30 * time.Millisecond)
+	require.Equal(t, stopped, runs.Load())
+}
+
+func TestSchedulerStopWithoutStart(t *testing.T) {
+	s := New(mocks.NewMockSegmentService(gomock.NewController(t)), SchedulerOptions{Interval: time.Minute})
+	require.NoError(t, s.Stop(context.Background()))
+}
+
+func TestSchedulerFoldsCounters(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	svc := mocks.NewMockSegmentService(ctrl)
+
+	// a failed fold doesn't stop the other jobs
+	var folds atomic.Int32
+	svc.EXPECT().RecordSegmentStates(gomock.Any()).Return(0, nil).MinTimes(2)
+	svc.EXPECT().FoldSegmentSizes(gomock.Any()).DoAndReturn(func(ctx context.Context) (int, error) {
+		if folds.Add(1) == 1 {
+			return 0, errors.New("some error")
+		}
+		return 5, nil
+	}).MinTimes(2)
+	svc.EXPECT().FoldReportRollups(gomock.Any()).Return(3, nil).MinTimes(2)
+	svc.EXPECT().CreateReportPartitions(gomock.Any()).Return(1, nil).MinTimes(2)
+
+	s := New(svc, SchedulerOptions{Interval: 10 * time.Millisecond})
+	require.NoError(t, s.Start())
+	require.Eventually(t, func() bool { return folds.Load() >= 2 }, time.Second, 5*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, s.Stop(ctx))
+}
+
+func TestSchedulerArchivesReports(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	svc := mocks.NewMockSegmentService(ctrl)
+
+	// a failed archival is retried on the next tick of its own interval
+	var archivals atomic.Int32
+	svc.EXPECT().RecordSegmentStates(gomock.Any()).Return(0, nil).AnyTimes()
+	svc.EXPECT().FoldSegmentSizes(gomock.Any()).Return(0, nil).AnyTimes()
+	svc.EXPECT().FoldReportRollups(gomock.Any()).Return(0, nil).AnyTimes()
+	svc.EXPECT().CreateReportPartitions(gomock.Any()).Return(0, nil).AnyTimes()
+	svc.EXPECT().ArchiveReports(gomock.Any()).DoAndReturn(func(ctx context.Context) ([]models.ReportArchiveRun, error) {
+		if archivals.Add(1) == 1 {
+			return []models.ReportArchiveRun{{Month: "2023-08", State: models.ArchiveArchived}}, errors.New("some error")
+		}
+		return nil, nil
+	}).MinTimes(2)
+
+	s := New(svc, SchedulerOptions{Interval: time.Hour, ArchiveInterval: 10 * time.Millisecond})
+	require.NoError(t, s.Start())
+	require.Eventually(t, func() bool { return archivals.Load() >= 2 }, time.Second, 5*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, s.Stop(ctx))
+}
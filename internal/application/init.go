@@ -3,10 +3,18 @@ package application
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"segmentation-service/internal/adapters/archive"
+	"segmentation-service/internal/adapters/auth"
+	"segmentation-service/internal/adapters/cache"
 	"segmentation-service/internal/adapters/db"
+	"segmentation-service/internal/adapters/grpc"
 	"segmentation-service/internal/adapters/http"
+	"segmentation-service/internal/adapters/scheduler"
 	"segmentation-service/internal/domain/usecases"
+	"segmentation-service/internal/ports"
+	"segmentation-service/pkg/infra/logger"
 	"time"
 )
 
@@ -18,8 +26,25 @@ type App struct {
 type AppOptions struct {
 	DB_url      string
 	HTTP_port   int
+	GRPC_port   int
 	Timeout     time.Duration
 	IdleTimeout time.Duration
+	ServiceName string
+	GracePeriod time.Duration
+	CacheSize   int
+	CacheTTL    time.Duration
+	APIKeys     []string
+
+	SchedulerInterval time.Duration
+
+	ReportRetentionMonths int
+	ReportArchiveDir      string
+	ReportArchiveInterval time.Duration
+	ReportLocation        *time.Location
+
+	DBInitialBackoff time.Duration
+	DBMaxBackoff     time.Duration
+	DBMaxWait        time.Duration
 }
 
 // New returns a new application instance.
@@ -29,27 +54,86 @@ func New(opts AppOptions) *App {
 	}
 }
 
-// Start creates the database and service instances, then builds and starts the adapter.
-func (app *App) Start() error {
+// Start creates the database and service instances, then builds and starts the grpc and http adapters.
+// Cancelling ctx aborts waiting for the database.
+func (app *App) Start(ctx context.Context) error {
+	apiKeys, err := auth.ParseKeys(app.opts.APIKeys)
+	if err != nil {
+		return err
+	}
+
 	// creates the database and service instances
-	storage, err := db.New(context.Background(), app.opts.DB_url)
+	optsConnect := db.ConnectOptions{
+		InitialBackoff: app.opts.DBInitialBackoff,
+		MaxBackoff:     app.opts.DBMaxBackoff,
+		MaxWait:        app.opts.DBMaxWait,
+	}
+	storage, err := db.New(ctx, app.opts.DB_url, optsConnect, app.opts.ReportLocation)
 	if err != nil {
 		return fmt.Errorf("storage creation failed: %w", err)
 	}
-	segmentService := usecases.New(storage)
+	app.shutdownFuncs = append(app.shutdownFuncs, storage.Close)
+
+	// wrap the storage with the read cache of user segments
+	var segmentStorage ports.SegmentStorage = storage
+	if app.opts.CacheSize > 0 {
+		cached := cache.New(storage, cache.StorageOptions{Size: app.opts.CacheSize, TTL: app.opts.CacheTTL})
+		app.shutdownFuncs = append(app.shutdownFuncs, func(ctx context.Context) error {
+			logger.Get().Info("user segments cache stats", "stats", cached.Stats())
+			return nil
+		})
+		segmentStorage = cached
+	}
+	// the archived months of the report are read from the archive even if the retention has been disabled since
+	reportArchive, err := archive.New(app.opts.ReportArchiveDir)
+	if err != nil {
+		return err
+	}
+	segmentService := usecases.New(segmentStorage, usecases.WithReportArchive(reportArchive, app.opts.ReportRetentionMonths),
+		usecases.WithReportLocation(app.opts.ReportLocation))
 
-	// instantiate the adapter
+	// start the scheduler recording the activations and deactivations of the segments and archiving the old report rows
+	optsScheduler := scheduler.SchedulerOptions{Interval: app.opts.SchedulerInterval}
+	if app.opts.ReportRetentionMonths > 0 {
+		optsScheduler.ArchiveInterval = app.opts.ReportArchiveInterval
+	}
+	sched := scheduler.New(segmentService, optsScheduler)
+	app.shutdownFuncs = append(app.shutdownFuncs, sched.Stop)
+	if err = sched.Start(); err != nil {
+		return fmt.Errorf("scheduler start failed: %w", err)
+	}
+
+	// instantiate the grpc adapter, it shares the service instance with the http adapter
+	optsGRPC := grpc.AdapterOptions{
+		GRPC_port: app.opts.GRPC_port,
+		APIKeys:   apiKeys,
+	}
+	g, err := grpc.New(segmentService, storage, optsGRPC)
+	if err != nil {
+		return fmt.Errorf("grpc adapter initialization failed: %w", err)
+	}
+	app.shutdownFuncs = append(app.shutdownFuncs, g.Stop)
+	err = g.Start()
+	if err != nil {
+		return fmt.Errorf("grpc server start failed: %w", err)
+	}
+
+	// instantiate the http adapter
 	optsAdapter := http.AdapterOptions{
 		HTTP_port:   app.opts.HTTP_port,
 		Timeout:     app.opts.Timeout,
 		IdleTimeout: app.opts.IdleTimeout,
+		ServiceName: app.opts.ServiceName,
+		GracePeriod: app.opts.GracePeriod,
+		APIKeys:     apiKeys,
 	}
-	s, err := http.New(segmentService, optsAdapter)
+	s, err := http.New(segmentService, storage, optsAdapter)
 	if err != nil {
 		return fmt.Errorf("adapter initialization failed: %w", err)
 	}
 
-	// add the application stop function to the list of shutdown functions and start the service.
+	// add the adapter stop function to the list of shutdown functions and start the service.
+	// Shutdown functions are executed in reverse order, so the server is drained before the pool is closed.
 	app.shutdownFuncs = append(app.shutdownFuncs, s.Stop)
 	err = s.Start()
 	if err != nil {
@@ -59,14 +143,14 @@ func (app *App) Start() error {
 	return nil
 }
 
-// Stop executes all shutdown functions.
+// Stop executes all shutdown functions in reverse order of registration. A failed function doesn't stop the rest,
+// so that a server that couldn't be drained in time still lets the pool be closed; the errors are joined.
 func (a *App) Stop(ctx context.Context) error {
-	var err error
+	var errs []error
 	for i := len(a.shutdownFuncs) - 1; i >= 0; i-- {
-		err = a.shutdownFuncs[i](ctx)
-		if err != nil {
-			return err
+		if err := a.shutdownFuncs[i](ctx); err != nil {
+			errs = append(errs, err)
 		}
 	}
-	return nil
+	return errors.Join(errs...)
 }
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestValidate(t *testing.T) {
+	// prepare test data
+	testCases := []struct {
+		name           string
+		initialBackoff time.Duration
+		maxBackoff     time.Duration
+		timezone       string
+		expErr         string
+	}{
+		{name: "Valid", initialBackoff: 500 * time.Millisecond, maxBackoff: 5 * time.Second},
+		{name: "Equal backoffs", initialBackoff: time.Second, maxBackoff: time.Second},
+		{name: "Zero initial backoff", initialBackoff: 0, maxBackoff: 5 * time.Second, expErr: "DB_INITIAL_BACKOFF must be positive"},
+		{name: "Negative initial backoff", initialBackoff: -time.Second, maxBackoff: 5 * time.Second, expErr: "DB_INITIAL_BACKOFF must be positive"},
+		{name: "Max backoff less than initial", initialBackoff: time.Second, maxBackoff: 500 * time.Millisecond, expErr: "DB_MAX_BACKOFF must not be less than DB_INITIAL_BACKOFF"},
+		{name: "Report timezone", initialBackoff: time.Second, maxBackoff: time.Second, timezone: "Asia/Yekaterinburg"},
+		{name: "Unknown report timezone", initialBackoff: time.Second, maxBackoff: time.Second, timezone: "Mars/Olympus", expErr: "invalid REPORT_TIMEZONE: unknown time zone Mars/Olympus"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := Config{DBInitialBackoff: tc.initialBackoff, DBMaxBackoff: tc.maxBackoff, ReportTimezone: tc.timezone}
+			err := c.validate()
+			if tc.expErr == "" {
+				assert.NilError(t, err)
+				return
+			}
+			assert.Error(t, err, tc.expErr)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+// The bucket package maps users to buckets deterministically: the same user ID and salt always give the same bucket,
+// and different salts give independent ones, so assignments of different experiments don't correlate.
+package bucket
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+
+	"github.com/google/uuid"
+)
+
+// rolloutBuckets is the resolution of rollouts: the percentages are rounded to hundredths.
+const rolloutBuckets = 10000
+
+// Hash returns a uniformly distributed number for the user ID and the salt.
+func Hash(salt string, userID uuid.UUID) uint64 {
+	sum := sha256.Sum256([]byte(salt + ":" + userID.String()))
+	return binary.BigEndian.Uint64(sum[:8])
+}
+
+// Pick returns the index of the weight the user falls into, with the probability of each index proportional
+// to its weight. The weights must be positive.
+func Pick(salt string, userID uuid.UUID, weights []int) int {
+	var total uint64
+	for _, w := range weights {
+		total += uint64(w)
+	}
+	n := Hash(salt, userID) % total
+	for i, w := range weights {
+		if n < uint64(w) {
+			return i
+		}
+		n -= uint64(w)
+	}
+	return len(weights) - 1
+}
+
+// InRollout reports whether the user falls into the given percentage of users for the salt. The users of a smaller
+// percentage are always in a greater one with the same salt, so raising the percentage only adds users.
+func InRollout(salt string, userID uuid.UUID, percent float64) bool {
+	return Hash(salt, userID)%rolloutBuckets < uint64(math.Round(percent*rolloutBuckets/100))
+}
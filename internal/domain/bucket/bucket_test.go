@@ -0,0 +1,73 @@
+package bucket
+
+import (
+	"math"
+	"testing"
+
+	"github.com/google/uuid"
+	"gotest.tools/assert"
+)
+
+func TestPickIsDeterministic(t *testing.T) {
+	userID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440000")
+	first := Pick("EXP_X", userID, []int{1, 1, 1})
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, first, Pick("EXP_X", userID, []int{1, 1, 1}))
+	}
+}
+
+func TestPickFollowsWeights(t *testing.T) {
+	const users = 100000
+	weights := []int{50, 30, 20}
+	counts := make([]int, len(weights))
+	for i := 0; i < users; i++ {
+		counts[Pick("EXP_X", uuid.New(), weights)]++
+	}
+	for i, w := range weights {
+		share := float64(counts[i]) / users * 100
+		assert.Assert(t, math.Abs(share-float64(w)) < 1, "variant %d got %.2f%%, expected %d%%", i, share, w)
+	}
+}
+
+func TestSaltsAreIndependent(t *testing.T) {
+	// with independent salts, half of the users of the first variant of one group are in the first variant of the other
+	const users = 100000
+	var first, both int
+	for i := 0; i < users; i++ {
+		userID := uuid.New()
+		if Pick("EXP_X", userID, []int{1, 1}) == 0 {
+			first++
+			if Pick("EXP_Y", userID, []int{1, 1}) == 0 {
+				both++
+			}
+		}
+	}
+	share := float64(both) / float64(first)
+	assert.Assert(t, math.Abs(share-0.5) < 0.02, "%.3f of the users share the variant", share)
+}
+
+func TestRolloutOnlyGrows(t *testing.T) {
+	const users = 100000
+	var in10, in25 int
+	for i := 0; i < users; i++ {
+		userID := uuid.New()
+		small, large := InRollout("ROLLOUT", userID, 10), InRollout("ROLLOUT", userID, 25)
+		assert.Assert(t, !small || large, "user %s left the rollout when it grew", userID)
+		if small {
+			in10++
+		}
+		if large {
+			in25++
+		}
+	}
+	assert.Assert(t, math.Abs(float64(in10)/users*100-10) < 1, "%d users in 10%%", in10)
+	assert.Assert(t, math.Abs(float64(in25)/users*100-25) < 1, "%d users in 25%%", in25)
+}
+
+func TestRolloutBounds(t *testing.T) {
+	for i := 0; i < 1000; i++ {
+		userID := uuid.New()
+		assert.Assert(t, !InRollout("ROLLOUT", userID, 0))
+		assert.Assert(t, InRollout("ROLLOUT", userID, 100))
+	}
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultAliasDays = 30  // the old slug of a renamed segment keeps working for this many days by default
+	MaxAliasDays     = 365 // upper bound of the alias lifetime
+)
+
+// RenameRequest renames the segment Slug to NewSlug. The old slug keeps working as an alias for AliasDays days,
+// DefaultAliasDays if not set, and stays reserved afterwards.
+type RenameRequest struct {
+	Slug      string `json:"slug" example:"AVITO_VOICE_MESSAGES"`
+	NewSlug   string `json:"new_slug" example:"AVITO_VOICE_NOTES"`
+	AliasDays *int   `json:"alias_days,omitempty" example:"30"`
+}
+
+// SegmentAlias is the old slug of a renamed segment, resolved to the current slug until ExpiresAt.
+type SegmentAlias struct {
+	Alias     string    `json:"alias" example:"AVITO_VOICE_MESSAGES"`
+	Slug      string    `json:"slug" example:"AVITO_VOICE_NOTES"`
+	ExpiresAt time.Time `json:"expires_at" example:"2023-10-01T00:00:00+03:00"`
+}
+
+// DeprecatedSlugs collects the aliases used by a request, so that the transport can warn the client about them.
+type DeprecatedSlugs struct {
+	mu      sync.Mutex
+	aliases []SegmentAlias
+}
+
+type deprecatedSlugsKey struct{}
+
+// WithDeprecatedSlugs returns a context in which RecordDeprecatedSlug collects the used aliases into the returned set.
+func WithDeprecatedSlugs(ctx context.Context) (context.Context, *DeprecatedSlugs) {
+	d := &DeprecatedSlugs{}
+	return context.WithValue(ctx, deprecatedSlugsKey{}, d), d
+}
+
+// RecordDeprecatedSlug adds the alias to the set stored in ctx, if any.
+func RecordDeprecatedSlug(ctx context.Context, alias SegmentAlias) {
+	d, ok := ctx.Value(deprecatedSlugsKey{}).(*DeprecatedSlugs)
+	if !ok {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, a := range d.aliases {
+		if a.Alias == alias.Alias {
+			return
+		}
+	}
+	d.aliases = append(d.aliases, alias)
+}
+
+// List returns the recorded aliases in the order of their first use.
+func (d *DeprecatedSlugs) List() []SegmentAlias {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]SegmentAlias(nil), d.aliases...)
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ArchiveVersion is the version of the archive format written by the export.
+const ArchiveVersion = 1
+
+const (
+	RestoreMerge   = "merge"   // add the missing segments and memberships, keep everything else
+	RestoreReplace = "replace" // make the segments and memberships exactly as in the archive
+)
+
+// Archive is the envelope of an exported service state. The checksum is the hex encoded SHA-256 of Data.
+type Archive struct {
+	Version   int             `json:"version"`
+	CreatedAt time.Time       `json:"created_at"`
+	Checksum  string          `json:"checksum"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// State is the logical content of the service: segments, current memberships, user attributes, experiment groups
+// and, optionally, the report log.
+type State struct {
+	Segments    []ArchiveSegment    `json:"segments"`
+	Memberships []ArchiveMembership `json:"memberships"`
+	Attributes  []ArchiveAttributes `json:"attributes,omitempty"`
+	Experiments []ExperimentGroup   `json:"experiments,omitempty"`
+	Report      []ArchiveReportRow  `json:"report,omitempty"`
+}
+
+// ArchiveSegment is a segment with its definition. Excluded are the users explicitly removed from a percentage rollout,
+// Aliases are the old slugs of the segment if it was renamed, the expired ones included, since they stay reserved.
+type ArchiveSegment struct {
+	Slug       string         `json:"slug"`
+	Rule       string         `json:"rule,omitempty"`
+	Expression string         `json:"expression,omitempty"`
+	Rollout    *float64       `json:"rollout,omitempty"`
+	Salt       string         `json:"salt,omitempty"`
+	Excluded   []uuid.UUID    `json:"excluded,omitempty"`
+	Aliases    []ArchiveAlias `json:"aliases,omitempty"`
+
+	ActiveFrom  *time.Time `json:"active_from,omitempty"`
+	ActiveUntil *time.Time `json:"active_until,omitempty"`
+	MaxMembers  *int       `json:"max_members,omitempty"`
+}
+
+// ArchiveAlias is an old slug of a segment, resolved to the segment until ExpiresAt.
+type ArchiveAlias struct {
+	Alias     string    `json:"alias"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type ArchiveAttributes struct {
+	UserID     uuid.UUID  `json:"user_id"`
+	Attributes Attributes `json:"attributes"`
+}
+
+type ArchiveMembership struct {
+	UserID  uuid.UUID `json:"user_id"`
+	Segment string    `json:"segment"`
+}
+
+type ArchiveReportRow struct {
+	UserID  uuid.UUID `json:"user_id"`
+	Segment string    `json:"segment"`
+	Action  string    `json:"action"`
+	Time    time.Time `json:"time"`
+	Actor   string    `json:"actor,omitempty"`
+	Source  string    `json:"source,omitempty"`
+	Reason  string    `json:"reason,omitempty"`
+}
+
+type RestoreResult struct {
+	Mode               string `json:"mode"`
+	SegmentsCreated    int    `json:"segments_created"`
+	SegmentsDeleted    int    `json:"segments_deleted"`
+	MembershipsAdded   int    `json:"memberships_added"`
+	MembershipsRemoved int    `json:"memberships_removed"`
+	AttributesRestored int    `json:"attributes_restored"`
+	ExperimentsCreated int    `json:"experiments_created"`
+	AliasesRestored    int    `json:"aliases_restored"`
+	ReportRestored     int    `json:"report_restored"`
+}
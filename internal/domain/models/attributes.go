@@ -0,0 +1,28 @@
+package models
+
+// Attributes are the properties of a user that the rules of dynamic segments are evaluated against.
+// The values are strings, numbers (float64) or booleans.
+type Attributes map[string]any
+
+// UserAttributes is the body of the attributes update, where the given values replace the current ones
+// and null removes the attribute, and of the attributes response.
+type UserAttributes struct {
+	Attributes Attributes `json:"attributes"`
+}
+
+// AttributesResult contains the attributes after the update and the changes of the user's dynamic segments.
+type AttributesResult struct {
+	Attributes      Attributes `json:"attributes"`
+	SegmentsAdded   []string   `json:"segments_added"`
+	SegmentsRemoved []string   `json:"segments_removed"`
+}
+
+// SyncResult is the number of members added to and removed from a dynamic or composite segment
+// after its rule or expression was set.
+type SyncResult struct {
+	Added   int `json:"added"`
+	Removed int `json:"removed"`
+}
+
+// RuleEvaluator reports whether the user with the given attributes matches the rule.
+type RuleEvaluator func(rule string, attrs Attributes) bool
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"unicode/utf8"
+)
+
+// The sources of the changes written to the report and the segment audit log.
+const (
+	SourceAPI         = "api"          // an explicit change through the http or gRPC API
+	SourceImport      = "import"       // an import of memberships or a restore from an archive
+	SourceTTLReaper   = "ttl-reaper"   // an expired membership removed by the reaper
+	SourceAutoPercent = "auto-percent" // a change made by a percentage rollout
+	SourceRule        = "rule"         // a recomputation of a dynamic or composite segment
+	SourceScheduler   = "scheduler"    // an activation or deactivation of a segment by its window
+)
+
+// Sources lists the valid sources of the changes.
+var Sources = []string{SourceAPI, SourceImport, SourceTTLReaper, SourceAutoPercent, SourceRule, SourceScheduler}
+
+const (
+	MaxActorLength  = 256  // maximum length of the actor in characters
+	MaxReasonLength = 1024 // maximum length of the reason in characters
+)
+
+// Attribution tells who made a change, through what and why. It is stored on every report row of the change.
+type Attribution struct {
+	Actor  string `json:"actor,omitempty"`
+	Source string `json:"source,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Validate checks the source and the lengths of the free-text fields, an empty source is allowed.
+func (a Attribution) Validate() error {
+	if a.Source != "" && !slices.Contains(Sources, a.Source) {
+		return fmt.Errorf("%w: unknown source '%s'", ErrInvalidAttribution, a.Source)
+	}
+	if utf8.RuneCountInString(a.Actor) > MaxActorLength {
+		return fmt.Errorf("%w: actor is longer than %d characters", ErrInvalidAttribution, MaxActorLength)
+	}
+	if utf8.RuneCountInString(a.Reason) > MaxReasonLength {
+		return fmt.Errorf("%w: reason is longer than %d characters", ErrInvalidAttribution, MaxReasonLength)
+	}
+	return nil
+}
+
+type attributionKey struct{}
+
+// WithAttribution returns a copy of ctx carrying the attribution of the changes made with it.
+func WithAttribution(ctx context.Context, a Attribution) context.Context {
+	return context.WithValue(ctx, attributionKey{}, a)
+}
+
+// WithSource returns a copy of ctx in which the changes are attributed to the source, keeping the actor and the reason.
+func WithSource(ctx context.Context, source string) context.Context {
+	a := AttributionFrom(ctx)
+	a.Source = source
+	return WithAttribution(ctx, a)
+}
+
+// AttributionFrom returns the attribution stored in ctx. The changes without a source are made through the API.
+func AttributionFrom(ctx context.Context) Attribution {
+	a, _ := ctx.Value(attributionKey{}).(Attribution)
+	if a.Source == "" {
+		a.Source = SourceAPI
+	}
+	return a
+}
@@ -8,10 +8,42 @@ type ErrorResponse struct {
 }
 
 var (
-	ErrInvalidSlugFormat    = fmt.Errorf("invalid format of parameter 'slug'")    // 400
-	ErrInvalidUuidFormat    = fmt.Errorf("invalid format of parameter 'userID'")  // 400
-	ErrInvalidPeriodFormat  = fmt.Errorf("invalid format of parameter 'period'")  // 400
-	ErrBadRequest           = fmt.Errorf("missing required parameters")           // 400
-	ErrSegmentAlreadyExists = fmt.Errorf("segment with this slug already exists") // 400
-	ErrSegmentNotFound      = fmt.Errorf("segment not found")                     // 404
+	ErrInvalidSlugFormat    = fmt.Errorf("invalid format of parameter 'slug'")                            // 400
+	ErrInvalidUuidFormat    = fmt.Errorf("invalid format of parameter 'userID'")                          // 400
+	ErrInvalidPeriodFormat  = fmt.Errorf("invalid format of parameter 'period'")                          // 400
+	ErrBadRequest           = fmt.Errorf("missing required parameters")                                   // 400
+	ErrSegmentAlreadyExists = fmt.Errorf("segment with this slug already exists")                         // 400
+	ErrSegmentNotFound      = fmt.Errorf("segment not found")                                             // 404
+	ErrInvalidAction        = fmt.Errorf("invalid action, expected 'add' or 'remove'")                    // 400
+	ErrInvalidColumns       = fmt.Errorf("invalid number of columns")                                     // 400
+	ErrInvalidImportFile    = fmt.Errorf("invalid import file")                                           // 400
+	ErrInvalidArchive       = fmt.Errorf("invalid archive")                                               // 400
+	ErrArchiveVersion       = fmt.Errorf("unsupported archive version")                                   // 400
+	ErrArchiveChecksum      = fmt.Errorf("archive checksum mismatch")                                     // 400
+	ErrInvalidRestoreMode   = fmt.Errorf("invalid mode, expected 'merge' or 'replace'")                   // 400
+	ErrUnauthorized         = fmt.Errorf("missing or invalid API key")                                    // 401
+	ErrInvalidRule          = fmt.Errorf("invalid rule")                                                  // 400
+	ErrInvalidAttributes    = fmt.Errorf("invalid attributes")                                            // 400
+	ErrDynamicSegment       = fmt.Errorf("members of the segment are computed by its rule or expression") // 400
+	ErrInvalidExpression    = fmt.Errorf("invalid expression")                                            // 400
+	ErrCompositeCycle       = fmt.Errorf("composite segments form a cycle")                               // 400
+	ErrInvalidLimit         = fmt.Errorf("invalid format of parameter 'limit'")                           // 400
+	ErrInvalidCursor        = fmt.Errorf("invalid format of parameter 'after'")                           // 400
+	ErrSegmentInUse         = fmt.Errorf("segment is in use")                                             // 400
+	ErrInvalidExperiment    = fmt.Errorf("invalid experiment group")                                      // 400
+	ErrExperimentExists     = fmt.Errorf("experiment group with this name already exists")                // 400
+	ErrExperimentNotFound   = fmt.Errorf("experiment group not found")                                    // 404
+	ErrExperimentConflict   = fmt.Errorf("user is already in another variant of the experiment group")    // 400
+	ErrSegmentInExperiment  = fmt.Errorf("segment is a variant of an experiment group")                   // 400
+	ErrInvalidRollout       = fmt.Errorf("invalid rollout")                                               // 400
+	ErrRolloutSegment       = fmt.Errorf("segment is a percentage rollout")                               // 400
+	ErrInvalidWindow        = fmt.Errorf("invalid activation window")                                     // 400
+	ErrSlugReserved         = fmt.Errorf("slug is reserved by a renamed segment")                         // 400
+	ErrInvalidAliasDays     = fmt.Errorf("invalid format of parameter 'alias_days'")                      // 400
+	ErrInvalidAttribution   = fmt.Errorf("invalid actor, source or reason")                               // 400
+	ErrInvalidCap           = fmt.Errorf("invalid cap")                                                   // 400
+	ErrSegmentFull          = fmt.Errorf("segment is full")                                               // 409
+	ErrInvalidDateRange     = fmt.Errorf("invalid format of parameters 'from' and 'to'")                  // 400
+	ErrRetentionDisabled    = fmt.Errorf("report retention is disabled")
+	ErrArchiveBusy          = fmt.Errorf("report is being archived by another instance")
 )
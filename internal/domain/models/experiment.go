@@ -0,0 +1,42 @@
+package models
+
+import "github.com/google/uuid"
+
+const (
+	ConflictReject = "reject" // adding a user to a variant fails if they are in a sibling variant
+	ConflictMove   = "move"   // adding a user to a variant removes them from the sibling variants
+)
+
+// ExperimentGroup is a named set of mutually exclusive segments, its variants. A user is assigned to a variant
+// with a probability proportional to its weight, by the hash of the user ID and the salt.
+type ExperimentGroup struct {
+	Name     string    `json:"name" example:"EXP_X"`
+	Salt     string    `json:"salt,omitempty" example:"EXP_X"`      // the name of the group by default
+	Conflict string    `json:"conflict,omitempty" example:"reject"` // ConflictReject (default) or ConflictMove
+	Variants []Variant `json:"variants"`
+}
+
+type Variant struct {
+	Slug   string `json:"slug" example:"EXP_X_CONTROL"`
+	Weight int    `json:"weight" example:"50"`
+}
+
+type ExperimentGroupsList struct {
+	Groups []ExperimentGroup `json:"groups"`
+}
+
+// AssignRequest is the body of the assignment of a user to a variant of the experiment group.
+type AssignRequest struct {
+	Group string `json:"group" example:"EXP_X"`
+}
+
+// Assignment is the variant of the experiment group the user is in. Assigned is false if the user
+// had already been in the variant before the request.
+type Assignment struct {
+	Group    string `json:"group"`
+	Segment  string `json:"segment"`
+	Assigned bool   `json:"assigned"`
+}
+
+// VariantPicker returns the slug of the variant of the group the user has to be assigned to.
+type VariantPicker func(group ExperimentGroup, userID uuid.UUID) string
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"strconv"
+	"time"
+)
+
+// SegmentEvent is an entry of the segment audit log: a lifecycle operation with the values before and after it.
+// An empty value means the segment had no such setting, e.g. no rule.
+type SegmentEvent struct {
+	Segment  string    `json:"segment" example:"AVITO_VOICE_MESSAGES"`
+	Event    string    `json:"event" example:"rule_changed"`
+	OldValue string    `json:"old_value" example:""`
+	NewValue string    `json:"new_value" example:"city == \"Moscow\""`
+	Time     time.Time `json:"time" example:"2023-08-30T14:38:42+03:00"`
+	Actor    string    `json:"actor" example:"ops"`
+	Source   string    `json:"source" example:"api"`
+	Reason   string    `json:"reason" example:"promo in Moscow"`
+}
+
+// SegmentHistory is the audit log of a segment in the order of the events. Events of a renamed segment keep
+// the slug it had at the time.
+type SegmentHistory struct {
+	Slug   string         `json:"slug" example:"AVITO_VOICE_MESSAGES"`
+	Events []SegmentEvent `json:"events"`
+}
+
+// RolloutValue formats the percentage of a rollout for the audit log, empty if the segment isn't a rollout.
+func RolloutValue(rollout *float64) string {
+	if rollout == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*rollout, 'f', -1, 64)
+}
+
+// CapValue formats the cap of the segment for the audit log, empty if the segment has no cap.
+func CapValue(max *int) string {
+	if max == nil {
+		return ""
+	}
+	return strconv.Itoa(*max)
+}
+
+// FillValue formats the number of members of a capped segment and its cap for the audit log, e.g. "10000/10000".
+func FillValue(members, max int) string {
+	return strconv.Itoa(members) + "/" + strconv.Itoa(max)
+}
+
+// WindowValue formats the activation window for the audit log as an RFC 3339 interval with ".." for an open side,
+// empty if the segment has no window.
+func WindowValue(from, until *time.Time) string {
+	if from == nil && until == nil {
+		return ""
+	}
+	bound := func(t *time.Time) string {
+		if t == nil {
+			return ".."
+		}
+		return t.Format(time.RFC3339)
+	}
+	return bound(from) + "/" + bound(until)
+}
@@ -0,0 +1,46 @@
+package models
+
+import "github.com/google/uuid"
+
+// MembershipChange is a single addition or removal of a user to or from a segment.
+type MembershipChange struct {
+	UserID  uuid.UUID
+	Segment string
+	Action  string // ActAdd or ActRemove
+}
+
+// Membership is a user in a segment.
+type Membership struct {
+	UserID  uuid.UUID `json:"user_id"`
+	Segment string    `json:"segment"`
+}
+
+// ImportDiff is the change of the memberships that a dry run of an import would make, see MembershipDiff.
+// The lines with unknown segments are also reported as errors.
+type ImportDiff struct {
+	Added   []Membership `json:"added"`
+	Removed []Membership `json:"removed"`
+	NoOp    []Membership `json:"no_op"`
+	Unknown []string     `json:"unknown"`
+}
+
+type ImportOptions struct {
+	Segment string // if set, every line contains only a user ID and the action is applied to this segment
+	Action  string // action for the single segment format, ActAdd by default
+	DryRun  bool   // validate the lines and compute the diff without writing anything
+}
+
+type ImportLineError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+type ImportResult struct {
+	DryRun  bool              `json:"dry_run"`
+	Total   int               `json:"total"`            // number of processed lines, without the header
+	Valid   int               `json:"valid"`            // number of lines that passed validation
+	Applied int               `json:"applied"`          // number of memberships that actually changed
+	Capped  int               `json:"capped,omitempty"` // number of additions left out because their segments reached the cap
+	Errors  []ImportLineError `json:"errors"`
+	Diff    *ImportDiff       `json:"diff,omitempty"` // only for a dry run
+}
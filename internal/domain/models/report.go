@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,4 +19,32 @@ type ReportRow struct {
 	SegmentName string
 	Action      reportAction
 	Time        time.Time
+	Actor       string
+	Source      string
+	Reason      string
+}
+
+// Record returns the row in the order of the csv columns: user, segment, action, time, actor, source, reason.
+// The time is written in the location.
+func (r ReportRow) Record(loc *time.Location) []string {
+	return []string{
+		fmt.Sprintf("%v", r.UserID), r.SegmentName, string(r.Action), FormatReportTime(r.Time, loc),
+		r.Actor, r.Source, r.Reason,
+	}
+}
+
+// FormatReportTime returns the time as it is written in the csv reports, in the location. A nil location is UTC.
+func FormatReportTime(t time.Time, loc *time.Location) string {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return t.In(loc).Format("2006-01-02 15:04:05")
+}
+
+// ReportFilter selects the report rows by their attribution: the actor and the source must match exactly,
+// the reason must contain the given text, case-insensitively. Empty fields match everything.
+type ReportFilter struct {
+	Actor  string
+	Source string
+	Reason string
 }
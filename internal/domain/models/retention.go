@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// The states of the archival of a month of the report.
+const (
+	ArchiveRunning  = "running"  // the rows are being exported, or the run was interrupted
+	ArchiveFailed   = "failed"   // the run failed, the rows are still in the database and the month is retried
+	ArchiveArchived = "archived" // the rows are in the file of the month and have been deleted from the database
+)
+
+// MonthLayout is the format of the months of the report.
+const MonthLayout = "2006-01"
+
+// ReportArchiveRun is the log entry of the archival of a month (in the format: yyyy-mm) of the report.
+// File, Rows and Checksum are set once the month is archived, Error is the reason of the last failure.
+type ReportArchiveRun struct {
+	Month      string     `json:"month"`
+	State      string     `json:"state"`
+	File       string     `json:"file,omitempty"`
+	Rows       int        `json:"rows"`
+	Checksum   string     `json:"checksum,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// ArchivedMonths are the log entries of the archived months of the report and the names of the segments by their IDs,
+// read in the snapshot of an export, so that the rows of the archived months are added to it neither twice nor never.
+type ArchivedMonths struct {
+	Runs  []ReportArchiveRun
+	Names map[int32]string
+}
+
+// ArchivedReportRow is a report row in the file of an archived month. Segment is the name of the segment at the time
+// of the archival, the reports show the current name of the segment with the ID.
+type ArchivedReportRow struct {
+	SegmentID int32
+	Segment   string
+	UserID    uuid.UUID
+	Action    string
+	Time      time.Time
+	Actor     string
+	Source    string
+	Reason    string
+}
+
+// ReportRow returns the row as a report row of the segment with the given name.
+func (r ArchivedReportRow) ReportRow(segment string) ReportRow {
+	return ReportRow{
+		UserID:      r.UserID,
+		SegmentName: segment,
+		Action:      reportAction(r.Action),
+		Time:        r.Time,
+		Actor:       r.Actor,
+		Source:      r.Source,
+		Reason:      r.Reason,
+	}
+}
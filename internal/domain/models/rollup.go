@@ -0,0 +1,18 @@
+package models
+
+// RollupResult is the outcome of rebuilding the daily rollups of the report from its rows.
+type RollupResult struct {
+	Rollups int `json:"rollups"` // rollup rows written, one per segment, day and action
+	Rows    int `json:"rows"`    // report rows counted into them
+}
+
+// RollupMismatch is a segment, day and action whose rollup doesn't match the number of the report rows.
+// Segment is empty if the segment has been deleted since.
+type RollupMismatch struct {
+	SegmentID int    `json:"segment_id"`
+	Segment   string `json:"segment"`
+	Date      string `json:"date"`
+	Action    string `json:"action"`
+	Rollup    int    `json:"rollup"`
+	Report    int    `json:"report"`
+}
@@ -1,18 +1,114 @@
 package models
 
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// States of a segment by its activation window.
+const (
+	StateScheduled = "scheduled" // the window hasn't started yet
+	StateActive    = "active"    // the segment has no window or the window is open
+	StateEnded     = "ended"     // the window is over
+)
+
+// Events of the segment audit log.
+const (
+	EventCreated           = "created"
+	EventDeleted           = "deleted"
+	EventRenamed           = "renamed"
+	EventRuleChanged       = "rule_changed"
+	EventExpressionChanged = "expression_changed"
+	EventRolloutChanged    = "rollout_changed"
+	EventWindowChanged     = "window_changed"
+	EventActivated         = "activated"
+	EventDeactivated       = "deactivated"
+	EventCapChanged        = "cap_changed"
+	EventFull              = "full" // the segment reached its cap
+)
+
 type Segment struct {
-	Slug string `json:"slug" example:"AVITO_VOICE_MESSAGES"`
+	Slug       string   `json:"slug" example:"AVITO_VOICE_MESSAGES"`
+	Rule       string   `json:"rule,omitempty" example:"city == \"Moscow\" and listings > 5"`                 // members of a segment with a rule are computed from the user attributes
+	Expression string   `json:"expression,omitempty" example:"AVITO_VOICE_MESSAGES except AVITO_DISCOUNT_30"` // members of a composite segment are computed from other segments
+	Rollout    *float64 `json:"rollout,omitempty" example:"25"`                                               // members of a percentage rollout are computed from the hash of the user ID
+	Salt       string   `json:"salt,omitempty" example:"AVITO_VOICE_MESSAGES"`                                // salt of the hash of a percentage rollout, fixed once set
+	MaxMembers *int     `json:"max_members,omitempty" example:"10000"`                                        // additions beyond the cap are rejected, enrollments stop at it
+
+	// outside the activation window the segment isn't returned to its members, but the memberships are kept
+	ActiveFrom  *time.Time `json:"active_from,omitempty" example:"2023-09-01T00:00:00+03:00"`
+	ActiveUntil *time.Time `json:"active_until,omitempty" example:"2023-10-01T00:00:00+03:00"`
 }
 
+// SegmentsList is the list of segment slugs. The listing of all segments also contains the state of each segment.
 type SegmentsList struct {
-	S []string `json:"segments"`
+	S      []string       `json:"segments"`
+	States []SegmentState `json:"states,omitempty"`
+}
+
+// SegmentState is the activation window of the segment and its state at the time of the request. For a segment
+// with a cap it also contains the cap and the number of members, i.e. the fill level.
+type SegmentState struct {
+	Slug        string     `json:"slug" example:"AVITO_VOICE_MESSAGES"`
+	State       string     `json:"state" example:"scheduled"`
+	ActiveFrom  *time.Time `json:"active_from,omitempty" example:"2023-09-01T00:00:00+03:00"`
+	ActiveUntil *time.Time `json:"active_until,omitempty" example:"2023-10-01T00:00:00+03:00"`
+	MaxMembers  *int       `json:"max_members,omitempty" example:"10000"`
+	Members     *int       `json:"members,omitempty" example:"9850"`
+}
+
+// Full reports whether the segment has a cap and reached it.
+func (s SegmentState) Full() bool {
+	return s.MaxMembers != nil && s.Members != nil && *s.Members >= *s.MaxMembers
+}
+
+// MembersList is a page of the members of a segment, ordered by user ID. Next is the cursor of the next page,
+// empty on the last page.
+type MembersList struct {
+	Members []uuid.UUID `json:"members"`
+	Next    string      `json:"next,omitempty"`
 }
 
 type SuccessResponse struct {
 	SuccessMsg string `json:"success"`
 }
 
+// RolloutChecker reports whether the user falls into the given percentage of users of a rollout with the salt.
+type RolloutChecker func(salt string, userID uuid.UUID, percent float64) bool
+
 type UpdateRequest struct {
 	SegmentsToAdd    []string `json:"segments-to-add"`
 	SegmentsToRemove []string `json:"segments-to-remove"`
+	DryRun           bool     `json:"-"` // go through all the checks and compute the diff, but write nothing
+	NonStrict        bool     `json:"-"` // apply the existing segments and report the unknown ones instead of failing
+}
+
+// Statuses of a requested segment in the result of a membership update.
+const (
+	StatusApplied       = "applied"
+	StatusAlreadyMember = "already_member"
+	StatusNotMember     = "not_member"
+	StatusNotFound      = "not_found"
+	StatusFull          = "segment_full" // the segment reached its cap, the user isn't added
+)
+
+// SegmentResult is the outcome of adding or removing the user to or from one of the requested segments.
+type SegmentResult struct {
+	Segment string `json:"segment" example:"AVITO_VOICE_MESSAGES"`
+	Action  string `json:"action" example:"add"`
+	Status  string `json:"status" example:"applied"`
+}
+
+// MembershipDiff is the change of the memberships of a user made by an update, or that a dry run would make.
+// Added and Removed include the composite segments and the experiment variants changed as a side effect, NoOp lists
+// the requested segments that stay as they are, Unknown - the requested segments that don't exist.
+type MembershipDiff struct {
+	DryRun  bool     `json:"dry_run"`
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	NoOp    []string `json:"no_op"`
+	Unknown []string `json:"unknown"`
+	// the status of every requested segment in the order of the request, the removals first
+	Results []SegmentResult `json:"results"`
 }
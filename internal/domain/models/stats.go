@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// DateLayout is the format of the days of the segment statistics.
+const DateLayout = time.DateOnly
+
+// DailyStats is the number of the members added to and removed from a segment on a day.
+type DailyStats struct {
+	Date    string `json:"date" example:"2023-08-30"`
+	Added   int    `json:"added" example:"120"`
+	Removed int    `json:"removed" example:"15"`
+}
+
+// SegmentStats is the current number of the members of a segment and its daily changes over a range of days,
+// the days without changes included. Only the stored members are counted, which for a percentage rollout are
+// the explicitly added users.
+type SegmentStats struct {
+	Slug       string       `json:"slug" example:"AVITO_VOICE_MESSAGES"`
+	Members    int          `json:"members" example:"9850"`
+	MaxMembers *int         `json:"max_members,omitempty" example:"10000"`
+	From       string       `json:"from" example:"2023-08-01"`
+	To         string       `json:"to" example:"2023-08-30"`
+	Days       []DailyStats `json:"days"`
+}
+
+// SegmentSize is the number of the stored members of a segment.
+type SegmentSize struct {
+	Slug       string   `json:"slug" example:"AVITO_VOICE_MESSAGES"`
+	Members    int      `json:"members" example:"9850"`
+	MaxMembers *int     `json:"max_members,omitempty" example:"10000"`
+	Rollout    *float64 `json:"rollout,omitempty" example:"10"`
+}
+
+// ServiceStats is the sizes of all segments in alphabetical order with the number of segments and the total
+// number of memberships.
+type ServiceStats struct {
+	Segments    int           `json:"segments" example:"12"`
+	Memberships int           `json:"memberships" example:"120500"`
+	Sizes       []SegmentSize `json:"sizes"`
+	Cache       *CacheStats   `json:"cache,omitempty"` // nil if the user segments cache is disabled
+}
+
+// CacheStats is the hit and miss counters of the user segments cache and the current number of cached users.
+type CacheStats struct {
+	Hits   uint64 `json:"hits" example:"98000"`
+	Misses uint64 `json:"misses" example:"2000"`
+	Size   int    `json:"size" example:"10000"`
+}
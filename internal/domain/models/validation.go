@@ -0,0 +1,18 @@
+package models
+
+import "regexp"
+
+var (
+	SlugRegexp   = regexp.MustCompile(`^[\w-]+$`)      // letters, numbers, underscores or hyphens
+	PeriodRegexp = regexp.MustCompile(`^\d{4}-\d{2}$`) // yyyy-mm
+
+	AttributeRegexp = regexp.MustCompile(`^[A-Za-z_]\w*$`) // attribute names, as they are written in rules
+)
+
+const (
+	DefaultMembersLimit = 1000  // members returned per page if the limit isn't given
+	MaxMembersLimit     = 10000 // maximum members returned per page
+
+	DefaultStatsDays = 30  // days of the segment statistics if the range isn't given
+	MaxStatsDays     = 366 // maximum days of the segment statistics
+)
@@ -0,0 +1,113 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokOp     // == != < <= > >=
+	tokLParen // (
+	tokRParen // )
+	tokLBrack // [
+	tokRBrack // ]
+	tokComma
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	value any // parsed value of numbers and strings
+	pos   int // byte offset in the rule, for error messages
+}
+
+var punctuation = map[byte]tokenKind{'(': tokLParen, ')': tokRParen, '[': tokLBrack, ']': tokRBrack, ',': tokComma}
+
+// lex splits the rule into tokens. The keywords (and, or, not, in, true, false) are returned as identifiers.
+func lex(src string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(' || c == ')' || c == '[' || c == ']' || c == ',':
+			tokens = append(tokens, token{kind: punctuation[c], text: string(c), pos: i})
+			i++
+		case c == '=' || c == '!' || c == '<' || c == '>':
+			op := string(c)
+			if i+1 < len(src) && src[i+1] == '=' {
+				op += "="
+			}
+			if op == "=" || op == "!" {
+				return nil, fmt.Errorf("unexpected '%s' at position %d, expected '==' or '!='", op, i+1)
+			}
+			tokens = append(tokens, token{kind: tokOp, text: op, pos: i})
+			i += len(op)
+		case c == '"':
+			end := i + 1
+			for end < len(src) && src[end] != '"' {
+				if src[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(src) {
+				return nil, fmt.Errorf("unterminated string at position %d", i+1)
+			}
+			text := src[i : end+1]
+			value, err := strconv.Unquote(text)
+			if err != nil {
+				return nil, fmt.Errorf("invalid string %s at position %d", text, i+1)
+			}
+			tokens = append(tokens, token{kind: tokString, text: text, value: value, pos: i})
+			i = end + 1
+		case c == '-' || c == '.' || isDigit(c):
+			end := i + 1
+			for end < len(src) && (isDigit(src[end]) || strings.IndexByte(".eE+-", src[end]) >= 0) {
+				// a sign is only a part of the number right after the exponent
+				if (src[end] == '+' || src[end] == '-') && src[end-1] != 'e' && src[end-1] != 'E' {
+					break
+				}
+				end++
+			}
+			text := src[i:end]
+			value, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number '%s' at position %d", text, i+1)
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: text, value: value, pos: i})
+			i = end
+		case isIdentStart(rune(c)):
+			end := i + 1
+			for end < len(src) && isIdentPart(rune(src[end])) {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: src[i:end], pos: i})
+			i = end
+		default:
+			return nil, fmt.Errorf("unexpected character '%c' at position %d", c, i+1)
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(src)}), nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || (r < unicode.MaxASCII && unicode.IsLetter(r))
+}
+
+func isIdentPart(r rune) bool {
+	return isIdentStart(r) || (r < unicode.MaxASCII && unicode.IsDigit(r))
+}
@@ -0,0 +1,337 @@
+// The rules package implements the language of the rules that define the members of dynamic segments.
+//
+// A rule is a boolean expression over the user attributes:
+//
+//	city == "Moscow" and listings > 5
+//	not (plan in ["free", "trial"]) or verified == true
+//
+// Comparisons (==, !=, <, <=, >, >=) take an attribute on the left and a string, number or boolean literal
+// on the right; 'in' and 'not in' take a list of literals. Numbers and strings can be ordered, booleans only
+// compared for equality. A comparison with a missing attribute or with a value of another type is false,
+// so 'country != "RU"' doesn't match users without the 'country' attribute.
+package rules
+
+import (
+	"fmt"
+	"segmentation-service/internal/domain/models"
+	"strings"
+)
+
+const (
+	maxRuleLength = 4096
+	maxDepth      = 64
+)
+
+// Rule is a parsed rule, safe for concurrent use.
+type Rule struct {
+	src  string
+	root node
+}
+
+// Parse parses and validates the rule.
+func Parse(src string) (*Rule, error) {
+	src = strings.TrimSpace(src)
+	if src == "" {
+		return nil, fmt.Errorf("empty rule")
+	}
+	if len(src) > maxRuleLength {
+		return nil, fmt.Errorf("rule is longer than %d characters", maxRuleLength)
+	}
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.unexpected(t, "'and', 'or' or the end of the rule")
+	}
+	return &Rule{src: src, root: root}, nil
+}
+
+// Match reports whether the user with the given attributes satisfies the rule.
+func (r *Rule) Match(attrs models.Attributes) bool {
+	return r.root.eval(attrs)
+}
+
+// String returns the source of the rule.
+func (r *Rule) String() string {
+	return r.src
+}
+
+type node interface {
+	eval(attrs models.Attributes) bool
+}
+
+type (
+	andNode struct{ left, right node }
+	orNode  struct{ left, right node }
+	notNode struct{ expr node }
+
+	compareNode struct {
+		attr  string
+		op    string
+		value any
+	}
+	inNode struct {
+		attr   string
+		values []any
+		negate bool
+	}
+)
+
+func (n andNode) eval(attrs models.Attributes) bool { return n.left.eval(attrs) && n.right.eval(attrs) }
+func (n orNode) eval(attrs models.Attributes) bool  { return n.left.eval(attrs) || n.right.eval(attrs) }
+func (n notNode) eval(attrs models.Attributes) bool { return !n.expr.eval(attrs) }
+
+func (n compareNode) eval(attrs models.Attributes) bool {
+	value, ok := attrs[n.attr]
+	if !ok {
+		return false
+	}
+	cmp, ok := compare(value, n.value)
+	if !ok {
+		return false
+	}
+	switch n.op {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	}
+	// booleans can't be ordered
+	if _, isBool := value.(bool); isBool {
+		return false
+	}
+	switch n.op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	default:
+		return cmp >= 0
+	}
+}
+
+func (n inNode) eval(attrs models.Attributes) bool {
+	value, ok := attrs[n.attr]
+	if !ok {
+		return false
+	}
+	for _, v := range n.values {
+		if cmp, ok := compare(value, v); ok && cmp == 0 {
+			return !n.negate
+		}
+	}
+	return n.negate
+}
+
+// compare compares the values of the same type, ok is false if the types differ.
+func compare(a, b any) (cmp int, ok bool) {
+	switch a := a.(type) {
+	case float64:
+		b, ok := b.(float64)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case a < b:
+			return -1, true
+		case a > b:
+			return 1, true
+		}
+		return 0, true
+	case string:
+		b, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(a, b), true
+	case bool:
+		b, ok := b.(bool)
+		if !ok {
+			return 0, false
+		}
+		if a == b {
+			return 0, true
+		}
+		return 1, true
+	}
+	return 0, false
+}
+
+// parser is a recursive descent parser of the grammar:
+//
+//	or         = and { "or" and }
+//	and        = unary { "and" unary }
+//	unary      = "not" unary | "(" or ")" | comparison
+//	comparison = IDENT OP literal | IDENT [ "not" ] "in" "[" literal { "," literal } "]"
+type parser struct {
+	tokens []token
+	pos    int
+	depth  int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) keyword(word string) bool {
+	if t := p.peek(); t.kind == tokIdent && t.text == word {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) unexpected(t token, expected string) error {
+	if t.kind == tokEOF {
+		return fmt.Errorf("unexpected end of the rule, expected %s", expected)
+	}
+	return fmt.Errorf("unexpected '%s' at position %d, expected %s", t.text, t.pos+1, expected)
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxDepth {
+		return nil, fmt.Errorf("rule is nested deeper than %d levels", maxDepth)
+	}
+
+	if p.keyword("not") {
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{expr: expr}, nil
+	}
+	if p.peek().kind == tokLParen {
+		p.next()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != tokRParen {
+			return nil, p.unexpected(t, "')'")
+		}
+		return expr, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	attr := p.next()
+	if attr.kind != tokIdent || isKeyword(attr.text) {
+		return nil, p.unexpected(attr, "an attribute name")
+	}
+
+	negate := p.keyword("not")
+	if p.keyword("in") {
+		values, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		return inNode{attr: attr.text, values: values, negate: negate}, nil
+	}
+	if negate {
+		return nil, p.unexpected(p.peek(), "'in'")
+	}
+
+	op := p.next()
+	if op.kind != tokOp {
+		return nil, p.unexpected(op, "a comparison operator or 'in'")
+	}
+	value, err := p.parseLiteral()
+	if err != nil {
+		return nil, err
+	}
+	if _, isBool := value.(bool); isBool && op.text != "==" && op.text != "!=" {
+		return nil, fmt.Errorf("booleans can only be compared with '==' or '!=', got '%s' at position %d", op.text, op.pos+1)
+	}
+	return compareNode{attr: attr.text, op: op.text, value: value}, nil
+}
+
+func (p *parser) parseList() ([]any, error) {
+	if t := p.next(); t.kind != tokLBrack {
+		return nil, p.unexpected(t, "'['")
+	}
+	var values []any
+	for {
+		value, err := p.parseLiteral()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		switch t := p.next(); t.kind {
+		case tokComma:
+		case tokRBrack:
+			return values, nil
+		default:
+			return nil, p.unexpected(t, "',' or ']'")
+		}
+	}
+}
+
+func (p *parser) parseLiteral() (any, error) {
+	t := p.next()
+	switch {
+	case t.kind == tokNumber || t.kind == tokString:
+		return t.value, nil
+	case t.kind == tokIdent && t.text == "true":
+		return true, nil
+	case t.kind == tokIdent && t.text == "false":
+		return false, nil
+	}
+	return nil, p.unexpected(t, "a string, number or boolean")
+}
+
+func isKeyword(word string) bool {
+	switch word {
+	case "and", "or", "not", "in", "true", "false":
+		return true
+	}
+	return false
+}
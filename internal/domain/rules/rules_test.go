@@ -0,0 +1,82 @@
+package rules
+
+import (
+	"segmentation-service/internal/domain/models"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+)
+
+func TestMatch(t *testing.T) {
+	user := models.Attributes{"city": "Moscow", "listings": 7.0, "verified": true, "plan": "pro"}
+
+	// prepare test data
+	testCases := []struct {
+		rule     string
+		expMatch bool
+	}{
+		{rule: `city == "Moscow"`, expMatch: true},
+		{rule: `city != "Moscow"`, expMatch: false},
+		{rule: `listings > 5`, expMatch: true},
+		{rule: `listings >= 7 and listings <= 7`, expMatch: true},
+		{rule: `listings < 7`, expMatch: false},
+		{rule: `listings > 1e1`, expMatch: false},
+		{rule: `listings > -1.5`, expMatch: true},
+		{rule: `city == "Moscow" and listings > 5`, expMatch: true},
+		{rule: `city == "Kazan" or verified == true`, expMatch: true},
+		{rule: `not verified == false`, expMatch: true},
+		{rule: `plan in ["free", "trial"]`, expMatch: false},
+		{rule: `plan not in ["free", "trial"]`, expMatch: true},
+		{rule: `listings in [1, 7]`, expMatch: true},
+		{rule: `city > "Kazan"`, expMatch: true},
+		{rule: `not (city == "Moscow" and listings > 5) or plan == "pro"`, expMatch: true},
+		{rule: `city == "Kazan" or listings > 5 and plan == "free"`, expMatch: false},
+		// missing attributes and other types never match a comparison
+		{rule: `country != "RU"`, expMatch: false},
+		{rule: `country not in ["RU"]`, expMatch: false},
+		{rule: `not country == "RU"`, expMatch: true},
+		{rule: `listings == "7"`, expMatch: false},
+		{rule: `city != 7`, expMatch: false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.rule, func(t *testing.T) {
+			rule, err := Parse(tc.rule)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expMatch, rule.Match(user))
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	// prepare test data
+	testCases := []struct {
+		rule   string
+		expErr string
+	}{
+		{rule: ``, expErr: "empty rule"},
+		{rule: `city = "Moscow"`, expErr: "unexpected '=' at position 6, expected '==' or '!='"},
+		{rule: `city == "Moscow`, expErr: "unterminated string at position 9"},
+		{rule: `city == Moscow`, expErr: "unexpected 'Moscow' at position 9, expected a string, number or boolean"},
+		{rule: `city == "Moscow" and`, expErr: "unexpected end of the rule, expected an attribute name"},
+		{rule: `city == "Moscow" listings > 5`, expErr: "unexpected 'listings' at position 18, expected 'and', 'or' or the end of the rule"},
+		{rule: `(city == "Moscow"`, expErr: "unexpected end of the rule, expected ')'"},
+		{rule: `plan in ["free" "trial"]`, expErr: "unexpected '\"trial\"' at position 17, expected ',' or ']'"},
+		{rule: `plan not == "free"`, expErr: "unexpected '==' at position 10, expected 'in'"},
+		{rule: `verified > true`, expErr: "booleans can only be compared with '==' or '!=', got '>' at position 10"},
+		{rule: `and == 1`, expErr: "unexpected 'and' at position 1, expected an attribute name"},
+		{rule: `city == "Moscow" & listings > 5`, expErr: "unexpected character '&' at position 18"},
+		{rule: `listings > 1.2.3`, expErr: "invalid number '1.2.3' at position 12"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.rule, func(t *testing.T) {
+			_, err := Parse(tc.rule)
+			require.Error(t, err)
+			assert.Equal(t, tc.expErr, err.Error())
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package sets
+
+import (
+	"fmt"
+	"segmentation-service/internal/domain/models"
+	"sort"
+	"strings"
+)
+
+// Order returns the slugs of the composite segments so that every segment comes after the composite segments
+// its expression refers to, which is the order their members have to be computed in. If the segments refer
+// to each other, models.ErrCompositeCycle is returned.
+func Order(composites map[string]*Expr) ([]string, error) {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(composites))
+	order := make([]string, 0, len(composites))
+	var path []string
+
+	var visit func(slug string) error
+	visit = func(slug string) error {
+		switch state[slug] {
+		case done:
+			return nil
+		case visiting:
+			// the path from the first occurrence of the segment is the cycle
+			for i, s := range path {
+				if s == slug {
+					return fmt.Errorf("%w: %s", models.ErrCompositeCycle, strings.Join(append(path[i:], slug), " -> "))
+				}
+			}
+		}
+		state[slug] = visiting
+		path = append(path, slug)
+		for _, dep := range composites[slug].Segments() {
+			if _, ok := composites[dep]; !ok {
+				continue
+			}
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[slug] = done
+		order = append(order, slug)
+		return nil
+	}
+
+	// visit in a fixed order, so that the result and the reported cycle don't depend on the map order
+	slugs := make([]string, 0, len(composites))
+	for slug := range composites {
+		slugs = append(slugs, slug)
+	}
+	sort.Strings(slugs)
+	for _, slug := range slugs {
+		if err := visit(slug); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
@@ -0,0 +1,263 @@
+// The sets package implements the expressions that define the members of composite segments.
+//
+// An expression combines other segments with set operations:
+//
+//	AVITO_VOICE_MESSAGES intersect AVITO_PERFORMANCE_VAS
+//	(MOSCOW union KAZAN) except BANNED
+//
+// 'intersect' binds tighter than 'union' and 'except', which are applied from left to right, as in SQL.
+// The keywords are case-insensitive, so a segment named like a keyword can't be used in an expression.
+package sets
+
+import (
+	"fmt"
+	"segmentation-service/internal/domain/models"
+	"sort"
+	"strings"
+)
+
+const (
+	maxExpressionLength = 4096
+	maxDepth            = 64
+)
+
+// Op is the operation of an expression node.
+type Op int
+
+const (
+	OpSegment Op = iota // the members of the segment Slug
+	OpUnion
+	OpIntersect
+	OpExcept
+)
+
+var keywords = map[string]Op{"union": OpUnion, "intersect": OpIntersect, "except": OpExcept}
+
+// Expr is a node of a parsed expression. Segment nodes have a slug, the other nodes have both operands.
+type Expr struct {
+	Op          Op
+	Slug        string
+	Left, Right *Expr
+}
+
+// Parse parses and validates the expression.
+func Parse(src string) (*Expr, error) {
+	src = strings.TrimSpace(src)
+	if src == "" {
+		return nil, fmt.Errorf("empty expression")
+	}
+	if len(src) > maxExpressionLength {
+		return nil, fmt.Errorf("expression is longer than %d characters", maxExpressionLength)
+	}
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	expr, err := p.parseUnion()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.text != "" {
+		return nil, p.unexpected(t, "'union', 'intersect', 'except' or the end of the expression")
+	}
+	return expr, nil
+}
+
+// Segments returns the sorted slugs of the segments the expression refers to.
+func (e *Expr) Segments() []string {
+	seen := make(map[string]bool)
+	var walk func(e *Expr)
+	walk = func(e *Expr) {
+		if e.Op == OpSegment {
+			seen[e.Slug] = true
+			return
+		}
+		walk(e.Left)
+		walk(e.Right)
+	}
+	walk(e)
+
+	slugs := make([]string, 0, len(seen))
+	for slug := range seen {
+		slugs = append(slugs, slug)
+	}
+	sort.Strings(slugs)
+	return slugs
+}
+
+// Rename replaces the slugs found in names with their new values and reports whether anything changed.
+func (e *Expr) Rename(names map[string]string) bool {
+	if e.Op == OpSegment {
+		name, ok := names[e.Slug]
+		if ok {
+			e.Slug = name
+		}
+		return ok
+	}
+	left := e.Left.Rename(names)
+	right := e.Right.Rename(names)
+	return left || right
+}
+
+// Contains reports whether the user belongs to the set, given the segments the user is a member of.
+func (e *Expr) Contains(member func(slug string) bool) bool {
+	switch e.Op {
+	case OpSegment:
+		return member(e.Slug)
+	case OpUnion:
+		return e.Left.Contains(member) || e.Right.Contains(member)
+	case OpIntersect:
+		return e.Left.Contains(member) && e.Right.Contains(member)
+	default:
+		return e.Left.Contains(member) && !e.Right.Contains(member)
+	}
+}
+
+// String returns the expression with every operation in parentheses, except the outermost one.
+func (e *Expr) String() string {
+	if e.Op == OpSegment {
+		return e.Slug
+	}
+	operand := func(e *Expr) string {
+		if e.Op == OpSegment {
+			return e.String()
+		}
+		return "(" + e.String() + ")"
+	}
+	return operand(e.Left) + " " + opNames[e.Op] + " " + operand(e.Right)
+}
+
+var opNames = map[Op]string{OpUnion: "union", OpIntersect: "intersect", OpExcept: "except"}
+
+type token struct {
+	text string // empty at the end of the expression
+	pos  int    // byte offset in the expression, for error messages
+}
+
+// lex splits the expression into slugs, keywords and parentheses.
+func lex(src string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, token{text: string(c), pos: i})
+			i++
+		case isSlugPart(c):
+			end := i + 1
+			for end < len(src) && isSlugPart(src[end]) {
+				end++
+			}
+			tokens = append(tokens, token{text: src[i:end], pos: i})
+			i = end
+		default:
+			return nil, fmt.Errorf("unexpected character '%c' at position %d", c, i+1)
+		}
+	}
+	return append(tokens, token{pos: len(src)}), nil
+}
+
+func isSlugPart(c byte) bool {
+	return c == '_' || c == '-' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// parser is a recursive descent parser of the grammar:
+//
+//	union     = intersect { ("union" | "except") intersect }
+//	intersect = operand { "intersect" operand }
+//	operand   = slug | "(" union ")"
+type parser struct {
+	tokens []token
+	pos    int
+	depth  int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.text != "" {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) keyword(t token) (Op, bool) {
+	op, ok := keywords[strings.ToLower(t.text)]
+	return op, ok
+}
+
+func (p *parser) parseUnion() (*Expr, error) {
+	left, err := p.parseIntersect()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.keyword(p.peek())
+		if !ok || op == OpIntersect {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseIntersect()
+		if err != nil {
+			return nil, err
+		}
+		left = &Expr{Op: op, Left: left, Right: right}
+	}
+}
+
+func (p *parser) parseIntersect() (*Expr, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if op, ok := p.keyword(p.peek()); !ok || op != OpIntersect {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		left = &Expr{Op: OpIntersect, Left: left, Right: right}
+	}
+}
+
+func (p *parser) parseOperand() (*Expr, error) {
+	t := p.next()
+	switch {
+	case t.text == "(":
+		p.depth++
+		if p.depth > maxDepth {
+			return nil, fmt.Errorf("expression is nested deeper than %d levels", maxDepth)
+		}
+		expr, err := p.parseUnion()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.text != ")" {
+			return nil, p.unexpected(t, "')'")
+		}
+		p.depth--
+		return expr, nil
+	case t.text == "" || t.text == ")":
+		return nil, p.unexpected(t, "a segment slug")
+	}
+	if _, ok := p.keyword(t); ok || !models.SlugRegexp.MatchString(t.text) {
+		return nil, p.unexpected(t, "a segment slug")
+	}
+	return &Expr{Op: OpSegment, Slug: t.text}, nil
+}
+
+func (p *parser) unexpected(t token, expected string) error {
+	if t.text == "" {
+		return fmt.Errorf("unexpected end of the expression, expected %s", expected)
+	}
+	return fmt.Errorf("unexpected '%s' at position %d, expected %s", t.text, t.pos+1, expected)
+}
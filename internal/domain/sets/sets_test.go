@@ -0,0 +1,128 @@
+package sets
+
+import (
+	"segmentation-service/internal/domain/models"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+)
+
+func TestParse(t *testing.T) {
+	// prepare test data
+	testCases := []struct {
+		expr        string
+		expString   string
+		expSegments []string
+	}{
+		{expr: `TEST1`, expString: `TEST1`, expSegments: []string{"TEST1"}},
+		{expr: `TEST1 union TEST-2`, expString: `TEST1 union TEST-2`, expSegments: []string{"TEST-2", "TEST1"}},
+		{expr: `A union B intersect C`, expString: `A union (B intersect C)`, expSegments: []string{"A", "B", "C"}},
+		{expr: `A except B union C`, expString: `(A except B) union C`, expSegments: []string{"A", "B", "C"}},
+		{expr: `A EXCEPT (B Union C)`, expString: `A except (B union C)`, expSegments: []string{"A", "B", "C"}},
+		{expr: `(A intersect A) intersect B`, expString: `(A intersect A) intersect B`, expSegments: []string{"A", "B"}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.expr, func(t *testing.T) {
+			expr, err := Parse(tc.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expString, expr.String())
+			assert.DeepEqual(t, tc.expSegments, expr.Segments())
+		})
+	}
+}
+
+func TestRename(t *testing.T) {
+	expr, err := Parse(`(A union B) except A`)
+	require.NoError(t, err)
+
+	assert.Equal(t, false, expr.Rename(map[string]string{"C": "D"}))
+	assert.Equal(t, true, expr.Rename(map[string]string{"A": "NEW_A"}))
+	assert.Equal(t, `(NEW_A union B) except NEW_A`, expr.String())
+}
+
+func TestContains(t *testing.T) {
+	member := func(slug string) bool { return slug == "A" || slug == "B" }
+
+	// prepare test data
+	testCases := []struct {
+		expr        string
+		expContains bool
+	}{
+		{expr: `A intersect B`, expContains: true},
+		{expr: `A intersect C`, expContains: false},
+		{expr: `C union B`, expContains: true},
+		{expr: `A except B`, expContains: false},
+		{expr: `A except C`, expContains: true},
+		{expr: `C union A intersect B`, expContains: true},
+		{expr: `(C union A) except (B intersect C)`, expContains: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.expr, func(t *testing.T) {
+			expr, err := Parse(tc.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expContains, expr.Contains(member))
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	// prepare test data
+	testCases := []struct {
+		expr   string
+		expErr string
+	}{
+		{expr: ``, expErr: "empty expression"},
+		{expr: `A union`, expErr: "unexpected end of the expression, expected a segment slug"},
+		{expr: `A B`, expErr: "unexpected 'B' at position 3, expected 'union', 'intersect', 'except' or the end of the expression"},
+		{expr: `(A union B`, expErr: "unexpected end of the expression, expected ')'"},
+		{expr: `A union ()`, expErr: "unexpected ')' at position 10, expected a segment slug"},
+		{expr: `union A`, expErr: "unexpected 'union' at position 1, expected a segment slug"},
+		{expr: `A & B`, expErr: "unexpected character '&' at position 3"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.expr, func(t *testing.T) {
+			_, err := Parse(tc.expr)
+			require.Error(t, err)
+			assert.Equal(t, tc.expErr, err.Error())
+		})
+	}
+}
+
+func TestOrder(t *testing.T) {
+	parse := func(exprs map[string]string) map[string]*Expr {
+		parsed := make(map[string]*Expr)
+		for slug, src := range exprs {
+			expr, err := Parse(src)
+			require.NoError(t, err)
+			parsed[slug] = expr
+		}
+		return parsed
+	}
+
+	order, err := Order(parse(map[string]string{
+		"ALL":           "MOSCOW_ACTIVE union KAZAN",
+		"KAZAN":         "KAZAN_USERS except BANNED",
+		"MOSCOW":        "MOSCOW_USERS except BANNED",
+		"MOSCOW_ACTIVE": "MOSCOW intersect ACTIVE",
+	}))
+	require.NoError(t, err)
+	assert.DeepEqual(t, []string{"KAZAN", "MOSCOW", "MOSCOW_ACTIVE", "ALL"}, order)
+
+	_, err = Order(parse(map[string]string{
+		"A": "B union C",
+		"B": "C intersect D",
+		"D": "E except B",
+	}))
+	require.ErrorIs(t, err, models.ErrCompositeCycle)
+	assert.Equal(t, "composite segments form a cycle: B -> D -> B", err.Error())
+
+	_, err = Order(parse(map[string]string{"A": "A union B"}))
+	require.ErrorIs(t, err, models.ErrCompositeCycle)
+}
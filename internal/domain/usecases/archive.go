@@ -0,0 +1,244 @@
+package usecases
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"segmentation-service/internal/domain/models"
+	"segmentation-service/internal/domain/rules"
+	"segmentation-service/internal/domain/sets"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ExportState writes the gzip compressed archive of the service state to w. The report includes the archived months.
+func (a *SegmentSvc) ExportState(ctx context.Context, w io.Writer, withReport bool) (err error) {
+	ctx, span := startSpan(ctx, "SegmentSvc.ExportState", attribute.Bool("export.report", withReport))
+	defer func() { endSpan(span, err) }()
+
+	state, archived, err := a.storage.ExportState(ctx, withReport)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if state.Segments == nil {
+		state.Segments = []models.ArchiveSegment{}
+	}
+	if state.Memberships == nil {
+		state.Memberships = []models.ArchiveMembership{}
+	}
+	if withReport {
+		// the archived months are older than the rows still in the database; the files are read as of the snapshot,
+		// so a month archived after it is only exported from the database
+		report, err := a.archivedReport(ctx, archived)
+		if err != nil {
+			return err
+		}
+		state.Report = append(report, state.Report...)
+	}
+	if withReport && state.Report == nil {
+		state.Report = []models.ArchiveReportRow{}
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	archive := models.Archive{
+		Version:   models.ArchiveVersion,
+		CreatedAt: time.Now().UTC(),
+		Checksum:  checksumOf(data),
+		Data:      data,
+	}
+
+	gz := gzip.NewWriter(w)
+	if err = json.NewEncoder(gz).Encode(archive); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// ImportState verifies the version and the checksum of the archive and restores the state in the given mode.
+// Both gzip compressed and plain archives are accepted.
+func (a *SegmentSvc) ImportState(ctx context.Context, r io.Reader, mode string) (result models.RestoreResult, err error) {
+	ctx, span := startSpan(ctx, "SegmentSvc.ImportState", attribute.String("import.mode", mode))
+	defer func() { endSpan(span, err) }()
+
+	if mode == "" {
+		mode = models.RestoreMerge
+	}
+	if mode != models.RestoreMerge && mode != models.RestoreReplace {
+		return result, models.ErrInvalidRestoreMode
+	}
+
+	state, err := decodeArchive(r)
+	if err != nil {
+		return result, err
+	}
+
+	result, err = a.storage.RestoreState(models.WithSource(ctx, models.SourceImport), state, mode)
+	if err != nil {
+		return result, fmt.Errorf("database error: %w", err)
+	}
+	return result, nil
+}
+
+// decodeArchive reads the archive and returns its validated content.
+func decodeArchive(r io.Reader) (models.State, error) {
+	var state models.State
+
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return state, fmt.Errorf("%w: %v", models.ErrInvalidArchive, err)
+		}
+		defer gz.Close()
+		r = gz
+	} else {
+		r = br
+	}
+
+	var archive models.Archive
+	if err := json.NewDecoder(r).Decode(&archive); err != nil {
+		return state, fmt.Errorf("%w: %v", models.ErrInvalidArchive, err)
+	}
+	if archive.Version != models.ArchiveVersion {
+		return state, fmt.Errorf("%w: %d, expected %d", models.ErrArchiveVersion, archive.Version, models.ArchiveVersion)
+	}
+	if checksumOf(archive.Data) != archive.Checksum {
+		return state, models.ErrArchiveChecksum
+	}
+	if err := json.Unmarshal(archive.Data, &state); err != nil {
+		return state, fmt.Errorf("%w: %v", models.ErrInvalidArchive, err)
+	}
+
+	// every membership, report row, expression and experiment variant must refer to a valid segment of the archive,
+	// and no alias may take the slug of a segment or another alias
+	segments := make(map[string]bool, len(state.Segments))
+	aliases := make(map[string]bool)
+	rollouts := make(map[string]bool)
+	composites := make(map[string]*sets.Expr)
+	for _, s := range state.Segments {
+		if !models.SlugRegexp.MatchString(s.Slug) {
+			return state, fmt.Errorf("%w: invalid slug '%s'", models.ErrInvalidArchive, s.Slug)
+		}
+		if s.Rule != "" && s.Expression != "" {
+			return state, fmt.Errorf("%w: segment '%s' has both a rule and an expression", models.ErrInvalidArchive, s.Slug)
+		}
+		if s.Rule != "" {
+			if _, err := rules.Parse(s.Rule); err != nil {
+				return state, fmt.Errorf("%w: invalid rule of '%s': %v", models.ErrInvalidArchive, s.Slug, err)
+			}
+		}
+		if s.Expression != "" {
+			expr, err := sets.Parse(s.Expression)
+			if err != nil {
+				return state, fmt.Errorf("%w: invalid expression of '%s': %v", models.ErrInvalidArchive, s.Slug, err)
+			}
+			composites[s.Slug] = expr
+		}
+		if s.Rollout != nil {
+			if s.Rule != "" || s.Expression != "" {
+				return state, fmt.Errorf("%w: rollout '%s' has a rule or an expression", models.ErrInvalidArchive, s.Slug)
+			}
+			if err := validateRollout(*s.Rollout); err != nil {
+				return state, fmt.Errorf("%w: rollout of '%s': %v", models.ErrInvalidArchive, s.Slug, err)
+			}
+			if s.Salt == "" {
+				return state, fmt.Errorf("%w: rollout '%s' has no salt", models.ErrInvalidArchive, s.Slug)
+			}
+			rollouts[s.Slug] = true
+		} else if len(s.Excluded) != 0 {
+			return state, fmt.Errorf("%w: segment '%s' has exclusions but isn't a rollout", models.ErrInvalidArchive, s.Slug)
+		}
+		if err := validateWindow(s.ActiveFrom, s.ActiveUntil); err != nil {
+			return state, fmt.Errorf("%w: window of '%s': %v", models.ErrInvalidArchive, s.Slug, err)
+		}
+		segments[s.Slug] = true
+		for _, a := range s.Aliases {
+			if !models.SlugRegexp.MatchString(a.Alias) {
+				return state, fmt.Errorf("%w: invalid alias '%s' of '%s'", models.ErrInvalidArchive, a.Alias, s.Slug)
+			}
+			if aliases[a.Alias] {
+				return state, fmt.Errorf("%w: alias '%s' belongs to several segments", models.ErrInvalidArchive, a.Alias)
+			}
+			aliases[a.Alias] = true
+		}
+	}
+	for alias := range aliases {
+		if segments[alias] {
+			return state, fmt.Errorf("%w: alias '%s' is the slug of a segment", models.ErrInvalidArchive, alias)
+		}
+	}
+	for slug, expr := range composites {
+		for _, s := range expr.Segments() {
+			if !segments[s] {
+				return state, fmt.Errorf("%w: expression of '%s' refers to unknown segment '%s'", models.ErrInvalidArchive, slug, s)
+			}
+			if rollouts[s] {
+				return state, fmt.Errorf("%w: expression of '%s' refers to rollout '%s'", models.ErrInvalidArchive, slug, s)
+			}
+		}
+	}
+	if _, err := sets.Order(composites); err != nil {
+		return state, fmt.Errorf("%w: %v", models.ErrInvalidArchive, err)
+	}
+	variants := make(map[string]bool)
+	for _, group := range state.Experiments {
+		if err := validateExperimentGroup(group); err != nil {
+			return state, fmt.Errorf("%w: %v", models.ErrInvalidArchive, err)
+		}
+		if group.Salt == "" {
+			return state, fmt.Errorf("%w: experiment group '%s' has no salt", models.ErrInvalidArchive, group.Name)
+		}
+		for _, v := range group.Variants {
+			if !segments[v.Slug] {
+				return state, fmt.Errorf("%w: experiment group '%s' refers to unknown segment '%s'", models.ErrInvalidArchive, group.Name, v.Slug)
+			}
+			if variants[v.Slug] {
+				return state, fmt.Errorf("%w: segment '%s' is a variant of several experiment groups", models.ErrInvalidArchive, v.Slug)
+			}
+			variants[v.Slug] = true
+		}
+	}
+	for _, s := range state.Segments {
+		if variants[s.Slug] && (s.Rule != "" || s.Expression != "" || s.Rollout != nil) {
+			return state, fmt.Errorf("%w: variant '%s' has a rule, an expression or a rollout", models.ErrInvalidArchive, s.Slug)
+		}
+	}
+	for _, m := range state.Memberships {
+		if !segments[m.Segment] {
+			return state, fmt.Errorf("%w: membership of unknown segment '%s'", models.ErrInvalidArchive, m.Segment)
+		}
+	}
+	for _, a := range state.Attributes {
+		if err := validateAttributes(a.Attributes); err != nil {
+			return state, fmt.Errorf("%w: %v", models.ErrInvalidArchive, err)
+		}
+	}
+	for _, row := range state.Report {
+		if !segments[row.Segment] {
+			return state, fmt.Errorf("%w: report row of unknown segment '%s'", models.ErrInvalidArchive, row.Segment)
+		}
+		if row.Action != models.ActAdd && row.Action != models.ActRemove {
+			return state, fmt.Errorf("%w: invalid report action '%s'", models.ErrInvalidArchive, row.Action)
+		}
+		if err := (models.Attribution{Actor: row.Actor, Source: row.Source, Reason: row.Reason}).Validate(); err != nil {
+			return state, fmt.Errorf("%w: %v", models.ErrInvalidArchive, err)
+		}
+	}
+	return state, nil
+}
+
+// checksumOf returns the hex encoded sha256 of the archive data.
+func checksumOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,189 @@
+package usecases
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"segmentation-service/internal/domain/models"
+	"segmentation-service/internal/ports/mocks"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+)
+
+func TestExportImportStateRoundtrip(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+	storage := mocks.NewMockSegmentStorage(c)
+	svc := New(storage)
+	ctx := context.Background()
+
+	rollout := 25.0
+	until := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	state := models.State{
+		Segments: []models.ArchiveSegment{
+			{Slug: "TEST1"}, {Slug: "TEST2", ActiveUntil: &until},
+			{Slug: "TEST3", Rollout: &rollout, Salt: "TEST3", Excluded: []uuid.UUID{uuid.MustParse(user1)},
+				Aliases: []models.ArchiveAlias{{Alias: "OLD_TEST3", ExpiresAt: until}}},
+		},
+		Memberships: []models.ArchiveMembership{{UserID: uuid.MustParse(user1), Segment: "TEST1"}},
+		Experiments: []models.ExperimentGroup{{
+			Name: "EXP", Salt: "EXP", Conflict: models.ConflictReject,
+			Variants: []models.Variant{{Slug: "TEST1", Weight: 1}, {Slug: "TEST2", Weight: 1}},
+		}},
+		Report: []models.ArchiveReportRow{
+			{UserID: uuid.MustParse(user1), Segment: "TEST1", Action: models.ActAdd, Time: time.Date(2023, 8, 1, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+	expResult := models.RestoreResult{Mode: models.RestoreReplace, SegmentsCreated: 3, MembershipsAdded: 1, AliasesRestored: 1, ReportRestored: 1}
+	storage.EXPECT().ExportState(gomock.Any(), true).Return(state, models.ArchivedMonths{}, nil)
+	storage.EXPECT().RestoreState(gomock.Any(), state, models.RestoreReplace).Return(expResult, nil)
+
+	var buf bytes.Buffer
+	require.NoError(t, svc.ExportState(ctx, &buf, true))
+	result, err := svc.ImportState(ctx, &buf, models.RestoreReplace)
+	require.NoError(t, err)
+	assert.DeepEqual(t, expResult, result)
+}
+
+func TestExportStateArchivedMonths(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storage := mocks.NewMockSegmentStorage(ctrl)
+	archive := mocks.NewMockReportArchive(ctrl)
+	svc := New(storage, WithReportArchive(archive, 12))
+
+	user := uuid.MustParse(user1)
+	run := models.ReportArchiveRun{Month: "2023-08", State: models.ArchiveArchived, File: "report-2023-08.csv.gz", Rows: 2}
+	archivedAt := time.Date(2023, 8, 1, 10, 0, 0, 0, time.UTC)
+	dbRow := models.ArchiveReportRow{UserID: user, Segment: "BETA", Action: models.ActRemove, Time: time.Date(2023, 9, 1, 10, 0, 0, 0, time.UTC)}
+	state := models.State{Segments: []models.ArchiveSegment{{Slug: "BETA"}}, Report: []models.ArchiveReportRow{dbRow}}
+
+	// only the months archived in the snapshot of the export are read from the archive, with the names of the snapshot
+	storage.EXPECT().ExportState(gomock.Any(), true).Return(state, models.ArchivedMonths{
+		Runs: []models.ReportArchiveRun{run}, Names: map[int32]string{1: "BETA"},
+	}, nil)
+	archive.EXPECT().Read(gomock.Any(), run, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, run models.ReportArchiveRun, visit func(models.ArchivedReportRow) error) error {
+			for _, row := range []models.ArchivedReportRow{
+				{SegmentID: 1, Segment: "OLD_NAME", UserID: user, Action: models.ActAdd, Time: archivedAt, Source: models.SourceAPI},
+				{SegmentID: 2, Segment: "DELETED", UserID: user, Action: models.ActAdd, Time: archivedAt, Source: models.SourceAPI},
+			} {
+				if err := visit(row); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+
+	var buf bytes.Buffer
+	require.NoError(t, svc.ExportState(context.Background(), &buf, true))
+	exported, err := decodeArchive(&buf)
+	require.NoError(t, err)
+	assert.DeepEqual(t, []models.ArchiveReportRow{
+		{UserID: user, Segment: "BETA", Action: models.ActAdd, Time: archivedAt, Source: models.SourceAPI},
+		dbRow,
+	}, exported.Report)
+}
+
+func TestImportStateErrors(t *testing.T) {
+	data := []byte(`{"segments":[{"slug":"TEST1"}],"memberships":[]}`)
+	archive := func(version int, checksum string, data string) string {
+		body, _ := json.Marshal(models.Archive{Version: version, Checksum: checksum, Data: json.RawMessage(data)})
+		return string(body)
+	}
+	unknownSegment := []byte(`{"segments":[],"memberships":[{"user_id":"` + user1 + `","segment":"TEST1"}]}`)
+	unknownVariant := []byte(`{"segments":[{"slug":"TEST1"}],"memberships":[],"experiments":[` +
+		`{"name":"EXP","salt":"EXP","conflict":"reject","variants":[{"slug":"TEST1","weight":1},{"slug":"TEST2","weight":1}]}]}`)
+
+	unknownReportSegment := []byte(`{"segments":[{"slug":"TEST1"}],"memberships":[],"report":[` +
+		`{"user_id":"` + user1 + `","segment":"TEST2","action":"add","time":"2023-08-01T10:00:00Z"}]}`)
+
+	aliasOfSlug := []byte(`{"segments":[{"slug":"TEST1"},{"slug":"TEST2","aliases":[{"alias":"TEST1","expires_at":"2023-10-01T00:00:00Z"}]}],"memberships":[]}`)
+	sharedAlias := []byte(`{"segments":[{"slug":"TEST1","aliases":[{"alias":"OLD","expires_at":"2023-10-01T00:00:00Z"}]},` +
+		`{"slug":"TEST2","aliases":[{"alias":"OLD","expires_at":"2023-10-01T00:00:00Z"}]}],"memberships":[]}`)
+
+	emptyWindow := []byte(`{"segments":[{"slug":"TEST1","active_from":"2023-10-01T00:00:00Z","active_until":"2023-09-01T00:00:00Z"}],"memberships":[]}`)
+	rolloutInExpression := []byte(`{"segments":[{"slug":"TEST1","rollout":10,"salt":"TEST1"},{"slug":"TEST2","expression":"TEST1"}],"memberships":[]}`)
+
+	testCases := []struct {
+		name   string
+		file   string
+		mode   string
+		expErr error
+	}{
+		{
+			name:   "Invalid mode",
+			file:   archive(models.ArchiveVersion, checksumOf(data), string(data)),
+			mode:   "append",
+			expErr: models.ErrInvalidRestoreMode,
+		},
+		{
+			name:   "Not an archive",
+			file:   "user_id,segment\n",
+			expErr: models.ErrInvalidArchive,
+		},
+		{
+			name:   "Unsupported version",
+			file:   archive(models.ArchiveVersion+1, checksumOf(data), string(data)),
+			expErr: models.ErrArchiveVersion,
+		},
+		{
+			name:   "Checksum mismatch",
+			file:   archive(models.ArchiveVersion, checksumOf(data), `{"segments":[{"slug":"TEST2"}],"memberships":[]}`),
+			expErr: models.ErrArchiveChecksum,
+		},
+		{
+			name:   "Membership of unknown segment",
+			file:   archive(models.ArchiveVersion, checksumOf(unknownSegment), string(unknownSegment)),
+			expErr: models.ErrInvalidArchive,
+		},
+		{
+			name:   "Variant of unknown segment",
+			file:   archive(models.ArchiveVersion, checksumOf(unknownVariant), string(unknownVariant)),
+			expErr: models.ErrInvalidArchive,
+		},
+		{
+			name:   "Report row of unknown segment",
+			file:   archive(models.ArchiveVersion, checksumOf(unknownReportSegment), string(unknownReportSegment)),
+			expErr: models.ErrInvalidArchive,
+		},
+		{
+			name:   "Alias is the slug of a segment",
+			file:   archive(models.ArchiveVersion, checksumOf(aliasOfSlug), string(aliasOfSlug)),
+			expErr: models.ErrInvalidArchive,
+		},
+		{
+			name:   "Alias of several segments",
+			file:   archive(models.ArchiveVersion, checksumOf(sharedAlias), string(sharedAlias)),
+			expErr: models.ErrInvalidArchive,
+		},
+		{
+			name:   "Expression over rollout",
+			file:   archive(models.ArchiveVersion, checksumOf(rolloutInExpression), string(rolloutInExpression)),
+			expErr: models.ErrInvalidArchive,
+		},
+		{
+			name:   "Window ends before it starts",
+			file:   archive(models.ArchiveVersion, checksumOf(emptyWindow), string(emptyWindow)),
+			expErr: models.ErrInvalidArchive,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+			svc := New(mocks.NewMockSegmentStorage(c))
+
+			_, err := svc.ImportState(context.Background(), strings.NewReader(tc.file), tc.mode)
+			require.True(t, errors.Is(err, tc.expErr), "unexpected error: %v", err)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"segmentation-service/internal/domain/models"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// SetSegmentCap validates and saves the maximum number of members of the segment, nil removes the cap. Additions
+// to a full segment are rejected with models.ErrSegmentFull, while the rules, the expressions and the imports stop
+// at the cap. The members above a lowered cap are kept. A percentage rollout can't have a cap, since its members
+// aren't stored.
+func (a *SegmentSvc) SetSegmentCap(ctx context.Context, slug string, maxMembers *int) (result models.SegmentState, err error) {
+	ctx, span := startSpan(ctx, "SegmentSvc.SetSegmentCap", attribute.String("segment.slug", slug))
+	defer func() { endSpan(span, err) }()
+
+	if maxMembers != nil {
+		if err = validateCap(*maxMembers); err != nil {
+			return result, err
+		}
+	}
+	if slug, err = a.resolveSlug(ctx, slug); err != nil {
+		return result, err
+	}
+
+	result, err = a.storage.SetSegmentCap(ctx, slug, maxMembers)
+	if err != nil && !errors.Is(err, models.ErrSegmentNotFound) && !errors.Is(err, models.ErrRolloutSegment) {
+		return result, fmt.Errorf("database error: %w", err)
+	}
+	return result, err
+}
+
+// validateCap checks that the cap lets at least one member in.
+func validateCap(maxMembers int) error {
+	if maxMembers < 1 {
+		return fmt.Errorf("%w: max_members must be positive, got %d", models.ErrInvalidCap, maxMembers)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"segmentation-service/internal/domain/models"
+	"segmentation-service/internal/ports/mocks"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+)
+
+func TestSetSegmentCap(t *testing.T) {
+	limit := func(n int) *int { return &n }
+
+	// prepare test data
+	testCases := []struct {
+		name          string
+		maxMembers    *int
+		mockBehaviour func(m *mocks.MockSegmentStorage)
+		expState      models.SegmentState
+		expErr        error
+	}{
+		{
+			name:       "Set cap",
+			maxMembers: limit(100),
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().SetSegmentCap(gomock.Any(), "BETA", limit(100)).
+					Return(models.SegmentState{Slug: "BETA", State: models.StateActive, MaxMembers: limit(100), Members: limit(100)}, nil)
+			},
+			expState: models.SegmentState{Slug: "BETA", State: models.StateActive, MaxMembers: limit(100), Members: limit(100)},
+		},
+		{
+			name: "Remove cap",
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().SetSegmentCap(gomock.Any(), "BETA", nil).Return(models.SegmentState{Slug: "BETA", State: models.StateActive}, nil)
+			},
+			expState: models.SegmentState{Slug: "BETA", State: models.StateActive},
+		},
+		{
+			name:          "Zero cap",
+			maxMembers:    limit(0),
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {},
+			expErr:        models.ErrInvalidCap,
+		},
+		{
+			name:          "Negative cap",
+			maxMembers:    limit(-5),
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {},
+			expErr:        models.ErrInvalidCap,
+		},
+		{
+			name:       "Percentage rollout",
+			maxMembers: limit(100),
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().SetSegmentCap(gomock.Any(), "BETA", limit(100)).
+					Return(models.SegmentState{}, fmt.Errorf("%w: its members aren't stored", models.ErrRolloutSegment))
+			},
+			expErr: models.ErrRolloutSegment,
+		},
+		{
+			name:       "Segment not found",
+			maxMembers: limit(100),
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().SetSegmentCap(gomock.Any(), "BETA", limit(100)).Return(models.SegmentState{}, models.ErrSegmentNotFound)
+			},
+			expErr: models.ErrSegmentNotFound,
+		},
+		{
+			name:       "Database error",
+			maxMembers: limit(100),
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().SetSegmentCap(gomock.Any(), "BETA", limit(100)).Return(models.SegmentState{}, errors.New("some error"))
+			},
+			expErr: errors.New("database error: some error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			storage := mocks.NewMockSegmentStorage(ctrl)
+			withoutAliases(storage)
+			tc.mockBehaviour(storage)
+
+			state, err := New(storage).SetSegmentCap(context.Background(), "BETA", tc.maxMembers)
+			if tc.expErr != nil {
+				require.ErrorContains(t, err, tc.expErr.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.DeepEqual(t, tc.expState, state)
+		})
+	}
+}
+
+func TestCreateSegmentWithCap(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storage := mocks.NewMockSegmentStorage(ctrl)
+	withoutAliases(storage)
+	maxMembers := 10000
+
+	// the cap is saved with the rule, so that the rule enrolls the users up to it
+	segment := models.Segment{Slug: "BETA", Rule: `city == "Moscow"`, MaxMembers: &maxMembers}
+	gomock.InOrder(
+		storage.EXPECT().FindSegment(gomock.Any(), "BETA").Return(0, nil),
+		storage.EXPECT().SaveSegment(gomock.Any(), segment, gomock.Any()).Return(nil),
+	)
+
+	svc := New(storage)
+	err := svc.CreateSegment(context.Background(), segment)
+	require.NoError(t, err)
+
+	// a percentage rollout can't have a cap, since its members aren't stored
+	rollout := 10.0
+	err = svc.CreateSegment(context.Background(), models.Segment{Slug: "BETA", Rollout: &rollout, MaxMembers: &maxMembers})
+	require.ErrorIs(t, err, models.ErrInvalidCap)
+}
+
+func TestSegmentFull(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storage := mocks.NewMockSegmentStorage(ctrl)
+	withoutAliases(storage)
+	full := fmt.Errorf("%w: 'BETA' has 100 of 100 members", models.ErrSegmentFull)
+	data := models.UpdateRequest{SegmentsToAdd: []string{"BETA"}}
+
+	storage.EXPECT().GetSegmentDefinitions(gomock.Any()).Return(map[string]models.Segment{}, nil)
+	storage.EXPECT().UpdateUserSegments(gomock.Any(), data, uuid.MustParse(user1), gomock.Any()).Return(models.MembershipDiff{}, full)
+	storage.EXPECT().AssignExperiment(gomock.Any(), "EXP", uuid.MustParse(user1), gomock.Any()).Return(models.Assignment{}, full)
+
+	// the error isn't wrapped as a database error, so that it gets its own status
+	svc := New(storage)
+	_, err := svc.UpdateUserSegments(context.Background(), data, uuid.MustParse(user1))
+	require.ErrorIs(t, err, models.ErrSegmentFull)
+	require.NotContains(t, err.Error(), "database error")
+
+	_, err = svc.AssignExperiment(context.Background(), "EXP", uuid.MustParse(user1))
+	require.ErrorIs(t, err, models.ErrSegmentFull)
+	require.NotContains(t, err.Error(), "database error")
+}
@@ -0,0 +1,102 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"segmentation-service/internal/domain/models"
+	"segmentation-service/internal/domain/sets"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// SetSegmentExpression validates and saves the expression of the segment, then brings its members in line with
+// the expression. An empty expression turns the segment into an ordinary one, keeping its members.
+func (a *SegmentSvc) SetSegmentExpression(ctx context.Context, slug, expression string) (result models.SyncResult, err error) {
+	ctx, span := startSpan(ctx, "SegmentSvc.SetSegmentExpression", attribute.String("segment.slug", slug))
+	defer func() { endSpan(span, err) }()
+
+	if slug, err = a.resolveSlug(ctx, slug); err != nil {
+		return result, err
+	}
+	expression = strings.TrimSpace(expression)
+	if expression != "" {
+		if expression, err = a.resolveExpression(ctx, expression); err != nil {
+			return result, err
+		}
+		if err = a.checkExpression(ctx, slug, expression); err != nil {
+			return result, err
+		}
+	}
+
+	result, err = a.storage.SetSegmentExpression(ctx, slug, expression)
+	if err != nil && !isExpressionError(err) {
+		return result, fmt.Errorf("database error: %w", err)
+	}
+	return result, err
+}
+
+// GetSegmentMembers returns a page of the members of the segment, starting after the given user ID.
+func (a *SegmentSvc) GetSegmentMembers(ctx context.Context, slug string, after uuid.UUID, limit int) (result models.MembersList, err error) {
+	ctx, span := startSpan(ctx, "SegmentSvc.GetSegmentMembers", attribute.String("segment.slug", slug))
+	defer func() { endSpan(span, err) }()
+
+	if slug, err = a.resolveSlug(ctx, slug); err != nil {
+		return result, err
+	}
+	members, err := a.storage.GetSegmentMembers(ctx, slug, after, limit)
+	if errors.Is(err, models.ErrSegmentNotFound) || errors.Is(err, models.ErrRolloutSegment) {
+		return result, err
+	}
+	if err != nil {
+		return result, fmt.Errorf("database error: %w", err)
+	}
+	result.Members = members
+	if len(members) == limit && limit > 0 {
+		result.Next = members[len(members)-1].String()
+	}
+	return result, nil
+}
+
+// checkExpression parses the expression and checks that it doesn't refer to the segment itself and that all
+// the segments it refers to exist and aren't percentage rollouts. Cycles through other composite segments are
+// detected by the storage.
+func (a *SegmentSvc) checkExpression(ctx context.Context, slug, expression string) error {
+	expr, err := sets.Parse(expression)
+	if err != nil {
+		return fmt.Errorf("%w: %v", models.ErrInvalidExpression, err)
+	}
+	if slices.Contains(expr.Segments(), slug) {
+		return fmt.Errorf("%w: %s -> %s", models.ErrCompositeCycle, slug, slug)
+	}
+	for _, s := range expr.Segments() {
+		count, err := a.storage.FindSegment(ctx, s)
+		if err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+		if count == 0 {
+			return fmt.Errorf("%w: '%s'", models.ErrSegmentNotFound, s)
+		}
+	}
+	// the members of a rollout aren't stored, so a composite segment can't be computed from them
+	definitions, err := a.storage.GetSegmentDefinitions(ctx)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	for _, s := range expr.Segments() {
+		if definitions[s].Rollout != nil {
+			return fmt.Errorf("%w: '%s' can't be used in an expression", models.ErrRolloutSegment, s)
+		}
+	}
+	return nil
+}
+
+// isExpressionError reports whether the storage rejected the expression, rather than failed.
+func isExpressionError(err error) bool {
+	return errors.Is(err, models.ErrSegmentNotFound) || errors.Is(err, models.ErrInvalidExpression) ||
+		errors.Is(err, models.ErrCompositeCycle) || errors.Is(err, models.ErrSegmentInExperiment) ||
+		errors.Is(err, models.ErrRolloutSegment)
+}
@@ -0,0 +1,199 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"segmentation-service/internal/domain/models"
+	"segmentation-service/internal/ports/mocks"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+)
+
+func TestSetSegmentExpression(t *testing.T) {
+	rollout := 10.0
+
+	// prepare test data
+	testCases := []struct {
+		name          string
+		expression    string
+		mockBehaviour func(m *mocks.MockSegmentStorage)
+		expResult     models.SyncResult
+		expErr        error
+	}{
+		{
+			name:       "OK",
+			expression: ` A union B except C `,
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().FindSegment(gomock.Any(), gomock.Any()).Return(1, nil).Times(3)
+				m.EXPECT().GetSegmentDefinitions(gomock.Any()).Return(map[string]models.Segment{}, nil)
+				m.EXPECT().SetSegmentExpression(gomock.Any(), "TEST", `A union B except C`).Return(models.SyncResult{Added: 3}, nil)
+			},
+			expResult: models.SyncResult{Added: 3},
+		},
+		{
+			name:       "Clear expression",
+			expression: "",
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().SetSegmentExpression(gomock.Any(), "TEST", "").Return(models.SyncResult{}, nil)
+			},
+		},
+		{
+			name:          "Invalid expression",
+			expression:    `A union`,
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {},
+			expErr:        models.ErrInvalidExpression,
+		},
+		{
+			name:          "Self reference",
+			expression:    `A union TEST`,
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {},
+			expErr:        models.ErrCompositeCycle,
+		},
+		{
+			name:       "Unknown segment",
+			expression: `A union B`,
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().FindSegment(gomock.Any(), "A").Return(1, nil)
+				m.EXPECT().FindSegment(gomock.Any(), "B").Return(0, nil)
+			},
+			expErr: errors.New("segment not found: 'B'"),
+		},
+		{
+			name:       "Rollout segment",
+			expression: `A union B`,
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().FindSegment(gomock.Any(), gomock.Any()).Return(1, nil).Times(2)
+				m.EXPECT().GetSegmentDefinitions(gomock.Any()).Return(map[string]models.Segment{"B": {Slug: "B", Rollout: &rollout}}, nil)
+			},
+			expErr: models.ErrRolloutSegment,
+		},
+		{
+			name:       "Cycle",
+			expression: `A`,
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().FindSegment(gomock.Any(), "A").Return(1, nil)
+				m.EXPECT().GetSegmentDefinitions(gomock.Any()).Return(map[string]models.Segment{}, nil)
+				m.EXPECT().SetSegmentExpression(gomock.Any(), "TEST", "A").Return(models.SyncResult{}, models.ErrCompositeCycle)
+			},
+			expErr: models.ErrCompositeCycle,
+		},
+		{
+			name:       "Database error",
+			expression: `A`,
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().FindSegment(gomock.Any(), "A").Return(1, nil)
+				m.EXPECT().GetSegmentDefinitions(gomock.Any()).Return(map[string]models.Segment{}, nil)
+				m.EXPECT().SetSegmentExpression(gomock.Any(), "TEST", "A").Return(models.SyncResult{}, errors.New("some error"))
+			},
+			expErr: errors.New("database error: some error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			storage := mocks.NewMockSegmentStorage(ctrl)
+			withoutAliases(storage)
+			tc.mockBehaviour(storage)
+
+			result, err := New(storage).SetSegmentExpression(context.Background(), "TEST", tc.expression)
+			if tc.expErr != nil {
+				require.ErrorContains(t, err, tc.expErr.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.DeepEqual(t, tc.expResult, result)
+		})
+	}
+}
+
+func TestCreateCompositeSegment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storage := mocks.NewMockSegmentStorage(ctrl)
+	withoutAliases(storage)
+	rollout := 10.0
+
+	// an expression over a rollout is rejected before the segment is saved, so a retry can create it
+	storage.EXPECT().FindSegment(gomock.Any(), "A").Return(1, nil)
+	storage.EXPECT().GetSegmentDefinitions(gomock.Any()).Return(map[string]models.Segment{"A": {Slug: "A", Rollout: &rollout}}, nil)
+	err := New(storage).CreateSegment(context.Background(), models.Segment{Slug: "TEST", Expression: "A"})
+	require.ErrorIs(t, err, models.ErrRolloutSegment)
+
+	// the segment is saved with its expression at once
+	segment := models.Segment{Slug: "TEST", Expression: "A"}
+	storage.EXPECT().FindSegment(gomock.Any(), "A").Return(1, nil)
+	storage.EXPECT().GetSegmentDefinitions(gomock.Any()).Return(map[string]models.Segment{}, nil)
+	storage.EXPECT().FindSegment(gomock.Any(), "TEST").Return(0, nil)
+	storage.EXPECT().SaveSegment(gomock.Any(), segment, gomock.Any()).Return(nil)
+	err = New(storage).CreateSegment(context.Background(), models.Segment{Slug: "TEST", Expression: " A "})
+	require.NoError(t, err)
+}
+
+func TestGetSegmentMembers(t *testing.T) {
+	members := []uuid.UUID{uuid.MustParse(user1), uuid.MustParse(user2)}
+
+	// prepare test data
+	testCases := []struct {
+		name          string
+		limit         int
+		mockBehaviour func(m *mocks.MockSegmentStorage)
+		expResult     models.MembersList
+		expErr        error
+	}{
+		{
+			name:  "Last page",
+			limit: 3,
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().GetSegmentMembers(gomock.Any(), "TEST", uuid.Nil, 3).Return(members, nil)
+			},
+			expResult: models.MembersList{Members: members},
+		},
+		{
+			name:  "Full page",
+			limit: 2,
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().GetSegmentMembers(gomock.Any(), "TEST", uuid.Nil, 2).Return(members, nil)
+			},
+			expResult: models.MembersList{Members: members, Next: user2},
+		},
+		{
+			name:  "Segment not found",
+			limit: 2,
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().GetSegmentMembers(gomock.Any(), "TEST", uuid.Nil, 2).Return(nil, models.ErrSegmentNotFound)
+			},
+			expErr: models.ErrSegmentNotFound,
+		},
+		{
+			name:  "Database error",
+			limit: 2,
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().GetSegmentMembers(gomock.Any(), "TEST", uuid.Nil, 2).Return(nil, errors.New("some error"))
+			},
+			expErr: errors.New("database error: some error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			storage := mocks.NewMockSegmentStorage(ctrl)
+			withoutAliases(storage)
+			tc.mockBehaviour(storage)
+
+			result, err := New(storage).GetSegmentMembers(context.Background(), "TEST", uuid.Nil, tc.limit)
+			if tc.expErr != nil {
+				require.ErrorContains(t, err, tc.expErr.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.DeepEqual(t, tc.expResult, result)
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"segmentation-service/internal/domain/bucket"
+	"segmentation-service/internal/domain/models"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// maxExperimentWeight limits the weight of a variant, so that the total weight can't overflow.
+const maxExperimentWeight = 1000000
+
+// CreateExperimentGroup validates and saves the experiment group. The salt defaults to the name of the group
+// and the conflict policy to models.ConflictReject. The saved group is returned.
+func (a *SegmentSvc) CreateExperimentGroup(ctx context.Context, group models.ExperimentGroup) (_ models.ExperimentGroup, err error) {
+	ctx, span := startSpan(ctx, "SegmentSvc.CreateExperimentGroup", attribute.String("experiment.group", group.Name))
+	defer func() { endSpan(span, err) }()
+
+	if group.Salt == "" {
+		group.Salt = group.Name
+	}
+	if group.Conflict == "" {
+		group.Conflict = models.ConflictReject
+	}
+	if err = validateExperimentGroup(group); err != nil {
+		return group, err
+	}
+
+	err = a.storage.CreateExperimentGroup(ctx, group)
+	if err != nil && !errors.Is(err, models.ErrSegmentNotFound) && !errors.Is(err, models.ErrDynamicSegment) &&
+		!errors.Is(err, models.ErrSegmentInExperiment) && !errors.Is(err, models.ErrExperimentExists) &&
+		!errors.Is(err, models.ErrExperimentConflict) && !errors.Is(err, models.ErrRolloutSegment) {
+		return group, fmt.Errorf("database error: %w", err)
+	}
+	return group, err
+}
+
+func (a *SegmentSvc) DeleteExperimentGroup(ctx context.Context, name string) (err error) {
+	ctx, span := startSpan(ctx, "SegmentSvc.DeleteExperimentGroup", attribute.String("experiment.group", name))
+	defer func() { endSpan(span, err) }()
+
+	err = a.storage.DeleteExperimentGroup(ctx, name)
+	if err != nil && !errors.Is(err, models.ErrExperimentNotFound) {
+		return fmt.Errorf("database error: %w", err)
+	}
+	return err
+}
+
+func (a *SegmentSvc) ListExperimentGroups(ctx context.Context) (result models.ExperimentGroupsList, err error) {
+	ctx, span := startSpan(ctx, "SegmentSvc.ListExperimentGroups")
+	defer func() { endSpan(span, err) }()
+
+	result.Groups, err = a.storage.ListExperimentGroups(ctx)
+	if err != nil {
+		return result, fmt.Errorf("database error: %w", err)
+	}
+	return result, nil
+}
+
+// AssignExperiment puts the user into a variant of the experiment group, chosen by the weights of the variants
+// and the hash of the user ID and the salt of the group. A user that is already in a variant stays there, a user
+// whose variant is full isn't assigned.
+func (a *SegmentSvc) AssignExperiment(ctx context.Context, name string, userID uuid.UUID) (result models.Assignment, err error) {
+	ctx, span := startSpan(ctx, "SegmentSvc.AssignExperiment",
+		attribute.String("experiment.group", name),
+		attribute.String("user.id", userID.String()),
+	)
+	defer func() { endSpan(span, err) }()
+
+	result, err = a.storage.AssignExperiment(ctx, name, userID, pickVariant)
+	if err != nil && !errors.Is(err, models.ErrExperimentNotFound) && !errors.Is(err, models.ErrSegmentFull) {
+		return result, fmt.Errorf("database error: %w", err)
+	}
+	return result, err
+}
+
+// pickVariant is the models.VariantPicker of the service.
+func pickVariant(group models.ExperimentGroup, userID uuid.UUID) string {
+	weights := make([]int, 0, len(group.Variants))
+	for _, v := range group.Variants {
+		weights = append(weights, v.Weight)
+	}
+	return group.Variants[bucket.Pick(group.Salt, userID, weights)].Slug
+}
+
+// validateExperimentGroup checks the name, the conflict policy and the variants of the group.
+func validateExperimentGroup(group models.ExperimentGroup) error {
+	if !models.SlugRegexp.MatchString(group.Name) {
+		return fmt.Errorf("%w: invalid name '%s'", models.ErrInvalidExperiment, group.Name)
+	}
+	if group.Conflict != models.ConflictReject && group.Conflict != models.ConflictMove {
+		return fmt.Errorf("%w: invalid conflict policy '%s', expected '%s' or '%s'",
+			models.ErrInvalidExperiment, group.Conflict, models.ConflictReject, models.ConflictMove)
+	}
+	if len(group.Variants) < 2 {
+		return fmt.Errorf("%w: at least two variants are required", models.ErrInvalidExperiment)
+	}
+	seen := make(map[string]bool, len(group.Variants))
+	for _, v := range group.Variants {
+		if !models.SlugRegexp.MatchString(v.Slug) {
+			return fmt.Errorf("%w: invalid slug '%s'", models.ErrInvalidExperiment, v.Slug)
+		}
+		if seen[v.Slug] {
+			return fmt.Errorf("%w: duplicate variant '%s'", models.ErrInvalidExperiment, v.Slug)
+		}
+		seen[v.Slug] = true
+		if v.Weight < 1 || v.Weight > maxExperimentWeight {
+			return fmt.Errorf("%w: weight of '%s' must be between 1 and %d", models.ErrInvalidExperiment, v.Slug, maxExperimentWeight)
+		}
+	}
+	return nil
+}
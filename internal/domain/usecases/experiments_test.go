@@ -0,0 +1,138 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"segmentation-service/internal/domain/models"
+	"segmentation-service/internal/ports/mocks"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+)
+
+func TestCreateExperimentGroup(t *testing.T) {
+	variants := []models.Variant{{Slug: "EXP_X_CONTROL", Weight: 50}, {Slug: "EXP_X_VARIANT_A", Weight: 50}}
+
+	// prepare test data
+	testCases := []struct {
+		name          string
+		group         models.ExperimentGroup
+		mockBehaviour func(m *mocks.MockSegmentStorage)
+		expGroup      models.ExperimentGroup
+		expErr        error
+	}{
+		{
+			name:  "Defaults",
+			group: models.ExperimentGroup{Name: "EXP_X", Variants: variants},
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().CreateExperimentGroup(gomock.Any(), models.ExperimentGroup{Name: "EXP_X", Salt: "EXP_X", Conflict: models.ConflictReject, Variants: variants}).Return(nil)
+			},
+			expGroup: models.ExperimentGroup{Name: "EXP_X", Salt: "EXP_X", Conflict: models.ConflictReject, Variants: variants},
+		},
+		{
+			name:  "Move policy",
+			group: models.ExperimentGroup{Name: "EXP_X", Salt: "2023-08", Conflict: models.ConflictMove, Variants: variants},
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().CreateExperimentGroup(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			expGroup: models.ExperimentGroup{Name: "EXP_X", Salt: "2023-08", Conflict: models.ConflictMove, Variants: variants},
+		},
+		{
+			name:          "Invalid name",
+			group:         models.ExperimentGroup{Name: "# %EXP", Variants: variants},
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {},
+			expErr:        models.ErrInvalidExperiment,
+		},
+		{
+			name:          "Invalid policy",
+			group:         models.ExperimentGroup{Name: "EXP_X", Conflict: "keep", Variants: variants},
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {},
+			expErr:        models.ErrInvalidExperiment,
+		},
+		{
+			name:          "Single variant",
+			group:         models.ExperimentGroup{Name: "EXP_X", Variants: variants[:1]},
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {},
+			expErr:        models.ErrInvalidExperiment,
+		},
+		{
+			name:          "Duplicate variant",
+			group:         models.ExperimentGroup{Name: "EXP_X", Variants: []models.Variant{variants[0], variants[0]}},
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {},
+			expErr:        models.ErrInvalidExperiment,
+		},
+		{
+			name:          "Zero weight",
+			group:         models.ExperimentGroup{Name: "EXP_X", Variants: []models.Variant{variants[0], {Slug: "EXP_X_VARIANT_A"}}},
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {},
+			expErr:        models.ErrInvalidExperiment,
+		},
+		{
+			name:  "Users in several variants",
+			group: models.ExperimentGroup{Name: "EXP_X", Variants: variants},
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().CreateExperimentGroup(gomock.Any(), gomock.Any()).Return(models.ErrExperimentConflict)
+			},
+			expErr: models.ErrExperimentConflict,
+		},
+		{
+			name:  "Database error",
+			group: models.ExperimentGroup{Name: "EXP_X", Variants: variants},
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().CreateExperimentGroup(gomock.Any(), gomock.Any()).Return(errors.New("some error"))
+			},
+			expErr: errors.New("database error: some error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			storage := mocks.NewMockSegmentStorage(ctrl)
+			tc.mockBehaviour(storage)
+
+			group, err := New(storage).CreateExperimentGroup(context.Background(), tc.group)
+			if tc.expErr != nil {
+				require.ErrorContains(t, err, tc.expErr.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.DeepEqual(t, tc.expGroup, group)
+		})
+	}
+}
+
+func TestAssignExperiment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storage := mocks.NewMockSegmentStorage(ctrl)
+	group := models.ExperimentGroup{
+		Name:     "EXP_X",
+		Salt:     "EXP_X",
+		Variants: []models.Variant{{Slug: "EXP_X_CONTROL", Weight: 1}, {Slug: "EXP_X_VARIANT_A", Weight: 1}},
+	}
+
+	// the picker chooses the same variant for the same user, and both variants get users
+	picked := make(map[string]bool)
+	storage.EXPECT().AssignExperiment(gomock.Any(), "EXP_X", gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, name string, userID uuid.UUID, pick models.VariantPicker) (models.Assignment, error) {
+			segment := pick(group, userID)
+			require.Equal(t, segment, pick(group, userID))
+			picked[segment] = true
+			return models.Assignment{Group: name, Segment: segment, Assigned: true}, nil
+		}).Times(20)
+	for i := 0; i < 20; i++ {
+		result, err := New(storage).AssignExperiment(context.Background(), "EXP_X", uuid.New())
+		require.NoError(t, err)
+		assert.Assert(t, result.Assigned)
+	}
+	assert.DeepEqual(t, map[string]bool{"EXP_X_CONTROL": true, "EXP_X_VARIANT_A": true}, picked)
+
+	storage.EXPECT().AssignExperiment(gomock.Any(), "EXP_Y", gomock.Any(), gomock.Any()).Return(models.Assignment{}, models.ErrExperimentNotFound)
+	_, err := New(storage).AssignExperiment(context.Background(), "EXP_Y", uuid.New())
+	require.ErrorIs(t, err, models.ErrExperimentNotFound)
+	assert.Equal(t, models.ErrExperimentNotFound.Error(), err.Error())
+}
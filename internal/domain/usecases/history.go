@@ -0,0 +1,38 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"segmentation-service/internal/domain/models"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// GetSegmentHistory returns the audit log of the segment: its creation, renames, changes of the definition and
+// activations, with who made them and why.
+func (a *SegmentSvc) GetSegmentHistory(ctx context.Context, slug string) (history models.SegmentHistory, err error) {
+	ctx, span := startSpan(ctx, "SegmentSvc.GetSegmentHistory", attribute.String("segment.slug", slug))
+	defer func() { endSpan(span, err) }()
+
+	if !models.SlugRegexp.MatchString(slug) {
+		return history, models.ErrInvalidSlugFormat
+	}
+	if slug, err = a.resolveSlug(ctx, slug); err != nil {
+		return history, err
+	}
+	history, err = a.storage.GetSegmentHistory(ctx, slug)
+	if err != nil && !errors.Is(err, models.ErrSegmentNotFound) {
+		return history, fmt.Errorf("database error: %w", err)
+	}
+	return history, err
+}
+
+// GetSegmentReport returns the audit log of all segments, including the deleted ones, for the month.
+func (a *SegmentSvc) GetSegmentReport(ctx context.Context, period string) (_ [][]string, err error) {
+	ctx, span := startSpan(ctx, "SegmentSvc.GetSegmentReport", attribute.String("report.period", period))
+	defer func() { endSpan(span, err) }()
+
+	monthBeginning := period + "-01"
+	return a.storage.GetSegmentReport(ctx, monthBeginning)
+}
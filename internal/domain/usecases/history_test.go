@@ -0,0 +1,91 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"segmentation-service/internal/domain/models"
+	"segmentation-service/internal/ports/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+)
+
+func TestGetSegmentHistory(t *testing.T) {
+	history := models.SegmentHistory{Slug: "NEW", Events: []models.SegmentEvent{
+		{Segment: "OLD", Event: models.EventCreated, NewValue: "OLD", Time: time.Date(2023, 8, 1, 0, 0, 0, 0, time.UTC), Source: models.SourceAPI},
+		{Segment: "NEW", Event: models.EventRenamed, OldValue: "OLD", NewValue: "NEW", Time: time.Date(2023, 8, 2, 0, 0, 0, 0, time.UTC), Source: models.SourceAPI},
+	}}
+
+	// prepare test data
+	testCases := []struct {
+		name          string
+		slug          string
+		mockBehaviour func(m *mocks.MockSegmentStorage)
+		expHistory    models.SegmentHistory
+		expErr        error
+	}{
+		{
+			name: "OK",
+			slug: "NEW",
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				withoutAliases(m)
+				m.EXPECT().GetSegmentHistory(gomock.Any(), "NEW").Return(history, nil)
+			},
+			expHistory: history,
+		},
+		{
+			name: "By the old slug",
+			slug: "OLD",
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().ResolveSegmentAliases(gomock.Any(), []string{"OLD"}).
+					Return(map[string]models.SegmentAlias{"OLD": {Alias: "OLD", Slug: "NEW"}}, nil)
+				m.EXPECT().GetSegmentHistory(gomock.Any(), "NEW").Return(history, nil)
+			},
+			expHistory: history,
+		},
+		{
+			name:          "Invalid slug",
+			slug:          "# %",
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {},
+			expErr:        models.ErrInvalidSlugFormat,
+		},
+		{
+			name: "Not found",
+			slug: "TEST",
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				withoutAliases(m)
+				m.EXPECT().GetSegmentHistory(gomock.Any(), "TEST").Return(models.SegmentHistory{}, models.ErrSegmentNotFound)
+			},
+			expErr: models.ErrSegmentNotFound,
+		},
+		{
+			name: "Database error",
+			slug: "TEST",
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				withoutAliases(m)
+				m.EXPECT().GetSegmentHistory(gomock.Any(), "TEST").Return(models.SegmentHistory{}, errors.New("some error"))
+			},
+			expErr: errors.New("database error: some error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			storage := mocks.NewMockSegmentStorage(ctrl)
+			tc.mockBehaviour(storage)
+
+			result, err := New(storage).GetSegmentHistory(context.Background(), tc.slug)
+			if tc.expErr != nil {
+				require.EqualError(t, err, tc.expErr.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.DeepEqual(t, tc.expHistory, result)
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package usecases
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"segmentation-service/internal/domain/bucket"
+	"segmentation-service/internal/domain/models"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// importBatchSize is the number of memberships applied in one storage transaction.
+const importBatchSize = 1000
+
+type importLine struct {
+	number int
+	change models.MembershipChange
+}
+
+// parsedLine is a line of the import file before its segment is checked; err is set if the line is invalid.
+type parsedLine struct {
+	number int
+	change models.MembershipChange
+	err    error
+}
+
+// ImportMemberships reads membership changes from a csv file and applies the valid ones in batches.
+// Each line is either "user_id,segment,action" or, if opts.Segment is set, a single user ID. A header line
+// starting with "user_id" is skipped. Invalid lines are reported with their numbers and don't stop the import.
+// The old slugs of renamed segments are replaced with the current ones and recorded as deprecated. If the same
+// membership is changed on several lines, the last line wins. A dry run applies all the valid lines
+// in one transaction that is rolled back and returns the diff instead.
+func (a *SegmentSvc) ImportMemberships(ctx context.Context, r io.Reader, opts models.ImportOptions) (result models.ImportResult, err error) {
+	ctx, span := startSpan(ctx, "SegmentSvc.ImportMemberships",
+		attribute.String("import.segment", opts.Segment),
+		attribute.Bool("import.dry_run", opts.DryRun),
+	)
+	defer func() { endSpan(span, err) }()
+	ctx = models.WithSource(ctx, models.SourceImport)
+
+	result = models.ImportResult{DryRun: opts.DryRun, Errors: []models.ImportLineError{}}
+	if opts.Segment != "" {
+		if opts.Action == "" {
+			opts.Action = models.ActAdd
+		}
+		if opts.Action != models.ActAdd && opts.Action != models.ActRemove {
+			return result, models.ErrInvalidAction
+		}
+		if !models.SlugRegexp.MatchString(opts.Segment) {
+			return result, models.ErrInvalidSlugFormat
+		}
+		if opts.Segment, err = a.resolveSlug(ctx, opts.Segment); err != nil {
+			return result, err
+		}
+	}
+
+	// the members of dynamic and composite segments are computed and can't be imported, the users of percentage
+	// rollouts are imported as explicit overrides
+	dynamic, err := a.storage.GetSegmentDefinitions(ctx)
+	if err != nil {
+		return result, fmt.Errorf("database error: %w", err)
+	}
+
+	// segment existence is checked once per slug
+	exists := make(map[string]bool)
+	segmentExists := func(slug string) (bool, error) {
+		if ok, checked := exists[slug]; checked {
+			return ok, nil
+		}
+		count, err := a.storage.FindSegment(ctx, slug)
+		if err != nil {
+			return false, fmt.Errorf("database error: %w", err)
+		}
+		exists[slug] = count != 0
+		return count != 0, nil
+	}
+	if opts.Segment != "" {
+		ok, err := segmentExists(opts.Segment)
+		if err != nil {
+			return result, err
+		}
+		if !ok {
+			return result, models.ErrSegmentNotFound
+		}
+		if isComputed(dynamic[opts.Segment]) {
+			return result, models.ErrDynamicSegment
+		}
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	// the file is parsed before the segments are checked, so that the slugs are resolved in one query
+	var parsed []parsedLine
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			result.Total++
+			parsed = append(parsed, parsedLine{number: parseErr.StartLine, err: parseErr.Err})
+			continue
+		}
+		if err != nil {
+			return result, fmt.Errorf("%w: %v", models.ErrInvalidImportFile, err)
+		}
+		// the position is only known for a record that was read without an error
+		line, _ := reader.FieldPos(0)
+		if line == 1 && len(record) > 0 && strings.EqualFold(strings.TrimSpace(record[0]), "user_id") {
+			continue
+		}
+		result.Total++
+
+		change, err := parseImportRecord(record, opts)
+		parsed = append(parsed, parsedLine{number: line, change: change, err: err})
+	}
+	if opts.Segment == "" {
+		if err = a.resolveImportSlugs(ctx, parsed); err != nil {
+			return result, err
+		}
+	}
+
+	var lines []importLine
+	index := make(map[models.MembershipChange]int) // membership without action -> position in lines
+	for _, p := range parsed {
+		line, change, err := p.number, p.change, p.err
+		if err == nil {
+			var ok bool
+			ok, err = segmentExists(change.Segment)
+			if err != nil {
+				return result, err
+			}
+			if !ok {
+				err = models.ErrSegmentNotFound
+			} else if isComputed(dynamic[change.Segment]) {
+				err = models.ErrDynamicSegment
+			}
+		}
+		if err != nil {
+			result.Errors = append(result.Errors, models.ImportLineError{Line: line, Error: err.Error()})
+			continue
+		}
+		result.Valid++
+
+		key := models.MembershipChange{UserID: change.UserID, Segment: change.Segment}
+		if i, ok := index[key]; ok {
+			lines[i] = importLine{number: line, change: change}
+			continue
+		}
+		index[key] = len(lines)
+		lines = append(lines, importLine{number: line, change: change})
+	}
+
+	if opts.DryRun {
+		diff := models.ImportDiff{Added: []models.Membership{}, Removed: []models.Membership{}, NoOp: []models.Membership{}}
+		if len(lines) > 0 {
+			changes := make([]models.MembershipChange, 0, len(lines))
+			for _, l := range lines {
+				changes = append(changes, l.change)
+			}
+			if diff, err = a.storage.DiffMemberships(ctx, changes, bucket.InRollout); err != nil {
+				if errors.Is(err, models.ErrExperimentConflict) {
+					return result, err
+				}
+				return result, fmt.Errorf("database error: %w", err)
+			}
+		}
+		diff.Unknown = []string{}
+		for slug, ok := range exists {
+			if !ok {
+				diff.Unknown = append(diff.Unknown, slug)
+			}
+		}
+		slices.Sort(diff.Unknown)
+		result.Diff = &diff
+		return result, nil
+	}
+
+	// apply the valid lines in batches through the storage
+	for start := 0; start < len(lines); start += importBatchSize {
+		end := start + importBatchSize
+		if end > len(lines) {
+			end = len(lines)
+		}
+		changes := make([]models.MembershipChange, 0, end-start)
+		for _, l := range lines[start:end] {
+			changes = append(changes, l.change)
+		}
+		applied, capped, err := a.storage.ApplyMemberships(ctx, changes, bucket.InRollout)
+		if errors.Is(err, models.ErrExperimentConflict) {
+			return result, fmt.Errorf("applying lines %d-%d failed: %w", lines[start].number, lines[end-1].number, err)
+		}
+		if err != nil {
+			return result, fmt.Errorf("database error: applying lines %d-%d failed: %w", lines[start].number, lines[end-1].number, err)
+		}
+		result.Applied += applied
+		result.Capped += capped
+	}
+	return result, nil
+}
+
+// resolveImportSlugs replaces the old slugs of the valid lines with the current ones, resolving every distinct slug
+// once.
+func (a *SegmentSvc) resolveImportSlugs(ctx context.Context, parsed []parsedLine) error {
+	current := make(map[string]string)
+	var slugs []string
+	for _, p := range parsed {
+		if _, ok := current[p.change.Segment]; p.err == nil && !ok {
+			current[p.change.Segment] = p.change.Segment
+			slugs = append(slugs, p.change.Segment)
+		}
+	}
+	resolved, err := a.resolveSlugs(ctx, slugs)
+	if err != nil {
+		return err
+	}
+	for i, slug := range slugs {
+		current[slug] = resolved[i]
+	}
+	for i := range parsed {
+		if parsed[i].err == nil {
+			parsed[i].change.Segment = current[parsed[i].change.Segment]
+		}
+	}
+	return nil
+}
+
+// parseImportRecord validates a csv record and converts it into a membership change.
+func parseImportRecord(record []string, opts models.ImportOptions) (models.MembershipChange, error) {
+	var change models.MembershipChange
+	for i := range record {
+		record[i] = strings.TrimSpace(record[i])
+	}
+
+	if opts.Segment != "" {
+		if len(record) != 1 {
+			return change, models.ErrInvalidColumns
+		}
+		change.Segment, change.Action = opts.Segment, opts.Action
+	} else {
+		if len(record) != 3 {
+			return change, models.ErrInvalidColumns
+		}
+		change.Segment, change.Action = record[1], strings.ToLower(record[2])
+		if !models.SlugRegexp.MatchString(change.Segment) {
+			return change, models.ErrInvalidSlugFormat
+		}
+		if change.Action != models.ActAdd && change.Action != models.ActRemove {
+			return change, models.ErrInvalidAction
+		}
+	}
+
+	userID, err := uuid.Parse(record[0])
+	if err != nil {
+		return change, models.ErrInvalidUuidFormat
+	}
+	change.UserID = userID
+	return change, nil
+}
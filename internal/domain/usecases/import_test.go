@@ -0,0 +1,240 @@
+package usecases
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"segmentation-service/internal/domain/models"
+	"segmentation-service/internal/ports/mocks"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+)
+
+const (
+	user1 = "550e8400-e29b-41d4-a716-446655440000"
+	user2 = "da3626c2-4747-11ee-be56-0242ac120002"
+)
+
+func TestImportMemberships(t *testing.T) {
+	// prepare test data
+	testCases := []struct {
+		name          string
+		file          string
+		opts          models.ImportOptions
+		mockBehaviour func(m *mocks.MockSegmentStorage)
+		expResult     models.ImportResult
+		expErr        error
+	}{
+		{
+			name: "Rows with actions",
+			file: "user_id,segment,action\n" +
+				user1 + ",TEST1,add\n" +
+				user2 + ",TEST1,remove\n" +
+				"123,TEST1,add\n" +
+				user1 + ",# %TEST,add\n" +
+				user1 + ",UNKNOWN,add\n" +
+				user1 + ",TEST1,move\n" +
+				user1 + ",TEST1\n",
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().FindSegment(gomock.Any(), "TEST1").Return(1, nil)
+				m.EXPECT().FindSegment(gomock.Any(), "UNKNOWN").Return(0, nil)
+				m.EXPECT().ApplyMemberships(gomock.Any(), []models.MembershipChange{
+					{UserID: uuid.MustParse(user1), Segment: "TEST1", Action: models.ActAdd},
+					{UserID: uuid.MustParse(user2), Segment: "TEST1", Action: models.ActRemove},
+				}, gomock.Any()).Return(1, 0, nil)
+			},
+			expResult: models.ImportResult{Total: 7, Valid: 2, Applied: 1, Errors: []models.ImportLineError{
+				{Line: 4, Error: models.ErrInvalidUuidFormat.Error()},
+				{Line: 5, Error: models.ErrInvalidSlugFormat.Error()},
+				{Line: 6, Error: models.ErrSegmentNotFound.Error()},
+				{Line: 7, Error: models.ErrInvalidAction.Error()},
+				{Line: 8, Error: models.ErrInvalidColumns.Error()},
+			}},
+		},
+		{
+			name: "Malformed line",
+			file: "x\"y,TEST1,add\n" + user1 + ",TEST1,add\n",
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().FindSegment(gomock.Any(), "TEST1").Return(1, nil)
+				m.EXPECT().ApplyMemberships(gomock.Any(), []models.MembershipChange{
+					{UserID: uuid.MustParse(user1), Segment: "TEST1", Action: models.ActAdd},
+				}, gomock.Any()).Return(1, 0, nil)
+			},
+			expResult: models.ImportResult{Total: 2, Valid: 1, Applied: 1, Errors: []models.ImportLineError{
+				{Line: 1, Error: csv.ErrBareQuote.Error()},
+			}},
+		},
+		{
+			name: "Single segment",
+			file: user1 + "\n" + user2 + "\n",
+			opts: models.ImportOptions{Segment: "TEST1", Action: models.ActRemove},
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().FindSegment(gomock.Any(), "TEST1").Return(1, nil)
+				m.EXPECT().ApplyMemberships(gomock.Any(), []models.MembershipChange{
+					{UserID: uuid.MustParse(user1), Segment: "TEST1", Action: models.ActRemove},
+					{UserID: uuid.MustParse(user2), Segment: "TEST1", Action: models.ActRemove},
+				}, gomock.Any()).Return(2, 0, nil)
+			},
+			expResult: models.ImportResult{Total: 2, Valid: 2, Applied: 2, Errors: []models.ImportLineError{}},
+		},
+		{
+			name: "Segment full",
+			file: user1 + "\n" + user2 + "\n",
+			opts: models.ImportOptions{Segment: "TEST1", Action: models.ActAdd},
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().FindSegment(gomock.Any(), "TEST1").Return(1, nil)
+				m.EXPECT().ApplyMemberships(gomock.Any(), []models.MembershipChange{
+					{UserID: uuid.MustParse(user1), Segment: "TEST1", Action: models.ActAdd},
+					{UserID: uuid.MustParse(user2), Segment: "TEST1", Action: models.ActAdd},
+				}, gomock.Any()).Return(1, 1, nil)
+			},
+			expResult: models.ImportResult{Total: 2, Valid: 2, Applied: 1, Capped: 1, Errors: []models.ImportLineError{}},
+		},
+		{
+			name: "Last line wins",
+			file: user1 + ",TEST1,add\n" + user1 + ",TEST1,remove\n",
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().FindSegment(gomock.Any(), "TEST1").Return(1, nil)
+				m.EXPECT().ApplyMemberships(gomock.Any(), []models.MembershipChange{
+					{UserID: uuid.MustParse(user1), Segment: "TEST1", Action: models.ActRemove},
+				}, gomock.Any()).Return(1, 0, nil)
+			},
+			expResult: models.ImportResult{Total: 2, Valid: 2, Applied: 1, Errors: []models.ImportLineError{}},
+		},
+		{
+			name: "Dry run",
+			file: user1 + ",TEST1,add\n" + user2 + ",UNKNOWN,add\n",
+			opts: models.ImportOptions{DryRun: true},
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().FindSegment(gomock.Any(), "TEST1").Return(1, nil)
+				m.EXPECT().FindSegment(gomock.Any(), "UNKNOWN").Return(0, nil)
+				m.EXPECT().DiffMemberships(gomock.Any(), []models.MembershipChange{
+					{UserID: uuid.MustParse(user1), Segment: "TEST1", Action: models.ActAdd},
+				}, gomock.Any()).Return(models.ImportDiff{
+					Added:   []models.Membership{{UserID: uuid.MustParse(user1), Segment: "TEST1"}},
+					Removed: []models.Membership{},
+					NoOp:    []models.Membership{},
+				}, nil)
+			},
+			expResult: models.ImportResult{DryRun: true, Total: 2, Valid: 1, Errors: []models.ImportLineError{
+				{Line: 2, Error: models.ErrSegmentNotFound.Error()},
+			}, Diff: &models.ImportDiff{
+				Added:   []models.Membership{{UserID: uuid.MustParse(user1), Segment: "TEST1"}},
+				Removed: []models.Membership{},
+				NoOp:    []models.Membership{},
+				Unknown: []string{"UNKNOWN"},
+			}},
+		},
+		{
+			name: "Unknown single segment",
+			file: user1 + "\n",
+			opts: models.ImportOptions{Segment: "UNKNOWN"},
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().FindSegment(gomock.Any(), "UNKNOWN").Return(0, nil)
+			},
+			expErr: models.ErrSegmentNotFound,
+		},
+		{
+			name: "Dynamic segments",
+			file: user1 + ",TEST1,add\n" + user2 + ",DYNAMIC,add\n",
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().FindSegment(gomock.Any(), "TEST1").Return(1, nil)
+				m.EXPECT().FindSegment(gomock.Any(), "DYNAMIC").Return(1, nil)
+				m.EXPECT().ApplyMemberships(gomock.Any(), []models.MembershipChange{
+					{UserID: uuid.MustParse(user1), Segment: "TEST1", Action: models.ActAdd},
+				}, gomock.Any()).Return(1, 0, nil)
+			},
+			expResult: models.ImportResult{Total: 2, Valid: 1, Applied: 1, Errors: []models.ImportLineError{
+				{Line: 2, Error: models.ErrDynamicSegment.Error()},
+			}},
+		},
+		{
+			name: "Dynamic single segment",
+			file: user1 + "\n",
+			opts: models.ImportOptions{Segment: "DYNAMIC"},
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().FindSegment(gomock.Any(), "DYNAMIC").Return(1, nil)
+			},
+			expErr: models.ErrDynamicSegment,
+		},
+		{
+			name:          "Invalid single segment action",
+			file:          user1 + "\n",
+			opts:          models.ImportOptions{Segment: "TEST1", Action: "move"},
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {},
+			expErr:        models.ErrInvalidAction,
+		},
+		{
+			name: "Database error",
+			file: user1 + ",TEST1,add\n",
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().FindSegment(gomock.Any(), "TEST1").Return(1, nil)
+				m.EXPECT().ApplyMemberships(gomock.Any(), gomock.Any(), gomock.Any()).Return(0, 0, errors.New("some error"))
+			},
+			expErr: errors.New("database error: applying lines 1-1 failed: some error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			storage := mocks.NewMockSegmentStorage(ctrl)
+			withoutAliases(storage)
+			storage.EXPECT().GetSegmentDefinitions(gomock.Any()).Return(map[string]models.Segment{"DYNAMIC": {Slug: "DYNAMIC", Rule: `city == "Moscow"`}}, nil).AnyTimes()
+			tc.mockBehaviour(storage)
+
+			result, err := New(storage).ImportMemberships(context.Background(), strings.NewReader(tc.file), tc.opts)
+			if tc.expErr != nil {
+				require.EqualError(t, err, tc.expErr.Error())
+				return
+			}
+			require.NoError(t, err)
+			tc.expResult.DryRun = tc.opts.DryRun
+			assert.DeepEqual(t, tc.expResult, result)
+		})
+	}
+}
+
+func TestImportMembershipsBatches(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storage := mocks.NewMockSegmentStorage(ctrl)
+	withoutAliases(storage)
+
+	var file strings.Builder
+	for i := 0; i < importBatchSize+1; i++ {
+		file.WriteString(uuid.NewString() + "\n")
+	}
+	storage.EXPECT().GetSegmentDefinitions(gomock.Any()).Return(map[string]models.Segment{}, nil)
+	storage.EXPECT().FindSegment(gomock.Any(), "TEST1").Return(1, nil)
+	storage.EXPECT().ApplyMemberships(gomock.Any(), gomock.Len(importBatchSize), gomock.Any()).Return(importBatchSize, 0, nil)
+	storage.EXPECT().ApplyMemberships(gomock.Any(), gomock.Len(1), gomock.Any()).Return(1, 0, nil)
+
+	result, err := New(storage).ImportMemberships(context.Background(), strings.NewReader(file.String()), models.ImportOptions{Segment: "TEST1"})
+	require.NoError(t, err)
+	assert.Equal(t, importBatchSize+1, result.Applied)
+}
+
+func TestImportMembershipsAttribution(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storage := mocks.NewMockSegmentStorage(ctrl)
+	withoutAliases(storage)
+
+	// the imported changes keep the actor and the reason of the request, but come from the import
+	storage.EXPECT().GetSegmentDefinitions(gomock.Any()).Return(map[string]models.Segment{}, nil)
+	storage.EXPECT().FindSegment(gomock.Any(), "TEST1").Return(1, nil)
+	storage.EXPECT().ApplyMemberships(gomock.Any(), gomock.Len(1), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, changes []models.MembershipChange, inRollout models.RolloutChecker) (int, int, error) {
+			assert.Equal(t, models.Attribution{Actor: "alice", Source: models.SourceImport, Reason: "promo"}, models.AttributionFrom(ctx))
+			return 1, 0, nil
+		})
+
+	ctx := models.WithAttribution(context.Background(), models.Attribution{Actor: "alice", Reason: "promo"})
+	_, err := New(storage).ImportMemberships(ctx, strings.NewReader(user1+"\n"), models.ImportOptions{Segment: "TEST1"})
+	require.NoError(t, err)
+}
@@ -0,0 +1,23 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+)
+
+// reportPartitionsAhead is the number of the months after the current one whose report partitions are created
+// in advance, so that a scheduler that has been stopped for a while doesn't leave the new rows without a partition.
+const reportPartitionsAhead = 3
+
+// CreateReportPartitions creates the missing monthly partitions of the report for the current and the next months
+// and returns their number. It is run periodically by the scheduler.
+func (a *SegmentSvc) CreateReportPartitions(ctx context.Context) (count int, err error) {
+	ctx, span := startSpan(ctx, "SegmentSvc.CreateReportPartitions")
+	defer func() { endSpan(span, err) }()
+
+	count, err = a.storage.CreateReportPartitions(ctx, reportPartitionsAhead)
+	if err != nil {
+		return 0, fmt.Errorf("database error: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,100 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"segmentation-service/internal/domain/models"
+	"segmentation-service/internal/domain/sets"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// RenameSegment changes the slug of the segment, keeping its memberships and history. The old slug keeps working
+// as an alias for the requested number of days and stays reserved afterwards, so no other segment can take it.
+func (a *SegmentSvc) RenameSegment(ctx context.Context, req models.RenameRequest) (result models.SegmentAlias, err error) {
+	ctx, span := startSpan(ctx, "SegmentSvc.RenameSegment",
+		attribute.String("segment.slug", req.Slug),
+		attribute.String("segment.new_slug", req.NewSlug),
+	)
+	defer func() { endSpan(span, err) }()
+
+	if !models.SlugRegexp.MatchString(req.Slug) || !models.SlugRegexp.MatchString(req.NewSlug) {
+		return result, models.ErrInvalidSlugFormat
+	}
+	if req.Slug == req.NewSlug {
+		return result, models.ErrSegmentAlreadyExists
+	}
+	days := models.DefaultAliasDays
+	if req.AliasDays != nil {
+		days = *req.AliasDays
+	}
+	if days < 0 || days > models.MaxAliasDays {
+		return result, fmt.Errorf("%w: expected 0-%d days", models.ErrInvalidAliasDays, models.MaxAliasDays)
+	}
+
+	result, err = a.storage.RenameSegment(ctx, req.Slug, req.NewSlug, time.Duration(days)*24*time.Hour)
+	if err != nil && !errors.Is(err, models.ErrSegmentNotFound) && !errors.Is(err, models.ErrSegmentAlreadyExists) &&
+		!errors.Is(err, models.ErrSlugReserved) {
+		return result, fmt.Errorf("database error: %w", err)
+	}
+	return result, err
+}
+
+// resolveSlugs replaces the old slugs of renamed segments, whose aliases haven't expired, with the current ones.
+// The used aliases are recorded in ctx, so that the transport can tell the client the slugs are deprecated.
+func (a *SegmentSvc) resolveSlugs(ctx context.Context, slugs []string) ([]string, error) {
+	if len(slugs) == 0 {
+		return slugs, nil
+	}
+	aliases, err := a.storage.ResolveSegmentAliases(ctx, slugs)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	if len(aliases) == 0 {
+		return slugs, nil
+	}
+	resolved := make([]string, len(slugs))
+	for i, slug := range slugs {
+		resolved[i] = slug
+		if alias, ok := aliases[slug]; ok {
+			resolved[i] = alias.Slug
+			models.RecordDeprecatedSlug(ctx, alias)
+		}
+	}
+	return resolved, nil
+}
+
+// resolveSlug resolves a single slug like resolveSlugs.
+func (a *SegmentSvc) resolveSlug(ctx context.Context, slug string) (string, error) {
+	resolved, err := a.resolveSlugs(ctx, []string{slug})
+	if err != nil {
+		return "", err
+	}
+	return resolved[0], nil
+}
+
+// resolveExpression rewrites the old slugs in the expression to the current ones. An expression that can't be
+// parsed is returned as is, to be rejected by the validation.
+func (a *SegmentSvc) resolveExpression(ctx context.Context, expression string) (string, error) {
+	expr, err := sets.Parse(expression)
+	if err != nil {
+		return expression, nil
+	}
+	slugs := expr.Segments()
+	resolved, err := a.resolveSlugs(ctx, slugs)
+	if err != nil {
+		return "", err
+	}
+	names := make(map[string]string)
+	for i, slug := range slugs {
+		if resolved[i] != slug {
+			names[slug] = resolved[i]
+		}
+	}
+	if !expr.Rename(names) {
+		return expression, nil
+	}
+	return expr.String(), nil
+}
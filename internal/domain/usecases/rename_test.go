@@ -0,0 +1,160 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"segmentation-service/internal/domain/models"
+	"segmentation-service/internal/ports/mocks"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+)
+
+// withoutAliases makes the storage resolve no slugs, as if no segment was ever renamed.
+func withoutAliases(m *mocks.MockSegmentStorage) {
+	m.EXPECT().ResolveSegmentAliases(gomock.Any(), gomock.Any()).Return(map[string]models.SegmentAlias{}, nil).AnyTimes()
+}
+
+func TestRenameSegment(t *testing.T) {
+	days := func(d int) *int { return &d }
+	expires := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	// prepare test data
+	testCases := []struct {
+		name          string
+		req           models.RenameRequest
+		mockBehaviour func(m *mocks.MockSegmentStorage)
+		expAlias      models.SegmentAlias
+		expErr        error
+	}{
+		{
+			name: "Default alias lifetime",
+			req:  models.RenameRequest{Slug: "OLD", NewSlug: "NEW"},
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().RenameSegment(gomock.Any(), "OLD", "NEW", 30*24*time.Hour).
+					Return(models.SegmentAlias{Alias: "OLD", Slug: "NEW", ExpiresAt: expires}, nil)
+			},
+			expAlias: models.SegmentAlias{Alias: "OLD", Slug: "NEW", ExpiresAt: expires},
+		},
+		{
+			name: "No alias",
+			req:  models.RenameRequest{Slug: "OLD", NewSlug: "NEW", AliasDays: days(0)},
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().RenameSegment(gomock.Any(), "OLD", "NEW", time.Duration(0)).
+					Return(models.SegmentAlias{Alias: "OLD", Slug: "NEW", ExpiresAt: expires}, nil)
+			},
+			expAlias: models.SegmentAlias{Alias: "OLD", Slug: "NEW", ExpiresAt: expires},
+		},
+		{
+			name:          "Invalid new slug",
+			req:           models.RenameRequest{Slug: "OLD", NewSlug: "# %NEW"},
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {},
+			expErr:        models.ErrInvalidSlugFormat,
+		},
+		{
+			name:          "Same slug",
+			req:           models.RenameRequest{Slug: "OLD", NewSlug: "OLD"},
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {},
+			expErr:        models.ErrSegmentAlreadyExists,
+		},
+		{
+			name:          "Alias lifetime too long",
+			req:           models.RenameRequest{Slug: "OLD", NewSlug: "NEW", AliasDays: days(366)},
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {},
+			expErr:        models.ErrInvalidAliasDays,
+		},
+		{
+			name: "Reserved slug",
+			req:  models.RenameRequest{Slug: "OLD", NewSlug: "NEW"},
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().RenameSegment(gomock.Any(), "OLD", "NEW", gomock.Any()).Return(models.SegmentAlias{}, models.ErrSlugReserved)
+			},
+			expErr: models.ErrSlugReserved,
+		},
+		{
+			name: "Database error",
+			req:  models.RenameRequest{Slug: "OLD", NewSlug: "NEW"},
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().RenameSegment(gomock.Any(), "OLD", "NEW", gomock.Any()).Return(models.SegmentAlias{}, errors.New("some error"))
+			},
+			expErr: errors.New("database error: some error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			storage := mocks.NewMockSegmentStorage(ctrl)
+			tc.mockBehaviour(storage)
+
+			alias, err := New(storage).RenameSegment(context.Background(), tc.req)
+			if tc.expErr != nil {
+				require.ErrorContains(t, err, tc.expErr.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.DeepEqual(t, tc.expAlias, alias)
+		})
+	}
+}
+
+func TestUpdateUserSegmentsByAlias(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storage := mocks.NewMockSegmentStorage(ctrl)
+	alias := models.SegmentAlias{Alias: "OLD", Slug: "NEW", ExpiresAt: time.Now().Add(time.Hour)}
+
+	// the old slug is replaced with the current one and recorded as deprecated
+	storage.EXPECT().ResolveSegmentAliases(gomock.Any(), []string{"OLD", "OTHER"}).Return(map[string]models.SegmentAlias{"OLD": alias}, nil)
+	storage.EXPECT().GetSegmentDefinitions(gomock.Any()).Return(map[string]models.Segment{}, nil)
+	storage.EXPECT().UpdateUserSegments(gomock.Any(), models.UpdateRequest{SegmentsToAdd: []string{"NEW"}, SegmentsToRemove: []string{"OTHER"}}, uuid.MustParse(user1), gomock.Any()).Return(models.MembershipDiff{}, nil)
+
+	ctx, deprecated := models.WithDeprecatedSlugs(context.Background())
+	data := models.UpdateRequest{SegmentsToAdd: []string{"OLD"}, SegmentsToRemove: []string{"OTHER"}}
+	_, err := New(storage).UpdateUserSegments(ctx, data, uuid.MustParse(user1))
+	require.NoError(t, err)
+	assert.DeepEqual(t, []models.SegmentAlias{alias}, deprecated.List())
+}
+
+func TestImportMembershipsByAlias(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storage := mocks.NewMockSegmentStorage(ctrl)
+	alias := models.SegmentAlias{Alias: "OLD", Slug: "NEW", ExpiresAt: time.Now().Add(time.Hour)}
+
+	// the slugs of the lines are resolved once each, and the changes of both slugs are merged
+	storage.EXPECT().GetSegmentDefinitions(gomock.Any()).Return(map[string]models.Segment{}, nil)
+	storage.EXPECT().ResolveSegmentAliases(gomock.Any(), []string{"OLD", "NEW"}).Return(map[string]models.SegmentAlias{"OLD": alias}, nil)
+	storage.EXPECT().FindSegment(gomock.Any(), "NEW").Return(1, nil)
+	storage.EXPECT().ApplyMemberships(gomock.Any(), []models.MembershipChange{
+		{UserID: uuid.MustParse(user1), Segment: "NEW", Action: models.ActRemove},
+		{UserID: uuid.MustParse(user2), Segment: "NEW", Action: models.ActAdd},
+	}, gomock.Any()).Return(2, 0, nil)
+
+	ctx, deprecated := models.WithDeprecatedSlugs(context.Background())
+	file := user1 + ",OLD,add\n" + user2 + ",OLD,add\n" + user1 + ",NEW,remove\n"
+	result, err := New(storage).ImportMemberships(ctx, strings.NewReader(file), models.ImportOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Valid)
+	assert.DeepEqual(t, []models.SegmentAlias{alias}, deprecated.List())
+}
+
+func TestSetSegmentExpressionByAlias(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storage := mocks.NewMockSegmentStorage(ctrl)
+	alias := models.SegmentAlias{Alias: "OLD", Slug: "NEW", ExpiresAt: time.Now().Add(time.Hour)}
+
+	// the expression is saved with the current slugs, so it keeps working after the alias expires
+	storage.EXPECT().ResolveSegmentAliases(gomock.Any(), []string{"COMPOSITE"}).Return(map[string]models.SegmentAlias{}, nil)
+	storage.EXPECT().ResolveSegmentAliases(gomock.Any(), []string{"OLD", "OTHER"}).Return(map[string]models.SegmentAlias{"OLD": alias}, nil)
+	storage.EXPECT().FindSegment(gomock.Any(), gomock.Any()).Return(1, nil).Times(2)
+	storage.EXPECT().GetSegmentDefinitions(gomock.Any()).Return(map[string]models.Segment{}, nil)
+	storage.EXPECT().SetSegmentExpression(gomock.Any(), "COMPOSITE", "NEW except OTHER").Return(models.SyncResult{}, nil)
+
+	_, err := New(storage).SetSegmentExpression(context.Background(), "COMPOSITE", "OLD except OTHER")
+	require.NoError(t, err)
+}
@@ -0,0 +1,178 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"segmentation-service/internal/domain/models"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ArchiveReports moves the report rows older than the retention period to the archive, a month at a time, the oldest
+// first. Every month is logged; a month whose archival failed or was interrupted stays in the database and is retried
+// by the next run. The run stops at the first failure and returns the runs of the months archived before it.
+// Nothing is done while another instance is archiving.
+func (a *SegmentSvc) ArchiveReports(ctx context.Context) (runs []models.ReportArchiveRun, err error) {
+	ctx, span := startSpan(ctx, "SegmentSvc.ArchiveReports")
+	defer func() { endSpan(span, err) }()
+
+	if a.archive == nil || a.retention <= 0 {
+		return nil, models.ErrRetentionDisabled
+	}
+	before := retentionCutoff(time.Now(), a.retention)
+	span.SetAttributes(attribute.String("report.archive_before", before))
+
+	months, err := a.storage.GetArchivableMonths(ctx, before)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	for _, month := range months {
+		run, err := a.archiveMonth(ctx, month)
+		if errors.Is(err, models.ErrArchiveBusy) {
+			return runs, nil
+		}
+		if err != nil {
+			return runs, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// archiveMonth archives the report rows of the month, recording the start and the failure of the run in the log.
+func (a *SegmentSvc) archiveMonth(ctx context.Context, month string) (run models.ReportArchiveRun, err error) {
+	err = a.storage.SaveReportArchiveRun(ctx, models.ReportArchiveRun{Month: month, State: models.ArchiveRunning})
+	if err != nil {
+		return run, fmt.Errorf("database error: %w", err)
+	}
+	w, err := a.archive.Create(month)
+	if err == nil {
+		run, err = a.storage.ArchiveReportMonth(ctx, month, w)
+		w.Abort()
+	}
+	if err == nil {
+		return run, nil
+	}
+
+	// the failure is recorded even if the run has been cancelled
+	failed := models.ReportArchiveRun{Month: month, State: models.ArchiveFailed, Error: err.Error()}
+	if saveErr := a.storage.SaveReportArchiveRun(context.WithoutCancel(ctx), failed); saveErr != nil {
+		return run, fmt.Errorf("archiving the month '%s' failed: %w (recording the failure failed: %v)", month, err, saveErr)
+	}
+	return run, fmt.Errorf("archiving the month '%s' failed: %w", month, err)
+}
+
+// GetReportArchiveRuns returns the log of the archival of the report, the oldest month first.
+func (a *SegmentSvc) GetReportArchiveRuns(ctx context.Context) (runs []models.ReportArchiveRun, err error) {
+	ctx, span := startSpan(ctx, "SegmentSvc.GetReportArchiveRuns")
+	defer func() { endSpan(span, err) }()
+
+	runs, err = a.storage.GetReportArchiveRuns(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return runs, nil
+}
+
+// withArchivedReport prepends the rows of the month selected by match to the records read from the database
+// if the month is archived. Like in the database, the rows of the deleted segments are skipped and the segments
+// are shown with their current names.
+func (a *SegmentSvc) withArchivedReport(ctx context.Context, period string, records [][]string,
+	match func(models.ArchivedReportRow) bool) ([][]string, error) {
+	if a.archive == nil {
+		return records, nil
+	}
+	runs, err := a.archivedRuns(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, run := range runs {
+		if run.Month != period {
+			continue
+		}
+		names, err := a.storage.GetSegmentNames(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+		var archived [][]string
+		err = a.readArchive(ctx, run, names, func(row models.ArchivedReportRow, segment string) {
+			if match(row) {
+				archived = append(archived, row.ReportRow(segment).Record(a.reportLoc))
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+		return append(archived, records...), nil
+	}
+	return records, nil
+}
+
+// archivedReport returns the rows of the archived months as the report rows of a state archive.
+func (a *SegmentSvc) archivedReport(ctx context.Context, archived models.ArchivedMonths) ([]models.ArchiveReportRow, error) {
+	if a.archive == nil {
+		return nil, nil
+	}
+	var report []models.ArchiveReportRow
+	for _, run := range archived.Runs {
+		err := a.readArchive(ctx, run, archived.Names, func(row models.ArchivedReportRow, segment string) {
+			report = append(report, models.ArchiveReportRow{
+				UserID:  row.UserID,
+				Segment: segment,
+				Action:  row.Action,
+				Time:    row.Time,
+				Actor:   row.Actor,
+				Source:  row.Source,
+				Reason:  row.Reason,
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// archivedRuns returns the log entries of the archived months.
+func (a *SegmentSvc) archivedRuns(ctx context.Context) ([]models.ReportArchiveRun, error) {
+	runs, err := a.storage.GetReportArchiveRuns(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	archived := runs[:0]
+	for _, run := range runs {
+		if run.State == models.ArchiveArchived {
+			archived = append(archived, run)
+		}
+	}
+	return archived, nil
+}
+
+// readArchive calls visit with the rows of the archived month whose segments still exist, given by the names
+// of the segments by their IDs, and their current names.
+func (a *SegmentSvc) readArchive(ctx context.Context, run models.ReportArchiveRun, names map[int32]string,
+	visit func(models.ArchivedReportRow, string)) error {
+	return a.archive.Read(ctx, run, func(row models.ArchivedReportRow) error {
+		if segment, ok := names[row.SegmentID]; ok {
+			visit(row, segment)
+		}
+		return nil
+	})
+}
+
+// matchesReportFilter tells whether the archived row is selected by the filter the way the database selects the rows.
+func matchesReportFilter(row models.ArchivedReportRow, filter models.ReportFilter) bool {
+	return (filter.Actor == "" || row.Actor == filter.Actor) &&
+		(filter.Source == "" || row.Source == filter.Source) &&
+		(filter.Reason == "" || strings.Contains(strings.ToLower(row.Reason), strings.ToLower(filter.Reason)))
+}
+
+// retentionCutoff returns the first day (in the format: yyyy-mm-dd) of the oldest month kept in the database: the rows
+// of the months before it are older than the given number of months and are archived.
+func retentionCutoff(now time.Time, months int) string {
+	first := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return first.AddDate(0, -months, 0).Format(time.DateOnly)
+}
@@ -0,0 +1,180 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"segmentation-service/internal/domain/models"
+	"segmentation-service/internal/ports/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+)
+
+func TestRetentionCutoff(t *testing.T) {
+	now := time.Date(2026, 3, 19, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, "2026-02-01", retentionCutoff(now, 1))
+	assert.Equal(t, "2025-03-01", retentionCutoff(now, 12))
+	assert.Equal(t, "2024-12-01", retentionCutoff(now, 15))
+}
+
+func TestArchiveReports(t *testing.T) {
+	archived := models.ReportArchiveRun{Month: "2023-08", State: models.ArchiveArchived, File: "report-2023-08.csv.gz", Rows: 10}
+
+	// prepare test data
+	testCases := []struct {
+		name          string
+		mockBehaviour func(s *mocks.MockSegmentStorage, a *mocks.MockReportArchive, w *mocks.MockReportArchiveWriter)
+		expRuns       []models.ReportArchiveRun
+		expErr        string
+	}{
+		{
+			name: "Oldest month first",
+			mockBehaviour: func(s *mocks.MockSegmentStorage, a *mocks.MockReportArchive, w *mocks.MockReportArchiveWriter) {
+				s.EXPECT().GetArchivableMonths(gomock.Any(), gomock.Any()).Return([]string{"2023-08", "2023-09"}, nil)
+				gomock.InOrder(
+					s.EXPECT().SaveReportArchiveRun(gomock.Any(), models.ReportArchiveRun{Month: "2023-08", State: models.ArchiveRunning}).Return(nil),
+					a.EXPECT().Create("2023-08").Return(w, nil),
+					s.EXPECT().ArchiveReportMonth(gomock.Any(), "2023-08", w).Return(archived, nil),
+					s.EXPECT().SaveReportArchiveRun(gomock.Any(), models.ReportArchiveRun{Month: "2023-09", State: models.ArchiveRunning}).Return(nil),
+					a.EXPECT().Create("2023-09").Return(w, nil),
+					s.EXPECT().ArchiveReportMonth(gomock.Any(), "2023-09", w).Return(models.ReportArchiveRun{Month: "2023-09", State: models.ArchiveArchived}, nil),
+				)
+				w.EXPECT().Abort().Return(nil).Times(2)
+			},
+			expRuns: []models.ReportArchiveRun{archived, {Month: "2023-09", State: models.ArchiveArchived}},
+		},
+		{
+			name: "Failure is logged and stops the run",
+			mockBehaviour: func(s *mocks.MockSegmentStorage, a *mocks.MockReportArchive, w *mocks.MockReportArchiveWriter) {
+				s.EXPECT().GetArchivableMonths(gomock.Any(), gomock.Any()).Return([]string{"2023-08", "2023-09"}, nil)
+				gomock.InOrder(
+					s.EXPECT().SaveReportArchiveRun(gomock.Any(), models.ReportArchiveRun{Month: "2023-08", State: models.ArchiveRunning}).Return(nil),
+					a.EXPECT().Create("2023-08").Return(w, nil),
+					s.EXPECT().ArchiveReportMonth(gomock.Any(), "2023-08", w).Return(models.ReportArchiveRun{}, errors.New("disk full")),
+					w.EXPECT().Abort().Return(nil),
+					s.EXPECT().SaveReportArchiveRun(gomock.Any(), models.ReportArchiveRun{Month: "2023-08", State: models.ArchiveFailed, Error: "disk full"}).Return(nil),
+				)
+			},
+			expErr: "archiving the month '2023-08' failed: disk full",
+		},
+		{
+			name: "File not created",
+			mockBehaviour: func(s *mocks.MockSegmentStorage, a *mocks.MockReportArchive, w *mocks.MockReportArchiveWriter) {
+				s.EXPECT().GetArchivableMonths(gomock.Any(), gomock.Any()).Return([]string{"2023-08"}, nil)
+				s.EXPECT().SaveReportArchiveRun(gomock.Any(), models.ReportArchiveRun{Month: "2023-08", State: models.ArchiveRunning}).Return(nil)
+				a.EXPECT().Create("2023-08").Return(nil, errors.New("permission denied"))
+				s.EXPECT().SaveReportArchiveRun(gomock.Any(), models.ReportArchiveRun{Month: "2023-08", State: models.ArchiveFailed, Error: "permission denied"}).Return(nil)
+			},
+			expErr: "archiving the month '2023-08' failed: permission denied",
+		},
+		{
+			name: "Another instance is archiving",
+			mockBehaviour: func(s *mocks.MockSegmentStorage, a *mocks.MockReportArchive, w *mocks.MockReportArchiveWriter) {
+				s.EXPECT().GetArchivableMonths(gomock.Any(), gomock.Any()).Return([]string{"2023-08", "2023-09"}, nil)
+				s.EXPECT().SaveReportArchiveRun(gomock.Any(), models.ReportArchiveRun{Month: "2023-08", State: models.ArchiveRunning}).Return(nil)
+				a.EXPECT().Create("2023-08").Return(w, nil)
+				s.EXPECT().ArchiveReportMonth(gomock.Any(), "2023-08", w).Return(models.ReportArchiveRun{}, models.ErrArchiveBusy)
+				w.EXPECT().Abort().Return(nil)
+				s.EXPECT().SaveReportArchiveRun(gomock.Any(), models.ReportArchiveRun{Month: "2023-08", State: models.ArchiveFailed, Error: models.ErrArchiveBusy.Error()}).Return(nil)
+			},
+		},
+		{
+			name: "Database error",
+			mockBehaviour: func(s *mocks.MockSegmentStorage, a *mocks.MockReportArchive, w *mocks.MockReportArchiveWriter) {
+				s.EXPECT().GetArchivableMonths(gomock.Any(), gomock.Any()).Return(nil, errors.New("some error"))
+			},
+			expErr: "database error: some error",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			storage := mocks.NewMockSegmentStorage(ctrl)
+			archive := mocks.NewMockReportArchive(ctrl)
+			writer := mocks.NewMockReportArchiveWriter(ctrl)
+			tc.mockBehaviour(storage, archive, writer)
+
+			runs, err := New(storage, WithReportArchive(archive, 12)).ArchiveReports(context.Background())
+			if tc.expErr != "" {
+				require.EqualError(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.DeepEqual(t, tc.expRuns, runs)
+		})
+	}
+}
+
+func TestArchiveReportsDisabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storage := mocks.NewMockSegmentStorage(ctrl)
+
+	_, err := New(storage).ArchiveReports(context.Background())
+	require.ErrorIs(t, err, models.ErrRetentionDisabled)
+	_, err = New(storage, WithReportArchive(mocks.NewMockReportArchive(ctrl), 0)).ArchiveReports(context.Background())
+	require.ErrorIs(t, err, models.ErrRetentionDisabled)
+}
+
+func TestGetReportArchived(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storage := mocks.NewMockSegmentStorage(ctrl)
+	archive := mocks.NewMockReportArchive(ctrl)
+	svc := New(storage, WithReportArchive(archive, 12), WithReportLocation(time.FixedZone("UTC+3", 3*60*60)))
+
+	user, other := uuid.New(), uuid.New()
+	created := time.Date(2023, 8, 1, 10, 0, 0, 0, time.UTC)
+	run := models.ReportArchiveRun{Month: "2023-08", State: models.ArchiveArchived, File: "report-2023-08.csv.gz", Rows: 4}
+	rows := []models.ArchivedReportRow{
+		{SegmentID: 1, Segment: "OLD_NAME", UserID: user, Action: models.ActAdd, Time: created, Actor: "ci", Source: models.SourceAPI, Reason: "Launch"},
+		{SegmentID: 1, Segment: "OLD_NAME", UserID: other, Action: models.ActAdd, Time: created, Source: models.SourceImport},
+		{SegmentID: 2, Segment: "DELETED", UserID: user, Action: models.ActAdd, Time: created, Source: models.SourceAPI},
+		{SegmentID: 1, Segment: "OLD_NAME", UserID: user, Action: models.ActRemove, Time: created, Source: models.SourceTTLReaper},
+	}
+	readRows := func(ctx context.Context, run models.ReportArchiveRun, visit func(models.ArchivedReportRow) error) error {
+		for _, row := range rows {
+			if err := visit(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	storage.EXPECT().GetReportArchiveRuns(gomock.Any()).Return([]models.ReportArchiveRun{
+		{Month: "2023-07", State: models.ArchiveFailed}, run,
+	}, nil).AnyTimes()
+	storage.EXPECT().GetSegmentNames(gomock.Any()).Return(map[int32]string{1: "BETA"}, nil).AnyTimes()
+	archive.EXPECT().Read(gomock.Any(), run, gomock.Any()).DoAndReturn(readRows).AnyTimes()
+
+	// the archived rows come before the rows left in the database, with the current names of the segments
+	dbRecord := []string{user.String(), "BETA", models.ActAdd, "2023-08-31 10:00:00", "", models.SourceAPI, ""}
+	storage.EXPECT().GetReport(gomock.Any(), "2023-08-01", models.ReportFilter{}).Return([][]string{dbRecord}, nil)
+	records, err := svc.GetReport(context.Background(), "2023-08", models.ReportFilter{})
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+	assert.DeepEqual(t, []string{user.String(), "BETA", models.ActAdd, "2023-08-01 13:00:00",
+		"ci", models.SourceAPI, "Launch"}, records[0])
+	assert.DeepEqual(t, dbRecord, records[3])
+
+	// the archived rows are filtered like the rows of the database
+	storage.EXPECT().GetReport(gomock.Any(), "2023-08-01", models.ReportFilter{Reason: "launch"}).Return(nil, nil)
+	records, err = svc.GetReport(context.Background(), "2023-08", models.ReportFilter{Reason: "launch"})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+
+	storage.EXPECT().GetUserReport(gomock.Any(), "2023-08-01", user, models.ReportFilter{}).Return(nil, nil)
+	records, err = svc.GetUserReport(context.Background(), "2023-08", user, models.ReportFilter{})
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, models.ActRemove, records[1][2])
+
+	// a month that isn't archived is read from the database only
+	storage.EXPECT().GetReport(gomock.Any(), "2023-07-01", models.ReportFilter{}).Return([][]string{dbRecord}, nil)
+	records, err = svc.GetReport(context.Background(), "2023-07", models.ReportFilter{})
+	require.NoError(t, err)
+	assert.DeepEqual(t, [][]string{dbRecord}, records)
+}
@@ -0,0 +1,43 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"segmentation-service/internal/domain/models"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// SetSegmentRollout validates and saves the percentage of the rollout segment. The users are computed on read from
+// the hash of the salt and the user ID, so the change takes effect at once without writing anything per user. A nil
+// percentage turns the segment into an ordinary one, keeping its explicitly added members.
+func (a *SegmentSvc) SetSegmentRollout(ctx context.Context, slug string, rollout *float64, salt string) (result models.Segment, err error) {
+	ctx, span := startSpan(ctx, "SegmentSvc.SetSegmentRollout", attribute.String("segment.slug", slug))
+	defer func() { endSpan(span, err) }()
+
+	if rollout != nil {
+		if err = validateRollout(*rollout); err != nil {
+			return result, err
+		}
+	}
+	if slug, err = a.resolveSlug(ctx, slug); err != nil {
+		return result, err
+	}
+
+	result, err = a.storage.SetSegmentRollout(ctx, slug, rollout, salt)
+	if err != nil && !errors.Is(err, models.ErrSegmentNotFound) && !errors.Is(err, models.ErrInvalidRollout) &&
+		!errors.Is(err, models.ErrSegmentInUse) && !errors.Is(err, models.ErrSegmentInExperiment) {
+		return result, fmt.Errorf("database error: %w", err)
+	}
+	return result, err
+}
+
+// validateRollout checks that the percentage is between 0 and 100.
+func validateRollout(rollout float64) error {
+	if math.IsNaN(rollout) || rollout < 0 || rollout > 100 {
+		return fmt.Errorf("%w: percentage must be between 0 and 100", models.ErrInvalidRollout)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"math"
+	"segmentation-service/internal/domain/models"
+	"segmentation-service/internal/ports/mocks"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+)
+
+func TestSetSegmentRollout(t *testing.T) {
+	percent := func(p float64) *float64 { return &p }
+
+	// prepare test data
+	testCases := []struct {
+		name          string
+		rollout       *float64
+		salt          string
+		mockBehaviour func(m *mocks.MockSegmentStorage)
+		expSegment    models.Segment
+		expErr        error
+	}{
+		{
+			name:    "Set percentage",
+			rollout: percent(25),
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().SetSegmentRollout(gomock.Any(), "ROLLOUT", percent(25), "").
+					Return(models.Segment{Slug: "ROLLOUT", Rollout: percent(25), Salt: "ROLLOUT"}, nil)
+			},
+			expSegment: models.Segment{Slug: "ROLLOUT", Rollout: percent(25), Salt: "ROLLOUT"},
+		},
+		{
+			name: "Turn off",
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().SetSegmentRollout(gomock.Any(), "ROLLOUT", nil, "").Return(models.Segment{Slug: "ROLLOUT"}, nil)
+			},
+			expSegment: models.Segment{Slug: "ROLLOUT"},
+		},
+		{
+			name:          "Percentage above 100",
+			rollout:       percent(100.5),
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {},
+			expErr:        models.ErrInvalidRollout,
+		},
+		{
+			name:          "Not a number",
+			rollout:       percent(math.NaN()),
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {},
+			expErr:        models.ErrInvalidRollout,
+		},
+		{
+			name:    "Changed salt",
+			rollout: percent(50),
+			salt:    "OTHER",
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().SetSegmentRollout(gomock.Any(), "ROLLOUT", percent(50), "OTHER").
+					Return(models.Segment{}, models.ErrInvalidRollout)
+			},
+			expErr: models.ErrInvalidRollout,
+		},
+		{
+			name:    "Used by composite",
+			rollout: percent(50),
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().SetSegmentRollout(gomock.Any(), "ROLLOUT", percent(50), "").Return(models.Segment{}, models.ErrSegmentInUse)
+			},
+			expErr: models.ErrSegmentInUse,
+		},
+		{
+			name:    "Database error",
+			rollout: percent(50),
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().SetSegmentRollout(gomock.Any(), "ROLLOUT", percent(50), "").Return(models.Segment{}, errors.New("some error"))
+			},
+			expErr: errors.New("database error: some error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			storage := mocks.NewMockSegmentStorage(ctrl)
+			withoutAliases(storage)
+			tc.mockBehaviour(storage)
+
+			segment, err := New(storage).SetSegmentRollout(context.Background(), "ROLLOUT", tc.rollout, tc.salt)
+			if tc.expErr != nil {
+				require.ErrorContains(t, err, tc.expErr.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.DeepEqual(t, tc.expSegment, segment)
+		})
+	}
+}
+
+func TestGetUserSegmentsRollout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storage := mocks.NewMockSegmentStorage(ctrl)
+	withoutAliases(storage)
+
+	// the checker passed to the storage puts everybody into a full rollout and nobody into an empty one
+	storage.EXPECT().GetUserSegments(gomock.Any(), uuid.MustParse(user1), gomock.Any()).
+		DoAndReturn(func(_ context.Context, userID uuid.UUID, inRollout models.RolloutChecker) (models.SegmentsList, error) {
+			list := models.SegmentsList{}
+			for slug, percent := range map[string]float64{"FULL": 100, "EMPTY": 0} {
+				if inRollout(slug, userID, percent) {
+					list.S = append(list.S, slug)
+				}
+			}
+			return list, nil
+		})
+
+	list, err := New(storage).GetUserSegments(context.Background(), uuid.MustParse(user1))
+	require.NoError(t, err)
+	assert.DeepEqual(t, []string{"FULL"}, list.S)
+}
+
+func TestUpdateUserSegmentsRollout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storage := mocks.NewMockSegmentStorage(ctrl)
+	withoutAliases(storage)
+	rollout := 10.0
+	data := models.UpdateRequest{SegmentsToAdd: []string{"ROLLOUT"}}
+
+	// the users of a rollout can be included explicitly
+	storage.EXPECT().GetSegmentDefinitions(gomock.Any()).Return(map[string]models.Segment{"ROLLOUT": {Slug: "ROLLOUT", Rollout: &rollout}}, nil)
+	storage.EXPECT().UpdateUserSegments(gomock.Any(), data, uuid.MustParse(user1), gomock.Any()).Return(models.MembershipDiff{}, nil)
+
+	_, err := New(storage).UpdateUserSegments(context.Background(), data, uuid.MustParse(user1))
+	require.NoError(t, err)
+}
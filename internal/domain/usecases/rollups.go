@@ -0,0 +1,73 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"segmentation-service/internal/domain/models"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// FoldReportRollups compacts the daily rollups of the report and returns the number of the folded rows. It is run
+// periodically by the scheduler.
+func (a *SegmentSvc) FoldReportRollups(ctx context.Context) (count int, err error) {
+	ctx, span := startSpan(ctx, "SegmentSvc.FoldReportRollups")
+	defer func() { endSpan(span, err) }()
+
+	count, err = a.storage.FoldReportRollups(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("database error: %w", err)
+	}
+	return count, nil
+}
+
+// BackfillRollups rebuilds the daily rollups of the report from its rows for the days from the first to the last
+// one, nil meaning the beginning or the end of the history, e.g. for the history written before the rollups existed.
+func (a *SegmentSvc) BackfillRollups(ctx context.Context, from, to *time.Time) (result models.RollupResult, err error) {
+	ctx, span := startSpan(ctx, "SegmentSvc.BackfillRollups")
+	defer func() { endSpan(span, err) }()
+
+	if err = validateRollupRange(from, to); err != nil {
+		return result, err
+	}
+	result, err = a.storage.BackfillRollups(ctx, from, to)
+	if err != nil {
+		return result, fmt.Errorf("database error: %w", err)
+	}
+	return result, nil
+}
+
+// CheckRollups returns the segments, days and actions whose rollups don't match the report rows for the days from
+// the first to the last one, nil meaning the beginning or the end of the history. No differences means the rollups
+// are consistent.
+func (a *SegmentSvc) CheckRollups(ctx context.Context, from, to *time.Time) (mismatches []models.RollupMismatch, err error) {
+	ctx, span := startSpan(ctx, "SegmentSvc.CheckRollups")
+	defer func() { endSpan(span, err) }()
+
+	if err = validateRollupRange(from, to); err != nil {
+		return nil, err
+	}
+	mismatches, err = a.storage.CheckRollups(ctx, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return mismatches, nil
+}
+
+// GetAggregateReport returns the daily numbers of the members added to and removed from every segment for the month.
+func (a *SegmentSvc) GetAggregateReport(ctx context.Context, period string) (_ [][]string, err error) {
+	ctx, span := startSpan(ctx, "SegmentSvc.GetAggregateReport", attribute.String("report.period", period))
+	defer func() { endSpan(span, err) }()
+
+	monthBeginning := period + "-01"
+	return a.storage.GetAggregateReport(ctx, monthBeginning)
+}
+
+// validateRollupRange checks that the range of days doesn't end before it starts.
+func validateRollupRange(from, to *time.Time) error {
+	if from != nil && to != nil && to.Before(*from) {
+		return fmt.Errorf("%w: 'from' is after 'to'", models.ErrInvalidDateRange)
+	}
+	return nil
+}
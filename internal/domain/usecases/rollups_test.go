@@ -0,0 +1,108 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"segmentation-service/internal/domain/models"
+	"segmentation-service/internal/ports/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+)
+
+func TestBackfillRollups(t *testing.T) {
+	from, to := time.Date(2023, 8, 1, 0, 0, 0, 0, time.UTC), time.Date(2023, 8, 31, 0, 0, 0, 0, time.UTC)
+
+	// prepare test data
+	testCases := []struct {
+		name          string
+		from, to      *time.Time
+		mockBehaviour func(m *mocks.MockSegmentStorage)
+		expResult     models.RollupResult
+		expErr        error
+	}{
+		{
+			name: "Range",
+			from: &from,
+			to:   &to,
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().BackfillRollups(gomock.Any(), &from, &to).Return(models.RollupResult{Rollups: 40, Rows: 1200}, nil)
+			},
+			expResult: models.RollupResult{Rollups: 40, Rows: 1200},
+		},
+		{
+			name: "Whole history",
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().BackfillRollups(gomock.Any(), nil, nil).Return(models.RollupResult{Rollups: 400, Rows: 12000}, nil)
+			},
+			expResult: models.RollupResult{Rollups: 400, Rows: 12000},
+		},
+		{
+			name:          "Start after end",
+			from:          &to,
+			to:            &from,
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {},
+			expErr:        models.ErrInvalidDateRange,
+		},
+		{
+			name: "Database error",
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().BackfillRollups(gomock.Any(), nil, nil).Return(models.RollupResult{}, errors.New("some error"))
+			},
+			expErr: errors.New("database error: some error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			storage := mocks.NewMockSegmentStorage(ctrl)
+			tc.mockBehaviour(storage)
+
+			result, err := New(storage).BackfillRollups(context.Background(), tc.from, tc.to)
+			if tc.expErr != nil {
+				require.ErrorContains(t, err, tc.expErr.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.DeepEqual(t, tc.expResult, result)
+		})
+	}
+}
+
+func TestCheckRollups(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storage := mocks.NewMockSegmentStorage(ctrl)
+	mismatches := []models.RollupMismatch{
+		{SegmentID: 1, Segment: "BETA", Date: "2023-08-01", Action: models.ActAdd, Rollup: 10, Report: 12},
+	}
+
+	storage.EXPECT().CheckRollups(gomock.Any(), nil, nil).Return(mismatches, nil)
+	result, err := New(storage).CheckRollups(context.Background(), nil, nil)
+	require.NoError(t, err)
+	assert.DeepEqual(t, mismatches, result)
+
+	from, to := time.Date(2023, 8, 2, 0, 0, 0, 0, time.UTC), time.Date(2023, 8, 1, 0, 0, 0, 0, time.UTC)
+	_, err = New(storage).CheckRollups(context.Background(), &from, &to)
+	require.ErrorIs(t, err, models.ErrInvalidDateRange)
+
+	storage.EXPECT().CheckRollups(gomock.Any(), nil, nil).Return(nil, errors.New("some error"))
+	_, err = New(storage).CheckRollups(context.Background(), nil, nil)
+	require.EqualError(t, err, "database error: some error")
+}
+
+func TestGetAggregateReport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storage := mocks.NewMockSegmentStorage(ctrl)
+	records := [][]string{{"BETA", "2023-08-01", "12", "2"}}
+
+	// the storage gets the first day of the month
+	storage.EXPECT().GetAggregateReport(gomock.Any(), "2023-08-01").Return(records, nil)
+	result, err := New(storage).GetAggregateReport(context.Background(), "2023-08")
+	require.NoError(t, err)
+	assert.DeepEqual(t, records, result)
+}
@@ -0,0 +1,126 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"segmentation-service/internal/domain/models"
+	"segmentation-service/internal/domain/rules"
+	"segmentation-service/pkg/infra/logger"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// SetSegmentRule validates and saves the rule of the segment, then brings its members in line with the rule.
+// An empty rule turns the segment into an ordinary one, keeping its members.
+func (a *SegmentSvc) SetSegmentRule(ctx context.Context, slug, rule string) (result models.SyncResult, err error) {
+	ctx, span := startSpan(ctx, "SegmentSvc.SetSegmentRule", attribute.String("segment.slug", slug))
+	defer func() { endSpan(span, err) }()
+
+	rule = strings.TrimSpace(rule)
+	if rule != "" {
+		if _, err = rules.Parse(rule); err != nil {
+			return result, fmt.Errorf("%w: %v", models.ErrInvalidRule, err)
+		}
+	}
+	if slug, err = a.resolveSlug(ctx, slug); err != nil {
+		return result, err
+	}
+
+	result, err = a.storage.SetSegmentRule(ctx, slug, rule, a.evaluate)
+	if err != nil && !errors.Is(err, models.ErrSegmentNotFound) && !errors.Is(err, models.ErrInvalidRule) &&
+		!errors.Is(err, models.ErrSegmentInExperiment) {
+		return result, fmt.Errorf("database error: %w", err)
+	}
+	return result, err
+}
+
+// UpdateUserAttributes merges the attributes into the stored ones and re-evaluates the rules of the dynamic segments
+// for the user.
+func (a *SegmentSvc) UpdateUserAttributes(ctx context.Context, userID uuid.UUID, attrs models.Attributes) (result models.AttributesResult, err error) {
+	ctx, span := startSpan(ctx, "SegmentSvc.UpdateUserAttributes",
+		attribute.String("user.id", userID.String()),
+		attribute.Int("attributes.count", len(attrs)),
+	)
+	defer func() { endSpan(span, err) }()
+
+	if attrs == nil {
+		attrs = models.Attributes{}
+	}
+	if err = validateAttributes(attrs); err != nil {
+		return result, err
+	}
+
+	result, err = a.storage.UpdateUserAttributes(ctx, userID, attrs, a.evaluate)
+	if err != nil {
+		return result, fmt.Errorf("database error: %w", err)
+	}
+	return result, nil
+}
+
+func (a *SegmentSvc) GetUserAttributes(ctx context.Context, userID uuid.UUID) (_ models.Attributes, err error) {
+	ctx, span := startSpan(ctx, "SegmentSvc.GetUserAttributes", attribute.String("user.id", userID.String()))
+	defer func() { endSpan(span, err) }()
+
+	attrs, err := a.storage.GetUserAttributes(ctx, userID)
+	if err != nil {
+		return attrs, fmt.Errorf("database error: %w", err)
+	}
+	return attrs, nil
+}
+
+// validateAttributes checks that the names can be used in rules and that the values are scalars.
+func validateAttributes(attrs models.Attributes) error {
+	for name, value := range attrs {
+		if !models.AttributeRegexp.MatchString(name) {
+			return fmt.Errorf("%w: invalid name '%s'", models.ErrInvalidAttributes, name)
+		}
+		switch value.(type) {
+		case string, float64, bool, nil:
+		default:
+			return fmt.Errorf("%w: value of '%s' must be a string, number, boolean or null", models.ErrInvalidAttributes, name)
+		}
+	}
+	return nil
+}
+
+// evaluate is the rule evaluator passed to the storage. Parsed rules are cached by their source. A stored rule
+// that can't be parsed matches nobody.
+func (a *SegmentSvc) evaluate(rule string, attrs models.Attributes) bool {
+	cached, ok := a.rules.Load(rule)
+	if !ok {
+		parsed, err := rules.Parse(rule)
+		if err != nil {
+			logger.Get().Warn("stored rule can't be parsed", "rule", rule, "desc", err.Error())
+		}
+		cached, _ = a.rules.LoadOrStore(rule, parsed)
+	}
+	parsed := cached.(*rules.Rule)
+	return parsed != nil && parsed.Match(attrs)
+}
+
+// checkStaticSegments returns an error if one of the segments is dynamic or composite, since the members of such
+// segments are only changed by their rules or expressions. The users of percentage rollouts can be added and removed
+// explicitly.
+func (a *SegmentSvc) checkStaticSegments(ctx context.Context, slugs ...string) error {
+	if len(slugs) == 0 {
+		return nil
+	}
+	dynamic, err := a.storage.GetSegmentDefinitions(ctx)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	for _, slug := range slugs {
+		if isComputed(dynamic[slug]) {
+			return fmt.Errorf("%w: '%s'", models.ErrDynamicSegment, slug)
+		}
+	}
+	return nil
+}
+
+// isComputed reports whether the members of the segment are only changed by its rule or expression.
+func isComputed(segment models.Segment) bool {
+	return segment.Rule != "" || segment.Expression != ""
+}
@@ -0,0 +1,162 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"segmentation-service/internal/domain/models"
+	"segmentation-service/internal/ports/mocks"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+)
+
+func TestSetSegmentRule(t *testing.T) {
+	// prepare test data
+	testCases := []struct {
+		name          string
+		rule          string
+		mockBehaviour func(m *mocks.MockSegmentStorage)
+		expResult     models.SyncResult
+		expErr        error
+	}{
+		{
+			name: "OK",
+			rule: ` city == "Moscow" `,
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().SetSegmentRule(gomock.Any(), "TEST", `city == "Moscow"`, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _, rule string, evaluate models.RuleEvaluator) (models.SyncResult, error) {
+						require.True(t, evaluate(rule, models.Attributes{"city": "Moscow"}))
+						require.False(t, evaluate(rule, models.Attributes{"city": "Kazan"}))
+						require.False(t, evaluate(rule, models.Attributes{}))
+						return models.SyncResult{Added: 2, Removed: 1}, nil
+					})
+			},
+			expResult: models.SyncResult{Added: 2, Removed: 1},
+		},
+		{
+			name: "Clear rule",
+			rule: "",
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().SetSegmentRule(gomock.Any(), "TEST", "", gomock.Any()).Return(models.SyncResult{}, nil)
+			},
+		},
+		{
+			name:          "Invalid rule",
+			rule:          `city == `,
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {},
+			expErr:        models.ErrInvalidRule,
+		},
+		{
+			name: "Segment not found",
+			rule: `age > 18`,
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().SetSegmentRule(gomock.Any(), "TEST", `age > 18`, gomock.Any()).Return(models.SyncResult{}, models.ErrSegmentNotFound)
+			},
+			expErr: models.ErrSegmentNotFound,
+		},
+		{
+			name: "Database error",
+			rule: `age > 18`,
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().SetSegmentRule(gomock.Any(), "TEST", `age > 18`, gomock.Any()).Return(models.SyncResult{}, errors.New("some error"))
+			},
+			expErr: errors.New("some error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			storage := mocks.NewMockSegmentStorage(ctrl)
+			withoutAliases(storage)
+			tc.mockBehaviour(storage)
+
+			result, err := New(storage).SetSegmentRule(context.Background(), "TEST", tc.rule)
+			if tc.expErr != nil {
+				require.ErrorContains(t, err, tc.expErr.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.DeepEqual(t, tc.expResult, result)
+		})
+	}
+}
+
+func TestUpdateUserAttributes(t *testing.T) {
+	userID := uuid.MustParse(user1)
+
+	// prepare test data
+	testCases := []struct {
+		name          string
+		attrs         models.Attributes
+		mockBehaviour func(m *mocks.MockSegmentStorage)
+		expErr        error
+	}{
+		{
+			name:  "OK",
+			attrs: models.Attributes{"city": "Moscow", "age": float64(30), "premium": true, "country": nil},
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().UpdateUserAttributes(gomock.Any(), userID, gomock.Any(), gomock.Any()).
+					Return(models.AttributesResult{SegmentsAdded: []string{"MOSCOW"}}, nil)
+			},
+		},
+		{
+			name:          "Invalid name",
+			attrs:         models.Attributes{"home city": "Moscow"},
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {},
+			expErr:        models.ErrInvalidAttributes,
+		},
+		{
+			name:          "Invalid value",
+			attrs:         models.Attributes{"cities": []any{"Moscow"}},
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {},
+			expErr:        models.ErrInvalidAttributes,
+		},
+		{
+			name:  "Database error",
+			attrs: models.Attributes{"age": float64(30)},
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().UpdateUserAttributes(gomock.Any(), userID, gomock.Any(), gomock.Any()).Return(models.AttributesResult{}, errors.New("some error"))
+			},
+			expErr: errors.New("database error: some error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			storage := mocks.NewMockSegmentStorage(ctrl)
+			withoutAliases(storage)
+			tc.mockBehaviour(storage)
+
+			_, err := New(storage).UpdateUserAttributes(context.Background(), userID, tc.attrs)
+			if tc.expErr != nil {
+				require.ErrorContains(t, err, tc.expErr.Error())
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestEvaluateInvalidStoredRule(t *testing.T) {
+	svc := New(nil)
+	require.False(t, svc.evaluate(`city ==`, models.Attributes{"city": "Moscow"}))
+	// the failed parse is cached as well
+	require.False(t, svc.evaluate(`city ==`, models.Attributes{"city": "Moscow"}))
+}
+
+func TestUpdateUserSegmentsDynamic(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storage := mocks.NewMockSegmentStorage(ctrl)
+	withoutAliases(storage)
+	storage.EXPECT().GetSegmentDefinitions(gomock.Any()).Return(map[string]models.Segment{"DYNAMIC": {Slug: "DYNAMIC", Rule: `age > 18`}}, nil)
+
+	_, err := New(storage).UpdateUserSegments(context.Background(), models.UpdateRequest{SegmentsToAdd: []string{"TEST", "DYNAMIC"}}, uuid.MustParse(user1))
+	require.ErrorIs(t, err, models.ErrDynamicSegment)
+}
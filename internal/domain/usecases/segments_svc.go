@@ -1,31 +1,114 @@
-// The usecases package implements the application's business logic. Since the functions are simple
+// The usecases package implements the application's business logic. Since most functions are simple
 // and there is almost no preliminary preparation before working with data, we immediately call the storage methods.
-// It seems that testing can be neglected.
+// Only the functions with their own logic, like the import of memberships, are covered by tests.
 package usecases
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"segmentation-service/internal/domain/bucket"
 	"segmentation-service/internal/domain/models"
+	"segmentation-service/internal/domain/rules"
 	"segmentation-service/internal/ports"
+	"segmentation-service/pkg/infra/tracing"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type SegmentSvc struct {
-	storage ports.SegmentStorage
+	storage   ports.SegmentStorage
+	archive   ports.ReportArchive // nil if the report isn't archived
+	retention int                 // number of months the report rows are kept in the database, 0 keeps them forever
+	reportLoc *time.Location      // time zone of the times of the archived report rows, UTC if nil
+	rules     sync.Map            // rule source -> *rules.Rule, nil if the rule can't be parsed
 }
 
 var _ ports.SegmentService = (*SegmentSvc)(nil)
 
+// Option configures the optional parts of SegmentSvc.
+type Option func(*SegmentSvc)
+
+// WithReportArchive makes the service read the archived months of the report from the archive and, if months
+// is positive, move the report rows older than that number of months to it.
+func WithReportArchive(archive ports.ReportArchive, months int) Option {
+	return func(a *SegmentSvc) {
+		a.archive = archive
+		a.retention = months
+	}
+}
+
+// WithReportLocation makes the service write the times of the archived report rows in the location, like the
+// storage writes the times of the rows left in the database.
+func WithReportLocation(loc *time.Location) Option {
+	return func(a *SegmentSvc) {
+		a.reportLoc = loc
+	}
+}
+
 // New returns a new instance of SegmentSvc.
-func New(storage ports.SegmentStorage) *SegmentSvc {
-	return &SegmentSvc{
+func New(storage ports.SegmentStorage, opts ...Option) *SegmentSvc {
+	a := &SegmentSvc{
 		storage: storage,
 	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
 }
 
-func (a *SegmentSvc) CreateSegment(slug string) error {
-	count, err := a.storage.FindSegment(slug)
+// CreateSegment creates the segment. If it has a rule, the users whose attributes match the rule are added to it,
+// if it has an expression, the users of the set the expression describes. If it has a rollout percentage, it becomes
+// a percentage rollout.
+func (a *SegmentSvc) CreateSegment(ctx context.Context, segment models.Segment) (err error) {
+	slug := segment.Slug
+	ctx, span := startSpan(ctx, "SegmentSvc.CreateSegment", attribute.String("segment.slug", slug))
+	defer func() { endSpan(span, err) }()
+
+	if segment.Rule != "" && segment.Expression != "" {
+		return fmt.Errorf("%w: a segment can't have both a rule and an expression", models.ErrInvalidExpression)
+	}
+	if segment.Rollout != nil && (segment.Rule != "" || segment.Expression != "") {
+		return fmt.Errorf("%w: a segment can't have both a rollout and a rule or an expression", models.ErrInvalidRollout)
+	}
+	if segment.Rollout != nil {
+		if err = validateRollout(*segment.Rollout); err != nil {
+			return err
+		}
+	}
+	if err = validateWindow(segment.ActiveFrom, segment.ActiveUntil); err != nil {
+		return err
+	}
+	if segment.MaxMembers != nil {
+		if err = validateCap(*segment.MaxMembers); err != nil {
+			return err
+		}
+		if segment.Rollout != nil {
+			return fmt.Errorf("%w: a percentage rollout can't have a cap", models.ErrInvalidCap)
+		}
+	}
+	if segment.Rule != "" {
+		if _, err = rules.Parse(segment.Rule); err != nil {
+			return fmt.Errorf("%w: %v", models.ErrInvalidRule, err)
+		}
+	}
+	if segment.Expression != "" {
+		if segment.Expression, err = a.resolveExpression(ctx, segment.Expression); err != nil {
+			return err
+		}
+		if err = a.checkExpression(ctx, slug, segment.Expression); err != nil {
+			return err
+		}
+	}
+
+	count, err := a.storage.FindSegment(ctx, slug)
 	if err != nil {
 		return fmt.Errorf("database error: %w", err)
 	}
@@ -33,50 +116,145 @@ func (a *SegmentSvc) CreateSegment(slug string) error {
 		return models.ErrSegmentAlreadyExists
 	}
 
-	err = a.storage.SaveSegment(slug)
+	// the segment is created and configured in one transaction, so a rejected setting leaves nothing behind
+	segment.Rule, segment.Expression = strings.TrimSpace(segment.Rule), strings.TrimSpace(segment.Expression)
+	err = a.storage.SaveSegment(ctx, segment, a.evaluate)
+	if errors.Is(err, models.ErrSlugReserved) || (segment.Expression != "" && isExpressionError(err)) {
+		return err
+	}
 	if err != nil {
 		return fmt.Errorf("database error: %w", err)
 	}
 	return nil
 }
 
-func (a *SegmentSvc) DeleteSegment(slug string) error {
-	count, err := a.storage.FindSegment(slug)
+func (a *SegmentSvc) DeleteSegment(ctx context.Context, slug string) (err error) {
+	ctx, span := startSpan(ctx, "SegmentSvc.DeleteSegment", attribute.String("segment.slug", slug))
+	defer func() { endSpan(span, err) }()
+
+	if slug, err = a.resolveSlug(ctx, slug); err != nil {
+		return err
+	}
+	count, err := a.storage.FindSegment(ctx, slug)
 	if err != nil {
 		return err
 	}
 	if count == 0 {
 		return models.ErrSegmentNotFound
 	}
-	err = a.storage.DeleteSegment(slug)
+	err = a.storage.DeleteSegment(ctx, slug)
+	if errors.Is(err, models.ErrSegmentInUse) {
+		return err
+	}
 	if err != nil {
 		return fmt.Errorf("database error: %w", err)
 	}
 	return nil
 }
 
-func (a *SegmentSvc) UpdateUserSegments(data models.UpdateRequest, userID uuid.UUID) error {
-	return a.storage.UpdateUserSegments(data, userID)
+// UpdateUserSegments adds and removes segments from a user and returns the diff of the user's memberships. A dry run
+// goes through the same checks, but reports the unknown segments in the diff instead of failing and writes nothing.
+func (a *SegmentSvc) UpdateUserSegments(ctx context.Context, data models.UpdateRequest, userID uuid.UUID) (_ models.MembershipDiff, err error) {
+	ctx, span := startSpan(ctx, "SegmentSvc.UpdateUserSegments",
+		attribute.String("user.id", userID.String()),
+		attribute.Int("segments.add", len(data.SegmentsToAdd)),
+		attribute.Int("segments.remove", len(data.SegmentsToRemove)),
+		attribute.Bool("dry_run", data.DryRun),
+	)
+	defer func() { endSpan(span, err) }()
+
+	// both lists are resolved in one query
+	slugs, err := a.resolveSlugs(ctx, slices.Concat(data.SegmentsToAdd, data.SegmentsToRemove))
+	if err != nil {
+		return models.MembershipDiff{}, err
+	}
+	if n := len(data.SegmentsToAdd); n != 0 {
+		data.SegmentsToAdd = slugs[:n]
+	}
+	if n := len(data.SegmentsToAdd); len(data.SegmentsToRemove) != 0 {
+		data.SegmentsToRemove = slugs[n:]
+	}
+	if err = a.checkStaticSegments(ctx, slugs...); err != nil {
+		return models.MembershipDiff{}, err
+	}
+	return a.storage.UpdateUserSegments(ctx, data, userID, bucket.InRollout)
 }
 
-func (a *SegmentSvc) GetUserSegments(userID uuid.UUID) (models.SegmentsList, error) {
-	return a.storage.GetUserSegments(userID)
+func (a *SegmentSvc) GetUserSegments(ctx context.Context, userID uuid.UUID) (_ models.SegmentsList, err error) {
+	ctx, span := startSpan(ctx, "SegmentSvc.GetUserSegments", attribute.String("user.id", userID.String()))
+	defer func() { endSpan(span, err) }()
+
+	return a.storage.GetUserSegments(ctx, userID, bucket.InRollout)
+}
+
+func (a *SegmentSvc) ListSegments(ctx context.Context) (_ models.SegmentsList, err error) {
+	ctx, span := startSpan(ctx, "SegmentSvc.ListSegments")
+	defer func() { endSpan(span, err) }()
+
+	segments, err := a.storage.ListSegments(ctx)
+	if err != nil {
+		return segments, fmt.Errorf("database error: %w", err)
+	}
+	return segments, nil
 }
 
-func (a *SegmentSvc) GetReport(period string) ([][]string, error) {
+// GetReport returns the report rows of the month selected by the filter. The rows of an archived month are read
+// from its file.
+func (a *SegmentSvc) GetReport(ctx context.Context, period string, filter models.ReportFilter) (_ [][]string, err error) {
+	ctx, span := startSpan(ctx, "SegmentSvc.GetReport", attribute.String("report.period", period))
+	defer func() { endSpan(span, err) }()
+
+	if err = validateReportFilter(filter); err != nil {
+		return nil, err
+	}
 	monthBeginning := period + "-01"
-	records, err := a.storage.GetReport(monthBeginning)
+	records, err := a.storage.GetReport(ctx, monthBeginning, filter)
 	if err != nil {
 		return records, err
 	}
-	return records, nil
+	return a.withArchivedReport(ctx, period, records, func(row models.ArchivedReportRow) bool {
+		return matchesReportFilter(row, filter)
+	})
 }
 
-func (a *SegmentSvc) GetUserReport(period string, userID uuid.UUID) ([][]string, error) {
+// GetUserReport returns the report rows of the user for the month selected by the filter. The rows of an archived
+// month are read from its file.
+func (a *SegmentSvc) GetUserReport(ctx context.Context, period string, userID uuid.UUID, filter models.ReportFilter) (_ [][]string, err error) {
+	ctx, span := startSpan(ctx, "SegmentSvc.GetUserReport",
+		attribute.String("report.period", period),
+		attribute.String("user.id", userID.String()),
+	)
+	defer func() { endSpan(span, err) }()
+
+	if err = validateReportFilter(filter); err != nil {
+		return nil, err
+	}
 	monthBeginning := period + "-01"
-	records, err := a.storage.GetUserReport(monthBeginning, userID)
+	records, err := a.storage.GetUserReport(ctx, monthBeginning, userID, filter)
 	if err != nil {
 		return records, err
 	}
-	return records, nil
+	return a.withArchivedReport(ctx, period, records, func(row models.ArchivedReportRow) bool {
+		return row.UserID == userID && matchesReportFilter(row, filter)
+	})
+}
+
+// validateReportFilter checks the filter like the attribution of a change, so an unknown source is rejected
+// instead of silently matching nothing.
+func validateReportFilter(filter models.ReportFilter) error {
+	return models.Attribution{Actor: filter.Actor, Source: filter.Source, Reason: filter.Reason}.Validate()
+}
+
+// startSpan starts a child span of the span stored in ctx (usually the server span of the http request).
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan marks the span as failed if the operation returned an error and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
 }
@@ -0,0 +1,80 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"segmentation-service/internal/domain/models"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// GetSegmentStats returns the number of the members of the segment and its daily additions and removals from
+// the first to the last day of the range. A missing end of the range is today and a missing start is
+// models.DefaultStatsDays days before the end.
+func (a *SegmentSvc) GetSegmentStats(ctx context.Context, slug string, from, to time.Time) (stats models.SegmentStats, err error) {
+	ctx, span := startSpan(ctx, "SegmentSvc.GetSegmentStats", attribute.String("segment.slug", slug))
+	defer func() { endSpan(span, err) }()
+
+	if !models.SlugRegexp.MatchString(slug) {
+		return stats, models.ErrInvalidSlugFormat
+	}
+	if from, to, err = statsRange(from, to, time.Now()); err != nil {
+		return stats, err
+	}
+	if slug, err = a.resolveSlug(ctx, slug); err != nil {
+		return stats, err
+	}
+	stats, err = a.storage.GetSegmentStats(ctx, slug, from, to)
+	if err != nil && !errors.Is(err, models.ErrSegmentNotFound) {
+		return stats, fmt.Errorf("database error: %w", err)
+	}
+	return stats, err
+}
+
+// GetServiceStats returns the numbers of the members of all segments.
+func (a *SegmentSvc) GetServiceStats(ctx context.Context) (stats models.ServiceStats, err error) {
+	ctx, span := startSpan(ctx, "SegmentSvc.GetServiceStats")
+	defer func() { endSpan(span, err) }()
+
+	stats, err = a.storage.GetServiceStats(ctx)
+	if err != nil {
+		return stats, fmt.Errorf("database error: %w", err)
+	}
+	return stats, nil
+}
+
+// FoldSegmentSizes compacts the counters the numbers of the segment members are read from and returns the number
+// of the folded rows. It is run periodically by the scheduler.
+func (a *SegmentSvc) FoldSegmentSizes(ctx context.Context) (count int, err error) {
+	ctx, span := startSpan(ctx, "SegmentSvc.FoldSegmentSizes")
+	defer func() { endSpan(span, err) }()
+
+	count, err = a.storage.FoldSegmentSizes(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("database error: %w", err)
+	}
+	return count, nil
+}
+
+// statsRange fills in the missing ends of the range of days relative to now and checks that the range is ordered
+// and no longer than models.MaxStatsDays days. Only the dates of the ends are kept.
+func statsRange(from, to, now time.Time) (time.Time, time.Time, error) {
+	day := func(t time.Time) time.Time { return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC) }
+	if to.IsZero() {
+		to = now
+	}
+	to = day(to)
+	if from.IsZero() {
+		from = to.AddDate(0, 0, 1-models.DefaultStatsDays)
+	}
+	from = day(from)
+	if to.Before(from) {
+		return from, to, fmt.Errorf("%w: 'from' is after 'to'", models.ErrInvalidDateRange)
+	}
+	if days := int(to.Sub(from).Hours()/24) + 1; days > models.MaxStatsDays {
+		return from, to, fmt.Errorf("%w: the range is longer than %d days", models.ErrInvalidDateRange, models.MaxStatsDays)
+	}
+	return from, to, nil
+}
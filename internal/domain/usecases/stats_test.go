@@ -0,0 +1,140 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"segmentation-service/internal/domain/models"
+	"segmentation-service/internal/ports/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+)
+
+func TestGetSegmentStats(t *testing.T) {
+	date := func(s string) time.Time {
+		d, err := time.Parse(models.DateLayout, s)
+		require.NoError(t, err)
+		return d
+	}
+	stats := models.SegmentStats{Slug: "BETA", Members: 10, From: "2023-08-01", To: "2023-08-02", Days: []models.DailyStats{
+		{Date: "2023-08-01", Added: 12, Removed: 2},
+		{Date: "2023-08-02"},
+	}}
+
+	// prepare test data
+	testCases := []struct {
+		name          string
+		from, to      time.Time
+		mockBehaviour func(m *mocks.MockSegmentStorage)
+		expStats      models.SegmentStats
+		expErr        error
+	}{
+		{
+			name: "OK",
+			from: date("2023-08-01"),
+			to:   date("2023-08-02"),
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().GetSegmentStats(gomock.Any(), "BETA", date("2023-08-01"), date("2023-08-02")).Return(stats, nil)
+			},
+			expStats: stats,
+		},
+		{
+			name: "Default start",
+			to:   date("2023-08-30"),
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().GetSegmentStats(gomock.Any(), "BETA", date("2023-08-01"), date("2023-08-30")).Return(stats, nil)
+			},
+			expStats: stats,
+		},
+		{
+			name:          "Start after end",
+			from:          date("2023-08-02"),
+			to:            date("2023-08-01"),
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {},
+			expErr:        models.ErrInvalidDateRange,
+		},
+		{
+			name:          "Range too long",
+			from:          date("2022-08-01"),
+			to:            date("2023-08-02"),
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {},
+			expErr:        models.ErrInvalidDateRange,
+		},
+		{
+			name: "Segment not found",
+			from: date("2023-08-01"),
+			to:   date("2023-08-02"),
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().GetSegmentStats(gomock.Any(), "BETA", gomock.Any(), gomock.Any()).Return(models.SegmentStats{}, models.ErrSegmentNotFound)
+			},
+			expErr: models.ErrSegmentNotFound,
+		},
+		{
+			name: "Database error",
+			from: date("2023-08-01"),
+			to:   date("2023-08-02"),
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().GetSegmentStats(gomock.Any(), "BETA", gomock.Any(), gomock.Any()).Return(models.SegmentStats{}, errors.New("some error"))
+			},
+			expErr: errors.New("database error: some error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			storage := mocks.NewMockSegmentStorage(ctrl)
+			withoutAliases(storage)
+			tc.mockBehaviour(storage)
+
+			result, err := New(storage).GetSegmentStats(context.Background(), "BETA", tc.from, tc.to)
+			if tc.expErr != nil {
+				require.ErrorContains(t, err, tc.expErr.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.DeepEqual(t, tc.expStats, result)
+		})
+	}
+}
+
+func TestStatsRange(t *testing.T) {
+	now := time.Date(2023, 8, 30, 23, 59, 0, 0, time.UTC)
+
+	// the missing range is the last days up to today, with the times dropped
+	from, to, err := statsRange(time.Time{}, time.Time{}, now)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2023, 8, 1, 0, 0, 0, 0, time.UTC), from)
+	require.Equal(t, time.Date(2023, 8, 30, 0, 0, 0, 0, time.UTC), to)
+
+	// a single day and the longest range are allowed
+	_, _, err = statsRange(now, now, now)
+	require.NoError(t, err)
+	_, _, err = statsRange(now.AddDate(0, 0, 1-models.MaxStatsDays), now, now)
+	require.NoError(t, err)
+	_, _, err = statsRange(now.AddDate(0, 0, -models.MaxStatsDays), now, now)
+	require.ErrorIs(t, err, models.ErrInvalidDateRange)
+}
+
+func TestGetServiceStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storage := mocks.NewMockSegmentStorage(ctrl)
+	limit := 100
+	stats := models.ServiceStats{Segments: 2, Memberships: 110, Sizes: []models.SegmentSize{
+		{Slug: "ALPHA", Members: 10},
+		{Slug: "BETA", Members: 100, MaxMembers: &limit},
+	}}
+
+	storage.EXPECT().GetServiceStats(gomock.Any()).Return(stats, nil)
+	result, err := New(storage).GetServiceStats(context.Background())
+	require.NoError(t, err)
+	assert.DeepEqual(t, stats, result)
+
+	storage.EXPECT().GetServiceStats(gomock.Any()).Return(models.ServiceStats{}, errors.New("some error"))
+	_, err = New(storage).GetServiceStats(context.Background())
+	require.EqualError(t, err, "database error: some error")
+}
@@ -0,0 +1,53 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"segmentation-service/internal/domain/models"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// SetSegmentWindow validates and saves the activation window of the segment. Outside the window the segment isn't
+// returned to its members, but the memberships are kept and can still be changed. Nil bounds leave the window open
+// on that side, so two nils remove the window.
+func (a *SegmentSvc) SetSegmentWindow(ctx context.Context, slug string, from, until *time.Time) (result models.SegmentState, err error) {
+	ctx, span := startSpan(ctx, "SegmentSvc.SetSegmentWindow", attribute.String("segment.slug", slug))
+	defer func() { endSpan(span, err) }()
+
+	if err = validateWindow(from, until); err != nil {
+		return result, err
+	}
+	if slug, err = a.resolveSlug(ctx, slug); err != nil {
+		return result, err
+	}
+
+	result, err = a.storage.SetSegmentWindow(ctx, slug, from, until)
+	if err != nil && !errors.Is(err, models.ErrSegmentNotFound) {
+		return result, fmt.Errorf("database error: %w", err)
+	}
+	return result, err
+}
+
+// RecordSegmentStates writes the activations and deactivations of the segments by their windows to the audit log
+// and returns the number of written events. It is called periodically by the scheduler.
+func (a *SegmentSvc) RecordSegmentStates(ctx context.Context) (count int, err error) {
+	ctx, span := startSpan(ctx, "SegmentSvc.RecordSegmentStates")
+	defer func() { endSpan(span, err) }()
+
+	count, err = a.storage.RecordSegmentStates(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("database error: %w", err)
+	}
+	return count, nil
+}
+
+// validateWindow checks that the window ends after it starts.
+func validateWindow(from, until *time.Time) error {
+	if from != nil && until != nil && !until.After(*from) {
+		return fmt.Errorf("%w: active_until must be after active_from", models.ErrInvalidWindow)
+	}
+	return nil
+}
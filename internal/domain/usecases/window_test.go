@@ -0,0 +1,125 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"segmentation-service/internal/domain/models"
+	"segmentation-service/internal/ports/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/assert"
+)
+
+func TestSetSegmentWindow(t *testing.T) {
+	from := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+	until := from.AddDate(0, 1, 0)
+
+	// prepare test data
+	testCases := []struct {
+		name          string
+		from, until   *time.Time
+		mockBehaviour func(m *mocks.MockSegmentStorage)
+		expState      models.SegmentState
+		expErr        error
+	}{
+		{
+			name:  "Set window",
+			from:  &from,
+			until: &until,
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().SetSegmentWindow(gomock.Any(), "PROMO", &from, &until).
+					Return(models.SegmentState{Slug: "PROMO", State: models.StateEnded, ActiveFrom: &from, ActiveUntil: &until}, nil)
+			},
+			expState: models.SegmentState{Slug: "PROMO", State: models.StateEnded, ActiveFrom: &from, ActiveUntil: &until},
+		},
+		{
+			name: "Open end",
+			from: &from,
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().SetSegmentWindow(gomock.Any(), "PROMO", &from, nil).
+					Return(models.SegmentState{Slug: "PROMO", State: models.StateActive, ActiveFrom: &from}, nil)
+			},
+			expState: models.SegmentState{Slug: "PROMO", State: models.StateActive, ActiveFrom: &from},
+		},
+		{
+			name:          "End before start",
+			from:          &until,
+			until:         &from,
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {},
+			expErr:        models.ErrInvalidWindow,
+		},
+		{
+			name:          "Empty window",
+			from:          &from,
+			until:         &from,
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {},
+			expErr:        models.ErrInvalidWindow,
+		},
+		{
+			name: "Segment not found",
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().SetSegmentWindow(gomock.Any(), "PROMO", nil, nil).Return(models.SegmentState{}, models.ErrSegmentNotFound)
+			},
+			expErr: models.ErrSegmentNotFound,
+		},
+		{
+			name: "Database error",
+			mockBehaviour: func(m *mocks.MockSegmentStorage) {
+				m.EXPECT().SetSegmentWindow(gomock.Any(), "PROMO", nil, nil).Return(models.SegmentState{}, errors.New("some error"))
+			},
+			expErr: errors.New("database error: some error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			storage := mocks.NewMockSegmentStorage(ctrl)
+			withoutAliases(storage)
+			tc.mockBehaviour(storage)
+
+			state, err := New(storage).SetSegmentWindow(context.Background(), "PROMO", tc.from, tc.until)
+			if tc.expErr != nil {
+				require.ErrorContains(t, err, tc.expErr.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.DeepEqual(t, tc.expState, state)
+		})
+	}
+}
+
+func TestCreateSegmentWithWindow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storage := mocks.NewMockSegmentStorage(ctrl)
+	withoutAliases(storage)
+	until := time.Now().Add(time.Hour)
+
+	segment := models.Segment{Slug: "PROMO", ActiveUntil: &until}
+	gomock.InOrder(
+		storage.EXPECT().FindSegment(gomock.Any(), "PROMO").Return(0, nil),
+		storage.EXPECT().SaveSegment(gomock.Any(), segment, gomock.Any()).Return(nil),
+	)
+
+	err := New(storage).CreateSegment(context.Background(), segment)
+	require.NoError(t, err)
+}
+
+func TestRecordSegmentStates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	storage := mocks.NewMockSegmentStorage(ctrl)
+	storage.EXPECT().RecordSegmentStates(gomock.Any()).Return(2, nil)
+	storage.EXPECT().RecordSegmentStates(gomock.Any()).Return(0, errors.New("some error"))
+
+	svc := New(storage)
+	count, err := svc.RecordSegmentStates(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	_, err = svc.RecordSegmentStates(context.Background())
+	require.ErrorContains(t, err, "database error: some error")
+}
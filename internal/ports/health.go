@@ -0,0 +1,8 @@
+package ports
+
+import "context"
+
+type HealthChecker interface {
+	// Ping returns an error if the storage can't serve requests yet: the connection is lost or the schema isn't migrated.
+	Ping(ctx context.Context) error
+}
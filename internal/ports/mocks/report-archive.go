@@ -0,0 +1,133 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/ports/report-archive.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	models "segmentation-service/internal/domain/models"
+	ports "segmentation-service/internal/ports"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockReportArchive is a mock of ReportArchive interface.
+type MockReportArchive struct {
+	ctrl     *gomock.Controller
+	recorder *MockReportArchiveMockRecorder
+}
+
+// MockReportArchiveMockRecorder is the mock recorder for MockReportArchive.
+type MockReportArchiveMockRecorder struct {
+	mock *MockReportArchive
+}
+
+// NewMockReportArchive creates a new mock instance.
+func NewMockReportArchive(ctrl *gomock.Controller) *MockReportArchive {
+	mock := &MockReportArchive{ctrl: ctrl}
+	mock.recorder = &MockReportArchiveMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReportArchive) EXPECT() *MockReportArchiveMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockReportArchive) Create(month string) (ports.ReportArchiveWriter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", month)
+	ret0, _ := ret[0].(ports.ReportArchiveWriter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockReportArchiveMockRecorder) Create(month interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockReportArchive)(nil).Create), month)
+}
+
+// Read mocks base method.
+func (m *MockReportArchive) Read(ctx context.Context, run models.ReportArchiveRun, visit func(models.ArchivedReportRow) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Read", ctx, run, visit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Read indicates an expected call of Read.
+func (mr *MockReportArchiveMockRecorder) Read(ctx, run, visit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockReportArchive)(nil).Read), ctx, run, visit)
+}
+
+// MockReportArchiveWriter is a mock of ReportArchiveWriter interface.
+type MockReportArchiveWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockReportArchiveWriterMockRecorder
+}
+
+// MockReportArchiveWriterMockRecorder is the mock recorder for MockReportArchiveWriter.
+type MockReportArchiveWriterMockRecorder struct {
+	mock *MockReportArchiveWriter
+}
+
+// NewMockReportArchiveWriter creates a new mock instance.
+func NewMockReportArchiveWriter(ctrl *gomock.Controller) *MockReportArchiveWriter {
+	mock := &MockReportArchiveWriter{ctrl: ctrl}
+	mock.recorder = &MockReportArchiveWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReportArchiveWriter) EXPECT() *MockReportArchiveWriterMockRecorder {
+	return m.recorder
+}
+
+// Abort mocks base method.
+func (m *MockReportArchiveWriter) Abort() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Abort")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Abort indicates an expected call of Abort.
+func (mr *MockReportArchiveWriterMockRecorder) Abort() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Abort", reflect.TypeOf((*MockReportArchiveWriter)(nil).Abort))
+}
+
+// Commit mocks base method.
+func (m *MockReportArchiveWriter) Commit() (string, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Commit")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Commit indicates an expected call of Commit.
+func (mr *MockReportArchiveWriterMockRecorder) Commit() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Commit", reflect.TypeOf((*MockReportArchiveWriter)(nil).Commit))
+}
+
+// Write mocks base method.
+func (m *MockReportArchiveWriter) Write(row models.ArchivedReportRow) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Write", row)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Write indicates an expected call of Write.
+func (mr *MockReportArchiveWriterMockRecorder) Write(row interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockReportArchiveWriter)(nil).Write), row)
+}
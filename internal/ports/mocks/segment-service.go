@@ -0,0 +1,575 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/ports/segment-service.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	io "io"
+	reflect "reflect"
+	models "segmentation-service/internal/domain/models"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+)
+
+// MockSegmentService is a mock of SegmentService interface.
+type MockSegmentService struct {
+	ctrl     *gomock.Controller
+	recorder *MockSegmentServiceMockRecorder
+}
+
+// MockSegmentServiceMockRecorder is the mock recorder for MockSegmentService.
+type MockSegmentServiceMockRecorder struct {
+	mock *MockSegmentService
+}
+
+// NewMockSegmentService creates a new mock instance.
+func NewMockSegmentService(ctrl *gomock.Controller) *MockSegmentService {
+	mock := &MockSegmentService{ctrl: ctrl}
+	mock.recorder = &MockSegmentServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSegmentService) EXPECT() *MockSegmentServiceMockRecorder {
+	return m.recorder
+}
+
+// ArchiveReports mocks base method.
+func (m *MockSegmentService) ArchiveReports(ctx context.Context) ([]models.ReportArchiveRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ArchiveReports", ctx)
+	ret0, _ := ret[0].([]models.ReportArchiveRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ArchiveReports indicates an expected call of ArchiveReports.
+func (mr *MockSegmentServiceMockRecorder) ArchiveReports(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ArchiveReports", reflect.TypeOf((*MockSegmentService)(nil).ArchiveReports), ctx)
+}
+
+// AssignExperiment mocks base method.
+func (m *MockSegmentService) AssignExperiment(ctx context.Context, name string, userID uuid.UUID) (models.Assignment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssignExperiment", ctx, name, userID)
+	ret0, _ := ret[0].(models.Assignment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AssignExperiment indicates an expected call of AssignExperiment.
+func (mr *MockSegmentServiceMockRecorder) AssignExperiment(ctx, name, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignExperiment", reflect.TypeOf((*MockSegmentService)(nil).AssignExperiment), ctx, name, userID)
+}
+
+// BackfillRollups mocks base method.
+func (m *MockSegmentService) BackfillRollups(ctx context.Context, from, to *time.Time) (models.RollupResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BackfillRollups", ctx, from, to)
+	ret0, _ := ret[0].(models.RollupResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BackfillRollups indicates an expected call of BackfillRollups.
+func (mr *MockSegmentServiceMockRecorder) BackfillRollups(ctx, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BackfillRollups", reflect.TypeOf((*MockSegmentService)(nil).BackfillRollups), ctx, from, to)
+}
+
+// CheckRollups mocks base method.
+func (m *MockSegmentService) CheckRollups(ctx context.Context, from, to *time.Time) ([]models.RollupMismatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckRollups", ctx, from, to)
+	ret0, _ := ret[0].([]models.RollupMismatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckRollups indicates an expected call of CheckRollups.
+func (mr *MockSegmentServiceMockRecorder) CheckRollups(ctx, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckRollups", reflect.TypeOf((*MockSegmentService)(nil).CheckRollups), ctx, from, to)
+}
+
+// CreateExperimentGroup mocks base method.
+func (m *MockSegmentService) CreateExperimentGroup(ctx context.Context, group models.ExperimentGroup) (models.ExperimentGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateExperimentGroup", ctx, group)
+	ret0, _ := ret[0].(models.ExperimentGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateExperimentGroup indicates an expected call of CreateExperimentGroup.
+func (mr *MockSegmentServiceMockRecorder) CreateExperimentGroup(ctx, group interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateExperimentGroup", reflect.TypeOf((*MockSegmentService)(nil).CreateExperimentGroup), ctx, group)
+}
+
+// CreateReportPartitions mocks base method.
+func (m *MockSegmentService) CreateReportPartitions(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReportPartitions", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateReportPartitions indicates an expected call of CreateReportPartitions.
+func (mr *MockSegmentServiceMockRecorder) CreateReportPartitions(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReportPartitions", reflect.TypeOf((*MockSegmentService)(nil).CreateReportPartitions), ctx)
+}
+
+// CreateSegment mocks base method.
+func (m *MockSegmentService) CreateSegment(ctx context.Context, segment models.Segment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSegment", ctx, segment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSegment indicates an expected call of CreateSegment.
+func (mr *MockSegmentServiceMockRecorder) CreateSegment(ctx, segment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSegment", reflect.TypeOf((*MockSegmentService)(nil).CreateSegment), ctx, segment)
+}
+
+// DeleteExperimentGroup mocks base method.
+func (m *MockSegmentService) DeleteExperimentGroup(ctx context.Context, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExperimentGroup", ctx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExperimentGroup indicates an expected call of DeleteExperimentGroup.
+func (mr *MockSegmentServiceMockRecorder) DeleteExperimentGroup(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExperimentGroup", reflect.TypeOf((*MockSegmentService)(nil).DeleteExperimentGroup), ctx, name)
+}
+
+// DeleteSegment mocks base method.
+func (m *MockSegmentService) DeleteSegment(ctx context.Context, slug string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSegment", ctx, slug)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSegment indicates an expected call of DeleteSegment.
+func (mr *MockSegmentServiceMockRecorder) DeleteSegment(ctx, slug interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSegment", reflect.TypeOf((*MockSegmentService)(nil).DeleteSegment), ctx, slug)
+}
+
+// ExportState mocks base method.
+func (m *MockSegmentService) ExportState(ctx context.Context, w io.Writer, withReport bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportState", ctx, w, withReport)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportState indicates an expected call of ExportState.
+func (mr *MockSegmentServiceMockRecorder) ExportState(ctx, w, withReport interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportState", reflect.TypeOf((*MockSegmentService)(nil).ExportState), ctx, w, withReport)
+}
+
+// FoldReportRollups mocks base method.
+func (m *MockSegmentService) FoldReportRollups(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FoldReportRollups", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FoldReportRollups indicates an expected call of FoldReportRollups.
+func (mr *MockSegmentServiceMockRecorder) FoldReportRollups(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FoldReportRollups", reflect.TypeOf((*MockSegmentService)(nil).FoldReportRollups), ctx)
+}
+
+// FoldSegmentSizes mocks base method.
+func (m *MockSegmentService) FoldSegmentSizes(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FoldSegmentSizes", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FoldSegmentSizes indicates an expected call of FoldSegmentSizes.
+func (mr *MockSegmentServiceMockRecorder) FoldSegmentSizes(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FoldSegmentSizes", reflect.TypeOf((*MockSegmentService)(nil).FoldSegmentSizes), ctx)
+}
+
+// GetAggregateReport mocks base method.
+func (m *MockSegmentService) GetAggregateReport(ctx context.Context, period string) ([][]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAggregateReport", ctx, period)
+	ret0, _ := ret[0].([][]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAggregateReport indicates an expected call of GetAggregateReport.
+func (mr *MockSegmentServiceMockRecorder) GetAggregateReport(ctx, period interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAggregateReport", reflect.TypeOf((*MockSegmentService)(nil).GetAggregateReport), ctx, period)
+}
+
+// GetReport mocks base method.
+func (m *MockSegmentService) GetReport(ctx context.Context, period string, filter models.ReportFilter) ([][]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReport", ctx, period, filter)
+	ret0, _ := ret[0].([][]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReport indicates an expected call of GetReport.
+func (mr *MockSegmentServiceMockRecorder) GetReport(ctx, period, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReport", reflect.TypeOf((*MockSegmentService)(nil).GetReport), ctx, period, filter)
+}
+
+// GetReportArchiveRuns mocks base method.
+func (m *MockSegmentService) GetReportArchiveRuns(ctx context.Context) ([]models.ReportArchiveRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReportArchiveRuns", ctx)
+	ret0, _ := ret[0].([]models.ReportArchiveRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReportArchiveRuns indicates an expected call of GetReportArchiveRuns.
+func (mr *MockSegmentServiceMockRecorder) GetReportArchiveRuns(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReportArchiveRuns", reflect.TypeOf((*MockSegmentService)(nil).GetReportArchiveRuns), ctx)
+}
+
+// GetSegmentHistory mocks base method.
+func (m *MockSegmentService) GetSegmentHistory(ctx context.Context, slug string) (models.SegmentHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSegmentHistory", ctx, slug)
+	ret0, _ := ret[0].(models.SegmentHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSegmentHistory indicates an expected call of GetSegmentHistory.
+func (mr *MockSegmentServiceMockRecorder) GetSegmentHistory(ctx, slug interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSegmentHistory", reflect.TypeOf((*MockSegmentService)(nil).GetSegmentHistory), ctx, slug)
+}
+
+// GetSegmentMembers mocks base method.
+func (m *MockSegmentService) GetSegmentMembers(ctx context.Context, slug string, after uuid.UUID, limit int) (models.MembersList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSegmentMembers", ctx, slug, after, limit)
+	ret0, _ := ret[0].(models.MembersList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSegmentMembers indicates an expected call of GetSegmentMembers.
+func (mr *MockSegmentServiceMockRecorder) GetSegmentMembers(ctx, slug, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSegmentMembers", reflect.TypeOf((*MockSegmentService)(nil).GetSegmentMembers), ctx, slug, after, limit)
+}
+
+// GetSegmentReport mocks base method.
+func (m *MockSegmentService) GetSegmentReport(ctx context.Context, period string) ([][]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSegmentReport", ctx, period)
+	ret0, _ := ret[0].([][]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSegmentReport indicates an expected call of GetSegmentReport.
+func (mr *MockSegmentServiceMockRecorder) GetSegmentReport(ctx, period interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSegmentReport", reflect.TypeOf((*MockSegmentService)(nil).GetSegmentReport), ctx, period)
+}
+
+// GetSegmentStats mocks base method.
+func (m *MockSegmentService) GetSegmentStats(ctx context.Context, slug string, from, to time.Time) (models.SegmentStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSegmentStats", ctx, slug, from, to)
+	ret0, _ := ret[0].(models.SegmentStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSegmentStats indicates an expected call of GetSegmentStats.
+func (mr *MockSegmentServiceMockRecorder) GetSegmentStats(ctx, slug, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSegmentStats", reflect.TypeOf((*MockSegmentService)(nil).GetSegmentStats), ctx, slug, from, to)
+}
+
+// GetServiceStats mocks base method.
+func (m *MockSegmentService) GetServiceStats(ctx context.Context) (models.ServiceStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetServiceStats", ctx)
+	ret0, _ := ret[0].(models.ServiceStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetServiceStats indicates an expected call of GetServiceStats.
+func (mr *MockSegmentServiceMockRecorder) GetServiceStats(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetServiceStats", reflect.TypeOf((*MockSegmentService)(nil).GetServiceStats), ctx)
+}
+
+// GetUserAttributes mocks base method.
+func (m *MockSegmentService) GetUserAttributes(ctx context.Context, userID uuid.UUID) (models.Attributes, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserAttributes", ctx, userID)
+	ret0, _ := ret[0].(models.Attributes)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserAttributes indicates an expected call of GetUserAttributes.
+func (mr *MockSegmentServiceMockRecorder) GetUserAttributes(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserAttributes", reflect.TypeOf((*MockSegmentService)(nil).GetUserAttributes), ctx, userID)
+}
+
+// GetUserReport mocks base method.
+func (m *MockSegmentService) GetUserReport(ctx context.Context, period string, userID uuid.UUID, filter models.ReportFilter) ([][]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserReport", ctx, period, userID, filter)
+	ret0, _ := ret[0].([][]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserReport indicates an expected call of GetUserReport.
+func (mr *MockSegmentServiceMockRecorder) GetUserReport(ctx, period, userID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserReport", reflect.TypeOf((*MockSegmentService)(nil).GetUserReport), ctx, period, userID, filter)
+}
+
+// GetUserSegments mocks base method.
+func (m *MockSegmentService) GetUserSegments(ctx context.Context, userID uuid.UUID) (models.SegmentsList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserSegments", ctx, userID)
+	ret0, _ := ret[0].(models.SegmentsList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserSegments indicates an expected call of GetUserSegments.
+func (mr *MockSegmentServiceMockRecorder) GetUserSegments(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserSegments", reflect.TypeOf((*MockSegmentService)(nil).GetUserSegments), ctx, userID)
+}
+
+// ImportMemberships mocks base method.
+func (m *MockSegmentService) ImportMemberships(ctx context.Context, r io.Reader, opts models.ImportOptions) (models.ImportResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportMemberships", ctx, r, opts)
+	ret0, _ := ret[0].(models.ImportResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportMemberships indicates an expected call of ImportMemberships.
+func (mr *MockSegmentServiceMockRecorder) ImportMemberships(ctx, r, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportMemberships", reflect.TypeOf((*MockSegmentService)(nil).ImportMemberships), ctx, r, opts)
+}
+
+// ImportState mocks base method.
+func (m *MockSegmentService) ImportState(ctx context.Context, r io.Reader, mode string) (models.RestoreResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportState", ctx, r, mode)
+	ret0, _ := ret[0].(models.RestoreResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportState indicates an expected call of ImportState.
+func (mr *MockSegmentServiceMockRecorder) ImportState(ctx, r, mode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportState", reflect.TypeOf((*MockSegmentService)(nil).ImportState), ctx, r, mode)
+}
+
+// ListExperimentGroups mocks base method.
+func (m *MockSegmentService) ListExperimentGroups(ctx context.Context) (models.ExperimentGroupsList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExperimentGroups", ctx)
+	ret0, _ := ret[0].(models.ExperimentGroupsList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExperimentGroups indicates an expected call of ListExperimentGroups.
+func (mr *MockSegmentServiceMockRecorder) ListExperimentGroups(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExperimentGroups", reflect.TypeOf((*MockSegmentService)(nil).ListExperimentGroups), ctx)
+}
+
+// ListSegments mocks base method.
+func (m *MockSegmentService) ListSegments(ctx context.Context) (models.SegmentsList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSegments", ctx)
+	ret0, _ := ret[0].(models.SegmentsList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSegments indicates an expected call of ListSegments.
+func (mr *MockSegmentServiceMockRecorder) ListSegments(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSegments", reflect.TypeOf((*MockSegmentService)(nil).ListSegments), ctx)
+}
+
+// RecordSegmentStates mocks base method.
+func (m *MockSegmentService) RecordSegmentStates(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordSegmentStates", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordSegmentStates indicates an expected call of RecordSegmentStates.
+func (mr *MockSegmentServiceMockRecorder) RecordSegmentStates(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordSegmentStates", reflect.TypeOf((*MockSegmentService)(nil).RecordSegmentStates), ctx)
+}
+
+// RenameSegment mocks base method.
+func (m *MockSegmentService) RenameSegment(ctx context.Context, req models.RenameRequest) (models.SegmentAlias, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameSegment", ctx, req)
+	ret0, _ := ret[0].(models.SegmentAlias)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenameSegment indicates an expected call of RenameSegment.
+func (mr *MockSegmentServiceMockRecorder) RenameSegment(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameSegment", reflect.TypeOf((*MockSegmentService)(nil).RenameSegment), ctx, req)
+}
+
+// SetSegmentCap mocks base method.
+func (m *MockSegmentService) SetSegmentCap(ctx context.Context, slug string, maxMembers *int) (models.SegmentState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSegmentCap", ctx, slug, maxMembers)
+	ret0, _ := ret[0].(models.SegmentState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetSegmentCap indicates an expected call of SetSegmentCap.
+func (mr *MockSegmentServiceMockRecorder) SetSegmentCap(ctx, slug, maxMembers interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSegmentCap", reflect.TypeOf((*MockSegmentService)(nil).SetSegmentCap), ctx, slug, maxMembers)
+}
+
+// SetSegmentExpression mocks base method.
+func (m *MockSegmentService) SetSegmentExpression(ctx context.Context, slug, expression string) (models.SyncResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSegmentExpression", ctx, slug, expression)
+	ret0, _ := ret[0].(models.SyncResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetSegmentExpression indicates an expected call of SetSegmentExpression.
+func (mr *MockSegmentServiceMockRecorder) SetSegmentExpression(ctx, slug, expression interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSegmentExpression", reflect.TypeOf((*MockSegmentService)(nil).SetSegmentExpression), ctx, slug, expression)
+}
+
+// SetSegmentRollout mocks base method.
+func (m *MockSegmentService) SetSegmentRollout(ctx context.Context, slug string, rollout *float64, salt string) (models.Segment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSegmentRollout", ctx, slug, rollout, salt)
+	ret0, _ := ret[0].(models.Segment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetSegmentRollout indicates an expected call of SetSegmentRollout.
+func (mr *MockSegmentServiceMockRecorder) SetSegmentRollout(ctx, slug, rollout, salt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSegmentRollout", reflect.TypeOf((*MockSegmentService)(nil).SetSegmentRollout), ctx, slug, rollout, salt)
+}
+
+// SetSegmentRule mocks base method.
+func (m *MockSegmentService) SetSegmentRule(ctx context.Context, slug, rule string) (models.SyncResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSegmentRule", ctx, slug, rule)
+	ret0, _ := ret[0].(models.SyncResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetSegmentRule indicates an expected call of SetSegmentRule.
+func (mr *MockSegmentServiceMockRecorder) SetSegmentRule(ctx, slug, rule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSegmentRule", reflect.TypeOf((*MockSegmentService)(nil).SetSegmentRule), ctx, slug, rule)
+}
+
+// SetSegmentWindow mocks base method.
+func (m *MockSegmentService) SetSegmentWindow(ctx context.Context, slug string, from, until *time.Time) (models.SegmentState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSegmentWindow", ctx, slug, from, until)
+	ret0, _ := ret[0].(models.SegmentState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetSegmentWindow indicates an expected call of SetSegmentWindow.
+func (mr *MockSegmentServiceMockRecorder) SetSegmentWindow(ctx, slug, from, until interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSegmentWindow", reflect.TypeOf((*MockSegmentService)(nil).SetSegmentWindow), ctx, slug, from, until)
+}
+
+// UpdateUserAttributes mocks base method.
+func (m *MockSegmentService) UpdateUserAttributes(ctx context.Context, userID uuid.UUID, attrs models.Attributes) (models.AttributesResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserAttributes", ctx, userID, attrs)
+	ret0, _ := ret[0].(models.AttributesResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserAttributes indicates an expected call of UpdateUserAttributes.
+func (mr *MockSegmentServiceMockRecorder) UpdateUserAttributes(ctx, userID, attrs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserAttributes", reflect.TypeOf((*MockSegmentService)(nil).UpdateUserAttributes), ctx, userID, attrs)
+}
+
+// UpdateUserSegments mocks base method.
+func (m *MockSegmentService) UpdateUserSegments(ctx context.Context, data models.UpdateRequest, userID uuid.UUID) (models.MembershipDiff, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserSegments", ctx, data, userID)
+	ret0, _ := ret[0].(models.MembershipDiff)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserSegments indicates an expected call of UpdateUserSegments.
+func (mr *MockSegmentServiceMockRecorder) UpdateUserSegments(ctx, data, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserSegments", reflect.TypeOf((*MockSegmentService)(nil).UpdateUserSegments), ctx, data, userID)
+}
@@ -0,0 +1,681 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/ports/segment-storage.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	models "segmentation-service/internal/domain/models"
+	ports "segmentation-service/internal/ports"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+)
+
+// MockSegmentStorage is a mock of SegmentStorage interface.
+type MockSegmentStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockSegmentStorageMockRecorder
+}
+
+// MockSegmentStorageMockRecorder is the mock recorder for MockSegmentStorage.
+type MockSegmentStorageMockRecorder struct {
+	mock *MockSegmentStorage
+}
+
+// NewMockSegmentStorage creates a new mock instance.
+func NewMockSegmentStorage(ctrl *gomock.Controller) *MockSegmentStorage {
+	mock := &MockSegmentStorage{ctrl: ctrl}
+	mock.recorder = &MockSegmentStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSegmentStorage) EXPECT() *MockSegmentStorageMockRecorder {
+	return m.recorder
+}
+
+// ApplyMemberships mocks base method.
+func (m *MockSegmentStorage) ApplyMemberships(ctx context.Context, changes []models.MembershipChange, inRollout models.RolloutChecker) (int, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyMemberships", ctx, changes, inRollout)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ApplyMemberships indicates an expected call of ApplyMemberships.
+func (mr *MockSegmentStorageMockRecorder) ApplyMemberships(ctx, changes, inRollout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyMemberships", reflect.TypeOf((*MockSegmentStorage)(nil).ApplyMemberships), ctx, changes, inRollout)
+}
+
+// ArchiveReportMonth mocks base method.
+func (m *MockSegmentStorage) ArchiveReportMonth(ctx context.Context, month string, w ports.ReportArchiveWriter) (models.ReportArchiveRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ArchiveReportMonth", ctx, month, w)
+	ret0, _ := ret[0].(models.ReportArchiveRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ArchiveReportMonth indicates an expected call of ArchiveReportMonth.
+func (mr *MockSegmentStorageMockRecorder) ArchiveReportMonth(ctx, month, w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ArchiveReportMonth", reflect.TypeOf((*MockSegmentStorage)(nil).ArchiveReportMonth), ctx, month, w)
+}
+
+// AssignExperiment mocks base method.
+func (m *MockSegmentStorage) AssignExperiment(ctx context.Context, name string, userID uuid.UUID, pick models.VariantPicker) (models.Assignment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssignExperiment", ctx, name, userID, pick)
+	ret0, _ := ret[0].(models.Assignment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AssignExperiment indicates an expected call of AssignExperiment.
+func (mr *MockSegmentStorageMockRecorder) AssignExperiment(ctx, name, userID, pick interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignExperiment", reflect.TypeOf((*MockSegmentStorage)(nil).AssignExperiment), ctx, name, userID, pick)
+}
+
+// BackfillRollups mocks base method.
+func (m *MockSegmentStorage) BackfillRollups(ctx context.Context, from, to *time.Time) (models.RollupResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BackfillRollups", ctx, from, to)
+	ret0, _ := ret[0].(models.RollupResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BackfillRollups indicates an expected call of BackfillRollups.
+func (mr *MockSegmentStorageMockRecorder) BackfillRollups(ctx, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BackfillRollups", reflect.TypeOf((*MockSegmentStorage)(nil).BackfillRollups), ctx, from, to)
+}
+
+// CheckRollups mocks base method.
+func (m *MockSegmentStorage) CheckRollups(ctx context.Context, from, to *time.Time) ([]models.RollupMismatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckRollups", ctx, from, to)
+	ret0, _ := ret[0].([]models.RollupMismatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckRollups indicates an expected call of CheckRollups.
+func (mr *MockSegmentStorageMockRecorder) CheckRollups(ctx, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckRollups", reflect.TypeOf((*MockSegmentStorage)(nil).CheckRollups), ctx, from, to)
+}
+
+// CreateExperimentGroup mocks base method.
+func (m *MockSegmentStorage) CreateExperimentGroup(ctx context.Context, group models.ExperimentGroup) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateExperimentGroup", ctx, group)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateExperimentGroup indicates an expected call of CreateExperimentGroup.
+func (mr *MockSegmentStorageMockRecorder) CreateExperimentGroup(ctx, group interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateExperimentGroup", reflect.TypeOf((*MockSegmentStorage)(nil).CreateExperimentGroup), ctx, group)
+}
+
+// CreateReportPartitions mocks base method.
+func (m *MockSegmentStorage) CreateReportPartitions(ctx context.Context, months int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReportPartitions", ctx, months)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateReportPartitions indicates an expected call of CreateReportPartitions.
+func (mr *MockSegmentStorageMockRecorder) CreateReportPartitions(ctx, months interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReportPartitions", reflect.TypeOf((*MockSegmentStorage)(nil).CreateReportPartitions), ctx, months)
+}
+
+// DeleteExperimentGroup mocks base method.
+func (m *MockSegmentStorage) DeleteExperimentGroup(ctx context.Context, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExperimentGroup", ctx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExperimentGroup indicates an expected call of DeleteExperimentGroup.
+func (mr *MockSegmentStorageMockRecorder) DeleteExperimentGroup(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExperimentGroup", reflect.TypeOf((*MockSegmentStorage)(nil).DeleteExperimentGroup), ctx, name)
+}
+
+// DeleteSegment mocks base method.
+func (m *MockSegmentStorage) DeleteSegment(ctx context.Context, slug string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSegment", ctx, slug)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSegment indicates an expected call of DeleteSegment.
+func (mr *MockSegmentStorageMockRecorder) DeleteSegment(ctx, slug interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSegment", reflect.TypeOf((*MockSegmentStorage)(nil).DeleteSegment), ctx, slug)
+}
+
+// DiffMemberships mocks base method.
+func (m *MockSegmentStorage) DiffMemberships(ctx context.Context, changes []models.MembershipChange, inRollout models.RolloutChecker) (models.ImportDiff, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DiffMemberships", ctx, changes, inRollout)
+	ret0, _ := ret[0].(models.ImportDiff)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DiffMemberships indicates an expected call of DiffMemberships.
+func (mr *MockSegmentStorageMockRecorder) DiffMemberships(ctx, changes, inRollout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiffMemberships", reflect.TypeOf((*MockSegmentStorage)(nil).DiffMemberships), ctx, changes, inRollout)
+}
+
+// ExportState mocks base method.
+func (m *MockSegmentStorage) ExportState(ctx context.Context, withReport bool) (models.State, models.ArchivedMonths, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportState", ctx, withReport)
+	ret0, _ := ret[0].(models.State)
+	ret1, _ := ret[1].(models.ArchivedMonths)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ExportState indicates an expected call of ExportState.
+func (mr *MockSegmentStorageMockRecorder) ExportState(ctx, withReport interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportState", reflect.TypeOf((*MockSegmentStorage)(nil).ExportState), ctx, withReport)
+}
+
+// FindSegment mocks base method.
+func (m *MockSegmentStorage) FindSegment(ctx context.Context, slug string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindSegment", ctx, slug)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindSegment indicates an expected call of FindSegment.
+func (mr *MockSegmentStorageMockRecorder) FindSegment(ctx, slug interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSegment", reflect.TypeOf((*MockSegmentStorage)(nil).FindSegment), ctx, slug)
+}
+
+// FoldReportRollups mocks base method.
+func (m *MockSegmentStorage) FoldReportRollups(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FoldReportRollups", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FoldReportRollups indicates an expected call of FoldReportRollups.
+func (mr *MockSegmentStorageMockRecorder) FoldReportRollups(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FoldReportRollups", reflect.TypeOf((*MockSegmentStorage)(nil).FoldReportRollups), ctx)
+}
+
+// FoldSegmentSizes mocks base method.
+func (m *MockSegmentStorage) FoldSegmentSizes(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FoldSegmentSizes", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FoldSegmentSizes indicates an expected call of FoldSegmentSizes.
+func (mr *MockSegmentStorageMockRecorder) FoldSegmentSizes(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FoldSegmentSizes", reflect.TypeOf((*MockSegmentStorage)(nil).FoldSegmentSizes), ctx)
+}
+
+// GetAggregateReport mocks base method.
+func (m *MockSegmentStorage) GetAggregateReport(ctx context.Context, period string) ([][]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAggregateReport", ctx, period)
+	ret0, _ := ret[0].([][]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAggregateReport indicates an expected call of GetAggregateReport.
+func (mr *MockSegmentStorageMockRecorder) GetAggregateReport(ctx, period interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAggregateReport", reflect.TypeOf((*MockSegmentStorage)(nil).GetAggregateReport), ctx, period)
+}
+
+// GetArchivableMonths mocks base method.
+func (m *MockSegmentStorage) GetArchivableMonths(ctx context.Context, before string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetArchivableMonths", ctx, before)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetArchivableMonths indicates an expected call of GetArchivableMonths.
+func (mr *MockSegmentStorageMockRecorder) GetArchivableMonths(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArchivableMonths", reflect.TypeOf((*MockSegmentStorage)(nil).GetArchivableMonths), ctx, before)
+}
+
+// GetReport mocks base method.
+func (m *MockSegmentStorage) GetReport(ctx context.Context, period string, filter models.ReportFilter) ([][]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReport", ctx, period, filter)
+	ret0, _ := ret[0].([][]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReport indicates an expected call of GetReport.
+func (mr *MockSegmentStorageMockRecorder) GetReport(ctx, period, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReport", reflect.TypeOf((*MockSegmentStorage)(nil).GetReport), ctx, period, filter)
+}
+
+// GetReportArchiveRuns mocks base method.
+func (m *MockSegmentStorage) GetReportArchiveRuns(ctx context.Context) ([]models.ReportArchiveRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReportArchiveRuns", ctx)
+	ret0, _ := ret[0].([]models.ReportArchiveRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReportArchiveRuns indicates an expected call of GetReportArchiveRuns.
+func (mr *MockSegmentStorageMockRecorder) GetReportArchiveRuns(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReportArchiveRuns", reflect.TypeOf((*MockSegmentStorage)(nil).GetReportArchiveRuns), ctx)
+}
+
+// GetSegmentDefinitions mocks base method.
+func (m *MockSegmentStorage) GetSegmentDefinitions(ctx context.Context) (map[string]models.Segment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSegmentDefinitions", ctx)
+	ret0, _ := ret[0].(map[string]models.Segment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSegmentDefinitions indicates an expected call of GetSegmentDefinitions.
+func (mr *MockSegmentStorageMockRecorder) GetSegmentDefinitions(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSegmentDefinitions", reflect.TypeOf((*MockSegmentStorage)(nil).GetSegmentDefinitions), ctx)
+}
+
+// GetSegmentHistory mocks base method.
+func (m *MockSegmentStorage) GetSegmentHistory(ctx context.Context, slug string) (models.SegmentHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSegmentHistory", ctx, slug)
+	ret0, _ := ret[0].(models.SegmentHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSegmentHistory indicates an expected call of GetSegmentHistory.
+func (mr *MockSegmentStorageMockRecorder) GetSegmentHistory(ctx, slug interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSegmentHistory", reflect.TypeOf((*MockSegmentStorage)(nil).GetSegmentHistory), ctx, slug)
+}
+
+// GetSegmentMembers mocks base method.
+func (m *MockSegmentStorage) GetSegmentMembers(ctx context.Context, slug string, after uuid.UUID, limit int) ([]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSegmentMembers", ctx, slug, after, limit)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSegmentMembers indicates an expected call of GetSegmentMembers.
+func (mr *MockSegmentStorageMockRecorder) GetSegmentMembers(ctx, slug, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSegmentMembers", reflect.TypeOf((*MockSegmentStorage)(nil).GetSegmentMembers), ctx, slug, after, limit)
+}
+
+// GetSegmentNames mocks base method.
+func (m *MockSegmentStorage) GetSegmentNames(ctx context.Context) (map[int32]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSegmentNames", ctx)
+	ret0, _ := ret[0].(map[int32]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSegmentNames indicates an expected call of GetSegmentNames.
+func (mr *MockSegmentStorageMockRecorder) GetSegmentNames(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSegmentNames", reflect.TypeOf((*MockSegmentStorage)(nil).GetSegmentNames), ctx)
+}
+
+// GetSegmentReport mocks base method.
+func (m *MockSegmentStorage) GetSegmentReport(ctx context.Context, period string) ([][]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSegmentReport", ctx, period)
+	ret0, _ := ret[0].([][]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSegmentReport indicates an expected call of GetSegmentReport.
+func (mr *MockSegmentStorageMockRecorder) GetSegmentReport(ctx, period interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSegmentReport", reflect.TypeOf((*MockSegmentStorage)(nil).GetSegmentReport), ctx, period)
+}
+
+// GetSegmentStats mocks base method.
+func (m *MockSegmentStorage) GetSegmentStats(ctx context.Context, slug string, from, to time.Time) (models.SegmentStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSegmentStats", ctx, slug, from, to)
+	ret0, _ := ret[0].(models.SegmentStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSegmentStats indicates an expected call of GetSegmentStats.
+func (mr *MockSegmentStorageMockRecorder) GetSegmentStats(ctx, slug, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSegmentStats", reflect.TypeOf((*MockSegmentStorage)(nil).GetSegmentStats), ctx, slug, from, to)
+}
+
+// GetServiceStats mocks base method.
+func (m *MockSegmentStorage) GetServiceStats(ctx context.Context) (models.ServiceStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetServiceStats", ctx)
+	ret0, _ := ret[0].(models.ServiceStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetServiceStats indicates an expected call of GetServiceStats.
+func (mr *MockSegmentStorageMockRecorder) GetServiceStats(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetServiceStats", reflect.TypeOf((*MockSegmentStorage)(nil).GetServiceStats), ctx)
+}
+
+// GetUserAttributes mocks base method.
+func (m *MockSegmentStorage) GetUserAttributes(ctx context.Context, userID uuid.UUID) (models.Attributes, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserAttributes", ctx, userID)
+	ret0, _ := ret[0].(models.Attributes)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserAttributes indicates an expected call of GetUserAttributes.
+func (mr *MockSegmentStorageMockRecorder) GetUserAttributes(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserAttributes", reflect.TypeOf((*MockSegmentStorage)(nil).GetUserAttributes), ctx, userID)
+}
+
+// GetUserReport mocks base method.
+func (m *MockSegmentStorage) GetUserReport(ctx context.Context, period string, userID uuid.UUID, filter models.ReportFilter) ([][]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserReport", ctx, period, userID, filter)
+	ret0, _ := ret[0].([][]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserReport indicates an expected call of GetUserReport.
+func (mr *MockSegmentStorageMockRecorder) GetUserReport(ctx, period, userID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserReport", reflect.TypeOf((*MockSegmentStorage)(nil).GetUserReport), ctx, period, userID, filter)
+}
+
+// GetUserSegments mocks base method.
+func (m *MockSegmentStorage) GetUserSegments(ctx context.Context, userID uuid.UUID, inRollout models.RolloutChecker) (models.SegmentsList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserSegments", ctx, userID, inRollout)
+	ret0, _ := ret[0].(models.SegmentsList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserSegments indicates an expected call of GetUserSegments.
+func (mr *MockSegmentStorageMockRecorder) GetUserSegments(ctx, userID, inRollout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserSegments", reflect.TypeOf((*MockSegmentStorage)(nil).GetUserSegments), ctx, userID, inRollout)
+}
+
+// ListExperimentGroups mocks base method.
+func (m *MockSegmentStorage) ListExperimentGroups(ctx context.Context) ([]models.ExperimentGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExperimentGroups", ctx)
+	ret0, _ := ret[0].([]models.ExperimentGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExperimentGroups indicates an expected call of ListExperimentGroups.
+func (mr *MockSegmentStorageMockRecorder) ListExperimentGroups(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExperimentGroups", reflect.TypeOf((*MockSegmentStorage)(nil).ListExperimentGroups), ctx)
+}
+
+// ListSegments mocks base method.
+func (m *MockSegmentStorage) ListSegments(ctx context.Context) (models.SegmentsList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSegments", ctx)
+	ret0, _ := ret[0].(models.SegmentsList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSegments indicates an expected call of ListSegments.
+func (mr *MockSegmentStorageMockRecorder) ListSegments(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSegments", reflect.TypeOf((*MockSegmentStorage)(nil).ListSegments), ctx)
+}
+
+// RecordSegmentStates mocks base method.
+func (m *MockSegmentStorage) RecordSegmentStates(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordSegmentStates", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordSegmentStates indicates an expected call of RecordSegmentStates.
+func (mr *MockSegmentStorageMockRecorder) RecordSegmentStates(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordSegmentStates", reflect.TypeOf((*MockSegmentStorage)(nil).RecordSegmentStates), ctx)
+}
+
+// RenameSegment mocks base method.
+func (m *MockSegmentStorage) RenameSegment(ctx context.Context, slug, newSlug string, aliasTTL time.Duration) (models.SegmentAlias, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameSegment", ctx, slug, newSlug, aliasTTL)
+	ret0, _ := ret[0].(models.SegmentAlias)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenameSegment indicates an expected call of RenameSegment.
+func (mr *MockSegmentStorageMockRecorder) RenameSegment(ctx, slug, newSlug, aliasTTL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameSegment", reflect.TypeOf((*MockSegmentStorage)(nil).RenameSegment), ctx, slug, newSlug, aliasTTL)
+}
+
+// ResolveSegmentAliases mocks base method.
+func (m *MockSegmentStorage) ResolveSegmentAliases(ctx context.Context, slugs []string) (map[string]models.SegmentAlias, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveSegmentAliases", ctx, slugs)
+	ret0, _ := ret[0].(map[string]models.SegmentAlias)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveSegmentAliases indicates an expected call of ResolveSegmentAliases.
+func (mr *MockSegmentStorageMockRecorder) ResolveSegmentAliases(ctx, slugs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveSegmentAliases", reflect.TypeOf((*MockSegmentStorage)(nil).ResolveSegmentAliases), ctx, slugs)
+}
+
+// RestoreState mocks base method.
+func (m *MockSegmentStorage) RestoreState(ctx context.Context, state models.State, mode string) (models.RestoreResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreState", ctx, state, mode)
+	ret0, _ := ret[0].(models.RestoreResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreState indicates an expected call of RestoreState.
+func (mr *MockSegmentStorageMockRecorder) RestoreState(ctx, state, mode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreState", reflect.TypeOf((*MockSegmentStorage)(nil).RestoreState), ctx, state, mode)
+}
+
+// SaveReportArchiveRun mocks base method.
+func (m *MockSegmentStorage) SaveReportArchiveRun(ctx context.Context, run models.ReportArchiveRun) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveReportArchiveRun", ctx, run)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveReportArchiveRun indicates an expected call of SaveReportArchiveRun.
+func (mr *MockSegmentStorageMockRecorder) SaveReportArchiveRun(ctx, run interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveReportArchiveRun", reflect.TypeOf((*MockSegmentStorage)(nil).SaveReportArchiveRun), ctx, run)
+}
+
+// SaveSegment mocks base method.
+func (m *MockSegmentStorage) SaveSegment(ctx context.Context, segment models.Segment, evaluate models.RuleEvaluator) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSegment", ctx, segment, evaluate)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSegment indicates an expected call of SaveSegment.
+func (mr *MockSegmentStorageMockRecorder) SaveSegment(ctx, segment, evaluate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSegment", reflect.TypeOf((*MockSegmentStorage)(nil).SaveSegment), ctx, segment, evaluate)
+}
+
+// SetSegmentCap mocks base method.
+func (m *MockSegmentStorage) SetSegmentCap(ctx context.Context, slug string, maxMembers *int) (models.SegmentState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSegmentCap", ctx, slug, maxMembers)
+	ret0, _ := ret[0].(models.SegmentState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetSegmentCap indicates an expected call of SetSegmentCap.
+func (mr *MockSegmentStorageMockRecorder) SetSegmentCap(ctx, slug, maxMembers interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSegmentCap", reflect.TypeOf((*MockSegmentStorage)(nil).SetSegmentCap), ctx, slug, maxMembers)
+}
+
+// SetSegmentExpression mocks base method.
+func (m *MockSegmentStorage) SetSegmentExpression(ctx context.Context, slug, expression string) (models.SyncResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSegmentExpression", ctx, slug, expression)
+	ret0, _ := ret[0].(models.SyncResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetSegmentExpression indicates an expected call of SetSegmentExpression.
+func (mr *MockSegmentStorageMockRecorder) SetSegmentExpression(ctx, slug, expression interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSegmentExpression", reflect.TypeOf((*MockSegmentStorage)(nil).SetSegmentExpression), ctx, slug, expression)
+}
+
+// SetSegmentRollout mocks base method.
+func (m *MockSegmentStorage) SetSegmentRollout(ctx context.Context, slug string, rollout *float64, salt string) (models.Segment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSegmentRollout", ctx, slug, rollout, salt)
+	ret0, _ := ret[0].(models.Segment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetSegmentRollout indicates an expected call of SetSegmentRollout.
+func (mr *MockSegmentStorageMockRecorder) SetSegmentRollout(ctx, slug, rollout, salt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSegmentRollout", reflect.TypeOf((*MockSegmentStorage)(nil).SetSegmentRollout), ctx, slug, rollout, salt)
+}
+
+// SetSegmentRule mocks base method.
+func (m *MockSegmentStorage) SetSegmentRule(ctx context.Context, slug, rule string, evaluate models.RuleEvaluator) (models.SyncResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSegmentRule", ctx, slug, rule, evaluate)
+	ret0, _ := ret[0].(models.SyncResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetSegmentRule indicates an expected call of SetSegmentRule.
+func (mr *MockSegmentStorageMockRecorder) SetSegmentRule(ctx, slug, rule, evaluate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSegmentRule", reflect.TypeOf((*MockSegmentStorage)(nil).SetSegmentRule), ctx, slug, rule, evaluate)
+}
+
+// SetSegmentWindow mocks base method.
+func (m *MockSegmentStorage) SetSegmentWindow(ctx context.Context, slug string, from, until *time.Time) (models.SegmentState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSegmentWindow", ctx, slug, from, until)
+	ret0, _ := ret[0].(models.SegmentState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetSegmentWindow indicates an expected call of SetSegmentWindow.
+func (mr *MockSegmentStorageMockRecorder) SetSegmentWindow(ctx, slug, from, until interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSegmentWindow", reflect.TypeOf((*MockSegmentStorage)(nil).SetSegmentWindow), ctx, slug, from, until)
+}
+
+// UpdateUserAttributes mocks base method.
+func (m *MockSegmentStorage) UpdateUserAttributes(ctx context.Context, userID uuid.UUID, attrs models.Attributes, evaluate models.RuleEvaluator) (models.AttributesResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserAttributes", ctx, userID, attrs, evaluate)
+	ret0, _ := ret[0].(models.AttributesResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserAttributes indicates an expected call of UpdateUserAttributes.
+func (mr *MockSegmentStorageMockRecorder) UpdateUserAttributes(ctx, userID, attrs, evaluate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserAttributes", reflect.TypeOf((*MockSegmentStorage)(nil).UpdateUserAttributes), ctx, userID, attrs, evaluate)
+}
+
+// UpdateUserSegments mocks base method.
+func (m *MockSegmentStorage) UpdateUserSegments(ctx context.Context, data models.UpdateRequest, userID uuid.UUID, inRollout models.RolloutChecker) (models.MembershipDiff, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserSegments", ctx, data, userID, inRollout)
+	ret0, _ := ret[0].(models.MembershipDiff)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserSegments indicates an expected call of UpdateUserSegments.
+func (mr *MockSegmentStorageMockRecorder) UpdateUserSegments(ctx, data, userID, inRollout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserSegments", reflect.TypeOf((*MockSegmentStorage)(nil).UpdateUserSegments), ctx, data, userID, inRollout)
+}
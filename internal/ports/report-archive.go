@@ -0,0 +1,24 @@
+package ports
+
+import (
+	"context"
+	"segmentation-service/internal/domain/models"
+)
+
+// ReportArchive keeps the report rows of the archived months in one file per month.
+type ReportArchive interface {
+	// Create starts the file of the month (in the format: yyyy-mm). It replaces the previous file of the month
+	// only when committed.
+	Create(month string) (ReportArchiveWriter, error)
+	// Read calls visit with every row of the file of the archived month, in the order of writing, and checks the file
+	// against the number of rows and the checksum of the run.
+	Read(ctx context.Context, run models.ReportArchiveRun, visit func(models.ArchivedReportRow) error) error
+}
+
+type ReportArchiveWriter interface {
+	Write(row models.ArchivedReportRow) error
+	// Commit completes the file and returns its name and checksum.
+	Commit() (file, checksum string, err error)
+	// Abort discards the file, it does nothing after Commit.
+	Abort() error
+}
@@ -2,16 +2,49 @@
 package ports
 
 import (
+	"context"
+	"io"
 	"segmentation-service/internal/domain/models"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type SegmentService interface {
-	CreateSegment(slug string) error
-	DeleteSegment(slug string) error
-	UpdateUserSegments(data models.UpdateRequest, userID uuid.UUID) error
-	GetUserSegments(userID uuid.UUID) (models.SegmentsList, error)
-	GetReport(period string) ([][]string, error)
-	GetUserReport(period string, userID uuid.UUID) ([][]string, error)
+	CreateSegment(ctx context.Context, segment models.Segment) error
+	DeleteSegment(ctx context.Context, slug string) error
+	SetSegmentRule(ctx context.Context, slug, rule string) (models.SyncResult, error)
+	SetSegmentExpression(ctx context.Context, slug, expression string) (models.SyncResult, error)
+	SetSegmentRollout(ctx context.Context, slug string, rollout *float64, salt string) (models.Segment, error)
+	SetSegmentWindow(ctx context.Context, slug string, from, until *time.Time) (models.SegmentState, error)
+	SetSegmentCap(ctx context.Context, slug string, maxMembers *int) (models.SegmentState, error)
+	RecordSegmentStates(ctx context.Context) (int, error)
+	RenameSegment(ctx context.Context, req models.RenameRequest) (models.SegmentAlias, error)
+	UpdateUserSegments(ctx context.Context, data models.UpdateRequest, userID uuid.UUID) (models.MembershipDiff, error)
+	ImportMemberships(ctx context.Context, r io.Reader, opts models.ImportOptions) (models.ImportResult, error)
+	GetUserSegments(ctx context.Context, userID uuid.UUID) (models.SegmentsList, error)
+	UpdateUserAttributes(ctx context.Context, userID uuid.UUID, attrs models.Attributes) (models.AttributesResult, error)
+	GetUserAttributes(ctx context.Context, userID uuid.UUID) (models.Attributes, error)
+	ListSegments(ctx context.Context) (models.SegmentsList, error)
+	GetSegmentMembers(ctx context.Context, slug string, after uuid.UUID, limit int) (models.MembersList, error)
+	GetSegmentHistory(ctx context.Context, slug string) (models.SegmentHistory, error)
+	GetSegmentStats(ctx context.Context, slug string, from, to time.Time) (models.SegmentStats, error)
+	GetServiceStats(ctx context.Context) (models.ServiceStats, error)
+	FoldSegmentSizes(ctx context.Context) (int, error)
+	FoldReportRollups(ctx context.Context) (int, error)
+	BackfillRollups(ctx context.Context, from, to *time.Time) (models.RollupResult, error)
+	CheckRollups(ctx context.Context, from, to *time.Time) ([]models.RollupMismatch, error)
+	CreateReportPartitions(ctx context.Context) (int, error)
+	CreateExperimentGroup(ctx context.Context, group models.ExperimentGroup) (models.ExperimentGroup, error)
+	DeleteExperimentGroup(ctx context.Context, name string) error
+	ListExperimentGroups(ctx context.Context) (models.ExperimentGroupsList, error)
+	AssignExperiment(ctx context.Context, name string, userID uuid.UUID) (models.Assignment, error)
+	ExportState(ctx context.Context, w io.Writer, withReport bool) error
+	ImportState(ctx context.Context, r io.Reader, mode string) (models.RestoreResult, error)
+	GetReport(ctx context.Context, period string, filter models.ReportFilter) ([][]string, error)
+	GetUserReport(ctx context.Context, period string, userID uuid.UUID, filter models.ReportFilter) ([][]string, error)
+	GetSegmentReport(ctx context.Context, period string) ([][]string, error)
+	GetAggregateReport(ctx context.Context, period string) ([][]string, error)
+	ArchiveReports(ctx context.Context) ([]models.ReportArchiveRun, error)
+	GetReportArchiveRuns(ctx context.Context) ([]models.ReportArchiveRun, error)
 }
@@ -1,17 +1,55 @@
 package ports
 
 import (
+	"context"
 	"segmentation-service/internal/domain/models"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type SegmentStorage interface {
-	FindSegment(slug string) (int, error)
-	SaveSegment(slug string) error
-	DeleteSegment(slug string) error
-	UpdateUserSegments(data models.UpdateRequest, userID uuid.UUID) error
-	GetUserSegments(userID uuid.UUID) (models.SegmentsList, error)
-	GetReport(period string) ([][]string, error)
-	GetUserReport(period string, userID uuid.UUID) ([][]string, error)
+	FindSegment(ctx context.Context, slug string) (int, error)
+	SaveSegment(ctx context.Context, segment models.Segment, evaluate models.RuleEvaluator) error
+	DeleteSegment(ctx context.Context, slug string) error
+	SetSegmentRule(ctx context.Context, slug, rule string, evaluate models.RuleEvaluator) (models.SyncResult, error)
+	SetSegmentExpression(ctx context.Context, slug, expression string) (models.SyncResult, error)
+	SetSegmentRollout(ctx context.Context, slug string, rollout *float64, salt string) (models.Segment, error)
+	SetSegmentWindow(ctx context.Context, slug string, from, until *time.Time) (models.SegmentState, error)
+	SetSegmentCap(ctx context.Context, slug string, maxMembers *int) (models.SegmentState, error)
+	RecordSegmentStates(ctx context.Context) (int, error)
+	RenameSegment(ctx context.Context, slug, newSlug string, aliasTTL time.Duration) (models.SegmentAlias, error)
+	ResolveSegmentAliases(ctx context.Context, slugs []string) (map[string]models.SegmentAlias, error)
+	GetSegmentDefinitions(ctx context.Context) (map[string]models.Segment, error)
+	UpdateUserSegments(ctx context.Context, data models.UpdateRequest, userID uuid.UUID, inRollout models.RolloutChecker) (models.MembershipDiff, error)
+	ApplyMemberships(ctx context.Context, changes []models.MembershipChange, inRollout models.RolloutChecker) (applied, capped int, err error)
+	DiffMemberships(ctx context.Context, changes []models.MembershipChange, inRollout models.RolloutChecker) (models.ImportDiff, error)
+	GetUserSegments(ctx context.Context, userID uuid.UUID, inRollout models.RolloutChecker) (models.SegmentsList, error)
+	UpdateUserAttributes(ctx context.Context, userID uuid.UUID, attrs models.Attributes, evaluate models.RuleEvaluator) (models.AttributesResult, error)
+	GetUserAttributes(ctx context.Context, userID uuid.UUID) (models.Attributes, error)
+	ListSegments(ctx context.Context) (models.SegmentsList, error)
+	GetSegmentMembers(ctx context.Context, slug string, after uuid.UUID, limit int) ([]uuid.UUID, error)
+	GetSegmentHistory(ctx context.Context, slug string) (models.SegmentHistory, error)
+	GetSegmentStats(ctx context.Context, slug string, from, to time.Time) (models.SegmentStats, error)
+	GetServiceStats(ctx context.Context) (models.ServiceStats, error)
+	FoldSegmentSizes(ctx context.Context) (int, error)
+	FoldReportRollups(ctx context.Context) (int, error)
+	BackfillRollups(ctx context.Context, from, to *time.Time) (models.RollupResult, error)
+	CheckRollups(ctx context.Context, from, to *time.Time) ([]models.RollupMismatch, error)
+	CreateReportPartitions(ctx context.Context, months int) (int, error)
+	CreateExperimentGroup(ctx context.Context, group models.ExperimentGroup) error
+	DeleteExperimentGroup(ctx context.Context, name string) error
+	ListExperimentGroups(ctx context.Context) ([]models.ExperimentGroup, error)
+	AssignExperiment(ctx context.Context, name string, userID uuid.UUID, pick models.VariantPicker) (models.Assignment, error)
+	ExportState(ctx context.Context, withReport bool) (models.State, models.ArchivedMonths, error)
+	RestoreState(ctx context.Context, state models.State, mode string) (models.RestoreResult, error)
+	GetReport(ctx context.Context, period string, filter models.ReportFilter) ([][]string, error)
+	GetUserReport(ctx context.Context, period string, userID uuid.UUID, filter models.ReportFilter) ([][]string, error)
+	GetSegmentReport(ctx context.Context, period string) ([][]string, error)
+	GetAggregateReport(ctx context.Context, period string) ([][]string, error)
+	GetSegmentNames(ctx context.Context) (map[int32]string, error)
+	GetArchivableMonths(ctx context.Context, before string) ([]string, error)
+	GetReportArchiveRuns(ctx context.Context) ([]models.ReportArchiveRun, error)
+	SaveReportArchiveRun(ctx context.Context, run models.ReportArchiveRun) error
+	ArchiveReportMonth(ctx context.Context, month string, w ReportArchiveWriter) (models.ReportArchiveRun, error)
 }
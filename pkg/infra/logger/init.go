@@ -2,27 +2,36 @@
 package logger
 
 import (
+	"context"
+	"io"
 	"log/slog"
 	"os"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 var log *slog.Logger
 
 type LoggerOptions struct {
 	IsProd bool
+	Output io.Writer // os.Stdout if nil
 }
 
 // New initializes the logger. Depending on the IsProd parameter, the logging level can be either LevelInfo or LevelDebug.
 func New(opts LoggerOptions) *slog.Logger {
+	output := opts.Output
+	if output == nil {
+		output = os.Stdout
+	}
 	switch {
 	case opts.IsProd:
-		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
-		)
+		log = slog.New(traceHandler{
+			slog.NewJSONHandler(output, &slog.HandlerOptions{Level: slog.LevelInfo}),
+		})
 	default:
-		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
-		)
+		log = slog.New(traceHandler{
+			slog.NewJSONHandler(output, &slog.HandlerOptions{Level: slog.LevelDebug}),
+		})
 	}
 	return log
 }
@@ -34,3 +43,33 @@ func Get() *slog.Logger {
 	}
 	return log
 }
+
+// FromContext returns the logger with the trace and span IDs from ctx already attached.
+// It is intended for code that logs without passing the context to the *Context methods.
+func FromContext(ctx context.Context) *slog.Logger {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return Get()
+	}
+	return Get().With(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+}
+
+// traceHandler adds the trace and span IDs of the active span to the records logged with a context.
+type traceHandler struct {
+	slog.Handler
+}
+
+func (h traceHandler) Handle(ctx context.Context, r slog.Record) error {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h traceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return traceHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h traceHandler) WithGroup(name string) slog.Handler {
+	return traceHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,79 @@
+// The tracing package is responsible for initializing the OpenTelemetry tracer provider.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+const instrumentationName = "segmentation-service"
+
+type TracingOptions struct {
+	Exporter     string // one of: none, stdout, otlp
+	OTLPEndpoint string // host:port of the OTLP/HTTP collector, used only by the otlp exporter
+	ServiceName  string
+}
+
+// New initializes the global tracer provider and the W3C trace context propagator.
+// The returned function flushes the remaining spans and must be called on shutdown.
+func New(opts TracingOptions) (func(ctx context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	switch opts.Exporter {
+	case ExporterNone, "":
+		// spans are still created so that trace IDs get propagated and logged, but nothing is exported
+		tp := sdktrace.NewTracerProvider()
+		otel.SetTracerProvider(tp)
+		return tp.Shutdown, nil
+	case ExporterStdout:
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, fmt.Errorf("stdout exporter creation failed: %w", err)
+		}
+		exporter = exp
+	case ExporterOTLP:
+		exp, err := otlptracehttp.New(context.Background(),
+			otlptracehttp.WithEndpoint(opts.OTLPEndpoint),
+			otlptracehttp.WithInsecure(),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("otlp exporter creation failed: %w", err)
+		}
+		exporter = exp
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter '%s'", opts.Exporter)
+	}
+
+	res := resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(opts.ServiceName))
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
+
+// Tracer returns the tracer used by all the service components.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}